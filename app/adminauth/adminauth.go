@@ -0,0 +1,69 @@
+// Package adminauth 管理API（/api/v1/admin）へのアクセスを管理者のトークンで制限する
+package adminauth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Prefix 管理APIのパス
+const Prefix = "/api/v1/admin"
+
+// SecurityScheme OpenAPIのスペックで管理APIに付けるセキュリティスキームの名前
+const SecurityScheme = "adminToken"
+
+// publicPaths トークンなしで受け付ける管理APIのパス
+// OAuthの同意画面からブラウザでリダイレクトされるためAuthorizationヘッダーを付けられない。代わりに、トークン付きで連携を開始した際に発行したstateで検証する
+var publicPaths = map[string]bool{
+	Prefix + "/integrations/google-calendar/callback": true,
+	Prefix + "/integrations/microsoft-todo/callback":  true,
+}
+
+// Middleware 管理APIへのリクエストに Authorization: Bearer <ADMIN_TOKEN> を要求するミドルウェア
+// トークンが未設定の場合は管理APIを公開しない（OAuthのコールバックを除き全て401）。設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Protected(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !ValidToken(r, config.Current().Admin.Token) {
+			slog.WarnContext(r.Context(), "管理APIへの認証されていないリクエストを拒否しました",
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(huma.Error401Unauthorized(i18n.T(r.Context(), "管理APIの認証に失敗しました")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Protected トークンが必要なパスか（OAuthのコールバックを除く管理APIのパス）
+func Protected(path string) bool {
+	return (path == Prefix || strings.HasPrefix(path, Prefix+"/")) && !publicPaths[path]
+}
+
+// ValidToken Bearerトークンが一致するか（比較は定数時間で行い、トークンが未設定の場合は常に不一致）
+func ValidToken(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
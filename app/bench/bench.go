@@ -0,0 +1,276 @@
+// Package bench クエリの発行方法（プリペアドステートメント・列の指定・差分更新）とページングの方式（OFFSET・キーセット）の実行時間を実DBで比較する
+package bench
+
+import (
+	"context"
+	"fmt"
+	"myapp/db/model"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Options 計測の設定
+type Options struct {
+	// Iterations クエリの比較で各ケースを実行する回数
+	Iterations int
+	// PagingIterations ページングの比較で各ケースを実行する回数（深いOFFSETは遅いため少なめにする）
+	PagingIterations int
+	// PageSize 一覧の1ページの件数
+	PageSize int
+	// Depths ページングを比較するページの位置（先頭から読み飛ばす件数。Todoの件数以上の位置は計測しない）
+	Depths []int
+}
+
+// Result 1ケースの計測結果
+type Result struct {
+	// Group 比較の種類（prepare / select / update / paging）
+	Group string
+	// Case ケースの名前
+	Case   string
+	Runs   int
+	Median time.Duration
+	P95    time.Duration
+	Mean   time.Duration
+}
+
+// todoColumns 一覧・詳細の取得で読む列（サービスと同じ model.TodoColumns）
+var todoColumns = model.TodoColumns
+
+// todoOrder 一覧の既定の並び順（作成日時の新しい順）
+const todoOrder = "created_at DESC, id DESC"
+
+// sampleSize 詳細の取得・更新の対象としてランダムに選ぶTodoの件数
+const sampleSize = 1000
+
+// warmup 計測の前に捨てる実行回数（接続の確立・ステートメントの準備・キャッシュの読み込みを計測に含めない）
+const warmup = 10
+
+// Run 既存のTodoに対して各ケースを実行し、計測結果を返す
+// 更新の比較はロールバックするトランザクション内で行うため、データは変更しない。Todoが1件もない場合はエラー
+func Run(ctx context.Context, gdb *gorm.DB, opts Options) ([]Result, error) {
+	if opts.Iterations < 1 || opts.PagingIterations < 1 {
+		return nil, fmt.Errorf("実行回数は1以上を指定してください（現在: %d / %d）", opts.Iterations, opts.PagingIterations)
+	}
+	if opts.PageSize < 1 {
+		return nil, fmt.Errorf("ページの件数は1以上を指定してください（現在: %d）", opts.PageSize)
+	}
+
+	// SQLログ・スロークエリの警告は計測の妨げになるため出さない
+	gdb = gdb.Session(&gorm.Session{Logger: logger.Discard})
+
+	var ids []uint
+	err := gdb.WithContext(ctx).Raw("SELECT id FROM todos WHERE deleted_at IS NULL ORDER BY random() LIMIT ?", sampleSize).Scan(&ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("計測対象のTodoの取得に失敗しました: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Todoがありません。-rowsを指定するか、loadgenサブコマンドで生成してから実行してください")
+	}
+
+	var results []Result
+	for _, step := range []func(context.Context, *gorm.DB, Options, []uint) ([]Result, error){
+		benchPrepare, benchSelect, benchUpdate, benchPaging,
+	} {
+		r, err := step(ctx, gdb, opts, ids)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+// benchPrepare GORMのプリペアドステートメントキャッシュ（DB_PREPARE_STMT）の有無で、詳細の取得と一覧の先頭ページを比較する
+// 同じ接続プールを共有するGORMのインスタンスを設定ごとに作るため、違いはGORM側でステートメントを準備・再利用するかのみ
+func benchPrepare(ctx context.Context, gdb *gorm.DB, opts Options, ids []uint) ([]Result, error) {
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		return nil, fmt.Errorf("接続プールの取得に失敗しました: %w", err)
+	}
+
+	var results []Result
+	for _, prepare := range []bool{false, true} {
+		pdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			PrepareStmt:            prepare,
+			SkipDefaultTransaction: true,
+			Logger:                 logger.Discard,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("GORMの初期化に失敗しました: %w", err)
+		}
+		label := fmt.Sprintf("PrepareStmt=%t", prepare)
+
+		r, err := measure(ctx, "prepare", "詳細の取得 "+label, opts.Iterations, func(i int) error {
+			var todo model.Todo
+			return pdb.WithContext(ctx).Select(todoColumns).Take(&todo, ids[i%len(ids)]).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+
+		r, err = measure(ctx, "prepare", "一覧の先頭ページ "+label, opts.Iterations, func(int) error {
+			var todos []*model.Todo
+			return pdb.WithContext(ctx).Select(todoColumns).Order(todoOrder).Limit(opts.PageSize).Find(&todos).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+
+		// プリペアドステートメントを閉じる（接続プールは共有のため閉じない）
+		if stmtDB, ok := pdb.ConnPool.(*gorm.PreparedStmtDB); ok {
+			stmtDB.Close()
+		}
+	}
+	return results, nil
+}
+
+// benchSelect 一覧の先頭ページを SELECT * と必要な列の指定で比較する
+func benchSelect(ctx context.Context, gdb *gorm.DB, opts Options, _ []uint) ([]Result, error) {
+	star, err := measure(ctx, "select", "SELECT *", opts.Iterations, func(int) error {
+		var todos []*model.Todo
+		return gdb.WithContext(ctx).Order(todoOrder).Limit(opts.PageSize).Find(&todos).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	columns, err := measure(ctx, "select", "SELECT 列の指定", opts.Iterations, func(int) error {
+		var todos []*model.Todo
+		return gdb.WithContext(ctx).Select(todoColumns).Order(todoOrder).Limit(opts.PageSize).Find(&todos).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Result{star, columns}, nil
+}
+
+// benchUpdate 完了状態の変更を、全列を書き戻すSave()と変更した列のみのUpdates()で比較する
+// 最後にロールバックするトランザクション内で実行するため、Todoは変更しない
+func benchUpdate(ctx context.Context, gdb *gorm.DB, opts Options, ids []uint) ([]Result, error) {
+	var todos []*model.Todo
+	if err := gdb.WithContext(ctx).Find(&todos, ids).Error; err != nil {
+		return nil, fmt.Errorf("計測対象のTodoの取得に失敗しました: %w", err)
+	}
+	if len(todos) == 0 {
+		return nil, fmt.Errorf("計測対象のTodoがありません")
+	}
+
+	tx := gdb.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("トランザクションの開始に失敗しました: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	save, err := measure(ctx, "update", "Save（全列）", opts.Iterations, func(i int) error {
+		todo := todos[i%len(todos)]
+		todo.Completed = !todo.Completed
+		return tx.Save(todo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	updates, err := measure(ctx, "update", "Updates（差分）", opts.Iterations, func(i int) error {
+		todo := todos[i%len(todos)]
+		return tx.Model(todo).Updates(map[string]any{"completed": !todo.Completed}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Result{save, updates}, nil
+}
+
+// benchPaging 一覧の深いページの取得を、OFFSET方式と (created_at, id) のキーセット方式で比較する
+// キーセット方式の起点（前のページの最後の行）は計測の前にOFFSETで1度だけ取得する
+func benchPaging(ctx context.Context, gdb *gorm.DB, opts Options, _ []uint) ([]Result, error) {
+	var total int64
+	if err := gdb.WithContext(ctx).Model(&model.Todo{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("Todoの件数の取得に失敗しました: %w", err)
+	}
+
+	var results []Result
+	for _, depth := range opts.Depths {
+		if depth < 1 || int64(depth) >= total {
+			continue
+		}
+
+		var last struct {
+			CreatedAt time.Time
+			ID        uint
+		}
+		err := gdb.WithContext(ctx).Model(&model.Todo{}).Select("created_at, id").
+			Order(todoOrder).Offset(depth - 1).Limit(1).Scan(&last).Error
+		if err != nil {
+			return nil, fmt.Errorf("キーセットの起点の取得に失敗しました: %w", err)
+		}
+
+		offset, err := measure(ctx, "paging", fmt.Sprintf("OFFSET %d", depth), opts.PagingIterations, func(int) error {
+			var todos []*model.Todo
+			return gdb.WithContext(ctx).Select(todoColumns).Order(todoOrder).Offset(depth).Limit(opts.PageSize).Find(&todos).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		keyset, err := measure(ctx, "paging", fmt.Sprintf("キーセット %d", depth), opts.PagingIterations, func(int) error {
+			var todos []*model.Todo
+			return gdb.WithContext(ctx).Select(todoColumns).
+				Where("(created_at, id) < (?, ?)", last.CreatedAt, last.ID).
+				Order(todoOrder).Limit(opts.PageSize).Find(&todos).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, offset, keyset)
+	}
+	return results, nil
+}
+
+// measure fnをwarmup回実行した後にruns回実行し、1回あたりの実行時間を集計する
+func measure(ctx context.Context, group, name string, runs int, fn func(i int) error) (Result, error) {
+	durations := make([]time.Duration, 0, runs)
+	for i := 0; i < warmup+runs; i++ {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		started := time.Now()
+		if err := fn(i); err != nil {
+			return Result{}, fmt.Errorf("%s（%s）の実行に失敗しました: %w", name, group, err)
+		}
+		if i >= warmup {
+			durations = append(durations, time.Since(started))
+		}
+	}
+
+	slices.Sort(durations)
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	return Result{
+		Group:  group,
+		Case:   name,
+		Runs:   runs,
+		Median: durations[len(durations)/2],
+		P95:    durations[min(len(durations)*95/100, len(durations)-1)],
+		Mean:   sum / time.Duration(len(durations)),
+	}, nil
+}
+
+// Format 計測結果を表の形式で返す
+func Format(results []Result) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "比較\tケース\t回数\t中央値\tp95\t平均")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", r.Group, r.Case, r.Runs,
+			r.Median.Round(time.Microsecond), r.P95.Round(time.Microsecond), r.Mean.Round(time.Microsecond))
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package bodylimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Middleware リクエストボディが上限を超える場合に413を返すミドルウェア
+// Content-Lengthで判定できない場合（chunked等）は上限+1バイトまで読み込んで判定する
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := limitFor(config.Current().BodyLimit, r.URL.Path)
+		if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength > limit {
+			tooLarge(w, r, limit)
+			return
+		}
+
+		if r.ContentLength < 0 {
+			body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+			r.Body.Close()
+			if err != nil {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(huma.Error400BadRequest(i18n.T(r.Context(), "リクエストボディを読み込めません")))
+				return
+			}
+			if int64(len(body)) > limit {
+				tooLarge(w, r, limit)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		} else {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitFor パスに適用する上限を取得（最も長く先頭一致したパスごとの設定、なければ既定値）
+func limitFor(cfg config.BodyLimitConfig, path string) int64 {
+	limit := cfg.MaxBytes
+
+	matched := 0
+	for _, rule := range cfg.Paths {
+		if strings.HasPrefix(path, rule.Prefix) && len(rule.Prefix) > matched {
+			matched = len(rule.Prefix)
+			limit = rule.MaxBytes
+		}
+	}
+	return limit
+}
+
+// tooLarge 413をproblem+json形式で返す
+func tooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(huma.NewError(http.StatusRequestEntityTooLarge, i18n.T(r.Context(), fmt.Sprintf("リクエストボディが大きすぎます（上限: %d バイト）", limit))))
+}
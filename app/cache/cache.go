@@ -0,0 +1,114 @@
+// Package cache クエリ結果のキャッシュ（名前空間ごとの世代番号による選択的な無効化。保存先はインメモリ/Redis）
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store キャッシュの値と名前空間の世代番号の保存先
+type Store interface {
+	// Get 値を取得（ない場合はfalse）
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set 値をttlの間保存
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Generations 名前空間ごとの世代番号（未設定の場合は0）
+	Generations(ctx context.Context, namespaces []string) ([]int64, error)
+	// Incr 名前空間の世代番号を進める
+	Incr(ctx context.Context, namespace string) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Scope キャッシュが依存する名前空間（いずれかの名前空間が無効化されると、そのキャッシュは参照されなくなる）
+// 例えば1件のTodoは Scope{"todos:item", "todos:item:5"} とし、ID 5 への書き込みでは "todos:item:5" だけを、対象の行が分からない書き込みでは "todos:item" を無効化する
+type Scope []string
+
+// Cache 名前空間ごとにキャッシュを保持し、世代番号を進めることで名前空間内のキャッシュをまとめて無効化する
+type Cache struct {
+	store  Store
+	prefix string
+}
+
+// New 保存先を指定して新しいキャッシュを作成
+func New(store Store) *Cache {
+	return &Cache{store: store, prefix: "cache:"}
+}
+
+// NewRedisCache Redisに保存するキャッシュを作成（複数インスタンスで共有し、無効化も全インスタンスに反映される）
+func NewRedisCache(addr string) *Cache {
+	return New(NewRedisStore(addr))
+}
+
+// NewMemoryCache プロセス内に保存するキャッシュを作成（単一インスタンス向け。無効化は他のインスタンスに反映されない）
+func NewMemoryCache(maxEntries int) *Cache {
+	return New(NewMemoryStore(maxEntries))
+}
+
+// Get キャッシュした値をdestにデコードする（キャッシュがない場合はfalse）
+// versionはスコープの現在の世代で、キャッシュがない場合に読み込んだ値をSetに渡すときに使う
+func (c *Cache) Get(ctx context.Context, scope Scope, key string, dest any) (hit bool, version string, err error) {
+	version, err = c.version(ctx, scope)
+	if err != nil {
+		return false, "", err
+	}
+	data, ok, err := c.store.Get(ctx, c.key(scope, version, key))
+	if err != nil || !ok {
+		return false, version, err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		// 型の変更などで読めないキャッシュは無いものとして扱う
+		return false, version, nil
+	}
+	return true, version, nil
+}
+
+// Set 値をversionの世代のキャッシュとしてttlの間保存する
+// versionはGetで取得した世代（読み込み中に無効化された場合は古い世代に保存されるため参照されない）
+func (c *Cache) Set(ctx context.Context, scope Scope, version, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, c.key(scope, version, key), data, ttl)
+}
+
+// Invalidate 名前空間の世代を進め、その名前空間を含むスコープのキャッシュを参照されないようにする（古いキャッシュはTTLで消える）
+func (c *Cache) Invalidate(ctx context.Context, namespace string) error {
+	return c.store.Incr(ctx, c.prefix+namespace+":gen")
+}
+
+// Ping 保存先への接続を確認
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.store.Ping(ctx)
+}
+
+// Close 保存先との接続を閉じる
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+// version スコープの名前空間の世代番号を "." で連結した値
+func (c *Cache) version(ctx context.Context, scope Scope) (string, error) {
+	keys := make([]string, len(scope))
+	for i, namespace := range scope {
+		keys[i] = c.prefix + namespace + ":gen"
+	}
+	gens, err := c.store.Generations(ctx, keys)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(gens))
+	for i, gen := range gens {
+		parts[i] = strconv.FormatInt(gen, 10)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// key 世代を含むキャッシュのキー（スコープの最も細かい名前空間の下に置く）
+func (c *Cache) key(scope Scope, version, key string) string {
+	return c.prefix + scope[len(scope)-1] + ":" + version + ":" + key
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry インメモリストアの値
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore プロセス内に保存するストア（単一インスタンス向け）
+// 値がmaxEntriesに達した場合は期限切れの値を破棄し、それでも空きがなければ任意の値を破棄する
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	generations map[string]int64
+	// base 記録のない名前空間の世代番号（世代番号の記録を破棄した後も、以前の世代と重ならないよう増やす）
+	base int64
+	// top これまでに発行した最大の世代番号
+	top        int64
+	maxEntries int
+	now        func() time.Time
+}
+
+// NewMemoryStore 新しいインメモリストアを作成
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		entries:     make(map[string]memoryEntry),
+		generations: make(map[string]int64),
+		maxEntries:  maxEntries,
+		now:         time.Now,
+	}
+}
+
+// Get 値を取得（期限切れの値は無いものとして扱う）
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set 値をttlの間保存
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		s.evict(now)
+	}
+	s.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Generations 世代番号を取得
+func (s *MemoryStore) Generations(ctx context.Context, keys []string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gens := make([]int64, len(keys))
+	for i, key := range keys {
+		gen, ok := s.generations[key]
+		if !ok {
+			gen = s.base
+		}
+		gens[i] = gen
+	}
+	return gens, nil
+}
+
+// Incr 世代番号を進める
+// 行ごとの名前空間で記録が増え続けないよう、maxEntriesに達した場合は値と世代番号の記録を全て破棄する
+func (s *MemoryStore) Incr(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gen, ok := s.generations[key]
+	if !ok {
+		if len(s.generations) >= s.maxEntries {
+			s.base = s.top + 1
+			s.generations = make(map[string]int64)
+			s.entries = make(map[string]memoryEntry)
+		}
+		gen = s.base
+	}
+	gen++
+	s.generations[key] = gen
+	s.top = max(s.top, gen)
+	return nil
+}
+
+// Ping 常に成功する
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close 保持している値を破棄する
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]memoryEntry)
+	return nil
+}
+
+// evict 期限切れの値を破棄し、空きがなければ1件破棄する
+func (s *MemoryStore) evict(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	if len(s.entries) < s.maxEntries {
+		return
+	}
+	for key := range s.entries {
+		delete(s.entries, key)
+		return
+	}
+}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Rule テーブルへの書き込みで無効化する名前空間
+type Rule struct {
+	// Namespaces 書き込みがあれば常に無効化する名前空間（一覧・件数等、どの行の変更でも結果が変わるもの）
+	Namespaces []string
+	// ItemNamespace 行ごとのキャッシュの名前空間の接頭辞（空の場合は行ごとに無効化しない）
+	// 書き込んだ行の主キーが分かる場合は "<接頭辞>:<主キー>" だけを、分からない場合（条件を指定した一括更新等）は接頭辞の名前空間を無効化する
+	ItemNamespace string
+}
+
+// InvalidationPlugin テーブルへの書き込み（作成・更新・削除）が成功するたびに対応する名前空間のキャッシュを無効化するGORMプラグイン
+// サービスを経由しない書き込み（外部サービスとの同期・取り込み等）でもキャッシュが古くならないようにする
+type InvalidationPlugin struct {
+	cache *Cache
+	// rules テーブル名と無効化する名前空間の対応
+	rules map[string]Rule
+}
+
+// NewInvalidationPlugin 新しい無効化プラグインを作成（rulesはテーブル名と無効化する名前空間の対応）
+func NewInvalidationPlugin(cache *Cache, rules map[string]Rule) *InvalidationPlugin {
+	return &InvalidationPlugin{cache: cache, rules: rules}
+}
+
+// Name プラグイン名を返す
+func (p *InvalidationPlugin) Name() string {
+	return "cache_invalidation"
+}
+
+// Initialize 書き込み系の処理の後にコールバックを登録
+func (p *InvalidationPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().After("gorm:create").Register("cache:after_create", p.after); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("cache:after_update", p.after); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("cache:after_delete", p.after)
+}
+
+// after 書き込みが成功した場合に名前空間を無効化（キャッシュの保存先の障害時は警告のみでSQLの結果には影響させない）
+func (p *InvalidationPlugin) after(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 || db.Statement.DryRun {
+		return
+	}
+	rule, ok := p.rules[db.Statement.Table]
+	if !ok {
+		return
+	}
+
+	namespaces := rule.Namespaces
+	if rule.ItemNamespace != "" {
+		if keys, ok := primaryKeys(db); ok {
+			for _, key := range keys {
+				namespaces = append(namespaces, rule.ItemNamespace+":"+key)
+			}
+		} else {
+			namespaces = append(namespaces, rule.ItemNamespace)
+		}
+	}
+
+	// トランザクション内の書き込みはコミット前のため、ここで無効化すると並行する読み込みがコミット前の値を新しい世代にキャッシュしてしまう
+	// Transaction で開始したトランザクションではコミットの後まで無効化を遅らせる
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		if pending, ok := db.Statement.Context.Value(pendingKey{}).(*pending); ok {
+			pending.add(p, db.Statement.Table, namespaces)
+			return
+		}
+	}
+	p.invalidate(db.Statement.Context, db.Statement.Table, namespaces)
+}
+
+// invalidate 名前空間を無効化する（キャッシュの保存先の障害時は警告のみ）
+func (p *InvalidationPlugin) invalidate(ctx context.Context, table string, namespaces []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, namespace := range namespaces {
+		if err := p.cache.Invalidate(ctx, namespace); err != nil {
+			slog.WarnContext(ctx, "キャッシュの無効化に失敗しました", "namespace", namespace, "table", table, "error", err)
+		}
+	}
+}
+
+// pendingKey トランザクション内で無効化を遅らせた名前空間を集めるコンテキストのキー
+type pendingKey struct{}
+
+// pending トランザクションの終了後に無効化する名前空間
+type pending struct {
+	mu      sync.Mutex
+	entries []pendingEntry
+}
+
+type pendingEntry struct {
+	plugin     *InvalidationPlugin
+	table      string
+	namespaces []string
+}
+
+func (p *pending) add(plugin *InvalidationPlugin, table string, namespaces []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, pendingEntry{plugin: plugin, table: table, namespaces: namespaces})
+}
+
+// flush 集めた名前空間を無効化する（ロールバックした場合も無効化するが、次の読み込みでキャッシュし直されるだけで害はない）
+func (p *pending) flush(ctx context.Context) {
+	p.mu.Lock()
+	entries := p.entries
+	p.entries = nil
+	p.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.plugin.invalidate(ctx, entry.table, entry.namespaces)
+	}
+}
+
+// Transaction fnをトランザクションで実行し、トランザクション内の書き込みによるキャッシュの無効化をコミットの後に行う
+// db.Transaction を直接使うと、コミット前に無効化されるため並行する読み込みが古い値をキャッシュすることがある
+// 既に Transaction の中で呼ばれた場合は、外側のトランザクションの終了時にまとめて無効化する
+func Transaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	if _, ok := ctx.Value(pendingKey{}).(*pending); ok {
+		return db.WithContext(ctx).Transaction(fn, opts...)
+	}
+
+	p := &pending{}
+	err := db.WithContext(context.WithValue(ctx, pendingKey{}, p)).Transaction(fn, opts...)
+	p.flush(ctx)
+	return err
+}
+
+// primaryKeys 書き込んだ行の主キー（モデル・作成したレコードに主キーがない場合はfalse）
+// モデルに主キーがある更新・削除は、GORMがその主キーを条件に加えるため、追加の条件があっても対象はその行に限られる
+func primaryKeys(db *gorm.DB) ([]string, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil || !stmt.ReflectValue.IsValid() {
+		return nil, false
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+
+	var keys []string
+	collect := func(rv reflect.Value) bool {
+		value, zero := field.ValueOf(stmt.Context, reflect.Indirect(rv))
+		if zero {
+			return false
+		}
+		keys = append(keys, fmt.Sprint(value))
+		return true
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		if !collect(rv) {
+			return nil, false
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if !collect(rv.Index(i)) {
+				return nil, false
+			}
+		}
+	default:
+		return nil, false
+	}
+	return keys, len(keys) > 0
+}
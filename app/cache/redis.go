@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// generationTTL 世代番号を保持する期間（行ごとの名前空間でキーが増え続けないよう、書き込みのない名前空間の世代番号は消す）
+// 消えた世代番号は0に戻るが、キャッシュのTTLはこれより十分短いため、以前の世代の値は既に消えている
+const generationTTL = 7 * 24 * time.Hour
+
+// RedisStore Redisに保存するストア（複数インスタンスで共有する）
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 新しいRedisストアを作成
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+		}),
+	}
+}
+
+// Get 値を取得
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Set 値をttlの間保存
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Generations 世代番号をまとめて取得（1往復で読む）
+func (s *RedisStore) Generations(ctx context.Context, keys []string) ([]int64, error) {
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	gens := make([]int64, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		gens[i], err = redis.NewStringResult(value.(string), nil).Int64()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return gens, nil
+}
+
+// Incr 世代番号を進め、generationTTLの間保持する
+func (s *RedisStore) Incr(ctx context.Context, key string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, generationTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Ping Redisへの接続を確認
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Close Redisとの接続を閉じる
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,464 @@
+// Package caldav TodoをVTODOとして公開するCalDAVサーバー（RFC 4791のうちリマインダーアプリの同期に必要な範囲）
+package caldav
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// リソースのパス
+const (
+	// Prefix CalDAVのエンドポイントのパス
+	Prefix         = "/caldav/"
+	principalPath  = Prefix + "principal/"
+	homePath       = Prefix + "calendars/"
+	collectionPath = homePath + "todos/"
+	// WellKnownPath クライアントの自動検出用のパス（RFC 6764）
+	WellKnownPath = "/.well-known/caldav"
+)
+
+// Methods CalDAVで使用するメソッド（chiに登録が必要な標準外のメソッドを含む）
+var Methods = []string{"PROPFIND", "REPORT"}
+
+// allowedMethods OPTIONSで返す許可メソッド
+const allowedMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+
+// maxTitleLength タイトルの上限（APIのバリデーションと同じ）
+const maxTitleLength = 255
+
+// resourceKind パスが指すリソースの種類
+type resourceKind int
+
+const (
+	kindRoot resourceKind = iota
+	kindPrincipal
+	kindHome
+	kindCollection
+	kindItem
+)
+
+// Handler CalDAVリクエストを処理するハンドラー
+type Handler struct {
+	service      service.CalDAVService
+	usernameHash [32]byte
+	passwordHash [32]byte
+}
+
+// NewHandler 新しいCalDAVハンドラーを作成（Basic認証の資格情報を指定）
+func NewHandler(caldavService service.CalDAVService, username, password string) *Handler {
+	return &Handler{
+		service:      caldavService,
+		usernameHash: sha256.Sum256([]byte(username)),
+		passwordHash: sha256.Sum256([]byte(password)),
+	}
+}
+
+// WellKnown /.well-known/caldav からCalDAVのルートへリダイレクト
+func WellKnown(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, Prefix, http.StatusMovedPermanently)
+}
+
+// ServeHTTP 認証後にメソッドとパスに応じて処理を振り分ける
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Todo CalDAV", charset="UTF-8"`)
+		http.Error(w, "認証が必要です", http.StatusUnauthorized)
+		return
+	}
+
+	kind, name, ok := resolve(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", allowedMethods)
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		h.propfind(w, r, kind, name)
+	case "REPORT":
+		h.report(w, r, kind)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, kind, name)
+	case http.MethodPut:
+		h.put(w, r, kind, name)
+	case http.MethodDelete:
+		h.delete(w, r, kind, name)
+	default:
+		w.Header().Set("Allow", allowedMethods)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// authorized Basic認証の資格情報を検証（長さが漏れないようハッシュを定数時間で比較）
+func (h *Handler) authorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	usernameHash := sha256.Sum256([]byte(username))
+	passwordHash := sha256.Sum256([]byte(password))
+	usernameMatch := subtle.ConstantTimeCompare(usernameHash[:], h.usernameHash[:])
+	passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], h.passwordHash[:])
+	return usernameMatch&passwordMatch == 1
+}
+
+// resolve パスからリソースの種類とTodoのリソース名を取得
+func resolve(path string) (resourceKind, string, bool) {
+	switch path {
+	case Prefix, strings.TrimSuffix(Prefix, "/"):
+		return kindRoot, "", true
+	case principalPath, strings.TrimSuffix(principalPath, "/"):
+		return kindPrincipal, "", true
+	case homePath, strings.TrimSuffix(homePath, "/"):
+		return kindHome, "", true
+	case collectionPath, strings.TrimSuffix(collectionPath, "/"):
+		return kindCollection, "", true
+	}
+	name, ok := strings.CutPrefix(path, collectionPath)
+	if !ok || name == "" || strings.Contains(name, "/") || len(name) > 255 {
+		return 0, "", false
+	}
+	return kindItem, name, true
+}
+
+// hrefOf リソースのパス
+func hrefOf(kind resourceKind, name string) string {
+	switch kind {
+	case kindPrincipal:
+		return principalPath
+	case kindHome:
+		return homePath
+	case kindCollection:
+		return collectionPath
+	case kindItem:
+		return collectionPath + name
+	default:
+		return Prefix
+	}
+}
+
+// etag TodoのETag（更新日時が変わるたびに変わる）
+func etag(todo *model.Todo) string {
+	return fmt.Sprintf(`"%d-%d"`, todo.ID, todo.UpdatedAt.UnixNano())
+}
+
+// propfind PROPFIND: リソースとDepth: 1の場合は直下のリソースのプロパティを返す
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, kind resourceKind, name string) {
+	var req propfindRequest
+	present, err := decodeXML(r.Body, &req)
+	if err != nil {
+		http.Error(w, "PROPFINDのボディが不正です", http.StatusBadRequest)
+		return
+	}
+	// ボディがない場合はallpropとして扱う
+	allprop := !present || req.AllProp != nil
+	names := []xml.Name(req.Prop)
+	if allprop || req.PropName != nil {
+		names = defaultProps(kind)
+	}
+
+	ctx := r.Context()
+	var responses []response
+	switch kind {
+	case kindItem:
+		todo, err := h.service.Find(ctx, name)
+		if errors.Is(err, service.ErrCalDAVNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			h.serverError(w, r, err)
+			return
+		}
+		responses = append(responses, h.itemResponse(todo, names))
+	case kindCollection:
+		ctag, err := h.service.CTag(ctx)
+		if err != nil {
+			h.serverError(w, r, err)
+			return
+		}
+		responses = append(responses, h.collectionResponse(kindCollection, ctag, names))
+		if r.Header.Get("Depth") != "0" {
+			todos, err := h.service.List(ctx)
+			if err != nil {
+				h.serverError(w, r, err)
+				return
+			}
+			itemNames := names
+			if allprop || req.PropName != nil {
+				itemNames = defaultProps(kindItem)
+			}
+			for _, todo := range todos {
+				responses = append(responses, h.itemResponse(todo, itemNames))
+			}
+		}
+	default:
+		responses = append(responses, h.collectionResponse(kind, "", names))
+		if r.Header.Get("Depth") != "0" {
+			for _, child := range children(kind) {
+				childNames := names
+				if allprop || req.PropName != nil {
+					childNames = defaultProps(child)
+				}
+				ctag := ""
+				if child == kindCollection {
+					if ctag, err = h.service.CTag(ctx); err != nil {
+						h.serverError(w, r, err)
+						return
+					}
+				}
+				responses = append(responses, h.collectionResponse(child, ctag, childNames))
+			}
+		}
+	}
+
+	// propnameは値を含めず名前だけを返す
+	if req.PropName != nil {
+		for i := range responses {
+			for j := range responses[i].found {
+				responses[i].found[j].inner = ""
+			}
+		}
+	}
+	writeMultistatus(w, responses)
+}
+
+// report REPORT: コレクションに対するcalendar-query・calendar-multiget
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, kind resourceKind) {
+	if kind != kindCollection {
+		http.Error(w, "REPORTはTodoのコレクションに対してのみ使用できます", http.StatusForbidden)
+		return
+	}
+
+	var req reportRequest
+	if _, err := decodeXML(r.Body, &req); err != nil {
+		http.Error(w, "REPORTのボディが不正です", http.StatusBadRequest)
+		return
+	}
+	names := []xml.Name(req.Prop)
+	if len(names) == 0 {
+		names = defaultProps(kindItem)
+	}
+
+	ctx := r.Context()
+	var responses []response
+	switch {
+	case req.XMLName.Space == nsCalDAV && req.XMLName.Local == "calendar-multiget":
+		for _, href := range req.Hrefs {
+			name, ok := strings.CutPrefix(hrefPath(href), collectionPath)
+			if !ok || name == "" {
+				responses = append(responses, response{href: href, status: "404 Not Found"})
+				continue
+			}
+			todo, err := h.service.Find(ctx, name)
+			if errors.Is(err, service.ErrCalDAVNotFound) {
+				responses = append(responses, response{href: href, status: "404 Not Found"})
+				continue
+			}
+			if err != nil {
+				h.serverError(w, r, err)
+				return
+			}
+			responses = append(responses, h.itemResponse(todo, names))
+		}
+	case req.XMLName.Space == nsCalDAV && req.XMLName.Local == "calendar-query":
+		todos, err := h.service.List(ctx)
+		if err != nil {
+			h.serverError(w, r, err)
+			return
+		}
+		for _, todo := range todos {
+			if matchFilter(req.Filter, todo) {
+				responses = append(responses, h.itemResponse(todo, names))
+			}
+		}
+	default:
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, errorBody(nsDAV, "supported-report"))
+		return
+	}
+	writeMultistatus(w, responses)
+}
+
+// get GET/HEAD: TodoをiCalendar形式で返す
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, kind resourceKind, name string) {
+	if kind != kindItem {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	todo, err := h.service.Find(r.Context(), name)
+	if errors.Is(err, service.ErrCalDAVNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+
+	body := encodeVTodo(todo, service.CalDAVUID(todo))
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", etag(todo))
+	w.Header().Set("Last-Modified", todo.UpdatedAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		io.WriteString(w, body)
+	}
+}
+
+// put PUT: VTODOからTodoを作成・更新（If-Match・If-None-Matchによる競合検出に対応）
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, kind resourceKind, name string) {
+	if kind != kindItem {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	item, err := decodeVTodo(r.Body)
+	if errors.Is(err, ErrNoVTodo) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, errorBody(nsCalDAV, "supported-calendar-component"))
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.Summary = strings.TrimSpace(item.Summary)
+	if item.Summary == "" {
+		http.Error(w, "SUMMARY（タイトル）は必須です", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(item.Summary) > maxTitleLength {
+		http.Error(w, fmt.Sprintf("SUMMARY（タイトル）は%d文字以内で指定してください", maxTitleLength), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	todo, err := h.service.Find(ctx, name)
+	if err != nil && !errors.Is(err, service.ErrCalDAVNotFound) {
+		h.serverError(w, r, err)
+		return
+	}
+
+	if todo == nil {
+		if r.Header.Get("If-Match") != "" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		created, err := h.service.Create(ctx, name, item)
+		if err != nil {
+			h.serverError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(created))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if !preconditionsMet(r, todo) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	updated, err := h.service.Update(ctx, todo, item)
+	if err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(updated))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete DELETE: Todoを削除
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, kind resourceKind, name string) {
+	if kind != kindItem {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	todo, err := h.service.Find(ctx, name)
+	if errors.Is(err, service.ErrCalDAVNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+	if !preconditionsMet(r, todo) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if err := h.service.Delete(ctx, todo); err != nil {
+		h.serverError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// preconditionsMet 既存のリソースに対するIf-Match・If-None-Matchを評価
+func preconditionsMet(r *http.Request, todo *model.Todo) bool {
+	current := etag(todo)
+	if match := r.Header.Get("If-Match"); match != "" && match != "*" && !containsETag(match, current) {
+		return false
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && (noneMatch == "*" || containsETag(noneMatch, current)) {
+		return false
+	}
+	return true
+}
+
+// containsETag カンマ区切りのETagの一覧に指定したETagが含まれるか（弱いETagも比較する）
+func containsETag(list, tag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// hrefPath hrefからパスを取り出す（絶対URLで指定された場合に対応）
+func hrefPath(href string) string {
+	if i := strings.Index(href, "://"); i >= 0 {
+		rest := href[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	return href
+}
+
+// serverError 内部エラーを記録して500を返す
+func (h *Handler) serverError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "CalDAVリクエストの処理に失敗しました", "method", r.Method, "path", r.URL.Path, "error", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// writeMultistatus 207 Multi-Statusを返す
+func writeMultistatus(w http.ResponseWriter, responses []response) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, multistatus(responses))
+}
@@ -0,0 +1,235 @@
+package caldav
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"myapp/db/model"
+	"strings"
+	"time"
+)
+
+// iCalendarの日時形式
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDateTime    = "20060102T150405"
+	icalDate        = "20060102"
+)
+
+// ErrNoVTodo VCALENDARにVTODOが含まれていない
+var ErrNoVTodo = errors.New("VTODOが含まれていません")
+
+// encodeVTodo TodoをVTODOを1件含むVCALENDARに変換
+func encodeVTodo(todo *model.Todo, uid string) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//myapp//Todo API//JA")
+	writeLine(&b, "BEGIN:VTODO")
+	writeLine(&b, "UID:"+escapeText(uid))
+	writeLine(&b, "DTSTAMP:"+todo.UpdatedAt.UTC().Format(icalDateTimeUTC))
+	writeLine(&b, "CREATED:"+todo.CreatedAt.UTC().Format(icalDateTimeUTC))
+	writeLine(&b, "LAST-MODIFIED:"+todo.UpdatedAt.UTC().Format(icalDateTimeUTC))
+	writeLine(&b, "SUMMARY:"+escapeText(todo.Title))
+	if todo.Description != "" {
+		writeLine(&b, "DESCRIPTION:"+escapeText(todo.Description))
+	}
+	if todo.DueDate != nil && todo.DueAllDay {
+		writeLine(&b, "DUE;VALUE=DATE:"+todo.DueDate.UTC().Format(icalDate))
+	} else if todo.DueDate != nil {
+		writeLine(&b, "DUE:"+todo.DueDate.UTC().Format(icalDateTimeUTC))
+	}
+	writeLine(&b, fmt.Sprintf("PRIORITY:%d", priorityToICal(todo.Priority)))
+	if todo.Completed {
+		writeLine(&b, "STATUS:COMPLETED")
+		writeLine(&b, "COMPLETED:"+todo.UpdatedAt.UTC().Format(icalDateTimeUTC))
+		writeLine(&b, "PERCENT-COMPLETE:100")
+	} else {
+		writeLine(&b, "STATUS:NEEDS-ACTION")
+	}
+	writeLine(&b, "END:VTODO")
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// decodeVTodo VCALENDARから最初のVTODOを読み取る
+func decodeVTodo(r io.Reader) (*model.CalDAVTodo, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.CalDAVTodo
+	depth := 0 // VTODO内のネスト（VALARM等）の深さ
+	for _, line := range lines {
+		name, params, value := parseLine(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO") && todo == nil:
+			todo = &model.CalDAVTodo{Priority: model.PriorityMedium}
+			depth = 1
+			continue
+		case todo == nil || depth == 0:
+			continue
+		case name == "BEGIN":
+			depth++
+			continue
+		case name == "END":
+			depth--
+			continue
+		case depth > 1:
+			continue
+		}
+
+		switch name {
+		case "UID":
+			todo.UID = unescapeText(value)
+		case "SUMMARY":
+			todo.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			todo.Description = unescapeText(value)
+		case "STATUS":
+			todo.Completed = strings.EqualFold(value, "COMPLETED")
+		case "COMPLETED":
+			todo.Completed = true
+		case "PRIORITY":
+			todo.Priority = priorityFromICal(value)
+		case "DUE":
+			due, err := parseDateTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DUEの形式が不正です: %w", err)
+			}
+			todo.Due = &due
+			todo.DueAllDay = params["VALUE"] == "DATE" || len(value) == len(icalDate)
+		}
+	}
+
+	if todo == nil {
+		return nil, ErrNoVTodo
+	}
+	return todo, nil
+}
+
+// priorityToICal 優先度をiCalendarのPRIORITY（1が最も高い）に変換
+func priorityToICal(p model.Priority) int {
+	switch p {
+	case model.PriorityUrgent:
+		return 1
+	case model.PriorityHigh:
+		return 3
+	case model.PriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+// priorityFromICal iCalendarのPRIORITYを優先度に変換（0・不正な値は中）
+func priorityFromICal(value string) model.Priority {
+	var n int
+	fmt.Sscanf(value, "%d", &n)
+	switch {
+	case n == 1 || n == 2:
+		return model.PriorityUrgent
+	case n == 3 || n == 4:
+		return model.PriorityHigh
+	case n >= 6 && n <= 9:
+		return model.PriorityLow
+	default:
+		return model.PriorityMedium
+	}
+}
+
+// parseDateTime DATE-TIME（UTC・TZID付き・フローティング）とDATEを解釈する
+// TZIDが解決できない場合とフローティング時刻はサーバーのローカル時刻として扱う
+func parseDateTime(value string, params map[string]string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(icalDateTimeUTC, value)
+	}
+
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if params["VALUE"] == "DATE" || len(value) == len(icalDate) {
+		return time.ParseInLocation(icalDate, value, loc)
+	}
+	return time.ParseInLocation(icalDateTime, value, loc)
+}
+
+// unfold 折り返された行を連結して論理行の一覧にする
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine "NAME;PARAM=VALUE:値" 形式の行を分解（名前とパラメーター名は大文字にそろえる）
+func parseLine(line string) (string, map[string]string, string) {
+	// 値の中の ":" と区別するため、引用符の外にある最初の ":" で区切る
+	quoted := false
+	sep := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[sep+1:]
+}
+
+// escapeText TEXT値のエスケープ
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeText TEXT値のエスケープを戻す
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// writeLine 75オクテットごとに折り返して1行を書き込む（UTF-8の文字の途中では折り返さない）
+func writeLine(b *strings.Builder, line string) {
+	const limit = 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// utf8RuneStart バイトがUTF-8の文字の先頭か
+func utf8RuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
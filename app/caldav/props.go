@@ -0,0 +1,203 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"strings"
+)
+
+// プロパティ名
+var (
+	propResourceType          = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName           = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentUserPrincipal  = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL          = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propPrivilegeSet          = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet    = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propGetETag               = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType        = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propGetLastModified       = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propCalendarHomeSet       = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propSupportedComponentSet = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData          = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag               = xml.Name{Space: nsCS, Local: "getctag"}
+)
+
+// privileges ログインユーザーの権限（全Todoの読み書きが可能）
+const privileges = "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>" +
+	"<D:privilege><D:write-content/></D:privilege><D:privilege><D:bind/></D:privilege>" +
+	"<D:privilege><D:unbind/></D:privilege><D:privilege><D:read-current-user-privilege-set/></D:privilege>"
+
+// defaultProps allprop・propnameで返すプロパティ
+func defaultProps(kind resourceKind) []xml.Name {
+	switch kind {
+	case kindItem:
+		return []xml.Name{propResourceType, propGetETag, propGetContentType, propGetLastModified}
+	case kindCollection:
+		return []xml.Name{propResourceType, propDisplayName, propSupportedComponentSet, propGetCTag, propCurrentUserPrincipal}
+	case kindPrincipal:
+		return []xml.Name{propResourceType, propDisplayName, propPrincipalURL, propCalendarHomeSet, propCurrentUserPrincipal}
+	default:
+		return []xml.Name{propResourceType, propDisplayName, propCurrentUserPrincipal}
+	}
+}
+
+// children Depth: 1で返す直下のリソース（Todoのコレクションの中身は別途取得する）
+func children(kind resourceKind) []resourceKind {
+	switch kind {
+	case kindRoot:
+		return []resourceKind{kindPrincipal, kindHome}
+	case kindHome:
+		return []resourceKind{kindCollection}
+	default:
+		return nil
+	}
+}
+
+// collectionResponse Todo以外のリソースのプロパティ（ctagはTodoのコレクションの場合のみ使用）
+func (h *Handler) collectionResponse(kind resourceKind, ctag string, names []xml.Name) response {
+	res := response{href: hrefOf(kind, "")}
+	for _, name := range names {
+		inner, ok := "", true
+		switch name {
+		case propResourceType:
+			switch kind {
+			case kindPrincipal:
+				inner = "<D:principal/>"
+			case kindCollection:
+				inner = "<D:collection/><C:calendar/>"
+			default:
+				inner = "<D:collection/>"
+			}
+		case propDisplayName:
+			ok = kind == kindPrincipal || kind == kindCollection
+			inner = "Todo"
+		case propCurrentUserPrincipal, propPrincipalURL:
+			inner = "<D:href>" + principalPath + "</D:href>"
+		case propCalendarHomeSet:
+			inner = "<D:href>" + homePath + "</D:href>"
+		case propPrivilegeSet:
+			inner = privileges
+		case propSupportedComponentSet:
+			ok = kind == kindCollection
+			inner = `<C:comp name="VTODO"/>`
+		case propSupportedReportSet:
+			ok = kind == kindCollection
+			inner = "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+				"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>"
+		case propGetCTag:
+			ok = kind == kindCollection
+			inner = escapeXML(ctag)
+		default:
+			ok = false
+		}
+		if ok {
+			res.found = append(res.found, property{name: name, inner: inner})
+		} else {
+			res.notFound = append(res.notFound, name)
+		}
+	}
+	return res
+}
+
+// itemResponse Todoのプロパティ
+func (h *Handler) itemResponse(todo *model.Todo, names []xml.Name) response {
+	res := response{href: hrefOf(kindItem, service.CalDAVName(todo))}
+	for _, name := range names {
+		inner, ok := "", true
+		switch name {
+		case propResourceType:
+			// 空の要素（コレクションではない）
+		case propGetETag:
+			inner = escapeXML(etag(todo))
+		case propGetContentType:
+			inner = "text/calendar; charset=utf-8; component=VTODO"
+		case propGetLastModified:
+			inner = todo.UpdatedAt.UTC().Format(http.TimeFormat)
+		case propCalendarData:
+			inner = escapeXML(encodeVTodo(todo, service.CalDAVUID(todo)))
+		case propCurrentUserPrincipal:
+			inner = "<D:href>" + principalPath + "</D:href>"
+		case propPrivilegeSet:
+			inner = privileges
+		default:
+			ok = false
+		}
+		if ok {
+			res.found = append(res.found, property{name: name, inner: inner})
+		} else {
+			res.notFound = append(res.notFound, name)
+		}
+	}
+	return res
+}
+
+// matchFilter calendar-queryのフィルターにTodoが一致するか
+// VTODOのCOMPLETED・STATUSに対するprop-filterに対応し、time-range等のその他の条件は一致として扱う
+func matchFilter(filter *compFilter, todo *model.Todo) bool {
+	if filter == nil {
+		return true
+	}
+	if !strings.EqualFold(filter.Name, "VCALENDAR") {
+		return false
+	}
+	for _, comp := range filter.CompFilters {
+		isTodo := strings.EqualFold(comp.Name, "VTODO")
+		if comp.IsNotDefined != nil {
+			if isTodo {
+				return false
+			}
+			continue
+		}
+		if !isTodo {
+			return false
+		}
+		for _, prop := range comp.PropFilters {
+			if !matchPropFilter(prop, todo) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchPropFilter prop-filterにTodoが一致するか
+func matchPropFilter(filter propFilter, todo *model.Todo) bool {
+	var value string
+	defined := true
+	switch strings.ToUpper(filter.Name) {
+	case "COMPLETED":
+		defined = todo.Completed
+	case "STATUS":
+		value = "NEEDS-ACTION"
+		if todo.Completed {
+			value = "COMPLETED"
+		}
+	case "SUMMARY":
+		value = todo.Title
+	case "DESCRIPTION":
+		value = todo.Description
+		defined = value != ""
+	case "DUE":
+		defined = todo.DueDate != nil
+	default:
+		return true
+	}
+
+	if filter.IsNotDefined != nil {
+		return !defined
+	}
+	if !defined {
+		return false
+	}
+	if filter.TextMatch != nil {
+		matched := strings.Contains(strings.ToLower(value), strings.ToLower(strings.TrimSpace(filter.TextMatch.Value)))
+		if filter.TextMatch.NegateCondition == "yes" {
+			return !matched
+		}
+		return matched
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// XML名前空間
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+// propNames <D:prop> の子要素の名前の一覧
+type propNames []xml.Name
+
+// UnmarshalXML 子要素の名前だけを読み取る（値は無視する）
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// propfindRequest PROPFINDのリクエストボディ
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+// reportRequest REPORT（calendar-query・calendar-multiget）のリクエストボディ
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    propNames   `xml:"DAV: prop"`
+	Hrefs   []string    `xml:"DAV: href"`
+	Filter  *compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+// compFilter calendar-queryのcomp-filter
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *struct{}    `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// propFilter calendar-queryのprop-filter
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// textMatch prop-filterのtext-match（大文字小文字を区別しない部分一致）
+type textMatch struct {
+	Value           string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+// decodeXML リクエストボディのXMLを読み取る（空のボディはfalseを返す）
+func decodeXML(r io.Reader, v interface{}) (bool, error) {
+	err := xml.NewDecoder(r).Decode(v)
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// property 応答に含めるプロパティ（innerは要素の中身のXML）
+type property struct {
+	name  xml.Name
+	inner string
+}
+
+// propstat 応答に含めるresponse要素1件分
+type response struct {
+	href     string
+	found    []property
+	notFound []xml.Name
+	// status プロパティを伴わない応答のステータス（multigetで存在しないリソース等）
+	status string
+}
+
+// multistatus 207 Multi-Statusのボディを組み立てる
+func multistatus(responses []response) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CS="http://calendarserver.org/ns/">`)
+	for _, res := range responses {
+		b.WriteString("<D:response><D:href>")
+		b.WriteString(escapeXML(res.href))
+		b.WriteString("</D:href>")
+		if res.status != "" {
+			b.WriteString("<D:status>HTTP/1.1 " + res.status + "</D:status>")
+		}
+		if len(res.found) > 0 {
+			b.WriteString("<D:propstat><D:prop>")
+			for _, p := range res.found {
+				writeElement(&b, p.name, p.inner)
+			}
+			b.WriteString("</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>")
+		}
+		if len(res.notFound) > 0 {
+			b.WriteString("<D:propstat><D:prop>")
+			for _, name := range res.notFound {
+				writeElement(&b, name, "")
+			}
+			b.WriteString("</D:prop><D:status>HTTP/1.1 404 Not Found</D:status></D:propstat>")
+		}
+		b.WriteString("</D:response>")
+	}
+	b.WriteString("</D:multistatus>")
+	return b.String()
+}
+
+// writeElement 要素自身に名前空間を宣言して書き込む（クライアントが指定した未知の名前空間にも対応するため）
+func writeElement(b *strings.Builder, name xml.Name, inner string) {
+	b.WriteString("<" + name.Local + ` xmlns="` + escapeXML(name.Space) + `"`)
+	if inner == "" {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">" + inner + "</" + name.Local + ">")
+}
+
+// escapeXML 文字データのエスケープ
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// errorBody 事前条件違反を表すエラーボディ
+func errorBody(space, condition string) string {
+	return xml.Header + `<D:error xmlns:D="DAV:"><` + condition + ` xmlns="` + space + `"/></D:error>`
+}
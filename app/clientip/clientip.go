@@ -0,0 +1,143 @@
+// Package clientip リクエスト元のクライアントIPを、信頼するリバースプロキシのヘッダーを考慮して求める
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// FromRequest リクエスト元のクライアントIPを取得
+// trustProxyがtrueで、接続元がtrustedProxies（CIDRまたは単一IP）のいずれかに含まれる場合のみ X-Forwarded-For・X-Real-IP を参照する。
+// X-Forwarded-Forは右（接続元に近い側）から辿り、信頼するプロキシでない最初のアドレスを返す（左側はクライアントが自由に付けられるため信頼しない）。
+// UNIXドメインソケットの接続元は同じホストのリバースプロキシとして信頼する
+func FromRequest(r *http.Request, trustProxy bool, trustedProxies []string) string {
+	peer := remoteIP(r.RemoteAddr)
+	if !trustProxy {
+		return peer
+	}
+
+	trusted := parsedProxies(trustedProxies)
+	peerAddr, err := netip.ParseAddr(peer)
+	if err == nil && !contains(trusted, peerAddr) {
+		return peer
+	}
+
+	hops := forwardedFor(r.Header)
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+		return peer
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// 解析できないアドレスより左は信頼できないため、直前に辿ったアドレスをクライアントとする
+			break
+		}
+		addr = addr.Unmap()
+		client = addr.String()
+		if !contains(trusted, addr) {
+			break
+		}
+	}
+	return client
+}
+
+// remoteIP 接続元のアドレスからポートを除く
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().String()
+	}
+	return host
+}
+
+// forwardedFor X-Forwarded-Forの全てのヘッダーのアドレスを左から順に返す（ポート付きのアドレスはポートを除く）
+func forwardedFor(header http.Header) []string {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hop = strings.TrimSpace(hop)
+			if hop == "" {
+				continue
+			}
+			if host, _, err := net.SplitHostPort(hop); err == nil {
+				hop = host
+			}
+			hops = append(hops, strings.Trim(hop, "[]"))
+		}
+	}
+	return hops
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedProxies 直近に解析した信頼するプロキシのリスト（設定が変わった場合のみ再解析する）
+var cachedProxies atomic.Pointer[struct {
+	raw    []string
+	parsed []netip.Prefix
+}]
+
+// parsedProxies 信頼するプロキシのリストを解析する（不正なエントリは設定の検証で弾かれるため読み飛ばす）
+func parsedProxies(entries []string) []netip.Prefix {
+	if c := cachedProxies.Load(); c != nil && slices.Equal(c.raw, entries) {
+		return c.parsed
+	}
+	parsed, _ := ParsePrefixes(entries)
+	cachedProxies.Store(&struct {
+		raw    []string
+		parsed []netip.Prefix
+	}{slices.Clone(entries), parsed})
+	return parsed
+}
+
+// ParsePrefixes CIDRまたは単一IPアドレスのリストを解析する（単一IPは/32・/128として扱う。不正なエントリがあれば最初のエラーを返し、残りは読み飛ばす）
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	var firstErr error
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("CIDRの形式が不正です: %q", entry)
+				}
+				continue
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("IPアドレスの形式が不正です: %q", entry)
+			}
+			continue
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, firstErr
+}
@@ -0,0 +1,292 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"myapp/config"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// 対応するエンコーディング（優先度順）
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// compressibleTypes 圧縮対象とするContent-Type（前方一致）
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"application/yaml",
+	"application/openmetrics-text",
+	"image/svg+xml",
+	"text/",
+}
+
+// Middleware Accept-Encodingに応じてレスポンスをbrotliまたはgzipで圧縮するミドルウェア
+// 本文が最小サイズ未満のレスポンスや圧縮済み・圧縮に向かないContent-Typeは圧縮しない
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Current().Compression
+		if !cfg.Enabled || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiate(r.Header.Get("Accept-Encoding"), cfg.Brotli)
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			level:          cfg.Level,
+			minSize:        cfg.MinSize,
+		}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiate Accept-Encodingから使用するエンコーディングを決定（q=0は拒否として扱う）
+func negotiate(header string, allowBrotli bool) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[name] = q
+	}
+
+	qualityOf := func(name string) float64 {
+		if q, ok := accepted[name]; ok {
+			return q
+		}
+		if q, ok := accepted["*"]; ok {
+			return q
+		}
+		return 0
+	}
+
+	best, bestQ := "", 0.0
+	candidates := []string{encodingGzip}
+	if allowBrotli {
+		candidates = []string{encodingBrotli, encodingGzip}
+	}
+	for _, name := range candidates {
+		if q := qualityOf(name); q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressible Content-Typeが圧縮対象か
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 最小サイズに達するまで本文をバッファし、達した時点で圧縮を開始するResponseWriter
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	level    int
+	minSize  int
+
+	status      int
+	buf         bytes.Buffer
+	encoder     io.WriteCloser
+	passthrough bool
+	wroteHeader bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(b)
+	}
+
+	// 圧縮できないレスポンスはそのまま書き出す
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || !compressible(header.Get("Content-Type")) ||
+		cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		cw.startPassthrough()
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buf.Write(b)
+	if cw.buf.Len() >= cw.minSize {
+		if err := cw.startEncoder(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// startPassthrough 圧縮せずにバッファ済みの本文を書き出す
+func (cw *compressWriter) startPassthrough() error {
+	cw.passthrough = true
+	cw.writeHeader()
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	_, err := cw.ResponseWriter.Write(cw.buf.Bytes())
+	cw.buf.Reset()
+	return err
+}
+
+// startEncoder ヘッダーを圧縮用に書き換えてバッファ済みの本文から圧縮を開始する
+func (cw *compressWriter) startEncoder() error {
+	header := cw.Header()
+	header.Set("Content-Encoding", cw.encoding)
+	header.Del("Content-Length")
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		// 圧縮後は内容がバイト単位で異なるため弱いETagにする
+		header.Set("ETag", "W/"+etag)
+	}
+	cw.writeHeader()
+
+	cw.encoder = newEncoder(cw.encoding, cw.level, cw.ResponseWriter)
+	_, err := cw.encoder.Write(cw.buf.Bytes())
+	cw.buf.Reset()
+	return err
+}
+
+func (cw *compressWriter) writeHeader() {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// Flush 圧縮中のデータを送出する（ストリーミングレスポンス用）
+func (cw *compressWriter) Flush() {
+	if cw.encoder == nil && !cw.passthrough && cw.status != 0 {
+		cw.startPassthrough()
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close 圧縮を終了する（最小サイズに達しなかった本文は非圧縮で書き出す）
+func (cw *compressWriter) Close() error {
+	if cw.encoder != nil {
+		err := cw.encoder.Close()
+		releaseEncoder(cw.encoding, cw.level, cw.encoder)
+		cw.encoder = nil
+		return err
+	}
+	if cw.status == 0 {
+		// ハンドラーが何も書き込まなかった場合は後続の既定処理に任せる
+		return nil
+	}
+	if !cw.passthrough {
+		return cw.startPassthrough()
+	}
+	return nil
+}
+
+// Unwrap http.ResponseController から元のResponseWriterを参照できるようにする
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// エンコーダーはレベルごとにプールして再利用する
+var (
+	gzipPools   sync.Map // map[int]*sync.Pool
+	brotliPools sync.Map // map[int]*sync.Pool
+)
+
+func poolFor(pools *sync.Map, level int, create func() any) *sync.Pool {
+	pool, _ := pools.LoadOrStore(level, &sync.Pool{New: create})
+	return pool.(*sync.Pool)
+}
+
+// newEncoder エンコーディングとレベルに応じた圧縮ライターを取得
+// levelが0の場合は各形式の既定レベルを使う
+func newEncoder(encoding string, level int, w io.Writer) io.WriteCloser {
+	if encoding == encodingBrotli {
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		bw := poolFor(&brotliPools, level, func() any { return brotli.NewWriterLevel(nil, level) }).Get().(*brotli.Writer)
+		bw.Reset(w)
+		return bw
+	}
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gw := poolFor(&gzipPools, level, func() any {
+		gw, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			gw = gzip.NewWriter(nil)
+		}
+		return gw
+	}).Get().(*gzip.Writer)
+	gw.Reset(w)
+	return gw
+}
+
+// releaseEncoder 使い終わった圧縮ライターをプールへ戻す
+func releaseEncoder(encoding string, level int, encoder io.WriteCloser) {
+	switch e := encoder.(type) {
+	case *brotli.Writer:
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		poolFor(&brotliPools, level, nil).Put(e)
+	case *gzip.Writer:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		poolFor(&gzipPools, level, nil).Put(e)
+	}
+}
@@ -0,0 +1,1242 @@
+package config
+
+import (
+	"fmt"
+	"myapp/db"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile 設定ファイルを指定しなかった場合に読み込むファイル（存在する場合のみ）
+const DefaultFile = "config.yaml"
+
+// Config アプリケーション全体の設定
+// 優先順位: デフォルト値 → 設定ファイル → 環境変数
+type Config struct {
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	Database    db.DatabaseConfig `yaml:"database" toml:"database"`
+	Startup     StartupConfig     `yaml:"startup" toml:"startup"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown" toml:"shutdown"`
+	Log         LogConfig         `yaml:"log" toml:"log"`
+	AccessLog   AccessLogConfig   `yaml:"access_log" toml:"access_log"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+	PanicAlert  PanicAlertConfig  `yaml:"panic_alert" toml:"panic_alert"`
+	CORS        CORSConfig        `yaml:"cors" toml:"cors"`
+	LLM         LLMConfig         `yaml:"llm" toml:"llm"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit"`
+	Security    SecurityConfig    `yaml:"security_headers" toml:"security_headers"`
+	BodyLimit   BodyLimitConfig   `yaml:"body_limit" toml:"body_limit"`
+	Timeout     TimeoutConfig     `yaml:"timeout" toml:"timeout"`
+	Compression CompressionConfig `yaml:"compression" toml:"compression"`
+	HTTPCache   HTTPCacheConfig   `yaml:"http_cache" toml:"http_cache"`
+	CSRF        CSRFConfig        `yaml:"csrf" toml:"csrf"`
+	IPFilter    IPFilterConfig    `yaml:"ip_filter" toml:"ip_filter"`
+	Admin       AdminConfig       `yaml:"admin" toml:"admin"`
+	Maintenance MaintenanceConfig `yaml:"maintenance" toml:"maintenance"`
+	Sanitize    SanitizeConfig    `yaml:"sanitize" toml:"sanitize"`
+	Validation  ValidationConfig  `yaml:"validation" toml:"validation"`
+	Locale      LocaleConfig      `yaml:"locale" toml:"locale"`
+	Secrets     SecretsConfig     `yaml:"secrets" toml:"secrets"`
+	Webhook     WebhookConfig     `yaml:"webhook" toml:"webhook"`
+	Session     SessionConfig     `yaml:"session" toml:"session"`
+	Replay      ReplayConfig      `yaml:"replay" toml:"replay"`
+	Cache       CacheConfig       `yaml:"cache" toml:"cache"`
+	List        ListConfig        `yaml:"list" toml:"list"`
+	Events      EventsConfig      `yaml:"events" toml:"events"`
+	Notify      NotifyConfig      `yaml:"notify" toml:"notify"`
+	Telegram    TelegramConfig    `yaml:"telegram" toml:"telegram"`
+	IMAP        IMAPConfig        `yaml:"imap" toml:"imap"`
+	Storage     StorageConfig     `yaml:"storage" toml:"storage"`
+	Calendar    CalendarConfig    `yaml:"calendar" toml:"calendar"`
+	MSTodo      MSTodoConfig      `yaml:"microsoft_todo" toml:"microsoft_todo"`
+	Scheduler   SchedulerConfig   `yaml:"scheduler" toml:"scheduler"`
+	Recurrence  RecurrenceConfig  `yaml:"recurrence" toml:"recurrence"`
+	Escalation  EscalationConfig  `yaml:"escalation" toml:"escalation"`
+	Export      ExportConfig      `yaml:"export" toml:"export"`
+	Stale       StaleConfig       `yaml:"stale" toml:"stale"`
+	Queue       QueueConfig       `yaml:"queue" toml:"queue"`
+	Bulk        BulkConfig        `yaml:"bulk" toml:"bulk"`
+	CalDAV      CalDAVConfig      `yaml:"caldav" toml:"caldav"`
+	GitHub      GitHubConfig      `yaml:"github" toml:"github"`
+	Jira        JiraConfig        `yaml:"jira" toml:"jira"`
+	Notion      NotionConfig      `yaml:"notion" toml:"notion"`
+	QuickAdd    QuickAddConfig    `yaml:"quick_add" toml:"quick_add"`
+	Zapier      ZapierConfig      `yaml:"zapier" toml:"zapier"`
+	IFTTT       IFTTTConfig       `yaml:"ifttt" toml:"ifttt"`
+}
+
+// ServerConfig HTTPサーバーの設定
+type ServerConfig struct {
+	Port int `yaml:"port" toml:"port" env:"PORT"`
+	// BindAddress 待ち受けるアドレス（127.0.0.1でローカルのみ、0.0.0.0でIPv4の全インターフェース。空の場合はIPv4/IPv6の全インターフェース）
+	BindAddress string `yaml:"bind_address" toml:"bind_address" env:"BIND_ADDRESS"`
+	// ReadTimeout / WriteTimeout リクエストの読み込み・レスポンスの書き込みの上限（0で無制限）
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	// IdleTimeout Keep-Aliveの接続で次のリクエストを待つ上限（0の場合はReadTimeoutと同じ）
+	IdleTimeout time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	// UnixSocket 指定時はTCPのポートの代わりにこのパスのUnixドメインソケットで待ち受ける（リバースプロキシの背後で動かす場合）
+	UnixSocket string `yaml:"unix_socket" toml:"unix_socket" env:"UNIX_SOCKET"`
+	// UnixSocketMode ソケットファイルのパーミッション（8進数。リバースプロキシのユーザー・グループが書き込める値にする）
+	UnixSocketMode string `yaml:"unix_socket_mode" toml:"unix_socket_mode" env:"UNIX_SOCKET_MODE"`
+	// TLSCertFile / TLSKeyFile 証明書ファイルを指定してHTTPSで配信する
+	TLSCertFile string `yaml:"tls_cert_file" toml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" toml:"tls_key_file" env:"TLS_KEY_FILE"`
+	// AutocertDomains Let's Encryptで証明書を自動取得するドメイン（指定時は証明書ファイルより優先）
+	AutocertDomains  []string `yaml:"autocert_domains" toml:"autocert_domains" env:"AUTOCERT_DOMAINS"`
+	AutocertEmail    string   `yaml:"autocert_email" toml:"autocert_email" env:"AUTOCERT_EMAIL"`
+	AutocertCacheDir string   `yaml:"autocert_cache_dir" toml:"autocert_cache_dir" env:"AUTOCERT_CACHE_DIR"`
+	// TLSClientCAFile クライアント証明書を検証するCA（PEM）。指定時はmTLSを有効にする
+	TLSClientCAFile string `yaml:"tls_client_ca_file" toml:"tls_client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	// TLSClientAuth クライアント証明書の要求方法（require / verify_if_given / request / none）
+	TLSClientAuth string `yaml:"tls_client_auth" toml:"tls_client_auth" env:"TLS_CLIENT_AUTH"`
+	// TLSReloadInterval 証明書・クライアントCAファイルの更新を確認する間隔（0で再読み込みしない）
+	TLSReloadInterval time.Duration `yaml:"tls_reload_interval" toml:"tls_reload_interval" env:"TLS_RELOAD_INTERVAL"`
+	// HTTPRedirectPort HTTPS配信時にHTTPS へリダイレクトするHTTPポート（0で無効。autocertではHTTP-01チャレンジにも使用）
+	HTTPRedirectPort int `yaml:"http_redirect_port" toml:"http_redirect_port" env:"HTTP_REDIRECT_PORT"`
+	// PathNormalize APIのパスの末尾・重複したスラッシュの扱い（rewrite: 書き換えて処理 / redirect: 308でリダイレクト / off: 正規化しない）
+	PathNormalize string `yaml:"path_normalize" toml:"path_normalize" env:"PATH_NORMALIZE"`
+}
+
+// TLSEnabled HTTPSで配信するか
+func (c ServerConfig) TLSEnabled() bool {
+	return len(c.AutocertDomains) > 0 || c.TLSCertFile != ""
+}
+
+// StartupConfig 起動時に依存サービス（DB・Redis・NATS）への接続を待つ設定
+type StartupConfig struct {
+	// WaitTimeout 接続できるまで再試行する時間（0で再試行せず、接続できなければ即座に終了する）
+	WaitTimeout time.Duration `yaml:"wait_timeout" toml:"wait_timeout" env:"STARTUP_WAIT_TIMEOUT"`
+	// BackoffBase・BackoffMax 再試行までの間隔（失敗のたびに倍増し、BackoffMaxで頭打ち）
+	BackoffBase time.Duration `yaml:"backoff_base" toml:"backoff_base" env:"STARTUP_BACKOFF_BASE"`
+	BackoffMax  time.Duration `yaml:"backoff_max" toml:"backoff_max" env:"STARTUP_BACKOFF_MAX"`
+}
+
+// ShutdownConfig SIGTERM受信後のグレースフルシャットダウンの設定（シャットダウンの開始時に参照するため、ホットリロードで反映される）
+type ShutdownConfig struct {
+	// Timeout HTTPサーバー・バックグラウンドワーカー・テレメトリ送信・DB接続を順に停止する処理全体のタイムアウト
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+	// DrainDelay readinessを失敗させてからHTTPサーバーを停止するまでの待機時間（ロードバランサーが切り離すまでの猶予。0で待たない）
+	DrainDelay time.Duration `yaml:"drain_delay" toml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+// LogConfig ログの設定
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+// AccessLogConfig アクセスログの出力設定（LOG_FORMAT・LOG_LEVELに関わらずJSONで出力する）
+type AccessLogConfig struct {
+	// Enabled アクセスログを出力するか
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"ACCESS_LOG_ENABLED"`
+	// ExcludePaths 出力しないパス（ヘルスチェック等、完全一致）
+	ExcludePaths []string `yaml:"exclude_paths" toml:"exclude_paths" env:"ACCESS_LOG_EXCLUDE_PATHS"`
+	// SampleRate 成功レスポンス（4xx/5xx以外）を出力する割合（0〜1）
+	SampleRate float64 `yaml:"sample_rate" toml:"sample_rate" env:"ACCESS_LOG_SAMPLE_RATE"`
+}
+
+// TracingConfig 分散トレーシングのスパンの送信先
+type TracingConfig struct {
+	// Endpoint OTLP/HTTPのエンドポイント（例: http://otel-collector:4318。空の場合はスパンを送信せず、トレースIDの伝播のみ行う）
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// ServiceName トレースに付与するサービス名
+	ServiceName string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// PanicAlertConfig リクエストの処理中のパニックを通知するアラートの設定
+type PanicAlertConfig struct {
+	// WebhookURL パニックの内容をJSONでPOSTするWebhook（ジョブキュー経由で送信する）
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"PANIC_ALERT_WEBHOOK_URL"`
+	// SlackWebhookURL SlackのIncoming Webhook
+	SlackWebhookURL string `yaml:"slack_webhook_url" toml:"slack_webhook_url" env:"PANIC_ALERT_SLACK_WEBHOOK_URL"`
+	// Cooldown アラートの最小送信間隔（この間に発生したパニックはログのみ出力する）
+	Cooldown time.Duration `yaml:"cooldown" toml:"cooldown" env:"PANIC_ALERT_COOLDOWN"`
+}
+
+// CORSConfig CORSの設定
+type CORSConfig struct {
+	// AllowedOrigins 許可するオリジン（"*" は全て、"https://*.example.com" はサブドメイン）
+	AllowedOrigins   []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `yaml:"allowed_methods" toml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `yaml:"exposed_headers" toml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	// MaxAge プリフライト結果のキャッシュ秒数（0の場合はヘッダーを付与しない）
+	MaxAge int `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// LLMConfig LLMプロバイダーの設定
+type LLMConfig struct {
+	Enabled  bool          `yaml:"enabled" toml:"enabled" env:"LLM_ENABLED"`
+	Provider string        `yaml:"provider" toml:"provider" env:"LLM_PROVIDER"`
+	BaseURL  string        `yaml:"base_url" toml:"base_url" env:"LLM_BASE_URL"`
+	APIKey   string        `yaml:"api_key" toml:"api_key" env:"LLM_API_KEY"`
+	Model    string        `yaml:"model" toml:"model" env:"LLM_MODEL"`
+	Timeout  time.Duration `yaml:"timeout" toml:"timeout" env:"LLM_TIMEOUT"`
+}
+
+// RateLimitConfig レートリミットの設定
+type RateLimitConfig struct {
+	Enabled           bool    `yaml:"enabled" toml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	RequestsPerSecond float64 `yaml:"requests_per_second" toml:"requests_per_second" env:"RATE_LIMIT_RPS"`
+	Burst             int     `yaml:"burst" toml:"burst" env:"RATE_LIMIT_BURST"`
+	// Backend 制限状態の保存先（memory: プロセス内のトークンバケット / redis: Redisのスライディングウィンドウ）
+	Backend string `yaml:"backend" toml:"backend" env:"RATE_LIMIT_BACKEND"`
+	// RedisAddr backend=redis の接続先（未設定の場合は REDIS_ADDR）
+	RedisAddr string `yaml:"redis_addr" toml:"redis_addr" env:"RATE_LIMIT_REDIS_ADDR"`
+	// FailOpen Redis障害時にリクエストを通すか（falseの場合は503で拒否）
+	FailOpen bool `yaml:"fail_open" toml:"fail_open" env:"RATE_LIMIT_FAIL_OPEN"`
+	// TrustProxy X-Forwarded-For / X-Real-IP をクライアントIPとして扱うか（リバースプロキシ配下で有効にする）
+	TrustProxy bool `yaml:"trust_proxy" toml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY"`
+	// TrustedProxies ヘッダーを信頼するリバースプロキシのアドレス（CIDRまたは単一IP。X-Forwarded-Forを右から辿り、これに含まれない最初のアドレスをクライアントIPとする）
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"RATE_LIMIT_TRUSTED_PROXIES"`
+	// ExcludePaths 制限しないパス（完全一致）
+	ExcludePaths []string `yaml:"exclude_paths" toml:"exclude_paths" env:"RATE_LIMIT_EXCLUDE_PATHS"`
+	// Paths パスごとの制限（先頭一致、最も長く一致したものを使用）
+	Paths []PathRateLimit `yaml:"paths" toml:"paths"`
+}
+
+// PathRateLimit パスごとのレートリミット
+type PathRateLimit struct {
+	Prefix            string  `yaml:"prefix" toml:"prefix"`
+	RequestsPerSecond float64 `yaml:"requests_per_second" toml:"requests_per_second"`
+	Burst             int     `yaml:"burst" toml:"burst"`
+}
+
+// SecurityConfig セキュリティヘッダーの設定（空文字の項目はヘッダーを付与しない）
+type SecurityConfig struct {
+	Enabled            bool   `yaml:"enabled" toml:"enabled" env:"SECURITY_HEADERS_ENABLED"`
+	ContentTypeOptions string `yaml:"content_type_options" toml:"content_type_options" env:"SECURITY_CONTENT_TYPE_OPTIONS"`
+	FrameOptions       string `yaml:"frame_options" toml:"frame_options" env:"SECURITY_FRAME_OPTIONS"`
+	ReferrerPolicy     string `yaml:"referrer_policy" toml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+	// HSTSMaxAge Strict-Transport-Securityのmax-age秒数（0の場合は付与しない。HTTPS配信時のみ有効にする）
+	HSTSMaxAge            int  `yaml:"hsts_max_age" toml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool `yaml:"hsts_include_subdomains" toml:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+	HSTSPreload           bool `yaml:"hsts_preload" toml:"hsts_preload" env:"SECURITY_HSTS_PRELOAD"`
+	// ContentSecurityPolicy APIレスポンスに付与するCSP
+	ContentSecurityPolicy string `yaml:"content_security_policy" toml:"content_security_policy" env:"SECURITY_CSP"`
+	// DocsContentSecurityPolicy /docs（外部のスクリプト・スタイルを読み込む）に付与するCSP
+	DocsContentSecurityPolicy string `yaml:"docs_content_security_policy" toml:"docs_content_security_policy" env:"SECURITY_DOCS_CSP"`
+}
+
+// BodyLimitConfig リクエストボディサイズの上限設定
+type BodyLimitConfig struct {
+	// MaxBytes 既定の上限（バイト、0で無制限）
+	MaxBytes int64 `yaml:"max_bytes" toml:"max_bytes" env:"BODY_LIMIT_MAX_BYTES"`
+	// Paths パスごとの上限（先頭一致、最も長く一致したものを使用）
+	Paths []PathBodyLimit `yaml:"paths" toml:"paths"`
+}
+
+// PathBodyLimit パスごとのリクエストボディサイズの上限
+type PathBodyLimit struct {
+	Prefix   string `yaml:"prefix" toml:"prefix"`
+	MaxBytes int64  `yaml:"max_bytes" toml:"max_bytes"`
+}
+
+// TimeoutConfig リクエストタイムアウトの設定
+type TimeoutConfig struct {
+	// Default 既定のタイムアウト（0で無制限）
+	Default time.Duration `yaml:"default" toml:"default" env:"REQUEST_TIMEOUT"`
+	// Paths パスごとのタイムアウト（先頭一致、最も長く一致したものを使用）
+	Paths []PathTimeout `yaml:"paths" toml:"paths"`
+}
+
+// PathTimeout パスごとのリクエストタイムアウト
+type PathTimeout struct {
+	Prefix  string        `yaml:"prefix" toml:"prefix"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// CompressionConfig レスポンス圧縮の設定
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"COMPRESSION_ENABLED"`
+	// MinSize 圧縮する本文の最小サイズ（バイト）
+	MinSize int `yaml:"min_size" toml:"min_size" env:"COMPRESSION_MIN_SIZE"`
+	// Level 圧縮レベル（1〜9、0で各形式の既定値）
+	Level int `yaml:"level" toml:"level" env:"COMPRESSION_LEVEL"`
+	// Brotli brotliを使用するか（falseの場合はgzipのみ）
+	Brotli bool `yaml:"brotli" toml:"brotli" env:"COMPRESSION_BROTLI"`
+}
+
+// HTTPCacheConfig 一覧・統計のGETレスポンスのHTTPキャッシュ制御（Cache-Control・ETag）の設定
+type HTTPCacheConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"HTTP_CACHE_ENABLED"`
+	// MaxAge Todoの一覧をクライアントが再検証せずに再利用できる期間（0の場合は毎回ETagで再検証させる）
+	MaxAge time.Duration `yaml:"max_age" toml:"max_age" env:"HTTP_CACHE_MAX_AGE"`
+	// StatsMaxAge 統計をクライアントが再利用できる期間
+	StatsMaxAge time.Duration `yaml:"stats_max_age" toml:"stats_max_age" env:"HTTP_CACHE_STATS_MAX_AGE"`
+	// RevalidateTTL 一覧のETagを検証済みとして覚えておく期間（この間はTodoの更新イベントがなければDBを読まずに304を返す。0で無効）
+	// 検証済みのETagと無効化はプロセス内だけで扱うため、単一インスタンスで起動する場合のみ指定する
+	RevalidateTTL time.Duration `yaml:"revalidate_ttl" toml:"revalidate_ttl" env:"HTTP_CACHE_REVALIDATE_TTL"`
+}
+
+// CSRFConfig Cookie認証時のCSRF保護の設定
+type CSRFConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"CSRF_ENABLED"`
+	// CookieName / HeaderName トークンを保存するCookieと、リクエスト時に送り返すヘッダー
+	CookieName string `yaml:"cookie_name" toml:"cookie_name" env:"CSRF_COOKIE_NAME"`
+	HeaderName string `yaml:"header_name" toml:"header_name" env:"CSRF_HEADER_NAME"`
+	// SessionCookie 認証に使うセッションCookie（このCookieを持たないリクエストは検証を免除、空の場合は常に検証）
+	SessionCookie string        `yaml:"session_cookie" toml:"session_cookie" env:"CSRF_SESSION_COOKIE"`
+	CookieDomain  string        `yaml:"cookie_domain" toml:"cookie_domain" env:"CSRF_COOKIE_DOMAIN"`
+	CookieSecure  bool          `yaml:"cookie_secure" toml:"cookie_secure" env:"CSRF_COOKIE_SECURE"`
+	CookieMaxAge  time.Duration `yaml:"cookie_max_age" toml:"cookie_max_age" env:"CSRF_COOKIE_MAX_AGE"`
+	// SameSite Cookieの SameSite 属性（lax / strict / none）
+	SameSite string `yaml:"same_site" toml:"same_site" env:"CSRF_SAME_SITE"`
+	// ExemptHeaders いずれかが付与されたリクエストは検証を免除する（Authorizationは常に免除）
+	ExemptHeaders []string `yaml:"exempt_headers" toml:"exempt_headers" env:"CSRF_EXEMPT_HEADERS"`
+	ExcludePaths  []string `yaml:"exclude_paths" toml:"exclude_paths" env:"CSRF_EXCLUDE_PATHS"`
+}
+
+// IPFilterConfig IPアドレスの許可・拒否リストの設定（CIDRまたは単一IPで指定）
+type IPFilterConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"IP_FILTER_ENABLED"`
+	// TrustProxy X-Forwarded-For / X-Real-IP をクライアントIPとして信頼するか
+	TrustProxy bool `yaml:"trust_proxy" toml:"trust_proxy" env:"IP_FILTER_TRUST_PROXY"`
+	// TrustedProxies ヘッダーを信頼するリバースプロキシのアドレス（CIDRまたは単一IP。X-Forwarded-Forを右から辿り、これに含まれない最初のアドレスをクライアントIPとする）
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"IP_FILTER_TRUSTED_PROXIES"`
+	// Allow / Deny アプリ全体に適用するリスト（Allowが空の場合は拒否リスト以外を許可）
+	Allow []string `yaml:"allow" toml:"allow" env:"IP_FILTER_ALLOW"`
+	Deny  []string `yaml:"deny" toml:"deny" env:"IP_FILTER_DENY"`
+	// Paths パスごとのリスト（先頭一致、最も長く一致したものを全体のリストに加えて適用）
+	Paths []PathIPFilter `yaml:"paths" toml:"paths"`
+}
+
+// PathIPFilter パスごとのIPアドレスの許可・拒否リスト
+type PathIPFilter struct {
+	Prefix string   `yaml:"prefix" toml:"prefix"`
+	Allow  []string `yaml:"allow" toml:"allow"`
+	Deny   []string `yaml:"deny" toml:"deny"`
+}
+
+// AdminConfig 管理API（/api/v1/admin）の設定
+type AdminConfig struct {
+	// Token 管理APIに要求するBearerトークン（未設定の場合は管理APIを公開しない）
+	Token string `yaml:"token" toml:"token" env:"ADMIN_TOKEN"`
+}
+
+// MaintenanceConfig メンテナンスモードの動作設定（有効/無効はフィーチャーフラグ maintenance_mode で切り替える）
+// 管理API（PUT /api/v1/admin/maintenance）で変更した場合は、再起動するまでその値を優先する
+type MaintenanceConfig struct {
+	// AllowReads メンテナンス中も読み取り（GET/HEAD）を許可するか
+	AllowReads bool `yaml:"allow_reads" toml:"allow_reads" env:"MAINTENANCE_ALLOW_READS"`
+	// RetryAfter Retry-Afterヘッダーに設定する時間
+	RetryAfter time.Duration `yaml:"retry_after" toml:"retry_after" env:"MAINTENANCE_RETRY_AFTER"`
+	// Message 503レスポンスのメッセージ（空の場合はデフォルトのメッセージ）
+	Message string `yaml:"message" toml:"message" env:"MAINTENANCE_MESSAGE"`
+}
+
+// SanitizeConfig Todoの説明文（description）のサニタイズ設定
+type SanitizeConfig struct {
+	// Mode off / strip（危険なタグ・属性を除去）/ escape（全てエスケープ）
+	Mode string `yaml:"mode" toml:"mode" env:"SANITIZE_MODE"`
+	// Stage save（保存時）/ output（出力時）
+	Stage string `yaml:"stage" toml:"stage" env:"SANITIZE_STAGE"`
+}
+
+// ValidationConfig リクエストバリデーションの設定
+type ValidationConfig struct {
+	// StrictUnknownFields スキーマ外のフィールド（typoした titel 等）を含むリクエストを400で拒否するか（falseの場合は無視）
+	StrictUnknownFields bool `yaml:"strict_unknown_fields" toml:"strict_unknown_fields" env:"VALIDATION_STRICT_UNKNOWN_FIELDS"`
+	// PastDueDate Todoの作成・更新で過去の期限を指定した場合の扱い（allow: 許可 / warn: 作成・更新した上で警告を返す / reject: 422で拒否）
+	PastDueDate string `yaml:"past_due_date" toml:"past_due_date" env:"VALIDATION_PAST_DUE_DATE"`
+}
+
+// LocaleConfig レスポンスのメッセージの言語の設定
+type LocaleConfig struct {
+	// Default Accept-Languageが未指定または対応していない言語のみの場合の言語（ja / en）
+	Default string `yaml:"default" toml:"default" env:"LOCALE_DEFAULT"`
+}
+
+// WebhookConfig Outgoing Webhookの署名・配信の設定
+type WebhookConfig struct {
+	// SigningSecret 署名シークレットの初期値（ローテーション後はDBに保存したシークレットを使う）
+	SigningSecret string `yaml:"signing_secret" toml:"signing_secret" env:"WEBHOOK_SIGNING_SECRET"`
+	// RotationGracePeriod ローテーション後も旧シークレットで署名を続ける期間
+	RotationGracePeriod time.Duration `yaml:"rotation_grace_period" toml:"rotation_grace_period" env:"WEBHOOK_ROTATION_GRACE_PERIOD"`
+	// LegacySignature v2の署名に加えて、nonceを含まないv1の署名も送るか（受信側がv2の検証に移行するまでの互換用）
+	LegacySignature bool `yaml:"legacy_signature" toml:"legacy_signature" env:"WEBHOOK_LEGACY_SIGNATURE"`
+	// SecretRefreshInterval DBのシークレットを読み込み直す間隔（他のインスタンスで行ったローテーションへの追従。0の場合は起動時のみ）
+	SecretRefreshInterval time.Duration `yaml:"secret_refresh_interval" toml:"secret_refresh_interval" env:"WEBHOOK_SECRET_REFRESH_INTERVAL"`
+	// Timeout 1回の送信のタイムアウト
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	// MaxAttempts 送信先への1件の配信を試行する回数の上限（0の場合はジョブキューの設定）
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	// AllowPrivateNetworks 登録した送信先への配信で、内部ネットワーク（プライベート・ループバック・リンクローカル等）のアドレスを許可するか（開発環境用）
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" toml:"allow_private_networks" env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+	// DisableAfter 送信先を自動で停止する、配信の連続失敗回数（0の場合は410 Gone以外では停止しない）
+	DisableAfter int `yaml:"disable_after" toml:"disable_after" env:"WEBHOOK_DISABLE_AFTER"`
+}
+
+// SessionConfig Cookieセッションの設定
+type SessionConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"SESSION_ENABLED"`
+	// Store cookie（暗号化Cookieに保存）/ redis（Redisに保存しCookieにはIDのみ）
+	Store      string `yaml:"store" toml:"store" env:"SESSION_STORE"`
+	CookieName string `yaml:"cookie_name" toml:"cookie_name" env:"SESSION_COOKIE_NAME"`
+	// Secret Cookieストアの暗号鍵を導出するシークレット（32文字以上）
+	Secret       string        `yaml:"secret" toml:"secret" env:"SESSION_SECRET"`
+	MaxAge       time.Duration `yaml:"max_age" toml:"max_age" env:"SESSION_MAX_AGE"`
+	CookieDomain string        `yaml:"cookie_domain" toml:"cookie_domain" env:"SESSION_COOKIE_DOMAIN"`
+	CookieSecure bool          `yaml:"cookie_secure" toml:"cookie_secure" env:"SESSION_COOKIE_SECURE"`
+	// SameSite Cookieの SameSite 属性（lax / strict / none）
+	SameSite  string `yaml:"same_site" toml:"same_site" env:"SESSION_SAME_SITE"`
+	RedisAddr string `yaml:"redis_addr" toml:"redis_addr" env:"SESSION_REDIS_ADDR"`
+}
+
+// CacheConfig 一覧・統計クエリの結果のキャッシュの設定
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"CACHE_ENABLED"`
+	// Backend 保存先（memory: プロセス内。単一インスタンス向け / redis: Redis。複数インスタンスで共有し、無効化も全インスタンスに反映される）
+	Backend string `yaml:"backend" toml:"backend" env:"CACHE_BACKEND"`
+	// MaxEntries backend=memory で保持するキャッシュの上限
+	MaxEntries int `yaml:"max_entries" toml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+	// RedisAddr backend=redis の接続先（未設定の場合は REDIS_ADDR）
+	RedisAddr string `yaml:"redis_addr" toml:"redis_addr" env:"CACHE_REDIS_ADDR"`
+	// TTL Todoの一覧・取得結果を保持する時間（書き込み時には期限前でも無効化する）
+	TTL time.Duration `yaml:"ttl" toml:"ttl" env:"CACHE_TTL"`
+	// StatsTTL DB統計を保持する時間（書き込みでは無効化しない）
+	StatsTTL time.Duration `yaml:"stats_ttl" toml:"stats_ttl" env:"CACHE_STATS_TTL"`
+}
+
+// ListConfig Todoの一覧APIの設定
+type ListConfig struct {
+	// CountMode ページング時の総件数の求め方（exact: COUNT(*) / estimated: 統計情報からの推定値 / none: 返さない）。リクエストの count で上書きできる
+	CountMode string `yaml:"count_mode" toml:"count_mode" env:"LIST_COUNT_MODE"`
+}
+
+// EventsConfig Todoのドメインイベント（CloudEvents）の発行の設定
+type EventsConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"EVENTS_ENABLED"`
+	// Broker 発行先（nats / kafka）
+	Broker string `yaml:"broker" toml:"broker" env:"EVENTS_BROKER"`
+	// Source CloudEventsのsource属性
+	Source string `yaml:"source" toml:"source" env:"EVENTS_SOURCE"`
+	// Topic NATSのサブジェクトの接頭辞（<topic>.todo.created 等）/ Kafkaのトピック
+	Topic string `yaml:"topic" toml:"topic" env:"EVENTS_TOPIC"`
+	// NATSURL nats://[user:pass@]host:4222 または tls://host:4222
+	NATSURL string `yaml:"nats_url" toml:"nats_url" env:"EVENTS_NATS_URL"`
+	// KafkaBrokers Kafkaのブートストラップサーバー（host:port）
+	KafkaBrokers []string `yaml:"kafka_brokers" toml:"kafka_brokers" env:"EVENTS_KAFKA_BROKERS"`
+	// KafkaTLS KafkaにTLSで接続するか
+	KafkaTLS bool `yaml:"kafka_tls" toml:"kafka_tls" env:"EVENTS_KAFKA_TLS"`
+	// KafkaSASLMechanism SASL認証の方式（plain / scram-sha-256 / scram-sha-512。KafkaUsernameが空の場合は認証しない）
+	KafkaSASLMechanism string `yaml:"kafka_sasl_mechanism" toml:"kafka_sasl_mechanism" env:"EVENTS_KAFKA_SASL_MECHANISM"`
+	KafkaUsername      string `yaml:"kafka_username" toml:"kafka_username" env:"EVENTS_KAFKA_USERNAME"`
+	KafkaPassword      string `yaml:"kafka_password" toml:"kafka_password" env:"EVENTS_KAFKA_PASSWORD"`
+	// BufferSize 発行待ちのイベントを保持する数（超えた分は破棄する）
+	BufferSize int `yaml:"buffer_size" toml:"buffer_size" env:"EVENTS_BUFFER_SIZE"`
+}
+
+// ReplayConfig 署名付きリクエスト（Webhook受信・サーバー間API）のリプレイ防止の設定
+type ReplayConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"REPLAY_PROTECTION_ENABLED"`
+	// Paths 署名・nonceを検証するパス（先頭一致）
+	Paths []string `yaml:"paths" toml:"paths" env:"REPLAY_PATHS"`
+	// Secrets 署名の検証に使う共有シークレット（複数指定時はいずれかで一致すればよい）
+	Secrets []string `yaml:"secrets" toml:"secrets" env:"REPLAY_SECRETS"`
+	// Tolerance 受け付ける署名時刻のずれ（使用済みnonceはこの2倍の期間保持する）
+	Tolerance time.Duration `yaml:"tolerance" toml:"tolerance" env:"REPLAY_TOLERANCE"`
+	// NonceStore 使用済みnonceの保存先（memory / redis）
+	NonceStore string `yaml:"nonce_store" toml:"nonce_store" env:"REPLAY_NONCE_STORE"`
+	// RedisAddr nonce_store=redis の接続先（未設定の場合は REDIS_ADDR）
+	RedisAddr string `yaml:"redis_addr" toml:"redis_addr" env:"REPLAY_REDIS_ADDR"`
+}
+
+// NotifyConfig Todoイベント（作成・完了・期限切れ）の外部通知の設定
+type NotifyConfig struct {
+	// OverdueCheckInterval 期限切れ・期限間近のTodoを確認する間隔（0の場合は通知しない）
+	OverdueCheckInterval time.Duration `yaml:"overdue_check_interval" toml:"overdue_check_interval" env:"NOTIFY_OVERDUE_CHECK_INTERVAL"`
+	// RemindBefore 期限のどれだけ前にリマインダーを送るか（0の場合は送らない）
+	RemindBefore time.Duration `yaml:"remind_before" toml:"remind_before" env:"NOTIFY_REMIND_BEFORE"`
+	// RenotifyInterval 完了していないTodoのリマインダーを再び送る間隔（0の場合は期限が変わるまで再通知しない）
+	RenotifyInterval time.Duration    `yaml:"renotify_interval" toml:"renotify_interval" env:"NOTIFY_RENOTIFY_INTERVAL"`
+	Slack            SlackConfig      `yaml:"slack" toml:"slack"`
+	Discord          DiscordConfig    `yaml:"discord" toml:"discord"`
+	Mattermost       MattermostConfig `yaml:"mattermost" toml:"mattermost"`
+	Teams            TeamsConfig      `yaml:"teams" toml:"teams"`
+	Email            EmailConfig      `yaml:"email" toml:"email"`
+	WebPush          WebPushConfig    `yaml:"webpush" toml:"webpush"`
+}
+
+// SlackConfig Slack通知の設定（BotTokenを指定した場合はBotで投稿し、それ以外はIncoming Webhookを使う）
+type SlackConfig struct {
+	Enabled    bool   `yaml:"enabled" toml:"enabled" env:"SLACK_NOTIFY_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"SLACK_WEBHOOK_URL"`
+	BotToken   string `yaml:"bot_token" toml:"bot_token" env:"SLACK_BOT_TOKEN"`
+	Channel    string `yaml:"channel" toml:"channel" env:"SLACK_CHANNEL"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は全て）
+	Events []string `yaml:"events" toml:"events" env:"SLACK_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"SLACK_NOTIFY_MIN_PRIORITY"`
+}
+
+// DiscordConfig Discord Webhook通知の設定
+type DiscordConfig struct {
+	Enabled    bool   `yaml:"enabled" toml:"enabled" env:"DISCORD_NOTIFY_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"DISCORD_WEBHOOK_URL"`
+	// Username 投稿者として表示する名前（空の場合はWebhookの既定名）
+	Username string `yaml:"username" toml:"username" env:"DISCORD_USERNAME"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は全て）
+	Events []string `yaml:"events" toml:"events" env:"DISCORD_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"DISCORD_NOTIFY_MIN_PRIORITY"`
+}
+
+// MattermostConfig Mattermost Incoming Webhook通知の設定
+type MattermostConfig struct {
+	Enabled    bool   `yaml:"enabled" toml:"enabled" env:"MATTERMOST_NOTIFY_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"MATTERMOST_WEBHOOK_URL"`
+	// Username 投稿者として表示する名前（空の場合はWebhookの既定名。上書きにはサーバー側の許可が必要）
+	Username string `yaml:"username" toml:"username" env:"MATTERMOST_USERNAME"`
+	// Channel 投稿先のチャンネル名（空の場合はWebhookの既定のチャンネル）
+	Channel string `yaml:"channel" toml:"channel" env:"MATTERMOST_CHANNEL"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は全て）
+	Events []string `yaml:"events" toml:"events" env:"MATTERMOST_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"MATTERMOST_NOTIFY_MIN_PRIORITY"`
+}
+
+// TeamsConfig Microsoft Teams Incoming Webhook通知の設定（Adaptive Cardで投稿する）
+type TeamsConfig struct {
+	Enabled    bool   `yaml:"enabled" toml:"enabled" env:"TEAMS_NOTIFY_ENABLED"`
+	WebhookURL string `yaml:"webhook_url" toml:"webhook_url" env:"TEAMS_WEBHOOK_URL"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は全て）
+	Events []string `yaml:"events" toml:"events" env:"TEAMS_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"TEAMS_NOTIFY_MIN_PRIORITY"`
+}
+
+// SchedulerConfig 定期実行ジョブのスケジューラーの設定
+type SchedulerConfig struct {
+	// Timezone cron式の時刻を解釈するタイムゾーン（IANAの名前。空の場合はサーバーのローカル時刻）
+	Timezone string `yaml:"timezone" toml:"timezone" env:"SCHEDULER_TIMEZONE"`
+	// LockLease 実行中のロックの期限（ジョブの実行時間の上限を兼ねる）
+	LockLease time.Duration `yaml:"lock_lease" toml:"lock_lease" env:"SCHEDULER_LOCK_LEASE"`
+	Purge     PurgeConfig   `yaml:"purge" toml:"purge"`
+}
+
+// PurgeConfig 保持期間を過ぎたデータのパージの設定
+type PurgeConfig struct {
+	// Schedule 実行するスケジュール（cron式。空の場合はパージしない）
+	Schedule string `yaml:"schedule" toml:"schedule" env:"SCHEDULER_PURGE_SCHEDULE"`
+	// Retention 論理削除したTodo・終了した取り込みの記録を保持する期間
+	Retention time.Duration `yaml:"retention" toml:"retention" env:"SCHEDULER_PURGE_RETENTION"`
+}
+
+// RecurrenceConfig 繰り返しTodoの次回のTodoを生成するジョブの設定（日付はスケジューラーのタイムゾーンで数える）
+type RecurrenceConfig struct {
+	// Schedule 生成するスケジュール（cron式。空の場合は生成しない）
+	Schedule string `yaml:"schedule" toml:"schedule" env:"RECURRENCE_SCHEDULE"`
+	// HolidayCalendar 祝日をスキップする繰り返しTodoで使う祝日（jp: 日本の祝日 / none: 追加の休日のみ）
+	HolidayCalendar string `yaml:"holiday_calendar" toml:"holiday_calendar" env:"RECURRENCE_HOLIDAY_CALENDAR"`
+	// Holidays 追加の休日（YYYY-MM-DD。年末年始・会社の休業日等）
+	Holidays []string `yaml:"holidays" toml:"holidays" env:"RECURRENCE_HOLIDAYS"`
+}
+
+// EscalationConfig 期限切れのTodoにエスカレーションルールを適用するジョブの設定
+type EscalationConfig struct {
+	// Schedule 適用するスケジュール（cron式。空の場合は適用しない）
+	Schedule string `yaml:"schedule" toml:"schedule" env:"ESCALATION_SCHEDULE"`
+}
+
+// ExportConfig 全データのスナップショットをストレージへエクスポートするジョブの設定
+type ExportConfig struct {
+	// Schedule エクスポートするスケジュール（cron式。例: 毎日 "0 2 * * *"、毎週 "0 2 * * sun"。空の場合はエクスポートしない）
+	Schedule string `yaml:"schedule" toml:"schedule" env:"EXPORT_SCHEDULE"`
+	// Formats エクスポートする形式（json / csv）
+	Formats []string `yaml:"formats" toml:"formats" env:"EXPORT_FORMATS"`
+	// Keep 保持するスナップショットの数（超えた古いものから削除する。0の場合は削除しない）
+	Keep int `yaml:"keep" toml:"keep" env:"EXPORT_KEEP"`
+}
+
+// StaleConfig 長期間更新されていない未完了のTodo（放置タスク）を検出するジョブの設定
+type StaleConfig struct {
+	// Schedule 検出するスケジュール（cron式。空の場合は検出しない。一覧APIは常に使える）
+	Schedule string `yaml:"schedule" toml:"schedule" env:"STALE_SCHEDULE"`
+	// After 最後の更新からこの期間を過ぎた未完了のTodoを放置タスクとする
+	After time.Duration `yaml:"after" toml:"after" env:"STALE_AFTER"`
+	// Action 検出したTodoに行うこと（flag: レビューが必要のフラグを付ける / notify: 通知する / both: 両方）
+	Action string `yaml:"action" toml:"action" env:"STALE_ACTION"`
+}
+
+// BulkConfig Todoの一括操作・取り込みの設定
+type BulkConfig struct {
+	// Concurrency 一括操作・取り込みを並列に処理するワーカー数（1件の一括操作・取り込みごと）
+	Concurrency int `yaml:"concurrency" toml:"concurrency" env:"BULK_CONCURRENCY"`
+	// MaxItems 1回の一括操作で指定できるTodoの上限
+	MaxItems int `yaml:"max_items" toml:"max_items" env:"BULK_MAX_ITEMS"`
+	// InsertBatchSize 一括作成・取り込みで1回のINSERTにまとめる件数
+	InsertBatchSize int `yaml:"insert_batch_size" toml:"insert_batch_size" env:"BULK_INSERT_BATCH_SIZE"`
+}
+
+// QueueConfig Webhook・メールの送信、LLMの処理を非同期に実行するジョブキューの設定
+type QueueConfig struct {
+	// Concurrency インスタンスごとに同時に実行するジョブ数
+	Concurrency int `yaml:"concurrency" toml:"concurrency" env:"QUEUE_CONCURRENCY"`
+	// PollInterval 実行待ちのジョブがない場合に次に確認するまでの間隔
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"QUEUE_POLL_INTERVAL"`
+	// LockLease 実行中のロックの期限（ジョブの実行時間の上限を兼ねる）
+	LockLease time.Duration `yaml:"lock_lease" toml:"lock_lease" env:"QUEUE_LOCK_LEASE"`
+	// MaxAttempts 試行回数の上限（超えたジョブはデッドレターになる。メールはEMAIL_MAX_RETRIES+1回）
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts" env:"QUEUE_MAX_ATTEMPTS"`
+	// BackoffBase・BackoffMax 再試行までの間隔（失敗のたびに倍増し、BackoffMaxで頭打ち）
+	BackoffBase time.Duration `yaml:"backoff_base" toml:"backoff_base" env:"QUEUE_BACKOFF_BASE"`
+	BackoffMax  time.Duration `yaml:"backoff_max" toml:"backoff_max" env:"QUEUE_BACKOFF_MAX"`
+	// Retention 成功したジョブを保持する期間（パージのジョブで削除する。デッドレターは削除しない）
+	Retention time.Duration `yaml:"retention" toml:"retention" env:"QUEUE_RETENTION"`
+}
+
+// TelegramConfig Telegramボットの設定
+type TelegramConfig struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" env:"TELEGRAM_ENABLED"`
+	BotToken string `yaml:"bot_token" toml:"bot_token" env:"TELEGRAM_BOT_TOKEN"`
+	// AllowedChatIDs 操作を許可するチャットID（これ以外のチャットには応答しない）
+	AllowedChatIDs []string `yaml:"allowed_chat_ids" toml:"allowed_chat_ids" env:"TELEGRAM_ALLOWED_CHAT_IDS"`
+}
+
+// IMAPConfig 受信メールからのTodo作成の設定（専用のメールボックスをIMAPでポーリングする）
+type IMAPConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"IMAP_ENABLED"`
+	// Addr IMAPサーバーのアドレス（host:port）
+	Addr string `yaml:"addr" toml:"addr" env:"IMAP_ADDR"`
+	// TLS 接続時からTLSを使う（IMAPS）
+	TLS      bool   `yaml:"tls" toml:"tls" env:"IMAP_TLS"`
+	Username string `yaml:"username" toml:"username" env:"IMAP_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"IMAP_PASSWORD"`
+	Mailbox  string `yaml:"mailbox" toml:"mailbox" env:"IMAP_MAILBOX"`
+	// ProcessedMailbox 処理したメールの移動先（空の場合は既読にするのみ）
+	ProcessedMailbox string        `yaml:"processed_mailbox" toml:"processed_mailbox" env:"IMAP_PROCESSED_MAILBOX"`
+	PollInterval     time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"IMAP_POLL_INTERVAL"`
+	// AllowedSenders Todoを作成できる送信者（メールアドレスまたは "@example.com" 形式のドメイン）
+	AllowedSenders []string `yaml:"allowed_senders" toml:"allowed_senders" env:"IMAP_ALLOWED_SENDERS"`
+	// UseLLM LLM機能が有効な場合に本文から期限・優先度を抽出する
+	UseLLM bool `yaml:"use_llm" toml:"use_llm" env:"IMAP_USE_LLM"`
+}
+
+// ChatIDs 許可するチャットIDを数値で取得（不正な値は無視する）
+func (c TelegramConfig) ChatIDs() []int64 {
+	ids := make([]int64, 0, len(c.AllowedChatIDs))
+	for _, raw := range c.AllowedChatIDs {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// EmailConfig SMTPによるメール通知の設定
+type EmailConfig struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" env:"EMAIL_NOTIFY_ENABLED"`
+	SMTPHost string `yaml:"smtp_host" toml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort int    `yaml:"smtp_port" toml:"smtp_port" env:"SMTP_PORT"`
+	Username string `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"SMTP_PASSWORD"`
+	// ImplicitTLS 接続時からTLSを使う（465番ポート）。falseの場合はサーバーが対応していればSTARTTLSを使う
+	ImplicitTLS bool     `yaml:"implicit_tls" toml:"implicit_tls" env:"SMTP_IMPLICIT_TLS"`
+	From        string   `yaml:"from" toml:"from" env:"EMAIL_FROM"`
+	To          []string `yaml:"to" toml:"to" env:"EMAIL_TO"`
+	// MaxRetries 送信に失敗した場合の再試行回数
+	MaxRetries int `yaml:"max_retries" toml:"max_retries" env:"EMAIL_MAX_RETRIES"`
+	// DigestTime 日次ダイジェストを送る時刻（HH:MM、スケジューラーのタイムゾーン。空の場合は送らない）
+	DigestTime string `yaml:"digest_time" toml:"digest_time" env:"EMAIL_DIGEST_TIME"`
+	// DigestCheckSchedule 送信先ごとのダイジェスト（管理APIで登録）の送る時刻を確認するスケジュール（cron式。空の場合は送らない）
+	DigestCheckSchedule string `yaml:"digest_check_schedule" toml:"digest_check_schedule" env:"EMAIL_DIGEST_CHECK_SCHEDULE"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は期限間近・期限切れのみ）
+	Events []string `yaml:"events" toml:"events" env:"EMAIL_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"EMAIL_NOTIFY_MIN_PRIORITY"`
+}
+
+// WebPushConfig ブラウザへのWeb Push通知（VAPID）の設定
+type WebPushConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"WEBPUSH_NOTIFY_ENABLED"`
+	// VAPIDPrivateKey VAPIDの秘密鍵（P-256、base64url。-generate-vapid-keys で生成できる）
+	VAPIDPrivateKey string `yaml:"vapid_private_key" toml:"vapid_private_key" env:"VAPID_PRIVATE_KEY"`
+	// Subject プッシュサービスが運用者へ連絡するための連絡先（mailto: または https: のURL）
+	Subject string `yaml:"subject" toml:"subject" env:"VAPID_SUBJECT"`
+	// TTL 端末がオフラインの場合にプッシュサービスが通知を保持する期間
+	TTL time.Duration `yaml:"ttl" toml:"ttl" env:"WEBPUSH_TTL"`
+	// AllowedHosts 購読を受け付けるプッシュサービスのホスト（*.example.com でサブドメインに一致。空の場合は全てのHTTPSのホスト）
+	AllowedHosts []string `yaml:"allowed_hosts" toml:"allowed_hosts" env:"WEBPUSH_ALLOWED_HOSTS"`
+	// Events 通知するイベント（created / completed / due_soon / overdue / stale。空の場合は全て）
+	Events []string `yaml:"events" toml:"events" env:"WEBPUSH_NOTIFY_EVENTS"`
+	// MinPriority 通知する最低の優先度（low / medium / high / urgent。空の場合は全て）
+	MinPriority string `yaml:"min_priority" toml:"min_priority" env:"WEBPUSH_NOTIFY_MIN_PRIORITY"`
+}
+
+// CalendarConfig Googleカレンダーとの同期の設定
+type CalendarConfig struct {
+	Enabled      bool   `yaml:"enabled" toml:"enabled" env:"GOOGLE_CALENDAR_ENABLED"`
+	ClientID     string `yaml:"client_id" toml:"client_id" env:"GOOGLE_CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" toml:"client_secret" env:"GOOGLE_CLIENT_SECRET"`
+	// RedirectURL 同意画面から戻るURL（/api/v1/admin/integrations/google-calendar/callback を指す公開URL）
+	RedirectURL string `yaml:"redirect_url" toml:"redirect_url" env:"GOOGLE_REDIRECT_URL"`
+	// CalendarID 同期先のカレンダー（primary はアカウントのメインカレンダー）
+	CalendarID   string        `yaml:"calendar_id" toml:"calendar_id" env:"GOOGLE_CALENDAR_ID"`
+	SyncInterval time.Duration `yaml:"sync_interval" toml:"sync_interval" env:"GOOGLE_CALENDAR_SYNC_INTERVAL"`
+	// EventDuration 期限を開始日時とする予定の長さ
+	EventDuration time.Duration `yaml:"event_duration" toml:"event_duration" env:"GOOGLE_CALENDAR_EVENT_DURATION"`
+}
+
+// MSTodoConfig Microsoft To Do（Microsoft Graph API）からの取り込みの設定
+type MSTodoConfig struct {
+	Enabled      bool   `yaml:"enabled" toml:"enabled" env:"MICROSOFT_TODO_ENABLED"`
+	ClientID     string `yaml:"client_id" toml:"client_id" env:"MICROSOFT_CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" toml:"client_secret" env:"MICROSOFT_CLIENT_SECRET"`
+	// RedirectURL 同意画面から戻るURL（/api/v1/admin/integrations/microsoft-todo/callback を指す公開URL）
+	RedirectURL string `yaml:"redirect_url" toml:"redirect_url" env:"MICROSOFT_REDIRECT_URL"`
+	// Tenant テナント（common: 個人・職場の両方のアカウント、consumers: 個人のみ、またはテナントID）
+	Tenant string `yaml:"tenant" toml:"tenant" env:"MICROSOFT_TENANT"`
+	// ListProjects リスト名と取り込み先のプロジェクト名の対応（"リスト名=プロジェクト名" の形式。対応のないリストはリスト名をプロジェクト名にする）
+	ListProjects []string `yaml:"list_projects" toml:"list_projects" env:"MICROSOFT_TODO_LIST_PROJECTS"`
+}
+
+// GitHubConfig GitHubのIssueとの同期の設定
+type GitHubConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"GITHUB_SYNC_ENABLED"`
+	// Token Issuesの読み書き権限を持つトークン（Fine-grained personal access token等）
+	Token string `yaml:"token" toml:"token" env:"GITHUB_TOKEN"`
+	// Repository 同期するリポジトリ（owner/repo）
+	Repository string `yaml:"repository" toml:"repository" env:"GITHUB_REPOSITORY"`
+	// WebhookSecret WebhookのSecret（空の場合はWebhookを受け付けずポーリングのみ）
+	WebhookSecret string        `yaml:"webhook_secret" toml:"webhook_secret" env:"GITHUB_WEBHOOK_SECRET"`
+	PollInterval  time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"GITHUB_POLL_INTERVAL"`
+	// APIURL REST APIのベースURL（GitHub Enterprise Serverの場合は https://<ホスト>/api/v3）
+	APIURL string `yaml:"api_url" toml:"api_url" env:"GITHUB_API_URL"`
+}
+
+// JiraConfig Jiraの課題との同期の設定
+type JiraConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"JIRA_SYNC_ENABLED"`
+	// BaseURL サイトのURL（https://<サイト>.atlassian.net 等）
+	BaseURL string `yaml:"base_url" toml:"base_url" env:"JIRA_BASE_URL"`
+	// Email Jira Cloudのアカウントのメールアドレス（空の場合はAPITokenをData Center/Serverの個人用アクセストークンとして使う）
+	Email    string `yaml:"email" toml:"email" env:"JIRA_EMAIL"`
+	APIToken string `yaml:"api_token" toml:"api_token" env:"JIRA_API_TOKEN"`
+	// JQL 取り込む課題の条件（例: project = ABC AND assignee = currentUser()）
+	JQL string `yaml:"jql" toml:"jql" env:"JIRA_JQL"`
+	// DoneTransition Todoを完了したときに実行するトランジション（名前またはID）
+	DoneTransition string `yaml:"done_transition" toml:"done_transition" env:"JIRA_DONE_TRANSITION"`
+	// ReopenTransition 完了したTodoを未完了に戻したときに実行するトランジション（空の場合は実行しない）
+	ReopenTransition string           `yaml:"reopen_transition" toml:"reopen_transition" env:"JIRA_REOPEN_TRANSITION"`
+	PollInterval     time.Duration    `yaml:"poll_interval" toml:"poll_interval" env:"JIRA_POLL_INTERVAL"`
+	Fields           JiraFieldsConfig `yaml:"fields" toml:"fields"`
+}
+
+// JiraFieldsConfig 課題のフィールドとTodoの項目の対応（カスタムフィールドは customfield_10010 のようにIDで指定。空の場合は取り込まない）
+type JiraFieldsConfig struct {
+	Title       string `yaml:"title" toml:"title" env:"JIRA_FIELD_TITLE"`
+	Description string `yaml:"description" toml:"description" env:"JIRA_FIELD_DESCRIPTION"`
+	DueDate     string `yaml:"due_date" toml:"due_date" env:"JIRA_FIELD_DUE_DATE"`
+	Priority    string `yaml:"priority" toml:"priority" env:"JIRA_FIELD_PRIORITY"`
+	Tags        string `yaml:"tags" toml:"tags" env:"JIRA_FIELD_TAGS"`
+	// PriorityMap 優先度の値とTodoの優先度の対応（"Highest=urgent" の形式。対応のない値は medium）
+	PriorityMap []string `yaml:"priority_map" toml:"priority_map" env:"JIRA_PRIORITY_MAP"`
+}
+
+// NotionConfig Notionのデータベースへのエクスポートの設定
+type NotionConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"NOTION_SYNC_ENABLED"`
+	// Token インテグレーションのシークレット（データベースをインテグレーションに共有しておく）
+	Token string `yaml:"token" toml:"token" env:"NOTION_TOKEN"`
+	// DatabaseID エクスポート先のデータベースのID（URLの32桁の16進数）
+	DatabaseID   string        `yaml:"database_id" toml:"database_id" env:"NOTION_DATABASE_ID"`
+	SyncInterval time.Duration `yaml:"sync_interval" toml:"sync_interval" env:"NOTION_SYNC_INTERVAL"`
+	// DoneStatus・TodoStatus 完了状態をステータス・セレクトのプロパティに書き込む場合の値
+	DoneStatus string                 `yaml:"done_status" toml:"done_status" env:"NOTION_DONE_STATUS"`
+	TodoStatus string                 `yaml:"todo_status" toml:"todo_status" env:"NOTION_TODO_STATUS"`
+	Properties NotionPropertiesConfig `yaml:"properties" toml:"properties"`
+	// APIURL APIのベースURL
+	APIURL string `yaml:"api_url" toml:"api_url" env:"NOTION_API_URL"`
+}
+
+// NotionPropertiesConfig Todoの項目と書き込むデータベースのプロパティ名の対応（空の場合は書き込まない）
+type NotionPropertiesConfig struct {
+	Title       string `yaml:"title" toml:"title" env:"NOTION_PROPERTY_TITLE"`
+	Description string `yaml:"description" toml:"description" env:"NOTION_PROPERTY_DESCRIPTION"`
+	Completed   string `yaml:"completed" toml:"completed" env:"NOTION_PROPERTY_COMPLETED"`
+	Priority    string `yaml:"priority" toml:"priority" env:"NOTION_PROPERTY_PRIORITY"`
+	DueDate     string `yaml:"due_date" toml:"due_date" env:"NOTION_PROPERTY_DUE_DATE"`
+	Tags        string `yaml:"tags" toml:"tags" env:"NOTION_PROPERTY_TAGS"`
+	// ID TodoのIDを書き込むプロパティ（数値またはテキスト）
+	ID string `yaml:"id" toml:"id" env:"NOTION_PROPERTY_ID"`
+}
+
+// StorageConfig 添付ファイル・エクスポート成果物等の保存先の設定
+type StorageConfig struct {
+	// Driver local / s3 / gcs
+	Driver string `yaml:"driver" toml:"driver" env:"STORAGE_DRIVER"`
+	// LocalDir localドライバーの保存先ディレクトリ
+	LocalDir string           `yaml:"local_dir" toml:"local_dir" env:"STORAGE_LOCAL_DIR"`
+	S3       StorageS3Config  `yaml:"s3" toml:"s3"`
+	GCS      StorageGCSConfig `yaml:"gcs" toml:"gcs"`
+}
+
+// StorageS3Config s3ドライバーの設定（S3互換サービスはEndpointとPathStyleを指定する）
+type StorageS3Config struct {
+	Bucket          string `yaml:"bucket" toml:"bucket" env:"STORAGE_S3_BUCKET"`
+	Region          string `yaml:"region" toml:"region" env:"STORAGE_S3_REGION"`
+	Endpoint        string `yaml:"endpoint" toml:"endpoint" env:"STORAGE_S3_ENDPOINT"`
+	AccessKeyID     string `yaml:"access_key_id" toml:"access_key_id" env:"STORAGE_S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" toml:"secret_access_key" env:"STORAGE_S3_SECRET_ACCESS_KEY"`
+	PathStyle       bool   `yaml:"path_style" toml:"path_style" env:"STORAGE_S3_PATH_STYLE"`
+}
+
+// StorageGCSConfig gcsドライバーの設定
+type StorageGCSConfig struct {
+	Bucket string `yaml:"bucket" toml:"bucket" env:"STORAGE_GCS_BUCKET"`
+	// CredentialsFile サービスアカウントのキー（JSON）のパス（空の場合はアプリケーションのデフォルト認証情報を使う）
+	CredentialsFile string `yaml:"credentials_file" toml:"credentials_file" env:"STORAGE_GCS_CREDENTIALS_FILE"`
+	// Endpoint JSON APIのURL（エミュレーター等に使う）
+	Endpoint string `yaml:"endpoint" toml:"endpoint" env:"STORAGE_GCS_ENDPOINT"`
+}
+
+// QuickAddConfig クイック追加（POST /quick-add）の設定
+type QuickAddConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"QUICK_ADD_ENABLED"`
+	// Tokens 認証に使うトークン（端末ごとに発行・失効できるよう複数指定できる。16文字以上）
+	Tokens []string `yaml:"tokens" toml:"tokens" env:"QUICK_ADD_TOKENS"`
+}
+
+// ZapierConfig Zapier向けのトリガー・アクションAPIの設定
+type ZapierConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"ZAPIER_ENABLED"`
+	// APIKey ZapierのアプリがX-API-Keyヘッダーで送るキー（16文字以上）
+	APIKey string `yaml:"api_key" toml:"api_key" env:"ZAPIER_API_KEY"`
+}
+
+// IFTTTConfig IFTTTのサービスAPI（/ifttt/v1）の設定
+type IFTTTConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"IFTTT_ENABLED"`
+	// ServiceKey IFTTTのサービス設定画面に表示されるService Key（16文字以上）
+	ServiceKey string `yaml:"service_key" toml:"service_key" env:"IFTTT_SERVICE_KEY"`
+}
+
+// CalDAVConfig CalDAVサーバーの設定（Basic認証の資格情報）
+type CalDAVConfig struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" env:"CALDAV_ENABLED"`
+	Username string `yaml:"username" toml:"username" env:"CALDAV_USERNAME"`
+	// Password 16文字以上
+	Password string `yaml:"password" toml:"password" env:"CALDAV_PASSWORD"`
+}
+
+// defaultTrustedProxies 既定で信頼するリバースプロキシ（ループバック・プライベートアドレス）
+var defaultTrustedProxies = []string{"127.0.0.0/8", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"}
+
+// Default デフォルト設定を取得
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			IdleTimeout:       2 * time.Minute,
+			UnixSocketMode:    "0660",
+			AutocertCacheDir:  "certs",
+			TLSClientAuth:     "require",
+			TLSReloadInterval: time.Minute,
+			PathNormalize:     "rewrite",
+		},
+		Database: *db.GetDefaultConfig(),
+		Startup: StartupConfig{
+			WaitTimeout: time.Minute,
+			BackoffBase: time.Second,
+			BackoffMax:  15 * time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			Timeout: 30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		AccessLog: AccessLogConfig{
+			Enabled:      true,
+			ExcludePaths: []string{"/health", "/livez", "/readyz", "/metrics"},
+			SampleRate:   1,
+		},
+		Tracing: TracingConfig{
+			ServiceName: "todo-api",
+		},
+		PanicAlert: PanicAlertConfig{
+			Cooldown: time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "traceparent", "X-Request-ID", "X-CSRF-Token"},
+			MaxAge:         600,
+		},
+		LLM: LLMConfig{
+			Provider: "openai",
+			Timeout:  60 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 10,
+			Burst:             20,
+			Backend:           "memory",
+			RedisAddr:         os.Getenv("REDIS_ADDR"),
+			FailOpen:          true,
+			TrustedProxies:    slices.Clone(defaultTrustedProxies),
+			ExcludePaths:      []string{"/health", "/livez", "/readyz", "/metrics"},
+		},
+		IPFilter: IPFilterConfig{
+			TrustedProxies: slices.Clone(defaultTrustedProxies),
+		},
+		BodyLimit: BodyLimitConfig{
+			MaxBytes: 1 << 20,
+			Paths: []PathBodyLimit{
+				{Prefix: "/api/v1/imports/", MaxBytes: 10 << 20},
+				{Prefix: "/api/v1/todos/bulk/create", MaxBytes: 10 << 20},
+			},
+		},
+		Timeout: TimeoutConfig{
+			Default: 30 * time.Second,
+			Paths: []PathTimeout{
+				// ストリーミングのエクスポートは件数に比例して時間がかかり、バッファすると逐次送信できないためタイムアウトを適用しない
+				{Prefix: "/api/v1/todos/export", Timeout: 0},
+			},
+		},
+		CSRF: CSRFConfig{
+			CookieName:    "csrf_token",
+			HeaderName:    "X-CSRF-Token",
+			SessionCookie: "session",
+			CookieMaxAge:  12 * time.Hour,
+			SameSite:      "lax",
+			ExemptHeaders: []string{"X-API-Key"},
+		},
+		Session: SessionConfig{
+			Store:      "cookie",
+			CookieName: "session",
+			MaxAge:     24 * time.Hour,
+			SameSite:   "lax",
+			RedisAddr:  os.Getenv("REDIS_ADDR"),
+		},
+		Replay: ReplayConfig{
+			Tolerance:  5 * time.Minute,
+			NonceStore: "memory",
+			RedisAddr:  os.Getenv("REDIS_ADDR"),
+		},
+		Events: EventsConfig{
+			Broker:             "nats",
+			Source:             "/todo-api",
+			Topic:              "todos",
+			KafkaSASLMechanism: "plain",
+			BufferSize:         10000,
+		},
+		Cache: CacheConfig{
+			Backend:    "redis",
+			MaxEntries: 10000,
+			RedisAddr:  os.Getenv("REDIS_ADDR"),
+			TTL:        time.Minute,
+			StatsTTL:   30 * time.Second,
+		},
+		GitHub: GitHubConfig{
+			PollInterval: 5 * time.Minute,
+			APIURL:       "https://api.github.com",
+		},
+		Jira: JiraConfig{
+			DoneTransition: "Done",
+			PollInterval:   5 * time.Minute,
+			Fields: JiraFieldsConfig{
+				Title:       "summary",
+				Description: "description",
+				DueDate:     "duedate",
+				Priority:    "priority",
+				Tags:        "labels",
+				PriorityMap: []string{"Highest=urgent", "High=high", "Medium=medium", "Low=low", "Lowest=low"},
+			},
+		},
+		Storage: StorageConfig{
+			Driver:   "local",
+			LocalDir: "data/storage",
+			S3: StorageS3Config{
+				Region: "us-east-1",
+			},
+		},
+		IMAP: IMAPConfig{
+			TLS:          true,
+			Mailbox:      "INBOX",
+			PollInterval: time.Minute,
+			UseLLM:       true,
+		},
+		Notion: NotionConfig{
+			SyncInterval: 5 * time.Minute,
+			DoneStatus:   "Done",
+			TodoStatus:   "Not started",
+			Properties: NotionPropertiesConfig{
+				Title:     "Name",
+				Completed: "Done",
+				Priority:  "Priority",
+				DueDate:   "Due",
+				Tags:      "Tags",
+			},
+			APIURL: "https://api.notion.com",
+		},
+		Calendar: CalendarConfig{
+			CalendarID:    "primary",
+			SyncInterval:  5 * time.Minute,
+			EventDuration: 30 * time.Minute,
+		},
+		MSTodo: MSTodoConfig{
+			Tenant: "common",
+		},
+		Scheduler: SchedulerConfig{
+			LockLease: 30 * time.Minute,
+			Purge: PurgeConfig{
+				Schedule:  "0 3 * * *",
+				Retention: 30 * 24 * time.Hour,
+			},
+		},
+		Recurrence: RecurrenceConfig{
+			Schedule:        "*/5 * * * *",
+			HolidayCalendar: "jp",
+		},
+		Escalation: EscalationConfig{
+			Schedule: "*/5 * * * *",
+		},
+		Export: ExportConfig{
+			Formats: []string{"json", "csv"},
+			Keep:    30,
+		},
+		List: ListConfig{
+			CountMode: "estimated",
+		},
+		Stale: StaleConfig{
+			After:  14 * 24 * time.Hour,
+			Action: "flag",
+		},
+		Bulk: BulkConfig{
+			Concurrency:     4,
+			MaxItems:        10000,
+			InsertBatchSize: 500,
+		},
+		Queue: QueueConfig{
+			Concurrency:  4,
+			PollInterval: 5 * time.Second,
+			LockLease:    5 * time.Minute,
+			MaxAttempts:  8,
+			BackoffBase:  30 * time.Second,
+			BackoffMax:   time.Hour,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Notify: NotifyConfig{
+			OverdueCheckInterval: 5 * time.Minute,
+			Email: EmailConfig{
+				SMTPPort:            587,
+				MaxRetries:          3,
+				DigestCheckSchedule: "* * * * *",
+				Events:              []string{"due_soon", "overdue"},
+			},
+			WebPush: WebPushConfig{
+				TTL: 24 * time.Hour,
+				AllowedHosts: []string{
+					"fcm.googleapis.com",
+					"updates.push.services.mozilla.com",
+					"*.notify.windows.com",
+					"web.push.apple.com",
+					"*.push.apple.com",
+				},
+				Events: []string{"due_soon", "overdue"},
+			},
+		},
+		Webhook: WebhookConfig{
+			RotationGracePeriod:   24 * time.Hour,
+			LegacySignature:       true,
+			SecretRefreshInterval: time.Minute,
+			Timeout:               10 * time.Second,
+			MaxAttempts:           8,
+			DisableAfter:          5,
+		},
+		Secrets: SecretsConfig{
+			Timeout: 10 * time.Second,
+		},
+		Maintenance: MaintenanceConfig{
+			AllowReads: true,
+			RetryAfter: 5 * time.Minute,
+		},
+		Sanitize: SanitizeConfig{
+			Mode:  "off",
+			Stage: "output",
+		},
+		Validation: ValidationConfig{
+			PastDueDate: "allow",
+		},
+		Locale: LocaleConfig{
+			Default: "ja",
+		},
+		Compression: CompressionConfig{
+			Enabled: true,
+			MinSize: 1024,
+			Brotli:  true,
+		},
+		HTTPCache: HTTPCacheConfig{
+			Enabled:     true,
+			StatsMaxAge: 30 * time.Second,
+		},
+		Security: SecurityConfig{
+			Enabled:                   true,
+			ContentTypeOptions:        "nosniff",
+			FrameOptions:              "DENY",
+			ReferrerPolicy:            "no-referrer",
+			ContentSecurityPolicy:     "default-src 'none'; frame-ancestors 'none'",
+			DocsContentSecurityPolicy: "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https:; font-src 'self' data: https:; frame-ancestors 'none'",
+		},
+	}
+}
+
+// Load 設定ファイルと環境変数から設定を読み込み、シークレット参照（vault:// 等）を実際の値に置き換える
+// pathが空の場合はCONFIG_FILE環境変数、それも空の場合はconfig.yaml（存在する場合のみ）を読み込む
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	explicit := true
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		path = DefaultFile
+		explicit = false
+	}
+
+	if err := loadFile(cfg, path); err != nil {
+		if !explicit && os.IsNotExist(err) {
+			// デフォルトの設定ファイルが無い場合は環境変数のみで構成する
+		} else {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if err := resolveSecrets(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile 拡張子に応じてYAMLまたはTOMLの設定ファイルを読み込む
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("未対応の設定ファイル形式です: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("設定ファイル %s の読み込みに失敗しました: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv env タグを持つフィールドを環境変数で上書き
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		structField := t.Field(i)
+
+		if field.Kind() == reflect.Struct && structField.Type != reflect.TypeOf(time.Duration(0)) {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := structField.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(key)
+		if !ok || value == "" {
+			continue
+		}
+
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("環境変数 %s の値が不正です: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setField 文字列の値をフィールドの型に変換して設定
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		parts := strings.Split(value, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		field.Set(reflect.ValueOf(parts))
+	default:
+		return fmt.Errorf("未対応の型です: %s", field.Kind())
+	}
+
+	return nil
+}
+
+// current 実行中に有効な設定（ホットリロードで差し替えられる）
+var current atomic.Pointer[Config]
+
+// Current 現在有効な設定を取得（未設定の場合はデフォルト設定）
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	return Default()
+}
+
+// SetCurrent 現在有効な設定を差し替える
+func SetCurrent(cfg *Config) {
+	current.Store(cfg)
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SecretsConfig シークレットプロバイダー（Vault / AWS Secrets Manager）の設定
+// 設定値に vault://パス#キー、awssm://シークレットID#キー、file:///パス の形式で参照を書くと、読み込み時に実際の値へ置き換える
+type SecretsConfig struct {
+	VaultAddr      string `yaml:"vault_addr" toml:"vault_addr" env:"VAULT_ADDR"`
+	VaultToken     string `yaml:"vault_token" toml:"vault_token" env:"VAULT_TOKEN"`
+	VaultNamespace string `yaml:"vault_namespace" toml:"vault_namespace" env:"VAULT_NAMESPACE"`
+	AWSRegion      string `yaml:"aws_region" toml:"aws_region" env:"AWS_REGION"`
+	// AWSEndpoint Secrets ManagerのエンドポイントURL（LocalStack等を使う場合のみ指定）
+	AWSEndpoint string `yaml:"aws_endpoint" toml:"aws_endpoint" env:"AWS_SECRETS_MANAGER_ENDPOINT"`
+	// Timeout 取得1件あたりのタイムアウト
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"SECRETS_TIMEOUT"`
+	// RefreshInterval ローテーションに追従するため再取得する間隔（0で無効）
+	RefreshInterval time.Duration `yaml:"refresh_interval" toml:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL"`
+}
+
+// SecretProvider シークレットの取得元
+type SecretProvider interface {
+	// GetSecret pathのシークレットを取得（keyが空でない場合はその項目の値を返す）
+	GetSecret(ctx context.Context, path, key string) (string, error)
+}
+
+// secretSchemes 参照のスキームとプロバイダーの生成関数
+var secretSchemes = map[string]func(cfg SecretsConfig) (SecretProvider, error){
+	"vault": newVaultProvider,
+	"awssm": newAWSSecretsManagerProvider,
+	"file":  func(SecretsConfig) (SecretProvider, error) { return fileProvider{}, nil },
+}
+
+// resolveSecrets 文字列フィールドのシークレット参照を取得した値に置き換える
+// プロバイダー自体の設定（VAULT_TOKEN を file:// で指定する等）を先に解決する
+func resolveSecrets(cfg *Config) error {
+	providers := map[string]SecretProvider{}
+
+	if err := walkStrings(reflect.ValueOf(&cfg.Secrets).Elem(), resolveField(cfg, providers)); err != nil {
+		return err
+	}
+	return walkStrings(reflect.ValueOf(cfg).Elem(), resolveField(cfg, providers))
+}
+
+// resolveField 文字列フィールドがシークレット参照であれば取得した値に置き換える関数を返す
+func resolveField(cfg *Config, providers map[string]SecretProvider) func(field reflect.Value) error {
+	return func(field reflect.Value) error {
+		ref := field.String()
+		scheme, path, key, ok := parseSecretRef(ref)
+		if !ok {
+			return nil
+		}
+
+		provider, ok := providers[scheme]
+		if !ok {
+			var err error
+			if provider, err = secretSchemes[scheme](cfg.Secrets); err != nil {
+				return err
+			}
+			providers[scheme] = provider
+		}
+
+		timeout := cfg.Secrets.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		value, err := provider.GetSecret(ctx, path, key)
+		if err != nil {
+			return fmt.Errorf("シークレット %s の取得に失敗しました: %w", ref, err)
+		}
+		field.SetString(value)
+		return nil
+	}
+}
+
+// parseSecretRef scheme://path#key 形式の参照を分解（未対応のスキームの場合はok=false）
+func parseSecretRef(value string) (scheme, path, key string, ok bool) {
+	scheme, rest, found := strings.Cut(value, "://")
+	if !found {
+		return "", "", "", false
+	}
+	if _, supported := secretSchemes[scheme]; !supported {
+		return "", "", "", false
+	}
+	path, key, _ = strings.Cut(rest, "#")
+	return scheme, path, key, path != ""
+}
+
+// walkStrings 構造体内の全ての文字列フィールドに対してfnを呼び出す
+func walkStrings(v reflect.Value, fn func(field reflect.Value) error) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := walkStrings(field, fn); err != nil {
+				return err
+			}
+		case reflect.String:
+			if err := fn(field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fileProvider ファイルの内容をシークレットとして読み込む（Docker/Kubernetesのシークレットマウント用）
+type fileProvider struct{}
+
+func (fileProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if key == "" {
+		return value, nil
+	}
+	return lookupJSONKey(value, key)
+}
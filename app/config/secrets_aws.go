@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// awsSecretsManagerProvider AWS Secrets ManagerのGetSecretValue APIから取得するプロバイダー
+// 認証情報は AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN 環境変数から読み込む（署名は aws-sdk-go-v2 が行う）
+type awsSecretsManagerProvider struct {
+	client *secretsmanager.Client
+}
+
+func newAWSSecretsManagerProvider(cfg SecretsConfig) (SecretProvider, error) {
+	region := cfg.AWSRegion
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, errors.New("AWS Secrets Managerのシークレットを参照するには AWS_REGION が必要です")
+	}
+	accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("AWS Secrets Managerのシークレットを参照するには AWS_ACCESS_KEY_ID と AWS_SECRET_ACCESS_KEY が必要です")
+	}
+
+	client := secretsmanager.NewFromConfig(aws.Config{
+		Region:      region,
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, os.Getenv("AWS_SESSION_TOKEN"))),
+		HTTPClient:  &http.Client{},
+	}, func(o *secretsmanager.Options) {
+		if cfg.AWSEndpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.AWSEndpoint)
+		}
+	})
+	return &awsSecretsManagerProvider{client: client}, nil
+}
+
+// GetSecret pathはシークレットIDまたはARN（keyを指定した場合はJSON形式のシークレットから項目を取り出す）
+func (p *awsSecretsManagerProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(path)})
+	if err != nil {
+		return "", fmt.Errorf("AWS Secrets Managerからシークレットを取得できません: %w", err)
+	}
+
+	if key == "" {
+		return aws.ToString(out.SecretString), nil
+	}
+	return lookupJSONKey(aws.ToString(out.SecretString), key)
+}
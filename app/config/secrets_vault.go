@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// vaultProvider HashiCorp VaultのKVシークレットエンジンから取得するプロバイダー（KV v1 / v2 対応）
+type vaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultProvider(cfg SecretsConfig) (SecretProvider, error) {
+	if cfg.VaultAddr == "" || cfg.VaultToken == "" {
+		return nil, errors.New("Vaultのシークレットを参照するには VAULT_ADDR と VAULT_TOKEN が必要です")
+	}
+	return &vaultProvider{
+		addr:      strings.TrimRight(cfg.VaultAddr, "/"),
+		token:     cfg.VaultToken,
+		namespace: cfg.VaultNamespace,
+		client:    &http.Client{},
+	}, nil
+}
+
+// GetSecret pathはマウントを含むAPIパス（KV v2の場合は secret/data/todo のように data を含める）
+func (p *vaultProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vaultが %d を返しました", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("Vaultのレスポンスを解析できません: %w", err)
+	}
+
+	// KV v2 は data.data に値が入る
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	if key == "" {
+		if len(data) != 1 {
+			return "", errors.New("複数の項目を持つシークレットは #キー で項目を指定してください")
+		}
+		for _, value := range data {
+			return fmt.Sprint(value), nil
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("項目 %q がありません", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// lookupJSONKey JSONオブジェクト形式のシークレットから項目を取り出す
+func lookupJSONKey(raw, key string) (string, error) {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return "", fmt.Errorf("項目 %q を指定しましたが、シークレットがJSONオブジェクトではありません", key)
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("項目 %q がありません", key)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,976 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError 設定項目ごとの検証エラー
+type FieldError struct {
+	// Field 設定ファイル上のキー（例: server.port）
+	Field string
+	// Env 上書きに使う環境変数名
+	Env     string
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Env != "" {
+		return fmt.Sprintf("%s (%s): %s", e.Field, e.Env, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError 設定の検証エラー（問題のある項目をすべて保持する）
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		lines = append(lines, fe.String())
+	}
+	return fmt.Sprintf("設定に %d 件の問題があります: %s", len(e.Errors), strings.Join(lines, "; "))
+}
+
+func (e *ValidationError) add(field, env, format string, args ...any) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Env: env, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate 必須設定の欠落や不正値を検証
+// 問題がある場合は *ValidationError を返す
+func (c *Config) Validate() error {
+	v := &ValidationError{}
+
+	// サーバー
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		v.add("server.port", "PORT", "1〜65535の範囲で指定してください（現在: %d）", c.Server.Port)
+	}
+	if c.Server.BindAddress != "" && net.ParseIP(c.Server.BindAddress) == nil && strings.ContainsAny(c.Server.BindAddress, ":/ ") {
+		v.add("server.bind_address", "BIND_ADDRESS", "ポートを含まないIPアドレスまたはホスト名を指定してください（現在: %q）", c.Server.BindAddress)
+	}
+	if c.Server.ReadTimeout < 0 {
+		v.add("server.read_timeout", "SERVER_READ_TIMEOUT", "0以上の時間を指定してください（現在: %s）", c.Server.ReadTimeout)
+	}
+	if c.Server.WriteTimeout < 0 {
+		v.add("server.write_timeout", "SERVER_WRITE_TIMEOUT", "0以上の時間を指定してください（現在: %s）", c.Server.WriteTimeout)
+	} else if c.Server.WriteTimeout > 0 && c.Timeout.Default > c.Server.WriteTimeout {
+		// 書き込みの上限が先に来るとタイムアウトの504を返せずに接続が切れる
+		v.add("server.write_timeout", "SERVER_WRITE_TIMEOUT", "REQUEST_TIMEOUT（%s）以上を指定してください（現在: %s）", c.Timeout.Default, c.Server.WriteTimeout)
+	}
+	if c.Server.IdleTimeout < 0 {
+		v.add("server.idle_timeout", "SERVER_IDLE_TIMEOUT", "0以上の時間を指定してください（現在: %s）", c.Server.IdleTimeout)
+	}
+	if c.Server.UnixSocket != "" {
+		if mode, err := strconv.ParseUint(c.Server.UnixSocketMode, 8, 32); err != nil || mode > 0o777 {
+			v.add("server.unix_socket_mode", "UNIX_SOCKET_MODE", "8進数のパーミッション（例: 0660）を指定してください（現在: %q）", c.Server.UnixSocketMode)
+		}
+		if len(c.Server.AutocertDomains) > 0 {
+			// HTTP-01チャレンジはTCPの80番ポートで受ける必要があり、TLSの終端はリバースプロキシで行う
+			v.add("server.unix_socket", "UNIX_SOCKET", "autocert（AUTOCERT_DOMAINS）と同時には指定できません")
+		}
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		v.add("server.tls_cert_file", "TLS_CERT_FILE", "証明書ファイルと秘密鍵ファイル（TLS_KEY_FILE）は両方指定してください")
+	}
+	if len(c.Server.AutocertDomains) > 0 && c.Server.AutocertCacheDir == "" {
+		v.add("server.autocert_cache_dir", "AUTOCERT_CACHE_DIR", "autocert使用時は必須です")
+	}
+	if c.Server.TLSClientCAFile != "" && !c.Server.TLSEnabled() {
+		v.add("server.tls_client_ca_file", "TLS_CLIENT_CA_FILE", "HTTPS（証明書ファイルまたはautocert）が有効な場合のみ指定できます")
+	}
+	switch strings.ToLower(c.Server.TLSClientAuth) {
+	case "", "none", "request", "verify_if_given", "require":
+	default:
+		v.add("server.tls_client_auth", "TLS_CLIENT_AUTH", "require / verify_if_given / request / none のいずれかを指定してください（現在: %q）", c.Server.TLSClientAuth)
+	}
+	if c.Server.TLSReloadInterval < 0 {
+		v.add("server.tls_reload_interval", "TLS_RELOAD_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Server.TLSReloadInterval)
+	}
+	if c.Server.HTTPRedirectPort != 0 {
+		if c.Server.HTTPRedirectPort < 1 || c.Server.HTTPRedirectPort > 65535 {
+			v.add("server.http_redirect_port", "HTTP_REDIRECT_PORT", "1〜65535の範囲で指定してください（現在: %d）", c.Server.HTTPRedirectPort)
+		} else if c.Server.HTTPRedirectPort == c.Server.Port {
+			v.add("server.http_redirect_port", "HTTP_REDIRECT_PORT", "server.port と異なるポートを指定してください")
+		}
+		if !c.Server.TLSEnabled() {
+			v.add("server.http_redirect_port", "HTTP_REDIRECT_PORT", "HTTPS（証明書ファイルまたはautocert）が有効な場合のみ指定できます")
+		}
+	}
+	switch c.Server.PathNormalize {
+	case "rewrite", "redirect", "off":
+	default:
+		v.add("server.path_normalize", "PATH_NORMALIZE", "rewrite / redirect / off のいずれかを指定してください（現在: %q）", c.Server.PathNormalize)
+	}
+
+	// 起動時の依存サービスの待機
+	if c.Startup.WaitTimeout < 0 {
+		v.add("startup.wait_timeout", "STARTUP_WAIT_TIMEOUT", "0以上の時間を指定してください（現在: %s）", c.Startup.WaitTimeout)
+	}
+	if c.Startup.WaitTimeout > 0 {
+		if c.Startup.BackoffBase <= 0 {
+			v.add("startup.backoff_base", "STARTUP_BACKOFF_BASE", "正の値を指定してください（現在: %s）", c.Startup.BackoffBase)
+		}
+		if c.Startup.BackoffMax < c.Startup.BackoffBase {
+			v.add("startup.backoff_max", "STARTUP_BACKOFF_MAX", "STARTUP_BACKOFF_BASE以上を指定してください（現在: %s）", c.Startup.BackoffMax)
+		}
+	}
+
+	// シャットダウン
+	if c.Shutdown.Timeout <= 0 {
+		v.add("shutdown.timeout", "SHUTDOWN_TIMEOUT", "正の値を指定してください（現在: %s）", c.Shutdown.Timeout)
+	}
+	if c.Shutdown.DrainDelay < 0 {
+		v.add("shutdown.drain_delay", "SHUTDOWN_DRAIN_DELAY", "0以上の時間を指定してください（現在: %s）", c.Shutdown.DrainDelay)
+	}
+
+	// トレーシング
+	if c.Tracing.Endpoint != "" && !isHTTPURL(c.Tracing.Endpoint) {
+		v.add("tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "http(s):// で始まるURLを指定してください（現在: %q）", c.Tracing.Endpoint)
+	}
+	if c.Tracing.ServiceName == "" {
+		v.add("tracing.service_name", "OTEL_SERVICE_NAME", "必須です")
+	}
+
+	// パニックのアラート
+	if c.PanicAlert.WebhookURL != "" && !isHTTPURL(c.PanicAlert.WebhookURL) {
+		v.add("panic_alert.webhook_url", "PANIC_ALERT_WEBHOOK_URL", "http(s):// で始まるURLを指定してください")
+	}
+	if c.PanicAlert.SlackWebhookURL != "" && !isHTTPURL(c.PanicAlert.SlackWebhookURL) {
+		v.add("panic_alert.slack_webhook_url", "PANIC_ALERT_SLACK_WEBHOOK_URL", "http(s):// で始まるURLを指定してください")
+	}
+	if c.PanicAlert.Cooldown < 0 {
+		v.add("panic_alert.cooldown", "PANIC_ALERT_COOLDOWN", "0以上の時間を指定してください（現在: %s）", c.PanicAlert.Cooldown)
+	}
+
+	// データベース
+	if c.Database.Host == "" {
+		v.add("database.host", "DB_HOST", "必須です")
+	}
+	if port, err := strconv.Atoi(c.Database.Port); err != nil || port < 1 || port > 65535 {
+		v.add("database.port", "DB_PORT", "1〜65535の数値で指定してください（現在: %q）", c.Database.Port)
+	}
+	if c.Database.User == "" {
+		v.add("database.user", "DB_USER", "必須です")
+	}
+	if c.Database.DBName == "" {
+		v.add("database.dbname", "DB_NAME", "必須です")
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		v.add("database.sslmode", "DB_SSLMODE", "disable / allow / prefer / require / verify-ca / verify-full のいずれかを指定してください（現在: %q）", c.Database.SSLMode)
+	}
+	if timeout, err := strconv.Atoi(c.Database.ConnectTimeout); err != nil || timeout < 0 {
+		v.add("database.connect_timeout", "DB_CONNECT_TIMEOUT", "0以上の秒数で指定してください（現在: %q）", c.Database.ConnectTimeout)
+	}
+	if c.Database.MaxOpenConns < 0 {
+		v.add("database.max_open_conns", "DB_MAX_OPEN_CONNS", "0以上を指定してください（0で無制限、現在: %d）", c.Database.MaxOpenConns)
+	}
+	if c.Database.MaxIdleConns < 0 {
+		v.add("database.max_idle_conns", "DB_MAX_IDLE_CONNS", "0以上を指定してください（現在: %d）", c.Database.MaxIdleConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		v.add("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "0以上の時間を指定してください（現在: %s）", c.Database.ConnMaxLifetime)
+	}
+	if c.Database.ConnMaxIdleTime < 0 {
+		v.add("database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", "0以上の時間を指定してください（現在: %s）", c.Database.ConnMaxIdleTime)
+	}
+	if c.Database.Breaker.FailureThreshold < 1 {
+		v.add("database.breaker.failure_threshold", "DB_BREAKER_FAILURE_THRESHOLD", "1以上を指定してください（現在: %d）", c.Database.Breaker.FailureThreshold)
+	}
+	if c.Database.Breaker.OpenTimeout <= 0 {
+		v.add("database.breaker.open_timeout", "DB_BREAKER_OPEN_TIMEOUT", "正の値を指定してください（現在: %s）", c.Database.Breaker.OpenTimeout)
+	}
+
+	// ログ
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		v.add("log.level", "LOG_LEVEL", "debug / info / warn / error のいずれかを指定してください（現在: %q）", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "text", "json":
+	default:
+		v.add("log.format", "LOG_FORMAT", "text / json のいずれかを指定してください（現在: %q）", c.Log.Format)
+	}
+	if c.AccessLog.SampleRate < 0 || c.AccessLog.SampleRate > 1 {
+		v.add("access_log.sample_rate", "ACCESS_LOG_SAMPLE_RATE", "0〜1の範囲で指定してください（現在: %g）", c.AccessLog.SampleRate)
+	}
+
+	// CORS
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				v.add("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "allow_credentials=true の場合は * を使用できません。オリジンを列挙してください")
+			}
+			continue
+		}
+		if !isHTTPURL(strings.Replace(origin, "://*.", "://", 1)) {
+			v.add("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "オリジンは *、http(s)://host または http(s)://*.domain 形式で指定してください（現在: %q）", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		v.add("cors.max_age", "CORS_MAX_AGE", "0以上の秒数を指定してください（現在: %d）", c.CORS.MaxAge)
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		v.add("cors.allowed_methods", "CORS_ALLOWED_METHODS", "1つ以上指定してください")
+	}
+
+	// LLM
+	if c.LLM.BaseURL != "" && !isHTTPURL(c.LLM.BaseURL) {
+		v.add("llm.base_url", "LLM_BASE_URL", "http(s):// で始まるURLを指定してください（現在: %q）", c.LLM.BaseURL)
+	}
+	if c.LLM.Enabled {
+		if c.LLM.APIKey == "" {
+			v.add("llm.api_key", "LLM_API_KEY", "LLM機能が有効な場合は必須です")
+		}
+		if c.LLM.Model == "" {
+			v.add("llm.model", "LLM_MODEL", "LLM機能が有効な場合は必須です")
+		}
+		if c.LLM.Provider != "openai" && c.LLM.Provider != "anthropic" {
+			v.add("llm.provider", "LLM_PROVIDER", "openai または anthropic を指定してください（現在: %q）", c.LLM.Provider)
+		}
+		if c.LLM.Timeout <= 0 {
+			v.add("llm.timeout", "LLM_TIMEOUT", "正の時間を指定してください（現在: %s）", c.LLM.Timeout)
+		}
+	}
+
+	// レートリミット
+	validateIPList(v, "rate_limit.trusted_proxies", "RATE_LIMIT_TRUSTED_PROXIES", c.RateLimit.TrustedProxies)
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RequestsPerSecond <= 0 {
+			v.add("rate_limit.requests_per_second", "RATE_LIMIT_RPS", "0より大きい値を指定してください（現在: %g）", c.RateLimit.RequestsPerSecond)
+		}
+		if c.RateLimit.Burst < 1 {
+			v.add("rate_limit.burst", "RATE_LIMIT_BURST", "1以上を指定してください（現在: %d）", c.RateLimit.Burst)
+		}
+		switch c.RateLimit.Backend {
+		case "memory":
+		case "redis":
+			if c.RateLimit.RedisAddr == "" {
+				v.add("rate_limit.redis_addr", "RATE_LIMIT_REDIS_ADDR", "backend=redis の場合は必須です（REDIS_ADDRでも可）")
+			}
+		default:
+			v.add("rate_limit.backend", "RATE_LIMIT_BACKEND", "memory / redis のいずれかを指定してください（現在: %q）", c.RateLimit.Backend)
+		}
+		for i, rule := range c.RateLimit.Paths {
+			field := fmt.Sprintf("rate_limit.paths[%d]", i)
+			if !strings.HasPrefix(rule.Prefix, "/") {
+				v.add(field+".prefix", "", "/ で始まるパスを指定してください（現在: %q）", rule.Prefix)
+			}
+			if rule.RequestsPerSecond <= 0 {
+				v.add(field+".requests_per_second", "", "0より大きい値を指定してください（現在: %g）", rule.RequestsPerSecond)
+			}
+			if rule.Burst < 1 {
+				v.add(field+".burst", "", "1以上を指定してください（現在: %d）", rule.Burst)
+			}
+		}
+	}
+
+	// リクエストボディサイズ
+	if c.BodyLimit.MaxBytes < 0 {
+		v.add("body_limit.max_bytes", "BODY_LIMIT_MAX_BYTES", "0以上のバイト数を指定してください（現在: %d）", c.BodyLimit.MaxBytes)
+	}
+	for i, rule := range c.BodyLimit.Paths {
+		field := fmt.Sprintf("body_limit.paths[%d]", i)
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			v.add(field+".prefix", "", "/ で始まるパスを指定してください（現在: %q）", rule.Prefix)
+		}
+		if rule.MaxBytes < 0 {
+			v.add(field+".max_bytes", "", "0以上のバイト数を指定してください（現在: %d）", rule.MaxBytes)
+		}
+	}
+
+	// リクエストタイムアウト
+	if c.Timeout.Default < 0 {
+		v.add("timeout.default", "REQUEST_TIMEOUT", "0以上の時間を指定してください（現在: %s）", c.Timeout.Default)
+	}
+	for i, rule := range c.Timeout.Paths {
+		field := fmt.Sprintf("timeout.paths[%d]", i)
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			v.add(field+".prefix", "", "/ で始まるパスを指定してください（現在: %q）", rule.Prefix)
+		}
+		if rule.Timeout < 0 {
+			v.add(field+".timeout", "", "0以上の時間を指定してください（現在: %s）", rule.Timeout)
+		}
+	}
+
+	// レスポンス圧縮
+	if c.Compression.MinSize < 0 {
+		v.add("compression.min_size", "COMPRESSION_MIN_SIZE", "0以上のバイト数を指定してください（現在: %d）", c.Compression.MinSize)
+	}
+	if c.Compression.Level < 0 || c.Compression.Level > 9 {
+		v.add("compression.level", "COMPRESSION_LEVEL", "0〜9で指定してください（現在: %d）", c.Compression.Level)
+	}
+
+	// HTTPキャッシュ
+	if c.HTTPCache.MaxAge < 0 {
+		v.add("http_cache.max_age", "HTTP_CACHE_MAX_AGE", "0以上の時間を指定してください（現在: %s）", c.HTTPCache.MaxAge)
+	}
+	if c.HTTPCache.StatsMaxAge < 0 {
+		v.add("http_cache.stats_max_age", "HTTP_CACHE_STATS_MAX_AGE", "0以上の時間を指定してください（現在: %s）", c.HTTPCache.StatsMaxAge)
+	}
+	if c.HTTPCache.RevalidateTTL < 0 {
+		v.add("http_cache.revalidate_ttl", "HTTP_CACHE_REVALIDATE_TTL", "0以上の時間を指定してください（現在: %s）", c.HTTPCache.RevalidateTTL)
+	}
+
+	// CSRF保護
+	if c.CSRF.Enabled {
+		if c.CSRF.CookieName == "" {
+			v.add("csrf.cookie_name", "CSRF_COOKIE_NAME", "Cookie名を指定してください")
+		}
+		if c.CSRF.HeaderName == "" {
+			v.add("csrf.header_name", "CSRF_HEADER_NAME", "ヘッダー名を指定してください")
+		}
+	}
+	switch strings.ToLower(c.CSRF.SameSite) {
+	case "", "lax", "strict":
+	case "none":
+		if !c.CSRF.CookieSecure {
+			v.add("csrf.same_site", "CSRF_SAME_SITE", "none を指定する場合は cookie_secure を有効にしてください")
+		}
+	default:
+		v.add("csrf.same_site", "CSRF_SAME_SITE", "lax / strict / none のいずれかを指定してください（現在: %q）", c.CSRF.SameSite)
+	}
+
+	// シークレットプロバイダー
+	if c.Secrets.RefreshInterval < 0 {
+		v.add("secrets.refresh_interval", "SECRETS_REFRESH_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Secrets.RefreshInterval)
+	}
+	if c.Secrets.VaultAddr != "" && !isHTTPURL(c.Secrets.VaultAddr) {
+		v.add("secrets.vault_addr", "VAULT_ADDR", "http(s)のURLを指定してください（現在: %q）", c.Secrets.VaultAddr)
+	}
+
+	// セッション
+	if c.Session.Enabled {
+		switch c.Session.Store {
+		case "cookie":
+			if len(c.Session.Secret) < 32 {
+				v.add("session.secret", "SESSION_SECRET", "Cookieストアでは32文字以上のシークレットが必要です")
+			}
+		case "redis":
+			if c.Session.RedisAddr == "" {
+				v.add("session.redis_addr", "SESSION_REDIS_ADDR", "Redisストアでは必須です")
+			}
+		default:
+			v.add("session.store", "SESSION_STORE", "cookie / redis のいずれかを指定してください（現在: %q）", c.Session.Store)
+		}
+		if c.Session.CookieName == "" {
+			v.add("session.cookie_name", "SESSION_COOKIE_NAME", "Cookie名を指定してください")
+		}
+		if c.Session.MaxAge <= 0 {
+			v.add("session.max_age", "SESSION_MAX_AGE", "正の時間を指定してください（現在: %s）", c.Session.MaxAge)
+		}
+	}
+	switch strings.ToLower(c.Session.SameSite) {
+	case "", "lax", "strict":
+	case "none":
+		if !c.Session.CookieSecure {
+			v.add("session.same_site", "SESSION_SAME_SITE", "none を指定する場合は cookie_secure を有効にしてください")
+		}
+	default:
+		v.add("session.same_site", "SESSION_SAME_SITE", "lax / strict / none のいずれかを指定してください（現在: %q）", c.Session.SameSite)
+	}
+
+	// リプレイ防止
+	if c.Replay.Enabled {
+		if len(c.Replay.Secrets) == 0 {
+			v.add("replay.secrets", "REPLAY_SECRETS", "署名の検証に使うシークレットを1つ以上指定してください")
+		}
+		if len(c.Replay.Paths) == 0 {
+			v.add("replay.paths", "REPLAY_PATHS", "検証するパスを1つ以上指定してください")
+		}
+		if c.Replay.Tolerance <= 0 {
+			v.add("replay.tolerance", "REPLAY_TOLERANCE", "正の時間を指定してください（現在: %s）", c.Replay.Tolerance)
+		}
+		switch c.Replay.NonceStore {
+		case "memory":
+		case "redis":
+			if c.Replay.RedisAddr == "" {
+				v.add("replay.redis_addr", "REPLAY_REDIS_ADDR", "nonce_store=redis の場合は必須です（REDIS_ADDRでも可）")
+			}
+		default:
+			v.add("replay.nonce_store", "REPLAY_NONCE_STORE", "memory / redis のいずれかを指定してください（現在: %q）", c.Replay.NonceStore)
+		}
+	}
+
+	// ドメインイベント
+	if c.Events.Enabled {
+		switch c.Events.Broker {
+		case "nats":
+			if u, err := url.Parse(c.Events.NATSURL); err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Host == "" {
+				v.add("events.nats_url", "EVENTS_NATS_URL", "nats:// または tls:// のURLを指定してください（現在: %q）", c.Events.NATSURL)
+			}
+			if strings.ContainsAny(c.Events.Topic, " \t*>") {
+				v.add("events.topic", "EVENTS_TOPIC", "NATSのサブジェクトに空白・ワイルドカードは使えません（現在: %q）", c.Events.Topic)
+			}
+		case "kafka":
+			if len(c.Events.KafkaBrokers) == 0 {
+				v.add("events.kafka_brokers", "EVENTS_KAFKA_BROKERS", "必須です")
+			}
+			for _, broker := range c.Events.KafkaBrokers {
+				if _, port, err := net.SplitHostPort(broker); err != nil || port == "" {
+					v.add("events.kafka_brokers", "EVENTS_KAFKA_BROKERS", "host:port の形式で指定してください（現在: %q）", broker)
+				}
+			}
+			switch c.Events.KafkaSASLMechanism {
+			case "plain", "scram-sha-256", "scram-sha-512":
+			default:
+				v.add("events.kafka_sasl_mechanism", "EVENTS_KAFKA_SASL_MECHANISM", "plain / scram-sha-256 / scram-sha-512 のいずれかを指定してください（現在: %q）", c.Events.KafkaSASLMechanism)
+			}
+		default:
+			v.add("events.broker", "EVENTS_BROKER", "nats / kafka のいずれかを指定してください（現在: %q）", c.Events.Broker)
+		}
+		if c.Events.Topic == "" {
+			v.add("events.topic", "EVENTS_TOPIC", "必須です")
+		}
+		if c.Events.Source == "" {
+			v.add("events.source", "EVENTS_SOURCE", "必須です")
+		}
+		if c.Events.BufferSize <= 0 {
+			v.add("events.buffer_size", "EVENTS_BUFFER_SIZE", "1以上を指定してください（現在: %d）", c.Events.BufferSize)
+		}
+	}
+
+	// キャッシュ
+	if c.Cache.Enabled {
+		switch c.Cache.Backend {
+		case "memory":
+			if c.Cache.MaxEntries < 1 {
+				v.add("cache.max_entries", "CACHE_MAX_ENTRIES", "1以上を指定してください（現在: %d）", c.Cache.MaxEntries)
+			}
+		case "redis":
+			if c.Cache.RedisAddr == "" {
+				v.add("cache.redis_addr", "CACHE_REDIS_ADDR", "必須です（REDIS_ADDRでも可）")
+			}
+		default:
+			v.add("cache.backend", "CACHE_BACKEND", "memory / redis のいずれかを指定してください（現在: %q）", c.Cache.Backend)
+		}
+		// 名前空間の世代番号は書き込みがなければ7日で消えるため、キャッシュはそれより十分短い期間だけ保持する
+		if c.Cache.TTL <= 0 || c.Cache.TTL > 24*time.Hour {
+			v.add("cache.ttl", "CACHE_TTL", "0より長く24h以下の時間を指定してください（現在: %s）", c.Cache.TTL)
+		}
+		if c.Cache.StatsTTL <= 0 || c.Cache.StatsTTL > 24*time.Hour {
+			v.add("cache.stats_ttl", "CACHE_STATS_TTL", "0より長く24h以下の時間を指定してください（現在: %s）", c.Cache.StatsTTL)
+		}
+	}
+
+	// 一覧API
+	switch c.List.CountMode {
+	case "exact", "estimated", "none":
+	default:
+		v.add("list.count_mode", "LIST_COUNT_MODE", "exact / estimated / none のいずれかを指定してください（現在: %q）", c.List.CountMode)
+	}
+
+	// 外部通知
+	if c.Notify.OverdueCheckInterval < 0 {
+		v.add("notify.overdue_check_interval", "NOTIFY_OVERDUE_CHECK_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Notify.OverdueCheckInterval)
+	}
+	if c.Notify.Slack.Enabled {
+		if c.Notify.Slack.BotToken != "" {
+			if c.Notify.Slack.Channel == "" {
+				v.add("notify.slack.channel", "SLACK_CHANNEL", "bot_token を指定する場合は投稿先のチャンネルが必要です")
+			}
+		} else if !isHTTPURL(c.Notify.Slack.WebhookURL) {
+			v.add("notify.slack.webhook_url", "SLACK_WEBHOOK_URL", "Incoming WebhookのURL（またはbot_token）を指定してください")
+		}
+		validateNotifyFilter(v, "notify.slack", "SLACK_NOTIFY", c.Notify.Slack.Events, c.Notify.Slack.MinPriority)
+	}
+	if c.Notify.Discord.Enabled {
+		if !isHTTPURL(c.Notify.Discord.WebhookURL) {
+			v.add("notify.discord.webhook_url", "DISCORD_WEBHOOK_URL", "Discord WebhookのURLを指定してください")
+		}
+		validateNotifyFilter(v, "notify.discord", "DISCORD_NOTIFY", c.Notify.Discord.Events, c.Notify.Discord.MinPriority)
+	}
+	if c.Notify.Mattermost.Enabled {
+		if !isHTTPURL(c.Notify.Mattermost.WebhookURL) {
+			v.add("notify.mattermost.webhook_url", "MATTERMOST_WEBHOOK_URL", "Mattermost Incoming WebhookのURLを指定してください")
+		}
+		validateNotifyFilter(v, "notify.mattermost", "MATTERMOST_NOTIFY", c.Notify.Mattermost.Events, c.Notify.Mattermost.MinPriority)
+	}
+	if c.Notify.Teams.Enabled {
+		if !isHTTPURL(c.Notify.Teams.WebhookURL) {
+			v.add("notify.teams.webhook_url", "TEAMS_WEBHOOK_URL", "Teams Incoming WebhookのURLを指定してください")
+		}
+		validateNotifyFilter(v, "notify.teams", "TEAMS_NOTIFY", c.Notify.Teams.Events, c.Notify.Teams.MinPriority)
+	}
+	if c.Notify.RemindBefore < 0 {
+		v.add("notify.remind_before", "NOTIFY_REMIND_BEFORE", "0以上の時間を指定してください（現在: %s）", c.Notify.RemindBefore)
+	}
+	if c.Notify.RenotifyInterval != 0 && c.Notify.RenotifyInterval < time.Minute {
+		v.add("notify.renotify_interval", "NOTIFY_RENOTIFY_INTERVAL", "0（再通知しない）または1分以上の時間を指定してください（現在: %s）", c.Notify.RenotifyInterval)
+	}
+	if email := c.Notify.Email; email.Enabled {
+		if email.SMTPHost == "" {
+			v.add("notify.email.smtp_host", "SMTP_HOST", "必須です")
+		}
+		if email.SMTPPort < 1 || email.SMTPPort > 65535 {
+			v.add("notify.email.smtp_port", "SMTP_PORT", "1〜65535の範囲で指定してください（現在: %d）", email.SMTPPort)
+		}
+		if _, err := mail.ParseAddress(email.From); err != nil {
+			v.add("notify.email.from", "EMAIL_FROM", "送信元のメールアドレスを指定してください（現在: %q）", email.From)
+		}
+		if len(email.To) == 0 {
+			v.add("notify.email.to", "EMAIL_TO", "送信先のメールアドレスを1つ以上指定してください")
+		}
+		for _, to := range email.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				v.add("notify.email.to", "EMAIL_TO", "メールアドレスが不正です（現在: %q）", to)
+			}
+		}
+		if email.MaxRetries < 0 || email.MaxRetries > 10 {
+			v.add("notify.email.max_retries", "EMAIL_MAX_RETRIES", "0〜10の範囲で指定してください（現在: %d）", email.MaxRetries)
+		}
+		if email.DigestTime != "" {
+			if _, err := time.Parse("15:04", email.DigestTime); err != nil {
+				v.add("notify.email.digest_time", "EMAIL_DIGEST_TIME", "HH:MM形式で指定してください（現在: %q）", email.DigestTime)
+			}
+		}
+		validateNotifyFilter(v, "notify.email", "EMAIL_NOTIFY", email.Events, email.MinPriority)
+	}
+	if push := c.Notify.WebPush; push.Enabled {
+		if push.VAPIDPrivateKey == "" {
+			v.add("notify.webpush.vapid_private_key", "VAPID_PRIVATE_KEY", "必須です（-generate-vapid-keys で生成できます）")
+		}
+		if !strings.HasPrefix(push.Subject, "mailto:") && !strings.HasPrefix(push.Subject, "https://") {
+			v.add("notify.webpush.subject", "VAPID_SUBJECT", "mailto: または https:// で始まる連絡先を指定してください（現在: %q）", push.Subject)
+		}
+		if push.TTL < 0 || push.TTL > 28*24*time.Hour {
+			v.add("notify.webpush.ttl", "WEBPUSH_TTL", "0〜28日の範囲で指定してください（現在: %s）", push.TTL)
+		}
+		validateNotifyFilter(v, "notify.webpush", "WEBPUSH_NOTIFY", push.Events, push.MinPriority)
+	}
+
+	// Telegramボット
+	if c.Telegram.Enabled {
+		if c.Telegram.BotToken == "" {
+			v.add("telegram.bot_token", "TELEGRAM_BOT_TOKEN", "必須です")
+		}
+		if len(c.Telegram.AllowedChatIDs) == 0 {
+			v.add("telegram.allowed_chat_ids", "TELEGRAM_ALLOWED_CHAT_IDS", "操作を許可するチャットIDを1つ以上指定してください")
+		}
+		for _, id := range c.Telegram.AllowedChatIDs {
+			if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+				v.add("telegram.allowed_chat_ids", "TELEGRAM_ALLOWED_CHAT_IDS", "数値のチャットIDを指定してください（現在: %q）", id)
+			}
+		}
+	}
+
+	// 受信メールからのTodo作成
+	if c.IMAP.Enabled {
+		if _, port, err := net.SplitHostPort(c.IMAP.Addr); err != nil || port == "" {
+			v.add("imap.addr", "IMAP_ADDR", "host:port の形式で指定してください（現在: %q）", c.IMAP.Addr)
+		}
+		if c.IMAP.Username == "" {
+			v.add("imap.username", "IMAP_USERNAME", "必須です")
+		}
+		if c.IMAP.Password == "" {
+			v.add("imap.password", "IMAP_PASSWORD", "必須です")
+		}
+		if c.IMAP.Mailbox == "" {
+			v.add("imap.mailbox", "IMAP_MAILBOX", "必須です")
+		}
+		if c.IMAP.PollInterval <= 0 {
+			v.add("imap.poll_interval", "IMAP_POLL_INTERVAL", "正の時間を指定してください（現在: %s）", c.IMAP.PollInterval)
+		}
+		if len(c.IMAP.AllowedSenders) == 0 {
+			v.add("imap.allowed_senders", "IMAP_ALLOWED_SENDERS", "Todoを作成できる送信者を1つ以上指定してください")
+		}
+		for _, sender := range c.IMAP.AllowedSenders {
+			if !strings.Contains(sender, "@") {
+				v.add("imap.allowed_senders", "IMAP_ALLOWED_SENDERS", "メールアドレスまたは @example.com の形式で指定してください（現在: %q）", sender)
+			}
+		}
+	}
+
+	// 定期実行ジョブのスケジューラー（スケジュールの書式は起動時にスケジューラーが検証する）
+	if c.Scheduler.Timezone != "" {
+		if _, err := time.LoadLocation(c.Scheduler.Timezone); err != nil {
+			v.add("scheduler.timezone", "SCHEDULER_TIMEZONE", "IANAのタイムゾーン名（例: Asia/Tokyo）を指定してください（現在: %q）", c.Scheduler.Timezone)
+		}
+	}
+	if c.Scheduler.LockLease < time.Minute {
+		v.add("scheduler.lock_lease", "SCHEDULER_LOCK_LEASE", "1m以上を指定してください（現在: %s）", c.Scheduler.LockLease)
+	}
+	if c.Scheduler.Purge.Schedule != "" && c.Scheduler.Purge.Retention < 24*time.Hour {
+		v.add("scheduler.purge.retention", "SCHEDULER_PURGE_RETENTION", "24h以上を指定してください（現在: %s）", c.Scheduler.Purge.Retention)
+	}
+
+	// 繰り返しTodo
+	if c.Recurrence.HolidayCalendar != "jp" && c.Recurrence.HolidayCalendar != "none" {
+		v.add("recurrence.holiday_calendar", "RECURRENCE_HOLIDAY_CALENDAR", "jp / none のいずれかを指定してください（現在: %q）", c.Recurrence.HolidayCalendar)
+	}
+	for _, date := range c.Recurrence.Holidays {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			v.add("recurrence.holidays", "RECURRENCE_HOLIDAYS", "YYYY-MM-DDの形式で指定してください（現在: %q）", date)
+		}
+	}
+
+	// エクスポートのスナップショット
+	if len(c.Export.Formats) == 0 {
+		v.add("export.formats", "EXPORT_FORMATS", "json / csv の1つ以上を指定してください")
+	}
+	for _, format := range c.Export.Formats {
+		if format != "json" && format != "csv" {
+			v.add("export.formats", "EXPORT_FORMATS", "json / csv のいずれかを指定してください（現在: %q）", format)
+		}
+	}
+	if c.Export.Keep < 0 {
+		v.add("export.keep", "EXPORT_KEEP", "0以上を指定してください（現在: %d）", c.Export.Keep)
+	}
+
+	// 放置タスクの検出
+	if c.Stale.After < time.Hour {
+		v.add("stale.after", "STALE_AFTER", "1h以上を指定してください（現在: %s）", c.Stale.After)
+	}
+	switch c.Stale.Action {
+	case "flag", "notify", "both":
+	default:
+		v.add("stale.action", "STALE_ACTION", "flag / notify / both のいずれかを指定してください（現在: %q）", c.Stale.Action)
+	}
+
+	// 一括操作
+	if c.Bulk.Concurrency < 1 || c.Bulk.Concurrency > 64 {
+		v.add("bulk.concurrency", "BULK_CONCURRENCY", "1〜64を指定してください（現在: %d）", c.Bulk.Concurrency)
+	}
+	if c.Bulk.MaxItems < 1 {
+		v.add("bulk.max_items", "BULK_MAX_ITEMS", "1以上を指定してください（現在: %d）", c.Bulk.MaxItems)
+	}
+	// PostgreSQLの1文あたりのパラメーター数の上限（65535）を超えないようにする
+	if c.Bulk.InsertBatchSize < 1 || c.Bulk.InsertBatchSize > 2000 {
+		v.add("bulk.insert_batch_size", "BULK_INSERT_BATCH_SIZE", "1〜2000を指定してください（現在: %d）", c.Bulk.InsertBatchSize)
+	}
+
+	// ジョブキュー
+	if c.Queue.Concurrency < 1 || c.Queue.Concurrency > 100 {
+		v.add("queue.concurrency", "QUEUE_CONCURRENCY", "1〜100を指定してください（現在: %d）", c.Queue.Concurrency)
+	}
+	if c.Queue.PollInterval < 100*time.Millisecond {
+		v.add("queue.poll_interval", "QUEUE_POLL_INTERVAL", "100ms以上を指定してください（現在: %s）", c.Queue.PollInterval)
+	}
+	if c.Queue.LockLease < 10*time.Second {
+		v.add("queue.lock_lease", "QUEUE_LOCK_LEASE", "10s以上を指定してください（現在: %s）", c.Queue.LockLease)
+	}
+	if c.Queue.MaxAttempts < 1 {
+		v.add("queue.max_attempts", "QUEUE_MAX_ATTEMPTS", "1以上を指定してください（現在: %d）", c.Queue.MaxAttempts)
+	}
+	if c.Queue.BackoffBase <= 0 {
+		v.add("queue.backoff_base", "QUEUE_BACKOFF_BASE", "正の値を指定してください（現在: %s）", c.Queue.BackoffBase)
+	}
+	if c.Queue.BackoffMax < c.Queue.BackoffBase {
+		v.add("queue.backoff_max", "QUEUE_BACKOFF_MAX", "QUEUE_BACKOFF_BASE以上を指定してください（現在: %s）", c.Queue.BackoffMax)
+	}
+	if c.Queue.Retention < time.Hour {
+		v.add("queue.retention", "QUEUE_RETENTION", "1h以上を指定してください（現在: %s）", c.Queue.Retention)
+	}
+
+	// Googleカレンダー同期
+	if c.Calendar.Enabled {
+		if c.Calendar.ClientID == "" {
+			v.add("calendar.client_id", "GOOGLE_CLIENT_ID", "必須です")
+		}
+		if c.Calendar.ClientSecret == "" {
+			v.add("calendar.client_secret", "GOOGLE_CLIENT_SECRET", "必須です")
+		}
+		if !isHTTPURL(c.Calendar.RedirectURL) {
+			v.add("calendar.redirect_url", "GOOGLE_REDIRECT_URL", "コールバックのURLを指定してください（現在: %q）", c.Calendar.RedirectURL)
+		}
+		if c.Calendar.CalendarID == "" {
+			v.add("calendar.calendar_id", "GOOGLE_CALENDAR_ID", "必須です")
+		}
+		if c.Calendar.SyncInterval < 0 {
+			v.add("calendar.sync_interval", "GOOGLE_CALENDAR_SYNC_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Calendar.SyncInterval)
+		}
+		if c.Calendar.EventDuration <= 0 {
+			v.add("calendar.event_duration", "GOOGLE_CALENDAR_EVENT_DURATION", "正の時間を指定してください（現在: %s）", c.Calendar.EventDuration)
+		}
+	}
+
+	// Microsoft To Doからの取り込み
+	if c.MSTodo.Enabled {
+		if c.MSTodo.ClientID == "" {
+			v.add("microsoft_todo.client_id", "MICROSOFT_CLIENT_ID", "必須です")
+		}
+		if c.MSTodo.ClientSecret == "" {
+			v.add("microsoft_todo.client_secret", "MICROSOFT_CLIENT_SECRET", "必須です")
+		}
+		if !isHTTPURL(c.MSTodo.RedirectURL) {
+			v.add("microsoft_todo.redirect_url", "MICROSOFT_REDIRECT_URL", "コールバックのURLを指定してください（現在: %q）", c.MSTodo.RedirectURL)
+		}
+		if c.MSTodo.Tenant == "" || strings.ContainsAny(c.MSTodo.Tenant, "/?#") {
+			v.add("microsoft_todo.tenant", "MICROSOFT_TENANT", "common・organizations・consumers またはテナントIDを指定してください（現在: %q）", c.MSTodo.Tenant)
+		}
+		for _, entry := range c.MSTodo.ListProjects {
+			if name, _, ok := strings.Cut(entry, "="); !ok || strings.TrimSpace(name) == "" {
+				v.add("microsoft_todo.list_projects", "MICROSOFT_TODO_LIST_PROJECTS", "\"リスト名=プロジェクト名\" の形式で指定してください（現在: %q）", entry)
+			}
+		}
+	}
+
+	// GitHub Issue同期
+	if c.GitHub.Enabled {
+		if c.GitHub.Token == "" {
+			v.add("github.token", "GITHUB_TOKEN", "必須です")
+		}
+		if owner, repo, ok := strings.Cut(c.GitHub.Repository, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			v.add("github.repository", "GITHUB_REPOSITORY", "owner/repo の形式で指定してください（現在: %q）", c.GitHub.Repository)
+		}
+		if c.GitHub.PollInterval < 0 {
+			v.add("github.poll_interval", "GITHUB_POLL_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.GitHub.PollInterval)
+		}
+		if !isHTTPURL(c.GitHub.APIURL) {
+			v.add("github.api_url", "GITHUB_API_URL", "http(s)のURLを指定してください（現在: %q）", c.GitHub.APIURL)
+		}
+	}
+
+	// Jira連携
+	if c.Jira.Enabled {
+		if !isHTTPURL(c.Jira.BaseURL) {
+			v.add("jira.base_url", "JIRA_BASE_URL", "http(s)のURLを指定してください（現在: %q）", c.Jira.BaseURL)
+		}
+		if c.Jira.APIToken == "" {
+			v.add("jira.api_token", "JIRA_API_TOKEN", "必須です")
+		}
+		if strings.TrimSpace(c.Jira.JQL) == "" {
+			v.add("jira.jql", "JIRA_JQL", "必須です")
+		}
+		if c.Jira.DoneTransition == "" {
+			v.add("jira.done_transition", "JIRA_DONE_TRANSITION", "必須です")
+		}
+		if c.Jira.PollInterval < 0 {
+			v.add("jira.poll_interval", "JIRA_POLL_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Jira.PollInterval)
+		}
+		if c.Jira.Fields.Title == "" {
+			v.add("jira.fields.title", "JIRA_FIELD_TITLE", "必須です")
+		}
+		for _, entry := range c.Jira.Fields.PriorityMap {
+			name, priority, ok := strings.Cut(entry, "=")
+			if !ok || strings.TrimSpace(name) == "" || !isPriority(strings.TrimSpace(priority)) {
+				v.add("jira.fields.priority_map", "JIRA_PRIORITY_MAP", "<値>=<low|medium|high|urgent> の形式で指定してください（現在: %q）", entry)
+			}
+		}
+	}
+
+	// Notionエクスポート
+	if c.Notion.Enabled {
+		if c.Notion.Token == "" {
+			v.add("notion.token", "NOTION_TOKEN", "必須です")
+		}
+		if c.Notion.DatabaseID == "" {
+			v.add("notion.database_id", "NOTION_DATABASE_ID", "必須です")
+		}
+		if c.Notion.SyncInterval < 0 {
+			v.add("notion.sync_interval", "NOTION_SYNC_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Notion.SyncInterval)
+		}
+		if c.Notion.Properties.Title == "" {
+			v.add("notion.properties.title", "NOTION_PROPERTY_TITLE", "必須です")
+		}
+		if c.Notion.Properties.Completed != "" && (c.Notion.DoneStatus == "" || c.Notion.TodoStatus == "") {
+			v.add("notion.done_status", "NOTION_DONE_STATUS", "完了状態を書き込む場合は done_status と todo_status が必要です")
+		}
+		if !isHTTPURL(c.Notion.APIURL) {
+			v.add("notion.api_url", "NOTION_API_URL", "http(s)のURLを指定してください（現在: %q）", c.Notion.APIURL)
+		}
+	}
+
+	// ストレージ
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			v.add("storage.local_dir", "STORAGE_LOCAL_DIR", "必須です")
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" {
+			v.add("storage.s3.bucket", "STORAGE_S3_BUCKET", "必須です")
+		}
+		if c.Storage.S3.Region == "" {
+			v.add("storage.s3.region", "STORAGE_S3_REGION", "必須です")
+		}
+		if c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			v.add("storage.s3.access_key_id", "STORAGE_S3_ACCESS_KEY_ID", "アクセスキーIDとシークレットアクセスキーを指定してください")
+		}
+		if c.Storage.S3.Endpoint != "" && !isHTTPURL(c.Storage.S3.Endpoint) {
+			v.add("storage.s3.endpoint", "STORAGE_S3_ENDPOINT", "http(s)のURLを指定してください（現在: %q）", c.Storage.S3.Endpoint)
+		}
+	case "gcs":
+		if c.Storage.GCS.Bucket == "" {
+			v.add("storage.gcs.bucket", "STORAGE_GCS_BUCKET", "必須です")
+		}
+		if c.Storage.GCS.Endpoint != "" && !isHTTPURL(c.Storage.GCS.Endpoint) {
+			v.add("storage.gcs.endpoint", "STORAGE_GCS_ENDPOINT", "http(s)のURLを指定してください（現在: %q）", c.Storage.GCS.Endpoint)
+		}
+	default:
+		v.add("storage.driver", "STORAGE_DRIVER", "local / s3 / gcs のいずれかを指定してください（現在: %q）", c.Storage.Driver)
+	}
+
+	// クイック追加
+	if c.QuickAdd.Enabled {
+		if len(c.QuickAdd.Tokens) == 0 {
+			v.add("quick_add.tokens", "QUICK_ADD_TOKENS", "トークンを1つ以上指定してください")
+		}
+		for _, token := range c.QuickAdd.Tokens {
+			if len(token) < 16 {
+				v.add("quick_add.tokens", "QUICK_ADD_TOKENS", "トークンは16文字以上で指定してください")
+				break
+			}
+		}
+	}
+
+	// Zapier・IFTTT連携
+	if c.Zapier.Enabled && len(c.Zapier.APIKey) < 16 {
+		v.add("zapier.api_key", "ZAPIER_API_KEY", "16文字以上で指定してください")
+	}
+	if c.IFTTT.Enabled && len(c.IFTTT.ServiceKey) < 16 {
+		v.add("ifttt.service_key", "IFTTT_SERVICE_KEY", "16文字以上で指定してください")
+	}
+
+	// CalDAVサーバー
+	if c.CalDAV.Enabled {
+		if c.CalDAV.Username == "" {
+			v.add("caldav.username", "CALDAV_USERNAME", "必須です")
+		}
+		if len(c.CalDAV.Password) < 16 {
+			v.add("caldav.password", "CALDAV_PASSWORD", "16文字以上で指定してください")
+		}
+	}
+
+	// Webhook署名・配信
+	if c.Webhook.RotationGracePeriod < 0 {
+		v.add("webhook.rotation_grace_period", "WEBHOOK_ROTATION_GRACE_PERIOD", "0以上の時間を指定してください（現在: %s）", c.Webhook.RotationGracePeriod)
+	}
+	if c.Webhook.SecretRefreshInterval < 0 {
+		v.add("webhook.secret_refresh_interval", "WEBHOOK_SECRET_REFRESH_INTERVAL", "0以上の時間を指定してください（現在: %s）", c.Webhook.SecretRefreshInterval)
+	}
+	if c.Webhook.Timeout <= 0 {
+		v.add("webhook.timeout", "WEBHOOK_TIMEOUT", "正の時間を指定してください（現在: %s）", c.Webhook.Timeout)
+	}
+	if c.Webhook.MaxAttempts < 0 {
+		v.add("webhook.max_attempts", "WEBHOOK_MAX_ATTEMPTS", "0以上の値を指定してください（現在: %d）", c.Webhook.MaxAttempts)
+	}
+	if c.Webhook.DisableAfter < 0 {
+		v.add("webhook.disable_after", "WEBHOOK_DISABLE_AFTER", "0以上の値を指定してください（現在: %d）", c.Webhook.DisableAfter)
+	}
+
+	// 説明文のサニタイズ
+	switch c.Sanitize.Mode {
+	case "off", "strip", "escape":
+	default:
+		v.add("sanitize.mode", "SANITIZE_MODE", "off / strip / escape のいずれかを指定してください（現在: %q）", c.Sanitize.Mode)
+	}
+	switch c.Sanitize.Stage {
+	case "save", "output":
+	default:
+		v.add("sanitize.stage", "SANITIZE_STAGE", "save / output のいずれかを指定してください（現在: %q）", c.Sanitize.Stage)
+	}
+
+	// 過去の期限の扱い
+	switch c.Validation.PastDueDate {
+	case "allow", "warn", "reject":
+	default:
+		v.add("validation.past_due_date", "VALIDATION_PAST_DUE_DATE", "allow / warn / reject のいずれかを指定してください（現在: %q）", c.Validation.PastDueDate)
+	}
+
+	// メッセージの言語
+	switch c.Locale.Default {
+	case "ja", "en":
+	default:
+		v.add("locale.default", "LOCALE_DEFAULT", "ja / en のいずれかを指定してください（現在: %q）", c.Locale.Default)
+	}
+
+	// IPフィルター
+	validateIPList(v, "ip_filter.trusted_proxies", "IP_FILTER_TRUSTED_PROXIES", c.IPFilter.TrustedProxies)
+	validateIPList(v, "ip_filter.allow", "IP_FILTER_ALLOW", c.IPFilter.Allow)
+	validateIPList(v, "ip_filter.deny", "IP_FILTER_DENY", c.IPFilter.Deny)
+	for i, rule := range c.IPFilter.Paths {
+		field := fmt.Sprintf("ip_filter.paths[%d]", i)
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			v.add(field+".prefix", "", "/ で始まるパスを指定してください（現在: %q）", rule.Prefix)
+		}
+		validateIPList(v, field+".allow", "", rule.Allow)
+		validateIPList(v, field+".deny", "", rule.Deny)
+	}
+
+	// 管理API
+	if c.Admin.Token != "" && len(c.Admin.Token) < 32 {
+		v.add("admin.token", "ADMIN_TOKEN", "32文字以上のトークンを指定してください")
+	}
+
+	// メンテナンスモード
+	if c.Maintenance.RetryAfter < 0 {
+		v.add("maintenance.retry_after", "MAINTENANCE_RETRY_AFTER", "0以上を指定してください（現在: %s）", c.Maintenance.RetryAfter)
+	}
+
+	// セキュリティヘッダー
+	switch strings.ToUpper(c.Security.FrameOptions) {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		v.add("security_headers.frame_options", "SECURITY_FRAME_OPTIONS", "DENY / SAMEORIGIN のいずれかを指定してください（現在: %q）", c.Security.FrameOptions)
+	}
+	if c.Security.HSTSMaxAge < 0 {
+		v.add("security_headers.hsts_max_age", "SECURITY_HSTS_MAX_AGE", "0以上の秒数を指定してください（現在: %d）", c.Security.HSTSMaxAge)
+	}
+
+	if len(v.Errors) > 0 {
+		return v
+	}
+	return nil
+}
+
+// isPriority Todoの優先度の値か判定
+func isPriority(s string) bool {
+	switch s {
+	case "low", "medium", "high", "urgent":
+		return true
+	}
+	return false
+}
+
+// isHTTPURL http(s)スキームとホストを持つURLか判定
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateIPList CIDRまたは単一IPアドレスのリストを検証
+func validateIPList(v *ValidationError, field, env string, entries []string) {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		var err error
+		if strings.Contains(entry, "/") {
+			_, err = netip.ParsePrefix(entry)
+		} else {
+			_, err = netip.ParseAddr(entry)
+		}
+		if err != nil {
+			v.add(field, env, "CIDRまたはIPアドレスを指定してください（現在: %q）", entry)
+		}
+	}
+}
+
+// validateNotifyFilter 通知条件（イベントの種類・最低優先度）を検証
+func validateNotifyFilter(v *ValidationError, field, envPrefix string, events []string, minPriority string) {
+	for _, event := range events {
+		switch event {
+		case "created", "completed", "due_soon", "overdue", "stale":
+		default:
+			v.add(field+".events", envPrefix+"_EVENTS", "created / completed / due_soon / overdue / stale のいずれかを指定してください（現在: %q）", event)
+		}
+	}
+	switch minPriority {
+	case "", "low", "medium", "high", "urgent":
+	default:
+		v.add(field+".min_priority", envPrefix+"_MIN_PRIORITY", "low / medium / high / urgent のいずれかを指定してください（現在: %q）", minPriority)
+	}
+}
@@ -0,0 +1,87 @@
+package cors
+
+import (
+	"myapp/config"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Middleware 設定に基づいてCORSヘッダーを付与し、プリフライトリクエストに応答するミドルウェア
+// exposedはアプリケーションが常に公開するレスポンスヘッダー（設定の exposed_headers に追加される）
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(exposed ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := config.Current().CORS
+			origin := r.Header.Get("Origin")
+
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			allowOrigin := matchOrigin(cfg.AllowedOrigins, origin, cfg.AllowCredentials)
+			if origin == "" || allowOrigin == "" {
+				// CORSリクエストでない、または許可されていないオリジン
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+				if cfg.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			exposedHeaders := append(append([]string{}, exposed...), cfg.ExposedHeaders...)
+			if len(exposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchOrigin 許可リストと照合し、Access-Control-Allow-Originに返す値を返す（不許可の場合は空文字）
+// "*" は全オリジン、"https://*.example.com" はサブドメインに一致する
+// クレデンシャルを許可する場合は "*" を返せないため、一致したオリジンをそのまま返す
+func matchOrigin(allowed []string, origin string, credentials bool) string {
+	if origin == "" {
+		return ""
+	}
+
+	for _, pattern := range allowed {
+		switch {
+		case pattern == "*":
+			if credentials {
+				return origin
+			}
+			return "*"
+		case strings.EqualFold(pattern, origin):
+			return origin
+		case strings.Contains(pattern, "://*."):
+			scheme, suffix, _ := strings.Cut(pattern, "://*")
+			if strings.HasPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://") &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) &&
+				len(origin) > len(scheme)+len("://")+len(suffix) {
+				return origin
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// tokenBytes トークンの乱数バイト数
+const tokenBytes = 32
+
+// DefaultHeaderName トークンを送受信する既定のヘッダー名
+const DefaultHeaderName = "X-CSRF-Token"
+
+type contextKey struct{}
+
+// Middleware Cookie認証のリクエストに対してCSRFトークンを発行・検証するミドルウェア（ダブルサブミットCookie方式）
+// 安全でないメソッドでは、Cookieのトークンとヘッダーのトークンが一致しない場合に403を返す
+// セッションCookieを持たないリクエストやAuthorization/APIキーヘッダーを使うリクエストは検証を免除する
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Current().CSRF
+		if !cfg.Enabled || excluded(cfg, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := ""
+		if cookie, err := r.Cookie(cfg.CookieName); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		}
+
+		if !safeMethod(r.Method) && !exempt(cfg, r) {
+			sent := r.Header.Get(cfg.HeaderName)
+			if token == "" || sent == "" || subtle.ConstantTimeCompare([]byte(token), []byte(sent)) != 1 {
+				forbidden(w, r)
+				return
+			}
+		}
+
+		if token == "" {
+			generated, err := newToken()
+			if err != nil {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(huma.Error500InternalServerError(i18n.T(r.Context(), "CSRFトークンを生成できません")))
+				return
+			}
+			token = generated
+			http.SetCookie(w, newCookie(cfg, token))
+		}
+		w.Header().Set(cfg.HeaderName, token)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, token)))
+	})
+}
+
+// Token コンテキストに紐づくCSRFトークンを取得（保護が無効な場合は空文字）
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(contextKey{}).(string)
+	return token
+}
+
+// safeMethod 状態を変更しないメソッドか
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// exempt CSRFの対象とならないリクエストか
+// Cookieを送らないクライアント（Bearer/APIキー利用）はブラウザに自動送信される資格情報を持たないため免除する
+func exempt(cfg config.CSRFConfig, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" {
+		return true
+	}
+	for _, header := range cfg.ExemptHeaders {
+		if r.Header.Get(header) != "" {
+			return true
+		}
+	}
+	if cfg.SessionCookie != "" {
+		if _, err := r.Cookie(cfg.SessionCookie); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded CSRF保護の対象外パスか
+func excluded(cfg config.CSRFConfig, path string) bool {
+	for _, prefix := range cfg.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// newToken ランダムなトークンを生成
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newCookie トークンを保存するCookieを作成
+// ダブルサブミット方式のため、フロントエンドのJavaScriptから読めるようHttpOnlyは付けない
+func newCookie(cfg config.CSRFConfig, token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    token,
+		Path:     "/",
+		Domain:   cfg.CookieDomain,
+		MaxAge:   int(cfg.CookieMaxAge.Seconds()),
+		Secure:   cfg.CookieSecure,
+		SameSite: sameSite(cfg.SameSite),
+	}
+}
+
+// sameSite 設定値をhttp.SameSiteに変換（不正な値の場合はLax）
+func sameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// forbidden 403をproblem+json形式で返す
+func forbidden(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(huma.Error403Forbidden(i18n.T(r.Context(), "CSRFトークンが無効です")))
+}
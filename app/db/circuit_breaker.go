@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrCircuitOpen サーキットブレーカーがオープン状態でクエリを拒否したことを示すエラー
+var ErrCircuitOpen = errors.New("データベースが一時的に利用できません（サーキットブレーカー作動中）")
+
+// BreakerState サーキットブレーカーの状態
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// CircuitBreaker 連続したDB接続障害を検知してクエリを即座に失敗させるGORMプラグイン
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            BreakerState
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	failureThreshold int
+	openTimeout      time.Duration
+}
+
+// BreakerConfig サーキットブレーカーの設定
+type BreakerConfig struct {
+	// FailureThreshold オープンする連続失敗回数（接続の切断・ネットワークエラー等の接続レベルのエラーのみ数える）
+	FailureThreshold int `yaml:"failure_threshold" toml:"failure_threshold" env:"DB_BREAKER_FAILURE_THRESHOLD"`
+	// OpenTimeout オープン後に半開状態へ移行するまでの時間
+	OpenTimeout time.Duration `yaml:"open_timeout" toml:"open_timeout" env:"DB_BREAKER_OPEN_TIMEOUT"`
+}
+
+// defaultBreakerConfig サーキットブレーカーのデフォルト設定
+var defaultBreakerConfig = BreakerConfig{
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+}
+
+// breaker 全接続で共有するサーキットブレーカー
+var breaker = NewCircuitBreaker(defaultBreakerConfig.FailureThreshold, defaultBreakerConfig.OpenTimeout)
+
+// ConfigureBreaker 共有サーキットブレーカーのしきい値を変更する（接続を作り直さないため、ホットリロードで反映できる）
+func ConfigureBreaker(config BreakerConfig) {
+	breaker.SetLimits(config.FailureThreshold, config.OpenTimeout)
+}
+
+// NewCircuitBreaker 新しいサーキットブレーカーインスタンスを作成
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:            BreakerClosed,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+	}
+}
+
+// SetLimits オープンする連続失敗回数と、半開状態へ移行するまでの時間を変更する（現在の状態・失敗回数は維持する）
+func (cb *CircuitBreaker) SetLimits(failureThreshold int, openTimeout time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failureThreshold = failureThreshold
+	cb.openTimeout = openTimeout
+}
+
+// GetBreakerState 共有サーキットブレーカーの現在の状態を取得
+func GetBreakerState() BreakerState {
+	return breaker.State()
+}
+
+// Name プラグイン名を返す
+func (cb *CircuitBreaker) Name() string {
+	return "circuit_breaker"
+}
+
+// Initialize 全ての処理種別の前後にコールバックを登録
+func (cb *CircuitBreaker) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().Before("*").Register("circuit_breaker:before_create", cb.before); err != nil {
+		return err
+	}
+	if err := callback.Create().After("*").Register("circuit_breaker:after_create", cb.after); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("*").Register("circuit_breaker:before_query", cb.before); err != nil {
+		return err
+	}
+	if err := callback.Query().After("*").Register("circuit_breaker:after_query", cb.after); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("*").Register("circuit_breaker:before_update", cb.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("*").Register("circuit_breaker:after_update", cb.after); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("*").Register("circuit_breaker:before_delete", cb.before); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("*").Register("circuit_breaker:after_delete", cb.after); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("*").Register("circuit_breaker:before_row", cb.before); err != nil {
+		return err
+	}
+	if err := callback.Row().After("*").Register("circuit_breaker:after_row", cb.after); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("*").Register("circuit_breaker:before_raw", cb.before); err != nil {
+		return err
+	}
+	return callback.Raw().After("*").Register("circuit_breaker:after_raw", cb.after)
+}
+
+// State 現在の状態を返す（オープン期限切れの場合は半開として扱う）
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == BreakerOpen && time.Since(cb.openedAt) >= cb.openTimeout {
+		return BreakerHalfOpen
+	}
+	return cb.state
+}
+
+// allow クエリの実行を許可するか判定
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case BreakerOpen:
+		if time.Since(cb.openedAt) < cb.openTimeout {
+			return false
+		}
+		// 待機時間を過ぎたら半開状態に移行し、試行クエリを1件だけ通す
+		cb.state = BreakerHalfOpen
+		cb.probing = true
+		slog.Info("サーキットブレーカーが半開状態になりました")
+		return true
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// recordSuccess 成功を記録（半開状態ならクローズに復帰）
+func (cb *CircuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == BreakerHalfOpen {
+		slog.Info("サーキットブレーカーがクローズ状態に復帰しました")
+	}
+	cb.state = BreakerClosed
+	cb.failures = 0
+	cb.probing = false
+}
+
+// recordFailure 失敗を記録（閾値到達または半開状態での失敗でオープン）
+func (cb *CircuitBreaker) recordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.failureThreshold {
+		if cb.state != BreakerOpen {
+			slog.Warn("サーキットブレーカーがオープンしました", "consecutive_failures", cb.failures)
+		}
+		cb.state = BreakerOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+// before クエリ実行前にブレーカーの状態を確認
+func (cb *CircuitBreaker) before(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if !cb.allow() {
+		db.AddError(ErrCircuitOpen)
+	}
+}
+
+// after クエリ実行結果をブレーカーに記録
+func (cb *CircuitBreaker) after(db *gorm.DB) {
+	if errors.Is(db.Error, ErrCircuitOpen) {
+		return
+	}
+	if isConnectionError(db.Error) {
+		cb.recordFailure()
+		return
+	}
+	cb.recordSuccess()
+}
+
+// isConnectionError DBへの到達性に関わるエラーか判定
+// 接続の切断・ネットワークエラー・SQLSTATEの08（接続例外）・57P（シャットダウン等）のみを数える
+// SQLエラー・レコード未検出・スキャンの型エラー・リクエストのキャンセルやタイムアウトは、DBが応答しているか呼び出し側の都合のため障害として数えない
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// *net.OpError（接続拒否・タイムアウト・リセット）や名前解決の失敗
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -2,25 +2,58 @@ package db
 
 import (
 	"fmt"
-	"log"
-	"myapp/db/model"
+	"log/slog"
+	"myapp/tracing"
 	"os"
+	"strconv"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
 // DatabaseConfig データベース設定
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD"`
+	DBName   string `yaml:"dbname" toml:"dbname" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE"`
+	// ConnectTimeout 接続確立のタイムアウト（秒）。DB停止時に接続待ちでハングしないようにする
+	ConnectTimeout string `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	// MaxOpenConns / MaxIdleConns 接続プールの最大接続数と、アイドル状態で保持する接続数
+	MaxOpenConns int `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns int `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	// ConnMaxLifetime 接続を使い続ける最長時間（0で無制限）。フェイルオーバー後の接続の入れ替えや、LB・PgBouncerによる切断より先に閉じるために使う
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// ConnMaxIdleTime アイドル状態の接続を閉じるまでの時間（0で無制限）
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// PgBouncer PgBouncerのトランザクションモード経由で接続する（プリペアドステートメントを使わず簡易プロトコルで問い合わせる）
+	PgBouncer bool `yaml:"pgbouncer" toml:"pgbouncer" env:"DB_PGBOUNCER"`
+	// PrepareStmt GORMのプリペアドステートメントキャッシュを使う（PgBouncerのトランザクションモードでは常に使わない）
+	PrepareStmt bool `yaml:"prepare_stmt" toml:"prepare_stmt" env:"DB_PREPARE_STMT"`
+	// Breaker 接続障害時に即座に失敗させるサーキットブレーカー
+	Breaker BreakerConfig `yaml:"breaker" toml:"breaker"`
+}
+
+// currentConfig Configureで設定された接続設定。未設定の場合は環境変数から構築する
+var currentConfig *DatabaseConfig
+
+// Configure 設定ファイル等から読み込んだ接続設定を適用する（Connectより前に呼ぶ）
+func Configure(config DatabaseConfig) {
+	currentConfig = &config
+	ConfigureBreaker(config.Breaker)
+}
+
+// activeConfig 現在有効な接続設定を取得
+func activeConfig() *DatabaseConfig {
+	if currentConfig != nil {
+		return currentConfig
+	}
+	return GetDefaultConfig()
 }
 
 // GetDefaultConfig デフォルトのデータベース設定を取得
@@ -32,6 +65,16 @@ func GetDefaultConfig() *DatabaseConfig {
 		Password: getEnv("DB_PASSWORD", "password"),
 		DBName:   getEnv("DB_NAME", "myapp"),
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		ConnectTimeout: getEnv("DB_CONNECT_TIMEOUT", "5"),
+
+		MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 100),
+		MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		PgBouncer:       getEnv("DB_PGBOUNCER", "false") == "true",
+		PrepareStmt:     true,
+		Breaker:         defaultBreakerConfig,
 	}
 }
 
@@ -43,36 +86,85 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt 環境変数を整数として取得、デフォルト値を設定
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration 環境変数を時間として取得、デフォルト値を設定
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // BuildDSN データベース接続文字列を構築
 func (config *DatabaseConfig) BuildDSN() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=%s",
+		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode, config.ConnectTimeout)
 }
 
-// Connect データベースに接続
+// Connect データベースの接続プールを作成する
+// 開く際にPingしないため、DBへ接続できるかは呼び出し側で確認する（起動時はmainが health.DBChecker でPingが通るまで待つ）
 func Connect() error {
-	config := GetDefaultConfig()
-	dsn := config.BuildDSN()
+	config := activeConfig()
+
+	db, err := open(config.BuildDSN())
+	if err != nil {
+		return err
+	}
+
+	DB = db
+	slog.Info("データベースの接続プールを作成しました")
+	return nil
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+// open DSNを指定してデータベースを開き、接続プールを設定（DBへの接続は確認しない）
+func open(dsn string) (*gorm.DB, error) {
+	config := activeConfig()
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN: dsn,
+		// PgBouncerのトランザクションモードでは同じサーバー接続が続く保証がなく、ドライバーが暗黙に作るプリペアドステートメントも使えない
+		PreferSimpleProtocol: config.PgBouncer,
+	}), &gorm.Config{
+		Logger: newSlogLogger(),
+		// プリペアドステートメントをキャッシュして再利用（PgBouncerのトランザクションモード併用時は無効化する）
+		PrepareStmt: !config.PgBouncer && config.PrepareStmt,
+		// 単一レコードの作成・更新で暗黙のトランザクションを張らない
+		SkipDefaultTransaction: true,
+		// 開く際にPingしない（起動時はDBが起動するまで呼び出し側がバックオフ付きで待つ）
+		DisableAutomaticPing: true,
 	})
 	if err != nil {
-		return fmt.Errorf("データベース接続に失敗しました: %w", err)
+		return nil, fmt.Errorf("データベース接続に失敗しました: %w", err)
 	}
 
 	// 接続プールの設定
 	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("データベース接続プールの設定に失敗しました: %w", err)
+		return nil, fmt.Errorf("データベース接続プールの設定に失敗しました: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	DB = db
-	log.Println("データベース接続が成功しました")
-	return nil
+	// SQL実行ごとのトレーシングスパン
+	if err := db.Use(&tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("トレーシングプラグインの登録に失敗しました: %w", err)
+	}
+
+	// DB障害時に即座に失敗させるサーキットブレーカー
+	if err := db.Use(breaker); err != nil {
+		return nil, fmt.Errorf("サーキットブレーカーの登録に失敗しました: %w", err)
+	}
+
+	return db, nil
 }
 
 // Migrate データベースマイグレーションを実行
@@ -81,14 +173,11 @@ func Migrate() error {
 		return fmt.Errorf("データベース接続が初期化されていません")
 	}
 
-	err := DB.AutoMigrate(
-		&model.Todo{},
-	)
-	if err != nil {
-		return fmt.Errorf("マイグレーションに失敗しました: %w", err)
+	if err := migrate(DB); err != nil {
+		return err
 	}
 
-	log.Println("データベースマイグレーションが完了しました")
+	slog.Info("データベースマイグレーションが完了しました")
 	return nil
 }
 
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slogLogger GORMのログをslogへ出力するロガー
+type slogLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newSlogLogger 新しいGORM用slogロガーを作成
+// DB_LOG_LEVEL: silent / error / warn / info（デフォルト: info、GO_ENV=productionではwarn）
+// DB_SLOW_QUERY_THRESHOLD: スロークエリとして警告する実行時間（デフォルト: 200ms、0で無効）
+func newSlogLogger() *slogLogger {
+	defaultLevel := "info"
+	if getEnv("GO_ENV", "") == "production" {
+		defaultLevel = "warn"
+	}
+
+	return &slogLogger{
+		level:         parseLogLevel(getEnv("DB_LOG_LEVEL", defaultLevel)),
+		slowThreshold: getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+	}
+}
+
+// parseLogLevel 文字列をGORMのログレベルに変換（不正な値の場合はinfo）
+func parseLogLevel(value string) logger.LogLevel {
+	switch strings.ToLower(value) {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn", "warning":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
+}
+
+// LogMode ログレベルを変更したロガーを返す
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info 情報ログを出力
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn 警告ログを出力
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error エラーログを出力
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace SQLの実行結果を出力（エラーはError、スロークエリはWarn、それ以外はDebug）
+// 全SQLのログはlevelがinfoの場合のみ出力する
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	attrs := []any{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("elapsed", elapsed),
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		slog.ErrorContext(ctx, "SQLの実行に失敗しました", append(attrs, slog.String("error", err.Error()))...)
+	case l.slowThreshold != 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		slog.WarnContext(ctx, "スロークエリを検出しました", append(attrs,
+			slog.Duration("threshold", l.slowThreshold),
+			slog.String("caller", caller()),
+		)...)
+	case l.level >= logger.Info:
+		slog.DebugContext(ctx, "SQLを実行しました", attrs...)
+	}
+}
+
+// caller GORMとdbパッケージを除いた最初の呼び出し元（ファイル:行）を返す
+func caller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/") &&
+			!strings.HasPrefix(frame.Function, "myapp/db.") &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
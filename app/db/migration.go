@@ -0,0 +1,511 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"myapp/db/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Migration バージョン管理されたマイグレーション
+type Migration struct {
+	ID          string
+	Description string
+	Migrate     func(tx *gorm.DB) error
+}
+
+// migrations 適用順に並べたマイグレーション一覧（IDは変更しないこと）
+// 適用済みのマイグレーションの内容は変えない。テーブルの作成は作成時点のスナップショット（todosV1 等）で行い、後から追加したカラムはそれぞれのマイグレーションで追加する
+var migrations = []Migration{
+	{
+		ID:          "20250611000000_create_todos",
+		Description: "todosテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&todosV1{})
+		},
+	},
+	{
+		ID:          "20250620000000_create_feature_flags",
+		Description: "feature_flagsテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.FeatureFlag{})
+		},
+	},
+	{
+		ID:          "20250701000000_create_webhook_secrets",
+		Description: "webhook_secretsテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.WebhookSecret{})
+		},
+	},
+	{
+		ID:          "20250710000000_add_todos_overdue_notified_at",
+		Description: "todosテーブルに期限切れ通知日時のカラムを追加",
+		Migrate: func(tx *gorm.DB) error {
+			// 最初のマイグレーションがmodel.Todoを作成していた間に構築した環境にはカラムがあるため、存在しない場合のみ追加
+			if tx.Migrator().HasColumn(&model.Todo{}, "OverdueNotifiedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&model.Todo{}, "OverdueNotifiedAt")
+		},
+	},
+	{
+		ID:          "20250715000000_add_todos_due_reminded_at",
+		Description: "todosテーブルに期限リマインダー送信日時のカラムを追加",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&model.Todo{}, "DueRemindedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&model.Todo{}, "DueRemindedAt")
+		},
+	},
+	{
+		ID:          "20250720000000_create_calendar_sync",
+		Description: "oauth_tokens・calendar_sync_statesテーブルの作成とtodosへの予定IDカラムの追加",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&model.OAuthToken{}, &model.CalendarSyncState{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"GoogleEventID", "CalendarSyncedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "GoogleEventID") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "GoogleEventID")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250725000000_add_todos_caldav_columns",
+		Description: "todosへのCalDAVリソース名・UIDカラムの追加",
+		Migrate: func(tx *gorm.DB) error {
+			for _, column := range []string{"CalDAVName", "CalDAVUID"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "CalDAVName") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "CalDAVName")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250801000000_create_import_jobs",
+		Description: "import_jobsテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.ImportJob{})
+		},
+	},
+	{
+		ID:          "20250805000000_add_todos_tags_and_github_sync",
+		Description: "todosへのタグ・GitHub Issueカラムの追加とgithub_sync_statesテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&model.GitHubSyncState{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"Tags", "GitHubIssue", "GitHubSyncedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "GitHubIssue") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "GitHubIssue")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250810000000_add_todos_jira_columns",
+		Description: "todosへのJira課題カラムの追加とjira_sync_statesテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&model.JiraSyncState{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"JiraIssue", "JiraSyncedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "JiraIssue") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "JiraIssue")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250820000000_create_push_subscriptions",
+		Description: "push_subscriptionsテーブルの作成（Web Pushの購読）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.PushSubscription{})
+		},
+	},
+	{
+		ID:          "20250825000000_add_todos_notion_columns",
+		Description: "todosへのNotionページカラムの追加とnotion_sync_statesテーブルの作成",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&model.NotionSyncState{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"NotionPageID", "NotionSyncedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "NotionPageID") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "NotionPageID")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250901000000_add_todos_completed_at",
+		Description: "todosへの完了日時カラムの追加（既存の完了済みTodoはupdated_atで補完）",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&model.Todo{}, "CompletedAt") {
+				if err := tx.Migrator().AddColumn(&model.Todo{}, "CompletedAt"); err != nil {
+					return err
+				}
+				if err := tx.Exec("UPDATE todos SET completed_at = updated_at WHERE completed = ?", true).Error; err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&model.Todo{}, "CompletedAt") {
+				return tx.Migrator().CreateIndex(&model.Todo{}, "CompletedAt")
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250905000000_create_inbound_mails",
+		Description: "inbound_mailsテーブルの作成（Todoを作成した受信メールの記録）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.InboundMail{})
+		},
+	},
+	{
+		ID:          "20250908000000_create_scheduled_jobs",
+		Description: "scheduled_jobsテーブルの作成（スケジューラーのジョブの実行状態とロック）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.ScheduledJob{})
+		},
+	},
+	{
+		ID:          "20250910000000_create_reminder_deliveries",
+		Description: "reminder_deliveriesテーブルの作成（期限間近・期限切れのリマインダーの送信先ごとの配信状態）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.ReminderDelivery{})
+		},
+	},
+	{
+		ID:          "20250912000000_add_todos_recurrence_columns",
+		Description: "todosへの繰り返しルール・生成元・次回の生成日時カラムの追加",
+		Migrate: func(tx *gorm.DB) error {
+			for _, column := range []string{"RecurrenceRule", "RecurrenceTimezone", "SkipHolidays", "RecurrenceParentID", "RecurrenceGeneratedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			for _, index := range []string{"RecurrenceParentID", "RecurrenceGeneratedAt"} {
+				if tx.Migrator().HasIndex(&model.Todo{}, index) {
+					continue
+				}
+				if err := tx.Migrator().CreateIndex(&model.Todo{}, index); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250914000000_create_queue_jobs",
+		Description: "queue_jobsテーブルの作成（リトライ・デッドレター付きの非同期ジョブキュー）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.QueueJob{})
+		},
+	},
+	{
+		ID:          "20250916000000_create_digest_subscriptions",
+		Description: "digest_subscriptionsテーブルの作成（送信先ごとの日次・週次ダイジェストメールの配信設定）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.DigestSubscription{})
+		},
+	},
+	{
+		ID:          "20250917000000_create_escalation_rules",
+		Description: "escalation_rules・escalation_logsテーブルの作成（期限切れのTodoのエスカレーションルールと適用の記録）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.EscalationRule{}, &model.EscalationLog{})
+		},
+	},
+	{
+		ID:          "20250918000000_create_webhook_endpoints",
+		Description: "webhook_endpoints・webhook_deliveriesテーブルの作成（Outgoing Webhookの送信先と配信の履歴）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.WebhookEndpoint{}, &model.WebhookDelivery{})
+		},
+	},
+	{
+		ID:          "20250919000000_add_todos_stale",
+		Description: "todosに放置タスクの検出用のカラムを追加（needs_review・stale_detected_at）",
+		Migrate: func(tx *gorm.DB) error {
+			for _, column := range []string{"NeedsReview", "StaleDetectedAt"} {
+				if tx.Migrator().HasColumn(&model.Todo{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&model.Todo{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "20250920000000_create_job_runs",
+		Description: "job_runsテーブルの作成（スケジューラーのジョブの実行履歴）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.JobRun{})
+		},
+	},
+	{
+		ID:          "20250921000000_add_todos_created_at_id_index",
+		Description: "todosへの(created_at, id)の複合インデックスの追加（一覧のキーセットページネーション用）",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&model.Todo{}, "idx_todos_created_at_id") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&model.Todo{}, "idx_todos_created_at_id")
+		},
+	},
+	{
+		ID:          "20250922000000_create_bulk_jobs",
+		Description: "bulk_jobsテーブルの作成（Todoの一括操作の進捗）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.BulkJob{})
+		},
+	},
+	{
+		ID:          "20250923000000_create_user_profiles",
+		Description: "user_profilesテーブルの作成（利用者のタイムゾーン）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&userProfilesV1{})
+		},
+	},
+	{
+		ID:          "20250924000000_add_todos_due_all_day",
+		Description: "todosに期限が日付のみ（終日）かのカラムを追加（due_all_day）",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&model.Todo{}, "DueAllDay") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&model.Todo{}, "DueAllDay")
+		},
+	},
+	{
+		ID:          "20250925000000_add_user_profiles_default_sort",
+		Description: "user_profilesに一覧の既定の並び順のカラムを追加（default_sort）",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&model.UserProfile{}, "DefaultSort") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&model.UserProfile{}, "DefaultSort")
+		},
+	},
+	{
+		ID:          "20250926000000_create_oauth_states",
+		Description: "oauth_statesテーブルの作成（複数インスタンスで共有するOAuthのstate）",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&model.OAuthState{})
+		},
+	},
+}
+
+// todosV1 最初のマイグレーション時点のtodosテーブル（model.Todoの変更に追従させない）
+type todosV1 struct {
+	ID          uint   `gorm:"primaryKey"`
+	Title       string `gorm:"not null;size:255"`
+	Description string `gorm:"type:text"`
+	Completed   bool   `gorm:"default:false"`
+	Priority    string `gorm:"type:varchar(10);default:'medium'"`
+	DueDate     *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName テーブル名を指定
+func (todosV1) TableName() string {
+	return "todos"
+}
+
+// userProfilesV1 作成時点のuser_profilesテーブル（default_sort は後のマイグレーションで追加する）
+type userProfilesV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Timezone  string `gorm:"size:64;not null;default:''"`
+	UpdatedAt time.Time
+}
+
+// TableName テーブル名を指定
+func (userProfilesV1) TableName() string {
+	return "user_profiles"
+}
+
+// schemaMigration 適用済みマイグレーションの記録
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time
+}
+
+// TableName テーブル名を指定
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrate 指定したデータベースに未適用のマイグレーションを順に適用
+func migrate(database *gorm.DB) error {
+	if err := database.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("マイグレーション管理テーブルの作成に失敗しました: %w", err)
+	}
+
+	applied, err := appliedMigrations(database)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.ID]; ok {
+			continue
+		}
+
+		err := database.Transaction(func(tx *gorm.DB) error {
+			if err := m.Migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("マイグレーション %s に失敗しました: %w", m.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// appliedMigrations 適用済みマイグレーションをIDをキーに取得
+func appliedMigrations(database *gorm.DB) (map[string]time.Time, error) {
+	var records []schemaMigration
+	if err := database.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("適用済みマイグレーションの取得に失敗しました: %w", err)
+	}
+
+	applied := make(map[string]time.Time, len(records))
+	for _, record := range records {
+		applied[record.ID] = record.AppliedAt
+	}
+	return applied, nil
+}
+
+// GetMigrationStatus 全マイグレーションの適用状況を取得
+func GetMigrationStatus(ctx context.Context) ([]*model.MigrationStatus, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("データベース接続が初期化されていません")
+	}
+	database := DB.WithContext(ctx)
+
+	applied := map[string]time.Time{}
+	if database.Migrator().HasTable(&schemaMigration{}) {
+		var err error
+		if applied, err = appliedMigrations(database); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]*model.MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status := &model.MigrationStatus{
+			ID:          m.ID,
+			Description: m.Description,
+		}
+		if appliedAt, ok := applied[m.ID]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses[i] = status
+	}
+
+	return statuses, nil
+}
+
+// DryRunMigrations 未適用マイグレーションで実行されるSQLを、実際には適用せずに取得
+// PostgreSQLのトランザクショナルDDLを利用し、トランザクション内で実行した後ロールバックする
+func DryRunMigrations(ctx context.Context) ([]string, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("データベース接続が初期化されていません")
+	}
+
+	recorder := &sqlRecorder{}
+	tx := DB.Session(&gorm.Session{Context: ctx, Logger: recorder}).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("トランザクションの開始に失敗しました: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	if err := migrate(tx); err != nil {
+		return nil, err
+	}
+
+	return recorder.statements, nil
+}
+
+// sqlRecorder 実行されたSQLのうちスキーマ変更・データの移行に関わる文を記録するロガー
+type sqlRecorder struct {
+	statements []string
+}
+
+// LogMode ロガーインターフェースの実装（レベルは使用しない）
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+// Info ロガーインターフェースの実装
+func (r *sqlRecorder) Info(context.Context, string, ...interface{}) {}
+
+// Warn ロガーインターフェースの実装
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{}) {}
+
+// Error ロガーインターフェースの実装
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+// Trace 実行されたSQLを記録（スキーマ確認用のSELECTと、適用済みの記録のschema_migrationsへのINSERTは除外）
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	statement := strings.ToUpper(strings.TrimSpace(sql))
+	if strings.HasPrefix(statement, "SELECT") || strings.HasPrefix(statement, `INSERT INTO "SCHEMA_MIGRATIONS"`) {
+		return
+	}
+	r.statements = append(r.statements, sql)
+}
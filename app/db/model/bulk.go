@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// BulkOperation Todoの一括操作の種類
+type BulkOperation string
+
+const (
+	// BulkUpdate 指定したフィールドを一括で更新
+	BulkUpdate BulkOperation = "update"
+	// BulkDelete 一括で削除
+	BulkDelete BulkOperation = "delete"
+)
+
+// BulkStatus 一括操作の状態
+type BulkStatus string
+
+const (
+	BulkStatusRunning   BulkStatus = "running"
+	BulkStatusCompleted BulkStatus = "completed"
+)
+
+// BulkRequest Todoの一括操作リクエスト
+type BulkRequest struct {
+	Operation BulkOperation `json:"operation" enum:"update,delete" doc:"操作（update: 更新 / delete: 削除）"`
+	IDs       []uint        `json:"ids" minItems:"1" doc:"対象のTodoのID（重複は1件として扱う）"`
+	// Update operation=update の場合に適用する変更（PUT /api/v1/todos/{id} と同じ）
+	Update *TodoUpdateRequest `json:"update,omitempty" doc:"operation=updateの場合に各Todoに適用する変更"`
+}
+
+// BulkJob Todoの一括操作の進捗
+type BulkJob struct {
+	ID        uint          `json:"id" gorm:"primaryKey" doc:"一括操作のID"`
+	Operation BulkOperation `json:"operation" gorm:"size:16;not null" enum:"update,delete" doc:"操作"`
+	Status    BulkStatus    `json:"status" gorm:"size:16;not null" enum:"running,completed" doc:"状態"`
+	Total     int           `json:"total" doc:"対象のTodoの件数"`
+	// Processed 処理済みの件数（Succeeded + Failed）
+	Processed int `json:"processed" doc:"処理済みの件数"`
+	Succeeded int `json:"succeeded" doc:"成功した件数"`
+	Failed    int `json:"failed" doc:"失敗した件数（存在しないTodoを含む）"`
+	// Messages 失敗の内容（上限件数まで）
+	Messages   []string   `json:"messages" gorm:"serializer:json;type:text" doc:"失敗の内容（先頭100件）"`
+	CreatedAt  time.Time  `json:"created_at" doc:"開始日時"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" doc:"終了日時"`
+}
+
+// TableName テーブル名を指定
+func (BulkJob) TableName() string {
+	return "bulk_jobs"
+}
+
+// bulkMaxMessages 記録する失敗の上限
+const bulkMaxMessages = 100
+
+// AddMessage 失敗の内容を記録（上限を超えた分は記録しない）
+func (j *BulkJob) AddMessage(message string) {
+	if len(j.Messages) < bulkMaxMessages {
+		j.Messages = append(j.Messages, message)
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// CalDAVTodo CalDAVクライアントから受け取ったVTODOのうちTodoに反映する項目
+type CalDAVTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Completed   bool
+	Priority    Priority
+	Due         *time.Time
+	// DueAllDay DUEが日付のみ（VALUE=DATE）
+	DueAllDay bool
+}
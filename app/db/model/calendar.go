@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// OAuthToken 外部サービス連携のOAuthトークン（連携先ごとに1件）
+type OAuthToken struct {
+	Provider     string    `gorm:"primaryKey;size:64"`
+	AccessToken  string    `gorm:"type:text"`
+	RefreshToken string    `gorm:"type:text"`
+	Expiry       time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName テーブル名を指定
+func (OAuthToken) TableName() string {
+	return "oauth_tokens"
+}
+
+// OAuthState 発行済みのOAuthのstate（同意画面から戻るまで保存し、どのインスタンスがコールバックを受けても検証できるようにする）
+type OAuthState struct {
+	State     string    `gorm:"primaryKey;size:64"`
+	Provider  string    `gorm:"size:64;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName テーブル名を指定
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
+
+// CalendarSyncState カレンダーごとの同期状態
+type CalendarSyncState struct {
+	CalendarID string `gorm:"primaryKey;size:255"`
+	// SyncToken 前回の取り込みで受け取った差分同期用のトークン
+	SyncToken    string `gorm:"type:text"`
+	LastSyncedAt *time.Time
+	// LockedUntil 同期中のインスタンスが保持するロックの期限（複数インスタンスでの同時実行を防ぐ）
+	LockedUntil *time.Time
+}
+
+// TableName テーブル名を指定
+func (CalendarSyncState) TableName() string {
+	return "calendar_sync_states"
+}
+
+// CalendarStatus カレンダー連携の状態
+type CalendarStatus struct {
+	Connected    bool       `json:"connected" doc:"OAuthトークンを保存済みか"`
+	CalendarID   string     `json:"calendar_id" doc:"同期先のカレンダーID"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty" doc:"直近の同期日時"`
+	LinkedTodos  int64      `json:"linked_todos" doc:"予定と紐付いているTodoの件数"`
+}
+
+// CalendarSyncResult 同期の結果
+type CalendarSyncResult struct {
+	Pushed int `json:"pushed" doc:"カレンダーに反映したTodoの件数"`
+	Pulled int `json:"pulled" doc:"カレンダーの変更を取り込んだTodoの件数"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// DBStats データベース統計情報
+type DBStats struct {
+	DatabaseSizeBytes int64         `json:"database_size_bytes" doc:"データベース全体のサイズ（バイト）"`
+	Tables            []*TableStats `json:"tables" doc:"テーブルごとの統計"`
+	Pool              *PoolStats    `json:"pool" doc:"コネクションプールの使用状況"`
+	LongestQuery      *RunningQuery `json:"longest_query,omitempty" doc:"実行中で最も長いクエリ"`
+	CollectedAt       time.Time     `json:"collected_at" doc:"統計の取得時刻"`
+}
+
+// TableStats テーブル単位の統計情報
+type TableStats struct {
+	TableName       string     `json:"table_name" doc:"テーブル名"`
+	LiveTuples      int64      `json:"live_tuples" doc:"有効な行数（推定）"`
+	DeadTuples      int64      `json:"dead_tuples" doc:"デッドタプル数"`
+	TotalSizeBytes  int64      `json:"total_size_bytes" doc:"インデックスを含むテーブルサイズ（バイト）"`
+	LastAutoVacuum  *time.Time `json:"last_autovacuum,omitempty" doc:"最後の自動VACUUM実行時刻"`
+	LastAutoAnalyze *time.Time `json:"last_autoanalyze,omitempty" doc:"最後の自動ANALYZE実行時刻"`
+}
+
+// PoolStats コネクションプールの統計情報
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections" doc:"最大接続数"`
+	OpenConnections    int   `json:"open_connections" doc:"確立済みの接続数"`
+	InUse              int   `json:"in_use" doc:"使用中の接続数"`
+	Idle               int   `json:"idle" doc:"アイドル状態の接続数"`
+	WaitCount          int64 `json:"wait_count" doc:"接続待ちが発生した累計回数"`
+	WaitDurationMs     int64 `json:"wait_duration_ms" doc:"接続待ちの累計時間（ミリ秒）"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed" doc:"最長時間（DB_CONN_MAX_LIFETIME）を過ぎて閉じた接続の累計数"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed" doc:"アイドル時間（DB_CONN_MAX_IDLE_TIME）を過ぎて閉じた接続の累計数"`
+}
+
+// RunningQuery 実行中のクエリ情報
+type RunningQuery struct {
+	PID             int     `json:"pid" doc:"バックエンドプロセスID"`
+	State           string  `json:"state" doc:"接続の状態"`
+	DurationSeconds float64 `json:"duration_seconds" doc:"実行時間（秒）"`
+	Query           string  `json:"query" doc:"実行中のSQL（リテラルを ? に置き換え、200文字で切り詰めたもの）"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// DigestFrequency ダイジェストを送る頻度
+type DigestFrequency string
+
+const (
+	// DigestDaily 毎日
+	DigestDaily DigestFrequency = "daily"
+	// DigestWeekly 毎週（Weekdayの曜日）
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestSubscription 送信先ごとのダイジェストメールの配信設定
+type DigestSubscription struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Email string `json:"email" gorm:"size:255;not null;uniqueIndex"`
+	// Name 送信先の表示名（メールの宛名に使う）
+	Name      string          `json:"name,omitempty" gorm:"size:255"`
+	Frequency DigestFrequency `json:"frequency" gorm:"size:20;not null"`
+	// SendTime 送る時刻（HH:MM、Timezoneの時刻）
+	SendTime string `json:"send_time" gorm:"size:5;not null"`
+	// Weekday 毎週の場合に送る曜日（0: 日曜日〜6: 土曜日）
+	Weekday  int    `json:"weekday" gorm:"not null;default:0"`
+	Timezone string `json:"timezone" gorm:"size:64;not null"`
+	Enabled  bool   `json:"enabled" gorm:"not null;default:true"`
+	// NextSendAt 次に送る日時（配信を停止している場合はnull）
+	NextSendAt *time.Time `json:"next_send_at,omitempty" gorm:"index"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	// LastError 直近の送信の失敗理由
+	LastError string    `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (DigestSubscription) TableName() string {
+	return "digest_subscriptions"
+}
+
+// DigestSubscriptionRequest ダイジェストの配信設定の登録・更新リクエスト
+type DigestSubscriptionRequest struct {
+	Email     string          `json:"email" format:"email" maxLength:"255" doc:"送信先のメールアドレス"`
+	Name      string          `json:"name,omitempty" maxLength:"255" doc:"送信先の表示名"`
+	Frequency DigestFrequency `json:"frequency" enum:"daily,weekly" doc:"送る頻度（daily: 毎日 / weekly: 毎週）"`
+	SendTime  string          `json:"send_time" pattern:"^([01][0-9]|2[0-3]):[0-5][0-9]$" doc:"送る時刻（HH:MM）"`
+	Weekday   int             `json:"weekday,omitempty" minimum:"0" maximum:"6" doc:"毎週の場合に送る曜日（0: 日曜日〜6: 土曜日）"`
+	Timezone  string          `json:"timezone,omitempty" maxLength:"64" doc:"送る時刻と「今日」を数えるタイムゾーン（省略時は利用者のタイムゾーン）"`
+	Enabled   *bool           `json:"enabled,omitempty" doc:"配信するか（省略時はtrue）"`
+}
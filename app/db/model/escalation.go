@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// EscalationRule 期限切れのTodoのエスカレーションルール
+// 期限をOverdueMinutes分過ぎた未完了のTodoについて、優先度の引き上げと通知を期限ごとに1回だけ行う
+type EscalationRule struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name" gorm:"size:255;not null"`
+	Enabled bool   `json:"enabled" gorm:"not null;default:true"`
+	// OverdueMinutes 期限を過ぎてからエスカレーションするまでの分数（0の場合は期限を過ぎたらすぐ）
+	OverdueMinutes int `json:"overdue_minutes" gorm:"not null;default:0"`
+	// Tag 対象とするTodoのタグ（空の場合は全て）
+	Tag string `json:"tag,omitempty" gorm:"size:50"`
+	// MinPriority 対象とするTodoの最低の優先度（空の場合は全て）
+	MinPriority Priority `json:"min_priority,omitempty" gorm:"type:varchar(10)"`
+	// RaisePriority 引き上げる優先度（空の場合は引き上げない。既にこの優先度以上のTodoは変更しない）
+	RaisePriority Priority `json:"raise_priority,omitempty" gorm:"type:varchar(10)"`
+	// Recipients 通知するメールアドレス（担当者・マネージャー等）
+	Recipients []string `json:"recipients" gorm:"serializer:json;type:text"`
+	// Channels 通知する送信先のチャンネル名（slack / discord / mattermost / teams / email / webpush）
+	Channels  []string  `json:"channels" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (EscalationRule) TableName() string {
+	return "escalation_rules"
+}
+
+// EscalationRuleRequest エスカレーションルールの登録・更新リクエスト
+type EscalationRuleRequest struct {
+	Name           string   `json:"name" minLength:"1" maxLength:"255" doc:"ルール名"`
+	Enabled        *bool    `json:"enabled,omitempty" doc:"ルールを適用するか（省略時はtrue）"`
+	OverdueMinutes int      `json:"overdue_minutes,omitempty" minimum:"0" maximum:"525600" doc:"期限を過ぎてからエスカレーションするまでの分数"`
+	Tag            string   `json:"tag,omitempty" maxLength:"50" doc:"対象とするTodoのタグ（省略時は全て）"`
+	MinPriority    Priority `json:"min_priority,omitempty" enum:"low,medium,high,urgent" doc:"対象とするTodoの最低の優先度（省略時は全て）"`
+	RaisePriority  Priority `json:"raise_priority,omitempty" enum:"low,medium,high,urgent" doc:"引き上げる優先度（省略時は引き上げない）"`
+	Recipients     []string `json:"recipients,omitempty" maxItems:"20" doc:"通知するメールアドレス（担当者・マネージャー等）"`
+	Channels       []string `json:"channels,omitempty" maxItems:"10" doc:"通知する送信先のチャンネル名（slack / discord / mattermost / teams / email / webpush）"`
+}
+
+// EscalationLog Todoにエスカレーションルールを適用した記録（ルール・Todo・期限ごとに1件）
+type EscalationLog struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	RuleID uint `json:"rule_id" gorm:"not null;uniqueIndex:idx_escalation_logs_target"`
+	TodoID uint `json:"todo_id" gorm:"not null;uniqueIndex:idx_escalation_logs_target;index"`
+	// DueDate 適用した時点のTodoの期限（期限が変更された場合は改めて適用する）
+	DueDate time.Time `json:"due_date" gorm:"uniqueIndex:idx_escalation_logs_target"`
+	// PreviousPriority・RaisedTo 引き上げる前と後の優先度（引き上げていない場合はRaisedToが空）
+	PreviousPriority Priority `json:"previous_priority" gorm:"type:varchar(10)"`
+	RaisedTo         Priority `json:"raised_to,omitempty" gorm:"type:varchar(10)"`
+	// Notified 通知した宛先・チャンネル
+	Notified []string `json:"notified" gorm:"serializer:json;type:text"`
+	// Error 通知の失敗理由
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName テーブル名を指定
+func (EscalationLog) TableName() string {
+	return "escalation_logs"
+}
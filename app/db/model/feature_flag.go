@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// FeatureFlag 管理APIから設定されたフィーチャーフラグの値
+type FeatureFlag struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:100"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// GitHubSyncState リポジトリごとのIssue同期の状態
+type GitHubSyncState struct {
+	Repository string `gorm:"primaryKey;size:255"`
+	// Since 前回までに取り込んだIssueの最新の更新日時（次回はこれ以降に更新されたIssueを取得する）
+	Since        *time.Time
+	LastSyncedAt *time.Time
+	// LockedUntil 同期中のインスタンスが保持するロックの期限（複数インスタンスでの同時実行を防ぐ）
+	LockedUntil *time.Time
+}
+
+// TableName テーブル名を指定
+func (GitHubSyncState) TableName() string {
+	return "github_sync_states"
+}
+
+// GitHubSyncResult 同期の結果
+type GitHubSyncResult struct {
+	Pushed int `json:"pushed" doc:"Issueに反映したTodoの件数"`
+	Pulled int `json:"pulled" doc:"Issueの変更を取り込んだTodoの件数（作成を含む）"`
+}
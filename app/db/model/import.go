@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// ImportStatus 取り込みの状態
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+// ImportTask 外部サービスから取り込むタスク（取り込み元ごとのパーサーが変換する）
+type ImportTask struct {
+	// Ref エラー表示用のタスクの参照（取り込み元のIDまたはCSVの行番号）
+	Ref         string
+	Title       string
+	Description string
+	// Project 所属するプロジェクト（Trelloのリスト等）の名前。Todoにはプロジェクトがないため説明に記録する
+	Project   string
+	Priority  Priority
+	Due       *time.Time
+	Completed bool
+	// Subtasks チェックリスト等のサブタスク。Todoにはサブタスクがないため説明に記録する
+	Subtasks []ImportSubtask
+	// UnparsedDue 解釈できなかった期限の文字列（繰り返し・自然言語の期限等）
+	UnparsedDue string
+}
+
+// ImportSubtask 取り込むタスクのサブタスク
+type ImportSubtask struct {
+	Title     string
+	Completed bool
+}
+
+// ImportJob 外部サービスからの取り込みの進捗
+type ImportJob struct {
+	ID     uint         `json:"id" gorm:"primaryKey" doc:"取り込みのID"`
+	Source string       `json:"source" gorm:"size:32;not null" doc:"取り込み元（todoist / trello / microsoft_todo）"`
+	Status ImportStatus `json:"status" gorm:"size:16;not null" enum:"running,completed,failed" doc:"状態"`
+	Total  int          `json:"total" doc:"取り込み対象のタスク数"`
+	// Processed 処理済みの件数（Imported + Duplicates + Failed）
+	Processed  int `json:"processed" doc:"処理済みのタスク数"`
+	Imported   int `json:"imported" doc:"作成したTodoの件数"`
+	Duplicates int `json:"duplicates" doc:"既存のTodoと重複したためスキップした件数"`
+	Failed     int `json:"failed" doc:"取り込めなかった件数"`
+	// Messages 失敗・警告の内容（上限件数まで）
+	Messages   []string   `json:"messages" gorm:"serializer:json;type:text" doc:"失敗・警告の内容（先頭100件）"`
+	CreatedAt  time.Time  `json:"created_at" doc:"開始日時"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" doc:"終了日時"`
+}
+
+// TableName テーブル名を指定
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// importMaxMessages 記録する失敗・警告の上限
+const importMaxMessages = 100
+
+// AddMessage 失敗・警告の内容を記録（上限を超えた分は記録しない）
+func (j *ImportJob) AddMessage(message string) {
+	if len(j.Messages) < importMaxMessages {
+		j.Messages = append(j.Messages, message)
+	}
+}
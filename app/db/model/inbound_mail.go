@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// InboundMail Todoを作成した受信メールの記録（同じメールから重複してTodoを作成しないために使う）
+type InboundMail struct {
+	ID uint `gorm:"primaryKey"`
+	// MessageKey Message-IDヘッダー（255文字を超える場合・ない場合はメールのSHA-256）
+	MessageKey string `gorm:"size:255;not null;uniqueIndex"`
+	Sender     string `gorm:"size:320"`
+	// TodoID 作成したTodoのID（作成中は0）
+	TodoID    uint `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// TableName テーブル名を指定
+func (InboundMail) TableName() string {
+	return "inbound_mails"
+}
+
+// InboundMailRequest 受信メールからTodoを作成するリクエスト
+type InboundMailRequest struct {
+	MessageKey string
+	Sender     string
+	Subject    string
+	Body       string
+	ReceivedAt time.Time
+}
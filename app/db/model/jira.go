@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// JiraSyncState サイトごとの課題同期の状態
+type JiraSyncState struct {
+	Site string `gorm:"primaryKey;size:255"`
+	// PulledAt 前回課題を取り込んだ時刻（次回はこれ以降に更新された課題を取得する）
+	PulledAt     *time.Time
+	LastSyncedAt *time.Time
+	// LockedUntil 同期中のインスタンスが保持するロックの期限（複数インスタンスでの同時実行を防ぐ）
+	LockedUntil *time.Time
+}
+
+// TableName テーブル名を指定
+func (JiraSyncState) TableName() string {
+	return "jira_sync_states"
+}
+
+// JiraSyncResult 同期の結果
+type JiraSyncResult struct {
+	Pushed int `json:"pushed" doc:"課題のトランジションを実行したTodoの件数"`
+	Pulled int `json:"pulled" doc:"課題の変更を取り込んだTodoの件数（作成を含む）"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// JobRunStatus スケジューラーのジョブの実行結果
+type JobRunStatus string
+
+const (
+	// JobRunRunning 実行中（インスタンスが異常終了した場合は running のまま残る）
+	JobRunRunning JobRunStatus = "running"
+	// JobRunSucceeded 成功
+	JobRunSucceeded JobRunStatus = "succeeded"
+	// JobRunFailed 失敗（エラー・パニック・ロックの期限切れによる中断）
+	JobRunFailed JobRunStatus = "failed"
+)
+
+// ジョブの実行のきっかけ
+const (
+	// JobRunTriggerSchedule 予定時刻による実行
+	JobRunTriggerSchedule = "schedule"
+	// JobRunTriggerManual 管理APIによる即時実行
+	JobRunTriggerManual = "manual"
+)
+
+// JobRun スケジューラーのジョブの1回の実行の履歴
+type JobRun struct {
+	ID  uint   `json:"id" gorm:"primaryKey"`
+	Job string `json:"job" gorm:"size:64;not null;index:idx_job_runs_job_started_at,priority:1"`
+	// Trigger 実行のきっかけ（schedule / manual）
+	Trigger string `json:"trigger" gorm:"size:20;not null"`
+	// ScheduledAt 予定時刻（即時実行の場合は未設定）
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// Instance 実行したインスタンス（ホスト名とプロセスID）
+	Instance   string       `json:"instance" gorm:"size:255"`
+	Status     JobRunStatus `json:"status" gorm:"size:20;not null;index"`
+	StartedAt  time.Time    `json:"started_at" gorm:"not null;index:idx_job_runs_job_started_at,priority:2"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	DurationMs float64      `json:"duration_ms"`
+	Error      string       `json:"error,omitempty" gorm:"type:text"`
+}
+
+// TableName テーブル名を指定
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// MigrationStatus マイグレーションの適用状況
+type MigrationStatus struct {
+	ID          string     `json:"id" doc:"マイグレーションID"`
+	Description string     `json:"description" doc:"マイグレーションの説明"`
+	Applied     bool       `json:"applied" doc:"適用済みかどうか"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty" doc:"適用日時"`
+}
+
+// MigrationReport マイグレーション一覧とdry-run結果
+type MigrationReport struct {
+	Migrations []*MigrationStatus `json:"migrations" doc:"マイグレーションの一覧（適用順）"`
+	Pending    int                `json:"pending" doc:"未適用のマイグレーション数"`
+	DryRunSQL  []string           `json:"dry_run_sql,omitempty" doc:"未適用マイグレーションで実行されるSQL（dry-run時のみ）"`
+}
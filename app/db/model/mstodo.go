@@ -0,0 +1,25 @@
+package model
+
+// MSTodoStatus Microsoft To Do連携の状態
+type MSTodoStatus struct {
+	Connected bool `json:"connected" doc:"OAuthトークンを保存済みか"`
+}
+
+// MSTodoList Microsoft To Doのリストと取り込み先のプロジェクト
+type MSTodoList struct {
+	ID   string `json:"id" doc:"リストのID"`
+	Name string `json:"name" doc:"リスト名"`
+	// Wellknown 既定のリスト等の区別（通常のリストはnone）
+	Wellknown string `json:"wellknown" doc:"既定のリスト（defaultList）・フラグ付きメール（flaggedEmails）等の区別。通常のリストはnone"`
+	Project   string `json:"project" doc:"取り込み先のプロジェクト名（空の場合はプロジェクトなし）"`
+}
+
+// MSTodoImportOptions Microsoft To Doからの取り込みの指定
+type MSTodoImportOptions struct {
+	// ListIDs 取り込むリストのID（空の場合は全てのリスト）
+	ListIDs []string
+	// Projects リストのIDまたはリスト名と取り込み先のプロジェクト名の対応（設定の対応より優先する）
+	Projects map[string]string
+	// IncludeCompleted 完了済みのタスクも取り込む
+	IncludeCompleted bool
+}
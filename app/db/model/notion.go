@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// NotionSyncState データベースごとのエクスポートの状態
+type NotionSyncState struct {
+	DatabaseID   string `gorm:"primaryKey;size:64"`
+	LastSyncedAt *time.Time
+	// LockedUntil 同期中のインスタンスが保持するロックの期限（複数インスタンスでの同時実行を防ぐ）
+	LockedUntil *time.Time
+}
+
+// TableName テーブル名を指定
+func (NotionSyncState) TableName() string {
+	return "notion_sync_states"
+}
+
+// NotionSyncResult 同期の結果
+type NotionSyncResult struct {
+	Created  int `json:"created" doc:"作成したページの件数"`
+	Updated  int `json:"updated" doc:"更新したページの件数"`
+	Archived int `json:"archived" doc:"削除したTodoに対応してアーカイブしたページの件数"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// PurgeResult パージで物理削除した件数
+type PurgeResult struct {
+	Todos              int64 `json:"todos" doc:"物理削除した削除済みTodoの件数"`
+	ImportJobs         int64 `json:"import_jobs" doc:"削除した取り込みの記録の件数"`
+	BulkJobs           int64 `json:"bulk_jobs" doc:"削除した一括操作の記録の件数"`
+	ReminderDeliveries int64 `json:"reminder_deliveries" doc:"削除したリマインダーの配信状態の件数"`
+	QueueJobs          int64 `json:"queue_jobs" doc:"削除した成功済みのジョブの件数"`
+	WebhookDeliveries  int64 `json:"webhook_deliveries" doc:"削除した配信済み・取り消したWebhookの配信の履歴の件数"`
+	JobRuns            int64 `json:"job_runs" doc:"削除したスケジューラーのジョブの実行履歴の件数"`
+}
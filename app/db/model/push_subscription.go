@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// PushSubscription ブラウザのWeb Pushの購読（PushSubscription.toJSON() の内容）
+type PushSubscription struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// Endpoint プッシュサービスの送信先URL（購読ごとに一意）
+	Endpoint string `json:"endpoint" gorm:"size:2048;not null;uniqueIndex"`
+	// P256dh・Auth メッセージの暗号化に使うブラウザの公開鍵と認証シークレット（APIでは返さない）
+	P256dh    string `json:"-" gorm:"size:255;not null"`
+	Auth      string `json:"-" gorm:"size:255;not null"`
+	UserAgent string `json:"user_agent" gorm:"size:512"`
+	// ExpiresAt ブラウザが通知した購読の有効期限（expirationTime。期限を過ぎた購読は削除する）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// FailureCount 連続した送信失敗の回数（上限に達した購読は削除する）
+	FailureCount  int        `json:"failure_count" gorm:"not null;default:0"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
+
+// Expired 購読の有効期限を過ぎているか
+func (s *PushSubscription) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+// PushSubscriptionRequest 購読の登録リクエスト（ブラウザの PushSubscription.toJSON() をそのまま送る）
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" maxLength:"2048" doc:"プッシュサービスの送信先URL"`
+	// ExpirationTime 購読の有効期限（UNIXエポックからのミリ秒。期限がない場合はnull）
+	ExpirationTime *int64 `json:"expirationTime,omitempty" doc:"購読の有効期限（UNIXミリ秒）"`
+	Keys           struct {
+		P256dh string `json:"p256dh" maxLength:"255" doc:"ブラウザの公開鍵（base64url）"`
+		Auth   string `json:"auth" maxLength:"255" doc:"認証シークレット（base64url）"`
+	} `json:"keys"`
+}
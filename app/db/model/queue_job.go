@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// QueueJobStatus ジョブキューのジョブの状態
+type QueueJobStatus string
+
+const (
+	// QueueJobPending 実行待ち（RunAtを過ぎたものから実行する。失敗して再試行を待つジョブを含む）
+	QueueJobPending QueueJobStatus = "pending"
+	// QueueJobRunning 実行中（LockedUntilを過ぎた場合はワーカーが異常終了したとみなして再実行する）
+	QueueJobRunning QueueJobStatus = "running"
+	// QueueJobSucceeded 成功
+	QueueJobSucceeded QueueJobStatus = "succeeded"
+	// QueueJobDead 試行回数の上限に達した、または再試行しても成功しない失敗（デッドレター。管理APIで再実行できる）
+	QueueJobDead QueueJobStatus = "dead"
+)
+
+// QueueJob 非同期に実行するジョブ（Webhookの送信・メールの送信・LLMの処理等）
+type QueueJob struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// Kind ジョブの種類（email.send 等。種類ごとに登録した処理で実行する）
+	Kind string `json:"kind" gorm:"size:64;not null;index"`
+	// Payload 処理に渡すJSON
+	Payload string         `json:"payload" gorm:"type:text;not null"`
+	Status  QueueJobStatus `json:"status" gorm:"size:20;not null;index:idx_queue_jobs_status_run_at,priority:1"`
+	// Attempts 実行した回数
+	Attempts    int `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int `json:"max_attempts" gorm:"not null"`
+	// RunAt 次に実行する日時（失敗した場合は指数バックオフで先送りする）
+	RunAt time.Time `json:"run_at" gorm:"not null;index:idx_queue_jobs_status_run_at,priority:2"`
+	// LockedBy 実行中のインスタンス
+	LockedBy string `json:"locked_by,omitempty" gorm:"size:255"`
+	// LockedUntil 実行中のロックの期限
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	// LastError 直近の失敗理由
+	LastError  string     `json:"last_error,omitempty" gorm:"type:text"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" gorm:"index"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (QueueJob) TableName() string {
+	return "queue_jobs"
+}
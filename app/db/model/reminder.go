@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// ReminderStatus リマインダーの配信状態
+type ReminderStatus string
+
+const (
+	// ReminderDelivered 配信済み
+	ReminderDelivered ReminderStatus = "delivered"
+	// ReminderFailed 配信に失敗（NextAttemptAtに再試行する。上限に達した場合は再試行しない）
+	ReminderFailed ReminderStatus = "failed"
+	// ReminderSkipped 送信先の通知条件（最低の優先度）を満たさないため配信しない
+	ReminderSkipped ReminderStatus = "skipped"
+)
+
+// ReminderDelivery Todoの期限間近・期限切れのリマインダーの送信先ごとの配信状態
+type ReminderDelivery struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	TodoID uint `json:"todo_id" gorm:"not null;uniqueIndex:idx_reminder_deliveries_target"`
+	// Event 通知の種類（due_soon / overdue）
+	Event   string `json:"event" gorm:"size:20;not null;uniqueIndex:idx_reminder_deliveries_target"`
+	Channel string `json:"channel" gorm:"size:64;not null;uniqueIndex:idx_reminder_deliveries_target"`
+	// DueDate 配信した時点のTodoの期限（期限が変更された場合は改めて配信する）
+	DueDate time.Time      `json:"due_date"`
+	Status  ReminderStatus `json:"status" gorm:"size:20;not null;index"`
+	// Failures 連続した配信の失敗回数（配信に成功するか期限が変わるとリセットされる）
+	Failures int `json:"failures" gorm:"not null;default:0"`
+	// LastError 直近の配信の失敗理由
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	// NextAttemptAt 次に配信する日時（再通知・再試行。未設定の場合は期限が変わるまで配信しない）
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (ReminderDelivery) TableName() string {
+	return "reminder_deliveries"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ScheduledJob スケジューラーのジョブごとの実行状態（複数インスタンスでの多重実行を防ぐロックを兼ねる）
+type ScheduledJob struct {
+	Name string `gorm:"primaryKey;size:64"`
+	// LastFiredAt 直近に実行した予定時刻（同じ予定時刻ではいずれか1つのインスタンスのみが実行する）
+	LastFiredAt *time.Time
+	// LockedBy 実行中のインスタンス
+	LockedBy string `gorm:"size:255"`
+	// LockedUntil 実行中のロックの期限（実行が異常終了した場合はこの時刻で解放される）
+	LockedUntil    *time.Time
+	LastFinishedAt *time.Time
+	LastError      string `gorm:"type:text"`
+}
+
+// TableName テーブル名を指定
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
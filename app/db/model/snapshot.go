@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Snapshot ストレージへエクスポートした全データのスナップショット（マニフェストとしてストレージに保存する）
+type Snapshot struct {
+	ID        string         `json:"id" doc:"スナップショットのID（作成日時 YYYYMMDD-HHMMSS、UTC）"`
+	CreatedAt time.Time      `json:"created_at" doc:"作成日時"`
+	Todos     int            `json:"todos" doc:"エクスポートしたTodoの件数"`
+	Files     []SnapshotFile `json:"files" doc:"形式ごとのファイル"`
+}
+
+// SnapshotFile スナップショットの形式ごとのファイル
+type SnapshotFile struct {
+	Format string `json:"format" doc:"形式（json / csv）"`
+	Key    string `json:"key" doc:"ストレージのキー"`
+	Size   int64  `json:"size" doc:"サイズ（バイト）"`
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,15 +11,57 @@ import (
 
 // Todo Todoアイテムのモデル
 type Todo struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
+	ID          uint           `json:"id" gorm:"primaryKey;index:idx_todos_created_at_id,priority:2"`
 	Title       string         `json:"title" gorm:"not null;size:255" validate:"required,max=255"`
 	Description string         `json:"description" gorm:"type:text"`
 	Completed   bool           `json:"completed" gorm:"default:false"`
 	Priority    Priority       `json:"priority" gorm:"type:varchar(10);default:'medium'"`
 	DueDate     *time.Time     `json:"due_date,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
+	Tags        Tags           `json:"tags" gorm:"type:text"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"index:idx_todos_created_at_id,priority:1"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	// CompletedAt 完了にした日時（未完了に戻すとリセットされる。完了状態で取り込んだTodoは未設定）
+	CompletedAt *time.Time `json:"-" gorm:"index"`
+	// DueAllDay 期限が日付のみ（終日）。DueDateはその日付のUTCの0時で、利用者のタイムゾーンでのその日の終わりまでを期限とする
+	DueAllDay bool `json:"due_all_day" gorm:"not null;default:false"`
+	// OverdueNotifiedAt・DueRemindedAt 旧方式の通知済みの日時（現在は reminder_deliveries に送信先ごとに記録するため使用しない）
+	OverdueNotifiedAt *time.Time `json:"-"`
+	DueRemindedAt     *time.Time `json:"-"`
+	// GoogleEventID 同期したGoogleカレンダーの予定ID
+	GoogleEventID *string `json:"-" gorm:"size:1024;index"`
+	// CalendarSyncedAt カレンダーに反映済みの更新日時（updated_atがこれより新しければ未反映）
+	CalendarSyncedAt *time.Time `json:"-"`
+	// CalDAVName CalDAVクライアントが作成したリソース名（未設定の場合は todo-<id>.ics で公開）
+	CalDAVName *string `json:"-" gorm:"column:caldav_name;size:255;uniqueIndex"`
+	// CalDAVUID CalDAVクライアントが指定したVTODOのUID
+	CalDAVUID *string `json:"-" gorm:"column:caldav_uid;size:255"`
+	// GitHubIssue 同期したGitHubのIssue（owner/repo#番号）
+	GitHubIssue *string `json:"-" gorm:"column:github_issue;size:255;uniqueIndex"`
+	// GitHubSyncedAt Issueに反映済みの更新日時（updated_atがこれより新しければ未反映）
+	GitHubSyncedAt *time.Time `json:"-" gorm:"column:github_synced_at"`
+	// JiraIssue 同期したJiraの課題のキー（ABC-123）
+	JiraIssue *string `json:"-" gorm:"size:255;uniqueIndex"`
+	// JiraSyncedAt 課題に反映済みの更新日時（updated_atがこれより新しければ未反映）
+	JiraSyncedAt *time.Time `json:"-"`
+	// NotionPageID エクスポートしたNotionのページID
+	NotionPageID *string `json:"-" gorm:"size:64;uniqueIndex"`
+	// NotionSyncedAt ページに反映済みの更新日時（updated_atがこれより新しければ未反映）
+	NotionSyncedAt *time.Time `json:"-"`
+	// RecurrenceRule 繰り返しルール（iCalendarのRRULE形式。設定されている場合は次回のTodoを生成する）
+	RecurrenceRule *string `json:"recurrence_rule,omitempty" gorm:"size:255"`
+	// RecurrenceTimezone 繰り返しの日付を数えるタイムゾーン（空の場合はスケジューラーのタイムゾーン）
+	RecurrenceTimezone string `json:"recurrence_timezone,omitempty" gorm:"size:64"`
+	// SkipHolidays 次回の日付が祝日の場合はその回を飛ばし、ルール上の次の日付にする
+	SkipHolidays bool `json:"skip_holidays" gorm:"not null;default:false"`
+	// RecurrenceParentID 生成元の繰り返しTodo
+	RecurrenceParentID *uint `json:"recurrence_parent_id,omitempty" gorm:"index"`
+	// RecurrenceGeneratedAt 次回のTodoを生成した日時（未設定の場合は未生成）
+	RecurrenceGeneratedAt *time.Time `json:"-" gorm:"index"`
+	// NeedsReview 長期間更新されていないため見直しが必要（放置タスクの検出ジョブが付け、更新すると外れる）
+	NeedsReview bool `json:"needs_review" gorm:"not null;default:false"`
+	// StaleDetectedAt 放置タスクとして検出した日時（検出は更新されるまで1回だけ。更新するとリセットされる）
+	StaleDetectedAt *time.Time `json:"-"`
 }
 
 // Priority 優先度の列挙型
@@ -39,17 +84,91 @@ func (p Priority) IsValid() bool {
 	}
 }
 
+// Rank 優先度の大小比較用の値（lowが最小。無効な値は0）
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	default:
+		return 0
+	}
+}
+
 // String 優先度を文字列で返す
 func (p Priority) String() string {
 	return string(p)
 }
 
+// TodoSort 一覧の並び順
+type TodoSort string
+
+const (
+	// TodoSortCreatedAt 作成日時の新しい順（既定）
+	TodoSortCreatedAt TodoSort = "created_at"
+	// TodoSortDueDate 期限の近い順（期限のないものは最後）
+	TodoSortDueDate TodoSort = "due_date"
+	// TodoSortPriority 優先度の高い順
+	TodoSortPriority TodoSort = "priority"
+)
+
+// IsValid 並び順が有効かチェック
+func (s TodoSort) IsValid() bool {
+	switch s {
+	case TodoSortCreatedAt, TodoSortDueDate, TodoSortPriority:
+		return true
+	default:
+		return false
+	}
+}
+
+// Tags Todoのタグ（JSON配列の文字列として保存する）
+type Tags []string
+
+// Value JSON配列に変換して保存（空の場合はNULL）
+func (t Tags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal([]string(t))
+	return string(b), err
+}
+
+// Scan 保存したJSON配列を読み込む
+func (t *Tags) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("タグの型が不正です: %T", value)
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
 // TodoCreateRequest Todo作成リクエスト用の構造体
 type TodoCreateRequest struct {
 	Title       string     `json:"title" validate:"required,max=255"`
 	Description string     `json:"description"`
 	Priority    Priority   `json:"priority"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty" maxItems:"20"`
+	// RecurrenceRule 繰り返しルール（"FREQ=WEEKLY;BYDAY=MO" 等。期限の指定が必要）
+	RecurrenceRule     string `json:"recurrence_rule,omitempty" maxLength:"255" doc:"繰り返しルール（RRULE形式）"`
+	RecurrenceTimezone string `json:"recurrence_timezone,omitempty" maxLength:"64" doc:"繰り返しの日付を数えるタイムゾーン（例: Asia/Tokyo）"`
+	SkipHolidays       bool   `json:"skip_holidays,omitempty" doc:"次回の日付が祝日の場合はその次の日付にする"`
+	// DueAllDay 期限を日付のみ（終日）にする（due_dateの日付のみを使い、時刻は無視する）
+	DueAllDay bool `json:"due_all_day,omitempty" doc:"期限を日付のみ（終日）にする。due_dateは日付のみを使い、利用者のタイムゾーンでのその日の終わりまでを期限とする"`
 }
 
 // TodoUpdateRequest Todo更新リクエスト用の構造体
@@ -59,6 +178,14 @@ type TodoUpdateRequest struct {
 	Completed   *bool      `json:"completed,omitempty"`
 	Priority    *Priority  `json:"priority,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	// Tags 指定した場合はタグを置き換える（空の配列で全て外す）
+	Tags *[]string `json:"tags,omitempty" maxItems:"20"`
+	// RecurrenceRule 指定した場合は繰り返しルールを置き換える（空文字で繰り返しを解除）
+	RecurrenceRule     *string `json:"recurrence_rule,omitempty" maxLength:"255" doc:"繰り返しルール（RRULE形式。空文字で解除）"`
+	RecurrenceTimezone *string `json:"recurrence_timezone,omitempty" maxLength:"64" doc:"繰り返しの日付を数えるタイムゾーン"`
+	SkipHolidays       *bool   `json:"skip_holidays,omitempty" doc:"次回の日付が祝日の場合はその次の日付にする"`
+	// DueAllDay 指定した場合は期限を日付のみ（終日）にするかを切り替える（省略した場合は現在の設定のまま）
+	DueAllDay *bool `json:"due_all_day,omitempty" doc:"期限を日付のみ（終日）にする"`
 }
 
 // TodoResponse APIレスポンス用のTodo構造体
@@ -69,8 +196,18 @@ type TodoResponse struct {
 	Completed   bool       `json:"completed"`
 	Priority    Priority   `json:"priority"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        Tags       `json:"tags,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	// DueAllDay 期限が日付のみ（終日）。due_dateはその日付のUTCの0時
+	DueAllDay bool `json:"due_all_day,omitempty"`
+	// 繰り返しの設定（繰り返しのないTodoでは省略）
+	RecurrenceRule     *string `json:"recurrence_rule,omitempty"`
+	RecurrenceTimezone string  `json:"recurrence_timezone,omitempty"`
+	SkipHolidays       bool    `json:"skip_holidays,omitempty"`
+	RecurrenceParentID *uint   `json:"recurrence_parent_id,omitempty"`
+	// NeedsReview 長期間更新されていないため見直しが必要（放置タスクの検出時のみtrue）
+	NeedsReview bool `json:"needs_review,omitempty"`
 }
 
 // ToResponse TodoモデルをTodoResponseに変換
@@ -82,8 +219,16 @@ func (t *Todo) ToResponse() *TodoResponse {
 		Completed:   t.Completed,
 		Priority:    t.Priority,
 		DueDate:     t.DueDate,
+		DueAllDay:   t.DueAllDay,
+		Tags:        t.Tags,
 		CreatedAt:   t.CreatedAt,
 		UpdatedAt:   t.UpdatedAt,
+
+		RecurrenceRule:     t.RecurrenceRule,
+		RecurrenceTimezone: t.RecurrenceTimezone,
+		SkipHolidays:       t.SkipHolidays,
+		RecurrenceParentID: t.RecurrenceParentID,
+		NeedsReview:        t.NeedsReview,
 	}
 }
 
@@ -91,3 +236,51 @@ func (t *Todo) ToResponse() *TodoResponse {
 func (Todo) TableName() string {
 	return "todos"
 }
+
+// TodoColumns 一覧・取得時にSELECTするカラム（SELECT * を避け、deleted_atなど不要な列を読まない）
+var TodoColumns = []string{
+	"id", "title", "description", "completed", "priority", "due_date", "due_all_day", "tags", "created_at", "updated_at",
+	"recurrence_rule", "recurrence_timezone", "skip_holidays", "recurrence_parent_id",
+	"needs_review", "stale_detected_at",
+}
+
+// AllDayDate 日時を終日の期限の値（書かれた日付のUTCの0時）にする
+func AllDayDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// AllDayCutoff 日時tの時点で期限を過ぎている終日の期限の上限（locでのtの日付のUTCの0時。これより前の日付が期限切れ）
+func AllDayCutoff(t time.Time, loc *time.Location) time.Time {
+	return AllDayDate(t.In(loc))
+}
+
+// DueBefore 期限がtより前か（終日の期限はlocでのその日の終わりを期限とする。期限がない場合はfalse）
+func (t *Todo) DueBefore(at time.Time, loc *time.Location) bool {
+	if t.DueDate == nil {
+		return false
+	}
+	if t.DueAllDay {
+		return t.DueDate.Before(AllDayCutoff(at, loc))
+	}
+	return t.DueDate.Before(at)
+}
+
+// DueIn 表示用の期限（時刻のある期限はlocの時刻にする。終日の期限は日付を変えないためそのまま）
+func (t *Todo) DueIn(loc *time.Location) *time.Time {
+	if t.DueDate == nil || t.DueAllDay {
+		return t.DueDate
+	}
+	due := t.DueDate.In(loc)
+	return &due
+}
+
+// FormatDue 通知等に表示する期限（終日の期限は日付のみ。期限がない場合は空文字）
+func (t Todo) FormatDue() string {
+	if t.DueDate == nil {
+		return ""
+	}
+	if t.DueAllDay {
+		return t.DueDate.Format("2006-01-02")
+	}
+	return t.DueDate.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// UserProfileID プロファイルの行のID（インスタンス全体で1行のみ）
+const UserProfileID = 1
+
+// UserProfile インスタンス（ワークスペース）全体の設定
+// ユーザーごとの設定ではなく、APIを呼び出す全員・スケジューラーのジョブ・連携に共通で適用する。「利用者のタイムゾーン」はこの設定のタイムゾーンを指す
+type UserProfile struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	// Timezone 「今日」「期限切れ」の判定・リマインダー・ダイジェストに使うタイムゾーン（IANAの名前。空の場合はスケジューラーのタイムゾーン）
+	Timezone string `json:"timezone" gorm:"size:64;not null;default:''"`
+	// EffectiveTimezone 実際に使うタイムゾーン（Timezoneが空の場合はスケジューラーのタイムゾーン）
+	EffectiveTimezone string `json:"effective_timezone" gorm:"-"`
+	// DefaultSort 並び順を指定しない一覧の並び順
+	DefaultSort TodoSort  `json:"default_sort" gorm:"size:16;not null;default:'created_at'"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (UserProfile) TableName() string {
+	return "user_profiles"
+}
+
+// UserProfileRequest プロファイル（インスタンス全体の設定）の更新リクエスト
+type UserProfileRequest struct {
+	Timezone    string   `json:"timezone" maxLength:"64" doc:"「今日」「期限切れ」の判定・リマインダー・ダイジェストに使うタイムゾーン（IANAの名前。例: Asia/Tokyo。空の場合はスケジューラーのタイムゾーン）"`
+	DefaultSort TodoSort `json:"default_sort,omitempty" enum:"created_at,due_date,priority" doc:"並び順を指定しない一覧の並び順（created_at: 作成日時の新しい順 / due_date: 期限の近い順 / priority: 優先度の高い順。省略時は変更しない）"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// WebhookEndpoint Todoのイベントを配信するOutgoing Webhookの送信先
+// 配信に失敗し続けた送信先は自動で停止し（Enabled=false）、更新APIで再開するまで配信しない
+type WebhookEndpoint struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	URL         string `json:"url" gorm:"size:2048;not null"`
+	Description string `json:"description,omitempty" gorm:"size:255"`
+	// Events 配信するイベントの種類（todo.created / todo.updated / todo.deleted。空の場合は全て）
+	Events  []string `json:"events" gorm:"serializer:json;type:text"`
+	Enabled bool     `json:"enabled" gorm:"not null"`
+	// ConsecutiveFailures 連続して配信に失敗した回数（成功するか再開すると0に戻す）
+	ConsecutiveFailures int `json:"consecutive_failures" gorm:"not null;default:0"`
+	// DisabledReason・DisabledAt 自動で停止した理由と日時
+	DisabledReason string     `json:"disabled_reason,omitempty" gorm:"size:255"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookEndpointRequest Webhookの送信先の登録・更新リクエスト
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" minLength:"1" maxLength:"2048" format:"uri" doc:"送信先のURL（http / https）"`
+	Description string   `json:"description,omitempty" maxLength:"255" doc:"説明"`
+	Events      []string `json:"events,omitempty" maxItems:"3" doc:"配信するイベントの種類（todo.created / todo.updated / todo.deleted。省略時は全て）"`
+	Enabled     *bool    `json:"enabled,omitempty" doc:"配信するか（省略時はtrue。停止中の送信先をtrueで更新すると連続失敗回数を戻して再開する）"`
+}
+
+// WebhookDeliveryStatus Webhookの配信の状態
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending 配信待ち（失敗して再試行を待つものを含む）
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded 配信に成功
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed 試行回数の上限に達した、または再試行しても成功しない失敗（管理APIで再配信できる）
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+	// WebhookDeliveryCanceled 送信先の停止・削除により配信しなかった
+	WebhookDeliveryCanceled WebhookDeliveryStatus = "canceled"
+)
+
+// WebhookDelivery 送信先へのイベント1件の配信の履歴（配信はジョブキューで行い、試行ごとに結果を更新する）
+type WebhookDelivery struct {
+	ID         uint `json:"id" gorm:"primaryKey"`
+	EndpointID uint `json:"endpoint_id" gorm:"not null;index"`
+	// EventID・EventType 配信するCloudEventsのidとtype
+	EventID   string `json:"event_id" gorm:"size:64;not null;index"`
+	EventType string `json:"event_type" gorm:"size:64;not null"`
+	// Payload 送信する本文（CloudEventsの構造化モードのJSON）
+	Payload string                `json:"payload" gorm:"type:text;not null"`
+	Status  WebhookDeliveryStatus `json:"status" gorm:"size:20;not null;index"`
+	// Attempts 送信を試行した回数
+	Attempts int `json:"attempts" gorm:"not null;default:0"`
+	// ResponseStatus 直近の試行で送信先が返したステータス（接続できなかった場合は0）
+	ResponseStatus int `json:"response_status,omitempty"`
+	// LastError 直近の失敗理由
+	LastError string `json:"last_error,omitempty" gorm:"type:text"`
+	// JobID 配信するジョブキューのジョブ（再配信すると新しいジョブになる）
+	JobID       uint       `json:"job_id,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName テーブル名を指定
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// WebhookSecret Outgoing Webhookの署名シークレット
+// ExpiresAtがnilのものが現在のシークレット、それ以外はローテーション猶予中（期限まで併せて署名する）
+type WebhookSecret struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Secret    string     `json:"-" gorm:"not null;size:255"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// TableName テーブル名を指定
+func (WebhookSecret) TableName() string {
+	return "webhook_secrets"
+}
+
+// WebhookSecretRotation シークレットのローテーション結果
+type WebhookSecretRotation struct {
+	Secret             string     `json:"secret" doc:"新しい署名シークレット（この応答でのみ返す）"`
+	PreviousExpiresAt  *time.Time `json:"previous_expires_at,omitempty" doc:"旧シークレットでの署名を終了する日時"`
+	GracePeriodSeconds int        `json:"grace_period_seconds" doc:"新旧両方で署名する猶予期間（秒）"`
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// テナントスキーマ名の接頭辞
+const tenantSchemaPrefix = "tenant_"
+
+// tenantIDPattern テナントIDとして許可する形式（スキーマ名に埋め込むため厳格に制限）
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,54}$`)
+
+// TenantSchemaName ワークスペースIDからPostgresスキーマ名を生成
+func TenantSchemaName(tenantID string) (string, error) {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return "", fmt.Errorf("無効なテナントIDです: %s", tenantID)
+	}
+	return tenantSchemaPrefix + tenantID, nil
+}
+
+// WithTenant テナントのスキーマを参照するトランザクションでfnを実行
+// テナントごとに接続プールを持たず、共有のプールから取った接続でトランザクション内だけ search_path を切り替える（SET LOCAL）
+// コミット・ロールバックで元に戻るため、接続をプールに返した後に他のテナントへ漏れない（PgBouncerのトランザクションモードでも使える）
+func WithTenant(ctx context.Context, tenantID string, fn func(tx *gorm.DB) error) error {
+	if DB == nil {
+		return fmt.Errorf("データベース接続が初期化されていません")
+	}
+
+	schema, err := TenantSchemaName(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := setSearchPath(tx, schema); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// setSearchPath トランザクション内の search_path をスキーマに切り替える（set_config の第3引数trueで SET LOCAL と同じ）
+func setSearchPath(tx *gorm.DB, schema string) error {
+	if err := tx.Exec("SELECT set_config('search_path', ?, true)", schema).Error; err != nil {
+		return fmt.Errorf("テナントスキーマ %s への切り替えに失敗しました: %w", schema, err)
+	}
+	return nil
+}
+
+// CreateTenant テナント用スキーマを作成し、マイグレーションを適用
+// スキーマの作成とマイグレーションは1つのトランザクションで行うため、途中で失敗した場合はスキーマも残らない
+func CreateTenant(ctx context.Context, tenantID string) error {
+	if DB == nil {
+		return fmt.Errorf("データベース接続が初期化されていません")
+	}
+
+	schema, err := TenantSchemaName(tenantID)
+	if err != nil {
+		return err
+	}
+
+	err = DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, schema)).Error; err != nil {
+			return fmt.Errorf("テナントスキーマ %s の作成に失敗しました: %w", schema, err)
+		}
+		if err := setSearchPath(tx, schema); err != nil {
+			return err
+		}
+		if err := migrate(tx); err != nil {
+			return fmt.Errorf("テナント %s のマイグレーションに失敗しました: %w", tenantID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "テナントを作成しました", "tenant", tenantID, "schema", schema)
+	return nil
+}
@@ -0,0 +1,159 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/config"
+	"myapp/health"
+	"myapp/shutdown"
+	"myapp/version"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// 問題の重要度
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// 問題とみなす閾値
+const (
+	diskFreeWarningRatio = 0.10
+	goroutineWarning     = 10000
+)
+
+// Issue 検出された問題
+type Issue struct {
+	Component string `json:"component" doc:"問題のある項目" example:"config"`
+	Severity  string `json:"severity" doc:"重要度" enum:"error,warning"`
+	Message   string `json:"message" doc:"問題の内容"`
+}
+
+// DiskStatus ディスクの状況
+type DiskStatus struct {
+	Path       string  `json:"path" doc:"確認したパス"`
+	TotalBytes uint64  `json:"total_bytes" doc:"総容量（バイト）"`
+	FreeBytes  uint64  `json:"free_bytes" doc:"空き容量（バイト）"`
+	FreeRatio  float64 `json:"free_ratio" doc:"空き容量の割合（0〜1）"`
+}
+
+// MemoryStatus メモリの状況
+type MemoryStatus struct {
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes" doc:"使用中のヒープ（バイト）"`
+	SysBytes       uint64 `json:"sys_bytes" doc:"OSから確保したメモリ（バイト）"`
+	NumGC          uint32 `json:"num_gc" doc:"GC実行回数"`
+	Goroutines     int    `json:"goroutines" doc:"ゴルーチン数"`
+}
+
+// Report 診断結果
+type Report struct {
+	Status       string                `json:"status" doc:"全体のステータス" enum:"ok,warning,error"`
+	Version      version.Info          `json:"version" doc:"バージョン情報"`
+	Uptime       string                `json:"uptime" doc:"起動からの経過時間"`
+	Issues       []Issue               `json:"issues" doc:"検出された問題の一覧"`
+	Dependencies []*health.CheckResult `json:"dependencies" doc:"依存サービスの接続状況"`
+	Disk         *DiskStatus           `json:"disk,omitempty" doc:"ディスクの状況（取得できない環境では省略）"`
+	Memory       MemoryStatus          `json:"memory" doc:"メモリの状況"`
+	Workers      map[string]int        `json:"workers" doc:"稼働中のバックグラウンドワーカー"`
+}
+
+// Diagnostics 設定・依存接続・リソース状況をまとめて確認する
+type Diagnostics struct {
+	aggregator *health.Aggregator
+	shutdown   *shutdown.Manager
+	startedAt  time.Time
+}
+
+// New 新しいDiagnosticsインスタンスを作成
+func New(aggregator *health.Aggregator, shutdownManager *shutdown.Manager) *Diagnostics {
+	return &Diagnostics{
+		aggregator: aggregator,
+		shutdown:   shutdownManager,
+		startedAt:  time.Now(),
+	}
+}
+
+// Run 全ての診断を実行して問題点を列挙
+func (d *Diagnostics) Run(ctx context.Context) *Report {
+	report := &Report{
+		Version: version.Get(),
+		Uptime:  time.Since(d.startedAt).Round(time.Second).String(),
+		Issues:  []Issue{},
+		Workers: d.shutdown.Workers(),
+	}
+
+	// 設定の妥当性
+	if err := config.Current().Validate(); err != nil {
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			for _, fe := range verr.Errors {
+				report.Issues = append(report.Issues, Issue{Component: "config", Severity: SeverityError, Message: fe.String()})
+			}
+		} else {
+			report.Issues = append(report.Issues, Issue{Component: "config", Severity: SeverityError, Message: err.Error()})
+		}
+	}
+
+	// 依存サービス
+	_, report.Dependencies = d.aggregator.CheckAll(ctx)
+	for _, result := range report.Dependencies {
+		if result.Status == health.StatusHealthy {
+			continue
+		}
+		severity := SeverityWarning
+		if result.Critical {
+			severity = SeverityError
+		}
+		report.Issues = append(report.Issues, Issue{Component: result.Name, Severity: severity, Message: result.Error})
+	}
+
+	// ディスク
+	if dir, err := os.Getwd(); err == nil {
+		if disk, err := diskUsage(dir); err == nil {
+			report.Disk = disk
+			if disk.FreeRatio < diskFreeWarningRatio {
+				report.Issues = append(report.Issues, Issue{
+					Component: "disk",
+					Severity:  SeverityWarning,
+					Message:   fmt.Sprintf("%s の空き容量が %.1f%% です", disk.Path, disk.FreeRatio*100),
+				})
+			}
+		}
+	}
+
+	// メモリ
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report.Memory = MemoryStatus{
+		HeapAllocBytes: mem.HeapAlloc,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+		Goroutines:     runtime.NumGoroutine(),
+	}
+	if report.Memory.Goroutines > goroutineWarning {
+		report.Issues = append(report.Issues, Issue{
+			Component: "memory",
+			Severity:  SeverityWarning,
+			Message:   fmt.Sprintf("ゴルーチン数が %d です（リークの可能性）", report.Memory.Goroutines),
+		})
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Severity == SeverityError && report.Issues[j].Severity != SeverityError
+	})
+
+	report.Status = "ok"
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityError {
+			report.Status = "error"
+			break
+		}
+		report.Status = "warning"
+	}
+
+	return report
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package diagnostics
+
+import "errors"
+
+// diskUsage この環境ではディスク使用状況を取得しない
+func diskUsage(path string) (*DiskStatus, error) {
+	return nil, errors.New("ディスク使用状況の取得に未対応の環境です")
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin
+
+package diagnostics
+
+import "syscall"
+
+// diskUsage 指定パスを含むファイルシステムの使用状況を取得
+func diskUsage(path string) (*DiskStatus, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, err
+	}
+
+	total := stat.Blocks * uint64(stat.Bsize)
+	free := stat.Bavail * uint64(stat.Bsize)
+
+	status := &DiskStatus{
+		Path:       path,
+		TotalBytes: total,
+		FreeBytes:  free,
+	}
+	if total > 0 {
+		status.FreeRatio = float64(free) / float64(total)
+	}
+	return status, nil
+}
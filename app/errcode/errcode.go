@@ -0,0 +1,193 @@
+// Package errcode エラーレスポンスに含める機械可読なエラーコードと、コード付きのエラー
+// サービスはコード付きのエラー（*Error）を返し、ハンドラーはメッセージ文字列ではなく errors.Is で判定する
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code 機械可読なエラーコード（大文字のスネークケース。一度公開したコードは変更しない）
+type Code string
+
+// ステータスコードに対応する汎用のコード（個別のコードがないエラーに付ける）
+const (
+	BadRequest         Code = "BAD_REQUEST"
+	Unauthorized       Code = "UNAUTHORIZED"
+	Forbidden          Code = "FORBIDDEN"
+	NotFound           Code = "NOT_FOUND"
+	MethodNotAllowed   Code = "METHOD_NOT_ALLOWED"
+	Conflict           Code = "CONFLICT"
+	PayloadTooLarge    Code = "PAYLOAD_TOO_LARGE"
+	ValidationFailed   Code = "VALIDATION_FAILED"
+	RateLimited        Code = "RATE_LIMITED"
+	Internal           Code = "INTERNAL_ERROR"
+	ServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+	Timeout            Code = "TIMEOUT"
+)
+
+// リクエスト共通
+const (
+	UnknownField  Code = "UNKNOWN_FIELD"
+	InvalidCursor Code = "INVALID_CURSOR"
+)
+
+// Todo
+const (
+	TodoNotFound          Code = "TODO_NOT_FOUND"
+	TitleRequired         Code = "TITLE_REQUIRED"
+	TitleTooLong          Code = "TITLE_TOO_LONG"
+	InvalidPriority       Code = "INVALID_PRIORITY"
+	InvalidTag            Code = "INVALID_TAG"
+	TooManyTags           Code = "TOO_MANY_TAGS"
+	InvalidTimezone       Code = "INVALID_TIMEZONE"
+	InvalidSort           Code = "INVALID_SORT"
+	InvalidRecurrenceRule Code = "INVALID_RECURRENCE_RULE"
+	DueDateRequired       Code = "DUE_DATE_REQUIRED"
+	DueDateInPast         Code = "DUE_DATE_IN_PAST"
+	UnsupportedFormat     Code = "UNSUPPORTED_FORMAT"
+)
+
+// 一括操作・取り込み
+const (
+	BulkNotFound     Code = "BULK_NOT_FOUND"
+	BulkTooManyItems Code = "BULK_TOO_MANY_ITEMS"
+	BulkNoUpdate     Code = "BULK_NO_UPDATE"
+	BulkInvalidItem  Code = "BULK_INVALID_ITEM"
+	ImportNotFound   Code = "IMPORT_NOT_FOUND"
+	ImportEmpty      Code = "IMPORT_EMPTY"
+)
+
+// ジョブキュー
+const (
+	JobNotFound     Code = "JOB_NOT_FOUND"
+	JobNotRetryable Code = "JOB_NOT_RETRYABLE"
+	JobRunning      Code = "JOB_RUNNING"
+)
+
+// スケジューラー
+const (
+	ScheduledJobNotFound Code = "SCHEDULED_JOB_NOT_FOUND"
+	JobRunNotFound       Code = "JOB_RUN_NOT_FOUND"
+	SchedulerNotRunning  Code = "SCHEDULER_NOT_RUNNING"
+)
+
+// Webhook
+const (
+	WebhookEndpointNotFound Code = "WEBHOOK_ENDPOINT_NOT_FOUND"
+	WebhookInvalidURL       Code = "WEBHOOK_INVALID_URL"
+	WebhookForbiddenURL     Code = "WEBHOOK_FORBIDDEN_URL"
+	WebhookUnknownEvent     Code = "WEBHOOK_UNKNOWN_EVENT"
+	WebhookDeliveryNotFound Code = "WEBHOOK_DELIVERY_NOT_FOUND"
+	WebhookDeliveryPending  Code = "WEBHOOK_DELIVERY_PENDING"
+	WebhookEndpointDisabled Code = "WEBHOOK_ENDPOINT_DISABLED"
+	InvalidSignature        Code = "INVALID_SIGNATURE"
+)
+
+// 通知・エスカレーション・ダイジェスト・Web Push
+const (
+	EscalationRuleNotFound     Code = "ESCALATION_RULE_NOT_FOUND"
+	EscalationNoAction         Code = "ESCALATION_NO_ACTION"
+	EscalationUnknownChannel   Code = "ESCALATION_UNKNOWN_CHANNEL"
+	EscalationInvalidRecipient Code = "ESCALATION_INVALID_RECIPIENT"
+	EscalationEmailDisabled    Code = "ESCALATION_EMAIL_DISABLED"
+	DigestNotFound             Code = "DIGEST_SUBSCRIPTION_NOT_FOUND"
+	DigestEmailExists          Code = "DIGEST_EMAIL_EXISTS"
+	PushSubscriptionNotFound   Code = "PUSH_SUBSCRIPTION_NOT_FOUND"
+	PushEndpointNotAllowed     Code = "PUSH_ENDPOINT_NOT_ALLOWED"
+	PushInvalidSubscription    Code = "PUSH_INVALID_SUBSCRIPTION"
+)
+
+// スナップショット
+const (
+	SnapshotNotFound Code = "SNAPSHOT_NOT_FOUND"
+	SnapshotExists   Code = "SNAPSHOT_EXISTS"
+	SnapshotFormat   Code = "SNAPSHOT_FORMAT_NOT_FOUND"
+)
+
+// 外部サービス連携
+const (
+	NotConnected      Code = "NOT_CONNECTED"
+	SyncRunning       Code = "SYNC_RUNNING"
+	InvalidOAuthState Code = "INVALID_OAUTH_STATE"
+	ListNotFound      Code = "LIST_NOT_FOUND"
+	InvalidSchema     Code = "INVALID_SCHEMA"
+	MailDuplicate     Code = "MAIL_DUPLICATE"
+)
+
+// ForStatus ステータスコードに対応する汎用のコード
+func ForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return ValidationFailed
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusServiceUnavailable:
+		return ServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return Timeout
+	}
+	if status >= http.StatusInternalServerError {
+		return Internal
+	}
+	return BadRequest
+}
+
+// Error コード付きのエラー
+// errors.Is は同じコードの *Error と一致するため、パッケージ変数のエラー（New）と、
+// 値を埋め込んだメッセージのエラー（Errorf）を同じように判定できる
+type Error struct {
+	Code Code
+	err  error
+}
+
+// New コード付きのエラーを作る（パッケージ変数のエラーの定義用）
+func New(code Code, msg string) *Error {
+	return &Error{Code: code, err: errors.New(msg)}
+}
+
+// Errorf メッセージを書式で組み立てたコード付きのエラーを作る（%w でラップしたエラーは errors.Is / As で辿れる）
+func Errorf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// Is 同じコードの *Error と一致する
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Of エラーに付いたコード（ラップされたものを含め、最も外側の *Error のもの。なければ ValidationError のもの）。コードがない場合は空文字
+func Of(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	if v, ok := AsValidation(err); ok {
+		return v.Code()
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FieldError フィールド単位のバリデーションエラー
+type FieldError struct {
+	// Field リクエストボディのJSONでの位置（priority、tags[2]、todos[0].title 等）
+	Field string
+	// Code 無効な理由のコード
+	Code Code
+	// Message 無効な理由
+	Message string
+	// Value 無効な値
+	Value any
+}
+
+// ValidationError 1つ以上のフィールドのバリデーションエラー
+// 最初のエラーで打ち切らずに全てのフィールドを検証し、まとめて返すために使う
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Add フィールドのエラーを追加する（メッセージはerrのメッセージ）
+func (v *ValidationError) Add(field string, value any, err *Error) {
+	v.Fields = append(v.Fields, &FieldError{Field: field, Code: err.Code, Message: err.Error(), Value: value})
+}
+
+// Merge 別の検証結果のエラーを、フィールドの位置にprefixを付けて追加する（一括作成の todos[0] 等）
+func (v *ValidationError) Merge(prefix string, other *ValidationError) {
+	for _, f := range other.Fields {
+		merged := *f
+		merged.Field = prefix + "." + f.Field
+		v.Fields = append(v.Fields, &merged)
+	}
+}
+
+// Err エラーがあれば自身を、なければnilを返す
+func (v *ValidationError) Err() error {
+	if len(v.Fields) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error 1件の場合はそのフィールドのメッセージ、複数の場合は件数（各フィールドの理由はFieldsに持つ）
+func (v *ValidationError) Error() string {
+	if len(v.Fields) == 1 {
+		return v.Fields[0].Message
+	}
+	return fmt.Sprintf("入力内容に%d件の誤りがあります", len(v.Fields))
+}
+
+// Code エラー全体のコード（1件の場合はそのフィールドのコード、複数の場合は VALIDATION_FAILED）
+func (v *ValidationError) Code() Code {
+	if len(v.Fields) == 1 {
+		return v.Fields[0].Code
+	}
+	return ValidationFailed
+}
+
+// Is いずれかのフィールドと同じコードの *Error、または VALIDATION_FAILED の *Error と一致する
+func (v *ValidationError) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if t.Code == ValidationFailed {
+		return true
+	}
+	for _, f := range v.Fields {
+		if f.Code == t.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// AsValidation エラーに含まれるフィールド単位のバリデーションエラー（ラップされたものを含む）
+func AsValidation(err error) (*ValidationError, bool) {
+	var v *ValidationError
+	if errors.As(err, &v) {
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// flushTimeout Shutdownのコンテキストに期限がない場合の送信待ちの上限
+const flushTimeout = 5 * time.Second
+
+// Init 環境変数からSentryのSDKを初期化
+// SENTRY_DSNが未設定の場合は何も送信しない。機密情報のスクラビングは送信前（BeforeSend）に行う
+func Init(release string) error {
+	dsn := os.Getenv("SENTRY_DSN")
+	if dsn == "" {
+		return nil
+	}
+
+	environment := os.Getenv("SENTRY_ENVIRONMENT")
+	if environment == "" {
+		environment = os.Getenv("GO_ENV")
+	}
+
+	sendPII := os.Getenv("SENTRY_SEND_DEFAULT_PII") == "true"
+	scrubber := NewScrubber(sendPII, os.Getenv("SENTRY_SCRUB_FIELDS"))
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:            dsn,
+		Environment:    environment,
+		Release:        release,
+		SendDefaultPII: sendPII,
+		BeforeSend: func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			scrubber.ScrubEvent(event)
+			return event
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Sentryの初期化に失敗しました: %w", err)
+	}
+
+	slog.Info("Sentryへのエラーレポーティングを開始しました", "environment", environment)
+	return nil
+}
+
+// Shutdown キューに残ったイベントを送信する（コンテキストの期限まで待つ）
+func Shutdown(ctx context.Context) error {
+	if !enabled() {
+		return nil
+	}
+
+	timeout := flushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !sentry.Flush(timeout) {
+		return fmt.Errorf("Sentryへの送信待ちがタイムアウトしました")
+	}
+	return nil
+}
+
+// enabled Sentryへの送信が有効か
+func enabled() bool {
+	return sentry.CurrentHub().Client() != nil
+}
@@ -0,0 +1,63 @@
+package errorreport
+
+import (
+	"context"
+	"myapp/requestid"
+	"myapp/tracing"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// newHub イベントごとのスコープを持つHubを作成し、リクエストID・トレースID・リクエスト情報を設定する（rはnil可）
+// リクエスト情報のスクラビングは送信時（BeforeSend）に行う
+func newHub(ctx context.Context, r *http.Request) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	scope := hub.Scope()
+	if id := requestid.FromContext(ctx); id != "" {
+		scope.SetTag("request_id", id)
+	}
+	if traceID := tracing.TraceIDFromContext(ctx); traceID != "" {
+		scope.SetTag("trace_id", traceID)
+	}
+	if r != nil {
+		scope.SetRequest(r)
+		transaction := r.Method + " " + r.URL.Path
+		scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			event.Transaction = transaction
+			return event
+		})
+	}
+	return hub
+}
+
+// CaptureError エラーをSentryへ送信（rはnil可）
+func CaptureError(ctx context.Context, err error, r *http.Request) {
+	if err == nil || !enabled() {
+		return
+	}
+	newHub(ctx, r).CaptureException(err)
+}
+
+// CaptureMessage メッセージをSentryへ送信（rはnil可）
+func CaptureMessage(ctx context.Context, level, message string, extra map[string]any, r *http.Request) {
+	if !enabled() {
+		return
+	}
+
+	hub := newHub(ctx, r)
+	hub.Scope().SetLevel(sentry.Level(level))
+	hub.Scope().SetExtras(extra)
+	hub.CaptureMessage(message)
+}
+
+// capturePanic リカバーした値をスタック付きでSentryへ送信
+func capturePanic(ctx context.Context, recovered any, r *http.Request) {
+	if !enabled() {
+		return
+	}
+
+	hub := newHub(ctx, r)
+	hub.Scope().SetLevel(sentry.LevelFatal)
+	hub.RecoverWithContext(ctx, recovered)
+}
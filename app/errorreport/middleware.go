@@ -0,0 +1,61 @@
+package errorreport
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// maxCapturedBody 5xxレスポンスとして記録する本文の最大バイト数
+const maxCapturedBody = 1024
+
+// Middleware パニックと5xxレスポンスをSentryへ送信するミドルウェア
+// パニックは送信後に再度panicするため、recovery.Recovererより内側で使用する
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec != http.ErrAbortHandler {
+					capturePanic(r.Context(), rec, r)
+				}
+				panic(rec)
+			}
+		}()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		body := &limitedBuffer{limit: maxCapturedBody}
+		ww.Tee(body)
+
+		next.ServeHTTP(ww, r)
+
+		if status := ww.Status(); status >= http.StatusInternalServerError {
+			CaptureMessage(r.Context(), "error", http.StatusText(status)+": "+r.Method+" "+r.URL.Path, map[string]any{
+				"status":   status,
+				"response": body.String(),
+			}, r)
+		}
+	})
+}
+
+// limitedBuffer 先頭limitバイトのみ保持するバッファ
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
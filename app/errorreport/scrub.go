@@ -0,0 +1,98 @@
+package errorreport
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// filtered スクラビングした値の置換文字列
+const filtered = "[Filtered]"
+
+// defaultSensitiveFields 常に伏せるヘッダー・クエリパラメータ名（小文字）
+var defaultSensitiveFields = []string{
+	"authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"proxy-authorization",
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"access_token",
+	"refresh_token",
+}
+
+// Scrubber 送信前にリクエスト情報から個人情報・機密情報を取り除く
+type Scrubber struct {
+	sendPII bool
+	fields  []string
+}
+
+// NewScrubber スクラバーを作成
+// sendPIIがfalseの場合はIPアドレス等の個人情報も送信しない。extraはカンマ区切りで追加の項目名
+func NewScrubber(sendPII bool, extra string) *Scrubber {
+	fields := append([]string{}, defaultSensitiveFields...)
+	for _, f := range strings.Split(extra, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return &Scrubber{sendPII: sendPII, fields: fields}
+}
+
+// ScrubEvent 送信前のイベントからリクエスト情報の機密項目と、個人情報（sendPIIがfalseの場合）を取り除く
+func (s *Scrubber) ScrubEvent(event *sentry.Event) {
+	if event.Request != nil {
+		s.Scrub(event.Request)
+	}
+	if !s.sendPII {
+		event.User = sentry.User{}
+	}
+}
+
+// Scrub リクエスト情報のヘッダー・Cookie・クエリ文字列を伏せ字にする
+func (s *Scrubber) Scrub(req *sentry.Request) {
+	if req.Cookies != "" {
+		req.Cookies = filtered
+	}
+	for name := range req.Headers {
+		if s.sensitive(name) {
+			req.Headers[name] = filtered
+		}
+	}
+	if !s.sendPII {
+		delete(req.Headers, "X-Forwarded-For")
+		delete(req.Headers, "X-Real-Ip")
+		req.Env = nil
+	}
+
+	if req.QueryString != "" {
+		query, err := url.ParseQuery(req.QueryString)
+		if err != nil {
+			req.QueryString = filtered
+			return
+		}
+		for name := range query {
+			if s.sensitive(name) {
+				query[name] = []string{filtered}
+			}
+		}
+		req.QueryString = query.Encode()
+	}
+}
+
+// sensitive 項目名が機密情報を含むか（部分一致）
+func (s *Scrubber) sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range s.fields {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+// Package events Todoのドメインイベント（作成・更新・削除）をCloudEvents形式でメッセージブローカーへ発行する
+// ブローカーへの発行とは別に、AddListenerで登録した処理（Webhookの配信等）にも同じイベントを渡す
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"myapp/db/model"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Type イベントの種類（CloudEventsのtype属性）
+type Type string
+
+const (
+	TodoCreated Type = "todo.created"
+	TodoUpdated Type = "todo.updated"
+	TodoDeleted Type = "todo.deleted"
+)
+
+// 発行の定数
+const (
+	// specVersion CloudEventsの仕様バージョン
+	specVersion = "1.0"
+	// ContentType 構造化モードのCloudEventsのContent-Type
+	ContentType = "application/cloudevents+json"
+	// maxAttempts 1件の発行を試行する回数
+	maxAttempts = 3
+	// publishTimeout 1回の発行のタイムアウト
+	publishTimeout = 10 * time.Second
+)
+
+// CloudEvent CloudEvents 1.0の構造化モードのイベント
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            Type      `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// Broker イベントの発行先（NATS・Kafka）
+type Broker interface {
+	// Name ログ等に使うブローカー名
+	Name() string
+	// Publish エンコード済みのイベントを発行する（ブローカーが受け付けるまでブロック）
+	Publish(ctx context.Context, event *CloudEvent, body []byte) error
+	Close() error
+}
+
+// Listener 発行したイベントを受け取る処理（発行元のリクエストの中で同期的に呼び出すため、時間のかかる処理はジョブキュー等に積む）
+type Listener func(ctx context.Context, event *CloudEvent)
+
+// Publisher イベントをキューに溜め、1つのワーカーで発行順を保ったままブローカーへ送る
+type Publisher struct {
+	broker Broker
+	queue  chan *CloudEvent
+	// pending キューに入れてから発行を終えるまでのイベント数（シャットダウン時に完了を待つ）
+	pending sync.WaitGroup
+}
+
+// NewPublisher 新しいPublisherを作成（bufferSizeはキューの長さ。source属性はSetSourceで設定する）
+func NewPublisher(broker Broker, bufferSize int) *Publisher {
+	return &Publisher{
+		broker: broker,
+		queue:  make(chan *CloudEvent, bufferSize),
+	}
+}
+
+// Run キューのイベントを発行する（ctxがキャンセルされるまでブロック。キャンセル後は残りのイベントの発行をWaitで待つ）
+func (p *Publisher) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-p.queue:
+			p.publish(context.WithoutCancel(ctx), event)
+		case <-ctx.Done():
+			go p.drain(context.WithoutCancel(ctx))
+			return
+		}
+	}
+}
+
+// drain シャットダウン時にキューに残ったイベントを発行する
+func (p *Publisher) drain(ctx context.Context) {
+	for {
+		select {
+		case event := <-p.queue:
+			p.publish(ctx, event)
+		default:
+			return
+		}
+	}
+}
+
+// Wait キューに入れたイベントの発行が終わるまで待つ（シャットダウン用）
+func (p *Publisher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return p.broker.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue イベントをキューに入れる（キューが一杯の場合は破棄して警告する）
+func (p *Publisher) enqueue(ctx context.Context, event *CloudEvent) {
+	p.pending.Add(1)
+	select {
+	case p.queue <- event:
+	default:
+		p.pending.Done()
+		slog.WarnContext(ctx, "イベントのキューが一杯のため破棄しました", "type", event.Type, "subject", event.Subject)
+	}
+}
+
+// publish 1件のイベントを再試行付きで発行（失敗し続けた場合はログに記録して破棄する）
+func (p *Publisher) publish(ctx context.Context, event *CloudEvent) {
+	defer p.pending.Done()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "イベントのエンコードに失敗しました", "type", event.Type, "subject", event.Subject, "error", err)
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err = p.broker.Publish(publishCtx, event, body)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= maxAttempts {
+			slog.ErrorContext(ctx, "イベントの発行に失敗しました", "broker", p.broker.Name(), "type", event.Type, "subject", event.Subject, "id", event.ID, "error", err)
+			return
+		}
+		slog.WarnContext(ctx, "イベントの発行に失敗したため再試行します", "broker", p.broker.Name(), "type", event.Type, "attempt", attempt, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// publisher 現在の発行先（未設定の場合はブローカーへ発行しない）
+var publisher atomic.Pointer[Publisher]
+
+// SetPublisher 発行に使うPublisherを設定
+func SetPublisher(p *Publisher) {
+	publisher.Store(p)
+}
+
+// source CloudEventsのsource属性
+var source atomic.Pointer[string]
+
+// SetSource CloudEventsのsource属性を設定
+func SetSource(s string) {
+	source.Store(&s)
+}
+
+// listeners 発行したイベントを受け取る処理
+var (
+	listenersMu sync.RWMutex
+	listeners   []Listener
+)
+
+// AddListener 発行したイベントを受け取る処理を追加（ブローカーの設定の有無に関わらず呼び出す）
+func AddListener(l Listener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, l)
+}
+
+// PublishTodo Todoの作成・更新イベントを非同期に発行する（dataは作成・更新後のTodo）
+func PublishTodo(ctx context.Context, typ Type, todo *model.Todo) {
+	publish(ctx, typ, strconv.FormatUint(uint64(todo.ID), 10), todo.ToResponse())
+}
+
+// PublishTodoDeleted Todoの削除イベントを非同期に発行する（dataは削除したTodoのIDのみ）
+func PublishTodoDeleted(ctx context.Context, id uint) {
+	publish(ctx, TodoDeleted, strconv.FormatUint(uint64(id), 10), map[string]uint{"id": id})
+}
+
+// publish イベントを作成し、Publisherのキューに入れてリスナーに渡す（どちらもない場合は何もしない）
+func publish(ctx context.Context, typ Type, subject string, data any) {
+	p := publisher.Load()
+	listenersMu.RLock()
+	ls := listeners
+	listenersMu.RUnlock()
+	if p == nil && len(ls) == 0 {
+		return
+	}
+
+	event := &CloudEvent{
+		SpecVersion:     specVersion,
+		ID:              newID(),
+		Type:            typ,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if s := source.Load(); s != nil {
+		event.Source = *s
+	}
+
+	if p != nil {
+		p.enqueue(ctx, event)
+	}
+	for _, l := range ls {
+		l(ctx, event)
+	}
+}
+
+// newID イベントID（ランダムな128ビットの16進数）
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// kafkaBatchTimeout 1件ずつ同期的に書き込むため、バッチが溜まるのを待たずに送る
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// KafkaBroker Kafkaのブローカーへ直接イベントを書き込むブローカー（segmentio/kafka-go を使用）
+// キーにTodoのIDを使うため、同じTodoのイベントは同じパーティションに順序どおり書き込まれる
+type KafkaBroker struct {
+	writer *kafka.Writer
+}
+
+// KafkaOptions Kafkaへの接続の設定
+type KafkaOptions struct {
+	// Brokers ブートストラップサーバー（host:port）
+	Brokers []string
+	Topic   string
+	// TLS TLSで接続するか
+	TLS bool
+	// SASLMechanism SASL認証の方式（plain / scram-sha-256 / scram-sha-512。Usernameが空の場合は認証しない）
+	SASLMechanism string
+	Username      string
+	Password      string
+}
+
+// NewKafkaBroker 新しいKafkaブローカーを作成
+func NewKafkaBroker(opts KafkaOptions) (*KafkaBroker, error) {
+	transport := &kafka.Transport{ClientID: "todo-api"}
+	if opts.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if opts.Username != "" {
+		mechanism, err := saslMechanism(opts.SASLMechanism, opts.Username, opts.Password)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+
+	return &KafkaBroker{writer: &kafka.Writer{
+		Addr:         kafka.TCP(opts.Brokers...),
+		Topic:        opts.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: kafkaBatchTimeout,
+		WriteTimeout: publishTimeout,
+		Transport:    transport,
+	}}, nil
+}
+
+// saslMechanism SASL認証の方式を作成
+func saslMechanism(name, username, password string) (sasl.Mechanism, error) {
+	switch name {
+	case "", "plain":
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, username, password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, fmt.Errorf("KafkaのSASL認証の方式 %q には対応していません", name)
+	}
+}
+
+// Name ブローカー名
+func (b *KafkaBroker) Name() string {
+	return "kafka"
+}
+
+// Publish イベントを1件のレコードとしてトピックに書き込む（全ての同期レプリカが受け付けるまでブロック）
+func (b *KafkaBroker) Publish(ctx context.Context, event *CloudEvent, body []byte) error {
+	return b.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.Subject),
+		Value:   body,
+		Headers: []kafka.Header{{Key: "content-type", Value: []byte(ContentType)}},
+	})
+}
+
+// Close 書き込み待ちのレコードを送ってから接続を閉じる
+func (b *KafkaBroker) Close() error {
+	return b.writer.Close()
+}
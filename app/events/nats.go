@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsDialTimeout NATSサーバーへの接続のタイムアウト
+const natsDialTimeout = 5 * time.Second
+
+// NATSBroker NATS（Core NATS）へイベントを発行するブローカー（公式クライアント nats.go を使用）
+// サブジェクトは <prefix>.<イベントの種類>（例: todos.todo.created）
+type NATSBroker struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSBroker 新しいNATSブローカーを作成
+// rawURLは nats://[user:pass@]host:4222 または tls://host:4222（ユーザー名のみの場合はトークン認証）
+// 接続が切れた場合はクライアントが自動で再接続し、再接続までの発行はクライアントのバッファに溜める
+func NewNATSBroker(rawURL, prefix string) (*NATSBroker, error) {
+	conn, err := nats.Connect(rawURL,
+		nats.Name("todo-api"),
+		nats.Timeout(natsDialTimeout),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				slog.Warn("NATSサーバーとの接続が切れました", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			slog.Info("NATSサーバーに再接続しました", "server", c.ConnectedUrlRedacted())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("NATSサーバーへの接続に失敗しました: %w", err)
+	}
+	return &NATSBroker{conn: conn, prefix: prefix}, nil
+}
+
+// Name ブローカー名
+func (b *NATSBroker) Name() string {
+	return "nats"
+}
+
+// Publish イベントをPUBし、Flush（PINGへのPONG）でサーバーが受け付けたことを確認する
+func (b *NATSBroker) Publish(ctx context.Context, event *CloudEvent, body []byte) error {
+	if err := b.conn.Publish(b.prefix+"."+string(event.Type), body); err != nil {
+		return err
+	}
+	if err := b.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("NATSサーバーからの応答がありません: %w", err)
+	}
+	return nil
+}
+
+// Close 送信待ちのメッセージを送ってから接続を閉じる
+func (b *NATSBroker) Close() error {
+	err := b.conn.FlushTimeout(natsDialTimeout)
+	b.conn.Close()
+	return err
+}
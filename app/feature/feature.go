@@ -0,0 +1,153 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"myapp/i18n"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ErrUnknownFlag 未登録のフィーチャーフラグを指定したことを示すエラー
+var ErrUnknownFlag = errors.New("未登録のフィーチャーフラグです")
+
+// フラグの値の設定元
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceDB      = "db"
+)
+
+// フィーチャーフラグ名
+const (
+	FlagAdminDBStats = "admin_db_stats"
+	FlagHealthDetail = "health_detail"
+	FlagMaintenance  = "maintenance_mode"
+)
+
+// Flag フィーチャーフラグ
+type Flag struct {
+	Name        string `json:"name" doc:"フラグ名"`
+	Description string `json:"description" doc:"フラグの説明"`
+	Enabled     bool   `json:"enabled" doc:"有効かどうか"`
+	Source      string `json:"source" doc:"値の設定元" enum:"default,env,db"`
+}
+
+// 登録済みフラグ
+var (
+	flags   = make(map[string]*Flag)
+	flagsMu sync.RWMutex
+)
+
+// Register フラグを登録（FEATURE_<NAME>環境変数があればデフォルト値を上書き）
+func Register(name, description string, defaultEnabled bool) {
+	flag := &Flag{
+		Name:        name,
+		Description: description,
+		Enabled:     defaultEnabled,
+		Source:      SourceDefault,
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv(envKey(name))); err == nil {
+		flag.Enabled = value
+		flag.Source = SourceEnv
+	}
+
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+
+	flags[name] = flag
+}
+
+// envKey フラグ名に対応する環境変数名（例: admin_db_stats → FEATURE_ADMIN_DB_STATS）
+func envKey(name string) string {
+	return "FEATURE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Enabled フラグが有効か判定（未登録のフラグは無効）
+func Enabled(name string) bool {
+	flagsMu.RLock()
+	defer flagsMu.RUnlock()
+
+	flag, ok := flags[name]
+	return ok && flag.Enabled
+}
+
+// Set フラグの値を変更
+func Set(name string, enabled bool, source string) error {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+
+	flag, ok := flags[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+
+	flag.Enabled = enabled
+	flag.Source = source
+	return nil
+}
+
+// Get フラグを取得
+func Get(name string) (Flag, bool) {
+	flagsMu.RLock()
+	defer flagsMu.RUnlock()
+
+	flag, ok := flags[name]
+	if !ok {
+		return Flag{}, false
+	}
+	return *flag, true
+}
+
+// List 登録済みの全フラグを名前順で取得
+func List() []Flag {
+	flagsMu.RLock()
+	defer flagsMu.RUnlock()
+
+	list := make([]Flag, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, *flag)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// disabledMessage 無効な機能へのアクセス時のメッセージ
+// 機能の存在自体を隠すため、無効時は一律で404を返す
+const disabledMessage = "リソースが見つかりません"
+
+// Guard フラグが無効な場合に404を返すようHumaハンドラーをラップ
+func Guard[I, O any](name string, handler func(context.Context, *I) (*O, error)) func(context.Context, *I) (*O, error) {
+	return func(ctx context.Context, input *I) (*O, error) {
+		if !Enabled(name) {
+			return nil, huma.Error404NotFound(disabledMessage)
+		}
+		return handler(ctx, input)
+	}
+}
+
+// Middleware フラグが無効な場合に404を返すHTTPミドルウェア（Huma以外のルート用）
+func Middleware(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Enabled(name) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(huma.Error404NotFound(i18n.T(r.Context(), disabledMessage)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
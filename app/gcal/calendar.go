@@ -0,0 +1,157 @@
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// apiBaseURL Google Calendar API v3のベースURL
+const apiBaseURL = "https://www.googleapis.com/calendar/v3"
+
+// APIエラー
+var (
+	// ErrNotFound 予定が存在しない（削除済みを含む）
+	ErrNotFound = errors.New("予定が見つかりません")
+	// ErrSyncTokenExpired 同期トークンが失効した（全件の同期からやり直す）
+	ErrSyncTokenExpired = errors.New("同期トークンが失効しました")
+)
+
+// Event カレンダーの予定（同期に使う項目のみ）
+type Event struct {
+	ID                 string              `json:"id,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	Summary            string              `json:"summary,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	Start              *EventTime          `json:"start,omitempty"`
+	End                *EventTime          `json:"end,omitempty"`
+	ExtendedProperties *ExtendedProperties `json:"extendedProperties,omitempty"`
+}
+
+// EventTime 予定の日時（終日の予定はDate、それ以外はDateTime）
+type EventTime struct {
+	DateTime *time.Time `json:"dateTime,omitempty"`
+	Date     string     `json:"date,omitempty"`
+}
+
+// ExtendedProperties 予定に付与する独自のプロパティ
+type ExtendedProperties struct {
+	Private map[string]string `json:"private,omitempty"`
+}
+
+// Cancelled 予定が削除されたか
+func (e *Event) Cancelled() bool {
+	return e.Status == "cancelled"
+}
+
+// EventList 予定一覧の1ページ
+type EventList struct {
+	Items         []*Event `json:"items"`
+	NextPageToken string   `json:"nextPageToken"`
+	NextSyncToken string   `json:"nextSyncToken"`
+}
+
+// Client Google Calendar APIのクライアント
+type Client struct {
+	accessToken func(ctx context.Context) (string, error)
+	client      *http.Client
+}
+
+// NewClient 新しいクライアントを作成（accessTokenは呼び出しごとに有効なアクセストークンを返す）
+func NewClient(accessToken func(ctx context.Context) (string, error)) *Client {
+	return &Client{
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Insert 予定を作成
+func (c *Client) Insert(ctx context.Context, calendarID string, event *Event) (*Event, error) {
+	var created Event
+	err := c.do(ctx, http.MethodPost, "/calendars/"+url.PathEscape(calendarID)+"/events", nil, event, &created)
+	return &created, err
+}
+
+// Patch 予定を部分更新
+func (c *Client) Patch(ctx context.Context, calendarID, eventID string, event *Event) (*Event, error) {
+	var updated Event
+	err := c.do(ctx, http.MethodPatch, "/calendars/"+url.PathEscape(calendarID)+"/events/"+url.PathEscape(eventID), nil, event, &updated)
+	return &updated, err
+}
+
+// Delete 予定を削除
+func (c *Client) Delete(ctx context.Context, calendarID, eventID string) error {
+	return c.do(ctx, http.MethodDelete, "/calendars/"+url.PathEscape(calendarID)+"/events/"+url.PathEscape(eventID), nil, nil, nil)
+}
+
+// List 予定一覧を取得（syncTokenを指定すると前回以降に変更・削除された予定のみ）
+func (c *Client) List(ctx context.Context, calendarID, syncToken, pageToken string) (*EventList, error) {
+	q := url.Values{"maxResults": {"250"}}
+	if syncToken != "" {
+		q.Set("syncToken", syncToken)
+	}
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+
+	var list EventList
+	err := c.do(ctx, http.MethodGet, "/calendars/"+url.PathEscape(calendarID)+"/events", q, nil, &list)
+	return &list, err
+}
+
+// do APIを呼び出し、レスポンスをoutにデコードする
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u := apiBaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusGone && method == http.MethodGet:
+		return ErrSyncTokenExpired
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Google Calendar APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
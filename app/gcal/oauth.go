@@ -0,0 +1,23 @@
+package gcal
+
+import (
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Scope 予定の読み書きに必要なスコープ
+const Scope = "https://www.googleapis.com/auth/calendar.events"
+
+// AuthCodeOptions 同意画面のURLに付けるパラメーター（リフレッシュトークンを受け取るため offline・consent を指定）
+var AuthCodeOptions = []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.ApprovalForce}
+
+// NewOAuthConfig GoogleのOAuth 2.0（認可コードフロー）の設定を作成
+func NewOAuthConfig(clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint:     google.Endpoint,
+		Scopes:       []string{Scope},
+	}
+}
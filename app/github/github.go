@@ -0,0 +1,183 @@
+// Package github GitHub REST APIのIssue操作とWebhookの署名検証
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL GitHub.comのREST APIのベースURL
+const DefaultAPIURL = "https://api.github.com"
+
+// Webhookのヘッダー
+const (
+	SignatureHeader = "X-Hub-Signature-256"
+	EventHeader     = "X-GitHub-Event"
+)
+
+// ErrNotFound Issueが存在しない（削除・移動済みを含む）
+var ErrNotFound = errors.New("Issueが見つかりません")
+
+// Issue Issue（同期に使う項目のみ）
+type Issue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Labels    []Label   `json:"labels"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// PullRequest プルリクエストの場合のみ設定される（Issue一覧にはプルリクエストも含まれる）
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// Label Issueのラベル
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Closed Issueがクローズされているか
+func (i *Issue) Closed() bool {
+	return i.State == "closed"
+}
+
+// LabelNames ラベル名の一覧
+func (i *Issue) LabelNames() []string {
+	names := make([]string, 0, len(i.Labels))
+	for _, label := range i.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// IssuesEvent issuesイベントのWebhookペイロード
+type IssuesEvent struct {
+	Action     string `json:"action"`
+	Issue      *Issue `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Client GitHub REST APIのクライアント
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient 新しいクライアントを作成（baseURLが空の場合はGitHub.com）
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListIssues sinceより後に更新されたIssueを更新日時の昇順で取得（プルリクエストは除く。nextは次のページがあるか）
+func (c *Client) ListIssues(ctx context.Context, repository string, since time.Time, page int) (issues []*Issue, next bool, err error) {
+	q := url.Values{
+		"state":     {"all"},
+		"sort":      {"updated"},
+		"direction": {"asc"},
+		"per_page":  {"100"},
+		"page":      {strconv.Itoa(page)},
+	}
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	var all []*Issue
+	if err := c.do(ctx, http.MethodGet, "/repos/"+repository+"/issues", q, nil, &all); err != nil {
+		return nil, false, err
+	}
+	for _, issue := range all {
+		if issue.PullRequest == nil {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, len(all) == 100, nil
+}
+
+// SetState Issueをオープン・クローズする（stateは open / closed）
+func (c *Client) SetState(ctx context.Context, repository string, number int, state string) (*Issue, error) {
+	var issue Issue
+	err := c.do(ctx, http.MethodPatch, "/repos/"+repository+"/issues/"+strconv.Itoa(number), nil, map[string]string{"state": state}, &issue)
+	return &issue, err
+}
+
+// do APIを呼び出し、レスポンスをoutにデコードする
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitHub APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// VerifySignature WebhookのX-Hub-Signature-256（sha256=<HMAC-SHA256>）を検証
+func VerifySignature(secret string, body []byte, signature string) bool {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
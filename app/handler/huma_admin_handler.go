@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// DBStatsResponse DB統計取得のレスポンス
+type DBStatsResponse struct {
+	Body struct {
+		Data    *model.DBStats `json:"data" doc:"データベース統計情報"`
+		Message string         `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MigrationsRequest マイグレーション一覧取得リクエスト
+type MigrationsRequest struct {
+	DryRun bool `query:"dry_run" doc:"trueの場合、未適用マイグレーションで実行されるSQLを適用せずに返す"`
+}
+
+// MigrationsResponse マイグレーション一覧取得のレスポンス
+type MigrationsResponse struct {
+	Body struct {
+		Data    *model.MigrationReport `json:"data" doc:"マイグレーションの適用状況"`
+		Message string                 `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaAdminHandler Huma用の運用管理ハンドラー
+type HumaAdminHandler struct {
+	adminService service.AdminService
+}
+
+// NewHumaAdminHandler 新しいHuma運用管理ハンドラーインスタンスを作成
+func NewHumaAdminHandler(adminService service.AdminService) *HumaAdminHandler {
+	return &HumaAdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetDBStats データベース統計を取得
+func (h *HumaAdminHandler) GetDBStats(ctx context.Context, input *struct{}) (*DBStatsResponse, error) {
+	stats, err := h.adminService.GetDBStats(ctx)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &DBStatsResponse{
+		Body: struct {
+			Data    *model.DBStats `json:"data" doc:"データベース統計情報"`
+			Message string         `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    stats,
+			Message: "データベース統計を取得しました",
+		},
+	}, nil
+}
+
+// GetMigrations マイグレーションの適用状況を取得
+func (h *HumaAdminHandler) GetMigrations(ctx context.Context, input *MigrationsRequest) (*MigrationsResponse, error) {
+	report, err := h.adminService.GetMigrations(ctx, input.DryRun)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &MigrationsResponse{
+		Body: struct {
+			Data    *model.MigrationReport `json:"data" doc:"マイグレーションの適用状況"`
+			Message string                 `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    report,
+			Message: "マイグレーション一覧を取得しました",
+		},
+	}, nil
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// BulkRequest Todoの一括操作リクエスト
+type BulkRequest struct {
+	Body model.BulkRequest
+}
+
+// BulkCreateRequest Todoの一括作成リクエスト
+type BulkCreateRequest struct {
+	Body struct {
+		Todos []*model.TodoCreateRequest `json:"todos" minItems:"1" doc:"作成するTodo（POST /api/v1/todos と同じ内容）"`
+	}
+}
+
+// BulkCreateResponse Todoの一括作成のレスポンス
+type BulkCreateResponse struct {
+	Body struct {
+		Data     []*model.TodoResponse `json:"data" doc:"作成したTodo（リクエストと同じ順）"`
+		Message  string                `json:"message" doc:"レスポンスメッセージ"`
+		Count    int                   `json:"count" doc:"作成した件数"`
+		Warnings []service.Warning     `json:"warnings,omitempty" doc:"作成はできたが確認が必要な内容（fieldは todos[0].due_date 等の位置）"`
+	}
+}
+
+// BulkIDRequest 一括操作のID指定リクエスト
+type BulkIDRequest struct {
+	ID int `path:"id" doc:"一括操作のID" minimum:"1"`
+}
+
+// BulkJobResponse 一括操作の進捗レスポンス
+type BulkJobResponse struct {
+	Body struct {
+		Data    *model.BulkJob `json:"data" doc:"一括操作の進捗"`
+		Message string         `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaBulkHandler Huma用の一括操作ハンドラー
+type HumaBulkHandler struct {
+	bulkService service.BulkService
+}
+
+// NewHumaBulkHandler 新しいHuma一括操作ハンドラーインスタンスを作成
+func NewHumaBulkHandler(bulkService service.BulkService) *HumaBulkHandler {
+	return &HumaBulkHandler{
+		bulkService: bulkService,
+	}
+}
+
+// StartBulk Todoの一括更新・削除を開始
+func (h *HumaBulkHandler) StartBulk(ctx context.Context, input *BulkRequest) (*BulkJobResponse, error) {
+	job, err := h.bulkService.Start(ctx, &input.Body)
+	if err != nil {
+		return nil, bulkError(err)
+	}
+
+	return &BulkJobResponse{
+		Body: struct {
+			Data    *model.BulkJob `json:"data" doc:"一括操作の進捗"`
+			Message string         `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: "一括操作を開始しました",
+		},
+	}, nil
+}
+
+// CreateBulk Todoを一括で作成
+func (h *HumaBulkHandler) CreateBulk(ctx context.Context, input *BulkCreateRequest) (*BulkCreateResponse, error) {
+	ctx, warnings := service.WithWarnings(ctx)
+	todos, err := h.bulkService.Create(ctx, input.Body.Todos)
+	if err != nil {
+		return nil, bulkError(err)
+	}
+
+	responses := make([]*model.TodoResponse, len(todos))
+	for i, todo := range todos {
+		responses[i] = toTodoResponse(todo)
+	}
+	return &BulkCreateResponse{
+		Body: struct {
+			Data     []*model.TodoResponse `json:"data" doc:"作成したTodo（リクエストと同じ順）"`
+			Message  string                `json:"message" doc:"レスポンスメッセージ"`
+			Count    int                   `json:"count" doc:"作成した件数"`
+			Warnings []service.Warning     `json:"warnings,omitempty" doc:"作成はできたが確認が必要な内容（fieldは todos[0].due_date 等の位置）"`
+		}{
+			Data:     responses,
+			Message:  fmt.Sprintf("%d件のTodoを作成しました", len(todos)),
+			Count:    len(todos),
+			Warnings: translateWarnings(ctx, warnings),
+		},
+	}, nil
+}
+
+// GetBulk 一括操作の進捗を取得
+func (h *HumaBulkHandler) GetBulk(ctx context.Context, input *BulkIDRequest) (*BulkJobResponse, error) {
+	job, err := h.bulkService.GetBulk(ctx, uint(input.ID))
+	if err != nil {
+		return nil, bulkError(err)
+	}
+
+	return &BulkJobResponse{
+		Body: struct {
+			Data    *model.BulkJob `json:"data" doc:"一括操作の進捗"`
+			Message string         `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: "一括操作の進捗を取得しました",
+		},
+	}, nil
+}
+
+// bulkError 一括操作サービスのエラーをHTTPエラーに変換
+func bulkError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrBulkNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrBulkTooManyItems), errors.Is(err, service.ErrBulkNoUpdate), errors.Is(err, service.ErrBulkInvalidItem):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// CalendarStatusResponse カレンダー連携の状態レスポンス
+type CalendarStatusResponse struct {
+	Body struct {
+		Data    *model.CalendarStatus `json:"data" doc:"連携の状態"`
+		Message string                `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// CalendarAuthorizeResponse 同意画面のURLレスポンス
+type CalendarAuthorizeResponse struct {
+	Body struct {
+		URL     string `json:"url" doc:"ブラウザで開くGoogleの同意画面のURL（10分間有効）"`
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// CalendarCallbackRequest 同意画面からのリダイレクト
+type CalendarCallbackRequest struct {
+	Code  string `query:"code" doc:"認可コード"`
+	State string `query:"state" doc:"同意画面のURLを発行した際のstate"`
+	Error string `query:"error" doc:"同意が拒否された場合のエラー"`
+}
+
+// CalendarMessageResponse メッセージのみのレスポンス
+type CalendarMessageResponse struct {
+	Body struct {
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// CalendarSyncResponse 同期結果レスポンス
+type CalendarSyncResponse struct {
+	Body struct {
+		Data    *model.CalendarSyncResult `json:"data" doc:"同期の結果"`
+		Message string                    `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaCalendarHandler Huma用のGoogleカレンダー連携ハンドラー
+type HumaCalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+// NewHumaCalendarHandler 新しいHumaカレンダー連携ハンドラーインスタンスを作成
+func NewHumaCalendarHandler(calendarService service.CalendarService) *HumaCalendarHandler {
+	return &HumaCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetStatus 連携の状態を取得
+func (h *HumaCalendarHandler) GetStatus(ctx context.Context, input *struct{}) (*CalendarStatusResponse, error) {
+	status, err := h.calendarService.Status(ctx)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+
+	return &CalendarStatusResponse{
+		Body: struct {
+			Data    *model.CalendarStatus `json:"data" doc:"連携の状態"`
+			Message string                `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    status,
+			Message: "Googleカレンダー連携の状態を取得しました",
+		},
+	}, nil
+}
+
+// Authorize 同意画面のURLを発行
+func (h *HumaCalendarHandler) Authorize(ctx context.Context, input *struct{}) (*CalendarAuthorizeResponse, error) {
+	url, err := h.calendarService.AuthURL(ctx)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &CalendarAuthorizeResponse{
+		Body: struct {
+			URL     string `json:"url" doc:"ブラウザで開くGoogleの同意画面のURL（10分間有効）"`
+			Message string `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			URL:     url,
+			Message: "URLをブラウザで開き、カレンダーへのアクセスを許可してください",
+		},
+	}, nil
+}
+
+// Callback 同意画面から戻った認可コードでトークンを取得
+func (h *HumaCalendarHandler) Callback(ctx context.Context, input *CalendarCallbackRequest) (*CalendarMessageResponse, error) {
+	if input.Error != "" {
+		return nil, huma.Error400BadRequest("カレンダーへのアクセスが許可されませんでした: " + input.Error)
+	}
+	if input.Code == "" {
+		return nil, huma.Error400BadRequest("認可コードがありません")
+	}
+
+	if err := h.calendarService.HandleCallback(ctx, input.Code, input.State); err != nil {
+		if errors.Is(err, service.ErrInvalidOAuthState) {
+			return nil, newError(http.StatusBadRequest, err)
+		}
+		return nil, calendarError(err)
+	}
+
+	resp := &CalendarMessageResponse{}
+	resp.Body.Message = "Googleカレンダーと連携しました"
+	return resp, nil
+}
+
+// Sync 今すぐ同期
+func (h *HumaCalendarHandler) Sync(ctx context.Context, input *struct{}) (*CalendarSyncResponse, error) {
+	result, err := h.calendarService.Sync(ctx)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+
+	return &CalendarSyncResponse{
+		Body: struct {
+			Data    *model.CalendarSyncResult `json:"data" doc:"同期の結果"`
+			Message string                    `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "Googleカレンダーと同期しました",
+		},
+	}, nil
+}
+
+// Disconnect 連携を解除
+func (h *HumaCalendarHandler) Disconnect(ctx context.Context, input *struct{}) (*CalendarMessageResponse, error) {
+	if err := h.calendarService.Disconnect(ctx); err != nil {
+		return nil, calendarError(err)
+	}
+
+	resp := &CalendarMessageResponse{}
+	resp.Body.Message = "Googleカレンダーとの連携を解除しました"
+	return resp, nil
+}
+
+// calendarError サービスのエラーをHTTPステータスに対応付ける
+func calendarError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrCalendarNotConnected):
+		return newError(http.StatusConflict, err)
+	case errors.Is(err, service.ErrCalendarSyncRunning):
+		return newError(http.StatusConflict, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"myapp/config"
+	"myapp/csrf"
+)
+
+// CSRFToken CSRFトークンの情報
+type CSRFToken struct {
+	Enabled    bool   `json:"enabled" doc:"CSRF保護が有効か"`
+	Token      string `json:"token" doc:"安全でないメソッドのリクエスト時にヘッダーへ設定するトークン"`
+	HeaderName string `json:"header_name" doc:"トークンを設定するヘッダー名"`
+}
+
+// CSRFTokenResponse CSRFトークン取得のレスポンス
+type CSRFTokenResponse struct {
+	Body struct {
+		Data    CSRFToken `json:"data" doc:"CSRFトークンの情報"`
+		Message string    `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// GetCSRFToken CSRFトークンを取得（トークンはミドルウェアがCookieにも設定する）
+func GetCSRFToken(ctx context.Context, input *struct{}) (*CSRFTokenResponse, error) {
+	cfg := config.Current().CSRF
+
+	return &CSRFTokenResponse{
+		Body: struct {
+			Data    CSRFToken `json:"data" doc:"CSRFトークンの情報"`
+			Message string    `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data: CSRFToken{
+				Enabled:    cfg.Enabled,
+				Token:      csrf.Token(ctx),
+				HeaderName: cfg.HeaderName,
+			},
+			Message: "CSRFトークンを取得しました",
+		},
+	}, nil
+}
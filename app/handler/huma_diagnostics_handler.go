@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"myapp/diagnostics"
+)
+
+// DiagnosticsResponse セルフ診断のレスポンス
+type DiagnosticsResponse struct {
+	Body struct {
+		Data    *diagnostics.Report `json:"data" doc:"診断結果"`
+		Message string              `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaDiagnosticsHandler Huma用のセルフ診断ハンドラー
+type HumaDiagnosticsHandler struct {
+	diagnostics *diagnostics.Diagnostics
+}
+
+// NewHumaDiagnosticsHandler 新しいHumaセルフ診断ハンドラーインスタンスを作成
+func NewHumaDiagnosticsHandler(d *diagnostics.Diagnostics) *HumaDiagnosticsHandler {
+	return &HumaDiagnosticsHandler{
+		diagnostics: d,
+	}
+}
+
+// GetDiagnostics 設定・依存接続・リソース状況を診断
+func (h *HumaDiagnosticsHandler) GetDiagnostics(ctx context.Context, input *struct{}) (*DiagnosticsResponse, error) {
+	report := h.diagnostics.Run(ctx)
+
+	message := "問題は検出されませんでした"
+	if len(report.Issues) > 0 {
+		message = "問題が検出されました"
+	}
+
+	return &DiagnosticsResponse{
+		Body: struct {
+			Data    *diagnostics.Report `json:"data" doc:"診断結果"`
+			Message string              `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    report,
+			Message: message,
+		},
+	}, nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// DigestSubscriptionIDRequest ID指定リクエスト
+type DigestSubscriptionIDRequest struct {
+	ID int `path:"id" minimum:"1" doc:"配信設定のID"`
+}
+
+// DigestSubscriptionCreateRequest 配信設定の登録リクエスト
+type DigestSubscriptionCreateRequest struct {
+	Body model.DigestSubscriptionRequest
+}
+
+// DigestSubscriptionUpdateRequest 配信設定の更新リクエスト
+type DigestSubscriptionUpdateRequest struct {
+	ID   int `path:"id" minimum:"1" doc:"配信設定のID"`
+	Body model.DigestSubscriptionRequest
+}
+
+// DigestSubscriptionResponse 配信設定のレスポンス
+type DigestSubscriptionResponse struct {
+	Body struct {
+		Data    *model.DigestSubscription `json:"data" doc:"配信設定"`
+		Message string                    `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// DigestSubscriptionListResponse 配信設定の一覧レスポンス
+type DigestSubscriptionListResponse struct {
+	Body struct {
+		Data    []*model.DigestSubscription `json:"data" doc:"配信設定（登録順）"`
+		Count   int                         `json:"count" doc:"件数"`
+		Message string                      `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaDigestHandler Huma用のダイジェストメールの配信設定ハンドラー
+type HumaDigestHandler struct {
+	digestService service.DigestService
+}
+
+// NewHumaDigestHandler 新しいHumaダイジェストハンドラーインスタンスを作成
+func NewHumaDigestHandler(digestService service.DigestService) *HumaDigestHandler {
+	return &HumaDigestHandler{
+		digestService: digestService,
+	}
+}
+
+// ListSubscriptions 配信設定の一覧を取得
+func (h *HumaDigestHandler) ListSubscriptions(ctx context.Context, input *struct{}) (*DigestSubscriptionListResponse, error) {
+	subs, err := h.digestService.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, digestError(err)
+	}
+
+	return &DigestSubscriptionListResponse{
+		Body: struct {
+			Data    []*model.DigestSubscription `json:"data" doc:"配信設定（登録順）"`
+			Count   int                         `json:"count" doc:"件数"`
+			Message string                      `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    subs,
+			Count:   len(subs),
+			Message: "ダイジェストの配信設定を取得しました",
+		},
+	}, nil
+}
+
+// GetSubscription 配信設定を取得
+func (h *HumaDigestHandler) GetSubscription(ctx context.Context, input *DigestSubscriptionIDRequest) (*DigestSubscriptionResponse, error) {
+	sub, err := h.digestService.GetSubscription(ctx, uint(input.ID))
+	if err != nil {
+		return nil, digestError(err)
+	}
+	return digestSubscriptionResponse(sub, "ダイジェストの配信設定を取得しました"), nil
+}
+
+// CreateSubscription 配信設定を登録
+func (h *HumaDigestHandler) CreateSubscription(ctx context.Context, input *DigestSubscriptionCreateRequest) (*DigestSubscriptionResponse, error) {
+	sub, err := h.digestService.CreateSubscription(ctx, &input.Body)
+	if err != nil {
+		return nil, digestError(err)
+	}
+	return digestSubscriptionResponse(sub, "ダイジェストの配信設定を登録しました"), nil
+}
+
+// UpdateSubscription 配信設定を更新
+func (h *HumaDigestHandler) UpdateSubscription(ctx context.Context, input *DigestSubscriptionUpdateRequest) (*DigestSubscriptionResponse, error) {
+	sub, err := h.digestService.UpdateSubscription(ctx, uint(input.ID), &input.Body)
+	if err != nil {
+		return nil, digestError(err)
+	}
+	return digestSubscriptionResponse(sub, "ダイジェストの配信設定を更新しました"), nil
+}
+
+// DeleteSubscription 配信設定を削除
+func (h *HumaDigestHandler) DeleteSubscription(ctx context.Context, input *DigestSubscriptionIDRequest) (*DeleteResponse, error) {
+	if err := h.digestService.DeleteSubscription(ctx, uint(input.ID)); err != nil {
+		return nil, digestError(err)
+	}
+
+	return &DeleteResponse{
+		Body: struct {
+			Message string `json:"message" doc:"削除結果のメッセージ"`
+		}{
+			Message: fmt.Sprintf("ID %d のダイジェストの配信設定を削除しました", input.ID),
+		},
+	}, nil
+}
+
+// SendNow ダイジェストをすぐに送信
+func (h *HumaDigestHandler) SendNow(ctx context.Context, input *DigestSubscriptionIDRequest) (*DigestSubscriptionResponse, error) {
+	sub, err := h.digestService.SendNow(ctx, uint(input.ID))
+	if err != nil {
+		return nil, digestError(err)
+	}
+	return digestSubscriptionResponse(sub, "ダイジェストを送信しました"), nil
+}
+
+// digestSubscriptionResponse 配信設定のレスポンスを作成
+func digestSubscriptionResponse(sub *model.DigestSubscription, message string) *DigestSubscriptionResponse {
+	return &DigestSubscriptionResponse{
+		Body: struct {
+			Data    *model.DigestSubscription `json:"data" doc:"配信設定"`
+			Message string                    `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    sub,
+			Message: message,
+		},
+	}
+}
+
+// digestError サービスのエラーをHTTPステータスに対応付ける
+func digestError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrDigestSubscriptionNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrDigestEmailExists):
+		return newError(http.StatusConflict, err)
+	case errors.Is(err, service.ErrDigestInvalidTimezone):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
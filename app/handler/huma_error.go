@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"myapp/db"
+	"myapp/errcode"
+	"myapp/i18n"
+	"myapp/requestid"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// APIError エラーコード・リクエストIDを含むエラーレスポンス（RFC 9457 Problem Details）
+type APIError struct {
+	huma.ErrorModel
+	Code      string `json:"code" doc:"機械可読なエラーコード（TODO_NOT_FOUND 等。個別のコードがないエラーはステータスに応じた NOT_FOUND・VALIDATION_FAILED 等）"`
+	RequestID string `json:"request_id,omitempty" doc:"問い合わせ時に使用するリクエストID"`
+}
+
+// NewAPIError huma.NewErrorの置き換え。全てのエラーレスポンスをAPIErrorで返す
+func NewAPIError(status int, msg string, errs ...error) huma.StatusError {
+	details := make([]*huma.ErrorDetail, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if converted, ok := err.(huma.ErrorDetailer); ok {
+			details = append(details, converted.ErrorDetail())
+		} else {
+			details = append(details, &huma.ErrorDetail{Message: err.Error()})
+		}
+	}
+
+	// 厳格モードではスキーマ外のフィールド（typo等）を400として返す
+	code := errcode.ForStatus(status)
+	if strictUnknownFields && status == http.StatusUnprocessableEntity && hasUnexpectedProperty(details) {
+		status = http.StatusBadRequest
+		msg = "リクエストにスキーマで定義されていないフィールドが含まれています"
+		code = errcode.UnknownField
+	}
+
+	return &APIError{
+		ErrorModel: huma.ErrorModel{
+			Status: status,
+			Title:  http.StatusText(status),
+			Detail: msg,
+			Errors: details,
+		},
+		Code: string(code),
+	}
+}
+
+// newError サービスのエラーからエラーレスポンスを作る
+// エラーにコード（errcode）が付いていればそれを使い、付いていない場合と5xxはステータスに応じたコードにする
+// フィールド単位のバリデーションエラーは、Humaのスキーマの検証と同じく errors にフィールドの位置（body.priority 等）・理由・値を含める
+func newError(status int, err error) huma.StatusError {
+	apiErr := NewAPIError(status, err.Error()).(*APIError)
+	if status >= http.StatusInternalServerError {
+		return apiErr
+	}
+	if code := errcode.Of(err); code != "" {
+		apiErr.Code = string(code)
+	}
+	if invalid, ok := errcode.AsValidation(err); ok {
+		for _, field := range invalid.Fields {
+			apiErr.Errors = append(apiErr.Errors, &huma.ErrorDetail{
+				Message:  field.Message,
+				Location: "body." + field.Field,
+				Value:    field.Value,
+			})
+		}
+	}
+	return apiErr
+}
+
+// isValidation フィールド単位のバリデーションエラーか判定
+func isValidation(err error) bool {
+	_, ok := errcode.AsValidation(err)
+	return ok
+}
+
+// ErrorTransformer エラーレスポンスにリクエストIDを付与し、メッセージをAccept-Languageの言語に翻訳するトランスフォーマー
+// NewAPIErrorでステータスを変更した場合（厳格モードの400等）はレスポンスのステータスにも反映する
+func ErrorTransformer(ctx huma.Context, status string, v any) (any, error) {
+	if apiErr, ok := v.(*APIError); ok {
+		apiErr.RequestID = requestid.FromContext(ctx.Context())
+		lang := i18n.FromContext(ctx.Context())
+		apiErr.Detail = i18n.TranslateError(lang, apiErr.Status, apiErr.Detail)
+		for _, detail := range apiErr.Errors {
+			detail.Message = i18n.TranslateError(lang, apiErr.Status, detail.Message)
+		}
+		if strconv.Itoa(apiErr.Status) != status {
+			ctx.SetStatus(apiErr.Status)
+		}
+	}
+	return v, nil
+}
+
+// isServiceUnavailable DB障害によりサービスが一時的に利用できないエラーか判定
+func isServiceUnavailable(err error) bool {
+	return errors.Is(err, db.ErrCircuitOpen)
+}
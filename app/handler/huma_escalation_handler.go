@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// EscalationRuleIDRequest ID指定リクエスト
+type EscalationRuleIDRequest struct {
+	ID int `path:"id" minimum:"1" doc:"エスカレーションルールのID"`
+}
+
+// EscalationRuleCreateRequest ルールの登録リクエスト
+type EscalationRuleCreateRequest struct {
+	Body model.EscalationRuleRequest
+}
+
+// EscalationRuleUpdateRequest ルールの更新リクエスト
+type EscalationRuleUpdateRequest struct {
+	ID   int `path:"id" minimum:"1" doc:"エスカレーションルールのID"`
+	Body model.EscalationRuleRequest
+}
+
+// EscalationRuleResponse ルールのレスポンス
+type EscalationRuleResponse struct {
+	Body struct {
+		Data    *model.EscalationRule `json:"data" doc:"エスカレーションルール"`
+		Message string                `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// EscalationRuleListResponse ルールの一覧レスポンス
+type EscalationRuleListResponse struct {
+	Body struct {
+		Data    []*model.EscalationRule `json:"data" doc:"エスカレーションルール（登録順）"`
+		Count   int                     `json:"count" doc:"件数"`
+		Message string                  `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// EscalationLogListRequest 適用の記録の一覧の取得リクエスト
+type EscalationLogListRequest struct {
+	RuleID int `query:"rule_id" minimum:"0" doc:"絞り込むルールのID（0の場合は全て）"`
+	TodoID int `query:"todo_id" minimum:"0" doc:"絞り込むTodoのID（0の場合は全て）"`
+	Limit  int `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// EscalationLogListResponse 適用の記録の一覧レスポンス
+type EscalationLogListResponse struct {
+	Body struct {
+		Data    []*model.EscalationLog `json:"data" doc:"適用の記録（新しい順）"`
+		Count   int                    `json:"count" doc:"件数"`
+		Message string                 `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaEscalationHandler Huma用のエスカレーションルールのハンドラー
+type HumaEscalationHandler struct {
+	escalationService service.EscalationService
+}
+
+// NewHumaEscalationHandler 新しいHumaエスカレーションハンドラーインスタンスを作成
+func NewHumaEscalationHandler(escalationService service.EscalationService) *HumaEscalationHandler {
+	return &HumaEscalationHandler{
+		escalationService: escalationService,
+	}
+}
+
+// ListRules ルールの一覧を取得
+func (h *HumaEscalationHandler) ListRules(ctx context.Context, input *struct{}) (*EscalationRuleListResponse, error) {
+	rules, err := h.escalationService.ListRules(ctx)
+	if err != nil {
+		return nil, escalationError(err)
+	}
+
+	return &EscalationRuleListResponse{
+		Body: struct {
+			Data    []*model.EscalationRule `json:"data" doc:"エスカレーションルール（登録順）"`
+			Count   int                     `json:"count" doc:"件数"`
+			Message string                  `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    rules,
+			Count:   len(rules),
+			Message: "エスカレーションルールを取得しました",
+		},
+	}, nil
+}
+
+// GetRule ルールを取得
+func (h *HumaEscalationHandler) GetRule(ctx context.Context, input *EscalationRuleIDRequest) (*EscalationRuleResponse, error) {
+	rule, err := h.escalationService.GetRule(ctx, uint(input.ID))
+	if err != nil {
+		return nil, escalationError(err)
+	}
+	return escalationRuleResponse(rule, "エスカレーションルールを取得しました"), nil
+}
+
+// CreateRule ルールを登録
+func (h *HumaEscalationHandler) CreateRule(ctx context.Context, input *EscalationRuleCreateRequest) (*EscalationRuleResponse, error) {
+	rule, err := h.escalationService.CreateRule(ctx, &input.Body)
+	if err != nil {
+		return nil, escalationError(err)
+	}
+	return escalationRuleResponse(rule, "エスカレーションルールを登録しました"), nil
+}
+
+// UpdateRule ルールを更新
+func (h *HumaEscalationHandler) UpdateRule(ctx context.Context, input *EscalationRuleUpdateRequest) (*EscalationRuleResponse, error) {
+	rule, err := h.escalationService.UpdateRule(ctx, uint(input.ID), &input.Body)
+	if err != nil {
+		return nil, escalationError(err)
+	}
+	return escalationRuleResponse(rule, "エスカレーションルールを更新しました"), nil
+}
+
+// DeleteRule ルールを削除
+func (h *HumaEscalationHandler) DeleteRule(ctx context.Context, input *EscalationRuleIDRequest) (*DeleteResponse, error) {
+	if err := h.escalationService.DeleteRule(ctx, uint(input.ID)); err != nil {
+		return nil, escalationError(err)
+	}
+
+	return &DeleteResponse{
+		Body: struct {
+			Message string `json:"message" doc:"削除結果のメッセージ"`
+		}{
+			Message: fmt.Sprintf("ID %d のエスカレーションルールを削除しました", input.ID),
+		},
+	}, nil
+}
+
+// ListLogs ルールを適用した記録を取得
+func (h *HumaEscalationHandler) ListLogs(ctx context.Context, input *EscalationLogListRequest) (*EscalationLogListResponse, error) {
+	logs, err := h.escalationService.ListLogs(ctx, uint(input.RuleID), uint(input.TodoID), input.Limit)
+	if err != nil {
+		return nil, escalationError(err)
+	}
+
+	return &EscalationLogListResponse{
+		Body: struct {
+			Data    []*model.EscalationLog `json:"data" doc:"適用の記録（新しい順）"`
+			Count   int                    `json:"count" doc:"件数"`
+			Message string                 `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    logs,
+			Count:   len(logs),
+			Message: "エスカレーションの記録を取得しました",
+		},
+	}, nil
+}
+
+// escalationRuleResponse ルールのレスポンスを作成
+func escalationRuleResponse(rule *model.EscalationRule, message string) *EscalationRuleResponse {
+	return &EscalationRuleResponse{
+		Body: struct {
+			Data    *model.EscalationRule `json:"data" doc:"エスカレーションルール"`
+			Message string                `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    rule,
+			Message: message,
+		},
+	}
+}
+
+// escalationError サービスのエラーをHTTPステータスに対応付ける
+func escalationError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrEscalationRuleNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrEscalationNoAction),
+		errors.Is(err, service.ErrEscalationUnknownChannel),
+		errors.Is(err, service.ErrEscalationInvalidRecipient),
+		errors.Is(err, service.ErrEscalationEmailDisabled):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
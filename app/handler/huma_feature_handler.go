@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/feature"
+	"myapp/service"
+	"net/http"
+)
+
+// FeatureListResponse フィーチャーフラグ一覧のレスポンス
+type FeatureListResponse struct {
+	Body struct {
+		Data    []feature.Flag `json:"data" doc:"フィーチャーフラグのリスト"`
+		Message string         `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// FeatureUpdateRequest フィーチャーフラグ更新リクエスト
+type FeatureUpdateRequest struct {
+	Name string `path:"name" doc:"フラグ名"`
+	Body struct {
+		Enabled bool `json:"enabled" doc:"有効にするかどうか"`
+	}
+}
+
+// FeatureResponse フィーチャーフラグ更新のレスポンス
+type FeatureResponse struct {
+	Body struct {
+		Data    *feature.Flag `json:"data" doc:"フィーチャーフラグ"`
+		Message string        `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaFeatureHandler Huma用のフィーチャーフラグハンドラー
+type HumaFeatureHandler struct {
+	featureService service.FeatureService
+}
+
+// NewHumaFeatureHandler 新しいHumaフィーチャーフラグハンドラーインスタンスを作成
+func NewHumaFeatureHandler(featureService service.FeatureService) *HumaFeatureHandler {
+	return &HumaFeatureHandler{
+		featureService: featureService,
+	}
+}
+
+// GetFeatures 全てのフィーチャーフラグを取得
+func (h *HumaFeatureHandler) GetFeatures(ctx context.Context, input *struct{}) (*FeatureListResponse, error) {
+	return &FeatureListResponse{
+		Body: struct {
+			Data    []feature.Flag `json:"data" doc:"フィーチャーフラグのリスト"`
+			Message string         `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    h.featureService.GetFeatures(ctx),
+			Message: "フィーチャーフラグを取得しました",
+		},
+	}, nil
+}
+
+// UpdateFeature フィーチャーフラグを切り替え
+func (h *HumaFeatureHandler) UpdateFeature(ctx context.Context, input *FeatureUpdateRequest) (*FeatureResponse, error) {
+	flag, err := h.featureService.SetFeature(ctx, input.Name, input.Body.Enabled)
+	if err != nil {
+		if errors.Is(err, feature.ErrUnknownFlag) {
+			return nil, newError(http.StatusNotFound, err)
+		}
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &FeatureResponse{
+		Body: struct {
+			Data    *feature.Flag `json:"data" doc:"フィーチャーフラグ"`
+			Message string        `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    flag,
+			Message: "フィーチャーフラグを更新しました",
+		},
+	}, nil
+}
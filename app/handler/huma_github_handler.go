@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// GitHubWebhookRequest GitHubからのWebhook（署名の検証のため生のボディを受け取る）
+type GitHubWebhookRequest struct {
+	Event     string `header:"X-GitHub-Event" doc:"イベントの種類（issues以外は無視する）"`
+	Signature string `header:"X-Hub-Signature-256" doc:"WebhookのSecretによるHMAC-SHA256署名（sha256=<hex>）"`
+	RawBody   []byte
+}
+
+// GitHubMessageResponse メッセージのみのレスポンス
+type GitHubMessageResponse struct {
+	Body struct {
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// GitHubSyncResponse 同期結果レスポンス
+type GitHubSyncResponse struct {
+	Body struct {
+		Data    *model.GitHubSyncResult `json:"data" doc:"同期の結果"`
+		Message string                  `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaGitHubHandler Huma用のGitHub Issue同期ハンドラー
+type HumaGitHubHandler struct {
+	githubService service.GitHubService
+}
+
+// NewHumaGitHubHandler 新しいHuma GitHub Issue同期ハンドラーインスタンスを作成
+func NewHumaGitHubHandler(githubService service.GitHubService) *HumaGitHubHandler {
+	return &HumaGitHubHandler{
+		githubService: githubService,
+	}
+}
+
+// Webhook Issueの変更をTodoに反映
+func (h *HumaGitHubHandler) Webhook(ctx context.Context, input *GitHubWebhookRequest) (*GitHubMessageResponse, error) {
+	if err := h.githubService.HandleWebhook(ctx, input.Event, input.Signature, input.RawBody); err != nil {
+		return nil, githubError(err)
+	}
+
+	resp := &GitHubMessageResponse{}
+	resp.Body.Message = "Webhookを受け付けました"
+	return resp, nil
+}
+
+// Sync 今すぐ同期
+func (h *HumaGitHubHandler) Sync(ctx context.Context, input *struct{}) (*GitHubSyncResponse, error) {
+	result, err := h.githubService.Sync(ctx)
+	if err != nil {
+		return nil, githubError(err)
+	}
+
+	return &GitHubSyncResponse{
+		Body: struct {
+			Data    *model.GitHubSyncResult `json:"data" doc:"同期の結果"`
+			Message string                  `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "GitHubのIssueと同期しました",
+		},
+	}, nil
+}
+
+// githubError サービスのエラーをHTTPステータスに対応付ける
+func githubError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrGitHubInvalidSignature):
+		return newError(http.StatusUnauthorized, err)
+	case errors.Is(err, service.ErrGitHubSyncRunning):
+		return newError(http.StatusConflict, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
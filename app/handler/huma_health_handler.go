@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"myapp/health"
+	"net/http"
+	"time"
+)
+
+// DetailedHealthResponse 詳細ヘルスチェックのレスポンス
+type DetailedHealthResponse struct {
+	Status int
+	Body   struct {
+		Status    string                `json:"status" doc:"全体のステータス" enum:"healthy,degraded,unhealthy"`
+		Timestamp time.Time             `json:"timestamp" doc:"チェック実行時刻"`
+		Checks    []*health.CheckResult `json:"checks" doc:"依存サービスごとのチェック結果"`
+	}
+}
+
+// ProbeResponse liveness/readinessプローブのレスポンス
+type ProbeResponse struct {
+	Status int
+	Body   struct {
+		Status  string   `json:"status" doc:"ステータス" enum:"ok,unavailable"`
+		Reasons []string `json:"reasons,omitempty" doc:"準備ができていない理由"`
+	}
+}
+
+// HumaHealthHandler Huma用のヘルスチェックハンドラー
+type HumaHealthHandler struct {
+	aggregator *health.Aggregator
+	probe      *health.Probe
+}
+
+// NewHumaHealthHandler 新しいHumaヘルスチェックハンドラーインスタンスを作成
+func NewHumaHealthHandler(aggregator *health.Aggregator, probe *health.Probe) *HumaHealthHandler {
+	return &HumaHealthHandler{
+		aggregator: aggregator,
+		probe:      probe,
+	}
+}
+
+// GetLiveness プロセスの生存のみを確認（依存サービスは見ない）
+func (h *HumaHealthHandler) GetLiveness(ctx context.Context, input *struct{}) (*ProbeResponse, error) {
+	resp := &ProbeResponse{
+		Status: http.StatusOK,
+	}
+	resp.Body.Status = "ok"
+
+	return resp, nil
+}
+
+// GetReadiness DB・マイグレーション・依存サービスを確認し、トラフィックを受け付けられるか返す
+func (h *HumaHealthHandler) GetReadiness(ctx context.Context, input *struct{}) (*ProbeResponse, error) {
+	ready, reasons := h.probe.Ready(ctx)
+
+	resp := &ProbeResponse{
+		Status: http.StatusOK,
+	}
+	resp.Body.Status = "ok"
+	if !ready {
+		resp.Status = http.StatusServiceUnavailable
+		resp.Body.Status = "unavailable"
+		resp.Body.Reasons = reasons
+	}
+
+	return resp, nil
+}
+
+// GetDetailedHealth 依存サービスごとの状態とレイテンシを取得
+func (h *HumaHealthHandler) GetDetailedHealth(ctx context.Context, input *struct{}) (*DetailedHealthResponse, error) {
+	status, checks := h.aggregator.CheckAll(ctx)
+
+	resp := &DetailedHealthResponse{
+		Status: http.StatusOK,
+	}
+	if status == health.StatusUnhealthy {
+		resp.Status = http.StatusServiceUnavailable
+	}
+	resp.Body.Status = status
+	resp.Body.Timestamp = time.Now()
+	resp.Body.Checks = checks
+
+	return resp, nil
+}
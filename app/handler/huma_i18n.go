@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"myapp/i18n"
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MessageTransformer 成功レスポンスの message をAccept-Languageの言語に翻訳するトランスフォーマー
+// レスポンスの本文はハンドラーごとの構造体のため、文字列の Message フィールドを持つものを対象にリフレクションで書き換える
+func MessageTransformer(ctx huma.Context, status string, v any) (any, error) {
+	lang := i18n.FromContext(ctx.Context())
+	if lang == i18n.Default || v == nil {
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return v, nil
+		}
+		translateMessage(rv.Elem(), lang)
+		return v, nil
+	}
+	if rv.Kind() != reflect.Struct {
+		return v, nil
+	}
+	// 値で渡された本文は書き換えられないため、コピーを書き換えて返す
+	copied := reflect.New(rv.Type()).Elem()
+	copied.Set(rv)
+	if !translateMessage(copied, lang) {
+		return v, nil
+	}
+	return copied.Interface(), nil
+}
+
+// translateMessage 構造体の Message フィールドを翻訳し、翻訳したかを返す
+func translateMessage(rv reflect.Value, lang i18n.Lang) bool {
+	field := rv.FieldByName("Message")
+	if !field.IsValid() || field.Kind() != reflect.String || !field.CanSet() {
+		return false
+	}
+	field.SetString(i18n.Translate(lang, field.String()))
+	return true
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"myapp/todoist"
+	"myapp/trello"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// TodoistImportRequest Todoistからの取り込みリクエスト
+type TodoistImportRequest struct {
+	Body struct {
+		Format  string `json:"format" enum:"json,csv" doc:"エクスポートの形式（json: APIで取得したタスク・プロジェクト、csv: プロジェクトのCSVエクスポート）"`
+		Content string `json:"content" minLength:"1" doc:"エクスポートの内容"`
+		Project string `json:"project,omitempty" maxLength:"255" doc:"CSVのタスクが属するプロジェクト名（CSVにはプロジェクトが含まれないため）"`
+	}
+}
+
+// TrelloImportRequest Trelloからの取り込みリクエスト
+type TrelloImportRequest struct {
+	Body struct {
+		Content string `json:"content" minLength:"1" doc:"ボードのJSONエクスポートの内容"`
+	}
+}
+
+// ImportIDRequest 取り込みのID指定リクエスト
+type ImportIDRequest struct {
+	ID int `path:"id" doc:"取り込みのID" minimum:"1"`
+}
+
+// ImportJobResponse 取り込みの進捗レスポンス
+type ImportJobResponse struct {
+	Body struct {
+		Data    *model.ImportJob `json:"data" doc:"取り込みの進捗"`
+		Message string           `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaImportHandler Huma用の取り込みハンドラー
+type HumaImportHandler struct {
+	importService service.ImportService
+}
+
+// NewHumaImportHandler 新しいHuma取り込みハンドラーインスタンスを作成
+func NewHumaImportHandler(importService service.ImportService) *HumaImportHandler {
+	return &HumaImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportTodoist Todoistのエクスポートの取り込みを開始
+func (h *HumaImportHandler) ImportTodoist(ctx context.Context, input *TodoistImportRequest) (*ImportJobResponse, error) {
+	var tasks []model.ImportTask
+	var err error
+	if input.Body.Format == "csv" {
+		tasks, err = todoist.ParseCSV(strings.NewReader(input.Body.Content), input.Body.Project)
+	} else {
+		tasks, err = todoist.ParseJSON([]byte(input.Body.Content))
+	}
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, err)
+	}
+	return h.start(ctx, "todoist", tasks, "Todoistからの取り込みを開始しました")
+}
+
+// ImportTrello Trelloのボードのエクスポートの取り込みを開始
+func (h *HumaImportHandler) ImportTrello(ctx context.Context, input *TrelloImportRequest) (*ImportJobResponse, error) {
+	tasks, err := trello.ParseBoard([]byte(input.Body.Content))
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, err)
+	}
+	return h.start(ctx, "trello", tasks, "Trelloからの取り込みを開始しました")
+}
+
+// GetImport 取り込みの進捗を取得
+func (h *HumaImportHandler) GetImport(ctx context.Context, input *ImportIDRequest) (*ImportJobResponse, error) {
+	job, err := h.importService.GetImport(ctx, uint(input.ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImportNotFound):
+			return nil, newError(http.StatusNotFound, err)
+		case isServiceUnavailable(err):
+			return nil, newError(http.StatusServiceUnavailable, err)
+		default:
+			return nil, newError(http.StatusInternalServerError, err)
+		}
+	}
+
+	return &ImportJobResponse{
+		Body: struct {
+			Data    *model.ImportJob `json:"data" doc:"取り込みの進捗"`
+			Message string           `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: "取り込みの進捗を取得しました",
+		},
+	}, nil
+}
+
+// start 変換したタスクの取り込みを開始
+func (h *HumaImportHandler) start(ctx context.Context, source string, tasks []model.ImportTask, message string) (*ImportJobResponse, error) {
+	if len(tasks) == 0 {
+		return nil, huma.Error400BadRequest("取り込むタスクがありません")
+	}
+
+	job, err := h.importService.Start(ctx, source, tasks)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &ImportJobResponse{
+		Body: struct {
+			Data    *model.ImportJob `json:"data" doc:"取り込みの進捗"`
+			Message string           `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: message,
+		},
+	}, nil
+}
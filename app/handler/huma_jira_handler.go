@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// JiraSyncResponse 同期結果レスポンス
+type JiraSyncResponse struct {
+	Body struct {
+		Data    *model.JiraSyncResult `json:"data" doc:"同期の結果"`
+		Message string                `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaJiraHandler Huma用のJira連携ハンドラー
+type HumaJiraHandler struct {
+	jiraService service.JiraService
+}
+
+// NewHumaJiraHandler 新しいHuma Jira連携ハンドラーインスタンスを作成
+func NewHumaJiraHandler(jiraService service.JiraService) *HumaJiraHandler {
+	return &HumaJiraHandler{
+		jiraService: jiraService,
+	}
+}
+
+// Sync 今すぐ同期
+func (h *HumaJiraHandler) Sync(ctx context.Context, input *struct{}) (*JiraSyncResponse, error) {
+	result, err := h.jiraService.Sync(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrJiraSyncRunning):
+			return nil, newError(http.StatusConflict, err)
+		case isServiceUnavailable(err):
+			return nil, newError(http.StatusServiceUnavailable, err)
+		default:
+			return nil, newError(http.StatusInternalServerError, err)
+		}
+	}
+
+	return &JiraSyncResponse{
+		Body: struct {
+			Data    *model.JiraSyncResult `json:"data" doc:"同期の結果"`
+			Message string                `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "Jiraの課題と同期しました",
+		},
+	}, nil
+}
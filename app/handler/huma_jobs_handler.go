@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/jobs"
+	"myapp/scheduler"
+	"net/http"
+	"sort"
+)
+
+// JobListResponse ジョブ稼働状況一覧のレスポンス
+type JobListResponse struct {
+	Body struct {
+		Data    []jobs.Status `json:"data" doc:"ジョブの稼働状況のリスト"`
+		Workers []string      `json:"workers" doc:"稼働中のバックグラウンドワーカー"`
+		Message string        `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// JobRunRequest ジョブの即時実行リクエスト
+type JobRunRequest struct {
+	Name string `path:"name" doc:"スケジューラーのジョブ名"`
+}
+
+// JobRunIDRequest 実行履歴のID指定リクエスト
+type JobRunIDRequest struct {
+	ID int `path:"id" minimum:"1" doc:"ジョブの実行履歴のID"`
+}
+
+// JobRunListRequest 実行履歴の取得リクエスト
+type JobRunListRequest struct {
+	Job    string `query:"job" doc:"絞り込むジョブ名"`
+	Status string `query:"status" enum:"running,succeeded,failed" doc:"絞り込む実行結果"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// JobRunResponse 実行履歴のレスポンス
+type JobRunResponse struct {
+	Body struct {
+		Data    *model.JobRun `json:"data" doc:"ジョブの実行履歴"`
+		Message string        `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// JobRunListResponse 実行履歴の一覧レスポンス
+type JobRunListResponse struct {
+	Body struct {
+		Data    []*model.JobRun `json:"data" doc:"ジョブの実行履歴（新しい順）"`
+		Count   int             `json:"count" doc:"件数"`
+		Message string          `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaJobsHandler Huma用のジョブ稼働状況ハンドラー
+type HumaJobsHandler struct {
+	workers   func() map[string]int
+	scheduler *scheduler.Scheduler
+}
+
+// NewHumaJobsHandler 新しいHumaジョブ稼働状況ハンドラーインスタンスを作成
+// workersは稼働中のワーカー名と起動数を返す関数
+func NewHumaJobsHandler(workers func() map[string]int, jobScheduler *scheduler.Scheduler) *HumaJobsHandler {
+	return &HumaJobsHandler{
+		workers:   workers,
+		scheduler: jobScheduler,
+	}
+}
+
+// GetJobs 全ジョブの稼働状況を取得
+func (h *HumaJobsHandler) GetJobs(ctx context.Context, input *struct{}) (*JobListResponse, error) {
+	workers := []string{}
+	for name := range h.workers() {
+		workers = append(workers, name)
+	}
+	sort.Strings(workers)
+
+	return &JobListResponse{
+		Body: struct {
+			Data    []jobs.Status `json:"data" doc:"ジョブの稼働状況のリスト"`
+			Workers []string      `json:"workers" doc:"稼働中のバックグラウンドワーカー"`
+			Message string        `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    jobs.List(),
+			Workers: workers,
+			Message: "ジョブの稼働状況を取得しました",
+		},
+	}, nil
+}
+
+// RunJob スケジューラーのジョブを即時実行する（実行はバックグラウンドで行い、実行履歴を返す）
+func (h *HumaJobsHandler) RunJob(ctx context.Context, input *JobRunRequest) (*JobRunResponse, error) {
+	run, err := h.scheduler.Trigger(ctx, input.Name)
+	if err != nil {
+		return nil, jobRunError(err)
+	}
+	return jobRunResponse(run, "ジョブの実行を開始しました"), nil
+}
+
+// ListRuns 実行履歴を取得
+func (h *HumaJobsHandler) ListRuns(ctx context.Context, input *JobRunListRequest) (*JobRunListResponse, error) {
+	runs, err := h.scheduler.Runs(ctx, input.Job, model.JobRunStatus(input.Status), input.Limit)
+	if err != nil {
+		return nil, jobRunError(err)
+	}
+
+	return &JobRunListResponse{
+		Body: struct {
+			Data    []*model.JobRun `json:"data" doc:"ジョブの実行履歴（新しい順）"`
+			Count   int             `json:"count" doc:"件数"`
+			Message string          `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    runs,
+			Count:   len(runs),
+			Message: "ジョブの実行履歴を取得しました",
+		},
+	}, nil
+}
+
+// GetRun 実行履歴を取得
+func (h *HumaJobsHandler) GetRun(ctx context.Context, input *JobRunIDRequest) (*JobRunResponse, error) {
+	run, err := h.scheduler.GetRun(ctx, uint(input.ID))
+	if err != nil {
+		return nil, jobRunError(err)
+	}
+	return jobRunResponse(run, "ジョブの実行履歴を取得しました"), nil
+}
+
+// jobRunResponse 実行履歴のレスポンスを作成
+func jobRunResponse(run *model.JobRun, message string) *JobRunResponse {
+	return &JobRunResponse{
+		Body: struct {
+			Data    *model.JobRun `json:"data" doc:"ジョブの実行履歴"`
+			Message string        `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    run,
+			Message: message,
+		},
+	}
+}
+
+// jobRunError スケジューラーのエラーをHTTPステータスに対応付ける
+func jobRunError(err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound), errors.Is(err, scheduler.ErrRunNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, scheduler.ErrJobRunning):
+		return newError(http.StatusConflict, err)
+	case errors.Is(err, scheduler.ErrNotRunning), isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
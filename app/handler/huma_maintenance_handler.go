@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"myapp/feature"
+	"myapp/maintenance"
+	"myapp/service"
+	"net/http"
+)
+
+// MaintenanceStatus メンテナンスモードの状態
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled" doc:"メンテナンスモードが有効か"`
+	maintenance.Settings
+}
+
+// MaintenanceResponse メンテナンスモードのレスポンス
+type MaintenanceResponse struct {
+	Body struct {
+		Data    MaintenanceStatus `json:"data" doc:"メンテナンスモードの状態"`
+		Message string            `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MaintenanceUpdateRequest メンテナンスモード更新リクエスト
+type MaintenanceUpdateRequest struct {
+	Body MaintenanceStatus
+}
+
+// HumaMaintenanceHandler Huma用のメンテナンスモードハンドラー
+type HumaMaintenanceHandler struct {
+	featureService service.FeatureService
+}
+
+// NewHumaMaintenanceHandler 新しいHumaメンテナンスモードハンドラーインスタンスを作成
+func NewHumaMaintenanceHandler(featureService service.FeatureService) *HumaMaintenanceHandler {
+	return &HumaMaintenanceHandler{
+		featureService: featureService,
+	}
+}
+
+// GetMaintenance メンテナンスモードの状態を取得
+func (h *HumaMaintenanceHandler) GetMaintenance(ctx context.Context, input *struct{}) (*MaintenanceResponse, error) {
+	return &MaintenanceResponse{
+		Body: struct {
+			Data    MaintenanceStatus `json:"data" doc:"メンテナンスモードの状態"`
+			Message string            `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data: MaintenanceStatus{
+				Enabled:  maintenance.Enabled(),
+				Settings: maintenance.Get(),
+			},
+			Message: "メンテナンスモードの状態を取得しました",
+		},
+	}, nil
+}
+
+// UpdateMaintenance メンテナンスモードを切り替え（有効/無効はフィーチャーフラグとしてDBに保存）
+func (h *HumaMaintenanceHandler) UpdateMaintenance(ctx context.Context, input *MaintenanceUpdateRequest) (*MaintenanceResponse, error) {
+	settings := maintenance.Set(input.Body.Settings)
+
+	flag, err := h.featureService.SetFeature(ctx, feature.FlagMaintenance, input.Body.Enabled)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	message := "メンテナンスモードを無効にしました"
+	if flag.Enabled {
+		message = "メンテナンスモードを有効にしました"
+	}
+
+	return &MaintenanceResponse{
+		Body: struct {
+			Data    MaintenanceStatus `json:"data" doc:"メンテナンスモードの状態"`
+			Message string            `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data: MaintenanceStatus{
+				Enabled:  flag.Enabled,
+				Settings: settings,
+			},
+			Message: message,
+		},
+	}, nil
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MSTodoStatusResponse Microsoft To Do連携の状態レスポンス
+type MSTodoStatusResponse struct {
+	Body struct {
+		Data    *model.MSTodoStatus `json:"data" doc:"連携の状態"`
+		Message string              `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MSTodoAuthorizeResponse 同意画面のURLレスポンス
+type MSTodoAuthorizeResponse struct {
+	Body struct {
+		URL     string `json:"url" doc:"ブラウザで開くMicrosoftの同意画面のURL（10分間有効）"`
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MSTodoCallbackRequest 同意画面からのリダイレクト
+type MSTodoCallbackRequest struct {
+	Code             string `query:"code" doc:"認可コード"`
+	State            string `query:"state" doc:"同意画面のURLを発行した際のstate"`
+	Error            string `query:"error" doc:"同意が拒否された場合のエラー"`
+	ErrorDescription string `query:"error_description" doc:"エラーの詳細"`
+}
+
+// MSTodoMessageResponse メッセージのみのレスポンス
+type MSTodoMessageResponse struct {
+	Body struct {
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MSTodoListsResponse リスト一覧レスポンス
+type MSTodoListsResponse struct {
+	Body struct {
+		Data    []model.MSTodoList `json:"data" doc:"リストと取り込み先のプロジェクト"`
+		Message string             `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// MSTodoImportRequest Microsoft To Doからの取り込みリクエスト
+type MSTodoImportRequest struct {
+	Body struct {
+		ListIDs          []string          `json:"list_ids,omitempty" doc:"取り込むリストのID（省略時は全てのリスト）"`
+		Projects         map[string]string `json:"projects,omitempty" doc:"リストのIDまたはリスト名と取り込み先のプロジェクト名の対応（設定の対応より優先。空文字でプロジェクトなし）"`
+		IncludeCompleted bool              `json:"include_completed,omitempty" doc:"完了済みのタスクも取り込む"`
+	}
+}
+
+// HumaMSTodoHandler Huma用のMicrosoft To Do連携ハンドラー
+type HumaMSTodoHandler struct {
+	mstodoService service.MSTodoService
+}
+
+// NewHumaMSTodoHandler 新しいHuma Microsoft To Do連携ハンドラーインスタンスを作成
+func NewHumaMSTodoHandler(mstodoService service.MSTodoService) *HumaMSTodoHandler {
+	return &HumaMSTodoHandler{
+		mstodoService: mstodoService,
+	}
+}
+
+// GetStatus 連携の状態を取得
+func (h *HumaMSTodoHandler) GetStatus(ctx context.Context, input *struct{}) (*MSTodoStatusResponse, error) {
+	status, err := h.mstodoService.Status(ctx)
+	if err != nil {
+		return nil, mstodoError(err)
+	}
+
+	return &MSTodoStatusResponse{
+		Body: struct {
+			Data    *model.MSTodoStatus `json:"data" doc:"連携の状態"`
+			Message string              `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    status,
+			Message: "Microsoft To Do連携の状態を取得しました",
+		},
+	}, nil
+}
+
+// Authorize 同意画面のURLを発行
+func (h *HumaMSTodoHandler) Authorize(ctx context.Context, input *struct{}) (*MSTodoAuthorizeResponse, error) {
+	url, err := h.mstodoService.AuthURL(ctx)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &MSTodoAuthorizeResponse{
+		Body: struct {
+			URL     string `json:"url" doc:"ブラウザで開くMicrosoftの同意画面のURL（10分間有効）"`
+			Message string `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			URL:     url,
+			Message: "URLをブラウザで開き、タスクへのアクセスを許可してください",
+		},
+	}, nil
+}
+
+// Callback 同意画面から戻った認可コードでトークンを取得
+func (h *HumaMSTodoHandler) Callback(ctx context.Context, input *MSTodoCallbackRequest) (*MSTodoMessageResponse, error) {
+	if input.Error != "" {
+		return nil, huma.Error400BadRequest("タスクへのアクセスが許可されませんでした: " + input.Error + " " + input.ErrorDescription)
+	}
+	if input.Code == "" {
+		return nil, huma.Error400BadRequest("認可コードがありません")
+	}
+
+	if err := h.mstodoService.HandleCallback(ctx, input.Code, input.State); err != nil {
+		if errors.Is(err, service.ErrInvalidOAuthState) {
+			return nil, newError(http.StatusBadRequest, err)
+		}
+		return nil, mstodoError(err)
+	}
+
+	resp := &MSTodoMessageResponse{}
+	resp.Body.Message = "Microsoft To Doと連携しました"
+	return resp, nil
+}
+
+// Disconnect 連携を解除
+func (h *HumaMSTodoHandler) Disconnect(ctx context.Context, input *struct{}) (*MSTodoMessageResponse, error) {
+	if err := h.mstodoService.Disconnect(ctx); err != nil {
+		return nil, mstodoError(err)
+	}
+
+	resp := &MSTodoMessageResponse{}
+	resp.Body.Message = "Microsoft To Doとの連携を解除しました"
+	return resp, nil
+}
+
+// GetLists リストの一覧を取得
+func (h *HumaMSTodoHandler) GetLists(ctx context.Context, input *struct{}) (*MSTodoListsResponse, error) {
+	lists, err := h.mstodoService.Lists(ctx)
+	if err != nil {
+		return nil, mstodoError(err)
+	}
+
+	return &MSTodoListsResponse{
+		Body: struct {
+			Data    []model.MSTodoList `json:"data" doc:"リストと取り込み先のプロジェクト"`
+			Message string             `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    lists,
+			Message: "Microsoft To Doのリストを取得しました",
+		},
+	}, nil
+}
+
+// Import リストのタスクの取り込みを開始
+func (h *HumaMSTodoHandler) Import(ctx context.Context, input *MSTodoImportRequest) (*ImportJobResponse, error) {
+	job, err := h.mstodoService.Import(ctx, model.MSTodoImportOptions{
+		ListIDs:          input.Body.ListIDs,
+		Projects:         input.Body.Projects,
+		IncludeCompleted: input.Body.IncludeCompleted,
+	})
+	if err != nil {
+		return nil, mstodoError(err)
+	}
+
+	return &ImportJobResponse{
+		Body: struct {
+			Data    *model.ImportJob `json:"data" doc:"取り込みの進捗"`
+			Message string           `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: "Microsoft To Doからの取り込みを開始しました",
+		},
+	}, nil
+}
+
+// mstodoError サービスのエラーをHTTPステータスに対応付ける
+func mstodoError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrMSTodoNotConnected):
+		return newError(http.StatusConflict, err)
+	case errors.Is(err, service.ErrMSTodoListNotFound), errors.Is(err, service.ErrImportEmpty):
+		return newError(http.StatusBadRequest, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// NotionSyncRequest 同期リクエスト
+type NotionSyncRequest struct {
+	Full bool `query:"full" doc:"trueの場合は変更の有無に関わらず全てのTodoをページに反映し直す"`
+}
+
+// NotionSyncResponse 同期結果レスポンス
+type NotionSyncResponse struct {
+	Body struct {
+		Data    *model.NotionSyncResult `json:"data" doc:"同期の結果"`
+		Message string                  `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaNotionHandler Huma用のNotionエクスポートハンドラー
+type HumaNotionHandler struct {
+	notionService service.NotionService
+}
+
+// NewHumaNotionHandler 新しいHuma Notionエクスポートハンドラーインスタンスを作成
+func NewHumaNotionHandler(notionService service.NotionService) *HumaNotionHandler {
+	return &HumaNotionHandler{
+		notionService: notionService,
+	}
+}
+
+// Sync 今すぐ同期
+func (h *HumaNotionHandler) Sync(ctx context.Context, input *NotionSyncRequest) (*NotionSyncResponse, error) {
+	result, err := h.notionService.Sync(ctx, input.Full)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotionSyncRunning):
+			return nil, newError(http.StatusConflict, err)
+		case errors.Is(err, service.ErrNotionInvalidSchema):
+			return nil, newError(http.StatusUnprocessableEntity, err)
+		case isServiceUnavailable(err):
+			return nil, newError(http.StatusServiceUnavailable, err)
+		default:
+			return nil, newError(http.StatusInternalServerError, err)
+		}
+	}
+
+	return &NotionSyncResponse{
+		Body: struct {
+			Data    *model.NotionSyncResult `json:"data" doc:"同期の結果"`
+			Message string                  `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "Notionのデータベースと同期しました",
+		},
+	}, nil
+}
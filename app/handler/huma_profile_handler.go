@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// ProfileUpdateRequest プロファイルの更新リクエスト
+type ProfileUpdateRequest struct {
+	Body model.UserProfileRequest
+}
+
+// ProfileResponse プロファイルのレスポンス
+type ProfileResponse struct {
+	Body struct {
+		Data    *model.UserProfile `json:"data" doc:"プロファイル"`
+		Message string             `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaProfileHandler Huma用のプロファイル（インスタンス全体の設定）のハンドラー
+type HumaProfileHandler struct {
+	profileService service.ProfileService
+}
+
+// NewHumaProfileHandler 新しいHumaプロファイルハンドラーインスタンスを作成
+func NewHumaProfileHandler(profileService service.ProfileService) *HumaProfileHandler {
+	return &HumaProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// GetProfile プロファイルを取得
+func (h *HumaProfileHandler) GetProfile(ctx context.Context, input *struct{}) (*ProfileResponse, error) {
+	profile, err := h.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, profileError(err)
+	}
+	return profileResponse(profile, "プロファイルを取得しました"), nil
+}
+
+// UpdateProfile プロファイルを更新
+func (h *HumaProfileHandler) UpdateProfile(ctx context.Context, input *ProfileUpdateRequest) (*ProfileResponse, error) {
+	profile, err := h.profileService.UpdateProfile(ctx, &input.Body)
+	if err != nil {
+		return nil, profileError(err)
+	}
+	return profileResponse(profile, "プロファイルを更新しました"), nil
+}
+
+// profileResponse プロファイルのレスポンスを作成
+func profileResponse(profile *model.UserProfile, message string) *ProfileResponse {
+	return &ProfileResponse{
+		Body: struct {
+			Data    *model.UserProfile `json:"data" doc:"プロファイル"`
+			Message string             `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    profile,
+			Message: message,
+		},
+	}
+}
+
+// profileError サービスのエラーをHTTPステータスに対応付ける
+func profileError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrProfileInvalidTimezone), errors.Is(err, service.ErrProfileInvalidSort):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// PushPublicKeyResponse VAPIDの公開鍵レスポンス
+type PushPublicKeyResponse struct {
+	Body struct {
+		PublicKey string `json:"public_key" doc:"pushManager.subscribe() の applicationServerKey に渡す公開鍵（base64url）"`
+		Message   string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// PushSubscribeRequest 購読の登録リクエスト
+type PushSubscribeRequest struct {
+	UserAgent string                        `header:"User-Agent" doc:"購読を登録したブラウザ（管理画面での識別用）"`
+	Body      model.PushSubscriptionRequest `doc:"ブラウザの PushSubscription.toJSON() の内容"`
+}
+
+// PushUnsubscribeRequest 購読の解除リクエスト
+type PushUnsubscribeRequest struct {
+	Endpoint string `query:"endpoint" required:"true" doc:"解除する購読の送信先URL"`
+}
+
+// PushSubscriptionIDRequest ID指定リクエスト
+type PushSubscriptionIDRequest struct {
+	ID int `path:"id" doc:"購読のID" minimum:"1"`
+}
+
+// PushSubscriptionResponse 購読レスポンス
+type PushSubscriptionResponse struct {
+	Body struct {
+		Data    *model.PushSubscription `json:"data" doc:"登録した購読"`
+		Message string                  `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// PushSubscriptionListResponse 購読一覧レスポンス
+type PushSubscriptionListResponse struct {
+	Body struct {
+		Data    []*model.PushSubscription `json:"data" doc:"登録済みの購読"`
+		Count   int                       `json:"count" doc:"購読の数"`
+		Message string                    `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// PushTestResponse テスト送信の結果レスポンス
+type PushTestResponse struct {
+	Body struct {
+		Data    *service.PushResult `json:"data" doc:"送信の結果"`
+		Message string              `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// PushMessageResponse メッセージのみのレスポンス
+type PushMessageResponse struct {
+	Body struct {
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaPushHandler Huma用のWeb Push通知ハンドラー
+type HumaPushHandler struct {
+	pushService service.PushService
+}
+
+// NewHumaPushHandler 新しいHuma Web Push通知ハンドラーインスタンスを作成
+func NewHumaPushHandler(pushService service.PushService) *HumaPushHandler {
+	return &HumaPushHandler{
+		pushService: pushService,
+	}
+}
+
+// GetPublicKey VAPIDの公開鍵を取得
+func (h *HumaPushHandler) GetPublicKey(ctx context.Context, input *struct{}) (*PushPublicKeyResponse, error) {
+	resp := &PushPublicKeyResponse{}
+	resp.Body.PublicKey = h.pushService.VAPIDPublicKey()
+	resp.Body.Message = "VAPIDの公開鍵を取得しました"
+	return resp, nil
+}
+
+// Subscribe 購読を登録
+func (h *HumaPushHandler) Subscribe(ctx context.Context, input *PushSubscribeRequest) (*PushSubscriptionResponse, error) {
+	sub, err := h.pushService.Subscribe(ctx, &input.Body, input.UserAgent)
+	if err != nil {
+		return nil, pushError(err)
+	}
+
+	return &PushSubscriptionResponse{
+		Body: struct {
+			Data    *model.PushSubscription `json:"data" doc:"登録した購読"`
+			Message string                  `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    sub,
+			Message: "Web Push通知を購読しました",
+		},
+	}, nil
+}
+
+// Unsubscribe 購読を解除
+func (h *HumaPushHandler) Unsubscribe(ctx context.Context, input *PushUnsubscribeRequest) (*PushMessageResponse, error) {
+	if err := h.pushService.Unsubscribe(ctx, input.Endpoint); err != nil {
+		return nil, pushError(err)
+	}
+
+	resp := &PushMessageResponse{}
+	resp.Body.Message = "Web Push通知の購読を解除しました"
+	return resp, nil
+}
+
+// ListSubscriptions 購読一覧を取得
+func (h *HumaPushHandler) ListSubscriptions(ctx context.Context, input *struct{}) (*PushSubscriptionListResponse, error) {
+	subs, err := h.pushService.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, pushError(err)
+	}
+
+	return &PushSubscriptionListResponse{
+		Body: struct {
+			Data    []*model.PushSubscription `json:"data" doc:"登録済みの購読"`
+			Count   int                       `json:"count" doc:"購読の数"`
+			Message string                    `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    subs,
+			Count:   len(subs),
+			Message: "Web Pushの購読一覧を取得しました",
+		},
+	}, nil
+}
+
+// DeleteSubscription 購読を削除
+func (h *HumaPushHandler) DeleteSubscription(ctx context.Context, input *PushSubscriptionIDRequest) (*PushMessageResponse, error) {
+	if err := h.pushService.DeleteSubscription(ctx, uint(input.ID)); err != nil {
+		return nil, pushError(err)
+	}
+
+	resp := &PushMessageResponse{}
+	resp.Body.Message = "Web Pushの購読を削除しました"
+	return resp, nil
+}
+
+// SendTest 全ての購読へテスト通知を送信
+func (h *HumaPushHandler) SendTest(ctx context.Context, input *struct{}) (*PushTestResponse, error) {
+	result, err := h.pushService.SendTest(ctx)
+	if err != nil {
+		return nil, pushError(err)
+	}
+
+	return &PushTestResponse{
+		Body: struct {
+			Data    *service.PushResult `json:"data" doc:"送信の結果"`
+			Message string              `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "テスト通知を送信しました",
+		},
+	}, nil
+}
+
+// pushError サービスのエラーをHTTPステータスに対応付ける
+func pushError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrPushSubscriptionNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrPushEndpointNotAllowed), errors.Is(err, service.ErrPushInvalidSubscription):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// QueueJobListRequest ジョブの一覧の取得リクエスト
+type QueueJobListRequest struct {
+	Status string `query:"status" enum:"pending,running,succeeded,dead" doc:"絞り込むジョブの状態（dead: デッドレター）"`
+	Kind   string `query:"kind" maxLength:"64" doc:"絞り込むジョブの種類（email.send 等）"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// QueueJobListResponse ジョブの一覧レスポンス
+type QueueJobListResponse struct {
+	Body struct {
+		Data    []*model.QueueJob `json:"data" doc:"ジョブ（新しい順）"`
+		Count   int               `json:"count" doc:"件数"`
+		Message string            `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// QueueJobRequest ジョブを指定するリクエスト
+type QueueJobRequest struct {
+	ID int `path:"id" minimum:"1" doc:"ジョブのID"`
+}
+
+// QueueJobResponse ジョブのレスポンス
+type QueueJobResponse struct {
+	Body struct {
+		Data    *model.QueueJob `json:"data" doc:"ジョブ"`
+		Message string          `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// QueueStatsResponse ジョブの件数のレスポンス
+type QueueStatsResponse struct {
+	Body struct {
+		Data    []service.QueueStat `json:"data" doc:"ジョブの種類・状態ごとの件数"`
+		Message string              `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// QueueRetryDeadRequest デッドレターのジョブの一括再実行リクエスト
+type QueueRetryDeadRequest struct {
+	Kind string `query:"kind" maxLength:"64" doc:"再実行するジョブの種類（省略時は全ての種類）"`
+}
+
+// QueueRetryDeadResponse デッドレターのジョブの一括再実行レスポンス
+type QueueRetryDeadResponse struct {
+	Body struct {
+		Retried int64  `json:"retried" doc:"実行待ちに戻したジョブの件数"`
+		Message string `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaQueueHandler Huma用のジョブキューの管理ハンドラー
+type HumaQueueHandler struct {
+	queueService service.QueueService
+}
+
+// NewHumaQueueHandler 新しいHumaジョブキューハンドラーインスタンスを作成
+func NewHumaQueueHandler(queueService service.QueueService) *HumaQueueHandler {
+	return &HumaQueueHandler{
+		queueService: queueService,
+	}
+}
+
+// ListJobs ジョブの一覧を取得
+func (h *HumaQueueHandler) ListJobs(ctx context.Context, input *QueueJobListRequest) (*QueueJobListResponse, error) {
+	jobs, err := h.queueService.ListJobs(ctx, model.QueueJobStatus(input.Status), input.Kind, input.Limit)
+	if err != nil {
+		return nil, queueError(err)
+	}
+
+	return &QueueJobListResponse{
+		Body: struct {
+			Data    []*model.QueueJob `json:"data" doc:"ジョブ（新しい順）"`
+			Count   int               `json:"count" doc:"件数"`
+			Message string            `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    jobs,
+			Count:   len(jobs),
+			Message: "ジョブを取得しました",
+		},
+	}, nil
+}
+
+// GetJob ジョブを取得
+func (h *HumaQueueHandler) GetJob(ctx context.Context, input *QueueJobRequest) (*QueueJobResponse, error) {
+	job, err := h.queueService.GetJob(ctx, uint(input.ID))
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return queueJobResponse(job, "ジョブを取得しました"), nil
+}
+
+// GetStats ジョブの種類・状態ごとの件数を取得
+func (h *HumaQueueHandler) GetStats(ctx context.Context, input *struct{}) (*QueueStatsResponse, error) {
+	stats, err := h.queueService.Stats(ctx)
+	if err != nil {
+		return nil, queueError(err)
+	}
+
+	return &QueueStatsResponse{
+		Body: struct {
+			Data    []service.QueueStat `json:"data" doc:"ジョブの種類・状態ごとの件数"`
+			Message string              `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    stats,
+			Message: "ジョブの件数を取得しました",
+		},
+	}, nil
+}
+
+// RetryJob デッドレター・成功済みのジョブを再実行
+func (h *HumaQueueHandler) RetryJob(ctx context.Context, input *QueueJobRequest) (*QueueJobResponse, error) {
+	job, err := h.queueService.RetryJob(ctx, uint(input.ID))
+	if err != nil {
+		return nil, queueError(err)
+	}
+	return queueJobResponse(job, "ジョブを実行待ちに戻しました"), nil
+}
+
+// RetryDead デッドレターのジョブをまとめて再実行
+func (h *HumaQueueHandler) RetryDead(ctx context.Context, input *QueueRetryDeadRequest) (*QueueRetryDeadResponse, error) {
+	retried, err := h.queueService.RetryDead(ctx, input.Kind)
+	if err != nil {
+		return nil, queueError(err)
+	}
+
+	return &QueueRetryDeadResponse{
+		Body: struct {
+			Retried int64  `json:"retried" doc:"実行待ちに戻したジョブの件数"`
+			Message string `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Retried: retried,
+			Message: "デッドレターのジョブを実行待ちに戻しました",
+		},
+	}, nil
+}
+
+// DeleteJob ジョブを削除
+func (h *HumaQueueHandler) DeleteJob(ctx context.Context, input *QueueJobRequest) (*DeleteResponse, error) {
+	if err := h.queueService.DeleteJob(ctx, uint(input.ID)); err != nil {
+		return nil, queueError(err)
+	}
+
+	return &DeleteResponse{
+		Body: struct {
+			Message string `json:"message" doc:"削除結果のメッセージ"`
+		}{
+			Message: fmt.Sprintf("ID %d のジョブを削除しました", input.ID),
+		},
+	}, nil
+}
+
+// queueJobResponse ジョブのレスポンスを作成
+func queueJobResponse(job *model.QueueJob, message string) *QueueJobResponse {
+	return &QueueJobResponse{
+		Body: struct {
+			Data    *model.QueueJob `json:"data" doc:"ジョブ"`
+			Message string          `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    job,
+			Message: message,
+		},
+	}
+}
+
+// queueError サービスのエラーをHTTPステータスに対応付ける
+func queueError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrQueueJobNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrQueueJobNotRetryable), errors.Is(err, service.ErrQueueJobRunning):
+		return newError(http.StatusConflict, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
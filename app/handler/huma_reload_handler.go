@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"myapp/config"
+	"myapp/reload"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ReloadResponse 設定再読み込みのレスポンス
+type ReloadResponse struct {
+	Body struct {
+		Data    *reload.Result `json:"data" doc:"再読み込みの結果"`
+		Message string         `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaReloadHandler Huma用の設定再読み込みハンドラー
+type HumaReloadHandler struct {
+	reloader *reload.Reloader
+}
+
+// NewHumaReloadHandler 新しいHuma設定再読み込みハンドラーインスタンスを作成
+func NewHumaReloadHandler(reloader *reload.Reloader) *HumaReloadHandler {
+	return &HumaReloadHandler{
+		reloader: reloader,
+	}
+}
+
+// Reload 設定を再読み込み
+func (h *HumaReloadHandler) Reload(ctx context.Context, input *struct{}) (*ReloadResponse, error) {
+	result, err := h.reloader.Reload(ctx)
+	if err != nil {
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			details := make([]error, 0, len(verr.Errors))
+			for _, fe := range verr.Errors {
+				details = append(details, &huma.ErrorDetail{Location: fe.Field, Message: fe.Message})
+			}
+			return nil, huma.Error422UnprocessableEntity("設定の検証に失敗しました。現在の設定を維持します", details...)
+		}
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &ReloadResponse{
+		Body: struct {
+			Data    *reload.Result `json:"data" doc:"再読み込みの結果"`
+			Message string         `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    result,
+			Message: "設定を再読み込みしました",
+		},
+	}, nil
+}
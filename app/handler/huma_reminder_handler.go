@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// ReminderDeliveryListRequest 配信状態の一覧の取得リクエスト
+type ReminderDeliveryListRequest struct {
+	TodoID int    `query:"todo_id" minimum:"0" doc:"絞り込むTodoのID（0の場合は全て）"`
+	Status string `query:"status" enum:"delivered,failed,skipped" doc:"絞り込む配信状態"`
+	Limit  int    `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// ReminderDeliveryListResponse 配信状態の一覧レスポンス
+type ReminderDeliveryListResponse struct {
+	Body struct {
+		Data    []*model.ReminderDelivery `json:"data" doc:"送信先ごとの配信状態（更新日時の新しい順）"`
+		Count   int                       `json:"count" doc:"件数"`
+		Message string                    `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaReminderHandler Huma用のリマインダーの配信状態ハンドラー
+type HumaReminderHandler struct {
+	reminderService service.ReminderService
+}
+
+// NewHumaReminderHandler 新しいHumaリマインダーハンドラーインスタンスを作成
+func NewHumaReminderHandler(reminderService service.ReminderService) *HumaReminderHandler {
+	return &HumaReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// ListDeliveries 期限間近・期限切れのリマインダーの配信状態を取得
+func (h *HumaReminderHandler) ListDeliveries(ctx context.Context, input *ReminderDeliveryListRequest) (*ReminderDeliveryListResponse, error) {
+	deliveries, err := h.reminderService.ListDeliveries(ctx, uint(input.TodoID), model.ReminderStatus(input.Status), input.Limit)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &ReminderDeliveryListResponse{
+		Body: struct {
+			Data    []*model.ReminderDelivery `json:"data" doc:"送信先ごとの配信状態（更新日時の新しい順）"`
+			Count   int                       `json:"count" doc:"件数"`
+			Message string                    `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    deliveries,
+			Count:   len(deliveries),
+			Message: "リマインダーの配信状態を取得しました",
+		},
+	}, nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"myapp/i18n"
+	"myapp/requestid"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routeMethods 405の Allow ヘッダーに含めるか判定するメソッド（ルーターに一致するルートがあるものを列挙する）
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// NotFound ルーターに一致するパスがない場合の404（chiの既定のプレーンテキストではなく、APIのエラーと同じproblem+json）
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeRouteError(w, r, http.StatusNotFound, "指定されたパスは存在しません")
+}
+
+// MethodNotAllowed パスはあるがメソッドに一致するルートがない場合の405（Allow ヘッダーに使用できるメソッドを付ける）
+// chiのカスタムハンドラーには使用できるメソッドが渡されないため、ルーターにメソッドごとに一致するルートがあるかを問い合わせる
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	if allowed := allowedMethods(r); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+	}
+	writeRouteError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("%s メソッドはこのパスでは使用できません", r.Method))
+}
+
+// allowedMethods リクエストのパスに一致するルートがあるメソッド
+func allowedMethods(r *http.Request) []string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+	var allowed []string
+	for _, method := range routeMethods {
+		if rctx.Routes.Match(chi.NewRouteContext(), method, r.URL.Path) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// writeRouteError Humaを通らないルーターのエラーを、APIErrorと同じ形式（コード・リクエストID付き、Accept-Languageの言語）で書き出す
+func writeRouteError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	apiErr := NewAPIError(status, i18n.TranslateError(i18n.FromContext(r.Context()), status, msg)).(*APIError)
+	apiErr.RequestID = requestid.FromContext(r.Context())
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiErr)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SnapshotIDRequest ID指定リクエスト
+type SnapshotIDRequest struct {
+	ID string `path:"id" pattern:"^[0-9]{8}-[0-9]{6}$" doc:"スナップショットのID（YYYYMMDD-HHMMSS）"`
+}
+
+// SnapshotDownloadRequest ダウンロードリクエスト
+type SnapshotDownloadRequest struct {
+	ID     string `path:"id" pattern:"^[0-9]{8}-[0-9]{6}$" doc:"スナップショットのID（YYYYMMDD-HHMMSS）"`
+	Format string `query:"format" enum:"json,csv" default:"json" doc:"ダウンロードする形式"`
+}
+
+// SnapshotResponse スナップショットのレスポンス
+type SnapshotResponse struct {
+	Body struct {
+		Data    *model.Snapshot `json:"data" doc:"スナップショット"`
+		Message string          `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// SnapshotListResponse スナップショットの一覧レスポンス
+type SnapshotListResponse struct {
+	Body struct {
+		Data    []*model.Snapshot `json:"data" doc:"スナップショット（新しい順）"`
+		Count   int               `json:"count" doc:"件数"`
+		Message string            `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaSnapshotHandler Huma用のエクスポートのスナップショットのハンドラー
+type HumaSnapshotHandler struct {
+	snapshotService service.SnapshotService
+}
+
+// NewHumaSnapshotHandler 新しいHumaスナップショットハンドラーインスタンスを作成
+func NewHumaSnapshotHandler(snapshotService service.SnapshotService) *HumaSnapshotHandler {
+	return &HumaSnapshotHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// ListSnapshots スナップショットの一覧を取得
+func (h *HumaSnapshotHandler) ListSnapshots(ctx context.Context, input *struct{}) (*SnapshotListResponse, error) {
+	snapshots, err := h.snapshotService.List(ctx)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	return &SnapshotListResponse{
+		Body: struct {
+			Data    []*model.Snapshot `json:"data" doc:"スナップショット（新しい順）"`
+			Count   int               `json:"count" doc:"件数"`
+			Message string            `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    snapshots,
+			Count:   len(snapshots),
+			Message: "スナップショットを取得しました",
+		},
+	}, nil
+}
+
+// CreateSnapshot スナップショットをすぐに作成
+func (h *HumaSnapshotHandler) CreateSnapshot(ctx context.Context, input *struct{}) (*SnapshotResponse, error) {
+	snapshot, err := h.snapshotService.Create(ctx)
+	if err != nil && snapshot == nil {
+		return nil, snapshotError(err)
+	}
+	if err != nil {
+		// 作成は成功し、古いスナップショットの削除のみ失敗した場合
+		slog.WarnContext(ctx, "古いスナップショットの削除に失敗しました", "error", err)
+	}
+	return snapshotResponse(snapshot, "スナップショットを作成しました"), nil
+}
+
+// GetSnapshot スナップショットを取得
+func (h *HumaSnapshotHandler) GetSnapshot(ctx context.Context, input *SnapshotIDRequest) (*SnapshotResponse, error) {
+	snapshot, err := h.snapshotService.Get(ctx, input.ID)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+	return snapshotResponse(snapshot, "スナップショットを取得しました"), nil
+}
+
+// DownloadSnapshot スナップショットのファイルをダウンロード
+func (h *HumaSnapshotHandler) DownloadSnapshot(ctx context.Context, input *SnapshotDownloadRequest) (*huma.StreamResponse, error) {
+	r, info, err := h.snapshotService.Open(ctx, input.ID, input.Format)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			defer r.Close()
+			contentType := info.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			hctx.SetHeader("Content-Type", contentType)
+			hctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="todos-%s.%s"`, input.ID, input.Format))
+			if info.Size >= 0 {
+				hctx.SetHeader("Content-Length", strconv.FormatInt(info.Size, 10))
+			}
+			if _, err := io.Copy(hctx.BodyWriter(), r); err != nil {
+				slog.WarnContext(ctx, "スナップショットの送信に失敗しました", "id", input.ID, "format", input.Format, "error", err)
+			}
+		},
+	}, nil
+}
+
+// DeleteSnapshot スナップショットを削除
+func (h *HumaSnapshotHandler) DeleteSnapshot(ctx context.Context, input *SnapshotIDRequest) (*DeleteResponse, error) {
+	if err := h.snapshotService.Delete(ctx, input.ID); err != nil {
+		return nil, snapshotError(err)
+	}
+
+	return &DeleteResponse{
+		Body: struct {
+			Message string `json:"message" doc:"削除結果のメッセージ"`
+		}{
+			Message: fmt.Sprintf("スナップショット %s を削除しました", input.ID),
+		},
+	}, nil
+}
+
+// snapshotResponse スナップショットのレスポンスを作成
+func snapshotResponse(snapshot *model.Snapshot, message string) *SnapshotResponse {
+	return &SnapshotResponse{
+		Body: struct {
+			Data    *model.Snapshot `json:"data" doc:"スナップショット"`
+			Message string          `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    snapshot,
+			Message: message,
+		},
+	}
+}
+
+// snapshotError サービスのエラーをHTTPステータスに対応付ける
+func snapshotError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrSnapshotNotFound), errors.Is(err, service.ErrSnapshotFormat):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrSnapshotExists):
+		return newError(http.StatusConflict, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
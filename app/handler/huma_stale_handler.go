@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"time"
+)
+
+// StaleTodoListRequest 放置タスクの一覧の取得リクエスト
+type StaleTodoListRequest struct {
+	Days  int `query:"days" minimum:"0" maximum:"3650" doc:"この日数より長く更新されていないTodoを返す（0の場合は設定 STALE_AFTER）"`
+	Limit int `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// StaleTodoListResponse 放置タスクの一覧レスポンス
+type StaleTodoListResponse struct {
+	Body struct {
+		Data    []*model.TodoResponse `json:"data" doc:"放置タスク（更新が古い順）"`
+		Count   int                   `json:"count" doc:"件数"`
+		Since   time.Time             `json:"since" doc:"この日時より前から更新されていないTodoを返した"`
+		Message string                `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaStaleHandler Huma用の放置タスクのハンドラー
+type HumaStaleHandler struct {
+	staleService service.StaleService
+}
+
+// NewHumaStaleHandler 新しいHuma放置タスクハンドラーインスタンスを作成
+func NewHumaStaleHandler(staleService service.StaleService) *HumaStaleHandler {
+	return &HumaStaleHandler{
+		staleService: staleService,
+	}
+}
+
+// ListStaleTodos 長期間更新されていない未完了のTodoを取得
+func (h *HumaStaleHandler) ListStaleTodos(ctx context.Context, input *StaleTodoListRequest) (*StaleTodoListResponse, error) {
+	todos, since, err := h.staleService.List(ctx, time.Duration(input.Days)*24*time.Hour, input.Limit)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	responses := make([]*model.TodoResponse, len(todos))
+	for i, todo := range todos {
+		responses[i] = toTodoResponse(todo)
+	}
+
+	return &StaleTodoListResponse{
+		Body: struct {
+			Data    []*model.TodoResponse `json:"data" doc:"放置タスク（更新が古い順）"`
+			Count   int                   `json:"count" doc:"件数"`
+			Since   time.Time             `json:"since" doc:"この日時より前から更新されていないTodoを返した"`
+			Message string                `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    responses,
+			Count:   len(responses),
+			Since:   since,
+			Message: "放置タスクを取得しました",
+		},
+	}, nil
+}
@@ -2,9 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"myapp/config"
 	"myapp/db/model"
+	"myapp/i18n"
+	"myapp/sanitize"
 	"myapp/service"
+	"net/http"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -15,17 +22,21 @@ import (
 // TodoListResponse Todoリスト取得のレスポンス
 type TodoListResponse struct {
 	Body struct {
-		Data    []*model.TodoResponse `json:"data" doc:"Todoアイテムのリスト"`
-		Message string                `json:"message" doc:"レスポンスメッセージ"`
-		Count   int                   `json:"count" doc:"Todoアイテムの総数"`
+		Data           []*model.TodoResponse `json:"data" doc:"Todoアイテムのリスト"`
+		Message        string                `json:"message" doc:"レスポンスメッセージ"`
+		Count          int                   `json:"count" doc:"Todoアイテムの総数"`
+		NextCursor     string                `json:"next_cursor,omitempty" doc:"次のページを取得するカーソル（limit指定時、最後のページでは省略）"`
+		Total          *int64                `json:"total,omitempty" doc:"絞り込みの条件に一致する総件数（limit指定時。count=noneの場合は省略）"`
+		TotalEstimated bool                  `json:"total_estimated,omitempty" doc:"totalが統計情報からの推定値の場合にtrue"`
 	}
 }
 
 // TodoResponse 単一Todo取得のレスポンス
 type TodoResponse struct {
 	Body struct {
-		Data    *model.TodoResponse `json:"data" doc:"Todoアイテム"`
-		Message string              `json:"message" doc:"レスポンスメッセージ"`
+		Data     *model.TodoResponse `json:"data" doc:"Todoアイテム"`
+		Message  string              `json:"message" doc:"レスポンスメッセージ"`
+		Warnings []service.Warning   `json:"warnings,omitempty" doc:"作成・更新はできたが確認が必要な内容（過去の期限等。validation.past_due_date が warn の場合）"`
 	}
 }
 
@@ -49,6 +60,16 @@ type TodoIDRequest struct {
 type TodoQueryRequest struct {
 	Priority  string `query:"priority" enum:"low,medium,high,urgent" doc:"優先度でフィルタリング"`
 	Completed string `query:"completed" doc:"完了状態でフィルタリング"`
+	Limit     int    `query:"limit" minimum:"0" maximum:"1000" doc:"1ページの件数（指定するとプロファイルの default_sort の順にページングし、next_cursorを返す。0の場合は全件）"`
+	Cursor    string `query:"cursor" doc:"前のページのnext_cursor（limitと併せて指定。default_sort を変更すると以前のカーソルは使えない）"`
+	Count     string `query:"count" enum:"exact,estimated,none" doc:"ページング時の総件数の求め方（exact: 正確に数える / estimated: 統計情報からの推定値 / none: 返さない。省略時は LIST_COUNT_MODE）"`
+}
+
+// TodoExportRequest ストリーミングでのエクスポートのリクエスト
+type TodoExportRequest struct {
+	Format    string `query:"format" enum:"jsonl,csv" default:"jsonl" doc:"形式（jsonl: 1行に1件のJSON / csv: ヘッダー付きのCSV）"`
+	Priority  string `query:"priority" enum:"low,medium,high,urgent" doc:"優先度でフィルタリング"`
+	Completed string `query:"completed" enum:"true,false" doc:"完了状態でフィルタリング"`
 }
 
 // DeleteResponse 削除レスポンス
@@ -91,63 +112,134 @@ func NewHumaTodoHandler(todoService service.TodoService) *HumaTodoHandler {
 // GetAllTodos 全てのTodoを取得
 func (h *HumaTodoHandler) GetAllTodos(ctx context.Context, input *TodoQueryRequest) (*TodoListResponse, error) {
 	var todos []*model.Todo
+	var nextCursor string
+	var total *service.TodoCount
 	var err error
 
 	// フィルタリング処理
-	if input.Priority != "" {
+	if input.Limit > 0 {
+		todos, nextCursor, total, err = h.getTodosPage(ctx, input)
+	} else if input.Cursor != "" {
+		return nil, huma.Error422UnprocessableEntity("cursorはlimitと併せて指定してください")
+	} else if input.Priority != "" {
 		priority := model.Priority(input.Priority)
-		todos, err = h.todoService.GetTodosByPriority(priority)
+		todos, err = h.todoService.GetTodosByPriority(ctx, priority)
 	} else if input.Completed != "" {
 		if input.Completed == "true" {
-			todos, err = h.todoService.GetCompletedTodos()
+			todos, err = h.todoService.GetCompletedTodos(ctx)
 		} else if input.Completed == "false" {
-			todos, err = h.todoService.GetPendingTodos()
+			todos, err = h.todoService.GetPendingTodos(ctx)
 		} else {
-			todos, err = h.todoService.GetAllTodos()
+			todos, err = h.todoService.GetAllTodos(ctx)
 		}
 	} else {
-		todos, err = h.todoService.GetAllTodos()
+		todos, err = h.todoService.GetAllTodos(ctx)
 	}
 
 	if err != nil {
-		return nil, huma.Error500InternalServerError(err.Error())
+		return nil, todoError(err)
 	}
 
 	// TodoResponseに変換
 	responses := make([]*model.TodoResponse, len(todos))
 	for i, todo := range todos {
-		responses[i] = todo.ToResponse()
+		responses[i] = toTodoResponse(todo)
 	}
 
-	return &TodoListResponse{
+	response := &TodoListResponse{
 		Body: struct {
-			Data    []*model.TodoResponse `json:"data" doc:"Todoアイテムのリスト"`
-			Message string                `json:"message" doc:"レスポンスメッセージ"`
-			Count   int                   `json:"count" doc:"Todoアイテムの総数"`
+			Data           []*model.TodoResponse `json:"data" doc:"Todoアイテムのリスト"`
+			Message        string                `json:"message" doc:"レスポンスメッセージ"`
+			Count          int                   `json:"count" doc:"Todoアイテムの総数"`
+			NextCursor     string                `json:"next_cursor,omitempty" doc:"次のページを取得するカーソル（limit指定時、最後のページでは省略）"`
+			Total          *int64                `json:"total,omitempty" doc:"絞り込みの条件に一致する総件数（limit指定時。count=noneの場合は省略）"`
+			TotalEstimated bool                  `json:"total_estimated,omitempty" doc:"totalが統計情報からの推定値の場合にtrue"`
 		}{
-			Data:    responses,
-			Message: "Todoリストを取得しました",
-			Count:   len(responses),
+			Data:       responses,
+			Message:    "Todoリストを取得しました",
+			Count:      len(responses),
+			NextCursor: nextCursor,
+		},
+	}
+	if total != nil {
+		response.Body.Total = &total.Total
+		response.Body.TotalEstimated = total.Estimated
+	}
+	return response, nil
+}
+
+// getTodosPage 絞り込みの条件でTodoを1ページ取得し、総件数とともに返す
+func (h *HumaTodoHandler) getTodosPage(ctx context.Context, input *TodoQueryRequest) ([]*model.Todo, string, *service.TodoCount, error) {
+	q := &service.TodoPageQuery{
+		Priority: model.Priority(input.Priority),
+		Cursor:   input.Cursor,
+		Limit:    input.Limit,
+	}
+	if input.Priority == "" && (input.Completed == "true" || input.Completed == "false") {
+		completed := input.Completed == "true"
+		q.Completed = &completed
+	}
+
+	page, err := h.todoService.GetTodosPage(ctx, q)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	mode := service.CountMode(input.Count)
+	if mode == "" {
+		mode = service.CountMode(config.Current().List.CountMode)
+	}
+	if mode == service.CountNone {
+		return page.Todos, page.NextCursor, nil, nil
+	}
+	total, err := h.todoService.CountTodos(ctx, q, mode)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return page.Todos, page.NextCursor, total, nil
+}
+
+// ExportTodos Todoを全件メモリに載せずにJSON Lines・CSVでストリーミングする
+func (h *HumaTodoHandler) ExportTodos(ctx context.Context, input *TodoExportRequest) (*huma.StreamResponse, error) {
+	q := &service.TodoPageQuery{Priority: model.Priority(input.Priority)}
+	if input.Completed != "" {
+		completed := input.Completed == "true"
+		q.Completed = &completed
+	}
+	format := service.ExportFormat(input.Format)
+	contentType := "application/x-ndjson"
+	if format == service.ExportCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			hctx.SetHeader("Content-Type", contentType)
+			hctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="todos-%s.%s"`, time.Now().Format("20060102-150405"), format))
+			w := &flushWriter{w: hctx.BodyWriter()}
+			count, err := h.todoService.ExportTodos(ctx, q, format, w)
+			if err != nil {
+				// ステータスコードは送信済みのため、途中で打ち切ってログに残す
+				slog.WarnContext(ctx, "Todoのエクスポートを中断しました", "format", format, "count", count, "error", err)
+			}
 		},
 	}, nil
 }
 
 // GetTodoByID 特定のTodoを取得
 func (h *HumaTodoHandler) GetTodoByID(ctx context.Context, input *TodoIDRequest) (*TodoResponse, error) {
-	todo, err := h.todoService.GetTodoByID(uint(input.ID))
+	todo, err := h.todoService.GetTodoByID(ctx, uint(input.ID))
 	if err != nil {
-		if err.Error() == fmt.Sprintf("ID %d のTodoが見つかりません", input.ID) {
-			return nil, huma.Error404NotFound(err.Error())
-		}
-		return nil, huma.Error500InternalServerError(err.Error())
+		return nil, todoError(err)
 	}
 
 	return &TodoResponse{
 		Body: struct {
-			Data    *model.TodoResponse `json:"data" doc:"Todoアイテム"`
-			Message string              `json:"message" doc:"レスポンスメッセージ"`
+			Data     *model.TodoResponse `json:"data" doc:"Todoアイテム"`
+			Message  string              `json:"message" doc:"レスポンスメッセージ"`
+			Warnings []service.Warning   `json:"warnings,omitempty" doc:"作成・更新はできたが確認が必要な内容（過去の期限等。validation.past_due_date が warn の場合）"`
 		}{
-			Data:    todo.ToResponse(),
+			Data:    toTodoResponse(todo),
 			Message: "Todoを取得しました",
 		},
 	}, nil
@@ -155,18 +247,21 @@ func (h *HumaTodoHandler) GetTodoByID(ctx context.Context, input *TodoIDRequest)
 
 // CreateTodo 新しいTodoを作成
 func (h *HumaTodoHandler) CreateTodo(ctx context.Context, input *TodoCreateRequest) (*TodoResponse, error) {
-	todo, err := h.todoService.CreateTodo(&input.Body)
+	ctx, warnings := service.WithWarnings(ctx)
+	todo, err := h.todoService.CreateTodo(ctx, &input.Body)
 	if err != nil {
-		return nil, huma.Error400BadRequest(err.Error())
+		return nil, todoError(err)
 	}
 
 	resp := &TodoResponse{
 		Body: struct {
-			Data    *model.TodoResponse `json:"data" doc:"Todoアイテム"`
-			Message string              `json:"message" doc:"レスポンスメッセージ"`
+			Data     *model.TodoResponse `json:"data" doc:"Todoアイテム"`
+			Message  string              `json:"message" doc:"レスポンスメッセージ"`
+			Warnings []service.Warning   `json:"warnings,omitempty" doc:"作成・更新はできたが確認が必要な内容（過去の期限等。validation.past_due_date が warn の場合）"`
 		}{
-			Data:    todo.ToResponse(),
-			Message: "Todoを作成しました",
+			Data:     toTodoResponse(todo),
+			Message:  "Todoを作成しました",
+			Warnings: translateWarnings(ctx, warnings),
 		},
 	}
 
@@ -175,33 +270,30 @@ func (h *HumaTodoHandler) CreateTodo(ctx context.Context, input *TodoCreateReque
 
 // UpdateTodo 既存のTodoを更新
 func (h *HumaTodoHandler) UpdateTodo(ctx context.Context, input *TodoUpdateRequest) (*TodoResponse, error) {
-	todo, err := h.todoService.UpdateTodo(uint(input.ID), &input.Body)
+	ctx, warnings := service.WithWarnings(ctx)
+	todo, err := h.todoService.UpdateTodo(ctx, uint(input.ID), &input.Body)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("ID %d のTodoが見つかりません", input.ID) {
-			return nil, huma.Error404NotFound(err.Error())
-		}
-		return nil, huma.Error400BadRequest(err.Error())
+		return nil, todoError(err)
 	}
 
 	return &TodoResponse{
 		Body: struct {
-			Data    *model.TodoResponse `json:"data" doc:"Todoアイテム"`
-			Message string              `json:"message" doc:"レスポンスメッセージ"`
+			Data     *model.TodoResponse `json:"data" doc:"Todoアイテム"`
+			Message  string              `json:"message" doc:"レスポンスメッセージ"`
+			Warnings []service.Warning   `json:"warnings,omitempty" doc:"作成・更新はできたが確認が必要な内容（過去の期限等。validation.past_due_date が warn の場合）"`
 		}{
-			Data:    todo.ToResponse(),
-			Message: "Todoを更新しました",
+			Data:     toTodoResponse(todo),
+			Message:  "Todoを更新しました",
+			Warnings: translateWarnings(ctx, warnings),
 		},
 	}, nil
 }
 
 // DeleteTodo Todoを削除
 func (h *HumaTodoHandler) DeleteTodo(ctx context.Context, input *TodoIDRequest) (*DeleteResponse, error) {
-	err := h.todoService.DeleteTodo(uint(input.ID))
+	err := h.todoService.DeleteTodo(ctx, uint(input.ID))
 	if err != nil {
-		if err.Error() == fmt.Sprintf("ID %d のTodoが見つかりません", input.ID) {
-			return nil, huma.Error404NotFound(err.Error())
-		}
-		return nil, huma.Error500InternalServerError(err.Error())
+		return nil, todoError(err)
 	}
 
 	return &DeleteResponse{
@@ -212,3 +304,49 @@ func (h *HumaTodoHandler) DeleteTodo(ctx context.Context, input *TodoIDRequest)
 		},
 	}, nil
 }
+
+// todoError Todoサービスのエラーをエラーコード付きのHTTPエラーに変換
+func todoError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		return newError(http.StatusNotFound, err)
+	case isValidation(err), errors.Is(err, service.ErrInvalidPriority), errors.Is(err, service.ErrInvalidCursor):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
+
+// toTodoResponse TodoをAPIレスポンスに変換（出力時サニタイズの設定であれば説明文を無害化する）
+func toTodoResponse(todo *model.Todo) *model.TodoResponse {
+	resp := todo.ToResponse()
+	resp.Description = sanitize.OnOutput(resp.Description)
+	return resp
+}
+
+// flushWriter 書き込むたびにクライアントへ送信するWriter（ExportTodosはバッチごとにまとめて書き込む）
+type flushWriter struct {
+	w io.Writer
+}
+
+// Write 書き込んでフラッシュする
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		if rw, ok := f.w.(http.ResponseWriter); ok {
+			_ = http.NewResponseController(rw).Flush()
+		}
+	}
+	return n, err
+}
+
+// translateWarnings サービスが追加した警告のメッセージをAccept-Languageの言語に翻訳する（警告がなければnil）
+func translateWarnings(ctx context.Context, warnings *service.Warnings) []service.Warning {
+	list := warnings.List()
+	for i := range list {
+		list[i].Message = i18n.T(ctx, list[i].Message)
+	}
+	return list
+}
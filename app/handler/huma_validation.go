@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"io"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// unexpectedPropertyMessage Humaがスキーマ外のフィールドを検出した際のエラーメッセージ
+const unexpectedPropertyMessage = "unexpected property"
+
+// strictUnknownFields スキーマ外のフィールドを400で拒否するか（ConfigureUnknownFieldsで設定）
+var strictUnknownFields bool
+
+// ConfigureUnknownFields リクエストJSONに含まれるスキーマ外のフィールドの扱いを設定する
+// strictの場合は400で拒否し、そうでない場合は無視して受け付ける
+// 全てのオペレーションを登録した後に呼び出すこと（登録済みのスキーマのみが対象）
+func ConfigureUnknownFields(api huma.API, strict bool) {
+	strictUnknownFields = strict
+
+	visited := map[*huma.Schema]bool{}
+	for _, schema := range api.OpenAPI().Components.Schemas.Map() {
+		allowAdditionalProperties(schema, !strict, visited)
+	}
+}
+
+// allowAdditionalProperties オブジェクトスキーマ（ネストしたものを含む）のadditionalPropertiesを設定
+// map型など値のスキーマが指定されているものは変更しない
+func allowAdditionalProperties(schema *huma.Schema, allow bool, visited map[*huma.Schema]bool) {
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+
+	if _, ok := schema.AdditionalProperties.(bool); ok && schema.Type == huma.TypeObject {
+		schema.AdditionalProperties = allow
+	}
+	for _, property := range schema.Properties {
+		allowAdditionalProperties(property, allow, visited)
+	}
+	allowAdditionalProperties(schema.Items, allow, visited)
+}
+
+// hasUnexpectedProperty バリデーションエラーにスキーマ外のフィールドが含まれるか
+func hasUnexpectedProperty(details []*huma.ErrorDetail) bool {
+	for _, detail := range details {
+		if detail.Message == unexpectedPropertyMessage {
+			return true
+		}
+	}
+	return false
+}
+
+// DeferredStatusMiddleware レスポンスのステータスコードを本文の書き込み直前まで確定させないHumaミドルウェア
+// Humaはエラー生成時に決めたステータスを先に書き込むため、ErrorTransformerでの変更（422→400）を反映するために使う
+// オペレーションの登録前に api.UseMiddleware で追加すること
+func DeferredStatusMiddleware(ctx huma.Context, next func(huma.Context)) {
+	deferred := &deferredStatusContext{humaContext: ctx}
+	next(deferred)
+	deferred.writeStatus()
+}
+
+// humaContext 埋め込み時にContext()メソッドとフィールド名が衝突しないようにするための別名
+type humaContext = huma.Context
+
+// deferredStatusContext SetStatusを保留し、BodyWriter取得時または処理完了時に書き込むContext
+type deferredStatusContext struct {
+	humaContext
+	status  int
+	written bool
+}
+
+func (c *deferredStatusContext) SetStatus(code int) {
+	if !c.written {
+		c.status = code
+	}
+}
+
+func (c *deferredStatusContext) BodyWriter() io.Writer {
+	c.writeStatus()
+	return c.humaContext.BodyWriter()
+}
+
+func (c *deferredStatusContext) writeStatus() {
+	if c.written || c.status == 0 {
+		return
+	}
+	c.written = true
+	c.humaContext.SetStatus(c.status)
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+)
+
+// WebhookEndpointIDRequest ID指定リクエスト
+type WebhookEndpointIDRequest struct {
+	ID int `path:"id" minimum:"1" doc:"Webhookの送信先のID"`
+}
+
+// WebhookEndpointCreateRequest 送信先の登録リクエスト
+type WebhookEndpointCreateRequest struct {
+	Body model.WebhookEndpointRequest
+}
+
+// WebhookEndpointUpdateRequest 送信先の更新リクエスト
+type WebhookEndpointUpdateRequest struct {
+	ID   int `path:"id" minimum:"1" doc:"Webhookの送信先のID"`
+	Body model.WebhookEndpointRequest
+}
+
+// WebhookEndpointResponse 送信先のレスポンス
+type WebhookEndpointResponse struct {
+	Body struct {
+		Data    *model.WebhookEndpoint `json:"data" doc:"Webhookの送信先"`
+		Message string                 `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// WebhookEndpointListResponse 送信先の一覧レスポンス
+type WebhookEndpointListResponse struct {
+	Body struct {
+		Data    []*model.WebhookEndpoint `json:"data" doc:"Webhookの送信先（登録順）"`
+		Count   int                      `json:"count" doc:"件数"`
+		Message string                   `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// WebhookDeliveryIDRequest 配信のID指定リクエスト
+type WebhookDeliveryIDRequest struct {
+	ID int `path:"id" minimum:"1" doc:"Webhookの配信のID"`
+}
+
+// WebhookDeliveryListRequest 配信の履歴の取得リクエスト
+type WebhookDeliveryListRequest struct {
+	EndpointID int    `query:"endpoint_id" minimum:"0" doc:"絞り込む送信先のID（0の場合は全て）"`
+	Status     string `query:"status" enum:"pending,succeeded,failed,canceled" doc:"絞り込む配信の状態"`
+	Limit      int    `query:"limit" minimum:"1" maximum:"1000" default:"100" doc:"取得する件数の上限"`
+}
+
+// WebhookDeliveryResponse 配信のレスポンス
+type WebhookDeliveryResponse struct {
+	Body struct {
+		Data    *model.WebhookDelivery `json:"data" doc:"Webhookの配信"`
+		Message string                 `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// WebhookDeliveryListResponse 配信の履歴のレスポンス
+type WebhookDeliveryListResponse struct {
+	Body struct {
+		Data    []*model.WebhookDelivery `json:"data" doc:"Webhookの配信（新しい順）"`
+		Count   int                      `json:"count" doc:"件数"`
+		Message string                   `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaWebhookEndpointHandler Huma用のWebhookの送信先・配信の履歴のハンドラー
+type HumaWebhookEndpointHandler struct {
+	webhookEndpointService service.WebhookEndpointService
+}
+
+// NewHumaWebhookEndpointHandler 新しいHuma Webhook送信先ハンドラーインスタンスを作成
+func NewHumaWebhookEndpointHandler(webhookEndpointService service.WebhookEndpointService) *HumaWebhookEndpointHandler {
+	return &HumaWebhookEndpointHandler{
+		webhookEndpointService: webhookEndpointService,
+	}
+}
+
+// ListEndpoints 送信先の一覧を取得
+func (h *HumaWebhookEndpointHandler) ListEndpoints(ctx context.Context, input *struct{}) (*WebhookEndpointListResponse, error) {
+	endpoints, err := h.webhookEndpointService.ListEndpoints(ctx)
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+
+	return &WebhookEndpointListResponse{
+		Body: struct {
+			Data    []*model.WebhookEndpoint `json:"data" doc:"Webhookの送信先（登録順）"`
+			Count   int                      `json:"count" doc:"件数"`
+			Message string                   `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    endpoints,
+			Count:   len(endpoints),
+			Message: "Webhookの送信先を取得しました",
+		},
+	}, nil
+}
+
+// GetEndpoint 送信先を取得
+func (h *HumaWebhookEndpointHandler) GetEndpoint(ctx context.Context, input *WebhookEndpointIDRequest) (*WebhookEndpointResponse, error) {
+	endpoint, err := h.webhookEndpointService.GetEndpoint(ctx, uint(input.ID))
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+	return webhookEndpointResponse(endpoint, "Webhookの送信先を取得しました"), nil
+}
+
+// CreateEndpoint 送信先を登録
+func (h *HumaWebhookEndpointHandler) CreateEndpoint(ctx context.Context, input *WebhookEndpointCreateRequest) (*WebhookEndpointResponse, error) {
+	endpoint, err := h.webhookEndpointService.CreateEndpoint(ctx, &input.Body)
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+	return webhookEndpointResponse(endpoint, "Webhookの送信先を登録しました"), nil
+}
+
+// UpdateEndpoint 送信先を更新（停止中の送信先はenabled=trueで再開する）
+func (h *HumaWebhookEndpointHandler) UpdateEndpoint(ctx context.Context, input *WebhookEndpointUpdateRequest) (*WebhookEndpointResponse, error) {
+	endpoint, err := h.webhookEndpointService.UpdateEndpoint(ctx, uint(input.ID), &input.Body)
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+	return webhookEndpointResponse(endpoint, "Webhookの送信先を更新しました"), nil
+}
+
+// DeleteEndpoint 送信先を削除
+func (h *HumaWebhookEndpointHandler) DeleteEndpoint(ctx context.Context, input *WebhookEndpointIDRequest) (*DeleteResponse, error) {
+	if err := h.webhookEndpointService.DeleteEndpoint(ctx, uint(input.ID)); err != nil {
+		return nil, webhookEndpointError(err)
+	}
+
+	return &DeleteResponse{
+		Body: struct {
+			Message string `json:"message" doc:"削除結果のメッセージ"`
+		}{
+			Message: fmt.Sprintf("ID %d のWebhookの送信先を削除しました", input.ID),
+		},
+	}, nil
+}
+
+// ListDeliveries 配信の履歴を取得
+func (h *HumaWebhookEndpointHandler) ListDeliveries(ctx context.Context, input *WebhookDeliveryListRequest) (*WebhookDeliveryListResponse, error) {
+	deliveries, err := h.webhookEndpointService.ListDeliveries(ctx, uint(input.EndpointID), model.WebhookDeliveryStatus(input.Status), input.Limit)
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+
+	return &WebhookDeliveryListResponse{
+		Body: struct {
+			Data    []*model.WebhookDelivery `json:"data" doc:"Webhookの配信（新しい順）"`
+			Count   int                      `json:"count" doc:"件数"`
+			Message string                   `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    deliveries,
+			Count:   len(deliveries),
+			Message: "Webhookの配信の履歴を取得しました",
+		},
+	}, nil
+}
+
+// GetDelivery 配信を取得
+func (h *HumaWebhookEndpointHandler) GetDelivery(ctx context.Context, input *WebhookDeliveryIDRequest) (*WebhookDeliveryResponse, error) {
+	delivery, err := h.webhookEndpointService.GetDelivery(ctx, uint(input.ID))
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+	return webhookDeliveryResponse(delivery, "Webhookの配信を取得しました"), nil
+}
+
+// Redeliver 配信をもう一度配信する
+func (h *HumaWebhookEndpointHandler) Redeliver(ctx context.Context, input *WebhookDeliveryIDRequest) (*WebhookDeliveryResponse, error) {
+	delivery, err := h.webhookEndpointService.Redeliver(ctx, uint(input.ID))
+	if err != nil {
+		return nil, webhookEndpointError(err)
+	}
+	return webhookDeliveryResponse(delivery, "Webhookの再配信を受け付けました"), nil
+}
+
+// webhookEndpointResponse 送信先のレスポンスを作成
+func webhookEndpointResponse(endpoint *model.WebhookEndpoint, message string) *WebhookEndpointResponse {
+	return &WebhookEndpointResponse{
+		Body: struct {
+			Data    *model.WebhookEndpoint `json:"data" doc:"Webhookの送信先"`
+			Message string                 `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    endpoint,
+			Message: message,
+		},
+	}
+}
+
+// webhookDeliveryResponse 配信のレスポンスを作成
+func webhookDeliveryResponse(delivery *model.WebhookDelivery, message string) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		Body: struct {
+			Data    *model.WebhookDelivery `json:"data" doc:"Webhookの配信"`
+			Message string                 `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    delivery,
+			Message: message,
+		},
+	}
+}
+
+// webhookEndpointError サービスのエラーをHTTPステータスに対応付ける
+func webhookEndpointError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrWebhookEndpointNotFound), errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		return newError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrWebhookDeliveryPending), errors.Is(err, service.ErrWebhookEndpointDisabled):
+		return newError(http.StatusConflict, err)
+	case errors.Is(err, service.ErrWebhookInvalidURL), errors.Is(err, service.ErrWebhookForbiddenURL), errors.Is(err, service.ErrWebhookUnknownEvent):
+		return newError(http.StatusUnprocessableEntity, err)
+	case isServiceUnavailable(err):
+		return newError(http.StatusServiceUnavailable, err)
+	default:
+		return newError(http.StatusInternalServerError, err)
+	}
+}
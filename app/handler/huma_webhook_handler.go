@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"myapp/config"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"time"
+)
+
+// WebhookSecretRotateRequest Webhookシークレットのローテーションリクエスト
+type WebhookSecretRotateRequest struct {
+	Body struct {
+		GracePeriodSeconds int `json:"grace_period_seconds,omitempty" minimum:"0" doc:"旧シークレットでも署名を続ける猶予期間（秒）。省略時は設定値"`
+	}
+}
+
+// WebhookSecretRotateResponse Webhookシークレットのローテーションレスポンス
+type WebhookSecretRotateResponse struct {
+	Body struct {
+		Data    *model.WebhookSecretRotation `json:"data" doc:"ローテーションの結果"`
+		Message string                       `json:"message" doc:"レスポンスメッセージ"`
+	}
+}
+
+// HumaWebhookHandler Huma用のWebhook管理ハンドラー
+type HumaWebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewHumaWebhookHandler 新しいHuma Webhook管理ハンドラーインスタンスを作成
+func NewHumaWebhookHandler(webhookService service.WebhookService) *HumaWebhookHandler {
+	return &HumaWebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// RotateSecret 署名シークレットをローテーション
+func (h *HumaWebhookHandler) RotateSecret(ctx context.Context, input *WebhookSecretRotateRequest) (*WebhookSecretRotateResponse, error) {
+	gracePeriod := config.Current().Webhook.RotationGracePeriod
+	if input.Body.GracePeriodSeconds > 0 {
+		gracePeriod = time.Duration(input.Body.GracePeriodSeconds) * time.Second
+	}
+
+	rotation, err := h.webhookService.RotateSecret(ctx, gracePeriod)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusInternalServerError, err)
+	}
+
+	return &WebhookSecretRotateResponse{
+		Body: struct {
+			Data    *model.WebhookSecretRotation `json:"data" doc:"ローテーションの結果"`
+			Message string                       `json:"message" doc:"レスポンスメッセージ"`
+		}{
+			Data:    rotation,
+			Message: "Webhookの署名シークレットをローテーションしました",
+		},
+	}, nil
+}
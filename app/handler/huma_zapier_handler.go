@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ZapierAuthRequest APIキーのみのリクエスト
+type ZapierAuthRequest struct {
+	APIKey string `header:"X-API-Key" doc:"ZAPIER_API_KEYに設定したAPIキー"`
+}
+
+// ZapierTriggerRequest ポーリング型トリガーのリクエスト
+type ZapierTriggerRequest struct {
+	APIKey string `header:"X-API-Key" doc:"ZAPIER_API_KEYに設定したAPIキー"`
+	Limit  int    `query:"limit" minimum:"0" maximum:"100" doc:"返す件数（省略時は50）"`
+}
+
+// ZapierCreateTodoRequest create_todoアクションのリクエスト
+type ZapierCreateTodoRequest struct {
+	APIKey string                  `header:"X-API-Key" doc:"ZAPIER_API_KEYに設定したAPIキー"`
+	Body   model.TodoCreateRequest `doc:"作成するTodoの情報"`
+}
+
+// ZapierTodo Zapierに返すTodo（idはZapierが重複排除に使う一意なキー）
+type ZapierTodo struct {
+	ID          string         `json:"id" doc:"重複排除のキー（new_todoはTodoのID、completed_todoはTodoのIDと完了日時）"`
+	TodoID      uint           `json:"todo_id" doc:"TodoのID"`
+	Title       string         `json:"title" doc:"タイトル"`
+	Description string         `json:"description" doc:"説明"`
+	Priority    model.Priority `json:"priority" doc:"優先度"`
+	DueDate     *time.Time     `json:"due_date,omitempty" doc:"期限"`
+	Tags        []string       `json:"tags" doc:"タグ"`
+	Completed   bool           `json:"completed" doc:"完了済みか"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty" doc:"完了日時"`
+	CreatedAt   time.Time      `json:"created_at" doc:"作成日時"`
+	UpdatedAt   time.Time      `json:"updated_at" doc:"更新日時"`
+}
+
+// ZapierAuthResponse 接続テストのレスポンス
+type ZapierAuthResponse struct {
+	Body struct {
+		OK    bool   `json:"ok" doc:"APIキーが有効か"`
+		Label string `json:"label" doc:"Zapierの接続一覧に表示する名前"`
+	}
+}
+
+// ZapierTriggerResponse ポーリング型トリガーのレスポンス（新しい順の配列）
+type ZapierTriggerResponse struct {
+	Body []ZapierTodo
+}
+
+// ZapierTodoResponse アクションのレスポンス
+type ZapierTodoResponse struct {
+	Body ZapierTodo
+}
+
+// HumaZapierHandler Huma用のZapier連携ハンドラー
+type HumaZapierHandler struct {
+	triggerService service.TriggerService
+	todoService    service.TodoService
+	apiKeyHash     [32]byte
+}
+
+// NewHumaZapierHandler 新しいHuma Zapier連携ハンドラーインスタンスを作成
+func NewHumaZapierHandler(triggerService service.TriggerService, todoService service.TodoService, apiKey string) *HumaZapierHandler {
+	return &HumaZapierHandler{
+		triggerService: triggerService,
+		todoService:    todoService,
+		apiKeyHash:     sha256.Sum256([]byte(apiKey)),
+	}
+}
+
+// TestAuth APIキーの確認（Zapierの接続テスト）
+func (h *HumaZapierHandler) TestAuth(ctx context.Context, input *ZapierAuthRequest) (*ZapierAuthResponse, error) {
+	if err := h.authorize(input.APIKey); err != nil {
+		return nil, err
+	}
+
+	resp := &ZapierAuthResponse{}
+	resp.Body.OK = true
+	resp.Body.Label = "Todo API"
+	return resp, nil
+}
+
+// NewTodo new_todoトリガー（作成日時の新しい順）
+func (h *HumaZapierHandler) NewTodo(ctx context.Context, input *ZapierTriggerRequest) (*ZapierTriggerResponse, error) {
+	if err := h.authorize(input.APIKey); err != nil {
+		return nil, err
+	}
+
+	todos, err := h.triggerService.NewTodos(ctx, input.Limit)
+	if err != nil {
+		return nil, zapierError(err)
+	}
+
+	resp := &ZapierTriggerResponse{Body: make([]ZapierTodo, 0, len(todos))}
+	for _, todo := range todos {
+		item := newZapierTodo(todo)
+		item.ID = fmt.Sprint(todo.ID)
+		resp.Body = append(resp.Body, item)
+	}
+	return resp, nil
+}
+
+// CompletedTodo completed_todoトリガー（完了日時の新しい順）
+func (h *HumaZapierHandler) CompletedTodo(ctx context.Context, input *ZapierTriggerRequest) (*ZapierTriggerResponse, error) {
+	if err := h.authorize(input.APIKey); err != nil {
+		return nil, err
+	}
+
+	todos, err := h.triggerService.CompletedTodos(ctx, input.Limit)
+	if err != nil {
+		return nil, zapierError(err)
+	}
+
+	resp := &ZapierTriggerResponse{Body: make([]ZapierTodo, 0, len(todos))}
+	for _, todo := range todos {
+		item := newZapierTodo(todo)
+		// 未完了に戻して再度完了した場合も別のイベントとして扱われるよう、完了日時をキーに含める
+		item.ID = fmt.Sprintf("%d-%d", todo.ID, todo.CompletedAt.Unix())
+		resp.Body = append(resp.Body, item)
+	}
+	return resp, nil
+}
+
+// CreateTodo create_todoアクション
+func (h *HumaZapierHandler) CreateTodo(ctx context.Context, input *ZapierCreateTodoRequest) (*ZapierTodoResponse, error) {
+	if err := h.authorize(input.APIKey); err != nil {
+		return nil, err
+	}
+
+	todo, err := h.todoService.CreateTodo(ctx, &input.Body)
+	if err != nil {
+		if isServiceUnavailable(err) {
+			return nil, newError(http.StatusServiceUnavailable, err)
+		}
+		return nil, newError(http.StatusBadRequest, err)
+	}
+
+	item := newZapierTodo(todo)
+	item.ID = fmt.Sprint(todo.ID)
+	return &ZapierTodoResponse{Body: item}, nil
+}
+
+// authorize APIキーを検証（長さによる推測を防ぐためハッシュを定数時間で比較する）
+func (h *HumaZapierHandler) authorize(apiKey string) error {
+	hash := sha256.Sum256([]byte(apiKey))
+	if apiKey == "" || subtle.ConstantTimeCompare(hash[:], h.apiKeyHash[:]) != 1 {
+		return huma.Error401Unauthorized("APIキーが不正です")
+	}
+	return nil
+}
+
+// newZapierTodo TodoをZapierに返す形式に変換
+func newZapierTodo(todo *model.Todo) ZapierTodo {
+	tags := []string(todo.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return ZapierTodo{
+		TodoID:      todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority,
+		DueDate:     todo.DueDate,
+		Tags:        tags,
+		Completed:   todo.Completed,
+		CompletedAt: todo.CompletedAt,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	}
+}
+
+// zapierError サービスのエラーをHTTPステータスに対応付ける
+func zapierError(err error) error {
+	if isServiceUnavailable(err) {
+		return newError(http.StatusServiceUnavailable, err)
+	}
+	return newError(http.StatusInternalServerError, err)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"myapp/db/model"
 	"myapp/service"
@@ -77,18 +78,18 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
 	// フィルタリング処理
 	if priority := query.Get("priority"); priority != "" {
 		priorityEnum := model.Priority(priority)
-		todos, err = h.todoService.GetTodosByPriority(priorityEnum)
+		todos, err = h.todoService.GetTodosByPriority(r.Context(), priorityEnum)
 	} else if completed := query.Get("completed"); completed != "" {
 		if completed == "true" {
-			todos, err = h.todoService.GetCompletedTodos()
+			todos, err = h.todoService.GetCompletedTodos(r.Context())
 		} else if completed == "false" {
-			todos, err = h.todoService.GetPendingTodos()
+			todos, err = h.todoService.GetPendingTodos(r.Context())
 		} else {
 			h.sendErrorResponse(w, "completedパラメータはtrueまたはfalseである必要があります", http.StatusBadRequest)
 			return
 		}
 	} else {
-		todos, err = h.todoService.GetAllTodos()
+		todos, err = h.todoService.GetAllTodos(r.Context())
 	}
 
 	if err != nil {
@@ -99,7 +100,7 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
 	// TodoResponseに変換
 	responses := make([]*model.TodoResponse, len(todos))
 	for i, todo := range todos {
-		responses[i] = todo.ToResponse()
+		responses[i] = toTodoResponse(todo)
 	}
 
 	count := len(responses)
@@ -121,9 +122,9 @@ func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.GetTodoByID(uint(id))
+	todo, err := h.todoService.GetTodoByID(r.Context(), uint(id))
 	if err != nil {
-		if strings.Contains(err.Error(), "見つかりません") {
+		if errors.Is(err, service.ErrTodoNotFound) {
 			h.sendErrorResponse(w, err.Error(), http.StatusNotFound)
 		} else {
 			h.sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
@@ -131,7 +132,7 @@ func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.sendSuccessResponse(w, todo.ToResponse(), "Todoを取得しました", nil)
+	h.sendSuccessResponse(w, toTodoResponse(todo), "Todoを取得しました", nil)
 }
 
 // CreateTodo POST /todos - 新しいTodoを作成
@@ -149,14 +150,14 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.CreateTodo(&req)
+	todo, err := h.todoService.CreateTodo(r.Context(), &req)
 	if err != nil {
 		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	h.sendSuccessResponse(w, todo.ToResponse(), "Todoを作成しました", nil)
+	h.sendSuccessResponse(w, toTodoResponse(todo), "Todoを作成しました", nil)
 }
 
 // UpdateTodo PUT /todos/{id} - 既存のTodoを更新
@@ -180,9 +181,9 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.UpdateTodo(uint(id), &req)
+	todo, err := h.todoService.UpdateTodo(r.Context(), uint(id), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "見つかりません") {
+		if errors.Is(err, service.ErrTodoNotFound) {
 			h.sendErrorResponse(w, err.Error(), http.StatusNotFound)
 		} else {
 			h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
@@ -190,7 +191,7 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.sendSuccessResponse(w, todo.ToResponse(), "Todoを更新しました", nil)
+	h.sendSuccessResponse(w, toTodoResponse(todo), "Todoを更新しました", nil)
 }
 
 // DeleteTodo DELETE /todos/{id} - Todoを削除
@@ -208,9 +209,9 @@ func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.todoService.DeleteTodo(uint(id))
+	err = h.todoService.DeleteTodo(r.Context(), uint(id))
 	if err != nil {
-		if strings.Contains(err.Error(), "見つかりません") {
+		if errors.Is(err, service.ErrTodoNotFound) {
 			h.sendErrorResponse(w, err.Error(), http.StatusNotFound)
 		} else {
 			h.sendErrorResponse(w, err.Error(), http.StatusInternalServerError)
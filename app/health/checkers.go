@@ -0,0 +1,214 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/storage"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DBChecker データベース接続のチェッカー
+type DBChecker struct{}
+
+// Name 依存サービスの名前
+func (c *DBChecker) Name() string {
+	return "database"
+}
+
+// Critical DBはアプリケーションの動作に必須
+func (c *DBChecker) Critical() bool {
+	return true
+}
+
+// Check DBへのPingで接続を確認
+func (c *DBChecker) Check(ctx context.Context) error {
+	database := db.GetDB()
+	if database == nil {
+		return fmt.Errorf("データベース接続が初期化されていません")
+	}
+
+	if db.GetBreakerState() == db.BreakerOpen {
+		return db.ErrCircuitOpen
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// TCPChecker TCP接続の可否で状態を確認するチェッカー（Redisなど）
+type TCPChecker struct {
+	name     string
+	address  string
+	critical bool
+}
+
+// NewTCPChecker 新しいTCPチェッカーインスタンスを作成
+func NewTCPChecker(name, address string, critical bool) *TCPChecker {
+	return &TCPChecker{
+		name:     name,
+		address:  address,
+		critical: critical,
+	}
+}
+
+// Name 依存サービスの名前
+func (c *TCPChecker) Name() string {
+	return c.name
+}
+
+// Critical 失敗時に全体を異常とみなすか
+func (c *TCPChecker) Critical() bool {
+	return c.critical
+}
+
+// Check TCP接続を確立できるか確認
+func (c *TCPChecker) Check(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return fmt.Errorf("%s に接続できません: %w", c.address, err)
+	}
+	return conn.Close()
+}
+
+// HTTPChecker HTTPレスポンスで状態を確認するチェッカー（S3・LLMプロバイダーなど）
+type HTTPChecker struct {
+	name     string
+	url      string
+	critical bool
+	client   *http.Client
+}
+
+// NewHTTPChecker 新しいHTTPチェッカーインスタンスを作成
+func NewHTTPChecker(name, url string, critical bool) *HTTPChecker {
+	return &HTTPChecker{
+		name:     name,
+		url:      url,
+		critical: critical,
+		client:   &http.Client{},
+	}
+}
+
+// Name 依存サービスの名前
+func (c *HTTPChecker) Name() string {
+	return c.name
+}
+
+// Critical 失敗時に全体を異常とみなすか
+func (c *HTTPChecker) Critical() bool {
+	return c.critical
+}
+
+// Check エンドポイントが5xx以外を返すか確認（認証エラーでも到達性は確認できるため許容）
+func (c *HTTPChecker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s に接続できません: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s がステータス %d を返しました", c.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// StorageChecker ストレージ（ローカルディスク・S3・GCS）への到達性と権限を確認するチェッカー
+type StorageChecker struct {
+	storage storage.Storage
+}
+
+// NewStorageChecker 新しいストレージチェッカーインスタンスを作成
+func NewStorageChecker(s storage.Storage) *StorageChecker {
+	return &StorageChecker{storage: s}
+}
+
+// Name 依存サービスの名前
+func (c *StorageChecker) Name() string {
+	return "storage"
+}
+
+// Critical ストレージは添付ファイル・エクスポート等の一部の機能でのみ使うため必須としない
+func (c *StorageChecker) Critical() bool {
+	return false
+}
+
+// Check 存在しないことが多いプレフィックスの一覧を取得できるか確認（バケットの不在・権限不足を検出する）
+func (c *StorageChecker) Check(ctx context.Context) error {
+	if _, err := c.storage.List(ctx, "healthcheck/"); err != nil {
+		return fmt.Errorf("%sストレージにアクセスできません: %w", c.storage.Driver(), err)
+	}
+	return nil
+}
+
+// QueueChecker ジョブキュー（queue_jobs）に止まっているジョブ・デッドレターがないかを確認するチェッカー
+type QueueChecker struct {
+	// backlog 実行予定の日時をこの時間以上過ぎても実行されていないジョブを滞留とみなす
+	backlog time.Duration
+	// deadWindow この期間内にデッドレターになったジョブを異常とみなす
+	deadWindow time.Duration
+}
+
+// NewQueueChecker 新しいジョブキューのチェッカーインスタンスを作成
+func NewQueueChecker(backlog, deadWindow time.Duration) *QueueChecker {
+	return &QueueChecker{
+		backlog:    backlog,
+		deadWindow: deadWindow,
+	}
+}
+
+// Name 依存サービスの名前
+func (c *QueueChecker) Name() string {
+	return "job-queue"
+}
+
+// Critical ジョブキューの異常はWebhook・メールの送信等の非同期処理にのみ影響するため必須としない
+func (c *QueueChecker) Critical() bool {
+	return false
+}
+
+// Check ロックの期限が切れた実行中のジョブ（ワーカーの異常終了）・滞留している実行待ちのジョブ・直近のデッドレターを数える
+func (c *QueueChecker) Check(ctx context.Context) error {
+	database := db.GetDB()
+	if database == nil {
+		return fmt.Errorf("データベース接続が初期化されていません")
+	}
+
+	now := time.Now()
+	var counts struct {
+		Expired int64
+		Backlog int64
+		Dead    int64
+	}
+	err := database.WithContext(ctx).Raw(`SELECT
+		COUNT(*) FILTER (WHERE status = ? AND locked_until < ?) AS expired,
+		COUNT(*) FILTER (WHERE status = ? AND run_at < ?) AS backlog,
+		COUNT(*) FILTER (WHERE status = ? AND finished_at >= ?) AS dead
+	FROM queue_jobs WHERE status IN ?`,
+		model.QueueJobRunning, now,
+		model.QueueJobPending, now.Add(-c.backlog),
+		model.QueueJobDead, now.Add(-c.deadWindow),
+		[]model.QueueJobStatus{model.QueueJobRunning, model.QueueJobPending, model.QueueJobDead},
+	).Scan(&counts).Error
+	if err != nil {
+		return fmt.Errorf("ジョブキューの状態を取得できません: %w", err)
+	}
+
+	if counts.Expired > 0 || counts.Backlog > 0 || counts.Dead > 0 {
+		return fmt.Errorf("ジョブキューに止まっているジョブがあります（ロックの期限切れ: %d件 / %s以上の滞留: %d件 / 直近%sのデッドレター: %d件）",
+			counts.Expired, c.backlog, counts.Backlog, c.deadWindow, counts.Dead)
+	}
+	return nil
+}
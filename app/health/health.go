@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ステータス
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	StatusDegraded  = "degraded"
+)
+
+// Checker 依存サービスの状態を確認するインターフェース
+type Checker interface {
+	// Name 依存サービスの名前
+	Name() string
+	// Critical 失敗時にアプリケーション全体を異常とみなすか
+	Critical() bool
+	// Check 状態を確認し、異常があればエラーを返す
+	Check(ctx context.Context) error
+}
+
+// CheckResult 依存サービスごとのチェック結果
+type CheckResult struct {
+	Name      string  `json:"name" doc:"依存サービス名"`
+	Status    string  `json:"status" doc:"ステータス" enum:"healthy,unhealthy"`
+	Critical  bool    `json:"critical" doc:"失敗時に全体を異常とみなすか"`
+	LatencyMs float64 `json:"latency_ms" doc:"チェックに要した時間（ミリ秒）"`
+	Error     string  `json:"error,omitempty" doc:"エラー内容"`
+}
+
+// Aggregator 複数の依存サービスのチェックをまとめて実行する
+type Aggregator struct {
+	mu       sync.RWMutex
+	checkers []Checker
+	timeout  time.Duration
+}
+
+// NewAggregator 新しいアグリゲーターインスタンスを作成
+func NewAggregator(timeout time.Duration) *Aggregator {
+	return &Aggregator{
+		timeout: timeout,
+	}
+}
+
+// Register チェッカーを登録
+func (a *Aggregator) Register(checker Checker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.checkers = append(a.checkers, checker)
+}
+
+// CheckAll 登録済みの全チェッカーを並列に実行し、全体のステータスと個別結果を返す
+// 重要な依存先の失敗はunhealthy、それ以外の失敗はdegradedとなる
+func (a *Aggregator) CheckAll(ctx context.Context) (string, []*CheckResult) {
+	a.mu.RLock()
+	checkers := make([]Checker, len(a.checkers))
+	copy(checkers, a.checkers)
+	a.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	results := make([]*CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	status := StatusHealthy
+	for _, result := range results {
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if result.Critical {
+			status = StatusUnhealthy
+		} else if status == StatusHealthy {
+			status = StatusDegraded
+		}
+	}
+
+	return status, results
+}
+
+// run 単一のチェッカーを実行して所要時間を計測
+func run(ctx context.Context, checker Checker) *CheckResult {
+	start := time.Now()
+	err := checker.Check(ctx)
+
+	result := &CheckResult{
+		Name:      checker.Name(),
+		Status:    StatusHealthy,
+		Critical:  checker.Critical(),
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Probe Kubernetes向けのliveness/readinessの状態を管理する
+type Probe struct {
+	aggregator   *Aggregator
+	migrated     atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+// NewProbe 新しいプローブインスタンスを作成
+func NewProbe(aggregator *Aggregator) *Probe {
+	return &Probe{
+		aggregator: aggregator,
+	}
+}
+
+// MarkMigrated マイグレーションの完了を記録
+func (p *Probe) MarkMigrated() {
+	p.migrated.Store(true)
+}
+
+// MarkShuttingDown シャットダウンの開始を記録（以降readinessは失敗する）
+func (p *Probe) MarkShuttingDown() {
+	p.shuttingDown.Store(true)
+}
+
+// Ready トラフィックを受け付けられるか判定し、受け付けられない理由を返す
+func (p *Probe) Ready(ctx context.Context) (bool, []string) {
+	var reasons []string
+
+	if p.shuttingDown.Load() {
+		reasons = append(reasons, "シャットダウン中です")
+	}
+	if !p.migrated.Load() {
+		reasons = append(reasons, "マイグレーションが完了していません")
+	}
+
+	if status, results := p.aggregator.CheckAll(ctx); status == StatusUnhealthy {
+		for _, result := range results {
+			if result.Critical && result.Status != StatusHealthy {
+				reasons = append(reasons, result.Name+": "+result.Error)
+			}
+		}
+	}
+
+	return len(reasons) == 0, reasons
+}
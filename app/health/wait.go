@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Backoff 起動時に依存サービスを待つ間の再試行の間隔（失敗のたびに倍増し、Maxで頭打ち）
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// WaitFor 依存サービスに接続できるまで指数バックオフでtryを再試行する
+// コンテナの起動順によってDB等がまだ起動していない場合に即座に終了しないためのもので、ctxの期限を過ぎた場合は最後のエラーを返す
+func WaitFor(ctx context.Context, name string, backoff Backoff, try func(ctx context.Context) error) error {
+	delay := backoff.Base
+	for attempt := 1; ; attempt++ {
+		err := try(ctx)
+		if err == nil {
+			if attempt > 1 {
+				slog.Info("依存サービスに接続できました", "dependency", name, "attempts", attempt)
+			}
+			return nil
+		}
+
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) < delay {
+			return fmt.Errorf("%sに接続できません（%d回試行）: %w", name, attempt, err)
+		}
+		slog.Warn("依存サービスに接続できないため再試行します", "dependency", name, "attempt", attempt, "retry_in", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%sに接続できません（%d回試行）: %w", name, attempt, err)
+		case <-timer.C:
+		}
+		delay = min(delay*2, backoff.Max)
+	}
+}
@@ -0,0 +1,194 @@
+// Package httpcache 一覧・統計のGETレスポンスのHTTPキャッシュ制御（Cache-Control・ETag・条件付きリクエスト）
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"myapp/config"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kind 対象のエンドポイントの種類
+type kind int
+
+const (
+	// kindList Todoの一覧（Todoの更新イベントで検証済みのETagを無効化する）
+	kindList kind = iota
+	// kindStats 統計（集計時刻を含むため、max-ageの間だけクライアントに再利用させる）
+	kindStats
+)
+
+// routes キャッシュ制御の対象のパス
+var routes = map[string]kind{
+	"/api/v1/todos":             kindList,
+	"/api/v1/todos/stale":       kindList,
+	"/api/v1/admin/db/stats":    kindStats,
+	"/api/v1/admin/queue/stats": kindStats,
+}
+
+// maxEntries 検証済みのETagを保持する上限（超えた場合は全て破棄する）
+const maxEntries = 10000
+
+// entry 検証済みのETag（この世代の間は同じETagの条件付きリクエストにハンドラーを実行せず304を返す）
+type entry struct {
+	etag       string
+	generation uint64
+	expires    time.Time
+}
+
+var (
+	mu         sync.Mutex
+	generation uint64
+	validated  = map[string]entry{}
+)
+
+// Invalidate Todoが作成・更新・削除されたときに呼び、検証済みのETagを全て無効化する
+// 検証済みのETagはプロセス内に保持し、無効化も他のインスタンスには伝わらない（http_cache.revalidate_ttl は単一インスタンス専用）
+// 以降の条件付きリクエストはハンドラーを実行し、本文から計算したETagで比較する
+func Invalidate() {
+	mu.Lock()
+	defer mu.Unlock()
+	generation++
+	validated = map[string]entry{}
+}
+
+// Middleware 対象のGETリクエストの成功レスポンスにETag・Cache-Controlを付け、If-None-Matchが一致する場合は304を返すミドルウェア
+// ETagは本文のハッシュから計算する（圧縮の有無で変わらないよう弱いETag）
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Current().HTTPCache
+		k, ok := routes[r.URL.Path]
+		if !cfg.Enabled || !ok || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// max-ageが0の場合は毎回ETagで再検証させる
+		maxAge := cfg.MaxAge
+		if k == kindStats {
+			maxAge = cfg.StatsMaxAge
+		}
+		cacheControl := "private, no-cache"
+		if maxAge > 0 {
+			cacheControl = fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+		}
+
+		key := cacheKey(r)
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		mu.Lock()
+		gen := generation
+		cached, hit := validated[key]
+		mu.Unlock()
+		if k == kindList && hit && ifNoneMatch != "" && cached.generation == gen && time.Now().Before(cached.expires) && matches(ifNoneMatch, cached.etag) {
+			notModified(w, cached.etag, cacheControl)
+			return
+		}
+
+		rec := &recorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for name, values := range rec.header {
+			w.Header()[name] = values
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		if k == kindList && cfg.RevalidateTTL > 0 {
+			remember(key, entry{etag: etag, generation: gen, expires: time.Now().Add(cfg.RevalidateTTL)})
+		}
+
+		if ifNoneMatch != "" && matches(ifNoneMatch, etag) {
+			notModified(w, etag, cacheControl)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", cacheControl)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// remember 検証済みのETagを保存（ハンドラーの実行中に無効化された場合は保存しない）
+func remember(key string, e entry) {
+	mu.Lock()
+	defer mu.Unlock()
+	if e.generation != generation {
+		return
+	}
+	if len(validated) >= maxEntries {
+		validated = map[string]entry{}
+	}
+	validated[key] = e
+}
+
+// notModified 本文なしの304を返す
+func notModified(w http.ResponseWriter, etag, cacheControl string) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", cacheControl)
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// cacheKey レスポンスを区別するキー（クエリ・認証情報・表現の交渉に使うヘッダーを含め、値はハッシュにして保持する）
+func cacheKey(r *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{
+		r.URL.Path,
+		r.URL.RawQuery,
+		r.Header.Get("Authorization"),
+		r.Header.Get("Cookie"),
+		r.Header.Get("Accept"),
+		r.Header.Get("Accept-Language"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// matches If-None-Matchのカンマ区切りのETagの一覧に指定したETagが含まれるか（弱い比較）
+func matches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder ETagを計算するためにレスポンスをバッファするResponseWriter
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header ヘッダーを返す
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader ステータスコードを記録
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+// Write 本文をバッファに書き込む
+func (r *recorder) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
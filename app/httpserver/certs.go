@@ -0,0 +1,160 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// certReloader 証明書・クライアントCAをファイルから読み込み、更新されていれば自動で再読み込みする
+// ハンドシェイク時に最大でinterval間隔でファイルの更新時刻を確認する（証明書の差し替え・ローテーション用）
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+// newCertReloader ファイルを読み込んでcertReloaderを作成（certFileが空の場合はクライアントCAのみを扱う）
+func newCertReloader(certFile, keyFile, caFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: interval,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load ファイルを読み込んで差し替える
+func (r *certReloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		loaded, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("証明書の読み込みに失敗しました: %w", err)
+		}
+		cert = &loaded
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("クライアントCAの読み込みに失敗しました: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("クライアントCAファイルに有効なPEM証明書がありません")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.clientCAs = pool
+	r.modTimes = modTimes
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// maybeReload 前回の確認からinterval以上経過し、ファイルが更新されていれば再読み込みする
+// 読み込みに失敗した場合は現在の証明書を使い続ける
+func (r *certReloader) maybeReload() {
+	if r.interval <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	if time.Since(r.checkedAt) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.checkedAt = time.Now()
+	modTimes := r.modTimes
+	r.mu.Unlock()
+
+	changed := false
+	for path, modTime := range modTimes {
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		slog.Error("証明書の再読み込みに失敗しました（現在の証明書を使い続けます）", "error", err)
+		return
+	}
+	slog.Info("証明書を再読み込みしました", "cert_file", r.certFile, "client_ca_file", r.caFile)
+}
+
+// GetCertificate tls.Config.GetCertificate の実装
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// ConfigForClient クライアントCAを最新の内容に差し替えたtls.Configを返す関数を作成
+func (r *certReloader) ConfigForClient(base *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.maybeReload()
+
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientCAs = r.clientCAs
+		return cfg, nil
+	}
+}
+
+// parseClientAuth 設定値をtls.ClientAuthTypeに変換
+func parseClientAuth(value string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(value) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("未対応のクライアント認証モードです: %s", value)
+	}
+}
@@ -0,0 +1,24 @@
+package httpserver
+
+import (
+	"myapp/config"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// NewServer 設定の待ち受けアドレス・ポートとタイムアウトでHTTPサーバーを作成
+func NewServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         Addr(cfg, cfg.Port),
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// Addr 設定の待ち受けアドレスとportを組み合わせたアドレス（待ち受けアドレスが空の場合は「:port」）
+func Addr(cfg config.ServerConfig, port int) string {
+	return net.JoinHostPort(cfg.BindAddress, strconv.Itoa(port))
+}
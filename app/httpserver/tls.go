@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"myapp/config"
+	"net"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ConfigureTLS 設定に応じてサーバーにTLS（クライアント証明書の検証を含む）を設定し、HTTP→HTTPSリダイレクト用のサーバーを返す
+// TLSが無効、またはリダイレクトポート未指定の場合はnilを返す
+func ConfigureTLS(srv *http.Server, cfg config.ServerConfig) (*http.Server, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil
+	}
+
+	clientAuth, err := parseClientAuth(cfg.TLSClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	var redirect http.Handler = redirectHandler(cfg.Port)
+
+	if len(cfg.AutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		srv.TLSConfig = manager.TLSConfig()
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		// HTTP-01チャレンジに応答し、それ以外はHTTPSへリダイレクト
+		redirect = manager.HTTPHandler(redirect)
+		slog.Info("Let's Encryptによる証明書の自動取得を有効にしました", "domains", cfg.AutocertDomains)
+	}
+
+	// 証明書ファイル・クライアントCAは更新を検知して再読み込みする
+	certFile, keyFile := cfg.TLSCertFile, cfg.TLSKeyFile
+	if len(cfg.AutocertDomains) > 0 {
+		certFile, keyFile = "", ""
+	}
+	if certFile != "" || cfg.TLSClientCAFile != "" {
+		reloader, err := newCertReloader(certFile, keyFile, cfg.TLSClientCAFile, cfg.TLSReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: reloader.GetCertificate,
+			}
+		}
+		if cfg.TLSClientCAFile != "" {
+			srv.TLSConfig.ClientAuth = clientAuth
+			srv.TLSConfig.GetConfigForClient = reloader.ConfigForClient(srv.TLSConfig)
+			slog.Info("クライアント証明書の検証（mTLS）を有効にしました", "client_auth", cfg.TLSClientAuth)
+		}
+	}
+
+	if cfg.HTTPRedirectPort == 0 {
+		return nil, nil
+	}
+
+	return &http.Server{
+		Addr:    Addr(cfg, cfg.HTTPRedirectPort),
+		Handler: redirect,
+	}, nil
+}
+
+// ListenAndServe 設定に応じてHTTPまたはHTTPSで配信する（unix_socket指定時はTCPのポートの代わりにUnixドメインソケットで待ち受ける）
+func ListenAndServe(srv *http.Server, cfg config.ServerConfig) error {
+	if cfg.UnixSocket != "" {
+		listener, err := listenUnix(cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.TLSEnabled() {
+			return srv.ServeTLS(listener, "", "")
+		}
+		return srv.Serve(listener)
+	}
+
+	switch {
+	case cfg.TLSEnabled():
+		// 証明書はTLSConfig.GetCertificateで取得する（autocertまたはファイルの再読み込み）
+		return srv.ListenAndServeTLS("", "")
+	default:
+		return srv.ListenAndServe()
+	}
+}
+
+// redirectHandler 同じホスト・パスのHTTPS URLへ恒久リダイレクトする
+func redirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"myapp/config"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// listenUnix Unixドメインソケットで待ち受け、ソケットファイルのパーミッションを設定する
+// 前回の異常終了で残ったソケットファイルは削除するが、他のプロセスが待ち受けているソケットと通常のファイルは削除しない
+func listenUnix(cfg config.ServerConfig) (net.Listener, error) {
+	mode, err := strconv.ParseUint(cfg.UnixSocketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("ソケットファイルのパーミッションが不正です: %q", cfg.UnixSocketMode)
+	}
+
+	if err := removeStaleSocket(cfg.UnixSocket); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", cfg.UnixSocket)
+	if err != nil {
+		return nil, fmt.Errorf("Unixドメインソケットで待ち受けできません: %w", err)
+	}
+	// 作成時のパーミッションはumaskに依存するため、待ち受けた後に設定する
+	if err := os.Chmod(cfg.UnixSocket, fs.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("ソケットファイルのパーミッションを設定できません: %w", err)
+	}
+	return listener, nil
+}
+
+// removeStaleSocket 待ち受けているプロセスがいないソケットファイルを削除する
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("ソケットファイルのパスに別のファイルがあります: %s", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("ソケットファイルは他のプロセスが使用しています: %s", path)
+	}
+	return os.Remove(path)
+}
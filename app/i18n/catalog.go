@@ -0,0 +1,160 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxDepth ラップされたエラーのメッセージを翻訳する深さの上限
+const maxDepth = 8
+
+// pattern 書式指定子を含むメッセージの翻訳
+type pattern struct {
+	// re 組み立て済みのメッセージに一致する正規表現（書式指定子ごとにサブマッチ）
+	re *regexp.Regexp
+	// prefix 最初の書式指定子より前の固定部分（正規表現を試す前の絞り込みに使う）
+	prefix string
+	// verbs 書式指定子の種類（引数の順）
+	verbs []byte
+	// template 翻訳先の書式（書式指定子を全て%sに置き換えたもの）
+	template string
+}
+
+// catalog 1言語分のメッセージカタログ
+type catalog struct {
+	exact    map[string]string
+	patterns []*pattern
+}
+
+// catalogs 言語ごとのカタログ（日本語はキーそのままのためカタログを持たない）
+var catalogs = map[Lang]*catalog{
+	English: newCatalog(messagesEN),
+}
+
+// Translate 組み立て済みの日本語のメッセージをlangに翻訳する
+// カタログにないメッセージはそのまま返す。%w・%s・%v で埋め込まれた部分（ラップしたエラー等）も翻訳する
+func Translate(lang Lang, msg string) string {
+	c := catalogs[lang]
+	if c == nil || msg == "" {
+		return msg
+	}
+	return c.translate(msg, 0)
+}
+
+func (c *catalog) translate(msg string, depth int) string {
+	if translated, ok := c.exact[msg]; ok {
+		return translated
+	}
+	if depth >= maxDepth {
+		return msg
+	}
+	for _, p := range c.patterns {
+		if !strings.HasPrefix(msg, p.prefix) {
+			continue
+		}
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		args := make([]any, len(p.verbs))
+		for i, verb := range p.verbs {
+			arg := m[i+1]
+			if verb == 's' || verb == 'v' || verb == 'w' {
+				arg = c.translate(arg, depth+1)
+			}
+			args[i] = arg
+		}
+		return fmt.Sprintf(p.template, args...)
+	}
+	return msg
+}
+
+// newCatalog 日本語の書式→翻訳先の書式の対応からカタログを作る
+// 翻訳先は同じ書式指定子を同じ順に含めること（異なる場合は起動時にpanicする）
+func newCatalog(messages map[string]string) *catalog {
+	c := &catalog{exact: map[string]string{}}
+	for key, translated := range messages {
+		re, prefix, verbs := compile(key)
+		if len(verbs) == 0 {
+			c.exact[strings.ReplaceAll(key, "%%", "%")] = strings.ReplaceAll(translated, "%%", "%")
+			continue
+		}
+		template, targetVerbs := toTemplate(translated)
+		if string(verbs) != string(targetVerbs) {
+			panic(fmt.Sprintf("i18n: %q と %q の書式指定子が一致しません", key, translated))
+		}
+		c.patterns = append(c.patterns, &pattern{re: re, prefix: prefix, verbs: verbs, template: template})
+	}
+
+	// 固定部分の長い（より限定的な）書式を先に試す
+	sort.Slice(c.patterns, func(i, j int) bool {
+		a, b := c.patterns[i], c.patterns[j]
+		if len(a.prefix) != len(b.prefix) {
+			return len(a.prefix) > len(b.prefix)
+		}
+		if la, lb := len(a.re.String()), len(b.re.String()); la != lb {
+			return la > lb
+		}
+		return a.re.String() < b.re.String()
+	})
+	return c
+}
+
+// compile 書式文字列から、組み立て済みのメッセージに一致する正規表現を作る
+func compile(format string) (*regexp.Regexp, string, []byte) {
+	var b, literal strings.Builder
+	var prefix string
+	var verbs []byte
+	b.WriteString(`(?s)^`)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			literal.WriteByte(format[i])
+			continue
+		}
+		i++
+		verb := format[i]
+		if verb == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+		if verbs == nil {
+			prefix = literal.String()
+		}
+		b.WriteString(regexp.QuoteMeta(literal.String()))
+		literal.Reset()
+		switch verb {
+		case 'd':
+			b.WriteString(`(-?\d+)`)
+		case 'q':
+			b.WriteString(`("(?:[^"\\]|\\.)*")`)
+		default:
+			b.WriteString(`(.*?)`)
+		}
+		verbs = append(verbs, verb)
+	}
+	b.WriteString(regexp.QuoteMeta(literal.String()))
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String()), prefix, verbs
+}
+
+// toTemplate 翻訳先の書式の書式指定子を%sに置き換える（引数はサブマッチの文字列で渡すため）
+func toTemplate(format string) (string, []byte) {
+	var b strings.Builder
+	var verbs []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if format[i] == '%' {
+			b.WriteString("%%")
+			continue
+		}
+		b.WriteString("%s")
+		verbs = append(verbs, format[i])
+	}
+	return b.String(), verbs
+}
@@ -0,0 +1,99 @@
+// Package i18n レスポンスのメッセージ・エラーの国際化（Accept-Languageによる日本語/英語の切り替え）
+// メッセージは日本語の書式（fmt.Sprintfの書式文字列）をキーとしてカタログを引く。
+// ハンドラー・サービスはこれまでどおり日本語でメッセージを組み立て、レスポンスを返す直前に翻訳する
+package i18n
+
+import (
+	"context"
+	"myapp/config"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang レスポンスの言語
+type Lang string
+
+const (
+	// Japanese 日本語（既定。カタログのキーそのまま）
+	Japanese Lang = "ja"
+	// English 英語
+	English Lang = "en"
+)
+
+// Default コンテキストに言語が設定されていない場合の言語（カタログのキーの言語）
+const Default = Japanese
+
+type contextKey struct{}
+
+// Middleware Accept-Languageからレスポンスの言語を決め、コンテキストに設定するミドルウェア
+// 言語によって本文が変わるため、Content-Language と Vary: Accept-Language を付与する
+// Accept-Languageで決まらない場合の言語は設定（locale.default）に従い、config.Current() を参照するためホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Negotiate(r.Header.Get("Accept-Language"), Lang(config.Current().Locale.Default))
+		w.Header().Set("Content-Language", string(lang))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), lang)))
+	})
+}
+
+// NewContext 言語を設定したコンテキストを返す
+func NewContext(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext コンテキストからレスポンスの言語を取得（設定されていなければ Default）
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// T メッセージをコンテキストの言語に翻訳する（msgは組み立て済みの日本語のメッセージ）
+func T(ctx context.Context, msg string) string {
+	return Translate(FromContext(ctx), msg)
+}
+
+// Negotiate Accept-Languageのうち、対応している言語で品質値（q）が最も高いものを返す
+// 同じ品質値の場合は先に書かれた言語を優先し、ワイルドカード（*）・対応している言語がない場合はfallbackを返す
+func Negotiate(header string, fallback Lang) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		// 地域などのサブタグ（en-US、ja-JP）は無視して主言語で判定する
+		primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+		switch strings.ToLower(primary) {
+		case string(Japanese):
+			candidates = append(candidates, candidate{Japanese, q})
+		case string(English):
+			candidates = append(candidates, candidate{English, q})
+		case "*":
+			candidates = append(candidates, candidate{fallback, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"net/http"
+	"unicode"
+)
+
+// Key 翻訳テーブルで引く汎用メッセージのキー
+type Key string
+
+const (
+	// KeyValidationFailed リクエストの内容が正しくない（400・422）
+	KeyValidationFailed Key = "error.validation_failed"
+	// KeyNotFound リソースが見つからない（404）
+	KeyNotFound Key = "error.not_found"
+	// KeyInternal 内部エラー（500等）
+	KeyInternal Key = "error.internal"
+	// KeyServiceUnavailable 一時的に利用できない（503）
+	KeyServiceUnavailable Key = "error.service_unavailable"
+)
+
+// keyMessages キーごと・言語ごとのメッセージ
+var keyMessages = map[Key]map[Lang]string{
+	KeyValidationFailed: {
+		Japanese: "リクエストの内容が正しくありません",
+		English:  "The request is invalid",
+	},
+	KeyNotFound: {
+		Japanese: "リソースが見つかりません",
+		English:  "Resource not found",
+	},
+	KeyInternal: {
+		Japanese: "内部エラーが発生しました",
+		English:  "An internal error occurred",
+	},
+	KeyServiceUnavailable: {
+		Japanese: "一時的に利用できません",
+		English:  "The service is temporarily unavailable",
+	},
+}
+
+// Message キーに対応するlangのメッセージ（langのメッセージがない場合は日本語）
+func Message(lang Lang, key Key) string {
+	messages := keyMessages[key]
+	if msg, ok := messages[lang]; ok {
+		return msg
+	}
+	return messages[Japanese]
+}
+
+// TranslateError エラーレスポンスのメッセージをlangに翻訳する
+// 日本語以外でカタログにないメッセージ（日本語が残るもの）は、バリデーション・404・5xxに限りステータスに応じた汎用メッセージに置き換える
+// それ以外のステータス（401・409等）は個別のメッセージに意味があるため、翻訳できない場合もそのまま返す
+func TranslateError(lang Lang, status int, msg string) string {
+	translated := Translate(lang, msg)
+	if lang == Japanese || !containsJapanese(translated) {
+		return translated
+	}
+
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return Message(lang, KeyValidationFailed)
+	case status == http.StatusNotFound:
+		return Message(lang, KeyNotFound)
+	case status == http.StatusServiceUnavailable:
+		return Message(lang, KeyServiceUnavailable)
+	case status >= http.StatusInternalServerError:
+		return Message(lang, KeyInternal)
+	}
+	return translated
+}
+
+// containsJapanese 日本語（ひらがな・カタカナ・漢字）を含むか
+func containsJapanese(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+			return true
+		}
+	}
+	return false
+}
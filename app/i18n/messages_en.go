@@ -0,0 +1,380 @@
+package i18n
+
+// messagesEN 日本語の書式 → 英語の書式
+// 書式指定子は日本語と同じものを同じ順に含める（語順の都合で並べ替えることはできない）
+var messagesEN = map[string]string{
+	// 共通・ミドルウェア
+	"内部エラーが発生しました": "An internal error occurred",
+	"リソースが見つかりません": "Resource not found",
+	"リクエストにスキーマで定義されていないフィールドが含まれています":  "The request contains fields that are not defined in the schema",
+	"リクエストボディを読み込めません":                  "Unable to read the request body",
+	"リクエストボディが大きすぎます":                   "The request body is too large",
+	"リクエストボディが大きすぎます（上限: %d バイト）":       "The request body is too large (limit: %d bytes)",
+	"リクエストがタイムアウトしました（%s）":              "The request timed out (%s)",
+	"リクエストが多すぎます。しばらくしてから再試行してください":     "Too many requests. Please try again later",
+	"一時的にリクエストを受け付けられません":               "Temporarily unable to accept requests",
+	"このIPアドレスからのアクセスは許可されていません":         "Access from this IP address is not allowed",
+	"管理APIの認証に失敗しました":                   "Admin API authentication failed",
+	"CSRFトークンが無効です":                     "Invalid CSRF token",
+	"CSRFトークンを生成できません":                  "Unable to generate a CSRF token",
+	"CSRFトークンを取得しました":                   "Retrieved the CSRF token",
+	"セッションを読み込めません":                     "Unable to load the session",
+	"セッションを作成できません":                     "Unable to create a session",
+	"リクエストを検証できません":                     "Unable to verify the request",
+	"nonceは既に使用されています":                  "The nonce has already been used",
+	"nonceがないか、長さが不正です":                 "The nonce is missing or has an invalid length",
+	"署名が一致しません":                         "The signature does not match",
+	"署名ヘッダーがありません":                      "The signature header is missing",
+	"署名時刻が許容範囲外です":                      "The signature timestamp is outside the allowed window",
+	"メンテナンス中のため一時的に利用できません":             "Temporarily unavailable due to maintenance",
+	"未登録のフィーチャーフラグです":                   "Unknown feature flag",
+	"シャットダウン中です":                        "The server is shutting down",
+	"マイグレーションが完了していません":                 "Migrations have not completed",
+	"APIキーが不正です":                        "Invalid API key",
+	"見つかりません":                           "Not found",
+	"指定されたパスは存在しません":                    "The requested path does not exist",
+	"%s メソッドはこのパスでは使用できません":             "The %s method is not allowed for this path",
+	"データベース接続が初期化されていません":               "The database connection has not been initialized",
+	"データベース接続に問題があります":                  "There is a problem with the database connection",
+	"データベースが一時的に利用できません（サーキットブレーカー作動中）": "The database is temporarily unavailable (circuit breaker open)",
+	"カーソルが正しくありません":                     "Invalid cursor",
+	"cursorはlimitと併せて指定してください":          "cursor must be specified together with limit",
+	"タイムゾーンを読み込めません":                    "Unable to load the time zone",
+	"一覧の並び順が正しくありません":                   "Invalid list sort order",
+	"無効なタイムゾーンです: %s":                   "Invalid time zone: %s",
+	"無効なテナントIDです: %s":                   "Invalid tenant ID: %s",
+	"未対応の形式です: %s":                      "Unsupported format: %s",
+	"%w（%d件、上限: %d件）":                   "%w (%d items, limit: %d)",
+	"入力内容に%d件の誤りがあります":                  "The request has %d invalid fields",
+	"%w: 有効期限を過ぎています":                   "%w: it has expired",
+	"設定を再読み込みしました":                      "Reloaded the configuration",
+	"設定の検証に失敗しました。現在の設定を維持します":          "Configuration validation failed. Keeping the current configuration",
+	"問題は検出されませんでした":                     "No problems were detected",
+	"問題が検出されました":                        "Problems were detected",
+
+	// Todo
+	"Todoが見つかりません":           "Todo not found",
+	"ID %d のTodoが見つかりません":    "Todo with ID %d not found",
+	"ID %d のTodoを削除しました":     "Deleted the todo with ID %d",
+	"Todoを作成しました":            "Created the todo",
+	"Todoを取得しました":            "Retrieved the todo",
+	"Todoを更新しました":            "Updated the todo",
+	"Todoリストを取得しました":         "Retrieved the todo list",
+	"%d件のTodoを作成しました":        "Created %d todos",
+	"Todoのエクスポートを中断しました":     "Aborted the todo export",
+	"タイトルは必須です":              "Title is required",
+	"タイトルは%d文字以内で指定してください":   "Title must be at most %d characters",
+	"無効な優先度です":               "Invalid priority",
+	"タグが正しくありません":            "Invalid tag",
+	"タグが多すぎます":               "Too many tags",
+	"タグは%d個までです":             "Up to %d tags are allowed",
+	"タグは%d文字以内で指定してください: %s": "Tags must be at most %d characters: %s",
+	"無効な優先度です: %s":           "Invalid priority: %s",
+	"無効な繰り返しルールです: %w":       "Invalid recurrence rule: %w",
+	"繰り返しTodoには期限を指定してください":  "A due date is required for recurring todos",
+	"終日の期限にするかを切り替える場合は期限も指定してください": "due_date is required when changing due_all_day",
+	"期限が過去の日時です":                      "The due date is in the past",
+	"%d回先までに該当する日付がありません":             "No matching date within the next %d occurrences",
+	"Todoの作成に失敗しました: %w":              "Failed to create the todo: %w",
+	"Todoの一括作成に失敗しました: %w":            "Failed to create todos in bulk: %w",
+	"Todoの取得に失敗しました: %w":              "Failed to fetch todos: %w",
+	"Todoの更新に失敗しました: %w":              "Failed to update the todo: %w",
+	"Todoの削除に失敗しました: %w":              "Failed to delete the todo: %w",
+	"Todoの件数の取得に失敗しました: %w":           "Failed to count todos: %w",
+	"Todoの推定件数の取得に失敗しました: %w":         "Failed to estimate the number of todos: %w",
+	"Todoの推定件数の取得に失敗しました: 実行計画を読めません": "Failed to estimate the number of todos: unable to read the query plan",
+	"Todoのエクスポートに失敗しました: %w":          "Failed to export todos: %w",
+	"優先度 %s のTodo取得に失敗しました: %w":       "Failed to fetch todos with priority %s: %w",
+	"完了済みTodoの取得に失敗しました: %w":          "Failed to fetch completed todos: %w",
+	"未完了Todoの取得に失敗しました: %w":           "Failed to fetch incomplete todos: %w",
+	"削除されたTodoの取得に失敗しました: %w":         "Failed to fetch deleted todos: %w",
+	"削除済みTodoのパージに失敗しました: %w":         "Failed to purge deleted todos: %w",
+	"対象のTodoの取得に失敗しました: %w":           "Failed to fetch the target todo: %w",
+	"既存のTodoの確認に失敗しました: %w":           "Failed to check existing todos: %w",
+	"繰り返しTodoの取得に失敗しました: %w":          "Failed to fetch recurring todos: %w",
+	"Todo %d の次回のTodoの生成に失敗しました: %w":  "Failed to create the next occurrence of todo %d: %w",
+	"放置タスクを取得しました":                    "Retrieved stale todos",
+	"放置タスクの取得に失敗しました: %w":             "Failed to fetch stale todos: %w",
+	"放置タスクの記録に失敗しました: %w":             "Failed to record stale todos: %w",
+	"（%d日間更新なし）":                      "(not updated for %d days)",
+
+	// 繰り返しルール
+	"繰り返しルールが空です":                                          "The recurrence rule is empty",
+	"繰り返しルールの項目は NAME=VALUE の形式で指定してください: %q":              "Recurrence rule parts must be in the form NAME=VALUE: %q",
+	"未対応の繰り返しルールの項目です: %s":                                 "Unsupported recurrence rule part: %s",
+	"FREQを指定してください":                                        "FREQ is required",
+	"FREQにはDAILY / WEEKLY / MONTHLY / YEARLYを指定してください: %q": "FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY: %q",
+	"INTERVALには1〜1000を指定してください: %q":                        "INTERVAL must be between 1 and 1000: %q",
+	"BYDAYの曜日が不正です: %q":                                    "Invalid weekday in BYDAY: %q",
+	"BYDAYの週には1〜5または-1〜-5を指定してください: %q":                    "The week in BYDAY must be 1 to 5 or -1 to -5: %q",
+	"BYDAYの週の指定（1MO等）はFREQ=MONTHLY / YEARLYの場合のみ使えます":      "Week numbers in BYDAY (such as 1MO) can only be used with FREQ=MONTHLY or YEARLY",
+	"%sには%d〜%dの値（0以外）を指定してください: %q":                        "%s must be a non-zero value between %d and %d: %q",
+	"UNTILはYYYYMMDDまたはYYYYMMDDTHHMMSSZの形式で指定してください: %q":    "UNTIL must be in the form YYYYMMDD or YYYYMMDDTHHMMSSZ: %q",
+	"WKSTはMOのみ対応しています: %q":                                 "Only MO is supported for WKST: %q",
+	"休日はYYYY-MM-DDの形式で指定してください: %q":                        "Holidays must be in the form YYYY-MM-DD: %q",
+	"祝日のカレンダーには jp または none を指定してください: %q":                 "The holiday calendar must be jp or none: %q",
+
+	// 一括操作・取り込み
+	"一括操作を開始しました":                            "Started the bulk operation",
+	"一括操作の進捗を取得しました":                         "Retrieved the bulk operation progress",
+	"一括操作が見つかりません":                           "Bulk operation not found",
+	"一度に操作できるTodoの上限を超えています":                 "Exceeded the maximum number of todos per operation",
+	"operation=update の場合は update を指定してください": "update is required when operation=update",
+	"作成するTodoの内容が正しくありません":                   "Invalid todo to create",
+	"作成するTodoの内容が正しくありません: %w":               "Invalid todo to create: %w",
+	"一括操作の開始に失敗しました: %w":                     "Failed to start the bulk operation: %w",
+	"一括操作の取得に失敗しました: %w":                     "Failed to fetch the bulk operation: %w",
+	"一括操作の記録のパージに失敗しました: %w":                 "Failed to purge bulk operation records: %w",
+	"Todoistからの取り込みを開始しました":                  "Started importing from Todoist",
+	"Trelloからの取り込みを開始しました":                   "Started importing from Trello",
+	"Microsoft To Doからの取り込みを開始しました":          "Started importing from Microsoft To Do",
+	"取り込みの進捗を取得しました":                         "Retrieved the import progress",
+	"取り込みが見つかりません":                           "Import not found",
+	"取り込むタスクがありません":                          "There are no tasks to import",
+	"取り込みの開始に失敗しました: %w":                     "Failed to start the import: %w",
+	"取り込みの取得に失敗しました: %w":                     "Failed to fetch the import: %w",
+	"取り込みの記録のパージに失敗しました: %w":                 "Failed to purge import records: %w",
+	"%s: タイトルが空です":                           "%s: the title is empty",
+	"%s: タイトルが%d文字を超えています":                   "%s: the title exceeds %d characters",
+	"%s: 期限「%s」を解釈できないため期限なしで取り込みました":        "%s: imported without a due date because %s could not be parsed",
+	"指定したリストが見つかりません":                        "The specified list was not found",
+	"リスト「%s」のタスクの取得に失敗しました: %w":              "Failed to fetch tasks in list %s: %w",
+
+	// Webhook
+	"Webhookの送信先を取得しました":                  "Retrieved webhook endpoints",
+	"Webhookの送信先を登録しました":                  "Registered the webhook endpoint",
+	"Webhookの送信先を更新しました":                  "Updated the webhook endpoint",
+	"ID %d のWebhookの送信先を削除しました":           "Deleted the webhook endpoint with ID %d",
+	"Webhookの送信先が見つかりません":                 "Webhook endpoint not found",
+	"Webhookの配信を取得しました":                   "Retrieved the webhook delivery",
+	"Webhookの配信の履歴を取得しました":                "Retrieved the webhook delivery history",
+	"Webhookの配信が見つかりません":                  "Webhook delivery not found",
+	"Webhookの再配信を受け付けました":                 "Accepted the webhook redelivery",
+	"Webhookの署名シークレットをローテーションしました":        "Rotated the webhook signing secret",
+	"Webhookを受け付けました":                     "Accepted the webhook",
+	"Webhookの署名が不正です":                     "Invalid webhook signature",
+	"Webhookのペイロードが不正です: %w":              "Invalid webhook payload: %w",
+	"送信先のURLはhttpまたはhttpsの絶対URLで指定してください": "The endpoint URL must be an absolute http or https URL",
+	"送信先にプライベート・ループバック・リンクローカルのアドレスは指定できません":                      "The endpoint must not resolve to a private, loopback or link-local address",
+	"配信できないイベントの種類です（todo.created / todo.updated / todo.deleted）": "Event type cannot be delivered (todo.created / todo.updated / todo.deleted)",
+	"配信待ちの配信は再配信できません":                                            "Pending deliveries cannot be redelivered",
+	"送信先が停止しています。再開してから再配信してください":                                 "The endpoint is disabled. Enable it before redelivering",
+	"配信に%d回連続で失敗しました":                                             "Delivery failed %d times in a row",
+	"Webhookの送信先の取得に失敗しました: %w":                                   "Failed to fetch webhook endpoints: %w",
+	"Webhookの送信先の登録に失敗しました: %w":                                   "Failed to register the webhook endpoint: %w",
+	"Webhookの送信先の更新に失敗しました: %w":                                   "Failed to update the webhook endpoint: %w",
+	"Webhookの送信先の削除に失敗しました: %w":                                   "Failed to delete the webhook endpoint: %w",
+	"Webhookの配信の取得に失敗しました: %w":                                    "Failed to fetch the webhook delivery: %w",
+	"Webhookの配信の更新に失敗しました: %w":                                    "Failed to update the webhook delivery: %w",
+	"Webhookの配信の履歴の取得に失敗しました: %w":                                 "Failed to fetch the webhook delivery history: %w",
+	"Webhookの配信の履歴の削除に失敗しました: %w":                                 "Failed to delete the webhook delivery history: %w",
+	"Webhookの配信の履歴のパージに失敗しました: %w":                                "Failed to purge the webhook delivery history: %w",
+	"Webhookの配信ジョブの登録に失敗しました: %w":                                 "Failed to enqueue the webhook delivery job: %w",
+	"Webhookシークレットの生成に失敗しました: %w":                                 "Failed to generate the webhook secret: %w",
+	"Webhookシークレットの読み込みに失敗しました: %w":                               "Failed to load the webhook secret: %w",
+	"Webhookシークレットのローテーションに失敗しました: %w":                            "Failed to rotate the webhook secret: %w",
+
+	// ジョブ・スケジューラー
+	"ジョブを取得しました":               "Retrieved jobs",
+	"ジョブの件数を取得しました":            "Retrieved job counts",
+	"ジョブの稼働状況を取得しました":          "Retrieved job status",
+	"ジョブの実行履歴を取得しました":          "Retrieved job run history",
+	"ジョブの実行を開始しました":            "Started the job",
+	"ジョブを実行待ちに戻しました":           "Requeued the job",
+	"デッドレターのジョブを実行待ちに戻しました":    "Requeued dead-letter jobs",
+	"ID %d のジョブを削除しました":        "Deleted the job with ID %d",
+	"スケジューラーに登録されていないジョブです":    "The job is not registered with the scheduler",
+	"ジョブは実行中です":                "The job is already running",
+	"ジョブの実行履歴が見つかりません":         "Job run not found",
+	"スケジューラーが起動していません":         "The scheduler is not running",
+	"ジョブが見つかりません":              "Job not found",
+	"実行中のジョブは削除できません":          "Running jobs cannot be deleted",
+	"デッドレター・成功済みのジョブのみ再実行できます": "Only dead-letter or succeeded jobs can be retried",
+	"ジョブの取得に失敗しました: %w":        "Failed to fetch jobs: %w",
+	"ジョブの件数の取得に失敗しました: %w":     "Failed to count jobs: %w",
+	"ジョブの再実行に失敗しました: %w":       "Failed to retry the job: %w",
+	"ジョブの削除に失敗しました: %w":        "Failed to delete the job: %w",
+	"ジョブの実行履歴のパージに失敗しました: %w":  "Failed to purge job run history: %w",
+	"成功済みのジョブのパージに失敗しました: %w":  "Failed to purge succeeded jobs: %w",
+
+	// エスカレーション
+	"エスカレーションルールを取得しました":                                "Retrieved escalation rules",
+	"エスカレーションルールを登録しました":                                "Registered the escalation rule",
+	"エスカレーションルールを更新しました":                                "Updated the escalation rule",
+	"ID %d のエスカレーションルールを削除しました":                         "Deleted the escalation rule with ID %d",
+	"エスカレーションルールが見つかりません":                               "Escalation rule not found",
+	"エスカレーションの記録を取得しました":                                "Retrieved escalation records",
+	"raise_priority・recipients・channels のいずれかを指定してください": "Specify at least one of raise_priority, recipients or channels",
+	"設定されていない通知チャンネルです":                                 "The notification channel is not configured",
+	"メールで通知するには EMAIL_NOTIFY_ENABLED=true が必要です":        "EMAIL_NOTIFY_ENABLED=true is required to notify by email",
+	"通知先のメールアドレスが正しくありません":                              "Invalid recipient email address",
+	"エスカレーションルールの取得に失敗しました: %w":                         "Failed to fetch escalation rules: %w",
+	"エスカレーションルールの登録に失敗しました: %w":                         "Failed to register the escalation rule: %w",
+	"エスカレーションルールの更新に失敗しました: %w":                         "Failed to update the escalation rule: %w",
+	"エスカレーションルールの削除に失敗しました: %w":                         "Failed to delete the escalation rule: %w",
+	"エスカレーションの記録の取得に失敗しました: %w":                         "Failed to fetch escalation records: %w",
+	"エスカレーションの記録の削除に失敗しました: %w":                         "Failed to delete escalation records: %w",
+	"Todo %d のエスカレーションの記録に失敗しました: %w":                   "Failed to record the escalation of todo %d: %w",
+	"Todo %d へのエスカレーションに失敗しました: %w":                     "Failed to escalate todo %d: %w",
+	"ルール %d（%s）: %w":                                    "Rule %d (%s): %w",
+	"メール: %w":                                           "Email: %w",
+
+	// ダイジェスト・リマインダー・通知
+	"ダイジェストの配信設定を取得しました":           "Retrieved digest subscriptions",
+	"ダイジェストの配信設定を登録しました":           "Registered the digest subscription",
+	"ダイジェストの配信設定を更新しました":           "Updated the digest subscription",
+	"ID %d のダイジェストの配信設定を削除しました":    "Deleted the digest subscription with ID %d",
+	"ダイジェストの配信設定が見つかりません":          "Digest subscription not found",
+	"このメールアドレスの配信設定は登録済みです":        "A subscription for this email address already exists",
+	"ダイジェストを送信しました":                "Sent the digest",
+	"ダイジェストの配信設定の取得に失敗しました: %w":    "Failed to fetch digest subscriptions: %w",
+	"ダイジェストの配信設定の登録に失敗しました: %w":    "Failed to register the digest subscription: %w",
+	"ダイジェストの配信設定の更新に失敗しました: %w":    "Failed to update the digest subscription: %w",
+	"ダイジェストの配信設定の削除に失敗しました: %w":    "Failed to delete the digest subscription: %w",
+	"ダイジェスト対象のTodoの取得に失敗しました: %w":  "Failed to fetch todos for the digest: %w",
+	"%s へのダイジェストの送信に失敗しました: %w":    "Failed to send the digest to %s: %w",
+	"%s へのダイジェストの配信の開始に失敗しました: %w": "Failed to start delivering the digest to %s: %w",
+	"リマインダーの配信状態を取得しました":           "Retrieved reminder delivery status",
+	"リマインダーの配信状態の取得に失敗しました: %w":    "Failed to fetch reminder delivery status: %w",
+	"リマインダーの配信状態のパージに失敗しました: %w":   "Failed to purge reminder delivery status: %w",
+	"期限間近のリマインダーの配信に失敗しました: %w":    "Failed to deliver due-soon reminders: %w",
+	"期限切れのリマインダーの配信に失敗しました: %w":    "Failed to deliver overdue reminders: %w",
+	"Todo %d の配信状態の記録に失敗しました: %w":  "Failed to record the delivery status of todo %d: %w",
+	"テスト通知を送信しました":                 "Sent a test notification",
+
+	// プロファイル
+	"プロファイルを取得しました":        "Retrieved the profile",
+	"プロファイルを更新しました":        "Updated the profile",
+	"プロファイルの取得に失敗しました: %w": "Failed to fetch the profile: %w",
+	"プロファイルの更新に失敗しました: %w": "Failed to update the profile: %w",
+
+	// Web Push
+	"VAPIDの公開鍵を取得しました":                  "Retrieved the VAPID public key",
+	"Web Push通知を購読しました":                 "Subscribed to Web Push notifications",
+	"Web Push通知の購読を解除しました":              "Unsubscribed from Web Push notifications",
+	"Web Pushの購読一覧を取得しました":              "Retrieved Web Push subscriptions",
+	"Web Pushの購読を削除しました":                "Deleted the Web Push subscription",
+	"購読が見つかりません":                        "Subscription not found",
+	"購読の内容が不正です":                        "Invalid subscription",
+	"購読の送信先が許可されていません":                  "The subscription endpoint is not allowed",
+	"%w: endpointにはHTTPSのURLを指定してください":  "%w: endpoint must be an HTTPS URL",
+	"%w: keys.p256dh と keys.auth は必須です": "%w: keys.p256dh and keys.auth are required",
+	"購読の取得に失敗しました: %w":                  "Failed to fetch subscriptions: %w",
+	"購読の登録に失敗しました: %w":                  "Failed to register the subscription: %w",
+	"購読の解除に失敗しました: %w":                  "Failed to unsubscribe: %w",
+	"購読の削除に失敗しました: %w":                  "Failed to delete the subscription: %w",
+
+	// 外部サービス連携
+	"stateが不正か、有効期限が切れています":            "The state is invalid or has expired",
+	"認可コードがありません":                      "The authorization code is missing",
+	"別のインスタンスで同期中です":                   "Another instance is already syncing",
+	"LLMが設定されていません":                    "No LLM is configured",
+	"このメールからは作成済みです":                   "A todo has already been created from this email",
+	"連携状態の取得に失敗しました: %w":               "Failed to fetch the connection status: %w",
+	"トークンの取得に失敗しました: %w":               "Failed to fetch the token: %w",
+	"トークンの保存に失敗しました: %w":               "Failed to save the token: %w",
+	"期限切れのstateの削除に失敗しました: %w":         "Failed to delete expired OAuth states: %w",
+	"stateの保存に失敗しました: %w":              "Failed to save the OAuth state: %w",
+	"stateの検証に失敗しました: %w":              "Failed to verify the OAuth state: %w",
+	"トークンの削除に失敗しました: %w":               "Failed to delete the token: %w",
+	"アクセストークンの更新に失敗しました: %w":           "Failed to refresh the access token: %w",
+	"同期のロックに失敗しました: %w":                "Failed to acquire the sync lock: %w",
+	"同期状態の取得に失敗しました: %w":               "Failed to fetch the sync state: %w",
+	"同期状態の作成に失敗しました: %w":               "Failed to create the sync state: %w",
+	"同期状態の保存に失敗しました: %w":               "Failed to save the sync state: %w",
+	"同期状態の削除に失敗しました: %w":               "Failed to delete the sync state: %w",
+	"同期済みTodoの件数の取得に失敗しました: %w":        "Failed to count synced todos: %w",
+	"反映するTodoの取得に失敗しました: %w":           "Failed to fetch todos to sync: %w",
+	"反映済みの更新日時の保存に失敗しました: %w":          "Failed to save the last synced update time: %w",
+	"反映済みの更新日時のリセットに失敗しました: %w":        "Failed to reset the last synced update time: %w",
+	"受信メールの記録に失敗しました: %w":              "Failed to record the received email: %w",
+	"Googleカレンダーと連携しました":               "Connected to Google Calendar",
+	"Googleカレンダーとの連携を解除しました":           "Disconnected from Google Calendar",
+	"Googleカレンダーと連携されていません":            "Not connected to Google Calendar",
+	"Googleカレンダー連携の状態を取得しました":          "Retrieved the Google Calendar connection status",
+	"Googleカレンダーと同期しました":               "Synced with Google Calendar",
+	"URLをブラウザで開き、カレンダーへのアクセスを許可してください": "Open the URL in a browser and allow access to your calendar",
+	"カレンダーへのアクセスが許可されませんでした: %s":       "Access to the calendar was not granted: %s",
+	"リフレッシュトークンを取得できませんでした。Googleアカウントの設定から連携を解除して再度お試しください": "Could not obtain a refresh token. Remove the app's access from your Google account settings and try again",
+	"予定の取得に失敗しました: %w":               "Failed to fetch events: %w",
+	"予定に対応するTodoの取得に失敗しました: %w":      "Failed to fetch the todo for the event: %w",
+	"予定との紐付けの解除に失敗しました: %w":          "Failed to unlink the event: %w",
+	"Todo %d のカレンダーへの反映に失敗しました: %w":  "Failed to sync todo %d to the calendar: %w",
+	"Todo %d への予定の変更の反映に失敗しました: %w":  "Failed to apply event changes to todo %d: %w",
+	"Microsoft To Doと連携しました":         "Connected to Microsoft To Do",
+	"Microsoft To Doとの連携を解除しました":     "Disconnected from Microsoft To Do",
+	"Microsoft To Doと連携されていません":      "Not connected to Microsoft To Do",
+	"Microsoft To Do連携の状態を取得しました":    "Retrieved the Microsoft To Do connection status",
+	"Microsoft To Doのリストを取得しました":     "Retrieved Microsoft To Do lists",
+	"URLをブラウザで開き、タスクへのアクセスを許可してください": "Open the URL in a browser and allow access to your tasks",
+	"タスクへのアクセスが許可されませんでした: %s":       "Access to tasks was not granted: %s",
+	"リフレッシュトークンを取得できませんでした。アプリの登録で offline_access を許可しているか確認してください": "Could not obtain a refresh token. Check that offline_access is allowed in the app registration",
+	"GitHubのIssueと同期しました":                        "Synced with GitHub issues",
+	"Issueの取得に失敗しました: %w":                        "Failed to fetch issues: %w",
+	"Issueに対応するTodoの取得に失敗しました: %w":               "Failed to fetch the todo for the issue: %w",
+	"Issueとの紐付けの解除に失敗しました: %w":                   "Failed to unlink the issue: %w",
+	"Issue %s からのTodoの作成に失敗しました: %w":             "Failed to create a todo from issue %s: %w",
+	"Todo %d のIssueへの反映に失敗しました: %w":              "Failed to sync todo %d to the issue: %w",
+	"Todo %d へのIssueの変更の反映に失敗しました: %w":           "Failed to apply issue changes to todo %d: %w",
+	"Jiraの課題と同期しました":                             "Synced with Jira issues",
+	"課題の検索に失敗しました: %w":                           "Failed to search issues: %w",
+	"課題 %s の取得に失敗しました: %w":                       "Failed to fetch issue %s: %w",
+	"課題に対応するTodoの取得に失敗しました: %w":                  "Failed to fetch the todo for the issue: %w",
+	"課題との紐付けの解除に失敗しました: %w":                      "Failed to unlink the issue: %w",
+	"課題 %s からのTodoの作成に失敗しました: %w":                "Failed to create a todo from issue %s: %w",
+	"課題 %s のトランジションの実行に失敗しました: %w":               "Failed to transition issue %s: %w",
+	"Todo %d への課題の変更の反映に失敗しました: %w":              "Failed to apply issue changes to todo %d: %w",
+	"Notionのデータベースと同期しました":                       "Synced with the Notion database",
+	"データベースのプロパティが設定と一致しません":                     "The database properties do not match the configuration",
+	"プロパティ %q がありません":                            "Property %q is missing",
+	"プロパティ %q の種類 %s には書き込めません（%s のいずれかにしてください）": "Cannot write to property %q of type %s (use one of %s)",
+	"データベースの取得に失敗しました: %w":                       "Failed to fetch the database: %w",
+	"ページとの紐付けの解除に失敗しました: %w":                     "Failed to unlink the page: %w",
+	"Todo %d のページの作成に失敗しました: %w":                 "Failed to create a page for todo %d: %w",
+	"Todo %d のページの更新に失敗しました: %w":                 "Failed to update the page for todo %d: %w",
+	"Todo %d のページのアーカイブに失敗しました: %w":              "Failed to archive the page for todo %d: %w",
+	"CalDAVリソース名の保存に失敗しました: %w":                  "Failed to save the CalDAV resource name: %w",
+	"コレクションの状態の取得に失敗しました: %w":                    "Failed to fetch the collection state: %w",
+
+	// スナップショット
+	"スナップショットを作成しました":                 "Created the snapshot",
+	"スナップショットを取得しました":                 "Retrieved snapshots",
+	"スナップショット %s を削除しました":             "Deleted snapshot %s",
+	"スナップショットが見つかりません":                "Snapshot not found",
+	"スナップショットにこの形式のファイルはありません":        "The snapshot has no file in this format",
+	"同じ時刻のスナップショットが作成済みです":            "A snapshot for the same time already exists",
+	"スナップショットの送信に失敗しました":              "Failed to send the snapshot",
+	"古いスナップショットの削除に失敗しました":            "Failed to delete old snapshots",
+	"スナップショットの作成に失敗しました: %w":          "Failed to create the snapshot: %w",
+	"スナップショットの取得に失敗しました: %w":          "Failed to fetch the snapshot: %w",
+	"スナップショットの一覧の取得に失敗しました: %w":       "Failed to list snapshots: %w",
+	"スナップショットの確認に失敗しました: %w":          "Failed to check the snapshot: %w",
+	"スナップショットのファイルの読み込みに失敗しました: %w":   "Failed to read the snapshot file: %w",
+	"スナップショット %s のマニフェストを読み込めません: %w": "Unable to read the manifest of snapshot %s: %w",
+	"スナップショット %s の削除に失敗しました: %w":      "Failed to delete snapshot %s: %w",
+	"%sのエクスポートに失敗しました: %w":            "Failed to export %s: %w",
+
+	// 管理
+	"フィーチャーフラグを取得しました":              "Retrieved feature flags",
+	"フィーチャーフラグを更新しました":              "Updated the feature flag",
+	"フィーチャーフラグの読み込みに失敗しました: %w":     "Failed to load feature flags: %w",
+	"フィーチャーフラグの保存に失敗しました: %w":       "Failed to save the feature flag: %w",
+	"メンテナンスモードの状態を取得しました":           "Retrieved the maintenance mode status",
+	"メンテナンスモードを有効にしました":             "Enabled maintenance mode",
+	"メンテナンスモードを無効にしました":             "Disabled maintenance mode",
+	"データベース統計を取得しました":               "Retrieved database statistics",
+	"マイグレーション一覧を取得しました":             "Retrieved migrations",
+	"テーブル統計の取得に失敗しました: %w":          "Failed to fetch table statistics: %w",
+	"データベースサイズの取得に失敗しました: %w":       "Failed to fetch the database size: %w",
+	"実行中クエリの取得に失敗しました: %w":          "Failed to fetch running queries: %w",
+	"コネクションプールの取得に失敗しました: %w":       "Failed to fetch the connection pool: %w",
+	"適用済みマイグレーションの取得に失敗しました: %w":    "Failed to fetch applied migrations: %w",
+	"マイグレーションのdry-runに失敗しました: %w":   "Failed to dry-run migrations: %w",
+	"マイグレーション %s に失敗しました: %w":       "Migration %s failed: %w",
+	"マイグレーション管理テーブルの作成に失敗しました: %w":  "Failed to create the migrations table: %w",
+	"トランザクションの開始に失敗しました: %w":        "Failed to begin the transaction: %w",
+	"データベース接続に失敗しました: %w":           "Failed to connect to the database: %w",
+	"テナント %s のマイグレーションに失敗しました: %w":  "Failed to migrate tenant %s: %w",
+	"テナントスキーマ %s の作成に失敗しました: %w":    "Failed to create tenant schema %s: %w",
+	"テナントスキーマ %s への切り替えに失敗しました: %w": "Failed to switch to tenant schema %s: %w",
+}
@@ -0,0 +1,270 @@
+// Package ifttt IFTTTのサービスAPI（/ifttt/v1）の互換エンドポイント
+// トリガー new_todo・completed_todo とアクション create_todo を提供する
+package ifttt
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix IFTTTが呼び出すエンドポイントのパス（IFTTTの仕様で固定）
+const Prefix = "/ifttt/v1/"
+
+// maxBodyBytes リクエストボディの上限
+const maxBodyBytes = 64 << 10
+
+// Handler IFTTTのリクエストを処理するハンドラー
+type Handler struct {
+	triggerService service.TriggerService
+	todoService    service.TodoService
+	serviceKeyHash [32]byte
+}
+
+// NewHandler 新しいIFTTTハンドラーを作成（serviceKeyはIFTTTのサービス設定画面に表示されるService Key）
+func NewHandler(triggerService service.TriggerService, todoService service.TodoService, serviceKey string) *Handler {
+	return &Handler{
+		triggerService: triggerService,
+		todoService:    todoService,
+		serviceKeyHash: sha256.Sum256([]byte(serviceKey)),
+	}
+}
+
+// triggerRequest トリガーのリクエスト
+type triggerRequest struct {
+	// Limit 返す件数（省略時は50。0の場合は空の配列を返す）
+	Limit *int `json:"limit"`
+}
+
+// actionRequest アクションのリクエスト
+type actionRequest struct {
+	ActionFields map[string]string `json:"actionFields"`
+}
+
+// errorItem エラーレスポンスの要素（statusがSKIPの場合、IFTTTはアクションを再試行しない）
+type errorItem struct {
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message"`
+}
+
+// ServeHTTP サービスキーを検証してパスに応じて処理を振り分ける
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeError(w, http.StatusUnauthorized, errorItem{Message: "サービスキーが不正です"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, Prefix)
+	switch {
+	case path == "status" && r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusOK)
+	case path == "test/setup" && r.Method == http.MethodPost:
+		h.testSetup(w)
+	case path == "triggers/new_todo" && r.Method == http.MethodPost:
+		h.trigger(w, r, false)
+	case path == "triggers/completed_todo" && r.Method == http.MethodPost:
+		h.trigger(w, r, true)
+	case strings.HasPrefix(path, "triggers/") && strings.Contains(path, "/trigger_identity/") && r.Method == http.MethodDelete:
+		// リアルタイム通知に対応していないため、トリガーの登録解除は記録せずに受け付ける
+		w.WriteHeader(http.StatusOK)
+	case path == "actions/create_todo" && r.Method == http.MethodPost:
+		h.createTodo(w, r)
+	default:
+		writeError(w, http.StatusNotFound, errorItem{Message: "エンドポイントが見つかりません"})
+	}
+}
+
+// authorized IFTTT-Service-Key（旧 IFTTT-Channel-Key）を定数時間で比較する
+func (h *Handler) authorized(r *http.Request) bool {
+	key := r.Header.Get("IFTTT-Service-Key")
+	if key == "" {
+		key = r.Header.Get("IFTTT-Channel-Key")
+	}
+	hash := sha256.Sum256([]byte(key))
+	return key != "" && subtle.ConstantTimeCompare(hash[:], h.serviceKeyHash[:]) == 1
+}
+
+// testSetup エンドポイントテスト用のサンプル値
+func (h *Handler) testSetup(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"samples": map[string]any{
+				"triggers": map[string]any{
+					"new_todo":       map[string]any{},
+					"completed_todo": map[string]any{},
+				},
+				"actions": map[string]any{
+					"create_todo": map[string]string{
+						"title":       "IFTTTからのテスト",
+						"description": "",
+						"priority":    string(model.PriorityMedium),
+						"due_date":    "",
+					},
+				},
+				"actionRecordSkipping": map[string]any{
+					"create_todo": map[string]string{
+						"title": "",
+					},
+				},
+			},
+		},
+	})
+}
+
+// trigger new_todo・completed_todo トリガー（新しい順）
+func (h *Handler) trigger(w http.ResponseWriter, r *http.Request, completed bool) {
+	var req triggerRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, errorItem{Message: err.Error()})
+		return
+	}
+
+	items := []map[string]any{}
+	if req.Limit != nil && *req.Limit <= 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"data": items})
+		return
+	}
+	limit := 0
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+
+	var (
+		todos []*model.Todo
+		err   error
+	)
+	if completed {
+		todos, err = h.triggerService.CompletedTodos(r.Context(), limit)
+	} else {
+		todos, err = h.triggerService.NewTodos(r.Context(), limit)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "IFTTTトリガーのTodoの取得に失敗しました", "error", err)
+		writeError(w, http.StatusInternalServerError, errorItem{Message: "Todoの取得に失敗しました"})
+		return
+	}
+
+	for _, todo := range todos {
+		item := ingredients(todo)
+		id, timestamp := strconv.FormatUint(uint64(todo.ID), 10), todo.CreatedAt
+		if completed {
+			// 未完了に戻して再度完了した場合も別のイベントとして扱われるよう、完了日時をIDに含める
+			id = fmt.Sprintf("%d-%d", todo.ID, todo.CompletedAt.Unix())
+			timestamp = *todo.CompletedAt
+		}
+		item["meta"] = map[string]any{"id": id, "timestamp": timestamp.Unix()}
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// createTodo create_todo アクション
+func (h *Handler) createTodo(w http.ResponseWriter, r *http.Request) {
+	var req actionRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, errorItem{Message: err.Error()})
+		return
+	}
+
+	fields := req.ActionFields
+	create := &model.TodoCreateRequest{
+		Title:       strings.TrimSpace(fields["title"]),
+		Description: fields["description"],
+		Priority:    model.Priority(strings.ToLower(strings.TrimSpace(fields["priority"]))),
+	}
+	if create.Title == "" {
+		writeError(w, http.StatusBadRequest, errorItem{Status: "SKIP", Message: "タイトルは必須です"})
+		return
+	}
+	if due := strings.TrimSpace(fields["due_date"]); due != "" {
+		t, err := parseDate(due)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errorItem{Status: "SKIP", Message: fmt.Sprintf("期限「%s」を解釈できません", due)})
+			return
+		}
+		create.DueDate = &t
+	}
+	if tags := strings.TrimSpace(fields["tags"]); tags != "" {
+		create.Tags = strings.Split(tags, ",")
+	}
+
+	todo, err := h.todoService.CreateTodo(r.Context(), create)
+	if err != nil {
+		// 入力の誤りは再試行しても同じ結果になるため、スキップとして返す
+		writeError(w, http.StatusBadRequest, errorItem{Status: "SKIP", Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": []map[string]string{{"id": strconv.FormatUint(uint64(todo.ID), 10)}},
+	})
+}
+
+// ingredients レシピで使える値（IFTTTの材料はすべて文字列で返す）
+func ingredients(todo *model.Todo) map[string]any {
+	item := map[string]any{
+		"todo_id":     strconv.FormatUint(uint64(todo.ID), 10),
+		"title":       todo.Title,
+		"description": todo.Description,
+		"priority":    string(todo.Priority),
+		"tags":        strings.Join(todo.Tags, ", "),
+		"due_date":    "",
+		"created_at":  todo.CreatedAt.Format(time.RFC3339),
+	}
+	if todo.DueDate != nil {
+		item["due_date"] = todo.DueDate.Format(time.RFC3339)
+	}
+	if todo.CompletedAt != nil {
+		item["completed_at"] = todo.CompletedAt.Format(time.RFC3339)
+	}
+	return item
+}
+
+// parseDate 日時（RFC 3339・IFTTTの "January 2, 2006 at 03:04PM" 形式）または日付を解析
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "January 2, 2006 at 03:04PM"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+// decode リクエストボディのJSONを読み込む（空のボディは許容する）
+func decode(r *http.Request, v any) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		return errors.New("リクエストボディを読み込めません")
+	}
+	if len(body) > maxBodyBytes {
+		return errors.New("リクエストボディが大きすぎます")
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.New("リクエストボディのJSONが不正です")
+	}
+	return nil
+}
+
+// writeJSON JSONレスポンスを書き込む
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError IFTTTの形式（{"errors": [...]}）でエラーを書き込む
+func writeError(w http.ResponseWriter, status int, item errorItem) {
+	writeJSON(w, status, map[string]any{"errors": []errorItem{item}})
+}
@@ -0,0 +1,231 @@
+// Package imap 受信メールの取得に必要な操作をまとめたIMAPクライアント
+// （ログイン・メールボックスの選択・未読の検索・取得・フラグ付け・移動のみ。プロトコルの処理は github.com/emersion/go-imap に任せる）
+package imap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+
+	goimap "github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/emersion/go-imap/commands"
+)
+
+// commandTimeout 1コマンドあたりの応答待ちの上限
+const commandTimeout = time.Minute
+
+// ErrNoMessage 指定したUIDのメールが存在しない（他のクライアントが削除した等）
+var ErrNoMessage = errors.New("メールが見つかりません")
+
+// Client IMAPサーバーとの接続
+type Client struct {
+	c *client.Client
+}
+
+// Dial サーバーに接続してあいさつを受け取る（useTLSがfalseの場合は平文で接続する）
+func Dial(ctx context.Context, addr string, useTLS bool) (*Client, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("IMAPサーバーに接続できません: %w", err)
+	}
+
+	// あいさつの待ち時間にも上限を設ける（以降はコマンドごとにTimeoutで設定される）
+	conn.SetDeadline(time.Now().Add(commandTimeout))
+	c, err := client.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("IMAPサーバーが接続を拒否しました: %w", err)
+	}
+	c.Timeout = commandTimeout
+	c.ErrorLog = slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn)
+	return &Client{c: c}, nil
+}
+
+// Close 接続を閉じる
+func (c *Client) Close() error {
+	return c.c.Terminate()
+}
+
+// Login ユーザー名とパスワードでログインする
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	err := c.run(ctx, func() error {
+		return c.c.Login(username, password)
+	})
+	if err != nil {
+		return fmt.Errorf("IMAPサーバーにログインできません: %w", err)
+	}
+	return nil
+}
+
+// Select メールボックスを選択
+func (c *Client) Select(ctx context.Context, mailbox string) error {
+	err := c.run(ctx, func() error {
+		_, err := c.c.Select(mailbox, false)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("メールボックス %q を選択できません: %w", mailbox, err)
+	}
+	return nil
+}
+
+// SearchUnseen 未読のメールのUIDを古い順に取得
+func (c *Client) SearchUnseen(ctx context.Context) ([]uint32, error) {
+	criteria := goimap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{goimap.SeenFlag}
+
+	var uids []uint32
+	err := c.run(ctx, func() error {
+		var err error
+		uids, err = c.c.UidSearch(criteria)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(uids)
+	return uids, nil
+}
+
+// Size メールのサイズ（バイト）を取得
+func (c *Client) Size(ctx context.Context, uid uint32) (int64, error) {
+	msg, err := c.fetch(ctx, uid, goimap.FetchRFC822Size)
+	if err != nil {
+		return 0, err
+	}
+	return int64(msg.Size), nil
+}
+
+// Fetch メール全体（RFC 5322形式）を取得（既読にはしない）
+func (c *Client) Fetch(ctx context.Context, uid uint32) ([]byte, error) {
+	section := &goimap.BodySectionName{Peek: true}
+	msg, err := c.fetch(ctx, uid, section.FetchItem())
+	if err != nil {
+		return nil, err
+	}
+	body := msg.GetBody(section)
+	if body == nil {
+		return nil, ErrNoMessage
+	}
+	return io.ReadAll(body)
+}
+
+// MarkSeen メールを既読にする
+func (c *Client) MarkSeen(ctx context.Context, uid uint32) error {
+	return c.addFlag(ctx, uid, goimap.SeenFlag)
+}
+
+// Move メールを別のメールボックスへ移動（MOVEに対応していないサーバーではコピーして削除する）
+func (c *Client) Move(ctx context.Context, uid uint32, mailbox string) error {
+	seqset := uidSet(uid)
+	move, err := c.c.Support("MOVE")
+	if err != nil {
+		return err
+	}
+	if move {
+		return c.run(ctx, func() error {
+			return c.c.UidMove(seqset, mailbox)
+		})
+	}
+
+	// UidMove の代替処理はEXPUNGEで他の削除済みフラグ付きのメールも消去するため使わない
+	if err := c.run(ctx, func() error { return c.c.UidCopy(seqset, mailbox) }); err != nil {
+		return err
+	}
+	if err := c.addFlag(ctx, uid, goimap.DeletedFlag); err != nil {
+		return err
+	}
+	uidPlus, err := c.c.Support("UIDPLUS")
+	if err != nil || !uidPlus {
+		return err
+	}
+	return c.run(ctx, func() error {
+		status, err := c.c.Execute(&commands.Uid{Cmd: uidExpunge{seqset}}, nil)
+		if err != nil {
+			return err
+		}
+		return status.Err()
+	})
+}
+
+// Logout ログアウトして接続を閉じる
+func (c *Client) Logout(ctx context.Context) error {
+	return c.run(ctx, c.c.Logout)
+}
+
+// fetch 1通のメールの項目を取得（存在しない場合はErrNoMessage）
+func (c *Client) fetch(ctx context.Context, uid uint32, item goimap.FetchItem) (*goimap.Message, error) {
+	var found *goimap.Message
+	err := c.run(ctx, func() error {
+		messages := make(chan *goimap.Message, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- c.c.UidFetch(uidSet(uid), []goimap.FetchItem{goimap.FetchUid, item}, messages)
+		}()
+		for msg := range messages {
+			if msg.Uid == uid {
+				found = msg
+			}
+		}
+		return <-done
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNoMessage
+	}
+	return found, nil
+}
+
+// addFlag メールにフラグを付ける
+func (c *Client) addFlag(ctx context.Context, uid uint32, flag string) error {
+	return c.run(ctx, func() error {
+		return c.c.UidStore(uidSet(uid), goimap.FormatFlagsOp(goimap.AddFlags, true), []interface{}{flag}, nil)
+	})
+}
+
+// run コマンドを実行する（ctxのキャンセル時は接続を閉じて応答待ちを中断し、ctxのエラーを返す）
+func (c *Client) run(ctx context.Context, fn func() error) error {
+	stop := context.AfterFunc(ctx, func() { c.c.Terminate() })
+	defer stop()
+
+	err := fn()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// uidSet 1つのUIDだけを含むシーケンスセット
+func uidSet(uid uint32) *goimap.SeqSet {
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(uid)
+	return seqset
+}
+
+// uidExpunge UIDを指定したEXPUNGE（RFC 4315のUID EXPUNGE。commands.Uid で包んで送る）
+type uidExpunge struct {
+	seqset *goimap.SeqSet
+}
+
+func (cmd uidExpunge) Command() *goimap.Command {
+	return &goimap.Command{Name: "EXPUNGE", Arguments: []interface{}{cmd.seqset}}
+}
@@ -0,0 +1,135 @@
+package ipfilter
+
+import (
+	"encoding/json"
+	"log/slog"
+	"myapp/clientip"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Middleware CIDR指定の許可リスト・拒否リストでクライアントIPを制限するミドルウェア
+// 拒否リストに一致するか、許可リストが設定されていて一致しない場合に403を返す
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Current()
+		if !cfg.IPFilter.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientip.FromRequest(r, cfg.IPFilter.TrustProxy, cfg.IPFilter.TrustedProxies)
+		if !rulesFor(cfg).allowed(ip, r.URL.Path) {
+			slog.WarnContext(r.Context(), "IPフィルターによりリクエストを拒否しました",
+				"client_ip", ip,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(huma.Error403Forbidden(i18n.T(r.Context(), "このIPアドレスからのアクセスは許可されていません")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// list 解析済みの許可・拒否リスト
+type list struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// pathList パスごとのリスト
+type pathList struct {
+	prefix string
+	list
+}
+
+// rules 設定から解析したルール一式
+type rules struct {
+	global list
+	paths  []pathList
+}
+
+// cached 直近に解析した設定とルール（設定が差し替わった場合のみ再解析する）
+var cached atomic.Pointer[struct {
+	cfg   *config.Config
+	rules *rules
+}]
+
+// rulesFor 設定に対応する解析済みルールを取得
+// 不正なエントリは設定の検証で弾かれるため、ここでは読み飛ばす
+func rulesFor(cfg *config.Config) *rules {
+	if c := cached.Load(); c != nil && c.cfg == cfg {
+		return c.rules
+	}
+
+	parsed := &rules{global: newList(cfg.IPFilter.Allow, cfg.IPFilter.Deny)}
+	for _, rule := range cfg.IPFilter.Paths {
+		parsed.paths = append(parsed.paths, pathList{prefix: rule.Prefix, list: newList(rule.Allow, rule.Deny)})
+	}
+
+	cached.Store(&struct {
+		cfg   *config.Config
+		rules *rules
+	}{cfg, parsed})
+	return parsed
+}
+
+func newList(allow, deny []string) list {
+	var l list
+	l.allow, _ = clientip.ParsePrefixes(allow)
+	l.deny, _ = clientip.ParsePrefixes(deny)
+	return l
+}
+
+// allowed IPアドレスがパスへのアクセスを許可されているか
+// 全体とパスごと（最も長く先頭一致したもの）の両方のリストを満たす必要がある
+func (r *rules) allowed(ip, path string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		// 判定できないアドレスは許可リストが無い場合のみ通す
+		return len(r.global.allow) == 0 && len(r.pathFor(path).allow) == 0
+	}
+	addr = addr.Unmap()
+
+	return r.global.allowed(addr) && r.pathFor(path).allowed(addr)
+}
+
+// pathFor パスに最も長く先頭一致したリストを取得（無ければ空のリスト）
+func (r *rules) pathFor(path string) list {
+	var matched list
+	length := 0
+	for _, rule := range r.paths {
+		if strings.HasPrefix(path, rule.prefix) && len(rule.prefix) > length {
+			length = len(rule.prefix)
+			matched = rule.list
+		}
+	}
+	return matched
+}
+
+func (l list) allowed(addr netip.Addr) bool {
+	if contains(l.deny, addr) {
+		return false
+	}
+	return len(l.allow) == 0 || contains(l.allow, addr)
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+// Package jira Jira REST API（v2）の課題の検索とトランジションの実行
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageSize 検索1回あたりの取得件数
+const pageSize = 100
+
+// エラー
+var (
+	// ErrNotFound 課題が存在しない（削除・権限の喪失を含む）
+	ErrNotFound = errors.New("課題が見つかりません")
+	// ErrTransitionUnavailable 課題の現在のステータスから実行できないトランジション
+	ErrTransitionUnavailable = errors.New("実行できないトランジションです")
+)
+
+// Issue 課題（フィールドは取得を指定したもののみ）
+type Issue struct {
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// Done ステータスのカテゴリーが完了か
+func (i *Issue) Done() bool {
+	var status struct {
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	}
+	if err := json.Unmarshal(i.Fields["status"], &status); err != nil {
+		return false
+	}
+	return status.StatusCategory.Key == "done"
+}
+
+// Text フィールドの値を文字列として取得（オブジェクトの場合は value / name、未設定の場合は空文字）
+func (i *Issue) Text(field string) string {
+	return text(i.Fields[field])
+}
+
+// Strings 配列のフィールドの値を文字列の一覧として取得（ラベル・コンポーネント等）
+func (i *Issue) Strings(field string) []string {
+	raw := i.Fields[field]
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		if s := text(raw); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s := text(item); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// text 文字列・数値、または value / name を持つオブジェクトを文字列にする
+func text(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	var obj struct {
+		Value string `json:"value"`
+		Name  string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		if obj.Value != "" {
+			return obj.Value
+		}
+		return obj.Name
+	}
+	return ""
+}
+
+// ParseTime 日付（2006-01-02、ローカル時刻の0時）または日時（2006-01-02T15:04:05.000-0700）を解析
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05.000-0700", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+// Transition 課題のステータスを変更するトランジション
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Client Jira REST APIのクライアント
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	client  *http.Client
+}
+
+// NewClient 新しいクライアントを作成（emailが空の場合はtokenを個人用アクセストークンとしてBearer認証に使う）
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   email,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Search JQLに一致する課題を取得（startAtは取得開始位置。nextは次のページがあるか）
+func (c *Client) Search(ctx context.Context, jql string, fields []string, startAt int) (issues []*Issue, next bool, err error) {
+	q := url.Values{
+		"jql":        {jql},
+		"fields":     {strings.Join(fields, ",")},
+		"startAt":    {strconv.Itoa(startAt)},
+		"maxResults": {strconv.Itoa(pageSize)},
+	}
+
+	var result struct {
+		StartAt int      `json:"startAt"`
+		Total   int      `json:"total"`
+		Issues  []*Issue `json:"issues"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/search", q, nil, &result); err != nil {
+		return nil, false, err
+	}
+	return result.Issues, len(result.Issues) > 0 && result.StartAt+len(result.Issues) < result.Total, nil
+}
+
+// GetIssue 課題を取得
+func (c *Client) GetIssue(ctx context.Context, key string, fields []string) (*Issue, error) {
+	var issue Issue
+	q := url.Values{"fields": {strings.Join(fields, ",")}}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key), q, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// DoTransition 名前またはIDで指定したトランジションを実行（現在のステータスから実行できない場合はErrTransitionUnavailable）
+func (c *Client) DoTransition(ctx context.Context, key, transition string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+
+	var result struct {
+		Transitions []Transition `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &result); err != nil {
+		return err
+	}
+
+	for _, t := range result.Transitions {
+		if t.ID == transition || strings.EqualFold(t.Name, transition) {
+			body := map[string]any{"transition": map[string]string{"id": t.ID}}
+			return c.do(ctx, http.MethodPost, path, nil, body, nil)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrTransitionUnavailable, transition)
+}
+
+// do APIを呼び出し、レスポンスをoutにデコードする
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Jira APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ジョブの状態
+const (
+	StatusIdle    = "idle"
+	StatusRunning = "running"
+	StatusFailing = "failing"
+	StatusStalled = "stalled"
+)
+
+// Status ジョブの稼働状況
+type Status struct {
+	Name                string     `json:"name" doc:"ジョブ名"`
+	Description         string     `json:"description" doc:"ジョブの説明"`
+	Interval            string     `json:"interval,omitempty" doc:"想定される実行間隔"`
+	Status              string     `json:"status" doc:"状態（stalledは想定間隔の2倍以上実行されていない）" enum:"idle,running,failing,stalled"`
+	RunCount            int64      `json:"run_count" doc:"実行回数"`
+	FailureCount        int64      `json:"failure_count" doc:"失敗回数"`
+	ConsecutiveFailures int64      `json:"consecutive_failures" doc:"連続失敗回数"`
+	LastStartedAt       *time.Time `json:"last_started_at,omitempty" doc:"直近の開始日時"`
+	LastFinishedAt      *time.Time `json:"last_finished_at,omitempty" doc:"直近の終了日時"`
+	LastDurationMs      float64    `json:"last_duration_ms" doc:"直近の実行時間（ミリ秒）"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty" doc:"直近の成功日時"`
+	LastError           string     `json:"last_error,omitempty" doc:"直近のエラー内容"`
+}
+
+// Job 実行状況を記録するジョブ
+type Job struct {
+	mu         sync.Mutex
+	status     Status
+	interval   time.Duration
+	running    int
+	registered time.Time
+}
+
+// 登録済みジョブ
+var (
+	registry   = make(map[string]*Job)
+	registryMu sync.RWMutex
+)
+
+// Register ジョブを登録（intervalは想定される実行間隔。0の場合は停滞検知を行わない）
+// 同名のジョブが登録済みの場合はそれを返す
+func Register(name, description string, interval time.Duration) *Job {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if job, ok := registry[name]; ok {
+		return job
+	}
+
+	job := &Job{
+		status: Status{
+			Name:        name,
+			Description: description,
+		},
+		interval:   interval,
+		registered: time.Now(),
+	}
+	if interval > 0 {
+		job.status.Interval = interval.String()
+	}
+	registry[name] = job
+	return job
+}
+
+// Run fnを実行し、結果を記録する（パニックは失敗として記録して再送出）
+func (j *Job) Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	start := time.Now()
+
+	j.mu.Lock()
+	j.running++
+	j.status.RunCount++
+	j.status.LastStartedAt = &start
+	j.mu.Unlock()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			j.finish(start, fmt.Errorf("パニックが発生しました: %v", rec))
+			panic(rec)
+		}
+		j.finish(start, err)
+	}()
+
+	return fn(ctx)
+}
+
+// finish 実行結果を記録
+func (j *Job) finish(start time.Time, err error) {
+	end := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.running--
+	j.status.LastFinishedAt = &end
+	j.status.LastDurationMs = float64(end.Sub(start).Microseconds()) / 1000
+
+	if err != nil {
+		j.status.FailureCount++
+		j.status.ConsecutiveFailures++
+		j.status.LastError = err.Error()
+		slog.Error("ジョブの実行に失敗しました", "job", j.status.Name, "error", err)
+		return
+	}
+
+	j.status.ConsecutiveFailures = 0
+	j.status.LastError = ""
+	j.status.LastSuccessAt = &end
+}
+
+// Status 現在の稼働状況を取得
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := j.status
+	switch {
+	case j.running > 0:
+		status.Status = StatusRunning
+	case j.stalled():
+		status.Status = StatusStalled
+	case status.ConsecutiveFailures > 0:
+		status.Status = StatusFailing
+	default:
+		status.Status = StatusIdle
+	}
+	return status
+}
+
+// stalled 想定間隔の2倍以上開始されていないか
+func (j *Job) stalled() bool {
+	if j.interval <= 0 {
+		return false
+	}
+	last := j.registered
+	if j.status.LastStartedAt != nil {
+		last = *j.status.LastStartedAt
+	}
+	return time.Since(last) > 2*j.interval
+}
+
+// List 全ジョブの稼働状況を名前順で取得
+func List() []Status {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	result := make([]Status, 0, len(registry))
+	for _, job := range registry {
+		result = append(result, job.Status())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,184 @@
+// Package llm LLMプロバイダー（OpenAI互換・Anthropic）のテキスト生成APIのクライアント
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// プロバイダー
+const (
+	// ProviderOpenAI OpenAIのChat Completions API（互換APIを提供するサーバーにも使える）
+	ProviderOpenAI = "openai"
+	// ProviderAnthropic AnthropicのMessages API
+	ProviderAnthropic = "anthropic"
+)
+
+// プロバイダーごとの既定のベースURL
+const (
+	defaultOpenAIURL    = "https://api.openai.com/v1"
+	defaultAnthropicURL = "https://api.anthropic.com"
+)
+
+// anthropicVersion anthropic-versionヘッダーに指定するAPIのバージョン
+const anthropicVersion = "2023-06-01"
+
+// maxTokens 生成するトークン数の上限
+const maxTokens = 1024
+
+// ErrUnsupportedProvider 対応していないプロバイダー
+var ErrUnsupportedProvider = errors.New("対応していないLLMプロバイダーです")
+
+// Client LLMのクライアント
+type Client struct {
+	provider string
+	baseURL  string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewClient 新しいクライアントを作成（baseURLが空の場合はプロバイダーの既定のURLを使う）
+func NewClient(provider, baseURL, apiKey, model string, timeout time.Duration) (*Client, error) {
+	baseURL, err := BaseURL(provider, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		provider: provider,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// BaseURL リクエスト先のベースURL（baseURLが空の場合はプロバイダーの既定のURL）
+func BaseURL(provider, baseURL string) (string, error) {
+	switch provider {
+	case ProviderOpenAI:
+		if baseURL == "" {
+			baseURL = defaultOpenAIURL
+		}
+	case ProviderAnthropic:
+		if baseURL == "" {
+			baseURL = defaultAnthropicURL
+		}
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedProvider, provider)
+	}
+	return baseURL, nil
+}
+
+// Complete システムプロンプトとユーザーのメッセージから応答のテキストを生成
+func (c *Client) Complete(ctx context.Context, system, prompt string) (string, error) {
+	if c.provider == ProviderAnthropic {
+		return c.completeAnthropic(ctx, system, prompt)
+	}
+	return c.completeOpenAI(ctx, system, prompt)
+}
+
+// completeOpenAI Chat Completions APIで生成
+func (c *Client) completeOpenAI(ctx context.Context, system, prompt string) (string, error) {
+	body := map[string]any{
+		"model": c.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+		"max_tokens":  maxTokens,
+		"temperature": 0,
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	header := http.Header{"Authorization": {"Bearer " + c.apiKey}}
+	if err := c.post(ctx, "/chat/completions", header, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("LLMの応答が空です")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// completeAnthropic Messages APIで生成
+func (c *Client) completeAnthropic(ctx context.Context, system, prompt string) (string, error) {
+	body := map[string]any{
+		"model":       c.model,
+		"system":      system,
+		"messages":    []map[string]string{{"role": "user", "content": prompt}},
+		"max_tokens":  maxTokens,
+		"temperature": 0,
+	}
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	header := http.Header{
+		"X-Api-Key":         {c.apiKey},
+		"Anthropic-Version": {anthropicVersion},
+	}
+	if err := c.post(ctx, "/v1/messages", header, body, &resp); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, content := range resp.Content {
+		if content.Type == "text" {
+			b.WriteString(content.Text)
+		}
+	}
+	if b.Len() == 0 {
+		return "", errors.New("LLMの応答が空です")
+	}
+	return b.String(), nil
+}
+
+// post JSONをPOSTし、レスポンスをoutにデコードする
+func (c *Client) post(ctx context.Context, path string, header http.Header, in, out any) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("LLM APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ExtractJSON 応答のテキストから最初のJSONオブジェクトを取り出す（コードブロックや前置きの文章を除く）
+func ExtractJSON(text string) (string, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return "", errors.New("LLMの応答にJSONが含まれていません")
+	}
+	return text[start : end+1], nil
+}
@@ -0,0 +1,219 @@
+// Package loadgen インデックス・ページネーションの性能検証に使う負荷試験用のTodoを現実的な分布で生成する
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"myapp/db/model"
+	"myapp/workerpool"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Options 生成の設定
+type Options struct {
+	// Count 生成するTodoの件数
+	Count int
+	// BatchSize 1回のINSERTで作成する件数
+	BatchSize int
+	// Concurrency 並列にINSERTするワーカー数
+	Concurrency int
+	// Seed 乱数のシード（同じシード・件数・バッチサイズなら同じ内容を生成する。作成日時は実行時刻が基準）
+	Seed int64
+	// Span 作成日時を分布させる期間（実行時刻から遡る）
+	Span time.Duration
+}
+
+// 生成するTodoの分布
+var (
+	// priorityWeights 優先度の割合（%）
+	priorityWeights = []struct {
+		priority model.Priority
+		weight   int
+	}{
+		{model.PriorityLow, 30},
+		{model.PriorityMedium, 45},
+		{model.PriorityHigh, 18},
+		{model.PriorityUrgent, 7},
+	}
+	// dueRatio 期限を設定する割合
+	dueRatio = 0.55
+	// tagRatio タグを付ける割合
+	tagRatio = 0.4
+	// descriptionRatio 説明を付ける割合
+	descriptionRatio = 0.3
+
+	verbs    = []string{"確認する", "レビューする", "作成する", "更新する", "連絡する", "予約する", "支払う", "提出する", "整理する", "調査する"}
+	subjects = []string{"見積書", "議事録", "請求書", "設計書", "週報", "歯医者", "経費精算", "プルリクエスト", "会議室", "契約書", "障害報告", "買い物リスト"}
+	tagPool  = []string{"work", "home", "shopping", "finance", "health", "project-a", "project-b", "review", "errand", "someday"}
+)
+
+// columns 生成したTodoで設定する列
+var columns = []string{"title", "description", "completed", "priority", "due_date", "tags", "created_at", "updated_at", "completed_at"}
+
+// Run opts.Count件のTodoをバッチに分けてワーカーで並列にINSERTし、作成した件数を返す
+// progressは各バッチの作成後に作成済みの件数で呼ばれる（nilの場合は呼ばない）
+// ドメインイベントの発行・通知・キャッシュの無効化は行わない
+func Run(ctx context.Context, gdb *gorm.DB, opts Options, progress func(created int)) (int, error) {
+	if opts.Count < 1 {
+		return 0, fmt.Errorf("生成する件数は1以上を指定してください（現在: %d）", opts.Count)
+	}
+	if opts.BatchSize < 1 || opts.BatchSize > 2000 {
+		return 0, fmt.Errorf("バッチサイズは1〜2000を指定してください（現在: %d）", opts.BatchSize)
+	}
+	if opts.Span <= 0 {
+		return 0, fmt.Errorf("作成日時の期間は0より大きい時間を指定してください（現在: %s）", opts.Span)
+	}
+
+	// 1バッチのSQLは大きいため、SQLログ・スロークエリの警告は出さない
+	gdb = gdb.Session(&gorm.Session{Logger: gdb.Logger.LogMode(logger.Error)})
+	now := time.Now()
+
+	batches := make([]int, 0, (opts.Count+opts.BatchSize-1)/opts.BatchSize)
+	for start := 0; start < opts.Count; start += opts.BatchSize {
+		batches = append(batches, start)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	created := 0
+	var failure error
+	workerpool.Run(ctx, opts.Concurrency, batches, func(ctx context.Context, start int) {
+		// バッチごとにシードを分け、並列に実行しても内容が実行順に依存しないようにする
+		rng := rand.New(rand.NewSource(opts.Seed + int64(start)))
+		todos := make([]*model.Todo, min(opts.BatchSize, opts.Count-start))
+		for i := range todos {
+			todos[i] = generate(rng, now, opts.Span)
+		}
+		err := gdb.WithContext(ctx).Select(columns).Create(&todos).Error
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if failure == nil {
+				failure = fmt.Errorf("Todoの作成に失敗しました: %w", err)
+				cancel()
+			}
+			return
+		}
+		created += len(todos)
+		if progress != nil {
+			progress(created)
+		}
+	})
+	if failure != nil {
+		return created, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// generate 1件のTodoを生成する
+// 作成日時は最近ほど多く、古いTodoほど完了している割合が高い。期限は作成日時から数日〜数か月後に偏らせる
+func generate(rng *rand.Rand, now time.Time, span time.Duration) *model.Todo {
+	// 作成日時: 経過時間を一様乱数の2乗で偏らせ、最近作成したTodoを多くする
+	u := rng.Float64()
+	age := time.Duration(u * u * float64(span))
+	createdAt := now.Add(-age)
+
+	todo := &model.Todo{
+		Title:     fmt.Sprintf("%sを%s", subjects[rng.Intn(len(subjects))], verbs[rng.Intn(len(verbs))]),
+		Priority:  pickPriority(rng),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+
+	if rng.Float64() < descriptionRatio {
+		todo.Description = fmt.Sprintf("負荷試験用のデータ（%d）", rng.Intn(100000))
+	}
+	if rng.Float64() < tagRatio {
+		todo.Tags = pickTags(rng)
+	}
+	if rng.Float64() < dueRatio {
+		// 期限までの日数: 対数正規分布（中央値 約4.5日、上限180日）
+		days := math.Min(math.Exp(rng.NormFloat64()+1.5), 180)
+		due := createdAt.Add(time.Duration(days * float64(24*time.Hour))).Truncate(time.Hour)
+		todo.DueDate = &due
+	}
+
+	// 完了率: 作成直後は20%、90日以上前に作成したTodoは80%
+	ratio := 0.2 + 0.6*math.Min(age.Hours()/(90*24), 1)
+	if rng.Float64() < ratio {
+		// 完了までの時間: 平均3日の指数分布（実行時刻を超えない）
+		completedAt := createdAt.Add(time.Duration(rng.ExpFloat64() * float64(3*24*time.Hour)))
+		if completedAt.After(now) {
+			completedAt = now
+		}
+		todo.Completed = true
+		todo.CompletedAt = &completedAt
+		todo.UpdatedAt = completedAt
+	} else if rng.Float64() < 0.5 {
+		// 未完了のTodoの半数は作成後に更新されている
+		todo.UpdatedAt = createdAt.Add(time.Duration(rng.Float64() * float64(age)))
+	}
+	return todo
+}
+
+// pickPriority 割合に従って優先度を選ぶ
+func pickPriority(rng *rand.Rand) model.Priority {
+	n := rng.Intn(100)
+	for _, w := range priorityWeights {
+		if n < w.weight {
+			return w.priority
+		}
+		n -= w.weight
+	}
+	return model.PriorityMedium
+}
+
+// pickTags 1〜3個のタグを重複なく選ぶ
+func pickTags(rng *rand.Rand) model.Tags {
+	count := 1 + rng.Intn(3)
+	tags := make(model.Tags, 0, count)
+	for _, i := range rng.Perm(len(tagPool))[:count] {
+		tags = append(tags, tagPool[i])
+	}
+	return tags
+}
+
+// Summary 生成したTodoの分布を確認するための集計（優先度ごとの件数・完了率・期限ありの割合）
+func Summary(ctx context.Context, gdb *gorm.DB) (string, error) {
+	var rows []struct {
+		Priority  string
+		Total     int64
+		Completed int64
+		WithDue   int64
+	}
+	err := gdb.WithContext(ctx).Model(&model.Todo{}).
+		Select("priority, COUNT(*) AS total, COUNT(*) FILTER (WHERE completed) AS completed, COUNT(due_date) AS with_due").
+		Group("priority").Order("priority").
+		Scan(&rows).Error
+	if err != nil {
+		return "", fmt.Errorf("Todoの集計に失敗しました: %w", err)
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%-8s %10d件  完了 %5.1f%%  期限あり %5.1f%%\n",
+			row.Priority, row.Total, percent(row.Completed, row.Total), percent(row.WithDue, row.Total))
+	}
+	return b.String(), nil
+}
+
+// percent 割合（%）
+func percent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
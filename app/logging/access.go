@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"myapp/config"
+	"os"
+	"slices"
+	"sync"
+)
+
+// accessLogger アクセスログ専用のロガー（LOG_FORMAT・LOG_LEVELに関わらずJSONで出力）
+var accessLogger = slog.New(&contextHandler{Handler: slog.NewJSONHandler(os.Stdout, nil)})
+
+// shouldLogAccess アクセスログを出力するか判定（エラーレスポンスはサンプリングせず常に出力）
+func shouldLogAccess(cfg config.AccessLogConfig, path string, status int) bool {
+	if !cfg.Enabled || slices.Contains(cfg.ExcludePaths, path) {
+		return false
+	}
+	if status >= 400 || cfg.SampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < cfg.SampleRate
+}
+
+// accessInfo リクエスト処理中に内側のハンドラーから設定されるアクセスログ項目
+type accessInfo struct {
+	mu     sync.Mutex
+	userID string
+}
+
+type accessInfoContextKey struct{}
+
+// SetUserID アクセスログに出力するユーザーIDを設定（認証処理から呼び出す）
+func SetUserID(ctx context.Context, userID string) {
+	info, ok := ctx.Value(accessInfoContextKey{}).(*accessInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	info.userID = userID
+	info.mu.Unlock()
+}
+
+// getUserID 設定されたユーザーIDを取得
+func (i *accessInfo) getUserID() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.userID
+}
+
+// logAccess アクセスログを出力
+func logAccess(ctx context.Context, cfg config.AccessLogConfig, info *accessInfo, attrs ...slog.Attr) {
+	if userID := info.getUserID(); userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+	if cfg.SampleRate < 1 {
+		attrs = append(attrs, slog.Float64("sample_rate", cfg.SampleRate))
+	}
+	accessLogger.LogAttrs(ctx, slog.LevelInfo, "access", attrs...)
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level 実行中に変更可能なログレベル
+var level = new(slog.LevelVar)
+
+// Setup slogのデフォルトロガーを設定
+// format: json または text（デフォルト: text）
+// levelName: debug / info / warn / error（デフォルト: info）
+func Setup(levelName, format string) {
+	SetLevel(levelName)
+	slog.SetDefault(New(os.Stdout, format))
+}
+
+// New 指定した出力先・形式のロガーを作成
+func New(w io.Writer, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// SetLevel ログレベルを文字列で設定（不正な値の場合はinfo）
+func SetLevel(value string) {
+	switch strings.ToLower(value) {
+	case "debug":
+		level.Set(slog.LevelDebug)
+	case "warn", "warning":
+		level.Set(slog.LevelWarn)
+	case "error":
+		level.Set(slog.LevelError)
+	default:
+		level.Set(slog.LevelInfo)
+	}
+}
+
+// Level 現在のログレベルを返す
+func Level() slog.Level {
+	return level.Level()
+}
+
+type attrsContextKey struct{}
+
+// WithAttrs コンテキストにログ属性を追加（以降のslog.*Context呼び出しで出力される）
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsContextKey{}, merged)
+}
+
+// contextHandler コンテキストに積まれた属性をログレコードに付与するハンドラー
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle コンテキストの属性を付与してから出力
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsContextKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs 属性付きのハンドラーを返す
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup グループ付きのハンドラーを返す
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"myapp/config"
+	"myapp/tracing"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Middleware リクエスト情報をログコンテキストに付与し、完了時にJSONのアクセスログを出力するミドルウェア
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		}
+		if traceID := tracing.TraceIDFromContext(r.Context()); traceID != "" {
+			attrs = append(attrs, slog.String("trace_id", traceID))
+		}
+		ctx := WithAttrs(r.Context(), attrs...)
+
+		info := &accessInfo{}
+		ctx = context.WithValue(ctx, accessInfoContextKey{}, info)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		// 設定の再読み込みを反映するため、リクエストごとに現在の設定を参照する
+		cfg := config.Current().AccessLog
+		if !shouldLogAccess(cfg, r.URL.Path, status) {
+			return
+		}
+
+		elapsed := time.Since(start)
+		logAccess(ctx, cfg, info,
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
@@ -0,0 +1,201 @@
+// Package mailin 専用メールボックスをIMAPでポーリングし、受信したメールからTodoを作成する
+package mailin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxMessageKeyLength Message-IDをそのまま重複排除のキーに使う最大長
+const maxMessageKeyLength = 255
+
+// maxPartDepth マルチパートの入れ子を辿る深さの上限
+const maxPartDepth = 5
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// wordDecoder ISO-2022-JP等の文字コードにも対応したMIMEエンコードワードのデコーダー
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// Message 受信したメール
+type Message struct {
+	// Key 重複排除のキー（Message-ID。ない場合はメール全体のSHA-256）
+	Key string
+	// From 送信者のメールアドレス（小文字）
+	From    string
+	Subject string
+	// Text 本文（text/plainを優先し、HTMLのみの場合はタグを除いたテキスト）
+	Text string
+	Date time.Time
+}
+
+// Parse RFC 5322形式のメールを解析する
+func Parse(raw []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("メールを解析できません: %w", err)
+	}
+
+	msg := &Message{}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(m.Header.Get("From")); err == nil {
+		msg.From = strings.ToLower(from.Address)
+	}
+	msg.Subject = decodeHeader(m.Header.Get("Subject"))
+	if date, err := m.Header.Date(); err == nil {
+		msg.Date = date
+	}
+
+	msg.Key = strings.TrimSpace(m.Header.Get("Message-ID"))
+	if msg.Key == "" || len(msg.Key) > maxMessageKeyLength {
+		sum := sha256.Sum256(raw)
+		msg.Key = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	plain, htmlText, err := readPart(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+	if plain == "" && htmlText != "" {
+		plain = htmlToText(htmlText)
+	}
+	msg.Text = cleanText(plain)
+	return msg, nil
+}
+
+// readPart パートの本文を読み込み、最初のtext/plainとtext/htmlを返す（添付ファイルは無視する）
+func readPart(contentType, encoding string, body io.Reader, depth int) (string, string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth || params["boundary"] == "" {
+			return "", "", nil
+		}
+		var plain, htmlText string
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", fmt.Errorf("マルチパートを解析できません: %w", err)
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			p, h, err := readPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+		return plain, htmlText, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+	text, err := decodeBody(body, encoding, params["charset"])
+	if err != nil {
+		return "", "", err
+	}
+	if mediaType == "text/html" {
+		return "", text, nil
+	}
+	return text, "", nil
+}
+
+// decodeBody 転送エンコーディングと文字コードを解いて本文を文字列にする
+func decodeBody(body io.Reader, encoding, charset string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	if charset != "" {
+		decoded, err := charsetReader(charset, body)
+		if err != nil {
+			return "", err
+		}
+		body = decoded
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("本文を読み込めません: %w", err)
+	}
+	return string(b), nil
+}
+
+// charsetReader 文字コードをUTF-8に変換するReader（不明な文字コードはエラー）
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "us-ascii", "ascii":
+		return input, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("対応していない文字コードです: %q", charset)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+// decodeHeader MIMEエンコードされたヘッダーをデコードする（失敗した場合は元の値）
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// htmlToText HTMLのタグを除いてテキストにする
+func htmlToText(s string) string {
+	s = htmlDropPattern.ReplaceAllString(s, "")
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+// cleanText 改行を揃え、署名（"-- " の行以降）と連続する空行を除く
+func cleanText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		// quoted-printableは行末の空白を除くため、署名の区切りは "--" になる場合がある
+		lines[i] = strings.TrimRight(line, " \t")
+		if lines[i] == "--" {
+			lines = lines[:i]
+			break
+		}
+	}
+	s = blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
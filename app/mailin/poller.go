@@ -0,0 +1,179 @@
+package mailin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db/model"
+	"myapp/imap"
+	"myapp/jobs"
+	"myapp/service"
+	"strings"
+	"time"
+)
+
+// maxMessageSize Todoを作成するメールのサイズの上限（超えるメールは既読にして無視する）
+const maxMessageSize = 10 << 20
+
+// Options IMAPサーバーと処理するメールボックスの設定
+type Options struct {
+	Addr     string
+	TLS      bool
+	Username string
+	Password string
+	Mailbox  string
+	// ProcessedMailbox 処理したメールの移動先（空の場合は既読にするのみ）
+	ProcessedMailbox string
+	// AllowedSenders Todoを作成できる送信者（メールアドレスまたは "@example.com" 形式のドメイン）
+	AllowedSenders []string
+}
+
+// Poller メールボックスをポーリングしてTodoを作成する
+type Poller struct {
+	opts        Options
+	mailService service.MailService
+	allowed     map[string]bool
+}
+
+// NewPoller 新しいポーラーを作成
+func NewPoller(opts Options, mailService service.MailService) *Poller {
+	allowed := make(map[string]bool, len(opts.AllowedSenders))
+	for _, sender := range opts.AllowedSenders {
+		allowed[strings.ToLower(strings.TrimSpace(sender))] = true
+	}
+	return &Poller{opts: opts, mailService: mailService, allowed: allowed}
+}
+
+// Watch intervalごとに未読のメールを処理する（ctxがキャンセルされるまでブロック）
+func (p *Poller) Watch(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("mail-inbound", "受信メールからのTodo作成", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job.Run(ctx, func(ctx context.Context) error {
+			_, err := p.Poll(ctx)
+			return err
+		})
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll 未読のメールからTodoを作成し、作成した件数を返す
+// 作成に失敗したメールは未読のまま残し、次回のポーリングで再試行する
+func (p *Poller) Poll(ctx context.Context) (int, error) {
+	client, err := imap.Dial(ctx, p.opts.Addr, p.opts.TLS)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	if err := client.Login(ctx, p.opts.Username, p.opts.Password); err != nil {
+		return 0, err
+	}
+	if err := client.Select(ctx, p.opts.Mailbox); err != nil {
+		return 0, err
+	}
+	uids, err := client.SearchUnseen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, uid := range uids {
+		done, err := p.process(ctx, client, uid)
+		if err != nil {
+			if ctx.Err() != nil {
+				return created, ctx.Err()
+			}
+			slog.ErrorContext(ctx, "受信メールからのTodo作成に失敗しました", "uid", uid, "error", err)
+			continue
+		}
+		if done {
+			created++
+		}
+		if err := p.finish(ctx, client, uid); err != nil {
+			return created, fmt.Errorf("処理済みのメールを既読にできません: %w", err)
+		}
+	}
+
+	if err := client.Logout(ctx); err != nil {
+		slog.WarnContext(ctx, "IMAPサーバーからのログアウトに失敗しました", "error", err)
+	}
+	return created, nil
+}
+
+// process 1通のメールからTodoを作成（作成しなかったメールはfalse。エラーの場合は未読のまま残す）
+func (p *Poller) process(ctx context.Context, client *imap.Client, uid uint32) (bool, error) {
+	size, err := client.Size(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	if size > maxMessageSize {
+		slog.WarnContext(ctx, "サイズの上限を超えたメールを無視しました", "uid", uid, "size", size)
+		return false, nil
+	}
+
+	raw, err := client.Fetch(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	msg, err := Parse(raw)
+	if err != nil {
+		slog.WarnContext(ctx, "解析できないメールを無視しました", "uid", uid, "error", err)
+		return false, nil
+	}
+	if !p.senderAllowed(msg.From) {
+		slog.WarnContext(ctx, "許可されていない送信者からのメールを無視しました", "from", msg.From)
+		return false, nil
+	}
+
+	todo, err := p.mailService.CreateTodo(ctx, &model.InboundMailRequest{
+		MessageKey: msg.Key,
+		Sender:     msg.From,
+		Subject:    msg.Subject,
+		Body:       msg.Text,
+		ReceivedAt: msg.Date,
+	})
+	if errors.Is(err, service.ErrMailDuplicate) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	slog.InfoContext(ctx, "受信メールからTodoを作成しました", "todo_id", todo.ID, "from", msg.From)
+	return true, nil
+}
+
+// finish 処理したメールを既読にし、移動先が設定されていれば移動する
+func (p *Poller) finish(ctx context.Context, client *imap.Client, uid uint32) error {
+	if err := client.MarkSeen(ctx, uid); err != nil {
+		return err
+	}
+	if p.opts.ProcessedMailbox == "" {
+		return nil
+	}
+	if err := client.Move(ctx, uid, p.opts.ProcessedMailbox); err != nil {
+		// 既読にしてあるため再処理はされない。移動のみ失敗として記録する
+		slog.WarnContext(ctx, "処理済みのメールを移動できません", "uid", uid, "mailbox", p.opts.ProcessedMailbox, "error", err)
+	}
+	return nil
+}
+
+// senderAllowed 送信者のアドレスまたはドメインが許可されているか
+func (p *Poller) senderAllowed(from string) bool {
+	if from == "" {
+		return false
+	}
+	if p.allowed[from] {
+		return true
+	}
+	_, domain, ok := strings.Cut(from, "@")
+	return ok && p.allowed["@"+domain]
+}
@@ -2,21 +2,78 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"myapp/adminauth"
+	"myapp/bench"
+	"myapp/bodylimit"
+	"myapp/cache"
+	"myapp/caldav"
+	"myapp/compress"
+	"myapp/config"
+	"myapp/cors"
+	"myapp/csrf"
 	"myapp/db"
+	"myapp/diagnostics"
+	"myapp/errorreport"
+	"myapp/events"
+	"myapp/feature"
+	"myapp/gcal"
+	"myapp/github"
 	"myapp/handler"
+	"myapp/health"
+	"myapp/httpcache"
+	"myapp/httpserver"
+	"myapp/i18n"
+	"myapp/ifttt"
+	"myapp/ipfilter"
+	"myapp/jira"
+	"myapp/llm"
+	"myapp/loadgen"
+	"myapp/logging"
+	"myapp/mailin"
+	"myapp/maintenance"
+	"myapp/metrics"
+	"myapp/mstodo"
+	"myapp/notify"
+	"myapp/notion"
+	"myapp/pathnorm"
+	"myapp/profiling"
+	"myapp/queue"
+	"myapp/quickadd"
+	"myapp/ratelimit"
+	"myapp/recovery"
+	"myapp/recurrence"
+	"myapp/reload"
+	"myapp/replay"
+	"myapp/requestid"
+	"myapp/scheduler"
+	"myapp/security"
 	"myapp/service"
+	"myapp/session"
+	"myapp/shutdown"
+	"myapp/storage"
+	"myapp/telegram"
+	"myapp/timeout"
+	"myapp/tracing"
+	"myapp/version"
+	"myapp/webhook"
+	"myapp/webpush"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 // ヘルスチェック用のレスポンス構造体
@@ -66,6 +123,10 @@ func dbHealthHandler(ctx context.Context, input *struct{}) (*HealthCheckResponse
 		return nil, huma.Error503ServiceUnavailable("データベース接続が初期化されていません")
 	}
 
+	if db.GetBreakerState() == db.BreakerOpen {
+		return nil, huma.Error503ServiceUnavailable(db.ErrCircuitOpen.Error())
+	}
+
 	sqlDB, err := database.DB()
 	if err != nil || sqlDB.Ping() != nil {
 		return nil, huma.Error503ServiceUnavailable("データベース接続に問題があります")
@@ -84,145 +145,1020 @@ func dbHealthHandler(ctx context.Context, input *struct{}) (*HealthCheckResponse
 	}, nil
 }
 
-func main() {
-	// データベース接続
-	log.Println("データベースに接続中...")
+// fatal エラーを記録してプロセスを終了
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// redisAddrs 有効な機能が使うRedisのアドレス（重複を除く）
+func redisAddrs(cfg *config.Config) []string {
+	var addrs []string
+	if cfg.Session.Enabled && cfg.Session.Store == "redis" {
+		addrs = append(addrs, cfg.Session.RedisAddr)
+	}
+	if cfg.RateLimit.Backend == "redis" {
+		addrs = append(addrs, cfg.RateLimit.RedisAddr)
+	}
+	if cfg.Replay.NonceStore == "redis" {
+		addrs = append(addrs, cfg.Replay.RedisAddr)
+	}
+	if cfg.Cache.Enabled && cfg.Cache.Backend == "redis" {
+		addrs = append(addrs, cfg.Cache.RedisAddr)
+	}
+	slices.Sort(addrs)
+	return slices.Compact(addrs)
+}
+
+// waitForDependencies DBに接続し、使用するRedis・NATSに接続できるまで待つ
+// コンテナの起動順で依存サービスがまだ起動していない場合に即座に終了しないよう、startup.wait_timeout の間は指数バックオフで再試行する
+func waitForDependencies(cfg *config.Config) error {
+	ctx := context.Background()
+	if cfg.Startup.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Startup.WaitTimeout)
+		defer cancel()
+	}
+	backoff := health.Backoff{Base: cfg.Startup.BackoffBase, Max: cfg.Startup.BackoffMax}
+
+	// 接続プールの作成ではPingしない（DisableAutomaticPing）ため、DBが起動していなくても失敗しない。Pingが通るまで待つ
 	if err := db.Connect(); err != nil {
-		log.Fatalf("データベース接続エラー: %v", err)
+		return err
+	}
+	if err := health.WaitFor(ctx, "データベース", backoff, (&health.DBChecker{}).Check); err != nil {
+		return err
+	}
+
+	for _, addr := range redisAddrs(cfg) {
+		if err := health.WaitFor(ctx, "Redis（"+addr+"）", backoff, health.NewTCPChecker("redis", addr, false).Check); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Events.Enabled && cfg.Events.Broker != "kafka" {
+		if u, err := url.Parse(cfg.Events.NATSURL); err == nil && u.Host != "" {
+			if err := health.WaitFor(ctx, "NATS（"+u.Host+"）", backoff, health.NewTCPChecker("nats", u.Host, false).Check); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runLoadgen loadgenサブコマンドの引数を解釈し、負荷試験用のTodoを生成して分布を出力する
+func runLoadgen(args []string, concurrency int) {
+	fs := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	count := fs.Int("count", 1000000, "生成するTodoの件数")
+	batchSize := fs.Int("batch", 1000, "1回のINSERTで作成する件数（1〜2000）")
+	workers := fs.Int("concurrency", concurrency, "並列にINSERTするワーカー数（未指定時はBULK_CONCURRENCY）")
+	seed := fs.Int64("seed", 1, "乱数のシード")
+	span := fs.Duration("span", 365*24*time.Hour, "作成日時を分布させる期間（実行時刻から遡る）")
+	analyze := fs.Bool("analyze", true, "生成後にANALYZEで統計情報を更新する（推定件数・実行計画に反映するため）")
+	_ = fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	started := time.Now()
+	step := max(*count/10, 1)
+	next := step
+	created, err := loadgen.Run(ctx, db.GetDB(), loadgen.Options{
+		Count:       *count,
+		BatchSize:   *batchSize,
+		Concurrency: *workers,
+		Seed:        *seed,
+		Span:        *span,
+	}, func(created int) {
+		if created >= next {
+			fmt.Printf("%d / %d 件作成しました（%s）\n", created, *count, time.Since(started).Round(time.Second))
+			next = (created/step + 1) * step
+		}
+	})
+	if err != nil {
+		fatal(fmt.Sprintf("負荷試験用データの生成に失敗しました（作成済み: %d件）", created), err)
+	}
+	fmt.Printf("%d件のTodoを作成しました（%s）\n", created, time.Since(started).Round(time.Millisecond))
+
+	if *analyze {
+		if err := db.GetDB().WithContext(ctx).Exec("ANALYZE todos").Error; err != nil {
+			fatal("統計情報の更新に失敗しました", err)
+		}
+	}
+	summary, err := loadgen.Summary(ctx, db.GetDB())
+	if err != nil {
+		fatal("負荷試験用データの集計に失敗しました", err)
+	}
+	fmt.Print(summary)
+}
+
+// runBench benchサブコマンドの引数を解釈し、Todoが足りなければ生成してから、クエリの発行方法・ページングの方式ごとの実行時間を計測して出力する
+func runBench(args []string, concurrency int) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	rows := fs.Int("rows", 0, "計測の前にTodoがこの件数になるまで負荷試験用のTodoを生成する（0は生成しない）")
+	iterations := fs.Int("iterations", 1000, "クエリの比較で各ケースを実行する回数")
+	pagingIterations := fs.Int("paging-iterations", 20, "ページングの比較で各ケースを実行する回数")
+	pageSize := fs.Int("page-size", 50, "一覧の1ページの件数")
+	depths := fs.String("depths", "1000,10000,100000,500000", "ページングを比較するページの位置（先頭から読み飛ばす件数。カンマ区切り）")
+	_ = fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var offsets []int
+	for _, v := range strings.Split(*depths, ",") {
+		depth, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || depth < 1 {
+			fatal("ページの位置は1以上の整数をカンマ区切りで指定してください", fmt.Errorf("-depths %q", *depths))
+		}
+		offsets = append(offsets, depth)
+	}
+
+	var existing int64
+	if err := db.GetDB().WithContext(ctx).Raw("SELECT COUNT(*) FROM todos WHERE deleted_at IS NULL").Scan(&existing).Error; err != nil {
+		fatal("Todoの件数の取得に失敗しました", err)
+	}
+	if missing := int64(*rows) - existing; missing > 0 {
+		fmt.Printf("Todoが%d件のため、%d件を生成します\n", existing, missing)
+		created, err := loadgen.Run(ctx, db.GetDB(), loadgen.Options{
+			Count:       int(missing),
+			BatchSize:   1000,
+			Concurrency: concurrency,
+			Seed:        1,
+			Span:        365 * 24 * time.Hour,
+		}, nil)
+		if err != nil {
+			fatal(fmt.Sprintf("負荷試験用データの生成に失敗しました（作成済み: %d件）", created), err)
+		}
+		// 実行計画が生成したTodoの分布に基づくよう統計情報を更新する
+		if err := db.GetDB().WithContext(ctx).Exec("ANALYZE todos").Error; err != nil {
+			fatal("統計情報の更新に失敗しました", err)
+		}
+	}
+
+	results, err := bench.Run(ctx, db.GetDB(), bench.Options{
+		Iterations:       *iterations,
+		PagingIterations: *pagingIterations,
+		PageSize:         *pageSize,
+		Depths:           offsets,
+	})
+	if err != nil {
+		fatal("計測に失敗しました", err)
+	}
+	fmt.Print(bench.Format(results))
+}
+
+// バージョン情報用のレスポンス構造体
+type VersionResponse struct {
+	Body version.Info
+}
+
+// バージョン情報用のハンドラー
+func versionHandler(ctx context.Context, input *struct{}) (*VersionResponse, error) {
+	return &VersionResponse{
+		Body: version.Get(),
+	}, nil
+}
+
+// importMaxBodyBytes 取り込みAPIのリクエストボディの上限（BODY_LIMIT_MAX_BYTES とは別にHuma側の上限を引き上げる）
+const importMaxBodyBytes = 10 << 20
+
+func main() {
+	configFile := flag.String("config", "", "設定ファイルのパス（YAMLまたはTOML。未指定時はCONFIG_FILE環境変数またはconfig.yaml）")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "未適用マイグレーションのSQLを出力して終了（適用はしない）")
+	generateVAPIDKeys := flag.Bool("generate-vapid-keys", false, "Web Push通知用のVAPIDの鍵ペアを生成して出力し終了")
+	listenPort := flag.Int("port", 0, "HTTPサーバーのポート（PORT環境変数・設定ファイルより優先）")
+	bindAddress := flag.String("bind-address", "", "待ち受けるアドレス（例: 127.0.0.1。BIND_ADDRESS環境変数・設定ファイルより優先）")
+	dumpOpenAPIPath := flag.String("dump-openapi", "", "OpenAPIのスペックを指定したファイルに出力して終了（.jsonはJSON、それ以外はYAML。「-」で標準出力）")
+	flag.Parse()
+
+	// VAPIDの鍵ペアの生成（設定・DBを必要としない）
+	if *generateVAPIDKeys {
+		publicKey, privateKey, err := webpush.GenerateKeys()
+		if err != nil {
+			fatal("VAPIDの鍵ペアの生成に失敗しました", err)
+		}
+		fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+		return
+	}
+
+	// 待ち受けのフラグは対応する環境変数として設定し、設定ファイル・環境変数より優先する（設定を再読み込みしても同じ値になる）
+	if *listenPort != 0 {
+		os.Setenv("PORT", strconv.Itoa(*listenPort))
+	}
+	if *bindAddress != "" {
+		os.Setenv("BIND_ADDRESS", *bindAddress)
+	}
+
+	// 設定の読み込み（デフォルト値 → 設定ファイル → 環境変数の順に上書き）
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fatal("設定の読み込みエラー", err)
+	}
+
+	// ロガーの初期化
+	logging.Setup(cfg.Log.Level, cfg.Log.Format)
+
+	// OpenAPIのスペックの出力（サーバーを起動せず、DBにも接続しない）
+	if *dumpOpenAPIPath != "" {
+		if err := dumpOpenAPI(*dumpOpenAPIPath, cfg.Validation.StrictUnknownFields); err != nil {
+			fatal("OpenAPIのスペックの出力に失敗しました", err)
+		}
+		return
+	}
+
+	// 設定の検証（問題のある項目をすべて出力して終了）
+	if err := cfg.Validate(); err != nil {
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			for _, fe := range verr.Errors {
+				slog.Error("設定値が不正です", "field", fe.Field, "env", fe.Env, "reason", fe.Message)
+			}
+		}
+		fatal("設定の検証エラー", err)
+	}
+
+	config.SetCurrent(cfg)
+	db.Configure(cfg.Database)
+
+	// トレーシングの初期化
+	if err := tracing.Init(cfg.Tracing.Endpoint, cfg.Tracing.ServiceName); err != nil {
+		fatal("トレーシングの初期化に失敗しました", err)
+	}
+
+	// エラーレポーティングの初期化（SENTRY_DSN設定時のみ）
+	if err := errorreport.Init(version.Version); err != nil {
+		fatal("Sentryの初期化エラー", err)
+	}
+
+	// データベース接続（DB・Redis・NATSが起動するまで startup.wait_timeout の間待つ）
+	slog.Info("データベースに接続中...")
+	if err := waitForDependencies(cfg); err != nil {
+		fatal("データベース接続エラー", err)
 	}
 
+	// dry-runモード: SQLを出力して終了
+	if *migrateDryRun {
+		statements, err := db.DryRunMigrations(context.Background())
+		if err != nil {
+			fatal("マイグレーションdry-runエラー", err)
+		}
+		for _, statement := range statements {
+			fmt.Printf("%s;\n", statement)
+		}
+		if err := db.Close(); err != nil {
+			slog.Error("データベース接続の終了エラー", "error", err)
+		}
+		return
+	}
+
+	// バックグラウンドワーカーとシャットダウン処理の管理
+	shutdownManager := shutdown.NewManager()
+
 	// マイグレーション実行
-	log.Println("データベースマイグレーション実行中...")
+	slog.Info("データベースマイグレーション実行中...")
 	if err := db.Migrate(); err != nil {
-		log.Fatalf("マイグレーションエラー: %v", err)
+		fatal("マイグレーションエラー", err)
+	}
+
+	// サブコマンド bench: クエリの発行方法・ページングの方式ごとの実行時間を計測して終了
+	if flag.Arg(0) == "bench" {
+		runBench(flag.Args()[1:], cfg.Bulk.Concurrency)
+		if err := db.Close(); err != nil {
+			slog.Error("データベース接続の終了エラー", "error", err)
+		}
+		return
+	}
+
+	// サブコマンド loadgen: 負荷試験用のTodoを生成して終了
+	if flag.Arg(0) == "loadgen" {
+		runLoadgen(flag.Args()[1:], cfg.Bulk.Concurrency)
+		if err := db.Close(); err != nil {
+			slog.Error("データベース接続の終了エラー", "error", err)
+		}
+		return
+	}
+
+	// フィーチャーフラグの登録（環境変数 FEATURE_<NAME> → 管理APIで保存した値の順に上書き）
+	feature.Register(feature.FlagAdminDBStats, "DB統計エンドポイント", true)
+	feature.Register(feature.FlagHealthDetail, "依存サービスの詳細ヘルスチェック", true)
+	feature.Register(feature.FlagMaintenance, "メンテナンスモード（APIの書き込みを503にする）", false)
+
+	// 非同期ジョブキュー（Webhook・メールの送信、LLMの処理。失敗したジョブは指数バックオフで再試行し、上限に達したジョブはデッドレターになる）
+	jobQueue := queue.New(queue.Options{
+		Concurrency:  cfg.Queue.Concurrency,
+		PollInterval: cfg.Queue.PollInterval,
+		Lease:        cfg.Queue.LockLease,
+		MaxAttempts:  cfg.Queue.MaxAttempts,
+		BackoffBase:  cfg.Queue.BackoffBase,
+		BackoffMax:   cfg.Queue.BackoffMax,
+	})
+	webhookClient := &http.Client{Timeout: cfg.Webhook.Timeout}
+	jobQueue.Register(webhook.JobKind, webhook.HandleJob(webhookClient), 0)
+	webhook.SetQueue(jobQueue)
+
+	// 登録した送信先へのTodoのイベントの配信（Outgoing Webhook。送信はジョブキューで行い、失敗し続けた送信先は自動で停止する）
+	// 送信先は管理APIで登録できるため、内部ネットワークへは送らない（SSRF対策）
+	endpointClient := webhookClient
+	if !cfg.Webhook.AllowPrivateNetworks {
+		endpointClient = webhook.NewPublicClient(cfg.Webhook.Timeout)
+	}
+	webhookEndpointService := service.NewWebhookEndpointService(endpointClient, jobQueue, cfg.Webhook.DisableAfter, cfg.Webhook.AllowPrivateNetworks)
+	jobQueue.Register(service.WebhookDeliveryJobKind, webhookEndpointService.HandleJob, cfg.Webhook.MaxAttempts)
+	events.AddListener(webhookEndpointService.Dispatch)
+
+	// Todoイベントの外部通知（Slack・Discord・Mattermost・Teams・メール・Web Push）
+	var subscriptions []notify.Subscription
+	if cfg.Notify.Slack.Enabled {
+		subscriptions = append(subscriptions, notify.NewSubscription(
+			notify.NewSlackChannel(cfg.Notify.Slack.WebhookURL, cfg.Notify.Slack.BotToken, cfg.Notify.Slack.Channel),
+			cfg.Notify.Slack.Events, cfg.Notify.Slack.MinPriority,
+		))
+	}
+	if cfg.Notify.Discord.Enabled {
+		subscriptions = append(subscriptions, notify.NewSubscription(
+			notify.NewDiscordChannel(cfg.Notify.Discord.WebhookURL, cfg.Notify.Discord.Username),
+			cfg.Notify.Discord.Events, cfg.Notify.Discord.MinPriority,
+		))
+	}
+	if cfg.Notify.Mattermost.Enabled {
+		subscriptions = append(subscriptions, notify.NewSubscription(
+			notify.NewMattermostChannel(cfg.Notify.Mattermost.WebhookURL, cfg.Notify.Mattermost.Username, cfg.Notify.Mattermost.Channel),
+			cfg.Notify.Mattermost.Events, cfg.Notify.Mattermost.MinPriority,
+		))
+	}
+	if cfg.Notify.Teams.Enabled {
+		subscriptions = append(subscriptions, notify.NewSubscription(
+			notify.NewTeamsChannel(cfg.Notify.Teams.WebhookURL),
+			cfg.Notify.Teams.Events, cfg.Notify.Teams.MinPriority,
+		))
+	}
+	var emailChannel *notify.EmailChannel
+	if email := cfg.Notify.Email; email.Enabled {
+		emailChannel = notify.NewEmailChannel(notify.SMTPConfig{
+			Host:        email.SMTPHost,
+			Port:        email.SMTPPort,
+			Username:    email.Username,
+			Password:    email.Password,
+			ImplicitTLS: email.ImplicitTLS,
+		}, email.From, email.To, email.MaxRetries)
+		emailChannel.UseQueue(jobQueue)
+		jobQueue.Register(notify.EmailJobKind, emailChannel.HandleJob, email.MaxRetries+1)
+		subscriptions = append(subscriptions, notify.NewSubscription(emailChannel, email.Events, email.MinPriority))
+	}
+	var pushHandler *handler.HumaPushHandler
+	if push := cfg.Notify.WebPush; push.Enabled {
+		vapid, err := webpush.NewVAPID(push.VAPIDPrivateKey, push.Subject)
+		if err != nil {
+			fatal("VAPIDの鍵の読み込みに失敗しました", err)
+		}
+		pushService := service.NewPushService(vapid, service.PushOptions{
+			TTL:          push.TTL,
+			AllowedHosts: push.AllowedHosts,
+		})
+		pushHandler = handler.NewHumaPushHandler(pushService)
+		subscriptions = append(subscriptions, notify.NewSubscription(
+			service.NewPushChannel(pushService),
+			push.Events, push.MinPriority,
+		))
+	}
+	notify.SetSubscriptions(subscriptions)
+	shutdownManager.Register(shutdown.PhaseFlush, "notify", notify.Wait)
+
+	// Todoのドメインイベント（CloudEvents）をメッセージブローカーへ発行
+	events.SetSource(cfg.Events.Source)
+	if cfg.Events.Enabled {
+		var broker events.Broker
+		switch cfg.Events.Broker {
+		case "kafka":
+			kafkaBroker, err := events.NewKafkaBroker(events.KafkaOptions{
+				Brokers:       cfg.Events.KafkaBrokers,
+				Topic:         cfg.Events.Topic,
+				TLS:           cfg.Events.KafkaTLS,
+				SASLMechanism: cfg.Events.KafkaSASLMechanism,
+				Username:      cfg.Events.KafkaUsername,
+				Password:      cfg.Events.KafkaPassword,
+			})
+			if err != nil {
+				fatal("Kafkaブローカーの作成に失敗しました", err)
+			}
+			broker = kafkaBroker
+		default:
+			natsBroker, err := events.NewNATSBroker(cfg.Events.NATSURL, cfg.Events.Topic)
+			if err != nil {
+				fatal("NATSブローカーの作成に失敗しました", err)
+			}
+			broker = natsBroker
+		}
+		publisher := events.NewPublisher(broker, cfg.Events.BufferSize)
+		events.SetPublisher(publisher)
+		shutdownManager.Go("events", publisher.Run)
+		shutdownManager.Register(shutdown.PhaseFlush, "events", publisher.Wait)
+	}
+
+	// 添付ファイル・エクスポート成果物等の保存先
+	storageOpts := storage.Options{
+		Driver:   cfg.Storage.Driver,
+		LocalDir: cfg.Storage.LocalDir,
+		S3: storage.S3Options{
+			Bucket:          cfg.Storage.S3.Bucket,
+			Region:          cfg.Storage.S3.Region,
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+			PathStyle:       cfg.Storage.S3.PathStyle,
+		},
+		GCS: storage.GCSOptions{
+			Bucket:   cfg.Storage.GCS.Bucket,
+			Endpoint: cfg.Storage.GCS.Endpoint,
+		},
+	}
+	if cfg.Storage.GCS.CredentialsFile != "" {
+		storageOpts.GCS.CredentialsJSON, err = os.ReadFile(cfg.Storage.GCS.CredentialsFile)
+		if err != nil {
+			fatal("GCSのサービスアカウントのキーを読み込めません", err)
+		}
+	}
+	objectStorage, err := storage.New(storageOpts)
+	if err != nil {
+		fatal("ストレージの初期化に失敗しました", err)
+	}
+
+	// 定期実行ジョブ（複数インスタンスで起動してもDBのロックで各予定時刻に1回だけ実行する）
+	schedulerLocation := time.Local
+	if cfg.Scheduler.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Scheduler.Timezone)
+		if err != nil {
+			fatal("スケジューラーのタイムゾーンを読み込めません", err)
+		}
+		schedulerLocation = loc
+	}
+	jobScheduler := scheduler.New(scheduler.Options{Location: schedulerLocation, LockLease: cfg.Scheduler.LockLease})
+	addJob := func(name, description, spec string, fn func(ctx context.Context) error) {
+		if err := jobScheduler.Add(name, description, spec, fn); err != nil {
+			fatal("ジョブを登録できません", err)
+		}
+	}
+
+	// 「今日」「期限切れ」の判定・リマインダー・ダイジェストは利用者のタイムゾーン（未設定の場合はスケジューラーのタイムゾーン）で行う
+	profileService := service.NewProfileService(schedulerLocation)
+	profileHandler := handler.NewHumaProfileHandler(profileService)
+	notificationService := service.NewNotificationService(profileService)
+	reminderService := service.NewReminderService(cfg.Notify.RemindBefore, cfg.Notify.RenotifyInterval, profileService)
+	if len(subscriptions) > 0 && cfg.Notify.OverdueCheckInterval > 0 {
+		addJob("deadline-notify", "期限切れ・期限間近のTodoのリマインダーの配信", "@every "+cfg.Notify.OverdueCheckInterval.String(), func(ctx context.Context) error {
+			_, err := reminderService.Deliver(ctx)
+			return err
+		})
+	}
+	if cfg.Notify.Email.Enabled && cfg.Notify.Email.DigestTime != "" {
+		at, _ := time.Parse("15:04", cfg.Notify.Email.DigestTime)
+		addJob("daily-digest", "日次ダイジェストの送信", fmt.Sprintf("%d %d * * *", at.Minute(), at.Hour()), notificationService.SendDigest)
+	}
+	var digestHandler *handler.HumaDigestHandler
+	if emailChannel != nil {
+		digestService := service.NewDigestService(emailChannel, profileService)
+		digestHandler = handler.NewHumaDigestHandler(digestService)
+		if cfg.Notify.Email.DigestCheckSchedule != "" {
+			addJob("user-digest", "送信先ごとの日次・週次ダイジェストの送信", cfg.Notify.Email.DigestCheckSchedule, func(ctx context.Context) error {
+				_, err := digestService.Deliver(ctx)
+				return err
+			})
+		}
+	}
+	if cfg.Scheduler.Purge.Schedule != "" {
+		purgeService := service.NewPurgeService(cfg.Scheduler.Purge.Retention, cfg.Queue.Retention)
+		addJob("purge", "保持期間を過ぎた削除済みTodo・取り込みの記録のパージ", cfg.Scheduler.Purge.Schedule, func(ctx context.Context) error {
+			_, err := purgeService.Purge(ctx)
+			return err
+		})
+	}
+	if cfg.Recurrence.Schedule != "" {
+		holidays, err := recurrence.NewHolidays(cfg.Recurrence.HolidayCalendar, cfg.Recurrence.Holidays)
+		if err != nil {
+			fatal("祝日のカレンダーを読み込めません", err)
+		}
+		recurrenceService := service.NewRecurrenceService(schedulerLocation, holidays)
+		addJob("recurrence", "繰り返しTodoの次回のTodoの生成", cfg.Recurrence.Schedule, func(ctx context.Context) error {
+			_, err := recurrenceService.Generate(ctx)
+			return err
+		})
+	}
+	// メールアドレスへの通知はメール通知が有効な場合のみ（nilのポインタをインターフェースに入れない）
+	var escalationMailer service.EscalationMailer
+	if emailChannel != nil {
+		escalationMailer = emailChannel
+	}
+	escalationService := service.NewEscalationService(escalationMailer, profileService)
+	escalationHandler := handler.NewHumaEscalationHandler(escalationService)
+	if cfg.Escalation.Schedule != "" {
+		addJob("escalation", "期限切れのTodoへのエスカレーションルールの適用", cfg.Escalation.Schedule, func(ctx context.Context) error {
+			_, err := escalationService.Escalate(ctx)
+			return err
+		})
+	}
+	snapshotService := service.NewSnapshotService(objectStorage, cfg.Export.Formats, cfg.Export.Keep)
+	snapshotHandler := handler.NewHumaSnapshotHandler(snapshotService)
+	if cfg.Export.Schedule != "" {
+		addJob("export-snapshot", "全データのスナップショットのストレージへのエクスポート", cfg.Export.Schedule, func(ctx context.Context) error {
+			_, err := snapshotService.Create(ctx)
+			return err
+		})
+	}
+	staleService := service.NewStaleService(cfg.Stale.After, cfg.Stale.Action)
+	staleHandler := handler.NewHumaStaleHandler(staleService)
+	if cfg.Stale.Schedule != "" {
+		addJob("stale", "長期間更新されていない未完了のTodo（放置タスク）の検出", cfg.Stale.Schedule, func(ctx context.Context) error {
+			_, err := staleService.Detect(ctx)
+			return err
+		})
+	}
+	if jobScheduler.Len() > 0 {
+		shutdownManager.Go("scheduler", jobScheduler.Run)
 	}
 
 	// サービスとハンドラーの初期化
-	todoService := service.NewTodoService()
+	todoService := service.NewTodoService(profileService)
+	adminService := service.NewAdminService()
+
+	// 一覧・統計クエリの結果のキャッシュ（todosへの書き込みはGORMのコールバックで検知し、一覧と書き込んだTodoの分を無効化）
+	if cfg.Cache.Enabled {
+		queryCache := cache.NewMemoryCache(cfg.Cache.MaxEntries)
+		if cfg.Cache.Backend == "redis" {
+			queryCache = cache.NewRedisCache(cfg.Cache.RedisAddr)
+		}
+		if err := db.GetDB().Use(cache.NewInvalidationPlugin(queryCache, map[string]cache.Rule{
+			"todos": {Namespaces: []string{service.TodoListCacheNamespace}, ItemNamespace: service.TodoItemCacheNamespace},
+			// 一覧の並び順（default_sort）の変更
+			"user_profiles": {Namespaces: []string{service.TodoListCacheNamespace}},
+		})); err != nil {
+			fatal("キャッシュ無効化プラグインの登録に失敗しました", err)
+		}
+		shutdownManager.Register(shutdown.PhaseResources, "cache", func(ctx context.Context) error {
+			return queryCache.Close()
+		})
+		todoService = service.NewCachedTodoService(todoService, queryCache, cfg.Cache.TTL)
+		adminService = service.NewCachedAdminService(adminService, queryCache, cfg.Cache.StatsTTL)
+	}
 	todoHandler := handler.NewHumaTodoHandler(todoService)
+	if cfg.Telegram.Enabled {
+		telegramClient := telegram.NewClient(cfg.Telegram.BotToken)
+		telegramHandler := telegram.NewTodoHandler(todoService)
+		shutdownManager.Go("telegram", func(ctx context.Context) {
+			telegram.Poll(ctx, telegramClient, telegramHandler, cfg.Telegram.ChatIDs())
+		})
+	}
+	if cfg.IMAP.Enabled {
+		// LLM機能が有効な場合は本文から期限・優先度を抽出する
+		var extractor *llm.Client
+		if cfg.LLM.Enabled && cfg.IMAP.UseLLM {
+			extractor, err = llm.NewClient(cfg.LLM.Provider, cfg.LLM.BaseURL, cfg.LLM.APIKey, cfg.LLM.Model, cfg.LLM.Timeout)
+			if err != nil {
+				fatal("LLMクライアントの作成に失敗しました", err)
+			}
+		}
+		// 抽出はジョブキューで行い、LLMの障害時も再試行する
+		mailService := service.NewMailService(todoService, extractor, jobQueue, profileService)
+		if extractor != nil {
+			jobQueue.Register(service.MailExtractJobKind, mailService.HandleExtractJob, 0)
+		}
+		mailPoller := mailin.NewPoller(mailin.Options{
+			Addr:             cfg.IMAP.Addr,
+			TLS:              cfg.IMAP.TLS,
+			Username:         cfg.IMAP.Username,
+			Password:         cfg.IMAP.Password,
+			Mailbox:          cfg.IMAP.Mailbox,
+			ProcessedMailbox: cfg.IMAP.ProcessedMailbox,
+			AllowedSenders:   cfg.IMAP.AllowedSenders,
+		}, mailService)
+		shutdownManager.Go("mail-inbound", func(ctx context.Context) {
+			mailPoller.Watch(ctx, cfg.IMAP.PollInterval)
+		})
+	}
+	shutdownManager.Go("queue", jobQueue.Run)
+	importService := service.NewImportService(cfg.Bulk.Concurrency, cfg.Bulk.InsertBatchSize)
+	importHandler := handler.NewHumaImportHandler(importService)
+	shutdownManager.Register(shutdown.PhaseFlush, "import", importService.Wait)
+	bulkService := service.NewBulkService(todoService, cfg.Bulk.Concurrency, cfg.Bulk.MaxItems, cfg.Bulk.InsertBatchSize)
+	bulkHandler := handler.NewHumaBulkHandler(bulkService)
+	shutdownManager.Register(shutdown.PhaseFlush, "bulk", bulkService.Wait)
+	adminHandler := handler.NewHumaAdminHandler(adminService)
+	featureService := service.NewFeatureService()
+	if err := featureService.LoadFeatures(context.Background()); err != nil {
+		fatal("フィーチャーフラグ読み込みエラー", err)
+	}
+	featureHandler := handler.NewHumaFeatureHandler(featureService)
+	maintenanceHandler := handler.NewHumaMaintenanceHandler(featureService)
+
+	// 設定のホットリロード（SIGHUPまたは管理API）
+	reloader := reload.NewReloader(*configFile, featureService)
+	shutdownManager.Go("sighup", reloader.WatchSignal)
+	if cfg.Secrets.RefreshInterval > 0 {
+		shutdownManager.Go("secrets-refresh", func(ctx context.Context) {
+			reloader.RefreshSecrets(ctx, cfg.Secrets.RefreshInterval)
+		})
+	}
+	reloadHandler := handler.NewHumaReloadHandler(reloader)
+
+	// Outgoing Webhookの署名シークレット・形式
+	webhook.SetLegacySignature(cfg.Webhook.LegacySignature)
+	webhookService := service.NewWebhookService(cfg.Webhook.SigningSecret)
+	if err := webhookService.LoadSecrets(context.Background()); err != nil {
+		slog.Warn("Webhookシークレットの読み込みに失敗しました（設定のシークレットで署名します）", "error", err)
+		if cfg.Webhook.SigningSecret != "" {
+			webhook.SetSecrets([]webhook.Secret{{Value: cfg.Webhook.SigningSecret}})
+		}
+	}
+	if cfg.Webhook.SecretRefreshInterval > 0 {
+		shutdownManager.Go("webhook-secrets-refresh", func(ctx context.Context) {
+			webhookService.RefreshSecrets(ctx, cfg.Webhook.SecretRefreshInterval)
+		})
+	}
+	webhookHandler := handler.NewHumaWebhookHandler(webhookService)
+	webhookEndpointHandler := handler.NewHumaWebhookEndpointHandler(webhookEndpointService)
+
+	// Googleカレンダーとの双方向同期
+	var calendarHandler *handler.HumaCalendarHandler
+	if cfg.Calendar.Enabled {
+		calendarService := service.NewCalendarService(
+			gcal.NewOAuthConfig(cfg.Calendar.ClientID, cfg.Calendar.ClientSecret, cfg.Calendar.RedirectURL),
+			cfg.Calendar.CalendarID, cfg.Calendar.EventDuration,
+		)
+		calendarHandler = handler.NewHumaCalendarHandler(calendarService)
+		if cfg.Calendar.SyncInterval > 0 {
+			shutdownManager.Go("calendar-sync", func(ctx context.Context) {
+				calendarService.WatchSync(ctx, cfg.Calendar.SyncInterval)
+			})
+		}
+	}
+
+	// Microsoft To Doからの取り込み
+	var mstodoHandler *handler.HumaMSTodoHandler
+	if cfg.MSTodo.Enabled {
+		mstodoHandler = handler.NewHumaMSTodoHandler(service.NewMSTodoService(
+			mstodo.NewOAuthConfig(cfg.MSTodo.ClientID, cfg.MSTodo.ClientSecret, cfg.MSTodo.RedirectURL, cfg.MSTodo.Tenant),
+			importService, cfg.MSTodo.ListProjects,
+		))
+	}
+
+	// GitHubのIssueとの同期（Webhookとポーリング）
+	var githubHandler *handler.HumaGitHubHandler
+	if cfg.GitHub.Enabled {
+		githubService := service.NewGitHubService(
+			github.NewClient(cfg.GitHub.APIURL, cfg.GitHub.Token),
+			cfg.GitHub.Repository, cfg.GitHub.WebhookSecret,
+		)
+		githubHandler = handler.NewHumaGitHubHandler(githubService)
+		if cfg.GitHub.PollInterval > 0 {
+			shutdownManager.Go("github-sync", func(ctx context.Context) {
+				githubService.WatchSync(ctx, cfg.GitHub.PollInterval)
+			})
+		}
+	}
+
+	// Jiraの課題との同期（ポーリング）
+	var jiraHandler *handler.HumaJiraHandler
+	if cfg.Jira.Enabled {
+		fields := cfg.Jira.Fields
+		jiraService := service.NewJiraService(
+			jira.NewClient(cfg.Jira.BaseURL, cfg.Jira.Email, cfg.Jira.APIToken),
+			cfg.Jira.BaseURL,
+			service.JiraOptions{
+				JQL:              cfg.Jira.JQL,
+				DoneTransition:   cfg.Jira.DoneTransition,
+				ReopenTransition: cfg.Jira.ReopenTransition,
+				TitleField:       fields.Title,
+				DescriptionField: fields.Description,
+				DueDateField:     fields.DueDate,
+				PriorityField:    fields.Priority,
+				TagsField:        fields.Tags,
+				PriorityMap:      fields.PriorityMap,
+			},
+		)
+		jiraHandler = handler.NewHumaJiraHandler(jiraService)
+		if cfg.Jira.PollInterval > 0 {
+			shutdownManager.Go("jira-sync", func(ctx context.Context) {
+				jiraService.WatchSync(ctx, cfg.Jira.PollInterval)
+			})
+		}
+	}
+
+	// Notionのデータベースへのエクスポート（Todo → ページの一方向）
+	var notionHandler *handler.HumaNotionHandler
+	if cfg.Notion.Enabled {
+		props := cfg.Notion.Properties
+		notionService := service.NewNotionService(
+			notion.NewClient(cfg.Notion.APIURL, cfg.Notion.Token),
+			service.NotionOptions{
+				DatabaseID:          cfg.Notion.DatabaseID,
+				TitleProperty:       props.Title,
+				DescriptionProperty: props.Description,
+				CompletedProperty:   props.Completed,
+				PriorityProperty:    props.Priority,
+				DueDateProperty:     props.DueDate,
+				TagsProperty:        props.Tags,
+				IDProperty:          props.ID,
+				DoneStatus:          cfg.Notion.DoneStatus,
+				TodoStatus:          cfg.Notion.TodoStatus,
+			},
+		)
+		notionHandler = handler.NewHumaNotionHandler(notionService)
+		if cfg.Notion.SyncInterval > 0 {
+			shutdownManager.Go("notion-sync", func(ctx context.Context) {
+				notionService.WatchSync(ctx, cfg.Notion.SyncInterval)
+			})
+		}
+	}
+
+	// 依存サービスのヘルスチェック（DB・ストレージ・ジョブキュー以外は設定で有効な場合のみ登録）
+	healthAggregator := health.NewAggregator(5 * time.Second)
+	healthAggregator.Register(&health.DBChecker{})
+	healthAggregator.Register(health.NewStorageChecker(objectStorage))
+	// 実行予定からロックの期限（ジョブの実行時間の上限）以上待っているジョブ・直近1時間のデッドレターを異常とする
+	healthAggregator.Register(health.NewQueueChecker(cfg.Queue.LockLease, time.Hour))
+	addrs := redisAddrs(cfg)
+	for _, addr := range addrs {
+		name := "redis"
+		if len(addrs) > 1 {
+			name = "redis（" + addr + "）"
+		}
+		healthAggregator.Register(health.NewTCPChecker(name, addr, false))
+	}
+	if cfg.LLM.Enabled {
+		if baseURL, err := llm.BaseURL(cfg.LLM.Provider, cfg.LLM.BaseURL); err == nil {
+			healthAggregator.Register(health.NewHTTPChecker("llm", baseURL, false))
+		}
+	}
+	probe := health.NewProbe(healthAggregator)
+	probe.MarkMigrated()
+	healthDetailHandler := handler.NewHumaHealthHandler(healthAggregator, probe)
+	diagnosticsHandler := handler.NewHumaDiagnosticsHandler(diagnostics.New(healthAggregator, shutdownManager))
+	jobsHandler := handler.NewHumaJobsHandler(shutdownManager.Workers, jobScheduler)
+	reminderHandler := handler.NewHumaReminderHandler(reminderService)
+	queueHandler := handler.NewHumaQueueHandler(service.NewQueueService())
+
+	// メトリクスの登録
+	if err := metrics.RegisterDB(db.GetDB()); err != nil {
+		fatal("メトリクス登録エラー", err)
+	}
+	if err := metrics.RegisterDBPoolSettings(cfg.Database.MaxIdleConns, cfg.Database.ConnMaxLifetime, cfg.Database.ConnMaxIdleTime); err != nil {
+		fatal("メトリクス登録エラー", err)
+	}
 
 	// Chi routerの設定
 	router := chi.NewRouter()
 
 	// ミドルウェアの追加
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
-
-	// CORSの設定
-	router.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
+	router.Use(requestid.Middleware)
+	// 末尾・重複したスラッシュを取り除いたAPIのパスでルーティングする（他のミドルウェアも正規化したパスを参照する）
+	router.Use(pathnorm.Middleware)
+	// Accept-Languageによるレスポンスの言語（日本語/英語）
+	router.Use(i18n.Middleware)
+	router.Use(tracing.Middleware)
+	router.Use(metrics.Middleware)
+	router.Use(logging.Middleware)
+	router.Use(compress.Middleware)
+	router.Use(security.HeadersMiddleware)
+	router.Use(cors.Middleware(tracing.TraceIDHeader, requestid.Header, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "ETag", csrf.DefaultHeaderName))
+	router.Use(recovery.NewFromConfig(cfg.PanicAlert).Middleware)
+
+	// CIDR指定のIP許可・拒否リスト
+	router.Use(ipfilter.Middleware)
+	// 管理APIのトークン認証
+	router.Use(adminauth.Middleware)
+	if cfg.Admin.Token == "" {
+		slog.Warn("ADMIN_TOKENが未設定のため、管理APIを公開しません", "prefix", adminauth.Prefix)
+	}
+	router.Use(errorreport.Middleware)
+	router.Use(maintenance.Middleware)
+
+	// Cookie認証時のCSRF保護（ダブルサブミットCookie）
+	router.Use(csrf.Middleware)
+
+	// Cookieセッション（暗号化Cookieまたは Redis に保存）
+	if cfg.Session.Enabled {
+		var sessionStore session.Store
+		if cfg.Session.Store == "redis" {
+			redisStore := session.NewRedisStore(cfg.Session.RedisAddr)
+			shutdownManager.Register(shutdown.PhaseResources, "session-redis", func(ctx context.Context) error {
+				return redisStore.Close()
+			})
+			sessionStore = redisStore
+		} else {
+			cookieStore, err := session.NewCookieStore(cfg.Session.Secret)
+			if err != nil {
+				fatal("セッションストアの作成に失敗しました", err)
 			}
+			sessionStore = cookieStore
+		}
+		router.Use(session.Middleware(sessionStore))
+	}
 
-			next.ServeHTTP(w, r)
+	// IP単位のレートリミット（backend=redisの場合は複数インスタンスで共有）
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Backend == "redis" {
+		redisStore := ratelimit.NewRedisStore(cfg.RateLimit.RedisAddr)
+		shutdownManager.Register(shutdown.PhaseResources, "ratelimit-redis", func(ctx context.Context) error {
+			return redisStore.Close()
 		})
+		rateLimitStore = redisStore
+	}
+	router.Use(ratelimit.Middleware(rateLimitStore))
+
+	// リクエストボディサイズの上限
+	router.Use(bodylimit.Middleware)
+
+	// 署名付きリクエストのリプレイ防止（タイムスタンプ＋nonce）
+	var nonceStore replay.NonceStore = replay.NewMemoryStore()
+	if cfg.Replay.NonceStore == "redis" {
+		redisStore := replay.NewRedisStore(cfg.Replay.RedisAddr)
+		shutdownManager.Register(shutdown.PhaseResources, "replay-redis", func(ctx context.Context) error {
+			return redisStore.Close()
+		})
+		nonceStore = redisStore
+	}
+	router.Use(replay.Middleware(nonceStore))
+
+	// ルートごとのリクエストタイムアウト（超過時は504）
+	router.Use(timeout.Middleware)
+
+	// 一覧・統計のHTTPキャッシュ制御（ETag・Cache-Control）。Todoの更新イベントで検証済みのETagを無効化する
+	router.Use(httpcache.Middleware)
+	events.AddListener(func(ctx context.Context, event *events.CloudEvent) {
+		httpcache.Invalidate()
 	})
 
 	// HumaのAPIインスタンスを作成
-	config := huma.DefaultConfig("Todo API", "1.0.0")
-	config.Info.Description = "Go製のTodo管理API"
-	config.Info.Contact = &huma.Contact{Name: "API Support"}
-
-	api := humachi.New(router, config)
-
-	// ヘルスチェックエンドポイント
-	huma.Register(api, huma.Operation{
-		OperationID: "get-health",
-		Method:      http.MethodGet,
-		Path:        "/health",
-		Summary:     "アプリケーションヘルスチェック",
-		Tags:        []string{"health"},
-	}, healthHandler)
-
-	huma.Register(api, huma.Operation{
-		OperationID: "get-home",
-		Method:      http.MethodGet,
-		Path:        "/",
-		Summary:     "ホームページ",
-		Tags:        []string{"health"},
-	}, homeHandler)
-
-	huma.Register(api, huma.Operation{
-		OperationID: "get-db-health",
-		Method:      http.MethodGet,
-		Path:        "/health/db",
-		Summary:     "データベースヘルスチェック",
-		Tags:        []string{"health"},
-	}, dbHealthHandler)
-
-	// Todo API エンドポイント
-	huma.Register(api, huma.Operation{
-		OperationID: "list-todos",
-		Method:      http.MethodGet,
-		Path:        "/api/v1/todos",
-		Summary:     "全てのTodoを取得",
-		Description: "優先度や完了状況でフィルタリング可能",
-		Tags:        []string{"todos"},
-	}, todoHandler.GetAllTodos)
-
-	huma.Register(api, huma.Operation{
-		OperationID:   "create-todo",
-		Method:        http.MethodPost,
-		Path:          "/api/v1/todos",
-		Summary:       "新しいTodoを作成",
-		Tags:          []string{"todos"},
-		DefaultStatus: 201,
-	}, todoHandler.CreateTodo)
-
-	huma.Register(api, huma.Operation{
-		OperationID: "get-todo",
-		Method:      http.MethodGet,
-		Path:        "/api/v1/todos/{id}",
-		Summary:     "特定のTodoを取得",
-		Tags:        []string{"todos"},
-	}, todoHandler.GetTodoByID)
-
-	huma.Register(api, huma.Operation{
-		OperationID: "update-todo",
-		Method:      http.MethodPut,
-		Path:        "/api/v1/todos/{id}",
-		Summary:     "Todoを更新",
-		Tags:        []string{"todos"},
-	}, todoHandler.UpdateTodo)
-
-	huma.Register(api, huma.Operation{
-		OperationID: "delete-todo",
-		Method:      http.MethodDelete,
-		Path:        "/api/v1/todos/{id}",
-		Summary:     "Todoを削除",
-		Tags:        []string{"todos"},
-	}, todoHandler.DeleteTodo)
-
-	// サーバーの起動
-	port := ":8080"
-	fmt.Printf("Todo API サーバーがポート%sで起動しています...\n", port)
+	api := humachi.New(router, newAPIConfig())
+	api.UseMiddleware(handler.DeferredStatusMiddleware)
+
+	// 存在しないパス・メソッドもAPIのエラーと同じproblem+jsonで返す（405はAllowヘッダー付き）
+	router.NotFound(handler.NotFound)
+	router.MethodNotAllowed(handler.MethodNotAllowed)
+
+	// Prometheusメトリクスエンドポイント
+	router.Handle("/metrics", metrics.Handler())
+
+	// CalDAVサーバー（リマインダーアプリ等からVTODOとして読み書き）
+	if cfg.CalDAV.Enabled {
+		for _, method := range caldav.Methods {
+			chi.RegisterMethod(method)
+		}
+		caldavHandler := caldav.NewHandler(service.NewCalDAVService(todoService), cfg.CalDAV.Username, cfg.CalDAV.Password)
+		router.Handle(caldav.Prefix+"*", caldavHandler)
+		router.Handle(strings.TrimSuffix(caldav.Prefix, "/"), caldavHandler)
+		router.HandleFunc(caldav.WellKnownPath, caldav.WellKnown)
+	}
+
+	// モバイルのショートカット・ブックマークレットからのクイック追加
+	if cfg.QuickAdd.Enabled {
+		router.Handle(quickadd.Path, quickadd.NewHandler(todoService, cfg.QuickAdd.Tokens))
+	}
+
+	// Zapier・IFTTTのポーリング型トリガーとTodo作成アクション
+	triggerService := service.NewTriggerService()
+	if cfg.IFTTT.Enabled {
+		router.Handle(ifttt.Prefix+"*", ifttt.NewHandler(triggerService, todoService, cfg.IFTTT.ServiceKey))
+	}
+
+	// pprofプロファイリングエンドポイント（管理者専用ポートまたは認証付きで公開）
+	var pprofServer *http.Server
+	pprofConfig := profiling.LoadConfig()
+	if pprofConfig.Enabled {
+		switch {
+		case pprofConfig.Addr != "":
+			pprofServer = profiling.NewServer(pprofConfig.Addr)
+			go func() {
+				if err := pprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					slog.Error("pprofサーバー起動エラー", "error", err)
+				}
+			}()
+			slog.Info("pprofを管理者専用ポートで公開しています", "addr", pprofConfig.Addr)
+		case pprofConfig.Token != "":
+			profiling.Mount(router, pprofConfig.Token)
+			slog.Info("pprofを認証付きで公開しています", "path", "/debug/pprof/")
+		default:
+			slog.Warn("PPROF_ADDRまたはPPROF_TOKENが未設定のため、pprofを公開しません")
+		}
+	}
+
+	// エンドポイントの登録（無効な機能のハンドラーはnilのため登録されない）
+	handlers := &apiHandlers{
+		healthDetail:    healthDetailHandler,
+		todo:            todoHandler,
+		stale:           staleHandler,
+		profile:         profileHandler,
+		bulk:            bulkHandler,
+		imports:         importHandler,
+		admin:           adminHandler,
+		feature:         featureHandler,
+		maintenance:     maintenanceHandler,
+		reload:          reloadHandler,
+		diagnostics:     diagnosticsHandler,
+		jobs:            jobsHandler,
+		queue:           queueHandler,
+		webhook:         webhookHandler,
+		webhookEndpoint: webhookEndpointHandler,
+		escalation:      escalationHandler,
+		reminder:        reminderHandler,
+		snapshot:        snapshotHandler,
+		digest:          digestHandler,
+		calendar:        calendarHandler,
+		mstodo:          mstodoHandler,
+		github:          githubHandler,
+		jira:            jiraHandler,
+		push:            pushHandler,
+		notion:          notionHandler,
+		githubWebhook:   cfg.GitHub.WebhookSecret != "",
+	}
+	if cfg.Zapier.Enabled {
+		handlers.zapier = handler.NewHumaZapierHandler(triggerService, todoService, cfg.Zapier.APIKey)
+	}
+	registerRoutes(api, handlers)
+
+	// スキーマ外のフィールドの扱い（厳格モードでは400で拒否、それ以外は無視）
+	handler.ConfigureUnknownFields(api, cfg.Validation.StrictUnknownFields)
+
+	// サーバーの起動（待ち受けアドレス・ポートとタイムアウトは server の設定）
+	server := httpserver.NewServer(cfg.Server, router)
+	listenAddr := server.Addr
+	if cfg.Server.UnixSocket != "" {
+		listenAddr = "unix:" + cfg.Server.UnixSocket
+	}
+	slog.Info("Todo API サーバーを起動しています", "addr", listenAddr, "tls", cfg.Server.TLSEnabled(), "version", version.Version)
 	fmt.Println("利用可能なエンドポイント:")
 	fmt.Println("  GET    /                    - ホームページ")
 	fmt.Println("  GET    /health              - ヘルスチェック")
 	fmt.Println("  GET    /health/db           - DBヘルスチェック")
+	fmt.Println("  GET    /health/detail       - 依存サービスの詳細ヘルスチェック")
+	fmt.Println("  GET    /version             - バージョン情報")
+	fmt.Println("  GET    /livez               - livenessプローブ")
+	fmt.Println("  GET    /readyz              - readinessプローブ")
 	fmt.Println("  GET    /api/v1/todos        - 全Todoを取得")
 	fmt.Println("  POST   /api/v1/todos        - 新しいTodoを作成")
+	fmt.Println("  GET    /api/v1/todos/stale  - 長期間更新されていないTodoを取得")
+	fmt.Println("  GET    /api/v1/todos/export - TodoをJSON Lines・CSVでストリーミング")
 	fmt.Println("  GET    /api/v1/todos/{id}   - 特定のTodoを取得")
 	fmt.Println("  PUT    /api/v1/todos/{id}   - Todoを更新")
 	fmt.Println("  DELETE /api/v1/todos/{id}   - Todoを削除")
+	fmt.Println("  POST   /api/v1/todos/bulk   - Todoを一括で更新・削除")
+	fmt.Println("  POST   /api/v1/todos/bulk/create - Todoを一括で作成")
+	fmt.Println("  GET    /api/v1/todos/bulk/{id} - 一括操作の進捗を取得")
+	fmt.Println("  GET    /api/v1/csrf-token   - CSRFトークンを取得")
+	fmt.Println("  GET    /api/v1/admin/db/stats - DB統計を取得")
+	fmt.Println("  GET    /api/v1/admin/migrations - マイグレーション状況を取得")
+	fmt.Println("  GET    /api/v1/admin/features - フィーチャーフラグ一覧")
+	fmt.Println("  PUT    /api/v1/admin/features/{name} - フィーチャーフラグを切り替え")
+	fmt.Println("  GET    /api/v1/admin/maintenance - メンテナンスモードの状態")
+	fmt.Println("  PUT    /api/v1/admin/maintenance - メンテナンスモードを切り替え")
+	fmt.Println("  GET    /api/v1/admin/diagnostics - セルフ診断")
+	fmt.Println("  GET    /api/v1/admin/jobs - ジョブ/ワーカーの稼働状況")
+	fmt.Println("  POST   /api/v1/admin/jobs/{name}/run - ジョブの即時実行")
+	fmt.Println("  GET    /api/v1/admin/jobs/runs - ジョブの実行履歴")
+	fmt.Println("  GET    /api/v1/admin/jobs/runs/{id} - ジョブの実行履歴の取得")
+	fmt.Println("  POST   /api/v1/admin/reload - 設定を再読み込み")
+	fmt.Println("  POST   /api/v1/admin/webhooks/secret/rotate - Webhookの署名シークレットをローテーション")
+	fmt.Println("  GET    /api/v1/admin/webhooks/endpoints - Webhookの送信先の一覧")
+	fmt.Println("  POST   /api/v1/admin/webhooks/endpoints - Webhookの送信先を登録")
+	fmt.Println("  GET    /api/v1/admin/webhooks/endpoints/{id} - Webhookの送信先を取得")
+	fmt.Println("  PUT    /api/v1/admin/webhooks/endpoints/{id} - Webhookの送信先を更新・再開")
+	fmt.Println("  DELETE /api/v1/admin/webhooks/endpoints/{id} - Webhookの送信先を削除")
+	fmt.Println("  GET    /api/v1/admin/webhooks/deliveries - Webhookの配信の履歴")
+	fmt.Println("  GET    /api/v1/admin/webhooks/deliveries/{id} - Webhookの配信を取得")
+	fmt.Println("  POST   /api/v1/admin/webhooks/deliveries/{id}/redeliver - Webhookを再配信")
 	fmt.Println("  GET    /docs                - OpenAPI ドキュメント")
+	fmt.Println("  GET    /metrics             - Prometheusメトリクス")
+
+	// HTTPS（証明書ファイルまたはLet's Encrypt）とHTTP→HTTPSリダイレクトの設定
+	redirectServer, err := httpserver.ConfigureTLS(server, cfg.Server)
+	if err != nil {
+		fatal("TLSの設定に失敗しました", err)
+	}
+	if redirectServer != nil {
+		go func() {
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("HTTPリダイレクトサーバー起動エラー", "error", err)
+			}
+		}()
+	}
 
-	// HTTPサーバーの設定
-	server := &http.Server{
-		Addr:    port,
-		Handler: router,
+	// 停止処理の登録
+	shutdownManager.Register(shutdown.PhaseServers, "http", server.Shutdown)
+	if redirectServer != nil {
+		shutdownManager.Register(shutdown.PhaseServers, "http-redirect", redirectServer.Shutdown)
+	}
+	if pprofServer != nil {
+		shutdownManager.Register(shutdown.PhaseServers, "pprof", pprofServer.Shutdown)
 	}
+	shutdownManager.Register(shutdown.PhaseFlush, "tracing", tracing.Shutdown)
+	shutdownManager.Register(shutdown.PhaseFlush, "sentry", errorreport.Shutdown)
+	shutdownManager.Register(shutdown.PhaseResources, "database", func(ctx context.Context) error {
+		return db.Close()
+	})
 
 	// グレースフルシャットダウンの設定
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("サーバー起動エラー: %v", err)
+		if err := httpserver.ListenAndServe(server, cfg.Server); err != nil && err != http.ErrServerClosed {
+			fatal("サーバー起動エラー", err)
 		}
 	}()
 
@@ -231,20 +1167,23 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("サーバーをシャットダウンしています...")
+	slog.Info("サーバーをシャットダウンしています...")
 
-	// グレースフルシャットダウン
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("サーバーシャットダウンエラー: %v", err)
+	// readinessを失敗させ、ロードバランサーから外れるまで待機してから停止する
+	probe.MarkShuttingDown()
+	shutdownConfig := config.Current().Shutdown
+	if delay := shutdownConfig.DrainDelay; delay > 0 {
+		slog.Info("トラフィックの切り離しを待機しています", "delay", delay)
+		time.Sleep(delay)
 	}
 
-	// データベース接続を閉じる
-	if err := db.Close(); err != nil {
-		log.Printf("データベース接続の終了エラー: %v", err)
+	// グレースフルシャットダウン（サーバー → ワーカー → テレメトリ送信 → DBの順に停止）
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownConfig.Timeout)
+	defer cancel()
+
+	if err := shutdownManager.Shutdown(ctx); err != nil {
+		slog.Error("シャットダウンエラー", "error", err)
 	}
 
-	log.Println("サーバーがシャットダウンしました")
+	slog.Info("サーバーがシャットダウンしました")
 }
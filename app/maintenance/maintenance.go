@@ -0,0 +1,111 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"myapp/config"
+	"myapp/feature"
+	"myapp/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// defaultMessage メンテナンス中のレスポンスメッセージ
+const defaultMessage = "メンテナンス中のため一時的に利用できません"
+
+// Settings メンテナンスモードの動作設定
+// 有効/無効はフィーチャーフラグ（maintenance_mode）で切り替える
+type Settings struct {
+	AllowReads        bool   `json:"allow_reads" doc:"メンテナンス中も読み取り（GET/HEAD）を許可するか"`
+	RetryAfterSeconds int    `json:"retry_after_seconds" minimum:"0" doc:"Retry-Afterヘッダーに設定する秒数"`
+	Message           string `json:"message" required:"false" doc:"503レスポンスのメッセージ（省略時はデフォルトのメッセージ）"`
+}
+
+var (
+	// override 管理APIで変更した設定（nilの間は設定ファイル・環境変数の値を使う）
+	override   *Settings
+	settingsMu sync.RWMutex
+)
+
+// fromConfig 現在の設定（maintenance）から動作設定を作成
+func fromConfig() Settings {
+	cfg := config.Current().Maintenance
+	s := Settings{
+		AllowReads:        cfg.AllowReads,
+		RetryAfterSeconds: int(cfg.RetryAfter.Seconds()),
+		Message:           cfg.Message,
+	}
+	if s.Message == "" {
+		s.Message = defaultMessage
+	}
+	return s
+}
+
+// Enabled メンテナンスモードが有効か
+func Enabled() bool {
+	return feature.Enabled(feature.FlagMaintenance)
+}
+
+// Get 現在の設定を取得（管理APIで変更していない場合は設定の再読み込みも反映される）
+func Get() Settings {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	if override != nil {
+		return *override
+	}
+	return fromConfig()
+}
+
+// Set 設定を変更（メッセージが空の場合はデフォルトのメッセージを使用。再起動するまで設定ファイルの値より優先する）
+func Set(s Settings) Settings {
+	if s.Message == "" {
+		s.Message = defaultMessage
+	}
+
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	override = &s
+	return s
+}
+
+// Middleware メンテナンス中にAPIへのリクエストを503で拒否するミドルウェア
+// 管理API・ヘルスチェック等の /api/ ・ /caldav/ 以外のパスは対象外
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() || !affected(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		s := Get()
+		if s.AllowReads && readMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(s.RetryAfterSeconds))
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(huma.Error503ServiceUnavailable(i18n.T(r.Context(), s.Message)))
+	})
+}
+
+// affected メンテナンスモードの対象となるリクエストか（CalDAVはTodoを書き換えるため対象に含める）
+func affected(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/caldav/") {
+		return true
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/") && !strings.HasPrefix(r.URL.Path, "/api/v1/admin/")
+}
+
+// readMethod 読み取りのみのメソッドか（CalDAVのPROPFIND・REPORTを含む）
+func readMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND", "REPORT":
+		return true
+	}
+	return false
+}
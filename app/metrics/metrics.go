@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// メトリクス名の名前空間
+const namespace = "todo_api"
+
+var (
+	// httpRequestsTotal リクエスト数（メソッド・ルート・ステータスコード別）
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "処理したHTTPリクエストの総数",
+	}, []string{"method", "route", "status"})
+
+	// httpRequestDuration リクエスト処理時間のヒストグラム
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTPリクエストの処理時間（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// httpRequestsInFlight 処理中のリクエスト数
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "処理中のHTTPリクエスト数",
+	})
+)
+
+// registry アプリケーションのメトリクスレジストリ
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+	)
+}
+
+// RegisterDB DBプール統計とTodo件数のコレクターを登録
+func RegisterDB(database *gorm.DB) error {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return err
+	}
+
+	return registerAll(
+		collectors.NewDBStatsCollector(sqlDB, "postgres"),
+		newTodoCollector(database),
+	)
+}
+
+// RegisterDBPoolSettings 接続プールの設定値を公開する
+// 使用中・待機の統計（go_sql_*）と合わせて、上限に対する使用率や接続の入れ替わりを監視できるようにする
+func RegisterDBPoolSettings(maxIdleConns int, connMaxLifetime, connMaxIdleTime time.Duration) error {
+	settings := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"db_pool_max_idle_connections", "アイドル状態で保持する接続数の上限", float64(maxIdleConns)},
+		{"db_pool_conn_max_lifetime_seconds", "接続を使い続ける最長時間（秒、0は無制限）", connMaxLifetime.Seconds()},
+		{"db_pool_conn_max_idle_time_seconds", "アイドル状態の接続を閉じるまでの時間（秒、0は無制限）", connMaxIdleTime.Seconds()},
+	}
+
+	gauges := make([]prometheus.Collector, len(settings))
+	for i, setting := range settings {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      setting.name,
+			Help:      setting.help,
+		})
+		gauge.Set(setting.value)
+		gauges[i] = gauge
+	}
+	return registerAll(gauges...)
+}
+
+// registerAll 複数のコレクターを登録
+func registerAll(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler /metrics用のハンドラーを返す
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Middleware リクエスト数・レイテンシ・ステータスコードを計測するミドルウェア
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		// ラベルの爆発を防ぐため実パスではなくルートパターンを使用
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		httpRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"log/slog"
+	"myapp/db/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// todoCollector スクレイプ時にTodo件数を集計するコレクター
+type todoCollector struct {
+	db    *gorm.DB
+	total *prometheus.Desc
+}
+
+// newTodoCollector 新しいTodo件数コレクターを作成
+func newTodoCollector(database *gorm.DB) *todoCollector {
+	return &todoCollector{
+		db: database,
+		total: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "todos"),
+			"Todoの件数（完了状態・優先度別）",
+			[]string{"completed", "priority"},
+			nil,
+		),
+	}
+}
+
+// Describe メトリクスの定義を送信
+func (c *todoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+}
+
+// Collect 完了状態・優先度ごとの件数を集計して送信
+func (c *todoCollector) Collect(ch chan<- prometheus.Metric) {
+	var rows []struct {
+		Completed bool
+		Priority  model.Priority
+		Count     int64
+	}
+
+	result := c.db.Model(&model.Todo{}).
+		Select("completed, priority, COUNT(*) AS count").
+		Group("completed, priority").
+		Scan(&rows)
+	if result.Error != nil {
+		slog.Error("Todo件数メトリクスの集計に失敗しました", "error", result.Error)
+		return
+	}
+
+	for _, row := range rows {
+		completed := "false"
+		if row.Completed {
+			completed = "true"
+		}
+		ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(row.Count), completed, row.Priority.String())
+	}
+}
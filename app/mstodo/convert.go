@@ -0,0 +1,85 @@
+package mstodo
+
+import (
+	"html"
+	"myapp/db/model"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// textPolicy HTMLの本文からタグを除く
+var textPolicy = bluemonday.StrictPolicy()
+
+// ToImportTasks リストのタスクを取り込むタスクに変換（projectは取り込み先のプロジェクト名、空の場合はプロジェクトなし）
+// チェックリストの項目→サブタスク、importance→優先度に変換し、完了済みのタスクはincludeCompletedの場合のみ含める
+func ToImportTasks(tasks []Task, project string, includeCompleted bool) []model.ImportTask {
+	var result []model.ImportTask
+	for _, t := range tasks {
+		completed := t.Status == "completed"
+		if completed && !includeCompleted {
+			continue
+		}
+
+		task := model.ImportTask{
+			Ref:       t.ID,
+			Title:     t.Title,
+			Project:   project,
+			Priority:  priority(t.Importance),
+			Completed: completed,
+		}
+		if t.Body != nil {
+			task.Description = body(t.Body.Content, t.Body.ContentType)
+		}
+		if t.DueDateTime != nil && t.DueDateTime.DateTime != "" {
+			if due, ok := parseDateTime(*t.DueDateTime); ok {
+				task.Due = &due
+			} else {
+				task.UnparsedDue = strings.TrimSpace(t.DueDateTime.DateTime + " " + t.DueDateTime.TimeZone)
+			}
+		}
+		for _, item := range t.ChecklistItems {
+			task.Subtasks = append(task.Subtasks, model.ImportSubtask{Title: item.DisplayName, Completed: item.IsChecked})
+		}
+		result = append(result, task)
+	}
+	return result
+}
+
+// priority importance（low / normal / high）を変換
+func priority(importance string) model.Priority {
+	switch importance {
+	case "high":
+		return model.PriorityHigh
+	case "low":
+		return model.PriorityLow
+	default:
+		return model.PriorityMedium
+	}
+}
+
+// body 本文をプレーンテキストにする（HTMLの場合はタグを除く）
+func body(content, contentType string) string {
+	if strings.EqualFold(contentType, "html") {
+		content = html.UnescapeString(textPolicy.Sanitize(content))
+	}
+	return strings.TrimSpace(content)
+}
+
+// parseDateTime 日時を解釈（タイムゾーンがIANAの名前として解釈できない場合は失敗とする）
+func parseDateTime(dt DateTimeTimeZone) (time.Time, bool) {
+	loc := time.UTC
+	if dt.TimeZone != "" && dt.TimeZone != "UTC" {
+		l, err := time.LoadLocation(dt.TimeZone)
+		if err != nil {
+			return time.Time{}, false
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.9999999", dt.DateTime, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,140 @@
+package mstodo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// graphBaseURL Microsoft Graph API v1.0のベースURL
+const graphBaseURL = "https://graph.microsoft.com/v1.0"
+
+// TaskList タスクリスト（取り込みに使う項目のみ）
+type TaskList struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	// WellknownListName 既定のリスト（defaultList）・フラグ付きメール（flaggedEmails）等の区別（通常のリストはnone）
+	WellknownListName string `json:"wellknownListName"`
+}
+
+// Task タスク（取り込みに使う項目のみ）
+type Task struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	Importance string `json:"importance"`
+	Body       *struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	DueDateTime    *DateTimeTimeZone `json:"dueDateTime"`
+	Recurrence     json.RawMessage   `json:"recurrence"`
+	ChecklistItems []struct {
+		DisplayName string `json:"displayName"`
+		IsChecked   bool   `json:"isChecked"`
+	} `json:"checklistItems"`
+}
+
+// DateTimeTimeZone タイムゾーン付きの日時（dateTimeはオフセットなしの表記）
+type DateTimeTimeZone struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+// Client Microsoft Graph APIのクライアント
+type Client struct {
+	accessToken func(ctx context.Context) (string, error)
+	client      *http.Client
+}
+
+// NewClient 新しいクライアントを作成（accessTokenは呼び出しごとに有効なアクセストークンを返す）
+func NewClient(accessToken func(ctx context.Context) (string, error)) *Client {
+	return &Client{
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Lists タスクリストの一覧を取得
+func (c *Client) Lists(ctx context.Context) ([]TaskList, error) {
+	var lists []TaskList
+	err := c.pages(ctx, graphBaseURL+"/me/todo/lists", func(page json.RawMessage) error {
+		var values []TaskList
+		if err := json.Unmarshal(page, &values); err != nil {
+			return err
+		}
+		lists = append(lists, values...)
+		return nil
+	})
+	return lists, err
+}
+
+// Tasks リストのタスクをチェックリストの項目とともに取得
+func (c *Client) Tasks(ctx context.Context, listID string) ([]Task, error) {
+	q := url.Values{"$expand": {"checklistItems"}, "$top": {"100"}}
+	var tasks []Task
+	err := c.pages(ctx, graphBaseURL+"/me/todo/lists/"+url.PathEscape(listID)+"/tasks?"+q.Encode(), func(page json.RawMessage) error {
+		var values []Task
+		if err := json.Unmarshal(page, &values); err != nil {
+			return err
+		}
+		tasks = append(tasks, values...)
+		return nil
+	})
+	return tasks, err
+}
+
+// pages @odata.nextLink を辿って各ページのvalueをfnに渡す
+func (c *Client) pages(ctx context.Context, u string, fn func(page json.RawMessage) error) error {
+	for u != "" {
+		var page struct {
+			Value    json.RawMessage `json:"value"`
+			NextLink string          `json:"@odata.nextLink"`
+		}
+		if err := c.get(ctx, u, &page); err != nil {
+			return err
+		}
+		if err := fn(page.Value); err != nil {
+			return fmt.Errorf("Microsoft Graph APIのレスポンスを解析できません: %w", err)
+		}
+		if page.NextLink != "" && !strings.HasPrefix(page.NextLink, graphBaseURL+"/") {
+			return fmt.Errorf("想定外の次ページのURLです: %q", page.NextLink)
+		}
+		u = page.NextLink
+	}
+	return nil
+}
+
+// get APIを呼び出し、レスポンスをoutにデコードする
+func (c *Client) get(ctx context.Context, u string, out any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	// 期限の日時をUTCで受け取る
+	req.Header.Set("Prefer", `outlook.timezone="UTC"`)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Microsoft Graph APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
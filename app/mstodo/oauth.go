@@ -0,0 +1,25 @@
+// Package mstodo Microsoft Graph API経由でMicrosoft To Do（Outlookのタスク）を取り込むためのクライアント
+package mstodo
+
+import (
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// Scopes タスクの読み取りとリフレッシュトークンの発行に必要なスコープ
+var Scopes = []string{"offline_access", "Tasks.Read"}
+
+// AuthCodeOptions 同意画面のURLに付けるパラメーター（認可コードをクエリで受け取る）
+var AuthCodeOptions = []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("response_mode", "query")}
+
+// NewOAuthConfig Microsoft ID プラットフォーム（v2.0）の認可コードフローの設定を作成
+// tenantは common（個人・職場の両方のアカウント）、consumers（個人のみ）またはテナントID
+func NewOAuthConfig(clientID, clientSecret, redirectURL, tenant string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Endpoint:     microsoft.AzureADEndpoint(tenant),
+		Scopes:       Scopes,
+	}
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Discord Embedの色（イベントごと）
+const (
+	discordColorCreated   = 0x5865F2
+	discordColorCompleted = 0x57F287
+	discordColorDueSoon   = 0xFEE75C
+	discordColorOverdue   = 0xED4245
+)
+
+// discordDescriptionLimit Embedの説明の最大文字数
+const discordDescriptionLimit = 4096
+
+// DiscordChannel Discord Webhookへ通知するチャンネル
+type DiscordChannel struct {
+	WebhookURL string
+	Username   string
+	client     *http.Client
+}
+
+// NewDiscordChannel 新しいDiscordチャンネルを作成（usernameが空の場合はWebhookの既定名で投稿）
+func NewDiscordChannel(webhookURL, username string) *DiscordChannel {
+	return &DiscordChannel{
+		WebhookURL: webhookURL,
+		Username:   username,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name チャンネル名
+func (c *DiscordChannel) Name() string {
+	return "discord"
+}
+
+// Send イベントをEmbed付きのメッセージとしてDiscordへ投稿
+func (c *DiscordChannel) Send(ctx context.Context, event Event) error {
+	embed := map[string]any{
+		"title":       event.Summary(),
+		"description": truncate(event.Todo.Description, discordDescriptionLimit),
+		"color":       discordColor(event.Type),
+		"timestamp":   event.OccurredAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	payload := map[string]any{
+		"embeds": []any{embed},
+		// 本文中の @everyone 等でメンションが飛ばないようにする
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	if c.Username != "" {
+		payload["username"] = c.Username
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Discordがステータス %d を返しました", resp.StatusCode)
+	}
+	return nil
+}
+
+// discordColor イベントごとのEmbedの色
+func discordColor(t EventType) int {
+	switch t {
+	case EventCompleted:
+		return discordColorCompleted
+	case EventDueSoon, EventStale:
+		return discordColorDueSoon
+	case EventOverdue, EventEscalated:
+		return discordColorOverdue
+	default:
+		return discordColorCreated
+	}
+}
+
+// truncate 最大文字数を超える場合は末尾を省略する
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,319 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"myapp/db/model"
+	"myapp/queue"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// emailTemplates メール本文のHTMLテンプレート
+var emailTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"section": func(title string, todos []model.Todo) map[string]any {
+		return map[string]any{"Title": title, "Todos": todos}
+	},
+}).ParseFS(templateFS, "templates/*.html"))
+
+// retryBaseDelay 送信失敗時の再試行間隔の初期値（試行ごとに2倍）
+const retryBaseDelay = 2 * time.Second
+
+// SMTPConfig SMTPサーバーの接続設定
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// ImplicitTLS 接続時からTLSを使う（465番ポート）。falseの場合はサーバーが対応していればSTARTTLSを使う
+	ImplicitTLS bool
+}
+
+// EmailJobKind メールの送信ジョブの種類
+const EmailJobKind = "email.send"
+
+// EmailChannel SMTPでメールを送信するチャンネル
+type EmailChannel struct {
+	smtp       SMTPConfig
+	from       string
+	to         []string
+	maxRetries int
+	// jobs 送信に使うジョブキュー（nilの場合は送信時に再試行しながら同期的に送信する）
+	jobs queue.Enqueuer
+}
+
+// emailJob メールの送信ジョブの内容
+type emailJob struct {
+	// To 宛先（空の場合はチャンネルの宛先）
+	To      []string `json:"to,omitempty"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text"`
+	HTML    string   `json:"html"`
+}
+
+// NewEmailChannel 新しいメールチャンネルを作成（送信に失敗した場合は最大maxRetries回再試行する）
+func NewEmailChannel(cfg SMTPConfig, from string, to []string, maxRetries int) *EmailChannel {
+	return &EmailChannel{smtp: cfg, from: from, to: to, maxRetries: maxRetries}
+}
+
+// UseQueue 送信をジョブキューに積むようにする（EmailJobKindにHandleJobを登録したキューを指定する）
+// 送信の失敗はキューで再試行するため、SMTPサーバーの障害が長引いてもメールを失わない
+func (c *EmailChannel) UseQueue(q queue.Enqueuer) {
+	c.jobs = q
+}
+
+// Name チャンネル名
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+// Timeout 再試行を含めた送信タイムアウト
+func (c *EmailChannel) Timeout() time.Duration {
+	return time.Duration(c.maxRetries+1)*sendTimeout + retryBaseDelay<<c.maxRetries
+}
+
+// Send イベントをメールで送信
+func (c *EmailChannel) Send(ctx context.Context, event Event) error {
+	return c.SendTo(ctx, nil, event)
+}
+
+// SendTo イベントを指定した宛先へメールで送信（toが空の場合はチャンネルの宛先）
+func (c *EmailChannel) SendTo(ctx context.Context, to []string, event Event) error {
+	var html bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&html, "event.html", event); err != nil {
+		return err
+	}
+	return c.deliver(ctx, to, "[Todo] "+event.Summary(), event.Summary(), html.String())
+}
+
+// SendDigest ダイジェストをメールで送信
+func (c *EmailChannel) SendDigest(ctx context.Context, digest Digest) error {
+	return c.SendDigestTo(ctx, nil, digest)
+}
+
+// SendDigestTo ダイジェストを指定した宛先へメールで送信（toが空の場合はチャンネルの宛先）
+func (c *EmailChannel) SendDigestTo(ctx context.Context, to []string, digest Digest) error {
+	var html bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&html, "digest.html", digest); err != nil {
+		return err
+	}
+	counts := fmt.Sprintf("期限切れ %d件・%s %d件", len(digest.Overdue), digest.DueTitle(), len(digest.DueToday))
+	if digest.Frequency != "" {
+		counts += fmt.Sprintf("・完了 %d件", len(digest.Completed))
+	}
+	// 件名の先頭の [Todo] と重複しないよう見出しの "Todo" を除く
+	subject := fmt.Sprintf("[Todo] %s %s（%s）", strings.TrimPrefix(digest.Title(), "Todo"), digest.GeneratedAt.Format("2006-01-02"), counts)
+	return c.deliver(ctx, to, subject, digestText(digest), html.String())
+}
+
+// deliver ジョブキューを使う場合はキューに積み、使わない場合は再試行しながら送信する（toが空の場合はチャンネルの宛先）
+func (c *EmailChannel) deliver(ctx context.Context, to []string, subject, text, html string) error {
+	if c.jobs == nil {
+		return c.sendWithRetry(ctx, to, subject, text, html)
+	}
+	_, err := c.jobs.Enqueue(ctx, EmailJobKind, emailJob{To: to, Subject: subject, Text: text, HTML: html})
+	return err
+}
+
+// HandleJob ジョブキューから送信ジョブを実行する（再試行はキューが行うため1回だけ送信する）
+func (c *EmailChannel) HandleJob(ctx context.Context, payload json.RawMessage) error {
+	var job emailJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	to := c.recipients(job.To)
+	msg, err := buildMessage(c.from, to, job.Subject, job.Text, job.HTML)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	return c.send(ctx, to, msg)
+}
+
+// recipients 宛先（toが空の場合はチャンネルの宛先）
+func (c *EmailChannel) recipients(to []string) []string {
+	if len(to) == 0 {
+		return c.to
+	}
+	return to
+}
+
+// sendWithRetry 送信に失敗した場合は間隔を倍にしながら再試行する
+func (c *EmailChannel) sendWithRetry(ctx context.Context, to []string, subject, text, html string) error {
+	to = c.recipients(to)
+	msg, err := buildMessage(c.from, to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err = c.send(ctx, to, msg)
+		if err == nil || attempt >= c.maxRetries {
+			return err
+		}
+		slog.WarnContext(ctx, "メールの送信に失敗しました。再試行します", "attempt", attempt+1, "error", err)
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return fmt.Errorf("%w（最後のエラー: %v）", ctx.Err(), err)
+		}
+	}
+}
+
+// send SMTPサーバーへ接続してメールを1通送信
+func (c *EmailChannel) send(ctx context.Context, to []string, msg []byte) error {
+	addr := net.JoinHostPort(c.smtp.Host, strconv.Itoa(c.smtp.Port))
+	dialer := &net.Dialer{Timeout: sendTimeout}
+
+	var conn net.Conn
+	var err error
+	if c.smtp.ImplicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: c.smtp.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !c.smtp.ImplicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: c.smtp.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if c.smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.smtp.Username, c.smtp.Password, c.smtp.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage テキストとHTMLの multipart/alternative メッセージを作成
+func buildMessage(from string, to []string, subject, text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", messageID(), domainOf(from))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// digestText ダイジェストのテキスト版
+func digestText(digest Digest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s（%s）\n", digest.Title(), digest.GeneratedAt.Format("2006-01-02"))
+	sections := []struct {
+		title string
+		todos []model.Todo
+	}{
+		{"期限切れ", digest.Overdue},
+		{digest.DueTitle(), digest.DueToday},
+		{"その他の未完了", digest.Pending},
+	}
+	if digest.Frequency != "" {
+		sections[2].title, sections[2].todos = "完了したTodo", digest.Completed
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n■ %s（%d件）\n", section.title, len(section.todos))
+		for _, todo := range section.todos {
+			fmt.Fprintf(&b, "- #%d [%s] %s\n", todo.ID, todo.Priority, todo.Title)
+		}
+	}
+	return b.String()
+}
+
+// messageID Message-IDのローカル部を生成
+func messageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// domainOf メールアドレスのドメイン部（取得できない場合はlocalhost）
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return strings.Trim(address[i+1:], "> ")
+	}
+	return "localhost"
+}
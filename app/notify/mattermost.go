@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Mattermostのアタッチメントの色（イベントごと）
+const (
+	mattermostColorCreated   = "#1C58D9"
+	mattermostColorCompleted = "#3DB887"
+	mattermostColorDueSoon   = "#FFBC1F"
+	mattermostColorOverdue   = "#D24B4E"
+)
+
+// mattermostTextLimit アタッチメントの本文の最大文字数（メッセージ全体の上限より小さくする）
+const mattermostTextLimit = 4000
+
+// MattermostChannel Mattermost の Incoming Webhook へ通知するチャンネル
+type MattermostChannel struct {
+	WebhookURL string
+	Username   string
+	Channel    string
+	client     *http.Client
+}
+
+// NewMattermostChannel 新しいMattermostチャンネルを作成
+// username・channelが空の場合はWebhookの既定の投稿者名・チャンネルに投稿する（上書きにはサーバー側の許可が必要）
+func NewMattermostChannel(webhookURL, username, channel string) *MattermostChannel {
+	return &MattermostChannel{
+		WebhookURL: webhookURL,
+		Username:   username,
+		Channel:    channel,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name チャンネル名
+func (c *MattermostChannel) Name() string {
+	return "mattermost"
+}
+
+// Send イベントをメッセージアタッチメント付きでMattermostへ投稿
+func (c *MattermostChannel) Send(ctx context.Context, event Event) error {
+	fields := []map[string]any{
+		{"title": "優先度", "value": string(event.Todo.Priority), "short": true},
+	}
+	if event.Todo.DueDate != nil {
+		fields = append(fields, map[string]any{"title": "期限", "value": event.Todo.FormatDue(), "short": true})
+	}
+	attachment := map[string]any{
+		"fallback": event.Summary(),
+		"color":    mattermostColor(event.Type),
+		"title":    fmt.Sprintf("%s（#%d）", event.Todo.Title, event.Todo.ID),
+		"text":     truncate(event.Todo.Description, mattermostTextLimit),
+		"fields":   fields,
+	}
+	payload := map[string]any{
+		"text":        slackText(event),
+		"attachments": []any{attachment},
+	}
+	if c.Username != "" {
+		payload["username"] = c.Username
+	}
+	if c.Channel != "" {
+		payload["channel"] = c.Channel
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Mattermostがステータス %d を返しました", resp.StatusCode)
+	}
+	return nil
+}
+
+// mattermostColor イベントごとのアタッチメントの色
+func mattermostColor(t EventType) string {
+	switch t {
+	case EventCompleted:
+		return mattermostColorCompleted
+	case EventDueSoon, EventStale:
+		return mattermostColorDueSoon
+	case EventOverdue, EventEscalated:
+		return mattermostColorOverdue
+	default:
+		return mattermostColorCreated
+	}
+}
@@ -0,0 +1,280 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db/model"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType Todoイベントの種類
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventCompleted EventType = "completed"
+	EventDueSoon   EventType = "due_soon"
+	EventOverdue   EventType = "overdue"
+	// EventEscalated エスカレーションルールによる通知（送信先の通知するイベントの設定に関係なく、ルールで指定したチャンネルへ送る）
+	EventEscalated EventType = "escalated"
+	// EventStale 長期間更新されていない未完了のTodo（放置タスクの検出ジョブが送る）
+	EventStale EventType = "stale"
+)
+
+// sendTimeout 1件の通知の送信タイムアウト
+const sendTimeout = 10 * time.Second
+
+// Event 通知するTodoイベント
+type Event struct {
+	Type       EventType
+	Todo       model.Todo
+	OccurredAt time.Time
+	// Note 補足（エスカレーションのルール名等）
+	Note string
+}
+
+// Summary 通知本文（チャンネル共通の1行テキスト）
+func (e Event) Summary() string {
+	var action string
+	switch e.Type {
+	case EventCreated:
+		action = "Todoが作成されました"
+	case EventCompleted:
+		action = "Todoが完了しました"
+	case EventDueSoon:
+		action = "Todoの期限が近づいています"
+	case EventOverdue:
+		action = "Todoの期限が切れました"
+	case EventEscalated:
+		action = "期限切れのTodoがエスカレーションされました"
+	case EventStale:
+		action = "長期間更新されていないTodoがあります"
+	default:
+		action = "Todoが更新されました"
+	}
+
+	text := fmt.Sprintf("%s: %s（#%d、優先度: %s", action, e.Todo.Title, e.Todo.ID, e.Todo.Priority)
+	if e.Todo.DueDate != nil {
+		text += "、期限: " + e.Todo.FormatDue()
+	}
+	text += "）"
+	if e.Note != "" {
+		text += " " + e.Note
+	}
+	return text
+}
+
+// Channel 通知の送信先（Slack等）
+type Channel interface {
+	// Name ログ等に使うチャンネル名
+	Name() string
+	// Send イベントを通知する
+	Send(ctx context.Context, event Event) error
+}
+
+// Timeouter 既定より長い送信タイムアウトが必要なチャンネル（リトライを行うチャンネル等）
+type Timeouter interface {
+	Timeout() time.Duration
+}
+
+// Digest 未完了Todoのダイジェスト
+type Digest struct {
+	GeneratedAt time.Time
+	// Frequency 送信先ごとのダイジェストの頻度（空の場合は全体向けの日次ダイジェスト）
+	Frequency model.DigestFrequency
+	Overdue   []model.Todo
+	// DueToday 今日が期限のTodo（毎週のダイジェストでは今後7日間が期限のTodo）
+	DueToday []model.Todo
+	Pending  []model.Todo
+	// Completed 前回のダイジェスト以降に完了したTodo（送信先ごとのダイジェストのみ）
+	Completed []model.Todo
+}
+
+// DueTitle 期限が近いTodoの見出し
+func (d Digest) DueTitle() string {
+	if d.Frequency == model.DigestWeekly {
+		return "今後7日間が期限"
+	}
+	return "今日が期限"
+}
+
+// Title ダイジェストの見出し
+func (d Digest) Title() string {
+	switch d.Frequency {
+	case model.DigestDaily:
+		return "Todo日次ダイジェスト"
+	case model.DigestWeekly:
+		return "Todo週次ダイジェスト"
+	}
+	return "Todoダイジェスト"
+}
+
+// DigestSender ダイジェストを送信できるチャンネル（メール等）
+type DigestSender interface {
+	SendDigest(ctx context.Context, digest Digest) error
+}
+
+// Subscription 送信先と通知条件
+type Subscription struct {
+	Channel Channel
+	// Events 通知するイベント（空の場合は全て）
+	Events []EventType
+	// MinPriority 通知する最低の優先度（空の場合は全て）
+	MinPriority model.Priority
+}
+
+// NewSubscription 設定値（イベント名・優先度の文字列）から送信先を作成
+func NewSubscription(channel Channel, events []string, minPriority string) Subscription {
+	sub := Subscription{Channel: channel, MinPriority: model.Priority(minPriority)}
+	for _, event := range events {
+		sub.Events = append(sub.Events, EventType(event))
+	}
+	return sub
+}
+
+// Matches イベントが通知条件を満たすか
+func (s Subscription) Matches(event Event) bool {
+	if s.MinPriority != "" && event.Todo.Priority.Rank() < s.MinPriority.Rank() {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, t := range s.Events {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// subscriptions 現在の送信先一覧
+	subscriptions atomic.Pointer[[]Subscription]
+	// inflight 送信中の通知（シャットダウン時に完了を待つ）
+	inflight sync.WaitGroup
+)
+
+// SetSubscriptions 送信先を差し替える
+func SetSubscriptions(list []Subscription) {
+	subscriptions.Store(&list)
+}
+
+// Publish 条件を満たす全ての送信先へ非同期に通知する（リクエスト処理をブロックしない）
+// 送信に失敗した場合はログに記録するのみで、呼び出し元にはエラーを返さない
+func Publish(ctx context.Context, event Event) {
+	list := subscriptions.Load()
+	if list == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	// リクエスト終了後も送信を続けるため、キャンセルを引き継がないコンテキストを使う
+	ctx = context.WithoutCancel(ctx)
+	for _, sub := range *list {
+		if !sub.Matches(event) {
+			continue
+		}
+		inflight.Add(1)
+		go func(channel Channel) {
+			defer inflight.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeoutFor(channel))
+			defer cancel()
+			if err := channel.Send(ctx, event); err != nil {
+				slog.ErrorContext(ctx, "通知の送信に失敗しました", "channel", channel.Name(), "event", event.Type, "todo_id", event.Todo.ID, "error", err)
+			}
+		}(sub.Channel)
+	}
+}
+
+// Listening イベントの種類を通知する送信先（優先度の条件は含めて返すため、Matchesで確認する）
+func Listening(eventType EventType) []Subscription {
+	list := subscriptions.Load()
+	if list == nil {
+		return nil
+	}
+
+	var result []Subscription
+	for _, sub := range *list {
+		if len(sub.Events) == 0 || slices.Contains(sub.Events, eventType) {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// Lookup チャンネル名から送信先を探す（見つからない場合はnil）
+func Lookup(name string) Channel {
+	list := subscriptions.Load()
+	if list == nil {
+		return nil
+	}
+	for _, sub := range *list {
+		if sub.Channel.Name() == name {
+			return sub.Channel
+		}
+	}
+	return nil
+}
+
+// Send 1つの送信先へ同期的に通知する（送信結果を記録して再試行する呼び出し元向け）
+func Send(ctx context.Context, channel Channel, event Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeoutFor(channel))
+	defer cancel()
+	return channel.Send(ctx, event)
+}
+
+// PublishDigest ダイジェストに対応する全ての送信先へ送信する（送信が終わるまでブロック）
+func PublishDigest(ctx context.Context, digest Digest) error {
+	list := subscriptions.Load()
+	if list == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, sub := range *list {
+		sender, ok := sub.Channel.(DigestSender)
+		if !ok {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, timeoutFor(sub.Channel))
+		if err := sender.SendDigest(sendCtx, digest); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.Channel.Name(), err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// timeoutFor チャンネルの送信タイムアウト
+func timeoutFor(channel Channel) time.Duration {
+	if t, ok := channel.(Timeouter); ok {
+		return t.Timeout()
+	}
+	return sendTimeout
+}
+
+// Wait 送信中の通知が完了するまで待つ（シャットダウン用）
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// slackPostMessageURL Bot Token で投稿する場合のAPI
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// SlackChannel Slackへ通知するチャンネル
+// BotTokenを指定した場合は chat.postMessage でChannelへ投稿し、それ以外はIncoming Webhookへ送信する
+type SlackChannel struct {
+	WebhookURL string
+	BotToken   string
+	Channel    string
+	client     *http.Client
+}
+
+// NewSlackChannel 新しいSlackチャンネルを作成
+func NewSlackChannel(webhookURL, botToken, channel string) *SlackChannel {
+	return &SlackChannel{
+		WebhookURL: webhookURL,
+		BotToken:   botToken,
+		Channel:    channel,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name チャンネル名
+func (c *SlackChannel) Name() string {
+	return "slack"
+}
+
+// Send イベントをSlackへ投稿
+func (c *SlackChannel) Send(ctx context.Context, event Event) error {
+	payload := map[string]string{"text": slackText(event)}
+
+	url := c.WebhookURL
+	if c.BotToken != "" {
+		url = slackPostMessageURL
+		payload["channel"] = c.Channel
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if c.BotToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.BotToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Slackがステータス %d を返しました", resp.StatusCode)
+	}
+
+	// Web APIはエラーでも200を返すため、レスポンスの ok を確認する
+	if c.BotToken != "" {
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("Slackのレスポンスを解析できません: %w", err)
+		}
+		if !result.OK {
+			return errors.New("Slackへの投稿に失敗しました: " + result.Error)
+		}
+	}
+	return nil
+}
+
+// slackText Slack向けの本文（イベントごとの絵文字を先頭に付ける）
+func slackText(event Event) string {
+	switch event.Type {
+	case EventCreated:
+		return ":memo: " + event.Summary()
+	case EventCompleted:
+		return ":white_check_mark: " + event.Summary()
+	case EventDueSoon:
+		return ":alarm_clock: " + event.Summary()
+	case EventOverdue:
+		return ":warning: " + event.Summary()
+	case EventEscalated:
+		return ":rotating_light: " + event.Summary()
+	case EventStale:
+		return ":hourglass: " + event.Summary()
+	default:
+		return event.Summary()
+	}
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// adaptiveCardContentType Adaptive Cardの添付のContent-Type
+const adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+
+// teamsTextLimit カードに載せる説明の最大文字数（Webhookのメッセージは28KBまで）
+const teamsTextLimit = 4000
+
+// TeamsChannel Microsoft Teams の Incoming Webhook（ワークフローのWebhookを含む）へ通知するチャンネル
+type TeamsChannel struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+// NewTeamsChannel 新しいTeamsチャンネルを作成
+func NewTeamsChannel(webhookURL string) *TeamsChannel {
+	return &TeamsChannel{
+		WebhookURL: webhookURL,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name チャンネル名
+func (c *TeamsChannel) Name() string {
+	return "teams"
+}
+
+// Send イベントをAdaptive CardのメッセージとしてTeamsへ投稿
+func (c *TeamsChannel) Send(ctx context.Context, event Event) error {
+	payload := map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": adaptiveCardContentType,
+				"content":     adaptiveCard(event),
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Teamsがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// adaptiveCard イベントのAdaptive Card（見出し・説明・優先度と期限のFactSet）
+func adaptiveCard(event Event) map[string]any {
+	facts := []map[string]string{
+		{"title": "ID", "value": "#" + strconv.FormatUint(uint64(event.Todo.ID), 10)},
+		{"title": "優先度", "value": string(event.Todo.Priority)},
+	}
+	if event.Todo.DueDate != nil {
+		facts = append(facts, map[string]string{"title": "期限", "value": event.Todo.FormatDue()})
+	}
+	if event.Note != "" {
+		facts = append(facts, map[string]string{"title": "補足", "value": event.Note})
+	}
+
+	body := []any{
+		map[string]any{
+			"type":   "TextBlock",
+			"text":   teamsHeading(event.Type),
+			"size":   "Small",
+			"weight": "Bolder",
+			"color":  teamsColor(event.Type),
+		},
+		map[string]any{
+			"type":   "TextBlock",
+			"text":   event.Todo.Title,
+			"size":   "Large",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+	}
+	if event.Todo.Description != "" {
+		body = append(body, map[string]any{
+			"type":     "TextBlock",
+			"text":     truncate(event.Todo.Description, teamsTextLimit),
+			"wrap":     true,
+			"isSubtle": true,
+		})
+	}
+	body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+
+	return map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+		"msteams": map[string]any{"width": "Full"},
+	}
+}
+
+// teamsHeading イベントの見出し
+func teamsHeading(t EventType) string {
+	switch t {
+	case EventCreated:
+		return "📝 Todoが作成されました"
+	case EventCompleted:
+		return "✅ Todoが完了しました"
+	case EventDueSoon:
+		return "⏰ Todoの期限が近づいています"
+	case EventOverdue:
+		return "⚠️ Todoの期限が切れました"
+	case EventEscalated:
+		return "🚨 期限切れのTodoがエスカレーションされました"
+	case EventStale:
+		return "⌛ 長期間更新されていないTodoがあります"
+	default:
+		return "Todoが更新されました"
+	}
+}
+
+// teamsColor イベントごとの見出しの色（Adaptive Cardの定義済みの色）
+func teamsColor(t EventType) string {
+	switch t {
+	case EventCompleted:
+		return "Good"
+	case EventDueSoon, EventStale:
+		return "Warning"
+	case EventOverdue, EventEscalated:
+		return "Attention"
+	default:
+		return "Accent"
+	}
+}
@@ -0,0 +1,283 @@
+// Package notion Notion API のデータベースとページの操作
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// APIの定数
+const (
+	// apiVersion Notion-Versionヘッダーに指定するAPIのバージョン
+	apiVersion = "2022-06-28"
+	// requestInterval リクエストの最小間隔（APIのレート制限は平均3リクエスト/秒）
+	requestInterval = 350 * time.Millisecond
+	// maxRetries レート制限（429）・一時的な障害の場合に再試行する回数
+	maxRetries = 3
+	// textChunkSize リッチテキスト1要素あたりの文字数の上限
+	textChunkSize = 2000
+	// maxTextChunks リッチテキストの要素数の上限
+	maxTextChunks = 100
+)
+
+// ErrNotFound ページ・データベースが存在しない（削除・インテグレーションの共有解除を含む）
+var ErrNotFound = errors.New("Notionのオブジェクトが見つかりません")
+
+// プロパティの種類
+const (
+	TypeTitle       = "title"
+	TypeRichText    = "rich_text"
+	TypeCheckbox    = "checkbox"
+	TypeSelect      = "select"
+	TypeStatus      = "status"
+	TypeMultiSelect = "multi_select"
+	TypeDate        = "date"
+	TypeNumber      = "number"
+)
+
+// Property データベースのプロパティの定義
+type Property struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Database データベースの定義（プロパティ名からプロパティの定義への対応）
+type Database struct {
+	ID         string              `json:"id"`
+	Properties map[string]Property `json:"properties"`
+}
+
+// Page ページ
+type Page struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Archived bool   `json:"archived"`
+}
+
+// Title タイトルプロパティの値
+func Title(s string) any {
+	return map[string]any{"title": richText(s)}
+}
+
+// RichText テキストプロパティの値（2000文字ごとに分割する）
+func RichText(s string) any {
+	return map[string]any{"rich_text": richText(s)}
+}
+
+// Checkbox チェックボックスプロパティの値
+func Checkbox(b bool) any {
+	return map[string]any{"checkbox": b}
+}
+
+// Select セレクトプロパティの値（空の場合は未選択）
+func Select(name string) any {
+	if name == "" {
+		return map[string]any{"select": nil}
+	}
+	return map[string]any{"select": map[string]string{"name": optionName(name)}}
+}
+
+// Status ステータスプロパティの値（ステータスはデータベースに存在する名前のみ指定できる）
+func Status(name string) any {
+	return map[string]any{"status": map[string]string{"name": name}}
+}
+
+// MultiSelect マルチセレクトプロパティの値
+func MultiSelect(names []string) any {
+	options := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		options = append(options, map[string]string{"name": optionName(name)})
+	}
+	return map[string]any{"multi_select": options}
+}
+
+// Date 日付プロパティの値（nilの場合は未設定）
+func Date(t *time.Time) any {
+	if t == nil {
+		return map[string]any{"date": nil}
+	}
+	return map[string]any{"date": map[string]string{"start": t.Format(time.RFC3339)}}
+}
+
+// Number 数値プロパティの値
+func Number(n float64) any {
+	return map[string]any{"number": n}
+}
+
+// richText 文字列をリッチテキストの要素に分割する
+func richText(s string) []map[string]any {
+	items := []map[string]any{}
+	for s != "" && len(items) < maxTextChunks {
+		chunk := s
+		if utf8.RuneCountInString(s) > textChunkSize {
+			chunk = string([]rune(s)[:textChunkSize])
+		}
+		items = append(items, map[string]any{"type": "text", "text": map[string]string{"content": chunk}})
+		s = s[len(chunk):]
+	}
+	return items
+}
+
+// optionName セレクトの選択肢名（カンマは使えないため置き換える）
+func optionName(name string) string {
+	return strings.ReplaceAll(name, ",", " ")
+}
+
+// Client Notion APIのクライアント
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+
+	// mu・last リクエストの間隔を空けるための直前のリクエスト時刻
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewClient 新しいクライアントを作成（tokenはインテグレーションのシークレット）
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetDatabase データベースの定義を取得
+func (c *Client) GetDatabase(ctx context.Context, databaseID string) (*Database, error) {
+	var db Database
+	if err := c.do(ctx, http.MethodGet, "/v1/databases/"+databaseID, nil, &db); err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+// CreatePage データベースにページを作成
+func (c *Client) CreatePage(ctx context.Context, databaseID string, properties map[string]any) (*Page, error) {
+	body := map[string]any{
+		"parent":     map[string]string{"database_id": databaseID},
+		"properties": properties,
+	}
+	var page Page
+	if err := c.do(ctx, http.MethodPost, "/v1/pages", body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdatePage ページのプロパティを更新
+func (c *Client) UpdatePage(ctx context.Context, pageID string, properties map[string]any) error {
+	body := map[string]any{"properties": properties, "archived": false}
+	return c.do(ctx, http.MethodPatch, "/v1/pages/"+pageID, body, nil)
+}
+
+// ArchivePage ページをアーカイブ（Notionのゴミ箱へ移動）
+func (c *Client) ArchivePage(ctx context.Context, pageID string) error {
+	return c.do(ctx, http.MethodPatch, "/v1/pages/"+pageID, map[string]any{"archived": true}, nil)
+}
+
+// do APIを呼び出し、レスポンスをoutにデコードする（429・5xxは再試行する）
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var payload []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		payload = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		retryAfter, err := c.send(ctx, method, path, payload, out)
+		if retryAfter == 0 || attempt >= maxRetries {
+			return err
+		}
+		select {
+		case <-time.After(retryAfter):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// send 1回のリクエストを送る（再試行すべき場合は待つ時間を返す）
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, out any) (time.Duration, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Notion-Version", apiVersion)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		wait := time.Second
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return wait, fmt.Errorf("Notion APIがステータス %d を返しました", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &apiErr) == nil && apiErr.Message != "" {
+			return 0, fmt.Errorf("Notion APIがステータス %d を返しました（%s）: %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return 0, fmt.Errorf("Notion APIがステータス %d を返しました: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	if out == nil {
+		return 0, nil
+	}
+	return 0, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// wait 直前のリクエストから requestInterval が経過するまで待つ
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	next := c.last.Add(requestInterval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.last = next
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(next)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+// Package pathnorm APIのパスの正規化（末尾のスラッシュ・重複したスラッシュ等）
+// クライアントの実装差で /api/v1/todos/ と /api/v1/todos が別のパスとして404になるのを防ぐ
+package pathnorm
+
+import (
+	"myapp/config"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Prefix 正規化の対象とするパス（CalDAVのコレクション等、末尾のスラッシュに意味があるパスは対象外）
+const Prefix = "/api/"
+
+// 正規化の方法（server.path_normalize）
+const (
+	// ModeRewrite ルーティングの前にパスを書き換える（既定。クライアントからは区別できない）
+	ModeRewrite = "rewrite"
+	// ModeRedirect 正規化したパスへ308でリダイレクトする（メソッド・ボディは引き継がれる）
+	ModeRedirect = "redirect"
+	// ModeOff 正規化しない
+	ModeOff = "off"
+)
+
+// Middleware APIのパスの末尾のスラッシュ・重複したスラッシュ・「.」「..」を取り除くミドルウェア
+// ルーティングの前に適用するため、ルーターより前（他のミドルウェアがパスを参照する前）に登録する
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode := config.Current().Server.PathNormalize
+		if mode == ModeOff || !strings.HasPrefix(r.URL.Path, Prefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cleaned := path.Clean(r.URL.Path)
+		if cleaned == r.URL.Path {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		u := *r.URL
+		u.Path = cleaned
+		if u.RawPath != "" {
+			u.RawPath = path.Clean(u.RawPath)
+		}
+		if mode == ModeRedirect {
+			http.Redirect(w, r, u.RequestURI(), http.StatusPermanentRedirect)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL = &u
+		next.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,62 @@
+package profiling
+
+import (
+	"myapp/adminauth"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Config pprofエンドポイントの設定
+type Config struct {
+	// Enabled pprofを公開するか
+	Enabled bool
+	// Addr 管理者専用ポートのアドレス（設定時はメインのポートとは別に公開）
+	Addr string
+	// Token メインのポートで公開する場合に要求するBearerトークン
+	Token string
+}
+
+// LoadConfig 環境変数からpprofの設定を読み込む
+// PPROF_ENABLED: trueで有効化
+// PPROF_ADDR: 管理者専用ポート（例: 127.0.0.1:6060）
+// PPROF_TOKEN: メインのポートで公開する場合の認証トークン
+func LoadConfig() Config {
+	return Config{
+		Enabled: os.Getenv("PPROF_ENABLED") == "true",
+		Addr:    os.Getenv("PPROF_ADDR"),
+		Token:   os.Getenv("PPROF_TOKEN"),
+	}
+}
+
+// NewServer 管理者専用ポートでpprofを公開するサーバーを作成
+func NewServer(addr string) *http.Server {
+	router := chi.NewRouter()
+	router.Mount("/debug", middleware.Profiler())
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
+}
+
+// Mount 認証付きでpprofをルーターに追加（/debug/pprof/*）
+func Mount(router chi.Router, token string) {
+	router.With(RequireToken(token)).Mount("/debug", middleware.Profiler())
+}
+
+// RequireToken Bearerトークンを検証するミドルウェア
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !adminauth.ValidToken(r, token) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pprof"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Package queue DBに永続化する非同期ジョブキュー
+// 失敗したジョブは指数バックオフで再試行し、試行回数の上限に達したジョブはデッドレター（dead）として残す
+// ジョブの取得は FOR UPDATE SKIP LOCKED で行うため、複数インスタンスで起動しても同じジョブを重複して実行しない
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/tracing"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Handler ジョブを実行する処理（エラーを返した場合は再試行する。再試行しても成功しない場合はPermanentで包む）
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Enqueuer ジョブを積む側のインターフェース
+type Enqueuer interface {
+	Enqueue(ctx context.Context, kind string, payload any) (*model.QueueJob, error)
+}
+
+// permanentError 再試行しない失敗
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 再試行しても成功しない失敗として包む（ジョブはすぐにデッドレターになる）
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// attemptKey 実行中のジョブの試行回数を保持するcontextのキー
+type attemptKey struct{}
+
+// Attempt 実行中のジョブの試行回数と上限を返す（ジョブの処理の中でのみ有効。それ以外ではokがfalse）
+// 最後の試行かどうかで失敗時の扱いを変える処理に使う
+func Attempt(ctx context.Context) (attempt, maxAttempts int, ok bool) {
+	job, ok := ctx.Value(attemptKey{}).(*model.QueueJob)
+	if !ok {
+		return 0, 0, false
+	}
+	return job.Attempts, job.MaxAttempts, true
+}
+
+// Options ジョブキューの設定
+type Options struct {
+	// Concurrency 同時に実行するジョブ数
+	Concurrency int
+	// PollInterval 実行待ちのジョブがない場合に次に確認するまでの間隔
+	PollInterval time.Duration
+	// Lease 実行中のロックの期限（ジョブの実行時間の上限を兼ねる）
+	Lease time.Duration
+	// MaxAttempts 種類ごとに指定しない場合の試行回数の上限
+	MaxAttempts int
+	// BackoffBase・BackoffMax 再試行までの間隔（失敗のたびに倍増し、BackoffMaxで頭打ち）
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// Queue ジョブキュー
+type Queue struct {
+	db   *gorm.DB
+	opts Options
+	// owner ロックを保持するインスタンスの識別子（ホスト名とプロセスID）
+	owner string
+	// wake ジョブを積んだことをワーカーに知らせる（同じインスタンスで積んだジョブはポーリングを待たずに実行する）
+	wake chan struct{}
+
+	mu    sync.RWMutex
+	kinds map[string]*kind
+}
+
+// kind 登録されたジョブの種類
+type kind struct {
+	handler     Handler
+	maxAttempts int
+}
+
+// New 新しいジョブキューを作成
+func New(opts Options) *Queue {
+	host, _ := os.Hostname()
+	return &Queue{
+		db:    db.GetDB(),
+		opts:  opts,
+		owner: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		wake:  make(chan struct{}, 1),
+		kinds: make(map[string]*kind),
+	}
+}
+
+// Register ジョブの種類と実行する処理を登録（maxAttemptsが0の場合は設定の上限。Runの前に呼び出す）
+func (q *Queue) Register(name string, handler Handler, maxAttempts int) {
+	if maxAttempts <= 0 {
+		maxAttempts = q.opts.MaxAttempts
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.kinds[name] = &kind{handler: handler, maxAttempts: maxAttempts}
+}
+
+// Kinds 登録済みのジョブの種類
+func (q *Queue) Kinds() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	names := make([]string, 0, len(q.kinds))
+	for name := range q.kinds {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Enqueue ジョブを積む（payloadはJSONに変換して保存する）
+func (q *Queue) Enqueue(ctx context.Context, kindName string, payload any) (*model.QueueJob, error) {
+	ctx, span := tracing.Start(ctx, "Queue.Enqueue", tracing.SpanKindInternal)
+	defer span.End()
+
+	q.mu.RLock()
+	k, ok := q.kinds[kindName]
+	q.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未登録のジョブの種類です: %s", kindName)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ジョブの内容をJSONに変換できません: %w", err)
+	}
+
+	job := &model.QueueJob{
+		Kind:        kindName,
+		Payload:     string(body),
+		Status:      model.QueueJobPending,
+		MaxAttempts: k.maxAttempts,
+		RunAt:       time.Now(),
+	}
+	if err := q.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("ジョブの登録に失敗しました: %w", err)
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Run ワーカーを起動してジョブを実行する（ctxがキャンセルされ、実行中のジョブが終わるまでブロック）
+func (q *Queue) Run(ctx context.Context) {
+	slog.InfoContext(ctx, "ジョブキューのワーカーを起動しました", "concurrency", q.opts.Concurrency, "kinds", q.Kinds())
+	var wg sync.WaitGroup
+	for i := 0; i < q.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work 実行できるジョブがなくなるまで実行し、なくなったらジョブが積まれるかポーリングの間隔が経つまで待つことを繰り返す
+func (q *Queue) work(ctx context.Context) {
+	for {
+		job, err := q.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "ジョブの取得に失敗しました", "error", err)
+		}
+		if job != nil {
+			q.process(ctx, job)
+			continue
+		}
+
+		timer := time.NewTimer(q.opts.PollInterval)
+		select {
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// claim 実行時刻を過ぎたジョブ（ロックの期限が切れた実行中のジョブを含む）を1件取得してロックする（ない場合はnil）
+func (q *Queue) claim(ctx context.Context) (*model.QueueJob, error) {
+	now := time.Now()
+	var jobs []*model.QueueJob
+	err := q.db.WithContext(ctx).Raw(`UPDATE queue_jobs
+SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+WHERE id = (
+	SELECT id FROM queue_jobs
+	WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)
+	ORDER BY run_at, id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`,
+		model.QueueJobRunning, q.owner, now.Add(q.opts.Lease), now,
+		model.QueueJobPending, now, model.QueueJobRunning, now,
+	).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+// process ジョブを実行し、結果に応じて成功・再試行待ち・デッドレターにする
+func (q *Queue) process(ctx context.Context, job *model.QueueJob) {
+	q.mu.RLock()
+	k, ok := q.kinds[job.Kind]
+	q.mu.RUnlock()
+
+	var err error
+	if ok {
+		runCtx, cancel := context.WithTimeout(ctx, q.opts.Lease)
+		err = q.run(runCtx, k.handler, job)
+		cancel()
+	} else {
+		err = Permanent(fmt.Errorf("未登録のジョブの種類です: %s", job.Kind))
+	}
+
+	now := time.Now()
+	updates := map[string]any{"locked_by": "", "locked_until": nil, "updated_at": now}
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		updates["status"] = model.QueueJobSucceeded
+		updates["last_error"] = ""
+		updates["finished_at"] = now
+	case ctx.Err() != nil:
+		// シャットダウンで中断したジョブは試行回数に数えず、次に起動したワーカーで実行する
+		updates["status"] = model.QueueJobPending
+		updates["attempts"] = job.Attempts - 1
+		updates["run_at"] = now
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		updates["status"] = model.QueueJobDead
+		updates["last_error"] = err.Error()
+		updates["finished_at"] = now
+		slog.ErrorContext(ctx, "ジョブがデッドレターになりました", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+	default:
+		updates["status"] = model.QueueJobPending
+		updates["last_error"] = err.Error()
+		updates["run_at"] = now.Add(q.backoff(job.Attempts))
+		slog.WarnContext(ctx, "ジョブが失敗しました。再試行します", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "run_at", updates["run_at"], "error", err)
+	}
+
+	// シャットダウン中でも結果は記録する（ロックの期限切れで別のインスタンスが取得した場合は上書きしない）
+	result := q.db.WithContext(context.WithoutCancel(ctx)).Model(&model.QueueJob{}).
+		Where("id = ? AND locked_by = ? AND attempts = ?", job.ID, q.owner, job.Attempts).
+		UpdateColumns(updates)
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "ジョブの結果の記録に失敗しました", "job_id", job.ID, "kind", job.Kind, "error", result.Error)
+	}
+}
+
+// run ジョブの処理を呼び出す（パニックは再試行しない失敗として扱う）
+func (q *Queue) run(ctx context.Context, handler Handler, job *model.QueueJob) (err error) {
+	ctx, span := tracing.Start(ctx, "Queue.Run "+job.Kind, tracing.SpanKindInternal)
+	defer span.End()
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("パニックが発生しました: %v", r))
+		}
+	}()
+	return handler(context.WithValue(ctx, attemptKey{}, job), json.RawMessage(job.Payload))
+}
+
+// backoff attempts回目の失敗の後に再試行するまでの間隔（BackoffBaseから倍増し、最大±10%のゆらぎを加える）
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.opts.BackoffBase
+	for i := 1; i < attempts && delay < q.opts.BackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, q.opts.BackoffMax)
+	jitter := time.Duration(rand.Int63n(int64(delay)/5+1)) - delay/10
+	return delay + jitter
+}
@@ -0,0 +1,151 @@
+// Package quickadd モバイルのショートカットやブックマークレットから1行のテキストでTodoを追加する軽量エンドポイント
+package quickadd
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"myapp/db/model"
+	"myapp/service"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Path エンドポイントのパス
+const Path = "/quick-add"
+
+// 入力の制限
+const (
+	// maxBodyBytes リクエストボディの上限（1行のテキストのみ受け付けるため小さくする）
+	maxBodyBytes = 4 << 10
+	// titleMaxLength Todoのタイトルの最大文字数
+	titleMaxLength = 255
+)
+
+// Handler クイック追加のハンドラー
+type Handler struct {
+	todoService service.TodoService
+	tokenHashes [][32]byte
+}
+
+// NewHandler 新しいハンドラーを作成（tokensのいずれかで認証する）
+func NewHandler(todoService service.TodoService, tokens []string) *Handler {
+	hashes := make([][32]byte, 0, len(tokens))
+	for _, token := range tokens {
+		hashes = append(hashes, sha256.Sum256([]byte(token)))
+	}
+	return &Handler{todoService: todoService, tokenHashes: hashes}
+}
+
+// ServeHTTP テキストの1行目をタイトルとしてTodoを作成する
+// 本文はtext/plain・フォーム（text）・JSON（{"text": ...}）のいずれかで受け付ける
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeText(w, http.StatusMethodNotAllowed, "POSTで送信してください")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	text, formToken, err := readText(r)
+	if err != nil {
+		writeText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.authorized(token(r, formToken)) {
+		writeText(w, http.StatusUnauthorized, "トークンが不正です")
+		return
+	}
+
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		writeText(w, http.StatusBadRequest, "追加するTodoのテキストを入力してください")
+		return
+	}
+	if utf8.RuneCountInString(title) > titleMaxLength {
+		title = string([]rune(title)[:titleMaxLength])
+	}
+
+	todo, err := h.todoService.CreateTodo(r.Context(), &model.TodoCreateRequest{Title: title})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "クイック追加でのTodo作成に失敗しました", "error", err)
+		writeText(w, http.StatusInternalServerError, "Todoを追加できませんでした")
+		return
+	}
+
+	if acceptsJSON(r) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]uint{"id": todo.ID})
+		return
+	}
+	writeText(w, http.StatusCreated, fmt.Sprintf("#%d %s", todo.ID, todo.Title))
+}
+
+// authorized トークンをハッシュにして定数時間で比較する（登録済みのトークン数によらず全て比較する）
+func (h *Handler) authorized(token string) bool {
+	if token == "" {
+		return false
+	}
+	hash := sha256.Sum256([]byte(token))
+	ok := 0
+	for _, expected := range h.tokenHashes {
+		ok |= subtle.ConstantTimeCompare(hash[:], expected[:])
+	}
+	return ok == 1
+}
+
+// readText Content-Typeに応じて本文のテキストを読み込む（フォームの場合はtokenフィールドも返す）
+func readText(r *http.Request) (string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		if err := r.ParseMultipartForm(maxBodyBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return "", "", errors.New("フォームを読み込めません")
+		}
+		return r.PostForm.Get("text"), r.PostForm.Get("token"), nil
+	case "application/json":
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return "", "", errors.New(`JSONは {"text": "..."} の形式で送信してください`)
+		}
+		return body.Text, "", nil
+	default:
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", "", errors.New("本文が大きすぎます")
+		}
+		return string(b), "", nil
+	}
+}
+
+// token Authorizationヘッダー（Bearer）またはフォームのtokenを取り出す
+// ブックマークレットはヘッダーを付けられない場合があるためフォームでも受け付ける（クエリはエラーレポート等に記録されるため受け付けない）
+func token(r *http.Request, formToken string) string {
+	if value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(value)
+	}
+	return formToken
+}
+
+// acceptsJSON JSONのレスポンスを求めているか
+func acceptsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// writeText テキストのレスポンスを書き込む
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, text+"\n")
+}
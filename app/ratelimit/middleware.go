@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"myapp/clientip"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Middleware クライアントIP単位でレートリミットを適用するミドルウェア
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(store Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := config.Current().RateLimit
+			if !cfg.Enabled || excluded(cfg.ExcludePaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scope, limit := limitFor(cfg, r.URL.Path)
+			key := scope + "|" + clientip.FromRequest(r, cfg.TrustProxy, cfg.TrustedProxies)
+
+			result, err := store.Allow(r.Context(), key, limit)
+			if err != nil {
+				// 判定できない場合はフェイルオープン（制限せずに通す）かフェイルクローズ（503）
+				slog.ErrorContext(r.Context(), "レートリミットの判定に失敗しました", "error", err, "fail_open", cfg.FailOpen)
+				if !cfg.FailOpen {
+					writeError(w, http.StatusServiceUnavailable, huma.Error503ServiceUnavailable(i18n.T(r.Context(), "一時的にリクエストを受け付けられません")))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(secondsCeil(result.ResetAfter)))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, secondsCeil(result.RetryAfter))))
+				writeError(w, http.StatusTooManyRequests, huma.Error429TooManyRequests(i18n.T(r.Context(), "リクエストが多すぎます。しばらくしてから再試行してください")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitFor パスに適用する制限を取得（最も長く先頭一致したパスごとの設定、なければグローバル設定）
+func limitFor(cfg config.RateLimitConfig, path string) (string, Limit) {
+	scope := "global"
+	limit := Limit{Rate: cfg.RequestsPerSecond, Burst: cfg.Burst}
+
+	matched := 0
+	for _, rule := range cfg.Paths {
+		if strings.HasPrefix(path, rule.Prefix) && len(rule.Prefix) > matched {
+			matched = len(rule.Prefix)
+			scope = rule.Prefix
+			limit = Limit{Rate: rule.RequestsPerSecond, Burst: rule.Burst}
+		}
+	}
+	return scope, limit
+}
+
+// excluded 制限対象外のパスか
+func excluded(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError problem+json形式のエラーレスポンスを書き込む
+func writeError(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit 1キーあたりの制限（Rate: 1秒あたりの補充数、Burst: 最大トークン数）
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result 制限の判定結果
+type Result struct {
+	// Allowed リクエストを許可するか
+	Allowed bool
+	// Limit 上限（ヘッダーの X-RateLimit-Limit）
+	Limit int
+	// Remaining 残り回数
+	Remaining int
+	// ResetAfter 上限まで回復するまでの時間
+	ResetAfter time.Duration
+	// RetryAfter 拒否した場合、次に許可されるまでの時間
+	RetryAfter time.Duration
+}
+
+// Store レートリミットの状態を保持するバックエンド
+type Store interface {
+	// Allow keyに対するリクエストを1件消費できるか判定
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// secondsCeil ヘッダー用に秒単位へ切り上げ
+func secondsCeil(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
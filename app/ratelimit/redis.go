@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript スライディングウィンドウログ方式で判定するLuaスクリプト
+// KEYS[1]: キー, ARGV[1]: 現在時刻(ms), ARGV[2]: ウィンドウ幅(ms), ARGV[3]: 上限, ARGV[4]: メンバーID
+// 戻り値: {許可=1/拒否=0, ウィンドウ内の件数, 最古のリクエスト時刻(ms)}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local oldestScore = now
+if oldest[2] then
+  oldestScore = tonumber(oldest[2])
+end
+return {allowed, count, oldestScore}
+`)
+
+// RedisStore Redisのスライディングウィンドウで制限するストア（複数インスタンスで制限を共有）
+// ウィンドウ幅は Burst/Rate 秒、ウィンドウ内の上限は Burst 件
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 新しいRedisストアを作成
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+		}),
+		prefix: "ratelimit:",
+	}
+}
+
+// Allow ウィンドウ内のリクエスト数が上限未満なら許可
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	window := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	now := time.Now()
+
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{s.prefix + key},
+		now.UnixMilli(), window.Milliseconds(), limit.Burst, memberID(now),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("Redisでのレートリミット判定に失敗しました: %w", err)
+	}
+
+	allowed, count, oldest := values[0] == 1, int(values[1]), values[2]
+	resetAfter := time.Until(time.UnixMilli(oldest).Add(window))
+
+	result := &Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  max(0, limit.Burst-count),
+		ResetAfter: resetAfter,
+	}
+	if !allowed {
+		result.RetryAfter = resetAfter
+	}
+	return result, nil
+}
+
+// Close Redis接続を閉じる
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// memberID 同一ミリ秒のリクエストを区別するためのメンバーID
+func memberID(now time.Time) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%d-%s", now.UnixNano(), hex.EncodeToString(b))
+}
@@ -0,0 +1,110 @@
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"myapp/webhook"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxSlackStackLength Slackに送るスタックトレースの最大文字数
+const maxSlackStackLength = 2500
+
+// Hook パニック発生時に呼び出されるアラートフック
+type Hook interface {
+	Name() string
+	Fire(ctx context.Context, report *PanicReport) error
+}
+
+// WebhookHook PanicReportをJSONでPOSTするフック（署名シークレット設定時はHMAC署名を付与）
+type WebhookHook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookHook 新しいWebhookフックを作成
+func NewWebhookHook(url string) *WebhookHook {
+	return &WebhookHook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name フック名
+func (h *WebhookHook) Name() string {
+	return "webhook"
+}
+
+// Fire PanicReportを送信（ジョブキューが設定されている場合はキューに積む）
+func (h *WebhookHook) Fire(ctx context.Context, report *PanicReport) error {
+	return webhook.Deliver(ctx, h.client, h.url, report)
+}
+
+// SlackHook SlackのIncoming Webhookに通知するフック
+type SlackHook struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackHook 新しいSlackフックを作成
+func NewSlackHook(url string) *SlackHook {
+	return &SlackHook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name フック名
+func (h *SlackHook) Name() string {
+	return "slack"
+}
+
+// Fire パニックの概要とスタックトレースをSlackに送信
+func (h *SlackHook) Fire(ctx context.Context, report *PanicReport) error {
+	stack := report.Stack
+	if len(stack) > maxSlackStackLength {
+		stack = stack[:maxSlackStackLength] + "\n..."
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, ":rotating_light: *パニックが発生しました*: `%s`\n", report.Panic)
+	fmt.Fprintf(&text, "*リクエスト*: `%s %s`\n", report.Method, report.Path)
+	if report.RequestID != "" {
+		fmt.Fprintf(&text, "*リクエストID*: `%s`\n", report.RequestID)
+	}
+	if report.TraceID != "" {
+		fmt.Fprintf(&text, "*トレースID*: `%s`\n", report.TraceID)
+	}
+	fmt.Fprintf(&text, "```%s```", stack)
+
+	return postJSON(ctx, h.client, h.url, map[string]string{"text": text.String()})
+}
+
+// postJSON JSONをPOSTし、2xx以外はエラーとする
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ステータス %d が返されました", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"myapp/config"
+	"myapp/i18n"
+	"myapp/requestid"
+	"myapp/tracing"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// PanicReport パニック発生時に記録・通知する内容
+type PanicReport struct {
+	Time       time.Time `json:"time"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	TraceID    string    `json:"trace_id,omitempty"`
+}
+
+// Recoverer パニックを回復して500を返し、ログ出力とアラートフックの発火を行うミドルウェア
+type Recoverer struct {
+	hooks    []Hook
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	lastSent time.Time
+}
+
+// New アラートフックを指定してRecovererを作成
+// cooldownの間は連続したパニックのアラートを抑制する（ログは常に出力）
+func New(cooldown time.Duration, hooks ...Hook) *Recoverer {
+	return &Recoverer{
+		hooks:    hooks,
+		cooldown: cooldown,
+	}
+}
+
+// NewFromConfig 設定（panic_alert）からアラートフックを構成してRecovererを作成
+// webhook_url: 汎用Webhook（PanicReportをJSONでPOST）
+// slack_webhook_url: SlackのIncoming Webhook
+func NewFromConfig(c config.PanicAlertConfig) *Recoverer {
+	var hooks []Hook
+	if c.WebhookURL != "" {
+		hooks = append(hooks, NewWebhookHook(c.WebhookURL))
+	}
+	if c.SlackWebhookURL != "" {
+		hooks = append(hooks, NewSlackHook(c.SlackWebhookURL))
+	}
+	return New(c.Cooldown, hooks...)
+}
+
+// Middleware chiのミドルウェアとして使用
+func (rc *Recoverer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// クライアント切断による中断はそのまま伝播させる
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			report := &PanicReport{
+				Time:       time.Now(),
+				Panic:      fmt.Sprint(rec),
+				Stack:      string(debug.Stack()),
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Query:      r.URL.RawQuery,
+				RemoteAddr: r.RemoteAddr,
+				UserAgent:  r.UserAgent(),
+				RequestID:  requestid.FromContext(r.Context()),
+				TraceID:    tracing.TraceIDFromContext(r.Context()),
+			}
+
+			slog.ErrorContext(r.Context(), "パニックが発生しました",
+				slog.String("panic", report.Panic),
+				slog.String("stack", report.Stack),
+				slog.String("query", report.Query),
+				slog.String("remote_addr", report.RemoteAddr),
+				slog.String("user_agent", report.UserAgent),
+			)
+
+			rc.alert(report)
+
+			if r.Header.Get("Connection") != "Upgrade" {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(huma.Error500InternalServerError(i18n.T(r.Context(), "内部エラーが発生しました")))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// alert クールダウン中でなければ全フックを非同期で発火
+func (rc *Recoverer) alert(report *PanicReport) {
+	if len(rc.hooks) == 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	if !rc.lastSent.IsZero() && report.Time.Sub(rc.lastSent) < rc.cooldown {
+		rc.mu.Unlock()
+		slog.Debug("クールダウン中のためパニックのアラートを抑制しました")
+		return
+	}
+	rc.lastSent = report.Time
+	rc.mu.Unlock()
+
+	for _, hook := range rc.hooks {
+		go func(hook Hook) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := hook.Fire(ctx, report); err != nil {
+				slog.Warn("パニックのアラート送信に失敗しました", "hook", hook.Name(), "error", err)
+			}
+		}(hook)
+	}
+}
@@ -0,0 +1,105 @@
+package recurrence
+
+import (
+	"fmt"
+	"time"
+)
+
+// Holidays 祝日のカレンダー（組み込みのカレンダーと、追加で指定した日付）
+type Holidays struct {
+	calendar string
+	extra    map[string]bool
+}
+
+// NewHolidays 祝日のカレンダーを作成
+// calendarは jp（日本の国民の祝日・振替休日・国民の休日）または none、extraは追加の休日（YYYY-MM-DD）
+func NewHolidays(calendar string, extra []string) (*Holidays, error) {
+	if calendar != "jp" && calendar != "none" && calendar != "" {
+		return nil, fmt.Errorf("祝日のカレンダーには jp または none を指定してください: %q", calendar)
+	}
+	h := &Holidays{calendar: calendar, extra: make(map[string]bool, len(extra))}
+	for _, date := range extra {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("休日はYYYY-MM-DDの形式で指定してください: %q", date)
+		}
+		h.extra[date] = true
+	}
+	return h, nil
+}
+
+// Contains tの日付（tのタイムゾーンでの日付）が祝日・追加の休日か
+func (h *Holidays) Contains(t time.Time) bool {
+	if h == nil {
+		return false
+	}
+	if h.extra[t.Format("2006-01-02")] {
+		return true
+	}
+	if h.calendar == "jp" {
+		return isJapaneseHoliday(t.Year(), t.Month(), t.Day())
+	}
+	return false
+}
+
+// isJapaneseHoliday 日本の休日か（国民の祝日に加え、振替休日・国民の休日を含む。2020年・2021年の特例による移動は考慮しない）
+func isJapaneseHoliday(year int, month time.Month, day int) bool {
+	if isNationalHoliday(year, month, day) {
+		return true
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	// 振替休日: 日曜日の祝日から続く祝日の翌日で、最初の祝日でない日
+	for d := date.AddDate(0, 0, -1); isNationalHoliday(d.Year(), d.Month(), d.Day()); d = d.AddDate(0, 0, -1) {
+		if d.Weekday() == time.Sunday {
+			return true
+		}
+	}
+
+	// 国民の休日: 前日と翌日が祝日の日
+	prev, next := date.AddDate(0, 0, -1), date.AddDate(0, 0, 1)
+	return isNationalHoliday(prev.Year(), prev.Month(), prev.Day()) && isNationalHoliday(next.Year(), next.Month(), next.Day())
+}
+
+// isNationalHoliday 「国民の祝日に関する法律」の祝日か
+func isNationalHoliday(year int, month time.Month, day int) bool {
+	switch month {
+	case time.January:
+		return day == 1 || day == nthMonday(year, month, 2) // 元日・成人の日
+	case time.February:
+		return day == 11 || day == 23 // 建国記念の日・天皇誕生日
+	case time.March:
+		return day == vernalEquinox(year) // 春分の日
+	case time.April:
+		return day == 29 // 昭和の日
+	case time.May:
+		return day == 3 || day == 4 || day == 5 // 憲法記念日・みどりの日・こどもの日
+	case time.July:
+		return day == nthMonday(year, month, 3) // 海の日
+	case time.August:
+		return day == 11 // 山の日
+	case time.September:
+		return day == nthMonday(year, month, 3) || day == autumnalEquinox(year) // 敬老の日・秋分の日
+	case time.October:
+		return day == nthMonday(year, month, 2) // スポーツの日
+	case time.November:
+		return day == 3 || day == 23 // 文化の日・勤労感謝の日
+	}
+	return false
+}
+
+// nthMonday 月の第n月曜日の日
+func nthMonday(year int, month time.Month, n int) int {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(time.Monday) - int(first.Weekday()) + 7) % 7
+	return 1 + offset + (n-1)*7
+}
+
+// vernalEquinox 春分日（1980〜2099年に適用できる近似式）
+func vernalEquinox(year int) int {
+	return int(20.8431+0.242194*float64(year-1980)) - (year-1980)/4
+}
+
+// autumnalEquinox 秋分日（1980〜2099年に適用できる近似式）
+func autumnalEquinox(year int) int {
+	return int(23.2488+0.242194*float64(year-1980)) - (year-1980)/4
+}
@@ -0,0 +1,269 @@
+// Package recurrence 繰り返しTodoの繰り返しルール（iCalendarのRRULEのサブセット）と祝日の判定
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq 繰り返しの単位
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+// maxSearchDays 次の日付を探す範囲（該当する日付がないルールで無限に探さないための上限）
+const maxSearchDays = 366 * 10
+
+// weekdays BYDAYの曜日の表記
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// WeekdayNum BYDAYの1項目（Nが0の場合は全ての週、正の場合は月・年の第N週、負の場合は最後からN番目）
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule 繰り返しルール
+// FREQ・INTERVAL・BYDAY・BYMONTHDAY・BYMONTH・UNTILに対応し、
+// BY*を省略した場合は前回の日付（曜日・日・月）を引き継ぐ
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	// Until 繰り返しの終了日時（ゼロ値の場合は終了しない）
+	Until time.Time
+}
+
+// Parse "FREQ=WEEKLY;BYDAY=MO,WE" 等のルールを解釈する（先頭の "RRULE:" は省略可能）
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("繰り返しルールが空です")
+	}
+
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("繰り返しルールの項目は NAME=VALUE の形式で指定してください: %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Freq(strings.ToUpper(value))
+			if !slices.Contains([]Freq{Daily, Weekly, Monthly, Yearly}, r.Freq) {
+				return nil, fmt.Errorf("FREQにはDAILY / WEEKLY / MONTHLY / YEARLYを指定してください: %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 || r.Interval > 1000 {
+				return nil, fmt.Errorf("INTERVALには1〜1000を指定してください: %q", value)
+			}
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value, "BYMONTHDAY", -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, "BYMONTH", 1, 12)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "WKST":
+			// 週の始まりは月曜日で固定（MO以外は未対応）
+			if !strings.EqualFold(value, "MO") {
+				return nil, fmt.Errorf("WKSTはMOのみ対応しています: %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("未対応の繰り返しルールの項目です: %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("FREQを指定してください")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("BYDAYの週の指定（1MO等）はFREQ=MONTHLY / YEARLYの場合のみ使えます")
+		}
+	}
+	return r, nil
+}
+
+// parseByDay "MO,WE" "1MO,-1FR" 等を解釈する
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("BYDAYの曜日が不正です: %q", item)
+		}
+		wd, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("BYDAYの曜日が不正です: %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("BYDAYの週には1〜5または-1〜-5を指定してください: %q", item)
+			}
+		}
+		result = append(result, WeekdayNum{N: n, Weekday: wd})
+	}
+	return result, nil
+}
+
+// parseInts カンマ区切りの整数を解釈する（0は不可）
+func parseInts(value, name string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("%sには%d〜%dの値（0以外）を指定してください: %q", name, min, max, item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// parseUntil UNTILの日付（YYYYMMDD）または日時（YYYYMMDDTHHMMSSZ）を解釈する
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// 日付のみの場合はその日の終わりまでを含める
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTILはYYYYMMDDまたはYYYYMMDDTHHMMSSZの形式で指定してください: %q", value)
+}
+
+// Next prevの次の日時（該当する日付がない・UNTILを過ぎた場合はゼロ値）
+// 日付と時刻はlocで解釈し、時刻（時・分・秒）はprevの時刻を引き継ぐため、夏時間の切り替えをまたいでも同じ時刻になる
+func (r *Rule) Next(prev time.Time, loc *time.Location) time.Time {
+	prev = prev.In(loc)
+	hour, minute, sec := prev.Clock()
+	for i := 1; i <= maxSearchDays; i++ {
+		day := time.Date(prev.Year(), prev.Month(), prev.Day()+i, 0, 0, 0, 0, time.UTC)
+		if !r.matches(day, prev) {
+			continue
+		}
+		next := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, sec, 0, loc)
+		if !r.Until.IsZero() && next.After(r.Until) {
+			return time.Time{}
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+// matches 日付dayがルールに一致するか（anchorは間隔を数える基準となる前回の日時）
+func (r *Rule) matches(day, anchor time.Time) bool {
+	anchorDay := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+		return false
+	}
+
+	switch r.Freq {
+	case Daily:
+		days := int(day.Sub(anchorDay).Hours() / 24)
+		return days%r.Interval == 0 && r.matchesWeekday(day)
+	case Weekly:
+		weeks := int(weekStart(day).Sub(weekStart(anchorDay)).Hours() / 24 / 7)
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == anchor.Weekday()
+		}
+		return r.matchesWeekday(day)
+	case Monthly:
+		months := (day.Year()-anchorDay.Year())*12 + int(day.Month()-anchorDay.Month())
+		return months%r.Interval == 0 && r.matchesDayOfMonth(day, anchor)
+	case Yearly:
+		if (day.Year()-anchorDay.Year())%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonth) == 0 && day.Month() != anchor.Month() {
+			return false
+		}
+		return r.matchesDayOfMonth(day, anchor)
+	}
+	return false
+}
+
+// matchesWeekday BYDAYの曜日に一致するか（BYDAYがない場合は常に一致）
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDayOfMonth 月の中の日付がBYMONTHDAY・BYDAYに一致するか（どちらもない場合は前回と同じ日）
+func (r *Rule) matchesDayOfMonth(day, anchor time.Time) bool {
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		return day.Day() == anchor.Day()
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := daysIn(day)
+		matched := false
+		for _, d := range r.ByMonthDay {
+			if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.ByDay) > 0 {
+		// 第N週の指定は月の中で数える
+		nth := (day.Day()-1)/7 + 1
+		nthFromEnd := -((daysIn(day)-day.Day())/7 + 1)
+		for _, wd := range r.ByDay {
+			if wd.Weekday == day.Weekday() && (wd.N == 0 || wd.N == nth || wd.N == nthFromEnd) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// weekStart dayを含む週の月曜日
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// daysIn dayを含む月の日数
+func daysIn(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
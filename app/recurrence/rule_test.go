@@ -0,0 +1,111 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestRuleNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, tokyo)
+	}
+
+	tests := []struct {
+		rule string
+		from time.Time
+		want []time.Time
+	}{
+		// BYDAY
+		{"FREQ=WEEKLY;BYDAY=MO,WE,FR", date(2024, 1, 1), []time.Time{date(2024, 1, 3), date(2024, 1, 5), date(2024, 1, 8)}},
+		{"FREQ=WEEKLY", date(2024, 1, 3), []time.Time{date(2024, 1, 10), date(2024, 1, 17)}},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", date(2024, 1, 4), []time.Time{date(2024, 1, 5), date(2024, 1, 8)}},
+		{"FREQ=MONTHLY;BYDAY=2TU", date(2024, 1, 9), []time.Time{date(2024, 2, 13), date(2024, 3, 12)}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", date(2024, 1, 26), []time.Time{date(2024, 2, 23), date(2024, 3, 29)}},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", date(2023, 11, 23), []time.Time{date(2024, 11, 28), date(2025, 11, 27)}},
+
+		// BYMONTHDAY
+		{"FREQ=MONTHLY;BYMONTHDAY=15", date(2024, 1, 20), []time.Time{date(2024, 2, 15), date(2024, 3, 15)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", date(2024, 1, 1), []time.Time{date(2024, 1, 15), date(2024, 2, 1)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, 1, 31), []time.Time{date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31), []time.Time{date(2024, 3, 31), date(2024, 5, 31)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", date(2024, 1, 1), []time.Time{date(2024, 9, 13), date(2024, 12, 13)}},
+		{"FREQ=YEARLY", date(2024, 2, 29), []time.Time{date(2028, 2, 29)}},
+
+		// INTERVAL
+		{"FREQ=DAILY;INTERVAL=3", date(2024, 2, 27), []time.Time{date(2024, 3, 1), date(2024, 3, 4)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", date(2024, 1, 2), []time.Time{date(2024, 1, 4), date(2024, 1, 16), date(2024, 1, 18)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", date(2024, 1, 4), []time.Time{date(2024, 1, 15), date(2024, 1, 29)}},
+		{"FREQ=MONTHLY;INTERVAL=3", date(2024, 1, 15), []time.Time{date(2024, 4, 15), date(2024, 7, 15)}},
+		{"FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO", date(2024, 1, 1), []time.Time{date(2024, 3, 4), date(2024, 5, 6)}},
+		{"FREQ=YEARLY;INTERVAL=2;BYMONTH=3;BYMONTHDAY=1", date(2024, 3, 1), []time.Time{date(2026, 3, 1)}},
+
+		// UNTIL
+		{"FREQ=WEEKLY;UNTIL=20240110", date(2024, 1, 3), []time.Time{date(2024, 1, 10), {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prev := tt.from
+			for _, want := range tt.want {
+				got := r.Next(prev, tokyo)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", prev, got, want)
+				}
+				prev = got
+			}
+		})
+	}
+}
+
+// TestRuleNextDST 夏時間の切り替えをまたいでも同じ時刻になる
+func TestRuleNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prev := time.Date(2024, 3, 9, 9, 0, 0, 0, ny)
+	want := time.Date(2024, 3, 10, 9, 0, 0, 0, ny)
+	if got := r.Next(prev, ny); !got.Equal(want) || got.Sub(prev) != 23*time.Hour {
+		t.Errorf("Next(%s) = %s, want %s", prev, got, want)
+	}
+
+	// 日付はUTCではなくlocで数える（UTCでは11月3日だがニューヨークでは11月2日）
+	prev = time.Date(2024, 11, 3, 2, 0, 0, 0, time.UTC)
+	want = time.Date(2024, 11, 3, 22, 0, 0, 0, ny)
+	if got := r.Next(prev, ny); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", prev, got, want)
+	}
+}
+
+func TestParseRejectsInvalidRule(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;COUNT=5",
+		"FREQ=DAILY;UNTIL=2024-01-10",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("Parse(%q) = nil error, want error", rule)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"myapp/config"
+	"myapp/db"
+	"myapp/jobs"
+	"myapp/logging"
+	"myapp/service"
+	"myapp/webhook"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Result 再読み込みの結果
+type Result struct {
+	Applied         []string `json:"applied" doc:"反映した設定"`
+	RestartRequired []string `json:"restart_required" doc:"変更されたが反映に再起動が必要な設定"`
+}
+
+// Reloader 再起動不要な設定（ログ・レートリミット・CORS・フィーチャーフラグ）を再読み込みする
+type Reloader struct {
+	path           string
+	featureService service.FeatureService
+
+	mu sync.Mutex
+}
+
+// NewReloader 新しいReloaderを作成（pathは起動時に指定した設定ファイル）
+func NewReloader(path string, featureService service.FeatureService) *Reloader {
+	return &Reloader{
+		path:           path,
+		featureService: featureService,
+	}
+}
+
+// Reload 設定ファイル・環境変数とDBのフィーチャーフラグを再読み込みして反映
+// 検証エラーの場合は何も反映しない
+func (r *Reloader) Reload(ctx context.Context) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	old := config.Current()
+	result := &Result{
+		Applied:         []string{},
+		RestartRequired: []string{},
+	}
+
+	// ログ設定
+	logging.Setup(cfg.Log.Level, cfg.Log.Format)
+	result.Applied = append(result.Applied, "log")
+
+	// アクセスログ（リクエストごとに現在の設定を参照する）
+	result.Applied = append(result.Applied, "access_log")
+
+	// シャットダウン（シャットダウンの開始時に現在の設定を参照する）
+	result.Applied = append(result.Applied, "shutdown")
+
+	// レートリミット・CORS・セキュリティヘッダー・ボディサイズ上限・タイムアウト・圧縮・CSRF・IPフィルター・メンテナンスモード・サニタイズ・リプレイ防止（ミドルウェアはリクエストごとに現在の設定を参照する）
+	result.Applied = append(result.Applied, "rate_limit", "cors", "security_headers", "body_limit", "timeout", "compression", "csrf", "ip_filter", "admin", "maintenance", "sanitize", "replay", "path_normalize")
+
+	// フィーチャーフラグ
+	if err := r.featureService.LoadFeatures(ctx); err != nil {
+		return nil, fmt.Errorf("フィーチャーフラグの再読み込みに失敗しました: %w", err)
+	}
+	result.Applied = append(result.Applied, "features")
+
+	// Webhookの署名の形式（署名のたびに参照する）
+	webhook.SetLegacySignature(cfg.Webhook.LegacySignature)
+	result.Applied = append(result.Applied, "webhook.legacy_signature")
+
+	// 起動時にのみ反映される設定（パスの正規化はミドルウェアがリクエストごとに参照するため除く）
+	oldServer, newServer := old.Server, cfg.Server
+	oldServer.PathNormalize, newServer.PathNormalize = "", ""
+	if !reflect.DeepEqual(oldServer, newServer) {
+		result.RestartRequired = append(result.RestartRequired, "server")
+	}
+	// サーキットブレーカーのしきい値は接続を作り直さずに反映する
+	db.ConfigureBreaker(cfg.Database.Breaker)
+	result.Applied = append(result.Applied, "database.breaker")
+	oldDatabase, newDatabase := old.Database, cfg.Database
+	oldDatabase.Breaker, newDatabase.Breaker = db.BreakerConfig{}, db.BreakerConfig{}
+	if !reflect.DeepEqual(oldDatabase, newDatabase) {
+		result.RestartRequired = append(result.RestartRequired, "database")
+	}
+	if old.Startup != cfg.Startup {
+		result.RestartRequired = append(result.RestartRequired, "startup")
+	}
+	if old.Tracing != cfg.Tracing {
+		result.RestartRequired = append(result.RestartRequired, "tracing")
+	}
+	if old.PanicAlert != cfg.PanicAlert {
+		result.RestartRequired = append(result.RestartRequired, "panic_alert")
+	}
+	if !reflect.DeepEqual(old.LLM, cfg.LLM) {
+		result.RestartRequired = append(result.RestartRequired, "llm")
+	}
+	if old.Session.Enabled != cfg.Session.Enabled || old.Session.Store != cfg.Session.Store ||
+		old.Session.Secret != cfg.Session.Secret || old.Session.RedisAddr != cfg.Session.RedisAddr {
+		result.RestartRequired = append(result.RestartRequired, "session")
+	}
+	if old.Replay.NonceStore != cfg.Replay.NonceStore || old.Replay.RedisAddr != cfg.Replay.RedisAddr {
+		result.RestartRequired = append(result.RestartRequired, "replay.nonce_store")
+	}
+	if !reflect.DeepEqual(old.Events, cfg.Events) {
+		result.RestartRequired = append(result.RestartRequired, "events")
+	}
+	if !reflect.DeepEqual(old.Cache, cfg.Cache) {
+		result.RestartRequired = append(result.RestartRequired, "cache")
+	}
+	if old.Webhook.SigningSecret != cfg.Webhook.SigningSecret || old.Webhook.SecretRefreshInterval != cfg.Webhook.SecretRefreshInterval {
+		result.RestartRequired = append(result.RestartRequired, "webhook.signing_secret")
+	}
+	if old.Webhook.Timeout != cfg.Webhook.Timeout || old.Webhook.MaxAttempts != cfg.Webhook.MaxAttempts || old.Webhook.DisableAfter != cfg.Webhook.DisableAfter || old.Webhook.AllowPrivateNetworks != cfg.Webhook.AllowPrivateNetworks {
+		result.RestartRequired = append(result.RestartRequired, "webhook.delivery")
+	}
+	if !reflect.DeepEqual(old.Notify, cfg.Notify) {
+		result.RestartRequired = append(result.RestartRequired, "notify")
+	}
+	if !reflect.DeepEqual(old.Telegram, cfg.Telegram) {
+		result.RestartRequired = append(result.RestartRequired, "telegram")
+	}
+	if !reflect.DeepEqual(old.Calendar, cfg.Calendar) {
+		result.RestartRequired = append(result.RestartRequired, "calendar")
+	}
+	if !reflect.DeepEqual(old.MSTodo, cfg.MSTodo) {
+		result.RestartRequired = append(result.RestartRequired, "microsoft_todo")
+	}
+	if !reflect.DeepEqual(old.Scheduler, cfg.Scheduler) {
+		result.RestartRequired = append(result.RestartRequired, "scheduler")
+	}
+	if !reflect.DeepEqual(old.Recurrence, cfg.Recurrence) {
+		result.RestartRequired = append(result.RestartRequired, "recurrence")
+	}
+	if !reflect.DeepEqual(old.Escalation, cfg.Escalation) {
+		result.RestartRequired = append(result.RestartRequired, "escalation")
+	}
+	if !reflect.DeepEqual(old.Export, cfg.Export) {
+		result.RestartRequired = append(result.RestartRequired, "export")
+	}
+	if !reflect.DeepEqual(old.Stale, cfg.Stale) {
+		result.RestartRequired = append(result.RestartRequired, "stale")
+	}
+	if !reflect.DeepEqual(old.Queue, cfg.Queue) {
+		result.RestartRequired = append(result.RestartRequired, "queue")
+	}
+	if !reflect.DeepEqual(old.Bulk, cfg.Bulk) {
+		result.RestartRequired = append(result.RestartRequired, "bulk")
+	}
+	if !reflect.DeepEqual(old.GitHub, cfg.GitHub) {
+		result.RestartRequired = append(result.RestartRequired, "github")
+	}
+	if !reflect.DeepEqual(old.Jira, cfg.Jira) {
+		result.RestartRequired = append(result.RestartRequired, "jira")
+	}
+	if !reflect.DeepEqual(old.Notion, cfg.Notion) {
+		result.RestartRequired = append(result.RestartRequired, "notion")
+	}
+	if !reflect.DeepEqual(old.Storage, cfg.Storage) {
+		result.RestartRequired = append(result.RestartRequired, "storage")
+	}
+	if !reflect.DeepEqual(old.IMAP, cfg.IMAP) {
+		result.RestartRequired = append(result.RestartRequired, "imap")
+	}
+	if !reflect.DeepEqual(old.QuickAdd, cfg.QuickAdd) {
+		result.RestartRequired = append(result.RestartRequired, "quick_add")
+	}
+	if !reflect.DeepEqual(old.Zapier, cfg.Zapier) {
+		result.RestartRequired = append(result.RestartRequired, "zapier")
+	}
+	if !reflect.DeepEqual(old.IFTTT, cfg.IFTTT) {
+		result.RestartRequired = append(result.RestartRequired, "ifttt")
+	}
+	if !reflect.DeepEqual(old.CalDAV, cfg.CalDAV) {
+		result.RestartRequired = append(result.RestartRequired, "caldav")
+	}
+	if !reflect.DeepEqual(old.Validation, cfg.Validation) {
+		result.RestartRequired = append(result.RestartRequired, "validation")
+	}
+
+	config.SetCurrent(cfg)
+
+	slog.InfoContext(ctx, "設定を再読み込みしました", "applied", result.Applied, "restart_required", result.RestartRequired)
+	return result, nil
+}
+
+// WatchSignal SIGHUPを受信するたびに再読み込みする（ctxがキャンセルされるまでブロック）
+func (r *Reloader) WatchSignal(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-hup:
+			slog.Info("SIGHUPを受信しました。設定を再読み込みします")
+			if _, err := r.Reload(context.Background()); err != nil {
+				slog.Error("設定の再読み込みに失敗しました（現在の設定を維持します）", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RefreshSecrets intervalごとに再読み込みし、ローテーションされたシークレットを取得し直す（ctxがキャンセルされるまでブロック）
+func (r *Reloader) RefreshSecrets(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("secrets-refresh", "シークレットの再取得（ローテーションへの追従）", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, func(ctx context.Context) error {
+				_, err := r.Reload(ctx)
+				return err
+			})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cleanupInterval 期限切れのnonceを破棄する間隔
+const cleanupInterval = time.Minute
+
+// MemoryStore プロセス内に使用済みnonceを保存するストア（単一インスタンス向け）
+type MemoryStore struct {
+	mu          sync.Mutex
+	nonces      map[string]time.Time
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// NewMemoryStore 新しいインメモリストアを作成
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nonces:      make(map[string]time.Time),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Use nonceを使用済みとして記録（期限内に記録済みの場合はfalse）
+func (s *MemoryStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) > cleanupInterval {
+		s.cleanup(now)
+	}
+
+	if expiresAt, ok := s.nonces[nonce]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// cleanup 期限切れのnonceを破棄
+func (s *MemoryStore) cleanup(now time.Time) {
+	for nonce, expiresAt := range s.nonces {
+		if !now.Before(expiresAt) {
+			delete(s.nonces, nonce)
+		}
+	}
+	s.lastCleanup = now
+}
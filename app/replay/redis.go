@@ -0,0 +1,42 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore Redisに使用済みnonceを保存するストア（複数インスタンスでリプレイ検出を共有）
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 新しいRedisストアを作成
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+		}),
+		prefix: "nonce:",
+	}
+}
+
+// Use SET NX でnonceを記録（既にキーが存在する場合はfalse）
+func (s *RedisStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, s.prefix+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("Redisへのnonceの記録に失敗しました: %w", err)
+	}
+	return ok, nil
+}
+
+// Close Redis接続を閉じる
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
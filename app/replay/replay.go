@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"myapp/config"
+	"myapp/i18n"
+	"myapp/webhook"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// nonceの長さの制限（短すぎるものは衝突しやすく、長すぎるものはストアを圧迫する）
+const (
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+// NonceStore 使用済みnonceを一定期間保存するストア
+type NonceStore interface {
+	// Use nonceを使用済みとして記録する。初めて使われた場合はtrue、既に使用済みの場合はfalse
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// Middleware 署名付きリクエストの署名・タイムスタンプ・nonceを検証し、リプレイされたリクエストを拒否するミドルウェア
+// 対象は設定の paths に先頭一致するパスのみ。署名の形式は Outgoing Webhook と同じ（nonceを含むv2のみ受け付ける。webhook.VerifyNonce）
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(store NonceStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := config.Current().Replay
+			if !cfg.Enabled || !matchPath(cfg.Paths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body []byte
+			if r.Body != nil {
+				var err error
+				body, err = io.ReadAll(r.Body)
+				r.Body.Close()
+				if err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						writeError(w, huma.NewError(http.StatusRequestEntityTooLarge, i18n.T(r.Context(), "リクエストボディが大きすぎます")))
+						return
+					}
+					writeError(w, huma.Error400BadRequest(i18n.T(r.Context(), "リクエストボディを読み込めません")))
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			if err := verify(cfg, r.Header, body, time.Now()); err != nil {
+				slog.WarnContext(r.Context(), "署名付きリクエストの検証に失敗しました", "path", r.URL.Path, "error", err)
+				writeError(w, huma.Error401Unauthorized(i18n.T(r.Context(), err.Error())))
+				return
+			}
+
+			// 署名の検証後にnonceを記録する（シークレットを持たない第三者がストアを埋められないように）
+			// タイムスタンプは前後 tolerance まで受け付けるため、その間はnonceを保持する
+			fresh, err := store.Use(r.Context(), r.Header.Get(webhook.NonceHeader), 2*cfg.Tolerance)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "nonceの記録に失敗しました", "error", err)
+				writeError(w, huma.Error503ServiceUnavailable(i18n.T(r.Context(), "リクエストを検証できません")))
+				return
+			}
+			if !fresh {
+				slog.WarnContext(r.Context(), "リプレイされたリクエストを拒否しました", "path", r.URL.Path)
+				writeError(w, huma.Error401Unauthorized(i18n.T(r.Context(), "nonceは既に使用されています")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verify nonceの形式と、いずれかのシークレットでの署名・タイムスタンプを検証
+func verify(cfg config.ReplayConfig, header http.Header, body []byte, now time.Time) error {
+	nonce := header.Get(webhook.NonceHeader)
+	if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return errors.New("nonceがないか、長さが不正です")
+	}
+
+	err := webhook.ErrInvalidSignature
+	for _, secret := range cfg.Secrets {
+		if err = webhook.VerifyNonce(secret, header, body, now, cfg.Tolerance); err == nil {
+			return nil
+		}
+		if !errors.Is(err, webhook.ErrInvalidSignature) {
+			return err
+		}
+	}
+	return err
+}
+
+// matchPath パスが対象のいずれかに先頭一致するか
+func matchPath(prefixes []string, path string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError エラーをproblem+json形式で返す
+func writeError(w http.ResponseWriter, err huma.StatusError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(err.GetStatus())
+	json.NewEncoder(w).Encode(err)
+}
@@ -0,0 +1,55 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"myapp/logging"
+	"net/http"
+	"regexp"
+)
+
+// Header リクエストIDを受け渡すHTTPヘッダー名
+const Header = "X-Request-ID"
+
+// validID クライアントから受理するリクエストIDの形式（ログ汚染を防ぐため制限）
+var validID = regexp.MustCompile(`^[A-Za-z0-9\-_.:]{1,128}$`)
+
+type contextKey struct{}
+
+// Middleware X-Request-IDを受理または生成し、レスポンスヘッダー・コンテキスト・ログに付与するミドルウェア
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !validID.MatchString(id) {
+			id = generate()
+		}
+
+		w.Header().Set(Header, id)
+
+		ctx := context.WithValue(r.Context(), contextKey{}, id)
+		ctx = logging.WithAttrs(ctx, slog.String("request_id", id))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromContext コンテキストからリクエストIDを取得（存在しなければ空文字）
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// NewContext リクエストIDを設定したコンテキストを返す（バックグラウンド処理への引き継ぎ用）
+func NewContext(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, id)
+	return logging.WithAttrs(ctx, slog.String("request_id", id))
+}
+
+// generate ランダムなリクエストIDを生成
+func generate() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
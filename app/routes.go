@@ -0,0 +1,1015 @@
+package main
+
+import (
+	"encoding/json"
+	"myapp/adminauth"
+	"myapp/feature"
+	"myapp/handler"
+	"myapp/version"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+)
+
+// apiHandlers HumaのAPIに登録するハンドラー
+type apiHandlers struct {
+	healthDetail    *handler.HumaHealthHandler
+	todo            *handler.HumaTodoHandler
+	stale           *handler.HumaStaleHandler
+	profile         *handler.HumaProfileHandler
+	bulk            *handler.HumaBulkHandler
+	imports         *handler.HumaImportHandler
+	admin           *handler.HumaAdminHandler
+	feature         *handler.HumaFeatureHandler
+	maintenance     *handler.HumaMaintenanceHandler
+	reload          *handler.HumaReloadHandler
+	diagnostics     *handler.HumaDiagnosticsHandler
+	jobs            *handler.HumaJobsHandler
+	queue           *handler.HumaQueueHandler
+	webhook         *handler.HumaWebhookHandler
+	webhookEndpoint *handler.HumaWebhookEndpointHandler
+	escalation      *handler.HumaEscalationHandler
+	reminder        *handler.HumaReminderHandler
+	snapshot        *handler.HumaSnapshotHandler
+
+	// 設定で有効な場合のみ登録する機能（nilの場合はエンドポイントを登録しない）
+	digest   *handler.HumaDigestHandler
+	calendar *handler.HumaCalendarHandler
+	mstodo   *handler.HumaMSTodoHandler
+	github   *handler.HumaGitHubHandler
+	jira     *handler.HumaJiraHandler
+	push     *handler.HumaPushHandler
+	notion   *handler.HumaNotionHandler
+	zapier   *handler.HumaZapierHandler
+	// githubWebhook GitHubのWebhookを受け付けるか（GITHUB_WEBHOOK_SECRET設定時のみ）
+	githubWebhook bool
+}
+
+// newAPIConfig HumaのAPIの設定
+func newAPIConfig() huma.Config {
+	config := huma.DefaultConfig("Todo API", version.Version)
+	config.Info.Description = "Go製のTodo管理API"
+	config.Info.Contact = &huma.Contact{Name: "API Support"}
+
+	// 管理APIのトークン（ADMIN_TOKEN）
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		adminauth.SecurityScheme: {Type: "http", Scheme: "bearer", Description: "管理API（/api/v1/admin）に要求するトークン（ADMIN_TOKEN）"},
+	}
+
+	// エラーレスポンスにリクエストIDを含める（メッセージはAccept-Languageの言語に翻訳する）
+	huma.NewError = handler.NewAPIError
+	config.Transformers = append(config.Transformers, handler.ErrorTransformer, handler.MessageTransformer)
+	return config
+}
+
+// registerRoutes HumaのAPIにエンドポイントを登録する（OpenAPIのスペックは登録したエンドポイントから生成される）
+func registerRoutes(api huma.API, h *apiHandlers) {
+	// ヘルスチェックエンドポイント
+	huma.Register(api, huma.Operation{
+		OperationID: "get-health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+		Summary:     "アプリケーションヘルスチェック",
+		Tags:        []string{"health"},
+	}, healthHandler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-home",
+		Method:      http.MethodGet,
+		Path:        "/",
+		Summary:     "ホームページ",
+		Tags:        []string{"health"},
+	}, homeHandler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-db-health",
+		Method:      http.MethodGet,
+		Path:        "/health/db",
+		Summary:     "データベースヘルスチェック",
+		Tags:        []string{"health"},
+	}, dbHealthHandler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-health-detail",
+		Method:      http.MethodGet,
+		Path:        "/health/detail",
+		Summary:     "依存サービスを含む詳細ヘルスチェック",
+		Description: "依存サービスごとの状態とレイテンシを返す。重要な依存先が異常な場合は503",
+		Tags:        []string{"health"},
+	}, feature.Guard(feature.FlagHealthDetail, h.healthDetail.GetDetailedHealth))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-version",
+		Method:      http.MethodGet,
+		Path:        "/version",
+		Summary:     "バージョン情報",
+		Description: "ビルド時に埋め込んだバージョン・コミットハッシュ・ビルド日時とGoのバージョンを返す",
+		Tags:        []string{"health"},
+	}, versionHandler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-livez",
+		Method:      http.MethodGet,
+		Path:        "/livez",
+		Summary:     "livenessプローブ",
+		Description: "プロセスの生存のみを確認する",
+		Tags:        []string{"health"},
+	}, h.healthDetail.GetLiveness)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-readyz",
+		Method:      http.MethodGet,
+		Path:        "/readyz",
+		Summary:     "readinessプローブ",
+		Description: "DB・マイグレーション完了・依存サービスを確認する。シャットダウン開始後は503",
+		Tags:        []string{"health"},
+	}, h.healthDetail.GetReadiness)
+
+	// Todo API エンドポイント
+	huma.Register(api, huma.Operation{
+		OperationID: "list-todos",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/todos",
+		Summary:     "全てのTodoを取得",
+		Description: "優先度や完了状況でフィルタリング可能。並び順はプロファイルの default_sort",
+		Tags:        []string{"todos"},
+	}, h.todo.GetAllTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-todo",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/todos",
+		Summary:       "新しいTodoを作成",
+		Tags:          []string{"todos"},
+		DefaultStatus: 201,
+	}, h.todo.CreateTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-stale-todos",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/todos/stale",
+		Summary:     "放置タスクを取得",
+		Description: "長期間（days、省略時は STALE_AFTER）更新されていない未完了のTodoを更新が古い順に返す。検出ジョブで「レビューが必要」になったTodoは needs_review=true",
+		Tags:        []string{"todos"},
+	}, h.stale.ListStaleTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-profile",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/profile",
+		Summary:     "プロファイルを取得",
+		Description: "インスタンス全体の設定（ユーザーごとではない）として、タイムゾーン（「今日」「期限切れ」の判定・リマインダー・ダイジェストに使う）と、未設定の場合に実際に使うタイムゾーン、一覧の既定の並び順を返す",
+		Tags:        []string{"profile"},
+	}, h.profile.GetProfile)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-profile",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/profile",
+		Summary:     "プロファイルを更新",
+		Description: "インスタンス全体のタイムゾーン（IANAの名前）と一覧の既定の並び順を設定する（全ての利用者・ジョブに適用される）。タイムゾーンが空の場合はスケジューラーのタイムゾーン（SCHEDULER_TIMEZONE）を使い、default_sort を省略した場合は変更しない",
+		Tags:        []string{"profile"},
+	}, h.profile.UpdateProfile)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "export-todos",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/todos/export",
+		Summary:     "Todoをストリーミングでエクスポート",
+		Description: "絞り込みの条件に一致するTodoをID順にJSON Lines（1行に1件）またはCSVで返す。全件をメモリに載せず、500件ずつ読みながら逐次送信する",
+		Tags:        []string{"todos"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "TodoのJSON Lines・CSV",
+				Content: map[string]*huma.MediaType{
+					"application/x-ndjson": {},
+					"text/csv":             {},
+				},
+			},
+		},
+	}, h.todo.ExportTodos)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-todo",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/todos/{id}",
+		Summary:     "特定のTodoを取得",
+		Tags:        []string{"todos"},
+	}, h.todo.GetTodoByID)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-todo",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/todos/{id}",
+		Summary:     "Todoを更新",
+		Tags:        []string{"todos"},
+	}, h.todo.UpdateTodo)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-todo",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/todos/{id}",
+		Summary:     "Todoを削除",
+		Tags:        []string{"todos"},
+	}, h.todo.DeleteTodo)
+
+	// Todoの一括操作
+	huma.Register(api, huma.Operation{
+		OperationID:   "start-bulk",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/todos/bulk",
+		Summary:       "Todoを一括で更新・削除",
+		Description:   "指定したIDのTodoをワーカープールで並列に更新・削除する。処理はバックグラウンドで行い、進捗は GET /api/v1/todos/bulk/{id} で確認する",
+		Tags:          []string{"todos"},
+		DefaultStatus: 202,
+	}, h.bulk.StartBulk)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-bulk",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/todos/bulk/create",
+		Summary:       "Todoを一括で作成",
+		Description:   "複数のTodoを1つのトランザクションで BULK_INSERT_BATCH_SIZE 件ずつまとめてINSERTする。1件でも不正な場合は作成しない（422）",
+		Tags:          []string{"todos"},
+		DefaultStatus: 201,
+		MaxBodyBytes:  importMaxBodyBytes,
+	}, h.bulk.CreateBulk)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-bulk",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/todos/bulk/{id}",
+		Summary:     "一括操作の進捗を取得",
+		Description: "処理済み・成功・失敗の件数と、失敗の内容を返す",
+		Tags:        []string{"todos"},
+	}, h.bulk.GetBulk)
+
+	// 外部サービスからの取り込み
+	huma.Register(api, huma.Operation{
+		OperationID:   "import-todoist",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/imports/todoist",
+		Summary:       "Todoistから取り込み",
+		Description:   "APIで取得したタスク・プロジェクトのJSONまたはプロジェクトのCSVエクスポートからTodoを作成する。取り込みはバックグラウンドで行い、進捗は GET /api/v1/imports/{id} で確認する",
+		Tags:          []string{"imports"},
+		DefaultStatus: 202,
+		MaxBodyBytes:  importMaxBodyBytes,
+	}, h.imports.ImportTodoist)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "import-trello",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/imports/trello",
+		Summary:       "Trelloから取り込み",
+		Description:   "ボードのJSONエクスポートからTodoを作成する（リスト→プロジェクト、カード→Todo、チェックリスト→サブタスク）。進捗は GET /api/v1/imports/{id} で確認する",
+		Tags:          []string{"imports"},
+		DefaultStatus: 202,
+		MaxBodyBytes:  importMaxBodyBytes,
+	}, h.imports.ImportTrello)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-import",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/imports/{id}",
+		Summary:     "取り込みの進捗を取得",
+		Description: "処理済み・作成・重複によるスキップ・失敗の件数と、失敗・警告の内容を返す",
+		Tags:        []string{"imports"},
+	}, h.imports.GetImport)
+
+	// CSRFトークン
+	huma.Register(api, huma.Operation{
+		OperationID: "get-csrf-token",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/csrf-token",
+		Summary:     "CSRFトークンを取得",
+		Description: "Cookie認証で安全でないメソッドを呼び出す際にヘッダーへ設定するトークンを返す",
+		Tags:        []string{"security"},
+	}, handler.GetCSRFToken)
+
+	// 管理 API エンドポイント
+	huma.Register(api, huma.Operation{
+		OperationID: "get-db-stats",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/db/stats",
+		Summary:     "データベース統計を取得",
+		Description: "テーブル行数・デッドタプル・プール使用状況・最長クエリを返す",
+		Tags:        []string{"admin"},
+	}, feature.Guard(feature.FlagAdminDBStats, h.admin.GetDBStats))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-migrations",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/migrations",
+		Summary:     "マイグレーションの適用状況を取得",
+		Description: "dry_run=trueで未適用マイグレーションのSQLを適用せずに返す",
+		Tags:        []string{"admin"},
+	}, h.admin.GetMigrations)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-features",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/features",
+		Summary:     "フィーチャーフラグ一覧を取得",
+		Tags:        []string{"admin"},
+	}, h.feature.GetFeatures)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-feature",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/admin/features/{name}",
+		Summary:     "フィーチャーフラグを切り替え",
+		Description: "変更はDBに保存され、再起動後も維持される",
+		Tags:        []string{"admin"},
+	}, h.feature.UpdateFeature)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-maintenance",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/maintenance",
+		Summary:     "メンテナンスモードの状態を取得",
+		Tags:        []string{"admin"},
+	}, h.maintenance.GetMaintenance)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-maintenance",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/admin/maintenance",
+		Summary:     "メンテナンスモードを切り替え",
+		Description: "有効中は /api/ 配下の書き込み（allow_reads=falseの場合は全リクエスト）を503（Retry-After付き）で拒否する。管理APIは対象外",
+		Tags:        []string{"admin"},
+	}, h.maintenance.UpdateMaintenance)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-diagnostics",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/diagnostics",
+		Summary:     "セルフ診断",
+		Description: "設定の妥当性・依存サービスの接続・ディスク/メモリ状況・稼働中のワーカーを確認し、問題点を列挙する",
+		Tags:        []string{"admin"},
+	}, h.diagnostics.GetDiagnostics)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-jobs",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/jobs",
+		Summary:     "ジョブ/ワーカーの稼働状況を取得",
+		Description: "ジョブごとの状態・直近の実行結果・失敗件数と、稼働中のバックグラウンドワーカーを返す",
+		Tags:        []string{"admin"},
+	}, h.jobs.GetJobs)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "run-job",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/admin/jobs/{name}/run",
+		Summary:       "ジョブを即時実行",
+		Description:   "スケジューラーのジョブを予定時刻を待たずにバックグラウンドで実行し、実行履歴を返す。実行中（別のインスタンスでの実行を含む）の場合は409",
+		Tags:          []string{"admin"},
+		DefaultStatus: 202,
+	}, h.jobs.RunJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-job-runs",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/jobs/runs",
+		Summary:     "ジョブの実行履歴を取得",
+		Description: "スケジューラーのジョブの実行履歴（予定時刻・即時実行の別、実行したインスタンス、所要時間、エラー）を新しい順に返す",
+		Tags:        []string{"admin"},
+	}, h.jobs.ListRuns)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-job-run",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/jobs/runs/{id}",
+		Summary:     "ジョブの実行履歴を取得",
+		Description: "IDを指定してジョブの実行履歴を返す（即時実行の完了の確認に使う）",
+		Tags:        []string{"admin"},
+	}, h.jobs.GetRun)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-reminder-deliveries",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/reminders",
+		Summary:     "リマインダーの配信状態を取得",
+		Description: "期限間近・期限切れのリマインダーの送信先ごとの配信状態（配信済み・失敗・対象外）と、再通知・再試行の予定日時を返す",
+		Tags:        []string{"admin"},
+	}, h.reminder.ListDeliveries)
+
+	if h.digest != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "list-digest-subscriptions",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/digests",
+			Summary:     "ダイジェストメールの配信設定の一覧を取得",
+			Description: "送信先ごとの頻度・時刻・タイムゾーンと、次に送る日時・直近の送信結果を返す（EMAIL_NOTIFY_ENABLED=true の場合のみ）",
+			Tags:        []string{"admin"},
+		}, h.digest.ListSubscriptions)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "create-digest-subscription",
+			Method:        http.MethodPost,
+			Path:          "/api/v1/admin/digests",
+			Summary:       "ダイジェストメールの配信設定を登録",
+			Description:   "送信先のメールアドレスごとに、毎日または毎週（曜日を指定）の送る時刻を登録する。同じメールアドレスは409",
+			Tags:          []string{"admin"},
+			DefaultStatus: http.StatusCreated,
+		}, h.digest.CreateSubscription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "get-digest-subscription",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/digests/{id}",
+			Summary:     "ダイジェストメールの配信設定を取得",
+			Tags:        []string{"admin"},
+		}, h.digest.GetSubscription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "update-digest-subscription",
+			Method:      http.MethodPut,
+			Path:        "/api/v1/admin/digests/{id}",
+			Summary:     "ダイジェストメールの配信設定を更新",
+			Description: "設定を置き換え、次に送る日時を計算し直す（enabled=false で配信を停止）",
+			Tags:        []string{"admin"},
+		}, h.digest.UpdateSubscription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-digest-subscription",
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/admin/digests/{id}",
+			Summary:     "ダイジェストメールの配信設定を削除",
+			Tags:        []string{"admin"},
+		}, h.digest.DeleteSubscription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "send-digest-now",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/digests/{id}/send",
+			Summary:     "ダイジェストメールをすぐに送信",
+			Description: "設定の確認用に、予定に関係なくダイジェストを送信する（次に送る日時は変わらない）",
+			Tags:        []string{"admin"},
+		}, h.digest.SendNow)
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-escalation-rules",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/escalation-rules",
+		Summary:     "エスカレーションルールの一覧を取得",
+		Tags:        []string{"admin"},
+	}, h.escalation.ListRules)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-escalation-rule",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/admin/escalation-rules",
+		Summary:       "エスカレーションルールを登録",
+		Description:   "期限を指定した分数過ぎた未完了のTodoについて、優先度の引き上げ・メールアドレスやチャンネルへの通知を行うルールを登録する。通知先のチャンネルは設定で有効にしたもののみ",
+		Tags:          []string{"admin"},
+		DefaultStatus: http.StatusCreated,
+	}, h.escalation.CreateRule)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-escalation-rule",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/escalation-rules/{id}",
+		Summary:     "エスカレーションルールを取得",
+		Tags:        []string{"admin"},
+	}, h.escalation.GetRule)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-escalation-rule",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/admin/escalation-rules/{id}",
+		Summary:     "エスカレーションルールを更新",
+		Description: "ルールを置き換える（適用済みのTodoには期限が変わるまで改めて適用しない。enabled=false で適用を停止）",
+		Tags:        []string{"admin"},
+	}, h.escalation.UpdateRule)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-escalation-rule",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/admin/escalation-rules/{id}",
+		Summary:     "エスカレーションルールを削除",
+		Description: "ルールと適用の記録を削除する",
+		Tags:        []string{"admin"},
+	}, h.escalation.DeleteRule)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-escalation-logs",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/escalations",
+		Summary:     "エスカレーションの記録を取得",
+		Description: "ルールを適用したTodo・引き上げた優先度・通知した宛先と通知の失敗理由を新しい順に返す",
+		Tags:        []string{"admin"},
+	}, h.escalation.ListLogs)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-snapshots",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/snapshots",
+		Summary:     "エクスポートのスナップショットの一覧を取得",
+		Description: "ストレージに保存した全データのスナップショットを新しい順に返す（作成途中・失敗したスナップショットは含まない）",
+		Tags:        []string{"admin"},
+	}, h.snapshot.ListSnapshots)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-snapshot",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/admin/snapshots",
+		Summary:       "スナップショットをすぐに作成",
+		Description:   "EXPORT_FORMATS の形式で全てのTodoをストレージへエクスポートする（EXPORT_KEEP を超えた古いスナップショットは削除）。同じ秒に作成済みの場合は409",
+		Tags:          []string{"admin"},
+		DefaultStatus: http.StatusCreated,
+	}, h.snapshot.CreateSnapshot)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-snapshot",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/snapshots/{id}",
+		Summary:     "スナップショットを取得",
+		Tags:        []string{"admin"},
+	}, h.snapshot.GetSnapshot)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "download-snapshot",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/snapshots/{id}/download",
+		Summary:     "スナップショットをダウンロード",
+		Description: "スナップショットのファイルを指定した形式（json / csv）で返す",
+		Tags:        []string{"admin"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "スナップショットのファイル",
+				Content: map[string]*huma.MediaType{
+					"application/json": {},
+					"text/csv":         {},
+				},
+			},
+		},
+	}, h.snapshot.DownloadSnapshot)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-snapshot",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/admin/snapshots/{id}",
+		Summary:     "スナップショットを削除",
+		Tags:        []string{"admin"},
+	}, h.snapshot.DeleteSnapshot)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-queue-jobs",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/queue/jobs",
+		Summary:     "ジョブキューのジョブを取得",
+		Description: "Webhook・メールの送信、LLMの処理のジョブを新しい順に返す。status=dead でデッドレターを確認できる",
+		Tags:        []string{"admin"},
+	}, h.queue.ListJobs)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-queue-stats",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/queue/stats",
+		Summary:     "ジョブキューの件数を取得",
+		Description: "ジョブの種類・状態ごとの件数を返す",
+		Tags:        []string{"admin"},
+	}, h.queue.GetStats)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-queue-job",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/queue/jobs/{id}",
+		Summary:     "ジョブキューのジョブを取得",
+		Description: "ジョブの内容・試行回数・直近の失敗理由を返す",
+		Tags:        []string{"admin"},
+	}, h.queue.GetJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "retry-queue-job",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/queue/jobs/{id}/retry",
+		Summary:     "ジョブを再実行",
+		Description: "デッドレター・成功済みのジョブを試行回数を0に戻して実行待ちにする。実行待ち・実行中のジョブは409",
+		Tags:        []string{"admin"},
+	}, h.queue.RetryJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "retry-dead-queue-jobs",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/queue/dead/retry",
+		Summary:     "デッドレターのジョブをまとめて再実行",
+		Description: "送信先の障害が解消した後などに、デッドレターのジョブ（kind指定時はその種類のみ）を実行待ちに戻す",
+		Tags:        []string{"admin"},
+	}, h.queue.RetryDead)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-queue-job",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/admin/queue/jobs/{id}",
+		Summary:     "ジョブを削除",
+		Description: "再実行しないデッドレター等を削除する。実行中のジョブは409",
+		Tags:        []string{"admin"},
+	}, h.queue.DeleteJob)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "reload-config",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/reload",
+		Summary:     "設定を再読み込み",
+		Description: "ログレベル・レートリミット・CORS・フィーチャーフラグを再起動せずに反映する（SIGHUPと同じ）。ポート・DB等の変更は再起動が必要",
+		Tags:        []string{"admin"},
+	}, h.reload.Reload)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "rotate-webhook-secret",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/webhooks/secret/rotate",
+		Summary:     "Webhookの署名シークレットをローテーション",
+		Description: "新しいシークレットを発行する。旧シークレットでも猶予期間が終わるまで署名を続けるため、受信側は順次切り替えられる",
+		Tags:        []string{"admin"},
+	}, h.webhook.RotateSecret)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-webhook-endpoints",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/webhooks/endpoints",
+		Summary:     "Webhookの送信先の一覧を取得",
+		Description: "自動で停止した送信先は enabled=false と停止の理由（disabled_reason）を返す",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.ListEndpoints)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-webhook-endpoint",
+		Method:        http.MethodPost,
+		Path:          "/api/v1/admin/webhooks/endpoints",
+		Summary:       "Webhookの送信先を登録",
+		Description:   "Todoの作成・更新・削除のイベントをCloudEvents形式の署名付きPOSTで配信する送信先を登録する。配信はジョブキューで行い、失敗した場合は指数バックオフで再試行する",
+		Tags:          []string{"admin"},
+		DefaultStatus: http.StatusCreated,
+	}, h.webhookEndpoint.CreateEndpoint)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-webhook-endpoint",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/webhooks/endpoints/{id}",
+		Summary:     "Webhookの送信先を取得",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.GetEndpoint)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-webhook-endpoint",
+		Method:      http.MethodPut,
+		Path:        "/api/v1/admin/webhooks/endpoints/{id}",
+		Summary:     "Webhookの送信先を更新",
+		Description: "送信先を置き換える。停止中の送信先を enabled=true で更新すると、連続失敗回数を戻して配信を再開する",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.UpdateEndpoint)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-webhook-endpoint",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/admin/webhooks/endpoints/{id}",
+		Summary:     "Webhookの送信先を削除",
+		Description: "送信先と配信の履歴を削除する",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.DeleteEndpoint)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-webhook-deliveries",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/webhooks/deliveries",
+		Summary:     "Webhookの配信の履歴を取得",
+		Description: "配信ごとの状態・試行回数・直近のステータスと失敗理由を新しい順に返す",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.ListDeliveries)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-webhook-delivery",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/webhooks/deliveries/{id}",
+		Summary:     "Webhookの配信を取得",
+		Description: "送信した本文（payload）を含めて返す",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.GetDelivery)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "redeliver-webhook-delivery",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/webhooks/deliveries/{id}/redeliver",
+		Summary:     "Webhookを再配信",
+		Description: "失敗・取り消した配信を同じ内容（同じ X-Webhook-Delivery）でもう一度ジョブキューに積む",
+		Tags:        []string{"admin"},
+	}, h.webhookEndpoint.Redeliver)
+
+	if h.calendar != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "get-calendar-status",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/integrations/google-calendar",
+			Summary:     "Googleカレンダー連携の状態を取得",
+			Description: "連携済みか・直近の同期日時・予定と紐付いているTodoの件数を返す",
+			Tags:        []string{"admin"},
+		}, h.calendar.GetStatus)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "authorize-calendar",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/google-calendar/authorize",
+			Summary:     "Googleカレンダー連携を開始",
+			Description: "Googleの同意画面のURLを返す。ブラウザで開いて許可するとコールバックでトークンを保存する",
+			Tags:        []string{"admin"},
+		}, h.calendar.Authorize)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "calendar-oauth-callback",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/integrations/google-calendar/callback",
+			Summary:     "Googleの同意画面からのコールバック",
+			Description: "認可コードをトークンに交換して保存する（GOOGLE_REDIRECT_URLに指定するURL）",
+			Tags:        []string{"admin"},
+		}, h.calendar.Callback)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "sync-calendar",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/google-calendar/sync",
+			Summary:     "Googleカレンダーと今すぐ同期",
+			Description: "期限付きTodoの変更を予定に反映し、カレンダー側の日時変更・削除を取り込む",
+			Tags:        []string{"admin"},
+		}, h.calendar.Sync)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "disconnect-calendar",
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/admin/integrations/google-calendar",
+			Summary:     "Googleカレンダー連携を解除",
+			Description: "保存したトークンと同期状態を削除する（カレンダーの予定は削除しない）",
+			Tags:        []string{"admin"},
+		}, h.calendar.Disconnect)
+	}
+
+	if h.mstodo != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "get-mstodo-status",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/integrations/microsoft-todo",
+			Summary:     "Microsoft To Do連携の状態を取得",
+			Tags:        []string{"admin"},
+		}, h.mstodo.GetStatus)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "authorize-mstodo",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/microsoft-todo/authorize",
+			Summary:     "Microsoft To Do連携を開始",
+			Description: "Microsoftの同意画面のURLを返す。ブラウザで開いて許可するとコールバックでトークンを保存する",
+			Tags:        []string{"admin"},
+		}, h.mstodo.Authorize)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "mstodo-oauth-callback",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/integrations/microsoft-todo/callback",
+			Summary:     "Microsoftの同意画面からのコールバック",
+			Description: "認可コードをトークンに交換して保存する（MICROSOFT_REDIRECT_URLに指定するURL）",
+			Tags:        []string{"admin"},
+		}, h.mstodo.Callback)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-mstodo-lists",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/integrations/microsoft-todo/lists",
+			Summary:     "Microsoft To Doのリスト一覧を取得",
+			Description: "リストごとに取り込み先のプロジェクト名（MICROSOFT_TODO_LIST_PROJECTS の対応、なければリスト名）を返す",
+			Tags:        []string{"admin"},
+		}, h.mstodo.GetLists)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "disconnect-mstodo",
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/admin/integrations/microsoft-todo",
+			Summary:     "Microsoft To Do連携を解除",
+			Description: "保存したトークンを削除する（取り込み済みのTodoは削除しない）",
+			Tags:        []string{"admin"},
+		}, h.mstodo.Disconnect)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "import-mstodo",
+			Method:        http.MethodPost,
+			Path:          "/api/v1/imports/microsoft-todo",
+			Summary:       "Microsoft To Doから取り込み",
+			Description:   "連携したアカウントのリストのタスクをMicrosoft Graph APIで取得してTodoを作成する（リスト→プロジェクト、チェックリスト→サブタスク）。進捗は GET /api/v1/imports/{id} で確認する",
+			Tags:          []string{"imports"},
+			DefaultStatus: 202,
+		}, h.mstodo.Import)
+	}
+
+	if h.github != nil {
+		if h.githubWebhook {
+			huma.Register(api, huma.Operation{
+				OperationID: "github-webhook",
+				Method:      http.MethodPost,
+				Path:        "/api/v1/integrations/github/webhook",
+				Summary:     "GitHubのWebhookを受信",
+				Description: "issuesイベントをTodoに反映する（作成・タイトル/本文/ラベルの変更・オープン/クローズ）。X-Hub-Signature-256を検証する",
+				Tags:        []string{"integrations"},
+			}, h.github.Webhook)
+		}
+
+		huma.Register(api, huma.Operation{
+			OperationID: "sync-github",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/github/sync",
+			Summary:     "GitHubのIssueと今すぐ同期",
+			Description: "Todoの完了状態をIssueに反映した後、前回以降に更新されたIssueを取り込む",
+			Tags:        []string{"admin"},
+		}, h.github.Sync)
+	}
+
+	if h.jira != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "sync-jira",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/jira/sync",
+			Summary:     "Jiraの課題と今すぐ同期",
+			Description: "完了・未完了に戻したTodoの課題でトランジションを実行した後、JQLに一致する課題の変更を取り込む",
+			Tags:        []string{"admin"},
+		}, h.jira.Sync)
+	}
+
+	if h.push != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "get-vapid-public-key",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/push/vapid-public-key",
+			Summary:     "VAPIDの公開鍵を取得",
+			Description: "ブラウザで pushManager.subscribe() を呼ぶ際に applicationServerKey として渡す",
+			Tags:        []string{"push"},
+		}, h.push.GetPublicKey)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "subscribe-push",
+			Method:        http.MethodPost,
+			Path:          "/api/v1/push/subscriptions",
+			Summary:       "Web Push通知を購読",
+			Description:   "PushSubscription.toJSON() の内容を登録する。同じendpointの購読は鍵・有効期限を更新する",
+			Tags:          []string{"push"},
+			DefaultStatus: 201,
+		}, h.push.Subscribe)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "unsubscribe-push",
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/push/subscriptions",
+			Summary:     "Web Push通知の購読を解除",
+			Tags:        []string{"push"},
+		}, h.push.Unsubscribe)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "list-push-subscriptions",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/admin/push/subscriptions",
+			Summary:     "Web Pushの購読一覧を取得",
+			Tags:        []string{"admin"},
+		}, h.push.ListSubscriptions)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "delete-push-subscription",
+			Method:      http.MethodDelete,
+			Path:        "/api/v1/admin/push/subscriptions/{id}",
+			Summary:     "Web Pushの購読を削除",
+			Tags:        []string{"admin"},
+		}, h.push.DeleteSubscription)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "test-push",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/push/test",
+			Summary:     "Web Pushのテスト通知を送信",
+			Description: "全ての購読へテスト通知を送信する。失効した購読は削除される",
+			Tags:        []string{"admin"},
+		}, h.push.SendTest)
+	}
+
+	if h.notion != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "sync-notion",
+			Method:      http.MethodPost,
+			Path:        "/api/v1/admin/integrations/notion/sync",
+			Summary:     "Notionのデータベースへ今すぐ同期",
+			Description: "前回以降に作成・更新されたTodoをページに反映し、削除されたTodoのページをアーカイブする（full=trueで全件を反映し直す）",
+			Tags:        []string{"admin"},
+		}, h.notion.Sync)
+	}
+
+	if h.zapier != nil {
+		huma.Register(api, huma.Operation{
+			OperationID: "zapier-test-auth",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/integrations/zapier/auth/test",
+			Summary:     "ZapierのAPIキーを確認",
+			Description: "Zapierの接続テストで呼び出され、APIキーが有効な場合は接続名を返す",
+			Tags:        []string{"integrations"},
+		}, h.zapier.TestAuth)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "zapier-trigger-new-todo",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/integrations/zapier/triggers/new_todo",
+			Summary:     "Zapierのnew_todoトリガー",
+			Description: "作成日時の新しい順にTodoを返す。Zapierはidで重複を排除する",
+			Tags:        []string{"integrations"},
+		}, h.zapier.NewTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID: "zapier-trigger-completed-todo",
+			Method:      http.MethodGet,
+			Path:        "/api/v1/integrations/zapier/triggers/completed_todo",
+			Summary:     "Zapierのcompleted_todoトリガー",
+			Description: "完了日時の新しい順に完了済みのTodoを返す。idにはTodoのIDと完了日時を含む",
+			Tags:        []string{"integrations"},
+		}, h.zapier.CompletedTodo)
+
+		huma.Register(api, huma.Operation{
+			OperationID:   "zapier-action-create-todo",
+			Method:        http.MethodPost,
+			Path:          "/api/v1/integrations/zapier/actions/create_todo",
+			Summary:       "Zapierのcreate_todoアクション",
+			Description:   "Todoを作成し、トリガーと同じ形式で返す",
+			Tags:          []string{"integrations"},
+			DefaultStatus: http.StatusCreated,
+		}, h.zapier.CreateTodo)
+	}
+
+	markAdminSecurity(api.OpenAPI())
+}
+
+// markAdminSecurity トークンが必要な管理APIのエンドポイントに、スペック上のセキュリティ要件を付ける（認証自体は adminauth.Middleware が行う）
+func markAdminSecurity(spec *huma.OpenAPI) {
+	for path, item := range spec.Paths {
+		if !adminauth.Protected(path) {
+			continue
+		}
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op != nil {
+				op.Security = []map[string][]string{{adminauth.SecurityScheme: {}}}
+			}
+		}
+	}
+}
+
+// dumpOpenAPI サーバーを起動せずに、OpenAPIのスペックをファイルに書き出す（pathが「-」の場合は標準出力）
+// 拡張子が .json の場合はJSON、それ以外はYAML。クライアントSDKの生成用に、設定で無効な機能を含む全てのエンドポイントを出力する
+// DB・外部サービスには接続せず、ハンドラーはゼロ値を登録する（スペックの生成にのみ使い、呼び出さない）
+func dumpOpenAPI(path string, strictUnknownFields bool) error {
+	api := humachi.New(chi.NewRouter(), newAPIConfig())
+	registerRoutes(api, &apiHandlers{
+		healthDetail:    &handler.HumaHealthHandler{},
+		todo:            &handler.HumaTodoHandler{},
+		stale:           &handler.HumaStaleHandler{},
+		profile:         &handler.HumaProfileHandler{},
+		bulk:            &handler.HumaBulkHandler{},
+		imports:         &handler.HumaImportHandler{},
+		admin:           &handler.HumaAdminHandler{},
+		feature:         &handler.HumaFeatureHandler{},
+		maintenance:     &handler.HumaMaintenanceHandler{},
+		reload:          &handler.HumaReloadHandler{},
+		diagnostics:     &handler.HumaDiagnosticsHandler{},
+		jobs:            &handler.HumaJobsHandler{},
+		queue:           &handler.HumaQueueHandler{},
+		webhook:         &handler.HumaWebhookHandler{},
+		webhookEndpoint: &handler.HumaWebhookEndpointHandler{},
+		escalation:      &handler.HumaEscalationHandler{},
+		reminder:        &handler.HumaReminderHandler{},
+		snapshot:        &handler.HumaSnapshotHandler{},
+		digest:          &handler.HumaDigestHandler{},
+		calendar:        &handler.HumaCalendarHandler{},
+		mstodo:          &handler.HumaMSTodoHandler{},
+		github:          &handler.HumaGitHubHandler{},
+		jira:            &handler.HumaJiraHandler{},
+		push:            &handler.HumaPushHandler{},
+		notion:          &handler.HumaNotionHandler{},
+		zapier:          &handler.HumaZapierHandler{},
+		githubWebhook:   true,
+	})
+	handler.ConfigureUnknownFields(api, strictUnknownFields)
+
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(api.OpenAPI(), "", "  ")
+	} else {
+		data, err = api.OpenAPI().YAML()
+	}
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
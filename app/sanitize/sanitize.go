@@ -0,0 +1,59 @@
+package sanitize
+
+import (
+	"html"
+	"myapp/config"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// サニタイズ方式
+const (
+	// ModeOff サニタイズしない
+	ModeOff = "off"
+	// ModeStrip 書式用の安全なタグのみ残し、scriptやイベントハンドラー属性等を除去する
+	ModeStrip = "strip"
+	// ModeEscape 全てのHTMLをエスケープしてプレーンテキストとして扱う
+	ModeEscape = "escape"
+)
+
+// サニタイズのタイミング
+const (
+	// StageSave 保存時にサニタイズする（DBには無害化済みの値が残る）
+	StageSave = "save"
+	// StageOutput 出力時にサニタイズする（DBには入力どおりの値が残る）
+	StageOutput = "output"
+)
+
+// policy 許可するタグ・属性の定義（ユーザー生成コンテンツ向けの既定ポリシー）
+var policy = bluemonday.UGCPolicy()
+
+// Apply 指定した方式で文字列をサニタイズ
+func Apply(mode, value string) string {
+	switch mode {
+	case ModeStrip:
+		return policy.Sanitize(value)
+	case ModeEscape:
+		return html.EscapeString(value)
+	default:
+		return value
+	}
+}
+
+// OnSave 保存時にサニタイズする設定の場合に説明文をサニタイズ
+func OnSave(description string) string {
+	cfg := config.Current().Sanitize
+	if cfg.Stage != StageSave {
+		return description
+	}
+	return Apply(cfg.Mode, description)
+}
+
+// OnOutput 出力時にサニタイズする設定の場合に説明文をサニタイズ
+func OnOutput(description string) string {
+	cfg := config.Current().Sanitize
+	if cfg.Stage != StageOutput {
+		return description
+	}
+	return Apply(cfg.Mode, description)
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule ジョブの実行時刻の決め方
+type Schedule interface {
+	// Next tより後の次の実行時刻
+	Next(t time.Time) time.Time
+}
+
+// cronParser 5フィールドのcron式（分 時 日 月 曜日）と @daily 等の定義済みのスケジュールを解釈するパーサー
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// cronSchedule cron式のスケジュール（robfig/cronのスケジュールに、夏時間の終了時に同じ時刻を繰り返さない処理を加える）
+type cronSchedule struct {
+	spec *cron.SpecSchedule
+}
+
+// everySchedule 一定間隔のスケジュール（インスタンス間で揃うようUnix時刻の間隔の倍数で実行する）
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Parse スケジュールを解釈する
+// 5フィールドのcron式（"*/15 9-18 * * mon-fri" 等）、@daily 等の定義済みのスケジュール、"@every 5m" の形式に対応し、
+// cron式の時刻はlocのタイムゾーンで解釈する
+func Parse(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	// robfig/cronの @every は前回の実行からの間隔のため、インスタンス間で揃うよう独自に解釈する
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("@every には1秒以上の間隔を指定してください: %q", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		return nil, fmt.Errorf("タイムゾーンはcron式ではなく SCHEDULER_TIMEZONE で指定してください: %q", spec)
+	}
+
+	parsed, err := cronParser.Parse(strings.ToLower(spec))
+	if err != nil {
+		return nil, fmt.Errorf("cron式（分 時 日 月 曜日）が不正です: %q: %w", spec, err)
+	}
+	s, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		return nil, fmt.Errorf("対応していないスケジュールです: %q", spec)
+	}
+	s.Location = loc
+	return cronSchedule{spec: s}, nil
+}
+
+// Next tより後で全てのフィールドに一致する最初の時刻（5年先まで見つからない場合はゼロ値）
+// 夏時間の終了で同じ時刻が繰り返される間は、t以前の時刻（壁時計）に戻った候補を実行しない
+func (s cronSchedule) Next(t time.Time) time.Time {
+	after := wallClock(t.In(s.spec.Location))
+	next := s.spec.Next(t)
+	for !next.IsZero() && !wallClock(next.In(s.spec.Location)).After(after) {
+		next = s.spec.Next(next)
+	}
+	return next
+}
+
+// wallClock タイムゾーンのオフセットを無視した壁時計の時刻
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// Next tより後で間隔の倍数になる最初の時刻
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
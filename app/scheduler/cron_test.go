@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCronScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(value string) time.Time {
+		t.Helper()
+		tm, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	// 2024-03-10 02:00 EST → 03:00 EDT（夏時間の開始）、2024-11-03 02:00 EDT → 01:00 EST（夏時間の終了）
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "夏時間の開始で存在しない時刻はスキップする",
+			spec: "30 2 * * *",
+			from: utc("2024-03-09T08:00:00Z"), // 03:00 EST
+			want: []time.Time{
+				utc("2024-03-11T06:30:00Z"), // 02:30 EDT
+			},
+		},
+		{
+			name: "夏時間の開始をまたぐ間隔",
+			spec: "*/30 * * * *",
+			from: utc("2024-03-10T06:45:00Z"), // 01:45 EST
+			want: []time.Time{
+				utc("2024-03-10T07:00:00Z"), // 03:00 EDT
+				utc("2024-03-10T07:30:00Z"), // 03:30 EDT
+			},
+		},
+		{
+			name: "夏時間の終了で繰り返される時刻は1回だけ実行する",
+			spec: "30 1 * * *",
+			from: utc("2024-11-02T16:00:00Z"), // 12:00 EDT
+			want: []time.Time{
+				utc("2024-11-03T05:30:00Z"), // 01:30 EDT
+				utc("2024-11-04T06:30:00Z"), // 01:30 EST（翌日）
+			},
+		},
+		{
+			name: "夏時間の終了で繰り返される1時間は実行しない",
+			spec: "*/15 * * * *",
+			from: utc("2024-11-03T05:30:00Z"), // 01:30 EDT
+			want: []time.Time{
+				utc("2024-11-03T05:45:00Z"), // 01:45 EDT
+				utc("2024-11-03T07:00:00Z"), // 02:00 EST
+				utc("2024-11-03T07:15:00Z"), // 02:15 EST
+			},
+		},
+		{
+			name: "毎時",
+			spec: "@hourly",
+			from: utc("2024-11-03T04:30:00Z"), // 00:30 EDT
+			want: []time.Time{
+				utc("2024-11-03T05:00:00Z"), // 01:00 EDT
+				utc("2024-11-03T07:00:00Z"), // 02:00 EST
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at := tt.from
+			for _, want := range tt.want {
+				got := s.Next(at)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", at.In(loc), got.In(loc), want.In(loc))
+				}
+				at = got
+			}
+		})
+	}
+}
+
+func TestEveryScheduleNext(t *testing.T) {
+	s, err := Parse("@every 5m", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 1, 10, 2, 30, 0, time.UTC)
+	if got, want := s.Next(from), time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
+
+func TestParseRejectsInvalidSpec(t *testing.T) {
+	for _, spec := range []string{"@every 500ms", "CRON_TZ=Asia/Tokyo 0 9 * * *", "0 9 * *", "61 * * * *"} {
+		if _, err := Parse(spec, time.UTC); err == nil {
+			t.Errorf("Parse(%q) = nil error, want error", spec)
+		}
+	}
+}
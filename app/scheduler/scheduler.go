@@ -0,0 +1,344 @@
+// Package scheduler cron式で定期実行するジョブのスケジューラー
+// 予定時刻ごとにDBの行を更新して実行権を取得するため、複数インスタンスで起動しても各予定時刻のジョブは1回だけ実行される
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/jobs"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 即時実行・実行履歴のエラー
+var (
+	ErrJobNotFound = errcode.New(errcode.ScheduledJobNotFound, "スケジューラーに登録されていないジョブです")
+	// ErrJobRunning 実行中のジョブ（別のインスタンスでの実行を含む）は即時実行できない
+	ErrJobRunning  = errcode.New(errcode.JobRunning, "ジョブは実行中です")
+	ErrRunNotFound = errcode.New(errcode.JobRunNotFound, "ジョブの実行履歴が見つかりません")
+	// ErrNotRunning スケジューラーが起動していない
+	ErrNotRunning = errcode.New(errcode.SchedulerNotRunning, "スケジューラーが起動していません")
+)
+
+// Options スケジューラーの設定
+type Options struct {
+	// Location cron式の時刻を解釈するタイムゾーン
+	Location *time.Location
+	// LockLease 実行中のロックの期限（ジョブの実行時間の上限を兼ねる）
+	LockLease time.Duration
+}
+
+// Scheduler 登録したジョブを予定時刻に実行するスケジューラー
+type Scheduler struct {
+	db       *gorm.DB
+	location *time.Location
+	lease    time.Duration
+	// owner ロックを保持するインスタンスの識別子（ホスト名とプロセスID）
+	owner string
+
+	mu      sync.Mutex
+	entries []*entry
+	// ctx Runに渡されたコンテキスト（即時実行したジョブもシャットダウン時に中断する）
+	ctx context.Context
+	// manual 即時実行中のジョブ（Runの終了時に完了を待つ）
+	manual sync.WaitGroup
+}
+
+// entry 登録されたジョブ
+type entry struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       func(ctx context.Context) error
+	job      *jobs.Job
+}
+
+// New 新しいスケジューラーを作成
+func New(opts Options) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	host, _ := os.Hostname()
+	return &Scheduler{
+		db:       db.GetDB(),
+		location: opts.Location,
+		lease:    opts.LockLease,
+		owner:    fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}
+}
+
+// Add ジョブを登録（specはcron式・@daily等・"@every 5m"。Runの前に呼び出す）
+// 実行状況はjobsパッケージに登録し、管理APIのジョブ一覧で確認できる
+func (s *Scheduler) Add(name, description, spec string, fn func(ctx context.Context) error) error {
+	schedule, err := Parse(spec, s.location)
+	if err != nil {
+		return fmt.Errorf("ジョブ %s のスケジュールが不正です: %w", name, err)
+	}
+	first := schedule.Next(time.Now())
+	if first.IsZero() {
+		return fmt.Errorf("ジョブ %s のスケジュールに該当する時刻がありません: %q", name, spec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+		// 停滞検知の間隔は次の2回の予定時刻の差とする
+		job: jobs.Register(name, description, schedule.Next(first).Sub(first)),
+	})
+	return nil
+}
+
+// Len 登録済みのジョブ数
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Run 登録済みのジョブをそれぞれの予定時刻に実行する（ctxがキャンセルされ、実行中のジョブが終わるまでブロック）
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	entries := append([]*entry(nil), s.entries...)
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+	s.manual.Wait()
+}
+
+// loop 次の予定時刻まで待って実行することを繰り返す
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	slog.InfoContext(ctx, "ジョブをスケジュールしました", "job", e.name, "schedule", e.spec, "next", e.schedule.Next(time.Now()))
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.WarnContext(ctx, "ジョブの次の予定時刻がないため停止します", "job", e.name, "schedule", e.spec)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.fire(ctx, e, next)
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// fire 予定時刻atの実行権を取得できた場合のみジョブを実行する
+func (s *Scheduler) fire(ctx context.Context, e *entry, at time.Time) {
+	claimed, err := s.claim(ctx, e.name, at)
+	if err != nil {
+		slog.ErrorContext(ctx, "ジョブのロックの取得に失敗しました", "job", e.name, "error", err)
+		return
+	}
+	if !claimed {
+		slog.DebugContext(ctx, "別のインスタンスが実行するためスキップしました", "job", e.name, "scheduled_at", at)
+		return
+	}
+
+	run := s.start(ctx, e.name, model.JobRunTriggerSchedule, &at)
+	s.execute(ctx, e, run)
+}
+
+// Trigger ジョブを予定時刻を待たずにバックグラウンドで実行し、実行履歴を返す（予定時刻のスケジュールは変わらない）
+// 実行中（別のインスタンスでの実行を含む）の場合はErrJobRunning
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*model.JobRun, error) {
+	s.mu.Lock()
+	base := s.ctx
+	var e *entry
+	for _, candidate := range s.entries {
+		if candidate.name == name {
+			e = candidate
+			break
+		}
+	}
+	s.mu.Unlock()
+	if e == nil {
+		return nil, ErrJobNotFound
+	}
+	if base == nil {
+		return nil, ErrNotRunning
+	}
+
+	claimed, err := s.claimNow(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("ジョブのロックの取得に失敗しました: %w", err)
+	}
+	if !claimed {
+		return nil, ErrJobRunning
+	}
+
+	run := s.start(ctx, name, model.JobRunTriggerManual, nil)
+	slog.InfoContext(ctx, "ジョブを即時実行します", "job", name, "run_id", run.ID)
+	s.manual.Add(1)
+	go func() {
+		defer s.manual.Done()
+		s.execute(base, e, run)
+	}()
+	return run, nil
+}
+
+// start 実行履歴を記録する（記録に失敗してもジョブは実行する）
+func (s *Scheduler) start(ctx context.Context, name, trigger string, scheduledAt *time.Time) *model.JobRun {
+	run := &model.JobRun{
+		Job:         name,
+		Trigger:     trigger,
+		ScheduledAt: scheduledAt,
+		Instance:    s.owner,
+		Status:      model.JobRunRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(run).Error; err != nil {
+		slog.WarnContext(ctx, "ジョブの実行履歴の記録に失敗しました", "job", name, "error", err)
+	}
+	return run
+}
+
+// execute ロックを取得済みのジョブを実行し、実行履歴とロックに結果を記録する
+func (s *Scheduler) execute(ctx context.Context, e *entry, run *model.JobRun) {
+	runCtx, cancel := context.WithTimeout(ctx, s.lease)
+	err := e.job.Run(runCtx, e.fn)
+	cancel()
+	s.release(ctx, e.name, err)
+	s.finish(ctx, run, err)
+}
+
+// finish 実行履歴に結果を記録する
+func (s *Scheduler) finish(ctx context.Context, run *model.JobRun, runErr error) {
+	if run.ID == 0 {
+		return
+	}
+	now := time.Now()
+	run.FinishedAt = &now
+	run.DurationMs = float64(now.Sub(run.StartedAt).Microseconds()) / 1000
+	run.Status = model.JobRunSucceeded
+	if runErr != nil {
+		run.Status = model.JobRunFailed
+		run.Error = runErr.Error()
+	}
+	err := s.db.WithContext(context.WithoutCancel(ctx)).Model(run).
+		UpdateColumns(map[string]any{
+			"status":      run.Status,
+			"finished_at": run.FinishedAt,
+			"duration_ms": run.DurationMs,
+			"error":       run.Error,
+		}).Error
+	if err != nil {
+		slog.WarnContext(ctx, "ジョブの実行履歴の記録に失敗しました", "job", run.Job, "run_id", run.ID, "error", err)
+	}
+}
+
+// Runs 実行履歴を新しい順に取得（name・statusが空の場合は絞り込まない）
+func (s *Scheduler) Runs(ctx context.Context, name string, status model.JobRunStatus, limit int) ([]*model.JobRun, error) {
+	query := s.db.WithContext(ctx).Order("started_at DESC, id DESC").Limit(limit)
+	if name != "" {
+		query = query.Where("job = ?", name)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var runs []*model.JobRun
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("ジョブの実行履歴の取得に失敗しました: %w", err)
+	}
+	return runs, nil
+}
+
+// GetRun IDで実行履歴を取得
+func (s *Scheduler) GetRun(ctx context.Context, id uint) (*model.JobRun, error) {
+	var run model.JobRun
+	if err := s.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunNotFound
+		}
+		return nil, fmt.Errorf("ジョブの実行履歴の取得に失敗しました: %w", err)
+	}
+	return &run, nil
+}
+
+// claim 予定時刻atのジョブの実行権を取得する
+// 同じ予定時刻を別のインスタンスが実行済み、または前回の実行がロックの期限内で続いている場合はfalse
+func (s *Scheduler) claim(ctx context.Context, name string, at time.Time) (bool, error) {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ScheduledJob{Name: name}).Error
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.ScheduledJob{}).
+		Where("name = ? AND (last_fired_at IS NULL OR last_fired_at < ?) AND (locked_until IS NULL OR locked_until < ?)", name, at, now).
+		UpdateColumns(map[string]any{
+			"last_fired_at": at,
+			"locked_by":     s.owner,
+			"locked_until":  now.Add(s.lease),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// claimNow 即時実行のためにジョブのロックを取得する（予定時刻の記録は変えない。実行中の場合はfalse）
+func (s *Scheduler) claimNow(ctx context.Context, name string) (bool, error) {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ScheduledJob{Name: name}).Error
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.ScheduledJob{}).
+		Where("name = ? AND (locked_until IS NULL OR locked_until < ?)", name, now).
+		UpdateColumns(map[string]any{
+			"locked_by":    s.owner,
+			"locked_until": now.Add(s.lease),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// release ロックを解放し、実行結果を記録する
+func (s *Scheduler) release(ctx context.Context, name string, runErr error) {
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+	err := s.db.WithContext(context.WithoutCancel(ctx)).Model(&model.ScheduledJob{}).
+		Where("name = ? AND locked_by = ?", name, s.owner).
+		UpdateColumns(map[string]any{
+			"locked_until":     nil,
+			"last_finished_at": time.Now(),
+			"last_error":       lastError,
+		}).Error
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.WarnContext(ctx, "ジョブのロックの解放に失敗しました", "job", name, "error", err)
+	}
+}
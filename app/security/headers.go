@@ -0,0 +1,58 @@
+package security
+
+import (
+	"myapp/config"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// docsPathPrefix 外部スクリプトを読み込むAPIドキュメントのパス
+const docsPathPrefix = "/docs"
+
+// HeadersMiddleware セキュリティ関連のレスポンスヘッダーを付与するミドルウェア
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func HeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Current().Security
+		if !cfg.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		setIfNotEmpty(h, "X-Content-Type-Options", cfg.ContentTypeOptions)
+		setIfNotEmpty(h, "X-Frame-Options", cfg.FrameOptions)
+		setIfNotEmpty(h, "Referrer-Policy", cfg.ReferrerPolicy)
+
+		if strings.HasPrefix(r.URL.Path, docsPathPrefix) {
+			setIfNotEmpty(h, "Content-Security-Policy", cfg.DocsContentSecurityPolicy)
+		} else {
+			setIfNotEmpty(h, "Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+
+		if cfg.HSTSMaxAge > 0 {
+			h.Set("Strict-Transport-Security", hstsValue(cfg))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hstsValue Strict-Transport-Securityヘッダーの値を組み立てる
+func hstsValue(cfg config.SecurityConfig) string {
+	value := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if cfg.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+func setIfNotEmpty(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
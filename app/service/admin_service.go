@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/tracing"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxQueryLength DB統計に含める実行中のSQLの最大文字数
+const maxQueryLength = 200
+
+// queryLiteralPattern SQL中のリテラル（文字列・ドル引用符・数値）とプレースホルダー（$1 等）
+var queryLiteralPattern = regexp.MustCompile(`(?s)[Ee]?'(?:[^']|'')*'|\$\$.*?\$\$|\$[A-Za-z_]\w*\$.*?\$[A-Za-z_]\w*\$|\$\d+|\b\d+(?:\.\d+)?\b`)
+
+// AdminService 運用管理サービスのインターフェース
+type AdminService interface {
+	GetDBStats(ctx context.Context) (*model.DBStats, error)
+	GetMigrations(ctx context.Context, dryRun bool) (*model.MigrationReport, error)
+}
+
+// adminService 運用管理サービスの実装
+type adminService struct {
+	db *gorm.DB
+}
+
+// NewAdminService 新しい運用管理サービスインスタンスを作成
+func NewAdminService() AdminService {
+	return &adminService{
+		db: db.GetDB(),
+	}
+}
+
+// GetDBStats テーブル行数・プール使用状況・最長クエリなどの統計を取得
+func (s *adminService) GetDBStats(ctx context.Context) (*model.DBStats, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetDBStats", tracing.SpanKindInternal)
+	defer span.End()
+
+	database := s.db.WithContext(ctx)
+	stats := &model.DBStats{
+		CollectedAt: time.Now(),
+	}
+
+	// データベースサイズ
+	result := database.Raw("SELECT pg_database_size(current_database())").Scan(&stats.DatabaseSizeBytes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("データベースサイズの取得に失敗しました: %w", result.Error)
+	}
+
+	// テーブルごとの統計
+	result = database.Raw(`
+		SELECT relname AS table_name,
+		       n_live_tup AS live_tuples,
+		       n_dead_tup AS dead_tuples,
+		       pg_total_relation_size(relid) AS total_size_bytes,
+		       last_autovacuum,
+		       last_autoanalyze
+		FROM pg_stat_user_tables
+		ORDER BY relname`).Scan(&stats.Tables)
+	if result.Error != nil {
+		return nil, fmt.Errorf("テーブル統計の取得に失敗しました: %w", result.Error)
+	}
+
+	// 実行中で最も長いクエリ（自身の接続とアイドル接続は除外）
+	var queries []*model.RunningQuery
+	result = database.Raw(`
+		SELECT pid,
+		       state,
+		       EXTRACT(EPOCH FROM (now() - query_start)) AS duration_seconds,
+		       query
+		FROM pg_stat_activity
+		WHERE datname = current_database()
+		  AND state <> 'idle'
+		  AND pid <> pg_backend_pid()
+		  AND query_start IS NOT NULL
+		ORDER BY query_start ASC
+		LIMIT 1`).Scan(&queries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("実行中クエリの取得に失敗しました: %w", result.Error)
+	}
+	if len(queries) > 0 {
+		stats.LongestQuery = queries[0]
+		stats.LongestQuery.Query = redactQuery(stats.LongestQuery.Query)
+	}
+
+	// コネクションプールの使用状況
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("コネクションプールの取得に失敗しました: %w", err)
+	}
+	poolStats := sqlDB.Stats()
+	stats.Pool = &model.PoolStats{
+		MaxOpenConnections: poolStats.MaxOpenConnections,
+		OpenConnections:    poolStats.OpenConnections,
+		InUse:              poolStats.InUse,
+		Idle:               poolStats.Idle,
+		WaitCount:          poolStats.WaitCount,
+		WaitDurationMs:     poolStats.WaitDuration.Milliseconds(),
+		MaxLifetimeClosed:  poolStats.MaxLifetimeClosed,
+		MaxIdleTimeClosed:  poolStats.MaxIdleTimeClosed,
+	}
+
+	return stats, nil
+}
+
+// GetMigrations マイグレーションの適用状況を取得（dryRun指定時は未適用分のSQLも取得）
+func (s *adminService) GetMigrations(ctx context.Context, dryRun bool) (*model.MigrationReport, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetMigrations", tracing.SpanKindInternal)
+	defer span.End()
+
+	statuses, err := db.GetMigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.MigrationReport{
+		Migrations: statuses,
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			report.Pending++
+		}
+	}
+
+	if dryRun {
+		statements, err := db.DryRunMigrations(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("マイグレーションのdry-runに失敗しました: %w", err)
+		}
+		report.DryRunSQL = statements
+	}
+
+	return report, nil
+}
+
+// redactQuery 実行中のSQLのリテラルを ? に置き換え、maxQueryLength 文字で切り詰める
+// 簡易プロトコル（PgBouncer併用時）ではパラメーターの値がSQLに埋め込まれるため、個人情報等を統計に含めない
+func redactQuery(query string) string {
+	query = queryLiteralPattern.ReplaceAllStringFunc(query, func(m string) string {
+		if strings.HasPrefix(m, "$") && len(m) > 1 && m[1] >= '0' && m[1] <= '9' {
+			return m
+		}
+		return "?"
+	})
+	query = strings.Join(strings.Fields(query), " ")
+	if runes := []rune(query); len(runes) > maxQueryLength {
+		return string(runes[:maxQueryLength]) + "…"
+	}
+	return query
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/tracing"
+	"myapp/workerpool"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// bulkProgressEvery 進捗を保存する間隔（処理した件数）
+const bulkProgressEvery = 100
+
+// 一括操作のエラー
+var (
+	// ErrBulkNotFound 指定したIDの一括操作が存在しない
+	ErrBulkNotFound = errcode.New(errcode.BulkNotFound, "一括操作が見つかりません")
+	// ErrBulkTooManyItems 対象のTodoが上限を超えている
+	ErrBulkTooManyItems = errcode.New(errcode.BulkTooManyItems, "一度に操作できるTodoの上限を超えています")
+	// ErrBulkNoUpdate operation=update で変更が指定されていない
+	ErrBulkNoUpdate = errcode.New(errcode.BulkNoUpdate, "operation=update の場合は update を指定してください")
+	// ErrBulkInvalidItem 一括作成するTodoに不正なものがある
+	ErrBulkInvalidItem = errcode.New(errcode.BulkInvalidItem, "作成するTodoの内容が正しくありません")
+)
+
+// BulkService Todoの一括更新・削除をワーカープールで処理するサービスのインターフェース
+type BulkService interface {
+	// Start 一括操作を開始し、進捗を確認するためのジョブを返す（処理はバックグラウンドで行う）
+	Start(ctx context.Context, req *model.BulkRequest) (*model.BulkJob, error)
+	// Create 複数のTodoを1つのトランザクションでまとめて作成する（同期的に処理する）
+	Create(ctx context.Context, reqs []*model.TodoCreateRequest) ([]*model.Todo, error)
+	GetBulk(ctx context.Context, id uint) (*model.BulkJob, error)
+	// Wait 実行中の一括操作が完了するまで待つ（シャットダウン用）
+	Wait(ctx context.Context) error
+}
+
+// bulkService 一括操作サービスの実装
+type bulkService struct {
+	db          *gorm.DB
+	todoService TodoService
+	// concurrency 1件の一括操作を並列に処理するワーカー数
+	concurrency int
+	// maxItems 1回の一括操作で指定できるTodoの上限
+	maxItems int
+	// insertBatchSize 一括作成で1回のINSERTにまとめる件数
+	insertBatchSize int
+
+	// running 実行中の一括操作（シャットダウン時に完了を待つ）
+	running sync.WaitGroup
+}
+
+// NewBulkService 新しい一括操作サービスインスタンスを作成
+// 1件ずつtodoServiceで更新・削除するため、変更の検証・イベントの発行・キャッシュの無効化は通常の更新と同じになる
+func NewBulkService(todoService TodoService, concurrency, maxItems, insertBatchSize int) BulkService {
+	return &bulkService{
+		db:              db.GetDB(),
+		todoService:     todoService,
+		concurrency:     concurrency,
+		maxItems:        maxItems,
+		insertBatchSize: insertBatchSize,
+	}
+}
+
+// Start 一括操作を開始
+func (s *bulkService) Start(ctx context.Context, req *model.BulkRequest) (*model.BulkJob, error) {
+	ctx, span := tracing.Start(ctx, "BulkService.Start", tracing.SpanKindInternal)
+	defer span.End()
+
+	if req.Operation == model.BulkUpdate && req.Update == nil {
+		return nil, ErrBulkNoUpdate
+	}
+	ids := slices.Clone(req.IDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) > s.maxItems {
+		return nil, fmt.Errorf("%w（%d件、上限: %d件）", ErrBulkTooManyItems, len(ids), s.maxItems)
+	}
+
+	job := &model.BulkJob{
+		Operation: req.Operation,
+		Status:    model.BulkStatusRunning,
+		Total:     len(ids),
+		Messages:  []string{},
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("一括操作の開始に失敗しました: %w", err)
+	}
+
+	// リクエストの完了後も続けるためキャンセルを引き継がない
+	runCtx := context.WithoutCancel(ctx)
+	started := *job
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(runCtx, &started, ids, req.Update)
+	}()
+	return job, nil
+}
+
+// Create 複数のTodoを一括で作成
+func (s *bulkService) Create(ctx context.Context, reqs []*model.TodoCreateRequest) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "BulkService.Create", tracing.SpanKindInternal)
+	defer span.End()
+
+	if len(reqs) > s.maxItems {
+		return nil, fmt.Errorf("%w（%d件、上限: %d件）", ErrBulkTooManyItems, len(reqs), s.maxItems)
+	}
+	return s.todoService.CreateTodos(ctx, reqs, s.insertBatchSize)
+}
+
+// GetBulk 一括操作の進捗を取得
+func (s *bulkService) GetBulk(ctx context.Context, id uint) (*model.BulkJob, error) {
+	ctx, span := tracing.Start(ctx, "BulkService.GetBulk", tracing.SpanKindInternal)
+	defer span.End()
+
+	var job model.BulkJob
+	if err := s.db.WithContext(ctx).Take(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBulkNotFound
+		}
+		return nil, fmt.Errorf("一括操作の取得に失敗しました: %w", err)
+	}
+	return &job, nil
+}
+
+// Wait 実行中の一括操作が完了するまで待つ
+func (s *bulkService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 対象のTodoをワーカープールで1件ずつ更新・削除し、一定件数ごとに進捗を保存する
+func (s *bulkService) run(ctx context.Context, job *model.BulkJob, ids []uint, update *model.TodoUpdateRequest) {
+	ctx, span := tracing.Start(ctx, "BulkService.run", tracing.SpanKindInternal)
+	defer span.End()
+
+	var mu sync.Mutex
+	workerpool.Run(ctx, s.concurrency, ids, func(ctx context.Context, id uint) {
+		var err error
+		if job.Operation == model.BulkDelete {
+			err = s.todoService.DeleteTodo(ctx, id)
+		} else {
+			_, err = s.todoService.UpdateTodo(ctx, id, update)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		job.Processed++
+		if err != nil {
+			job.Failed++
+			job.AddMessage(fmt.Sprintf("ID %d: %v", id, err))
+		} else {
+			job.Succeeded++
+		}
+		if job.Processed%bulkProgressEvery == 0 {
+			if err := s.saveProgress(ctx, job); err != nil {
+				slog.WarnContext(ctx, "一括操作の進捗の保存に失敗しました", "bulk_id", job.ID, "error", err)
+			}
+		}
+	})
+
+	job.Status = model.BulkStatusCompleted
+	now := time.Now()
+	job.FinishedAt = &now
+	err := s.db.WithContext(ctx).Model(job).
+		Select("status", "processed", "succeeded", "failed", "messages", "finished_at").
+		Updates(job).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "一括操作の結果の保存に失敗しました", "bulk_id", job.ID, "error", err)
+	}
+	slog.InfoContext(ctx, "一括操作が完了しました",
+		"bulk_id", job.ID, "operation", job.Operation, "succeeded", job.Succeeded, "failed", job.Failed, "concurrency", s.concurrency)
+}
+
+// saveProgress 進捗を保存
+func (s *bulkService) saveProgress(ctx context.Context, job *model.BulkJob) error {
+	return s.db.WithContext(ctx).Model(job).Select("processed", "succeeded", "failed", "messages").Updates(job).Error
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"myapp/cache"
+	"myapp/db/model"
+	"strconv"
+	"time"
+)
+
+// キャッシュの名前空間
+const (
+	// TodoListCacheNamespace Todoの一覧・件数（todosテーブルへのどの書き込みでも無効化する）
+	TodoListCacheNamespace = "todos:list"
+	// TodoItemCacheNamespace 1件のTodoの取得結果の接頭辞（"todos:item:<ID>" をそのTodoへの書き込みで無効化する）
+	TodoItemCacheNamespace = "todos:item"
+	// statsCacheNamespace DB統計（書き込みでは無効化せずTTLで更新する）
+	statsCacheNamespace = "stats"
+)
+
+// キャッシュのスコープ
+var (
+	todoListScope = cache.Scope{TodoListCacheNamespace}
+	statsScope    = cache.Scope{statsCacheNamespace}
+)
+
+// todoItemScope 1件のTodoのスコープ（対象の行が分からない書き込みで無効化する "todos:item" と、そのTodoの名前空間）
+func todoItemScope(id uint) cache.Scope {
+	return cache.Scope{TodoItemCacheNamespace, TodoItemCacheNamespace + ":" + strconv.FormatUint(uint64(id), 10)}
+}
+
+// cachedTodoService 一覧・取得の結果をキャッシュするTodoサービス
+// 無効化はcache.InvalidationPluginがtodosテーブルへの書き込みを検知して行う（一覧は全て、1件の取得結果は書き込んだTodoの分だけ）
+type cachedTodoService struct {
+	TodoService
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachedTodoService 読み取り結果をttlの間キャッシュするTodoサービスを作成（書き込みはそのまま委譲する）
+func NewCachedTodoService(inner TodoService, c *cache.Cache, ttl time.Duration) TodoService {
+	return &cachedTodoService{
+		TodoService: inner,
+		cache:       c,
+		ttl:         ttl,
+	}
+}
+
+// GetAllTodos 全てのTodoを取得
+func (s *cachedTodoService) GetAllTodos(ctx context.Context) ([]*model.Todo, error) {
+	return cached(ctx, s.cache, todoListScope, "all", s.ttl, func() ([]*model.Todo, error) {
+		return s.TodoService.GetAllTodos(ctx)
+	})
+}
+
+// GetTodoByID IDで特定のTodoを取得
+func (s *cachedTodoService) GetTodoByID(ctx context.Context, id uint) (*model.Todo, error) {
+	return cached(ctx, s.cache, todoItemScope(id), "todo", s.ttl, func() (*model.Todo, error) {
+		return s.TodoService.GetTodoByID(ctx, id)
+	})
+}
+
+// GetTodosByPriority 優先度でTodoをフィルタリング
+func (s *cachedTodoService) GetTodosByPriority(ctx context.Context, priority model.Priority) ([]*model.Todo, error) {
+	if !priority.IsValid() {
+		return s.TodoService.GetTodosByPriority(ctx, priority)
+	}
+	return cached(ctx, s.cache, todoListScope, "priority:"+string(priority), s.ttl, func() ([]*model.Todo, error) {
+		return s.TodoService.GetTodosByPriority(ctx, priority)
+	})
+}
+
+// GetCompletedTodos 完了済みTodoを取得
+func (s *cachedTodoService) GetCompletedTodos(ctx context.Context) ([]*model.Todo, error) {
+	return cached(ctx, s.cache, todoListScope, "completed", s.ttl, func() ([]*model.Todo, error) {
+		return s.TodoService.GetCompletedTodos(ctx)
+	})
+}
+
+// GetPendingTodos 未完了Todoを取得
+func (s *cachedTodoService) GetPendingTodos(ctx context.Context) ([]*model.Todo, error) {
+	return cached(ctx, s.cache, todoListScope, "pending", s.ttl, func() ([]*model.Todo, error) {
+		return s.TodoService.GetPendingTodos(ctx)
+	})
+}
+
+// CountTodos 総件数を取得（正確な件数のみキャッシュする。推定値は統計情報を読むだけのためキャッシュしない）
+func (s *cachedTodoService) CountTodos(ctx context.Context, q *TodoPageQuery, mode CountMode) (*TodoCount, error) {
+	if mode != CountExact {
+		return s.TodoService.CountTodos(ctx, q, mode)
+	}
+	completed := ""
+	if q.Completed != nil {
+		completed = strconv.FormatBool(*q.Completed)
+	}
+	return cached(ctx, s.cache, todoListScope, "count:"+string(q.Priority)+":"+completed, s.ttl, func() (*TodoCount, error) {
+		return s.TodoService.CountTodos(ctx, q, mode)
+	})
+}
+
+// cachedAdminService DB統計をキャッシュする運用管理サービス
+type cachedAdminService struct {
+	AdminService
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachedAdminService DB統計をttlの間キャッシュする運用管理サービスを作成（統計は集計時刻 collected_at を含む）
+func NewCachedAdminService(inner AdminService, c *cache.Cache, ttl time.Duration) AdminService {
+	return &cachedAdminService{
+		AdminService: inner,
+		cache:        c,
+		ttl:          ttl,
+	}
+}
+
+// GetDBStats テーブル行数・プール使用状況・最長クエリなどの統計を取得
+func (s *cachedAdminService) GetDBStats(ctx context.Context) (*model.DBStats, error) {
+	return cached(ctx, s.cache, statsScope, "db", s.ttl, func() (*model.DBStats, error) {
+		return s.AdminService.GetDBStats(ctx)
+	})
+}
+
+// cached キャッシュがあれば返し、なければloadの結果をキャッシュして返す
+// 保存先（Redis）の障害時はキャッシュを使わずloadの結果を返す（エラーはキャッシュしない）
+func cached[T any](ctx context.Context, c *cache.Cache, scope cache.Scope, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	var value T
+	hit, version, err := c.Get(ctx, scope, key, &value)
+	if err != nil {
+		slog.WarnContext(ctx, "キャッシュの取得に失敗しました", "scope", scope, "key", key, "error", err)
+		return load()
+	}
+	if hit {
+		return value, nil
+	}
+
+	value, err = load()
+	if err != nil {
+		return value, err
+	}
+	if err := c.Set(ctx, scope, version, key, value, ttl); err != nil {
+		slog.WarnContext(ctx, "キャッシュの保存に失敗しました", "scope", scope, "key", key, "error", err)
+	}
+	return value, nil
+}
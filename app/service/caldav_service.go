@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/tracing"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// caldavDefaultPrefix CalDAVで作成されていないTodoのリソース名・UIDの接頭辞
+const caldavDefaultPrefix = "todo-"
+
+// ErrCalDAVNotFound 指定したリソース名のTodoが存在しない
+var ErrCalDAVNotFound = errcode.New(errcode.TodoNotFound, "Todoが見つかりません")
+
+// CalDAVService CalDAVサーバーからTodoを読み書きするサービスのインターフェース
+type CalDAVService interface {
+	List(ctx context.Context) ([]*model.Todo, error)
+	Find(ctx context.Context, name string) (*model.Todo, error)
+	Create(ctx context.Context, name string, item *model.CalDAVTodo) (*model.Todo, error)
+	Update(ctx context.Context, todo *model.Todo, item *model.CalDAVTodo) (*model.Todo, error)
+	Delete(ctx context.Context, todo *model.Todo) error
+	CTag(ctx context.Context) (string, error)
+}
+
+// caldavColumns CalDAVで読み込むカラム
+var caldavColumns = append(append([]string{}, todoColumns...), "caldav_name", "caldav_uid")
+
+// caldavService CalDAVサービスの実装
+type caldavService struct {
+	db          *gorm.DB
+	todoService TodoService
+}
+
+// NewCalDAVService 新しいCalDAVサービスインスタンスを作成
+// 作成・更新はTodoService経由で行い、サニタイズや通知を通常のAPIと共通にする
+func NewCalDAVService(todoService TodoService) CalDAVService {
+	return &caldavService{
+		db:          db.GetDB(),
+		todoService: todoService,
+	}
+}
+
+// CalDAVName TodoのCalDAVでのリソース名
+func CalDAVName(todo *model.Todo) string {
+	if todo.CalDAVName != nil {
+		return *todo.CalDAVName
+	}
+	return caldavDefaultPrefix + strconv.FormatUint(uint64(todo.ID), 10) + ".ics"
+}
+
+// CalDAVUID TodoのVTODOのUID
+func CalDAVUID(todo *model.Todo) string {
+	if todo.CalDAVUID != nil {
+		return *todo.CalDAVUID
+	}
+	return caldavDefaultPrefix + strconv.FormatUint(uint64(todo.ID), 10)
+}
+
+// List 全てのTodoを取得
+func (s *caldavService) List(ctx context.Context) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.List", tracing.SpanKindInternal)
+	defer span.End()
+
+	var todos []*model.Todo
+	if err := s.db.WithContext(ctx).Select(caldavColumns).Order("id").Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", err)
+	}
+	return todos, nil
+}
+
+// Find リソース名でTodoを取得（CalDAVで作成したものはその名前、それ以外は todo-<id>.ics）
+func (s *caldavService) Find(ctx context.Context, name string) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Find", tracing.SpanKindInternal)
+	defer span.End()
+
+	var todos []*model.Todo
+	if err := s.db.WithContext(ctx).Select(caldavColumns).Where("caldav_name = ?", name).Limit(1).Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", err)
+	}
+	if len(todos) > 0 {
+		return todos[0], nil
+	}
+
+	id, ok := parseDefaultCalDAVName(name)
+	if !ok {
+		return nil, ErrCalDAVNotFound
+	}
+	if err := s.db.WithContext(ctx).Select(caldavColumns).Where("id = ? AND caldav_name IS NULL", id).Limit(1).Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", err)
+	}
+	if len(todos) == 0 {
+		return nil, ErrCalDAVNotFound
+	}
+	return todos[0], nil
+}
+
+// Create CalDAVクライアントが作成したVTODOからTodoを作成
+func (s *caldavService) Create(ctx context.Context, name string, item *model.CalDAVTodo) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Create", tracing.SpanKindInternal)
+	defer span.End()
+
+	created, err := s.todoService.CreateTodo(ctx, &model.TodoCreateRequest{
+		Title:       item.Summary,
+		Description: item.Description,
+		Priority:    item.Priority,
+		DueDate:     item.Due,
+		DueAllDay:   item.DueAllDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 作成直後の更新でupdated_atが進まないようUpdateColumnsを使用
+	columns := map[string]interface{}{"caldav_name": name}
+	if item.UID != "" {
+		columns["caldav_uid"] = item.UID
+	}
+	if err := s.db.WithContext(ctx).Model(&model.Todo{ID: created.ID}).UpdateColumns(columns).Error; err != nil {
+		return nil, fmt.Errorf("CalDAVリソース名の保存に失敗しました: %w", err)
+	}
+
+	if item.Completed {
+		completed := true
+		if _, err := s.todoService.UpdateTodo(ctx, created.ID, &model.TodoUpdateRequest{Completed: &completed}); err != nil {
+			return nil, err
+		}
+	}
+	return s.reload(ctx, created.ID)
+}
+
+// Update CalDAVクライアントが更新したVTODOをTodoに反映
+func (s *caldavService) Update(ctx context.Context, todo *model.Todo, item *model.CalDAVTodo) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Update", tracing.SpanKindInternal)
+	defer span.End()
+
+	req := &model.TodoUpdateRequest{
+		Title:       &item.Summary,
+		Description: &item.Description,
+		Completed:   &item.Completed,
+		Priority:    &item.Priority,
+		DueDate:     item.Due,
+	}
+	if item.Due != nil {
+		req.DueAllDay = &item.DueAllDay
+	}
+	if _, err := s.todoService.UpdateTodo(ctx, todo.ID, req); err != nil {
+		return nil, err
+	}
+
+	// UpdateTodoでは期限を外せないため、DUEが削除された場合は個別に更新する
+	if item.Due == nil && todo.DueDate != nil {
+		if err := s.db.WithContext(ctx).Model(&model.Todo{ID: todo.ID}).Updates(map[string]any{"due_date": nil, "due_all_day": false}).Error; err != nil {
+			return nil, fmt.Errorf("Todoの更新に失敗しました: %w", err)
+		}
+	}
+
+	if item.UID != "" && item.UID != CalDAVUID(todo) {
+		if err := s.db.WithContext(ctx).Model(&model.Todo{ID: todo.ID}).UpdateColumn("caldav_uid", item.UID).Error; err != nil {
+			return nil, fmt.Errorf("Todoの更新に失敗しました: %w", err)
+		}
+	}
+	return s.reload(ctx, todo.ID)
+}
+
+// Delete Todoを削除（ソフトデリート）
+func (s *caldavService) Delete(ctx context.Context, todo *model.Todo) error {
+	return s.todoService.DeleteTodo(ctx, todo.ID)
+}
+
+// CTag コレクション全体の変更を表す値（作成・更新・削除のたびに変わる）
+func (s *caldavService) CTag(ctx context.Context) (string, error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.CTag", tracing.SpanKindInternal)
+	defer span.End()
+
+	var row struct {
+		Count   int64
+		Updated *time.Time
+		Deleted *time.Time
+	}
+	// 削除済みも含めて集計し、削除でも値が変わるようにする
+	err := s.db.WithContext(ctx).Unscoped().Model(&model.Todo{}).
+		Select("COUNT(*) FILTER (WHERE deleted_at IS NULL) AS count, MAX(updated_at) AS updated, MAX(deleted_at) AS deleted").
+		Scan(&row).Error
+	if err != nil {
+		return "", fmt.Errorf("コレクションの状態の取得に失敗しました: %w", err)
+	}
+
+	var updated, deleted int64
+	if row.Updated != nil {
+		updated = row.Updated.UnixNano()
+	}
+	if row.Deleted != nil {
+		deleted = row.Deleted.UnixNano()
+	}
+	return fmt.Sprintf("%d-%d-%d", row.Count, updated, deleted), nil
+}
+
+// reload 更新後のTodoを読み込み直す
+func (s *caldavService) reload(ctx context.Context, id uint) (*model.Todo, error) {
+	var todo model.Todo
+	if err := s.db.WithContext(ctx).Select(caldavColumns).Take(&todo, id).Error; err != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", err)
+	}
+	return &todo, nil
+}
+
+// parseDefaultCalDAVName todo-<id>.ics 形式のリソース名からIDを取り出す
+func parseDefaultCalDAVName(name string) (uint, bool) {
+	rest, ok := strings.CutPrefix(name, caldavDefaultPrefix)
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, ".ics")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(rest, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
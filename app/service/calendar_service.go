@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/cache"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/gcal"
+	"myapp/jobs"
+	"myapp/tracing"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// googleProvider oauth_tokens に保存する連携先名
+const googleProvider = "google"
+
+// カレンダー同期の定数
+const (
+	// calendarPushBatchSize 1回の同期でカレンダーに反映するTodoの上限
+	calendarPushBatchSize = 200
+	// calendarSyncLease 同期中のロックの期限（同期が異常終了した場合はこの時間で解放される）
+	calendarSyncLease = 10 * time.Minute
+	// oauthStateTTL 同意画面から戻るまでの有効期限
+	oauthStateTTL = 10 * time.Minute
+	// tokenRefreshMargin 有効期限のこの時間前にアクセストークンを更新する
+	tokenRefreshMargin = time.Minute
+	// todoIDProperty 予定に記録するTodoのIDのプロパティ名
+	todoIDProperty = "todo_id"
+)
+
+// カレンダー連携のエラー
+var (
+	ErrCalendarNotConnected = errcode.New(errcode.NotConnected, "Googleカレンダーと連携されていません")
+	ErrCalendarSyncRunning  = errcode.New(errcode.SyncRunning, "別のインスタンスで同期中です")
+	ErrInvalidOAuthState    = errcode.New(errcode.InvalidOAuthState, "stateが不正か、有効期限が切れています")
+)
+
+// CalendarService Googleカレンダーとの双方向同期を行うサービスのインターフェース
+type CalendarService interface {
+	AuthURL(ctx context.Context) (string, error)
+	HandleCallback(ctx context.Context, code, state string) error
+	Status(ctx context.Context) (*model.CalendarStatus, error)
+	Disconnect(ctx context.Context) error
+	Sync(ctx context.Context) (*model.CalendarSyncResult, error)
+	WatchSync(ctx context.Context, interval time.Duration)
+}
+
+// calendarService カレンダー同期サービスの実装
+type calendarService struct {
+	db            *gorm.DB
+	oauth         *oauth2.Config
+	client        *gcal.Client
+	calendarID    string
+	eventDuration time.Duration
+
+	// tokenMu アクセストークンの更新を直列化する
+	tokenMu sync.Mutex
+}
+
+// NewCalendarService 新しいカレンダー同期サービスインスタンスを作成
+func NewCalendarService(oauth *oauth2.Config, calendarID string, eventDuration time.Duration) CalendarService {
+	s := &calendarService{
+		db:            db.GetDB(),
+		oauth:         oauth,
+		calendarID:    calendarID,
+		eventDuration: eventDuration,
+	}
+	s.client = gcal.NewClient(s.accessToken)
+	return s
+}
+
+// AuthURL 同意画面のURLを発行
+func (s *calendarService) AuthURL(ctx context.Context) (string, error) {
+	state, err := issueOAuthState(ctx, s.db, googleProvider)
+	if err != nil {
+		return "", err
+	}
+	return s.oauth.AuthCodeURL(state, gcal.AuthCodeOptions...), nil
+}
+
+// HandleCallback 同意画面から戻った認可コードをトークンに交換して保存
+func (s *calendarService) HandleCallback(ctx context.Context, code, state string) error {
+	ctx, span := tracing.Start(ctx, "CalendarService.HandleCallback", tracing.SpanKindInternal)
+	defer span.End()
+
+	if err := consumeOAuthState(ctx, s.db, googleProvider, state); err != nil {
+		return err
+	}
+
+	token, err := exchangeOAuthCode(ctx, s.oauth, code)
+	if err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		return errors.New("リフレッシュトークンを取得できませんでした。Googleアカウントの設定から連携を解除して再度お試しください")
+	}
+	return s.saveToken(ctx, token)
+}
+
+// Status 連携の状態を取得
+func (s *calendarService) Status(ctx context.Context) (*model.CalendarStatus, error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.Status", tracing.SpanKindInternal)
+	defer span.End()
+
+	status := &model.CalendarStatus{CalendarID: s.calendarID}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.OAuthToken{}).Where("provider = ?", googleProvider).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("連携状態の取得に失敗しました: %w", err)
+	}
+	status.Connected = count > 0
+
+	var state model.CalendarSyncState
+	result := s.db.WithContext(ctx).Where("calendar_id = ?", s.calendarID).Limit(1).Find(&state)
+	if result.Error != nil {
+		return nil, fmt.Errorf("同期状態の取得に失敗しました: %w", result.Error)
+	}
+	status.LastSyncedAt = state.LastSyncedAt
+
+	if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("google_event_id IS NOT NULL").Count(&status.LinkedTodos).Error; err != nil {
+		return nil, fmt.Errorf("同期済みTodoの件数の取得に失敗しました: %w", err)
+	}
+	return status, nil
+}
+
+// Disconnect トークンと同期状態を削除する（カレンダーの予定は削除しない）
+func (s *calendarService) Disconnect(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "CalendarService.Disconnect", tracing.SpanKindInternal)
+	defer span.End()
+
+	return cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := tx.Where("provider = ?", googleProvider).Delete(&model.OAuthToken{}).Error; err != nil {
+			return fmt.Errorf("トークンの削除に失敗しました: %w", err)
+		}
+		if err := tx.Where("calendar_id = ?", s.calendarID).Delete(&model.CalendarSyncState{}).Error; err != nil {
+			return fmt.Errorf("同期状態の削除に失敗しました: %w", err)
+		}
+		err := tx.Unscoped().Model(&model.Todo{}).
+			Where("google_event_id IS NOT NULL OR calendar_synced_at IS NOT NULL").
+			UpdateColumns(map[string]any{"google_event_id": nil, "calendar_synced_at": nil}).Error
+		if err != nil {
+			return fmt.Errorf("予定との紐付けの解除に失敗しました: %w", err)
+		}
+		return nil
+	})
+}
+
+// Sync Todoの変更をカレンダーへ反映してから、カレンダー側の変更（日時変更・削除）を取り込む
+func (s *calendarService) Sync(ctx context.Context) (*model.CalendarSyncResult, error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.Sync", tracing.SpanKindInternal)
+	defer span.End()
+
+	if _, err := s.loadToken(ctx); err != nil {
+		return nil, err
+	}
+
+	state, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(ctx)
+
+	result := &model.CalendarSyncResult{}
+	if result.Pushed, err = s.push(ctx); err != nil {
+		return result, err
+	}
+
+	syncToken, pulled, err := s.pull(ctx, state.SyncToken)
+	result.Pulled = pulled
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&model.CalendarSyncState{}).
+		Where("calendar_id = ?", s.calendarID).
+		Updates(map[string]any{"sync_token": syncToken, "last_synced_at": now}).Error
+	if err != nil {
+		return result, fmt.Errorf("同期状態の保存に失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// WatchSync intervalごとに同期する（未連携の間は何もしない。ctxがキャンセルされるまでブロック）
+func (s *calendarService) WatchSync(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("calendar-sync", "Googleカレンダーとの差分同期", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, func(ctx context.Context) error {
+				_, err := s.Sync(ctx)
+				if errors.Is(err, ErrCalendarNotConnected) || errors.Is(err, ErrCalendarSyncRunning) {
+					return nil
+				}
+				return err
+			})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lock 同期状態の行を作成してリースを取得（他のインスタンスが同期中の場合はErrCalendarSyncRunning）
+func (s *calendarService) lock(ctx context.Context) (*model.CalendarSyncState, error) {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.CalendarSyncState{CalendarID: s.calendarID}).Error
+	if err != nil {
+		return nil, fmt.Errorf("同期状態の作成に失敗しました: %w", err)
+	}
+
+	now := time.Now()
+	var states []*model.CalendarSyncState
+	result := s.db.WithContext(ctx).Model(&states).
+		Clauses(clause.Returning{}).
+		Where("calendar_id = ? AND (locked_until IS NULL OR locked_until < ?)", s.calendarID, now).
+		UpdateColumn("locked_until", now.Add(calendarSyncLease))
+	if result.Error != nil {
+		return nil, fmt.Errorf("同期のロックに失敗しました: %w", result.Error)
+	}
+	if len(states) == 0 {
+		return nil, ErrCalendarSyncRunning
+	}
+	return states[0], nil
+}
+
+// unlock リースを解放
+func (s *calendarService) unlock(ctx context.Context) {
+	s.db.WithContext(context.WithoutCancel(ctx)).Model(&model.CalendarSyncState{}).
+		Where("calendar_id = ?", s.calendarID).
+		UpdateColumn("locked_until", nil)
+}
+
+// push 前回の反映以降に変更・削除されたTodoをカレンダーへ反映
+func (s *calendarService) push(ctx context.Context) (int, error) {
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).Unscoped().
+		Where("calendar_synced_at IS NULL OR updated_at > calendar_synced_at OR deleted_at > calendar_synced_at").
+		Where("due_date IS NOT NULL OR google_event_id IS NOT NULL").
+		Order("updated_at").
+		Limit(calendarPushBatchSize).
+		Find(&todos)
+	if result.Error != nil {
+		return 0, fmt.Errorf("反映するTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	for i, todo := range todos {
+		if err := s.pushTodo(ctx, todo); err != nil {
+			return i, fmt.Errorf("Todo %d のカレンダーへの反映に失敗しました: %w", todo.ID, err)
+		}
+	}
+	return len(todos), nil
+}
+
+// pushTodo 1件のTodoを予定として作成・更新・削除し、反映済みの更新日時を記録
+func (s *calendarService) pushTodo(ctx context.Context, todo *model.Todo) error {
+	eventID := todo.GoogleEventID
+	syncedAt := todo.UpdatedAt
+	if todo.DeletedAt.Valid && todo.DeletedAt.Time.After(syncedAt) {
+		syncedAt = todo.DeletedAt.Time
+	}
+
+	if todo.DeletedAt.Valid || todo.DueDate == nil {
+		if eventID != nil {
+			if err := s.client.Delete(ctx, s.calendarID, *eventID); err != nil && !errors.Is(err, gcal.ErrNotFound) {
+				return err
+			}
+		}
+		eventID = nil
+	} else {
+		event := s.toEvent(todo)
+		var saved *gcal.Event
+		var err error
+		if eventID != nil {
+			saved, err = s.client.Patch(ctx, s.calendarID, *eventID, event)
+		}
+		// 未作成、またはカレンダー側で削除済みの場合は作成し直す
+		if eventID == nil || errors.Is(err, gcal.ErrNotFound) {
+			saved, err = s.client.Insert(ctx, s.calendarID, event)
+		}
+		if err != nil {
+			return err
+		}
+		eventID = &saved.ID
+	}
+
+	return s.db.WithContext(ctx).Unscoped().Model(&model.Todo{}).
+		Where("id = ?", todo.ID).
+		UpdateColumns(map[string]any{"google_event_id": eventID, "calendar_synced_at": syncedAt}).Error
+}
+
+// toEvent Todoを予定に変換（期限を開始日時とし、完了済みは件名に印を付ける。終日の期限は終日の予定にする）
+func (s *calendarService) toEvent(todo *model.Todo) *gcal.Event {
+	summary := todo.Title
+	if todo.Completed {
+		summary = "✅ " + summary
+	}
+	start := *todo.DueDate
+	end := start.Add(s.eventDuration)
+	startTime, endTime := &gcal.EventTime{DateTime: &start}, &gcal.EventTime{DateTime: &end}
+	if todo.DueAllDay {
+		startTime = &gcal.EventTime{Date: start.Format(time.DateOnly)}
+		endTime = &gcal.EventTime{Date: start.AddDate(0, 0, 1).Format(time.DateOnly)}
+	}
+	return &gcal.Event{
+		Summary:     summary,
+		Description: todo.Description,
+		Start:       startTime,
+		End:         endTime,
+		ExtendedProperties: &gcal.ExtendedProperties{
+			Private: map[string]string{todoIDProperty: strconv.FormatUint(uint64(todo.ID), 10)},
+		},
+	}
+}
+
+// pull 前回以降に変更された予定を取り込み、次回用の同期トークンと取り込んだ件数を返す
+// 同期トークンが失効している場合は全件を取得し直す
+func (s *calendarService) pull(ctx context.Context, syncToken string) (string, int, error) {
+	pulled := 0
+	pageToken := ""
+	for {
+		list, err := s.client.List(ctx, s.calendarID, syncToken, pageToken)
+		if errors.Is(err, gcal.ErrSyncTokenExpired) {
+			syncToken, pageToken = "", ""
+			continue
+		}
+		if err != nil {
+			return "", pulled, fmt.Errorf("予定の取得に失敗しました: %w", err)
+		}
+
+		for _, event := range list.Items {
+			applied, err := s.applyEvent(ctx, event)
+			if err != nil {
+				return "", pulled, err
+			}
+			if applied {
+				pulled++
+			}
+		}
+
+		if list.NextPageToken == "" {
+			return list.NextSyncToken, pulled, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// applyEvent 予定の変更を対応するTodoに反映（削除された場合は期限と紐付けを解除する）
+func (s *calendarService) applyEvent(ctx context.Context, event *gcal.Event) (bool, error) {
+	var todo model.Todo
+	result := s.db.WithContext(ctx).Where("google_event_id = ?", event.ID).Limit(1).Find(&todo)
+	if result.Error != nil {
+		return false, fmt.Errorf("予定に対応するTodoの取得に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	updates := map[string]any{"updated_at": now, "calendar_synced_at": now}
+	switch {
+	case event.Cancelled():
+		updates["google_event_id"] = nil
+		updates["due_date"] = nil
+	case event.Start != nil && event.Start.DateTime != nil:
+		if todo.DueDate != nil && !todo.DueAllDay && todo.DueDate.Equal(*event.Start.DateTime) {
+			return false, nil
+		}
+		updates["due_date"] = *event.Start.DateTime
+		updates["due_all_day"] = false
+	case event.Start != nil && event.Start.Date != "":
+		// 終日の予定は日付のみの期限にする
+		date, err := time.Parse(time.DateOnly, event.Start.Date)
+		if err != nil {
+			return false, nil
+		}
+		if todo.DueDate != nil && todo.DueAllDay && todo.DueDate.Equal(date) {
+			return false, nil
+		}
+		updates["due_date"] = date
+		updates["due_all_day"] = true
+	default:
+		return false, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(&todo).UpdateColumns(updates).Error; err != nil {
+		return false, fmt.Errorf("Todo %d への予定の変更の反映に失敗しました: %w", todo.ID, err)
+	}
+	events.PublishTodo(ctx, events.TodoUpdated, &todo)
+	return true, nil
+}
+
+// accessToken 保存済みのアクセストークンを返す（有効期限が近い場合は更新して保存する）
+func (s *calendarService) accessToken(ctx context.Context) (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	stored, err := s.loadToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	if time.Until(stored.Expiry) > tokenRefreshMargin {
+		return stored.AccessToken, nil
+	}
+
+	token, err := refreshOAuthToken(ctx, s.oauth, stored.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("アクセストークンの更新に失敗しました: %w", err)
+	}
+	if err := s.saveToken(ctx, token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// loadToken 保存済みのトークンを取得
+func (s *calendarService) loadToken(ctx context.Context) (*model.OAuthToken, error) {
+	var token model.OAuthToken
+	result := s.db.WithContext(ctx).Where("provider = ?", googleProvider).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, fmt.Errorf("トークンの取得に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrCalendarNotConnected
+	}
+	return &token, nil
+}
+
+// saveToken トークンを保存（既存の場合は上書き）
+func (s *calendarService) saveToken(ctx context.Context, token *oauth2.Token) error {
+	record := &model.OAuthToken{
+		Provider:     googleProvider,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}},
+		DoUpdates: clause.AssignmentColumns([]string{"access_token", "refresh_token", "expiry", "updated_at"}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("トークンの保存に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/notify"
+	"myapp/tracing"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// digestBatchSize 1回の配信で対象とする送信先の上限（残りは次回の配信で処理する）
+	digestBatchSize = 100
+	// digestCompletedLimit ダイジェストに載せる完了したTodoの上限
+	digestCompletedLimit = 100
+)
+
+// ダイジェストの配信設定のエラー
+var (
+	ErrDigestSubscriptionNotFound = errcode.New(errcode.DigestNotFound, "ダイジェストの配信設定が見つかりません")
+	ErrDigestEmailExists          = errcode.New(errcode.DigestEmailExists, "このメールアドレスの配信設定は登録済みです")
+	ErrDigestInvalidTimezone      = errcode.New(errcode.InvalidTimezone, "タイムゾーンを読み込めません")
+)
+
+// DigestMailer ダイジェストを指定した宛先へ送信する送信先（メールチャンネル）
+type DigestMailer interface {
+	SendDigestTo(ctx context.Context, to []string, digest notify.Digest) error
+}
+
+// DigestService 送信先ごとの日次・週次ダイジェストメールの配信設定を管理し、配信するサービスのインターフェース
+type DigestService interface {
+	ListSubscriptions(ctx context.Context) ([]*model.DigestSubscription, error)
+	GetSubscription(ctx context.Context, id uint) (*model.DigestSubscription, error)
+	CreateSubscription(ctx context.Context, req *model.DigestSubscriptionRequest) (*model.DigestSubscription, error)
+	UpdateSubscription(ctx context.Context, id uint, req *model.DigestSubscriptionRequest) (*model.DigestSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	// SendNow 予定に関係なくすぐに送信する（次に送る日時は変えない）
+	SendNow(ctx context.Context, id uint) (*model.DigestSubscription, error)
+	// Deliver 送る日時を過ぎた送信先へ配信し、配信した件数を返す
+	Deliver(ctx context.Context) (int, error)
+}
+
+// digestService ダイジェストサービスの実装
+type digestService struct {
+	db     *gorm.DB
+	mailer DigestMailer
+	// profiles タイムゾーンを指定していない配信設定の時刻を解釈するタイムゾーン（利用者のタイムゾーン）
+	profiles ProfileService
+}
+
+// NewDigestService 新しいダイジェストサービスインスタンスを作成
+func NewDigestService(mailer DigestMailer, profiles ProfileService) DigestService {
+	return &digestService{
+		db:       db.GetDB(),
+		mailer:   mailer,
+		profiles: profiles,
+	}
+}
+
+// ListSubscriptions 配信設定を登録順に取得
+func (s *digestService) ListSubscriptions(ctx context.Context) ([]*model.DigestSubscription, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.ListSubscriptions", tracing.SpanKindInternal)
+	defer span.End()
+
+	var subs []*model.DigestSubscription
+	if err := s.db.WithContext(ctx).Order("id").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("ダイジェストの配信設定の取得に失敗しました: %w", err)
+	}
+	return subs, nil
+}
+
+// GetSubscription IDで配信設定を取得
+func (s *digestService) GetSubscription(ctx context.Context, id uint) (*model.DigestSubscription, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.GetSubscription", tracing.SpanKindInternal)
+	defer span.End()
+
+	var sub model.DigestSubscription
+	if err := s.db.WithContext(ctx).First(&sub, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDigestSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("ダイジェストの配信設定の取得に失敗しました: %w", err)
+	}
+	return &sub, nil
+}
+
+// CreateSubscription 配信設定を登録
+func (s *digestService) CreateSubscription(ctx context.Context, req *model.DigestSubscriptionRequest) (*model.DigestSubscription, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.CreateSubscription", tracing.SpanKindInternal)
+	defer span.End()
+
+	sub := &model.DigestSubscription{}
+	if err := s.apply(ctx, sub, req, time.Now()); err != nil {
+		return nil, err
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(sub)
+	if result.Error != nil {
+		return nil, fmt.Errorf("ダイジェストの配信設定の登録に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrDigestEmailExists
+	}
+	return sub, nil
+}
+
+// UpdateSubscription 配信設定を置き換える（送る時刻を変更した場合は次に送る日時を計算し直す）
+func (s *digestService) UpdateSubscription(ctx context.Context, id uint, req *model.DigestSubscriptionRequest) (*model.DigestSubscription, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.UpdateSubscription", tracing.SpanKindInternal)
+	defer span.End()
+
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.DigestSubscription{}).
+		Where("email = ? AND id <> ?", strings.TrimSpace(req.Email), id).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("ダイジェストの配信設定の更新に失敗しました: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrDigestEmailExists
+	}
+
+	if err := s.apply(ctx, sub, req, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(sub).Error; err != nil {
+		return nil, fmt.Errorf("ダイジェストの配信設定の更新に失敗しました: %w", err)
+	}
+	return sub, nil
+}
+
+// DeleteSubscription 配信設定を削除
+func (s *digestService) DeleteSubscription(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "DigestService.DeleteSubscription", tracing.SpanKindInternal)
+	defer span.End()
+
+	result := s.db.WithContext(ctx).Delete(&model.DigestSubscription{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("ダイジェストの配信設定の削除に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDigestSubscriptionNotFound
+	}
+	return nil
+}
+
+// apply リクエストの内容を配信設定に反映し、次に送る日時を計算する
+func (s *digestService) apply(ctx context.Context, sub *model.DigestSubscription, req *model.DigestSubscriptionRequest, now time.Time) error {
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			invalid := &errcode.ValidationError{}
+			invalid.Add("timezone", req.Timezone, ErrDigestInvalidTimezone)
+			return invalid
+		}
+	}
+	sub.Email = strings.TrimSpace(req.Email)
+	sub.Name = strings.TrimSpace(req.Name)
+	sub.Frequency = req.Frequency
+	sub.SendTime = req.SendTime
+	sub.Weekday = req.Weekday
+	sub.Timezone = req.Timezone
+	sub.Enabled = req.Enabled == nil || *req.Enabled
+
+	sub.NextSendAt = nil
+	if sub.Enabled {
+		next := s.nextSendAt(ctx, sub, now)
+		sub.NextSendAt = &next
+	}
+	return nil
+}
+
+// SendNow 予定に関係なくダイジェストを送信
+func (s *digestService) SendNow(ctx context.Context, id uint) (*model.DigestSubscription, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.SendNow", tracing.SpanKindInternal)
+	defer span.End()
+
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.send(ctx, sub, time.Now()); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Deliver 送る日時を過ぎた配信設定のダイジェストを送信
+// 次に送る日時を先に進めてから送信するため、複数インスタンスで実行しても重複して送らない
+// 停止等で送る日時を過ぎた回は、まとめて1回だけ送る
+func (s *digestService) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.Deliver", tracing.SpanKindInternal)
+	defer span.End()
+
+	now := time.Now()
+	var subs []*model.DigestSubscription
+	result := s.db.WithContext(ctx).
+		Where("enabled = ? AND next_send_at <= ?", true, now).
+		Order("next_send_at").
+		Limit(digestBatchSize).
+		Find(&subs)
+	if result.Error != nil {
+		return 0, fmt.Errorf("ダイジェストの配信設定の取得に失敗しました: %w", result.Error)
+	}
+
+	sent := 0
+	var errs []error
+	for _, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		next := s.nextSendAt(ctx, sub, now)
+		claim := s.db.WithContext(ctx).Model(&model.DigestSubscription{}).
+			Where("id = ? AND next_send_at = ?", sub.ID, *sub.NextSendAt).
+			UpdateColumn("next_send_at", next)
+		if claim.Error != nil {
+			return sent, fmt.Errorf("%s へのダイジェストの配信の開始に失敗しました: %w", sub.Email, claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		if err := s.send(ctx, sub, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// send ダイジェストを作成して送信し、結果を記録する
+func (s *digestService) send(ctx context.Context, sub *model.DigestSubscription, now time.Time) error {
+	digest, err := s.build(ctx, sub, now)
+	if err == nil {
+		err = s.mailer.SendDigestTo(ctx, []string{sub.Email}, *digest)
+	}
+
+	updates := map[string]any{"last_error": ""}
+	if err != nil {
+		updates["last_error"] = err.Error()
+		slog.WarnContext(ctx, "ダイジェストの送信に失敗しました", "subscription_id", sub.ID, "error", err)
+	} else {
+		updates["last_sent_at"] = now
+		sub.LastSentAt = &now
+	}
+	if err := s.db.WithContext(ctx).Model(sub).UpdateColumns(updates).Error; err != nil {
+		slog.ErrorContext(ctx, "ダイジェストの送信結果の記録に失敗しました", "subscription_id", sub.ID, "error", err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s へのダイジェストの送信に失敗しました: %w", sub.Email, err)
+	}
+	return nil
+}
+
+// build 配信設定のタイムゾーンで「今日」（毎週の場合は今後7日間）を数えたダイジェストを作成する
+// 完了したTodoは前回送った日時（初回は1日・7日前）以降に完了したもの
+func (s *digestService) build(ctx context.Context, sub *model.DigestSubscription, now time.Time) (*notify.Digest, error) {
+	loc := s.locationOf(ctx, sub)
+	local := now.In(loc)
+	days := 1
+	if sub.Frequency == model.DigestWeekly {
+		days = 7
+	}
+	until := time.Date(local.Year(), local.Month(), local.Day()+days, 0, 0, 0, 0, loc)
+	since := now.AddDate(0, 0, -days)
+	if sub.LastSentAt != nil {
+		since = *sub.LastSentAt
+	}
+
+	var pending []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("completed = ?", false).
+		Where(dueBefore(until, loc)).
+		Order("due_date, created_at").
+		Find(&pending)
+	if result.Error != nil {
+		return nil, fmt.Errorf("ダイジェスト対象のTodoの取得に失敗しました: %w", result.Error)
+	}
+	var completed []*model.Todo
+	result = s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("completed = ? AND completed_at >= ? AND completed_at < ?", true, since, now).
+		Order("completed_at").
+		Limit(digestCompletedLimit).
+		Find(&completed)
+	if result.Error != nil {
+		return nil, fmt.Errorf("ダイジェスト対象のTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	digest := &notify.Digest{GeneratedAt: local, Frequency: sub.Frequency}
+	for _, todo := range pending {
+		overdue := todo.DueBefore(now, loc)
+		todo.DueDate = todo.DueIn(loc)
+		if overdue {
+			digest.Overdue = append(digest.Overdue, *todo)
+		} else {
+			digest.DueToday = append(digest.DueToday, *todo)
+		}
+	}
+	for _, todo := range completed {
+		todo.DueDate = todo.DueIn(loc)
+		digest.Completed = append(digest.Completed, *todo)
+	}
+	return digest, nil
+}
+
+// nextSendAt nowより後の最初の送る日時
+// 夏時間の切り替えで存在しない時刻はtime.Dateの正規化に従って前後にずれる
+func (s *digestService) nextSendAt(ctx context.Context, sub *model.DigestSubscription, now time.Time) time.Time {
+	loc := s.locationOf(ctx, sub)
+	at, _ := time.Parse("15:04", sub.SendTime)
+	local := now.In(loc)
+	for i := 0; ; i++ {
+		next := time.Date(local.Year(), local.Month(), local.Day()+i, at.Hour(), at.Minute(), 0, 0, loc)
+		if !next.After(now) {
+			continue
+		}
+		if sub.Frequency == model.DigestWeekly && int(next.Weekday()) != sub.Weekday {
+			continue
+		}
+		return next
+	}
+}
+
+// locationOf 配信設定のタイムゾーン（未指定・読み込めない場合は利用者のタイムゾーン）
+func (s *digestService) locationOf(ctx context.Context, sub *model.DigestSubscription) *time.Location {
+	if sub.Timezone != "" {
+		if loc, err := time.LoadLocation(sub.Timezone); err == nil {
+			return loc
+		}
+	}
+	return s.profiles.Location(ctx)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/cache"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/notify"
+	"myapp/tracing"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// escalationBatchSize 1回の実行でルールごとに対象とするTodoの上限（残りは次回の実行で処理する）
+const escalationBatchSize = 100
+
+// エスカレーションルールのエラー
+var (
+	ErrEscalationRuleNotFound     = errcode.New(errcode.EscalationRuleNotFound, "エスカレーションルールが見つかりません")
+	ErrEscalationNoAction         = errcode.New(errcode.EscalationNoAction, "raise_priority・recipients・channels のいずれかを指定してください")
+	ErrEscalationUnknownChannel   = errcode.New(errcode.EscalationUnknownChannel, "設定されていない通知チャンネルです")
+	ErrEscalationInvalidRecipient = errcode.New(errcode.EscalationInvalidRecipient, "通知先のメールアドレスが正しくありません")
+	ErrEscalationEmailDisabled    = errcode.New(errcode.EscalationEmailDisabled, "メールで通知するには EMAIL_NOTIFY_ENABLED=true が必要です")
+)
+
+// EscalationMailer イベントを指定した宛先へ送信する送信先（メールチャンネル）
+type EscalationMailer interface {
+	SendTo(ctx context.Context, to []string, event notify.Event) error
+}
+
+// EscalationService 期限切れのTodoのエスカレーションルールを管理し、適用するサービスのインターフェース
+type EscalationService interface {
+	ListRules(ctx context.Context) ([]*model.EscalationRule, error)
+	GetRule(ctx context.Context, id uint) (*model.EscalationRule, error)
+	CreateRule(ctx context.Context, req *model.EscalationRuleRequest) (*model.EscalationRule, error)
+	UpdateRule(ctx context.Context, id uint, req *model.EscalationRuleRequest) (*model.EscalationRule, error)
+	DeleteRule(ctx context.Context, id uint) error
+	ListLogs(ctx context.Context, ruleID, todoID uint, limit int) ([]*model.EscalationLog, error)
+	// Escalate 有効なルールを期限切れのTodoに適用し、適用した件数を返す
+	Escalate(ctx context.Context) (int, error)
+}
+
+// escalationService エスカレーションサービスの実装
+type escalationService struct {
+	db *gorm.DB
+	// mailer メールアドレスへの通知に使う送信先（nilの場合はメールアドレスへ通知できない）
+	mailer EscalationMailer
+	// profiles 終日の期限の終わりを数えるタイムゾーン（利用者のタイムゾーン）
+	profiles ProfileService
+}
+
+// NewEscalationService 新しいエスカレーションサービスインスタンスを作成
+func NewEscalationService(mailer EscalationMailer, profiles ProfileService) EscalationService {
+	return &escalationService{
+		db:       db.GetDB(),
+		mailer:   mailer,
+		profiles: profiles,
+	}
+}
+
+// ListRules ルールを登録順に取得
+func (s *escalationService) ListRules(ctx context.Context) ([]*model.EscalationRule, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.ListRules", tracing.SpanKindInternal)
+	defer span.End()
+
+	var rules []*model.EscalationRule
+	if err := s.db.WithContext(ctx).Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("エスカレーションルールの取得に失敗しました: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule IDでルールを取得
+func (s *escalationService) GetRule(ctx context.Context, id uint) (*model.EscalationRule, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.GetRule", tracing.SpanKindInternal)
+	defer span.End()
+
+	var rule model.EscalationRule
+	if err := s.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEscalationRuleNotFound
+		}
+		return nil, fmt.Errorf("エスカレーションルールの取得に失敗しました: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule ルールを登録
+func (s *escalationService) CreateRule(ctx context.Context, req *model.EscalationRuleRequest) (*model.EscalationRule, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.CreateRule", tracing.SpanKindInternal)
+	defer span.End()
+
+	rule := &model.EscalationRule{}
+	if err := s.apply(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("エスカレーションルールの登録に失敗しました: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule ルールを置き換える（適用済みのTodoには、期限が変わるまで改めて適用しない）
+func (s *escalationService) UpdateRule(ctx context.Context, id uint, req *model.EscalationRuleRequest) (*model.EscalationRule, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.UpdateRule", tracing.SpanKindInternal)
+	defer span.End()
+
+	rule, err := s.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(rule).Error; err != nil {
+		return nil, fmt.Errorf("エスカレーションルールの更新に失敗しました: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule ルールと適用の記録を削除
+func (s *escalationService) DeleteRule(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "EscalationService.DeleteRule", tracing.SpanKindInternal)
+	defer span.End()
+
+	return cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		result := tx.Delete(&model.EscalationRule{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("エスカレーションルールの削除に失敗しました: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrEscalationRuleNotFound
+		}
+		if err := tx.Where("rule_id = ?", id).Delete(&model.EscalationLog{}).Error; err != nil {
+			return fmt.Errorf("エスカレーションの記録の削除に失敗しました: %w", err)
+		}
+		return nil
+	})
+}
+
+// apply リクエストの内容を検証してルールに反映する（無効なフィールドはまとめて返す）
+func (s *escalationService) apply(rule *model.EscalationRule, req *model.EscalationRuleRequest) error {
+	invalid := &errcode.ValidationError{}
+	recipients := make([]string, 0, len(req.Recipients))
+	for i, r := range req.Recipients {
+		addr, err := mail.ParseAddress(strings.TrimSpace(r))
+		if err != nil {
+			invalid.Add(fmt.Sprintf("recipients[%d]", i), r, ErrEscalationInvalidRecipient)
+			continue
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	if len(req.Recipients) > 0 && s.mailer == nil {
+		invalid.Add("recipients", req.Recipients, ErrEscalationEmailDisabled)
+	}
+	channels := make([]string, 0, len(req.Channels))
+	for i, name := range req.Channels {
+		name = strings.TrimSpace(name)
+		if notify.Lookup(name) == nil {
+			invalid.Add(fmt.Sprintf("channels[%d]", i), name, ErrEscalationUnknownChannel)
+			continue
+		}
+		channels = append(channels, name)
+	}
+	if req.RaisePriority == "" && len(req.Recipients) == 0 && len(req.Channels) == 0 {
+		invalid.Add("raise_priority", req.RaisePriority, ErrEscalationNoAction)
+	}
+	if err := invalid.Err(); err != nil {
+		return err
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.OverdueMinutes = req.OverdueMinutes
+	rule.Tag = strings.TrimSpace(req.Tag)
+	rule.MinPriority = req.MinPriority
+	rule.RaisePriority = req.RaisePriority
+	rule.Recipients = recipients
+	rule.Channels = channels
+	return nil
+}
+
+// ListLogs 適用の記録を新しい順に取得（ruleID・todoIDが0の場合は絞り込まない）
+func (s *escalationService) ListLogs(ctx context.Context, ruleID, todoID uint, limit int) ([]*model.EscalationLog, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.ListLogs", tracing.SpanKindInternal)
+	defer span.End()
+
+	query := s.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if ruleID != 0 {
+		query = query.Where("rule_id = ?", ruleID)
+	}
+	if todoID != 0 {
+		query = query.Where("todo_id = ?", todoID)
+	}
+
+	var logs []*model.EscalationLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("エスカレーションの記録の取得に失敗しました: %w", err)
+	}
+	return logs, nil
+}
+
+// Escalate 有効なルールを登録順に、条件に一致する期限切れの未完了Todoへ適用する
+// 適用はルール・Todo・期限ごとに1回だけで、期限を変更したTodoには新しい期限を過ぎてから改めて適用する
+func (s *escalationService) Escalate(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "EscalationService.Escalate", tracing.SpanKindInternal)
+	defer span.End()
+
+	var rules []*model.EscalationRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		return 0, fmt.Errorf("エスカレーションルールの取得に失敗しました: %w", err)
+	}
+
+	now := time.Now()
+	var errs []error
+	total := 0
+	for _, rule := range rules {
+		n, err := s.escalate(ctx, rule, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ルール %d（%s）: %w", rule.ID, rule.Name, err))
+		}
+		total += n
+	}
+	return total, errors.Join(errs...)
+}
+
+// escalate 1つのルールを、条件に一致して未適用のTodoへ適用する
+// 終日の期限は利用者のタイムゾーンでのその日の終わりから超過時間を数える
+func (s *escalationService) escalate(ctx context.Context, rule *model.EscalationRule, now time.Time) (int, error) {
+	logged := s.db.Model(&model.EscalationLog{}).
+		Select("1").
+		Where("escalation_logs.rule_id = ? AND escalation_logs.todo_id = todos.id AND escalation_logs.due_date = todos.due_date", rule.ID)
+
+	query := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("completed = ? AND due_date IS NOT NULL", false).
+		Where(dueBefore(now.Add(-time.Duration(rule.OverdueMinutes)*time.Minute), s.profiles.Location(ctx))).
+		Where("NOT EXISTS (?)", logged)
+	if rule.Tag != "" {
+		tag, _ := json.Marshal([]string{rule.Tag})
+		query = query.Where("tags::jsonb @> ?::jsonb", string(tag))
+	}
+	if rule.MinPriority != "" {
+		query = query.Where("priority IN ?", priorities(func(p model.Priority) bool { return p.Rank() >= rule.MinPriority.Rank() }))
+	}
+
+	var todos []*model.Todo
+	if err := query.Order("due_date").Limit(escalationBatchSize).Find(&todos).Error; err != nil {
+		return 0, fmt.Errorf("対象のTodoの取得に失敗しました: %w", err)
+	}
+
+	count := 0
+	for _, todo := range todos {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		ok, err := s.escalateTodo(ctx, rule, todo, now)
+		if err != nil {
+			return count, err
+		}
+		if ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// escalateTodo 適用を記録してから優先度を引き上げ、通知する（別のインスタンスが適用済みの場合はfalse）
+func (s *escalationService) escalateTodo(ctx context.Context, rule *model.EscalationRule, todo *model.Todo, now time.Time) (bool, error) {
+	entry := &model.EscalationLog{
+		RuleID:           rule.ID,
+		TodoID:           todo.ID,
+		DueDate:          *todo.DueDate,
+		PreviousPriority: todo.Priority,
+	}
+	if rule.RaisePriority != "" && todo.Priority.Rank() < rule.RaisePriority.Rank() {
+		entry.RaisedTo = rule.RaisePriority
+	}
+
+	claimed := false
+	err := cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = true
+		if entry.RaisedTo == "" {
+			return nil
+		}
+		return tx.Model(todo).Update("priority", entry.RaisedTo).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("Todo %d へのエスカレーションに失敗しました: %w", todo.ID, err)
+	}
+	if !claimed {
+		return false, nil
+	}
+	if entry.RaisedTo != "" {
+		todo.Priority = entry.RaisedTo
+		events.PublishTodo(ctx, events.TodoUpdated, todo)
+	}
+
+	notified, err := s.notify(ctx, rule, todo, now)
+	entry.Notified = notified
+	if err != nil {
+		entry.Error = err.Error()
+		slog.WarnContext(ctx, "エスカレーションの通知に失敗しました", "rule_id", rule.ID, "todo_id", todo.ID, "error", err)
+	}
+	if len(notified) > 0 || err != nil {
+		if err := s.db.WithContext(ctx).Model(entry).Select("notified", "error").Updates(entry).Error; err != nil {
+			return true, fmt.Errorf("Todo %d のエスカレーションの記録に失敗しました: %w", todo.ID, err)
+		}
+	}
+	slog.InfoContext(ctx, "期限切れのTodoをエスカレーションしました", "rule_id", rule.ID, "todo_id", todo.ID, "raised_to", entry.RaisedTo, "notified", notified)
+	return true, nil
+}
+
+// notify ルールで指定した宛先・チャンネルへ通知し、通知した宛先・チャンネルを返す
+// 失敗した通知は再試行しない（メールはジョブキューを使う場合のみキューで再試行する）
+func (s *escalationService) notify(ctx context.Context, rule *model.EscalationRule, todo *model.Todo, now time.Time) ([]string, error) {
+	event := notify.Event{Type: notify.EventEscalated, Todo: *todo, OccurredAt: now, Note: "ルール: " + rule.Name}
+
+	var notified []string
+	var errs []error
+	if len(rule.Recipients) > 0 {
+		if s.mailer == nil {
+			errs = append(errs, ErrEscalationEmailDisabled)
+		} else if err := s.mailer.SendTo(ctx, rule.Recipients, event); err != nil {
+			errs = append(errs, fmt.Errorf("メール: %w", err))
+		} else {
+			notified = append(notified, rule.Recipients...)
+		}
+	}
+	for _, name := range rule.Channels {
+		channel := notify.Lookup(name)
+		if channel == nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrEscalationUnknownChannel, name))
+			continue
+		}
+		if err := notify.Send(ctx, channel, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		notified = append(notified, name)
+	}
+	return notified, errors.Join(errs...)
+}
+
+// priorities 条件を満たす優先度（低い順）
+func priorities(keep func(model.Priority) bool) []model.Priority {
+	var result []model.Priority
+	for _, p := range []model.Priority{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityUrgent} {
+		if keep(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
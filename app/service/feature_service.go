@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/feature"
+	"myapp/tracing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeatureService フィーチャーフラグサービスのインターフェース
+type FeatureService interface {
+	LoadFeatures(ctx context.Context) error
+	GetFeatures(ctx context.Context) []feature.Flag
+	SetFeature(ctx context.Context, name string, enabled bool) (*feature.Flag, error)
+}
+
+// featureService フィーチャーフラグサービスの実装
+type featureService struct {
+	db *gorm.DB
+}
+
+// NewFeatureService 新しいフィーチャーフラグサービスインスタンスを作成
+func NewFeatureService() FeatureService {
+	return &featureService{
+		db: db.GetDB(),
+	}
+}
+
+// LoadFeatures DBに保存された値をフラグに反映（環境変数・デフォルト値より優先）
+func (s *featureService) LoadFeatures(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "FeatureService.LoadFeatures", tracing.SpanKindInternal)
+	defer span.End()
+
+	var records []*model.FeatureFlag
+
+	result := s.db.WithContext(ctx).Find(&records)
+	if result.Error != nil {
+		return fmt.Errorf("フィーチャーフラグの読み込みに失敗しました: %w", result.Error)
+	}
+
+	for _, record := range records {
+		// 登録されなくなったフラグの値は無視する
+		_ = feature.Set(record.Name, record.Enabled, feature.SourceDB)
+	}
+
+	return nil
+}
+
+// GetFeatures 全てのフラグを取得
+func (s *featureService) GetFeatures(ctx context.Context) []feature.Flag {
+	return feature.List()
+}
+
+// SetFeature フラグの値を変更してDBに保存
+func (s *featureService) SetFeature(ctx context.Context, name string, enabled bool) (*feature.Flag, error) {
+	ctx, span := tracing.Start(ctx, "FeatureService.SetFeature", tracing.SpanKindInternal)
+	defer span.End()
+
+	if _, ok := feature.Get(name); !ok {
+		return nil, fmt.Errorf("%w: %s", feature.ErrUnknownFlag, name)
+	}
+
+	record := &model.FeatureFlag{
+		Name:    name,
+		Enabled: enabled,
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(record)
+	if result.Error != nil {
+		return nil, fmt.Errorf("フィーチャーフラグの保存に失敗しました: %w", result.Error)
+	}
+
+	if err := feature.Set(name, enabled, feature.SourceDB); err != nil {
+		return nil, err
+	}
+
+	flag, _ := feature.Get(name)
+	return &flag, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/github"
+	"myapp/jobs"
+	"myapp/sanitize"
+	"myapp/tracing"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GitHub Issue同期の定数
+const (
+	// githubPushBatchSize 1回の同期でIssueに反映するTodoの上限
+	githubPushBatchSize = 200
+	// githubSyncLease 同期中のロックの期限（同期が異常終了した場合はこの時間で解放される）
+	githubSyncLease = 10 * time.Minute
+	// githubMaxTitleLength タイトルの上限（APIのバリデーションと同じ）
+	githubMaxTitleLength = 255
+)
+
+// GitHub Issue同期のエラー
+var (
+	ErrGitHubSyncRunning      = errcode.New(errcode.SyncRunning, "別のインスタンスで同期中です")
+	ErrGitHubInvalidSignature = errcode.New(errcode.InvalidSignature, "Webhookの署名が不正です")
+)
+
+// GitHubService GitHubのIssueとTodoを同期するサービスのインターフェース
+type GitHubService interface {
+	Sync(ctx context.Context) (*model.GitHubSyncResult, error)
+	HandleWebhook(ctx context.Context, event, signature string, body []byte) error
+	WatchSync(ctx context.Context, interval time.Duration)
+}
+
+// githubService GitHub Issue同期サービスの実装
+type githubService struct {
+	db            *gorm.DB
+	client        *github.Client
+	repository    string
+	webhookSecret string
+}
+
+// NewGitHubService 新しいGitHub Issue同期サービスインスタンスを作成（repositoryは owner/repo）
+func NewGitHubService(client *github.Client, repository, webhookSecret string) GitHubService {
+	return &githubService{
+		db:            db.GetDB(),
+		client:        client,
+		repository:    repository,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// Sync Todoの完了状態をIssueに反映した後、前回以降に更新されたIssueを取り込む
+func (s *githubService) Sync(ctx context.Context) (*model.GitHubSyncResult, error) {
+	ctx, span := tracing.Start(ctx, "GitHubService.Sync", tracing.SpanKindInternal)
+	defer span.End()
+
+	state, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(ctx)
+
+	result := &model.GitHubSyncResult{}
+	if result.Pushed, err = s.push(ctx); err != nil {
+		return result, err
+	}
+
+	since, pulled, err := s.pull(ctx, state.Since)
+	result.Pulled = pulled
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&model.GitHubSyncState{}).
+		Where("repository = ?", s.repository).
+		Updates(map[string]any{"since": since, "last_synced_at": now}).Error
+	if err != nil {
+		return result, fmt.Errorf("同期状態の保存に失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// HandleWebhook issuesイベントのWebhookを検証してTodoに反映（対象外のリポジトリ・イベントは無視する）
+func (s *githubService) HandleWebhook(ctx context.Context, event, signature string, body []byte) error {
+	ctx, span := tracing.Start(ctx, "GitHubService.HandleWebhook", tracing.SpanKindInternal)
+	defer span.End()
+
+	if !github.VerifySignature(s.webhookSecret, body, signature) {
+		return ErrGitHubInvalidSignature
+	}
+	if event != "issues" {
+		return nil
+	}
+
+	var payload github.IssuesEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("Webhookのペイロードが不正です: %w", err)
+	}
+	if payload.Issue == nil || !strings.EqualFold(payload.Repository.FullName, s.repository) {
+		return nil
+	}
+
+	switch payload.Action {
+	case "deleted", "transferred":
+		// Issueがなくなった場合はTodoを残して紐付けのみ解除する
+		err := s.db.WithContext(ctx).Unscoped().Model(&model.Todo{}).
+			Where("github_issue = ?", s.issueRef(payload.Issue.Number)).
+			UpdateColumns(map[string]any{"github_issue": nil, "github_synced_at": nil}).Error
+		if err != nil {
+			return fmt.Errorf("Issueとの紐付けの解除に失敗しました: %w", err)
+		}
+		return nil
+	default:
+		_, err := s.applyIssue(ctx, payload.Issue)
+		return err
+	}
+}
+
+// WatchSync intervalごとに同期する（ctxがキャンセルされるまでブロック）
+func (s *githubService) WatchSync(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("github-sync", "GitHub Issueとの差分同期", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, func(ctx context.Context) error {
+				_, err := s.Sync(ctx)
+				if errors.Is(err, ErrGitHubSyncRunning) {
+					return nil
+				}
+				return err
+			})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lock 同期状態の行にロックの期限を設定（他のインスタンスが同期中の場合はErrGitHubSyncRunning）
+func (s *githubService) lock(ctx context.Context) (*model.GitHubSyncState, error) {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.GitHubSyncState{Repository: s.repository}).Error
+	if err != nil {
+		return nil, fmt.Errorf("同期状態の作成に失敗しました: %w", err)
+	}
+
+	now := time.Now()
+	var states []*model.GitHubSyncState
+	result := s.db.WithContext(ctx).Model(&states).
+		Clauses(clause.Returning{}).
+		Where("repository = ? AND (locked_until IS NULL OR locked_until < ?)", s.repository, now).
+		UpdateColumn("locked_until", now.Add(githubSyncLease))
+	if result.Error != nil {
+		return nil, fmt.Errorf("同期のロックに失敗しました: %w", result.Error)
+	}
+	if len(states) == 0 {
+		return nil, ErrGitHubSyncRunning
+	}
+	return states[0], nil
+}
+
+// unlock 同期のロックを解放
+func (s *githubService) unlock(ctx context.Context) {
+	s.db.WithContext(context.WithoutCancel(ctx)).Model(&model.GitHubSyncState{}).
+		Where("repository = ?", s.repository).
+		UpdateColumn("locked_until", nil)
+}
+
+// push 前回の反映以降に更新されたTodoの完了状態をIssueのオープン・クローズに反映し、反映した件数を返す
+func (s *githubService) push(ctx context.Context) (int, error) {
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select("id", "completed", "updated_at", "github_issue").
+		Where("github_issue LIKE ?", s.repository+"#%").
+		Where("github_synced_at IS NULL OR updated_at > github_synced_at").
+		Order("updated_at").
+		Limit(githubPushBatchSize).
+		Find(&todos)
+	if result.Error != nil {
+		return 0, fmt.Errorf("反映するTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	for i, todo := range todos {
+		number, ok := s.issueNumber(*todo.GitHubIssue)
+		if !ok {
+			continue
+		}
+		state := "open"
+		if todo.Completed {
+			state = "closed"
+		}
+		if _, err := s.client.SetState(ctx, s.repository, number, state); err != nil {
+			if errors.Is(err, github.ErrNotFound) {
+				slog.WarnContext(ctx, "Issueが見つからないため紐付けを解除します", "todo_id", todo.ID, "issue", *todo.GitHubIssue)
+				if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("id = ?", todo.ID).
+					UpdateColumns(map[string]any{"github_issue": nil, "github_synced_at": nil}).Error; err != nil {
+					return i, fmt.Errorf("Issueとの紐付けの解除に失敗しました: %w", err)
+				}
+				continue
+			}
+			return i, fmt.Errorf("Todo %d のIssueへの反映に失敗しました: %w", todo.ID, err)
+		}
+
+		// 反映中に更新された場合は次回改めて反映するよう、読み込んだ時点の更新日時を記録する
+		if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("id = ?", todo.ID).
+			UpdateColumn("github_synced_at", todo.UpdatedAt).Error; err != nil {
+			return i, fmt.Errorf("反映済みの更新日時の保存に失敗しました: %w", err)
+		}
+	}
+	return len(todos), nil
+}
+
+// pull sinceより後に更新されたIssueを取り込み、次回用のsinceと取り込んだ件数を返す
+func (s *githubService) pull(ctx context.Context, since *time.Time) (*time.Time, int, error) {
+	var from time.Time
+	if since != nil {
+		from = *since
+	}
+
+	pulled := 0
+	for page := 1; ; page++ {
+		issues, next, err := s.client.ListIssues(ctx, s.repository, from, page)
+		if err != nil {
+			return since, pulled, fmt.Errorf("Issueの取得に失敗しました: %w", err)
+		}
+		for _, issue := range issues {
+			applied, err := s.applyIssue(ctx, issue)
+			if err != nil {
+				return since, pulled, err
+			}
+			if applied {
+				pulled++
+			}
+			if since == nil || issue.UpdatedAt.After(*since) {
+				updatedAt := issue.UpdatedAt
+				since = &updatedAt
+			}
+		}
+		if !next {
+			return since, pulled, nil
+		}
+	}
+}
+
+// applyIssue Issueを対応するTodoに反映（未作成のオープンなIssueはTodoを作成する）
+// Todo側に未反映の変更がある場合、完了状態はTodo側を優先する（次回の同期でIssueに反映される）
+func (s *githubService) applyIssue(ctx context.Context, issue *github.Issue) (bool, error) {
+	if issue.PullRequest != nil {
+		return false, nil
+	}
+	ref := s.issueRef(issue.Number)
+
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).Unscoped().Where("github_issue = ?", ref).Limit(1).Find(&todos)
+	if result.Error != nil {
+		return false, fmt.Errorf("Issueに対応するTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	title := truncateRunes(strings.TrimSpace(issue.Title), githubMaxTitleLength)
+	description := sanitize.OnSave(issue.Body)
+	tags := labelsToTags(issue.LabelNames())
+	now := time.Now()
+
+	if len(todos) == 0 {
+		// クローズ済みのIssueは取り込まない
+		if issue.Closed() {
+			return false, nil
+		}
+		todo := &model.Todo{
+			Title:          title,
+			Description:    description,
+			Priority:       model.PriorityMedium,
+			Tags:           tags,
+			GitHubIssue:    &ref,
+			GitHubSyncedAt: &now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.db.WithContext(ctx).Create(todo).Error; err != nil {
+			return false, fmt.Errorf("Issue %s からのTodoの作成に失敗しました: %w", ref, err)
+		}
+		events.PublishTodo(ctx, events.TodoCreated, todo)
+		return true, nil
+	}
+
+	todo := todos[0]
+	// 削除済みのTodoは作成し直さない
+	if todo.DeletedAt.Valid {
+		return false, nil
+	}
+
+	updates := map[string]any{}
+	if title != todo.Title {
+		updates["title"] = title
+	}
+	if description != todo.Description {
+		updates["description"] = description
+	}
+	if !slices.Equal(tags, todo.Tags) {
+		updates["tags"] = tags
+	}
+	pending := todo.GitHubSyncedAt == nil || todo.UpdatedAt.After(*todo.GitHubSyncedAt)
+	if !pending && issue.Closed() != todo.Completed {
+		updates["completed"] = issue.Closed()
+		updates["completed_at"] = completedAt(issue.Closed())
+	}
+	if len(updates) == 0 {
+		return false, nil
+	}
+
+	updates["updated_at"] = now
+	if !pending {
+		updates["github_synced_at"] = now
+	}
+	if err := s.db.WithContext(ctx).Model(todo).UpdateColumns(updates).Error; err != nil {
+		return false, fmt.Errorf("Todo %d へのIssueの変更の反映に失敗しました: %w", todo.ID, err)
+	}
+	events.PublishTodo(ctx, events.TodoUpdated, todo)
+	return true, nil
+}
+
+// issueRef TodoのカラムにIssueを記録する形式（owner/repo#番号）
+func (s *githubService) issueRef(number int) string {
+	return s.repository + "#" + strconv.Itoa(number)
+}
+
+// issueNumber 記録したIssueから番号を取り出す
+func (s *githubService) issueNumber(ref string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, s.repository+"#"))
+	return n, err == nil
+}
+
+// labelsToTags ラベルをタグに変換（上限を超える長さ・個数のラベルは取り込まない）
+func labelsToTags(labels []string) model.Tags {
+	var accepted []string
+	for _, label := range labels {
+		if utf8.RuneCountInString(strings.TrimSpace(label)) <= maxTagLength {
+			accepted = append(accepted, label)
+		}
+	}
+	if len(accepted) > maxTags {
+		accepted = accepted[:maxTags]
+	}
+	tags, _ := NormalizeTags(accepted)
+	return tags
+}
+
+// truncateRunes 文字数の上限で切り詰める
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/cache"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/sanitize"
+	"myapp/tracing"
+	"myapp/workerpool"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 取り込みの定数
+const (
+	// importMaxTitleLength タイトルの上限（APIのバリデーションと同じ）
+	importMaxTitleLength = 255
+)
+
+// 取り込みのエラー
+var (
+	// ErrImportNotFound 指定したIDの取り込みが存在しない
+	ErrImportNotFound = errcode.New(errcode.ImportNotFound, "取り込みが見つかりません")
+	// ErrImportEmpty 取り込むタスクがない
+	ErrImportEmpty = errcode.New(errcode.ImportEmpty, "取り込むタスクがありません")
+)
+
+// ImportService 外部サービスからTodoを取り込むサービスのインターフェース
+type ImportService interface {
+	Start(ctx context.Context, source string, tasks []model.ImportTask) (*model.ImportJob, error)
+	GetImport(ctx context.Context, id uint) (*model.ImportJob, error)
+	Wait(ctx context.Context) error
+}
+
+// importService 取り込みサービスの実装
+type importService struct {
+	db *gorm.DB
+	// concurrency バッチを並列に取り込むワーカー数
+	concurrency int
+	// batchSize 重複確認・作成をまとめて行う件数（1回のINSERTにまとめる件数。進捗もこの単位で更新する）
+	batchSize int
+
+	// running 実行中の取り込み（シャットダウン時に完了を待つ）
+	running sync.WaitGroup
+}
+
+// NewImportService 新しい取り込みサービスインスタンスを作成
+func NewImportService(concurrency, batchSize int) ImportService {
+	return &importService{
+		db:          db.GetDB(),
+		concurrency: concurrency,
+		batchSize:   batchSize,
+	}
+}
+
+// Start 取り込み元（todoist等）のパーサーが変換したタスクの取り込みを開始（進捗はGetImportで確認する）
+func (s *importService) Start(ctx context.Context, source string, tasks []model.ImportTask) (*model.ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Start", tracing.SpanKindInternal)
+	defer span.End()
+
+	job := &model.ImportJob{
+		Source:   source,
+		Status:   model.ImportStatusRunning,
+		Total:    len(tasks),
+		Messages: []string{},
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("取り込みの開始に失敗しました: %w", err)
+	}
+
+	// リクエストの完了後も続けるためキャンセルを引き継がない
+	runCtx := context.WithoutCancel(ctx)
+	started := *job
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(runCtx, &started, tasks)
+	}()
+	return job, nil
+}
+
+// GetImport 取り込みの進捗を取得
+func (s *importService) GetImport(ctx context.Context, id uint) (*model.ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.GetImport", tracing.SpanKindInternal)
+	defer span.End()
+
+	var job model.ImportJob
+	if err := s.db.WithContext(ctx).Take(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportNotFound
+		}
+		return nil, fmt.Errorf("取り込みの取得に失敗しました: %w", err)
+	}
+	return &job, nil
+}
+
+// Wait 実行中の取り込みが完了するまで待つ（シャットダウン用）
+func (s *importService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run バッチごとに重複を除いてTodoを作成し、進捗を保存する
+// バッチはワーカープールで並列に取り込み、いずれかのバッチが失敗した場合は未着手のバッチを取り込まない
+func (s *importService) run(ctx context.Context, job *model.ImportJob, tasks []model.ImportTask) {
+	ctx, span := tracing.Start(ctx, "ImportService.run", tracing.SpanKindInternal)
+	defer span.End()
+
+	poolCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 取り込むタスク同士の重複も検出する（seen・jobはバッチ間で共有するためmuで保護する）
+	var mu sync.Mutex
+	seen := make(map[string]bool, len(tasks))
+	var failure error
+	workerpool.Run(poolCtx, s.concurrency, workerpool.Chunks(tasks, s.batchSize), func(ctx context.Context, batch []model.ImportTask) {
+		err := s.importBatch(ctx, job, batch, seen, &mu)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if failure == nil {
+				failure = err
+				cancel()
+			}
+			return
+		}
+		if err := s.saveProgress(ctx, job); err != nil {
+			slog.WarnContext(ctx, "取り込みの進捗の保存に失敗しました", "import_id", job.ID, "error", err)
+		}
+	})
+	if failure != nil {
+		slog.ErrorContext(ctx, "取り込みに失敗しました", "import_id", job.ID, "source", job.Source, "error", failure)
+		job.Status = model.ImportStatusFailed
+		job.AddMessage(failure.Error())
+		s.finish(ctx, job)
+		return
+	}
+
+	job.Status = model.ImportStatusCompleted
+	s.finish(ctx, job)
+	slog.InfoContext(ctx, "取り込みが完了しました",
+		"import_id", job.ID, "source", job.Source, "imported", job.Imported, "duplicates", job.Duplicates, "failed", job.Failed)
+}
+
+// importBatch 1バッチ分のタスクを取り込む（重複の判定と件数の更新はmuを取得して行う）
+func (s *importService) importBatch(ctx context.Context, job *model.ImportJob, batch []model.ImportTask, seen map[string]bool, mu *sync.Mutex) error {
+	titles := make([]string, 0, len(batch))
+	for _, task := range batch {
+		titles = append(titles, strings.TrimSpace(task.Title))
+	}
+
+	// 同じタイトルの既存Todoを期限ごとに確認する
+	var existing []*model.Todo
+	if err := s.db.WithContext(ctx).Select("title", "due_date").Where("title IN ?", titles).Find(&existing).Error; err != nil {
+		return fmt.Errorf("既存のTodoの確認に失敗しました: %w", err)
+	}
+
+	mu.Lock()
+	for _, todo := range existing {
+		seen[duplicateKey(todo.Title, todo.DueDate)] = true
+	}
+
+	var todos []*model.Todo
+	for _, task := range batch {
+		job.Processed++
+		title := strings.TrimSpace(task.Title)
+		switch {
+		case title == "":
+			job.Failed++
+			job.AddMessage(fmt.Sprintf("%s: タイトルが空です", task.Ref))
+			continue
+		case utf8.RuneCountInString(title) > importMaxTitleLength:
+			job.Failed++
+			job.AddMessage(fmt.Sprintf("%s: タイトルが%d文字を超えています", task.Ref, importMaxTitleLength))
+			continue
+		}
+
+		key := duplicateKey(title, task.Due)
+		if seen[key] {
+			job.Duplicates++
+			continue
+		}
+		seen[key] = true
+
+		if task.UnparsedDue != "" {
+			job.AddMessage(fmt.Sprintf("%s: 期限「%s」を解釈できないため期限なしで取り込みました", task.Ref, task.UnparsedDue))
+		}
+
+		todos = append(todos, &model.Todo{
+			Title:       title,
+			Description: sanitize.OnSave(importDescription(task)),
+			Priority:    task.Priority,
+			DueDate:     task.Due,
+			Completed:   task.Completed,
+		})
+	}
+	mu.Unlock()
+
+	if len(todos) > 0 {
+		// バッチ内のTodoは1つのトランザクションで複数行のINSERTにまとめて作成する
+		err := cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+			return tx.CreateInBatches(&todos, s.batchSize).Error
+		})
+		if err != nil {
+			return fmt.Errorf("Todoの作成に失敗しました: %w", err)
+		}
+		mu.Lock()
+		job.Imported += len(todos)
+		mu.Unlock()
+		for _, todo := range todos {
+			events.PublishTodo(ctx, events.TodoCreated, todo)
+		}
+	}
+	return nil
+}
+
+// saveProgress 進捗を保存
+func (s *importService) saveProgress(ctx context.Context, job *model.ImportJob) error {
+	return s.db.WithContext(ctx).Model(job).Select("processed", "imported", "duplicates", "failed", "messages").Updates(job).Error
+}
+
+// finish 終了状態を保存
+func (s *importService) finish(ctx context.Context, job *model.ImportJob) {
+	now := time.Now()
+	job.FinishedAt = &now
+	err := s.db.WithContext(ctx).Model(job).
+		Select("status", "processed", "imported", "duplicates", "failed", "messages", "finished_at").
+		Updates(job).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "取り込みの結果の保存に失敗しました", "import_id", job.ID, "error", err)
+	}
+}
+
+// duplicateKey 重複判定のキー（タイトルと期限が一致するTodoを重複とみなす）
+func duplicateKey(title string, due *time.Time) string {
+	if due == nil {
+		return title + "\x00"
+	}
+	return title + "\x00" + due.UTC().Format(time.RFC3339)
+}
+
+// importDescription 説明にサブタスクとプロジェクトを追記する（Todoにはサブタスク・プロジェクトがないため）
+func importDescription(task model.ImportTask) string {
+	var sections []string
+	if description := strings.TrimSpace(task.Description); description != "" {
+		sections = append(sections, description)
+	}
+	if len(task.Subtasks) > 0 {
+		lines := make([]string, 0, len(task.Subtasks))
+		for _, subtask := range task.Subtasks {
+			mark := " "
+			if subtask.Completed {
+				mark = "x"
+			}
+			lines = append(lines, fmt.Sprintf("- [%s] %s", mark, subtask.Title))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if task.Project != "" {
+		sections = append(sections, "プロジェクト: "+task.Project)
+	}
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/jira"
+	"myapp/jobs"
+	"myapp/sanitize"
+	"myapp/tracing"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Jira連携の定数
+const (
+	// jiraPushBatchSize 1回の同期で課題に反映するTodoの上限
+	jiraPushBatchSize = 200
+	// jiraSyncLease 同期中のロックの期限（同期が異常終了した場合はこの時間で解放される）
+	jiraSyncLease = 10 * time.Minute
+	// jiraPullMargin 前回の取り込みとの間に更新された課題を取りこぼさないための余裕
+	jiraPullMargin = 2 * time.Minute
+	// jiraMaxTitleLength タイトルの上限（APIのバリデーションと同じ）
+	jiraMaxTitleLength = 255
+)
+
+// ErrJiraSyncRunning 別のインスタンスで同期中
+var ErrJiraSyncRunning = errcode.New(errcode.SyncRunning, "別のインスタンスで同期中です")
+
+// JiraOptions 取り込む課題の条件・完了時のトランジション・フィールドの対応
+type JiraOptions struct {
+	JQL              string
+	DoneTransition   string
+	ReopenTransition string
+	// 各項目に対応する課題のフィールドID（空の場合は取り込まない。Titleは必須）
+	TitleField       string
+	DescriptionField string
+	DueDateField     string
+	PriorityField    string
+	TagsField        string
+	// PriorityMap 優先度フィールドの値とTodoの優先度の対応（"Highest=urgent" の形式）
+	PriorityMap []string
+}
+
+// JiraService Jiraの課題とTodoを同期するサービスのインターフェース
+type JiraService interface {
+	Sync(ctx context.Context) (*model.JiraSyncResult, error)
+	WatchSync(ctx context.Context, interval time.Duration)
+}
+
+// jiraService Jira連携サービスの実装
+type jiraService struct {
+	db         *gorm.DB
+	client     *jira.Client
+	site       string
+	opts       JiraOptions
+	priorities map[string]model.Priority
+}
+
+// NewJiraService 新しいJira連携サービスインスタンスを作成（siteは同期状態を記録するキーとなるサイトのURL）
+func NewJiraService(client *jira.Client, site string, opts JiraOptions) JiraService {
+	priorities := make(map[string]model.Priority, len(opts.PriorityMap))
+	for _, entry := range opts.PriorityMap {
+		if name, priority, ok := strings.Cut(entry, "="); ok {
+			priorities[strings.ToLower(strings.TrimSpace(name))] = model.Priority(strings.TrimSpace(priority))
+		}
+	}
+	return &jiraService{
+		db:         db.GetDB(),
+		client:     client,
+		site:       site,
+		opts:       opts,
+		priorities: priorities,
+	}
+}
+
+// Sync 完了・未完了に戻したTodoの課題でトランジションを実行した後、前回以降に更新された課題を取り込む
+func (s *jiraService) Sync(ctx context.Context) (*model.JiraSyncResult, error) {
+	ctx, span := tracing.Start(ctx, "JiraService.Sync", tracing.SpanKindInternal)
+	defer span.End()
+
+	state, err := s.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(ctx)
+
+	result := &model.JiraSyncResult{}
+	if result.Pushed, err = s.push(ctx); err != nil {
+		return result, err
+	}
+
+	started := time.Now()
+	if result.Pulled, err = s.pull(ctx, state.PulledAt); err != nil {
+		return result, err
+	}
+
+	err = s.db.WithContext(ctx).Model(&model.JiraSyncState{}).
+		Where("site = ?", s.site).
+		Updates(map[string]any{"pulled_at": started, "last_synced_at": time.Now()}).Error
+	if err != nil {
+		return result, fmt.Errorf("同期状態の保存に失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// WatchSync intervalごとに同期する（ctxがキャンセルされるまでブロック）
+func (s *jiraService) WatchSync(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("jira-sync", "Jiraの課題との差分同期", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, func(ctx context.Context) error {
+				_, err := s.Sync(ctx)
+				if errors.Is(err, ErrJiraSyncRunning) {
+					return nil
+				}
+				return err
+			})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lock 同期状態の行にロックの期限を設定（他のインスタンスが同期中の場合はErrJiraSyncRunning）
+func (s *jiraService) lock(ctx context.Context) (*model.JiraSyncState, error) {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.JiraSyncState{Site: s.site}).Error
+	if err != nil {
+		return nil, fmt.Errorf("同期状態の作成に失敗しました: %w", err)
+	}
+
+	now := time.Now()
+	var states []*model.JiraSyncState
+	result := s.db.WithContext(ctx).Model(&states).
+		Clauses(clause.Returning{}).
+		Where("site = ? AND (locked_until IS NULL OR locked_until < ?)", s.site, now).
+		UpdateColumn("locked_until", now.Add(jiraSyncLease))
+	if result.Error != nil {
+		return nil, fmt.Errorf("同期のロックに失敗しました: %w", result.Error)
+	}
+	if len(states) == 0 {
+		return nil, ErrJiraSyncRunning
+	}
+	return states[0], nil
+}
+
+// unlock 同期のロックを解放
+func (s *jiraService) unlock(ctx context.Context) {
+	s.db.WithContext(context.WithoutCancel(ctx)).Model(&model.JiraSyncState{}).
+		Where("site = ?", s.site).
+		UpdateColumn("locked_until", nil)
+}
+
+// push 前回の反映以降に更新されたTodoの完了状態を課題のトランジションで反映し、トランジションを実行した件数を返す
+func (s *jiraService) push(ctx context.Context) (int, error) {
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select("id", "completed", "updated_at", "jira_issue").
+		Where("jira_issue IS NOT NULL").
+		Where("jira_synced_at IS NULL OR updated_at > jira_synced_at").
+		Order("updated_at").
+		Limit(jiraPushBatchSize).
+		Find(&todos)
+	if result.Error != nil {
+		return 0, fmt.Errorf("反映するTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	pushed := 0
+	for _, todo := range todos {
+		key := *todo.JiraIssue
+		issue, err := s.client.GetIssue(ctx, key, []string{"status"})
+		if errors.Is(err, jira.ErrNotFound) {
+			slog.WarnContext(ctx, "課題が見つからないため紐付けを解除します", "todo_id", todo.ID, "issue", key)
+			if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("id = ?", todo.ID).
+				UpdateColumns(map[string]any{"jira_issue": nil, "jira_synced_at": nil}).Error; err != nil {
+				return pushed, fmt.Errorf("課題との紐付けの解除に失敗しました: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return pushed, fmt.Errorf("課題 %s の取得に失敗しました: %w", key, err)
+		}
+
+		transition := ""
+		switch {
+		case todo.Completed && !issue.Done():
+			transition = s.opts.DoneTransition
+		case !todo.Completed && issue.Done():
+			transition = s.opts.ReopenTransition
+		}
+		if transition != "" {
+			err := s.client.DoTransition(ctx, key, transition)
+			switch {
+			case errors.Is(err, jira.ErrTransitionUnavailable):
+				// ワークフロー上実行できない場合は再試行しても同じため、反映済みとして扱う
+				slog.WarnContext(ctx, "課題のトランジションを実行できません", "todo_id", todo.ID, "issue", key, "transition", transition)
+			case err != nil:
+				return pushed, fmt.Errorf("課題 %s のトランジションの実行に失敗しました: %w", key, err)
+			default:
+				pushed++
+			}
+		}
+
+		// 反映中に更新された場合は次回改めて反映するよう、読み込んだ時点の更新日時を記録する
+		if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("id = ?", todo.ID).
+			UpdateColumn("jira_synced_at", todo.UpdatedAt).Error; err != nil {
+			return pushed, fmt.Errorf("反映済みの更新日時の保存に失敗しました: %w", err)
+		}
+	}
+	return pushed, nil
+}
+
+// pull JQLに一致する課題のうちpulledAt以降に更新されたものを取り込み、取り込んだ件数を返す
+func (s *jiraService) pull(ctx context.Context, pulledAt *time.Time) (int, error) {
+	jql := "(" + s.opts.JQL + ")"
+	if pulledAt != nil {
+		// JQLの日時はJira側のユーザーのタイムゾーンで解釈されるため、相対指定（-N分）で絞り込む
+		minutes := int(math.Ceil((time.Since(*pulledAt) + jiraPullMargin).Minutes()))
+		jql += fmt.Sprintf(" AND updated >= \"-%dm\"", minutes)
+	}
+	jql += " ORDER BY updated ASC"
+
+	fields := []string{"status"}
+	for _, field := range []string{s.opts.TitleField, s.opts.DescriptionField, s.opts.DueDateField, s.opts.PriorityField, s.opts.TagsField} {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	pulled := 0
+	for startAt := 0; ; {
+		issues, next, err := s.client.Search(ctx, jql, fields, startAt)
+		if err != nil {
+			return pulled, fmt.Errorf("課題の検索に失敗しました: %w", err)
+		}
+		for _, issue := range issues {
+			applied, err := s.applyIssue(ctx, issue)
+			if err != nil {
+				return pulled, err
+			}
+			if applied {
+				pulled++
+			}
+		}
+		if !next {
+			return pulled, nil
+		}
+		startAt += len(issues)
+	}
+}
+
+// applyIssue 課題を対応するTodoに反映（未作成の未完了の課題はTodoを作成する）
+// Todo側に未反映の変更がある場合、完了状態はTodo側を優先する（次回の同期で課題に反映される）
+func (s *jiraService) applyIssue(ctx context.Context, issue *jira.Issue) (bool, error) {
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).Unscoped().Where("jira_issue = ?", issue.Key).Limit(1).Find(&todos)
+	if result.Error != nil {
+		return false, fmt.Errorf("課題に対応するTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	// 対応付けた項目のみ反映する
+	values := map[string]any{}
+	title := truncateRunes(strings.TrimSpace(issue.Text(s.opts.TitleField)), jiraMaxTitleLength)
+	if title == "" {
+		title = issue.Key
+	}
+	values["title"] = title
+	if s.opts.DescriptionField != "" {
+		values["description"] = sanitize.OnSave(issue.Text(s.opts.DescriptionField))
+	}
+	if s.opts.DueDateField != "" {
+		var due *time.Time
+		if t, err := jira.ParseTime(issue.Text(s.opts.DueDateField)); err == nil {
+			due = &t
+		}
+		values["due_date"] = due
+	}
+	if s.opts.PriorityField != "" {
+		values["priority"] = s.priority(issue.Text(s.opts.PriorityField))
+	}
+	if s.opts.TagsField != "" {
+		values["tags"] = labelsToTags(issue.Strings(s.opts.TagsField))
+	}
+	now := time.Now()
+
+	if len(todos) == 0 {
+		// 完了済みの課題は取り込まない
+		if issue.Done() {
+			return false, nil
+		}
+		todo := &model.Todo{
+			Title:        title,
+			Priority:     model.PriorityMedium,
+			JiraIssue:    &issue.Key,
+			JiraSyncedAt: &now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if description, ok := values["description"].(string); ok {
+			todo.Description = description
+		}
+		if due, ok := values["due_date"].(*time.Time); ok {
+			todo.DueDate = due
+		}
+		if priority, ok := values["priority"].(model.Priority); ok {
+			todo.Priority = priority
+		}
+		if tags, ok := values["tags"].(model.Tags); ok {
+			todo.Tags = tags
+		}
+		if err := s.db.WithContext(ctx).Create(todo).Error; err != nil {
+			return false, fmt.Errorf("課題 %s からのTodoの作成に失敗しました: %w", issue.Key, err)
+		}
+		events.PublishTodo(ctx, events.TodoCreated, todo)
+		return true, nil
+	}
+
+	todo := todos[0]
+	// 削除済みのTodoは作成し直さない
+	if todo.DeletedAt.Valid {
+		return false, nil
+	}
+
+	updates := map[string]any{}
+	if values["title"] != todo.Title {
+		updates["title"] = values["title"]
+	}
+	if description, ok := values["description"].(string); ok && description != todo.Description {
+		updates["description"] = description
+	}
+	if due, ok := values["due_date"].(*time.Time); ok {
+		if (due == nil) != (todo.DueDate == nil) || (due != nil && !due.Equal(*todo.DueDate)) {
+			updates["due_date"] = due
+		}
+	}
+	if priority, ok := values["priority"].(model.Priority); ok && priority != todo.Priority {
+		updates["priority"] = priority
+	}
+	if tags, ok := values["tags"].(model.Tags); ok && !slices.Equal(tags, todo.Tags) {
+		updates["tags"] = tags
+	}
+	pending := todo.JiraSyncedAt == nil || todo.UpdatedAt.After(*todo.JiraSyncedAt)
+	if !pending && issue.Done() != todo.Completed {
+		updates["completed"] = issue.Done()
+		updates["completed_at"] = completedAt(issue.Done())
+	}
+	if len(updates) == 0 {
+		return false, nil
+	}
+
+	updates["updated_at"] = now
+	if !pending {
+		updates["jira_synced_at"] = now
+	}
+	if err := s.db.WithContext(ctx).Model(todo).UpdateColumns(updates).Error; err != nil {
+		return false, fmt.Errorf("Todo %d への課題の変更の反映に失敗しました: %w", todo.ID, err)
+	}
+	events.PublishTodo(ctx, events.TodoUpdated, todo)
+	return true, nil
+}
+
+// priority 優先度フィールドの値をTodoの優先度に変換（対応のない値は medium）
+func (s *jiraService) priority(value string) model.Priority {
+	if priority, ok := s.priorities[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return priority
+	}
+	return model.PriorityMedium
+}
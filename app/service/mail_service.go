@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/llm"
+	"myapp/queue"
+	"myapp/tracing"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 受信メールから作成するTodoの制限
+const (
+	// mailTitleMaxLength タイトルの最大文字数
+	mailTitleMaxLength = 255
+	// mailDescriptionMaxLength 説明（本文）の最大文字数
+	mailDescriptionMaxLength = 10000
+	// mailPromptMaxLength LLMに渡す本文の最大文字数
+	mailPromptMaxLength = 4000
+)
+
+// ErrMailDuplicate 同じメールから作成済み
+var ErrMailDuplicate = errcode.New(errcode.MailDuplicate, "このメールからは作成済みです")
+
+// mailExtractPrompt 期限・優先度を抽出するシステムプロンプト
+const mailExtractPrompt = `あなたはメールからタスクの情報を抽出するアシスタントです。
+メールの件名と本文から、タスクの期限と優先度を読み取り、次の形式のJSONだけを返してください。
+{"due_date": "期限（RFC 3339形式、タイムゾーン付き。期限が読み取れない場合はnull）", "priority": "low / medium / high / urgent のいずれか"}
+「明日」「来週金曜」などの相対的な表現は、与えられた現在日時を基準に解釈してください。時刻の指定がない場合は 18:00 としてください。
+優先度は「至急」「緊急」などの表現があれば urgent、期限が近い・重要と明記されていれば high、特に手がかりがなければ medium としてください。`
+
+// MailExtractJobKind 受信メールから作成したTodoに、LLMで抽出した期限・優先度を設定するジョブの種類
+const MailExtractJobKind = "mail.extract"
+
+// MailService 受信メールからTodoを作成するサービスのインターフェース
+type MailService interface {
+	// CreateTodo メールからTodoを作成（同じメールから作成済みの場合はErrMailDuplicate）
+	CreateTodo(ctx context.Context, req *model.InboundMailRequest) (*model.Todo, error)
+	// HandleExtractJob ジョブキューから期限・優先度の抽出を実行する
+	HandleExtractJob(ctx context.Context, payload json.RawMessage) error
+}
+
+// mailService 受信メールサービスの実装
+type mailService struct {
+	db          *gorm.DB
+	todoService TodoService
+	// extractor 期限・優先度を抽出するLLM（nilの場合は抽出しない）
+	extractor *llm.Client
+	// jobs 抽出をジョブキューで非同期に行う場合のキュー（nilの場合は作成時に抽出する）
+	jobs queue.Enqueuer
+	// profiles LLMに渡す現在日時のタイムゾーン（「明日」等を利用者のタイムゾーンで解釈させる）
+	profiles ProfileService
+}
+
+// mailExtractJob 期限・優先度の抽出ジョブの内容
+type mailExtractJob struct {
+	TodoID     uint      `json:"todo_id"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// NewMailService 新しい受信メールサービスインスタンスを作成（extractorがnilの場合は期限・優先度を抽出しない）
+// jobsを指定した場合は、Todoを先に作成してから期限・優先度の抽出をジョブキューで行う（LLMの障害時も再試行される）
+func NewMailService(todoService TodoService, extractor *llm.Client, jobs queue.Enqueuer, profiles ProfileService) MailService {
+	return &mailService{
+		db:          db.GetDB(),
+		todoService: todoService,
+		extractor:   extractor,
+		jobs:        jobs,
+		profiles:    profiles,
+	}
+}
+
+// CreateTodo 件名をタイトル、本文を説明としてTodoを作成
+// 複数インスタンスが同じメールボックスを処理しても重複しないよう、先にメールの記録を作成してから作成する
+func (s *mailService) CreateTodo(ctx context.Context, req *model.InboundMailRequest) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "MailService.CreateTodo", tracing.SpanKindInternal)
+	defer span.End()
+
+	record := &model.InboundMail{MessageKey: req.MessageKey, Sender: req.Sender}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, fmt.Errorf("受信メールの記録に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrMailDuplicate
+	}
+
+	create := &model.TodoCreateRequest{
+		Title:       mailTitle(req.Subject, req.Body),
+		Description: truncateRunes(strings.TrimSpace(req.Body), mailDescriptionMaxLength),
+	}
+	if s.extractor != nil && s.jobs == nil {
+		// 失敗した場合はメールを失わないよう抽出せずに作成する
+		if priority, due, err := s.extract(ctx, req.Subject, req.Body, req.ReceivedAt); err != nil {
+			slog.WarnContext(ctx, "LLMによる期限・優先度の抽出に失敗しました", "error", err)
+		} else {
+			create.Priority = priority
+			create.DueDate = due
+		}
+	}
+
+	todo, err := s.todoService.CreateTodo(ctx, create)
+	if err != nil {
+		// 再試行できるよう記録を取り消す
+		if err := s.db.WithContext(ctx).Delete(record).Error; err != nil {
+			slog.ErrorContext(ctx, "受信メールの記録の取り消しに失敗しました", "error", err)
+		}
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(record).UpdateColumn("todo_id", todo.ID).Error; err != nil {
+		slog.ErrorContext(ctx, "受信メールの記録の更新に失敗しました", "todo_id", todo.ID, "error", err)
+	}
+	if s.extractor != nil && s.jobs != nil {
+		job := mailExtractJob{TodoID: todo.ID, Subject: req.Subject, Body: truncateRunes(req.Body, mailPromptMaxLength), ReceivedAt: req.ReceivedAt}
+		if _, err := s.jobs.Enqueue(ctx, MailExtractJobKind, job); err != nil {
+			slog.ErrorContext(ctx, "期限・優先度の抽出ジョブの登録に失敗しました", "todo_id", todo.ID, "error", err)
+		}
+	}
+	return todo, nil
+}
+
+// HandleExtractJob LLMで期限・優先度を抽出し、作成済みのTodoに設定する
+// LLMの呼び出しに失敗した場合は再試行し、応答を解釈できない・Todoが削除された場合は再試行しない
+func (s *mailService) HandleExtractJob(ctx context.Context, payload json.RawMessage) error {
+	var job mailExtractJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	if s.extractor == nil {
+		return queue.Permanent(errors.New("LLMが設定されていません"))
+	}
+
+	priority, due, err := s.extract(ctx, job.Subject, job.Body, job.ReceivedAt)
+	if err != nil {
+		var parseErr *mailParseError
+		if errors.As(err, &parseErr) {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+	if priority == "" && due == nil {
+		return nil
+	}
+
+	update := &model.TodoUpdateRequest{DueDate: due}
+	if priority != "" {
+		update.Priority = &priority
+	}
+	if _, err := s.todoService.UpdateTodo(ctx, job.TodoID, update); err != nil {
+		if errors.Is(err, ErrTodoNotFound) {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// mailParseError LLMの応答を解釈できない（再試行しても同じ結果になる見込みが高い）
+type mailParseError struct {
+	err error
+}
+
+func (e *mailParseError) Error() string {
+	return "LLMの応答を解釈できません: " + e.err.Error()
+}
+
+func (e *mailParseError) Unwrap() error {
+	return e.err
+}
+
+// extract LLMで件名・本文から優先度と期限を抽出する（読み取れなかった項目はゼロ値）
+func (s *mailService) extract(ctx context.Context, subject, body string, receivedAt time.Time) (model.Priority, *time.Time, error) {
+	now := receivedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	prompt := fmt.Sprintf("現在日時: %s\n件名: %s\n\n%s",
+		now.In(s.profiles.Location(ctx)).Format(time.RFC3339+" (Monday)"),
+		subject,
+		truncateRunes(body, mailPromptMaxLength),
+	)
+
+	text, err := s.extractor.Complete(ctx, mailExtractPrompt, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	raw, err := llm.ExtractJSON(text)
+	if err != nil {
+		return "", nil, &mailParseError{err: err}
+	}
+	var fields struct {
+		DueDate  *string `json:"due_date"`
+		Priority string  `json:"priority"`
+	}
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return "", nil, &mailParseError{err: err}
+	}
+
+	priority := model.Priority(strings.ToLower(strings.TrimSpace(fields.Priority)))
+	if !priority.IsValid() {
+		priority = ""
+	}
+	var due *time.Time
+	if fields.DueDate != nil && *fields.DueDate != "" {
+		parsed, err := time.Parse(time.RFC3339, *fields.DueDate)
+		if err != nil {
+			// 期限を解釈できない場合も優先度は設定する
+			slog.WarnContext(ctx, "LLMが返した期限を解釈できません", "due_date", *fields.DueDate)
+		} else {
+			due = &parsed
+		}
+	}
+	return priority, due, nil
+}
+
+// mailTitle 件名から転送・返信の接頭辞を除いてタイトルにする（件名がない場合は本文の1行目）
+func mailTitle(subject, body string) string {
+	title := strings.TrimSpace(subject)
+	for {
+		trimmed := title
+		for _, prefix := range []string{"Fwd:", "FWD:", "Fw:", "FW:", "Re:", "RE:", "転送:", "返信:"} {
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, prefix))
+		}
+		if trimmed == title {
+			break
+		}
+		title = trimmed
+	}
+	if title == "" {
+		title, _, _ = strings.Cut(strings.TrimSpace(body), "\n")
+		title = strings.TrimSpace(title)
+	}
+	if title == "" {
+		title = "（件名なし）"
+	}
+	return truncateRunes(title, mailTitleMaxLength)
+}
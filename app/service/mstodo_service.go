@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/mstodo"
+	"myapp/tracing"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// microsoftProvider oauth_tokens に保存する連携先名
+const microsoftProvider = "microsoft"
+
+// mstodoImportSource 取り込み元の名前
+const mstodoImportSource = "microsoft_todo"
+
+// Microsoft To Do連携のエラー
+var (
+	ErrMSTodoNotConnected = errcode.New(errcode.NotConnected, "Microsoft To Doと連携されていません")
+	ErrMSTodoListNotFound = errcode.New(errcode.ListNotFound, "指定したリストが見つかりません")
+)
+
+// MSTodoService Microsoft To Doのタスクを取り込むサービスのインターフェース
+type MSTodoService interface {
+	AuthURL(ctx context.Context) (string, error)
+	HandleCallback(ctx context.Context, code, state string) error
+	Status(ctx context.Context) (*model.MSTodoStatus, error)
+	Disconnect(ctx context.Context) error
+	Lists(ctx context.Context) ([]model.MSTodoList, error)
+	Import(ctx context.Context, opts model.MSTodoImportOptions) (*model.ImportJob, error)
+}
+
+// mstodoService Microsoft To Do連携サービスの実装
+type mstodoService struct {
+	db            *gorm.DB
+	oauth         *oauth2.Config
+	client        *mstodo.Client
+	importService ImportService
+	// projects リスト名と取り込み先のプロジェクト名の対応（設定で指定したもの）
+	projects map[string]string
+
+	// tokenMu アクセストークンの更新を直列化する
+	tokenMu sync.Mutex
+}
+
+// NewMSTodoService 新しいMicrosoft To Do連携サービスインスタンスを作成
+// listProjectsは "リスト名=プロジェクト名" の形式（プロジェクト名を空にするとプロジェクトなし）
+func NewMSTodoService(oauth *oauth2.Config, importService ImportService, listProjects []string) MSTodoService {
+	projects := make(map[string]string, len(listProjects))
+	for _, entry := range listProjects {
+		if name, project, ok := strings.Cut(entry, "="); ok {
+			projects[strings.TrimSpace(name)] = strings.TrimSpace(project)
+		}
+	}
+	s := &mstodoService{
+		db:            db.GetDB(),
+		oauth:         oauth,
+		importService: importService,
+		projects:      projects,
+	}
+	s.client = mstodo.NewClient(s.accessToken)
+	return s
+}
+
+// AuthURL 同意画面のURLを発行
+func (s *mstodoService) AuthURL(ctx context.Context) (string, error) {
+	state, err := issueOAuthState(ctx, s.db, microsoftProvider)
+	if err != nil {
+		return "", err
+	}
+	return s.oauth.AuthCodeURL(state, mstodo.AuthCodeOptions...), nil
+}
+
+// HandleCallback 同意画面から戻った認可コードをトークンに交換して保存
+func (s *mstodoService) HandleCallback(ctx context.Context, code, state string) error {
+	ctx, span := tracing.Start(ctx, "MSTodoService.HandleCallback", tracing.SpanKindInternal)
+	defer span.End()
+
+	if err := consumeOAuthState(ctx, s.db, microsoftProvider, state); err != nil {
+		return err
+	}
+
+	token, err := exchangeOAuthCode(ctx, s.oauth, code)
+	if err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		return errors.New("リフレッシュトークンを取得できませんでした。アプリの登録で offline_access を許可しているか確認してください")
+	}
+	return s.saveToken(ctx, token)
+}
+
+// Status 連携の状態を取得
+func (s *mstodoService) Status(ctx context.Context) (*model.MSTodoStatus, error) {
+	ctx, span := tracing.Start(ctx, "MSTodoService.Status", tracing.SpanKindInternal)
+	defer span.End()
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.OAuthToken{}).Where("provider = ?", microsoftProvider).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("連携状態の取得に失敗しました: %w", err)
+	}
+	return &model.MSTodoStatus{Connected: count > 0}, nil
+}
+
+// Disconnect トークンを削除する（取り込み済みのTodoは削除しない）
+func (s *mstodoService) Disconnect(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "MSTodoService.Disconnect", tracing.SpanKindInternal)
+	defer span.End()
+
+	if err := s.db.WithContext(ctx).Where("provider = ?", microsoftProvider).Delete(&model.OAuthToken{}).Error; err != nil {
+		return fmt.Errorf("トークンの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Lists リストの一覧を取り込み先のプロジェクトとともに取得
+func (s *mstodoService) Lists(ctx context.Context) ([]model.MSTodoList, error) {
+	ctx, span := tracing.Start(ctx, "MSTodoService.Lists", tracing.SpanKindInternal)
+	defer span.End()
+
+	lists, err := s.client.Lists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.MSTodoList, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, model.MSTodoList{
+			ID:        list.ID,
+			Name:      list.DisplayName,
+			Wellknown: list.WellknownListName,
+			Project:   s.project(list, nil),
+		})
+	}
+	return result, nil
+}
+
+// Import 指定したリストのタスクを取得して取り込みを開始（進捗はImportServiceで確認する）
+func (s *mstodoService) Import(ctx context.Context, opts model.MSTodoImportOptions) (*model.ImportJob, error) {
+	ctx, span := tracing.Start(ctx, "MSTodoService.Import", tracing.SpanKindInternal)
+	defer span.End()
+
+	lists, err := s.client.Lists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.ListIDs) > 0 {
+		byID := make(map[string]mstodo.TaskList, len(lists))
+		for _, list := range lists {
+			byID[list.ID] = list
+		}
+		selected := make([]mstodo.TaskList, 0, len(opts.ListIDs))
+		for _, id := range opts.ListIDs {
+			list, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrMSTodoListNotFound, id)
+			}
+			selected = append(selected, list)
+		}
+		lists = selected
+	}
+
+	var tasks []model.ImportTask
+	for _, list := range lists {
+		listTasks, err := s.client.Tasks(ctx, list.ID)
+		if err != nil {
+			return nil, fmt.Errorf("リスト「%s」のタスクの取得に失敗しました: %w", list.DisplayName, err)
+		}
+		tasks = append(tasks, mstodo.ToImportTasks(listTasks, s.project(list, opts.Projects), opts.IncludeCompleted)...)
+	}
+	if len(tasks) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return s.importService.Start(ctx, mstodoImportSource, tasks)
+}
+
+// project リストの取り込み先のプロジェクト名（リクエストの指定 → 設定の対応 → リスト名の順。IDでの指定はリスト名より優先する）
+func (s *mstodoService) project(list mstodo.TaskList, overrides map[string]string) string {
+	for _, projects := range []map[string]string{overrides, s.projects} {
+		if project, ok := projects[list.ID]; ok {
+			return project
+		}
+		if project, ok := projects[list.DisplayName]; ok {
+			return project
+		}
+	}
+	return list.DisplayName
+}
+
+// accessToken 有効なアクセストークンを返す（期限が近い場合は更新する）
+func (s *mstodoService) accessToken(ctx context.Context) (string, error) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	var stored model.OAuthToken
+	result := s.db.WithContext(ctx).Where("provider = ?", microsoftProvider).Limit(1).Find(&stored)
+	if result.Error != nil {
+		return "", fmt.Errorf("トークンの取得に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrMSTodoNotConnected
+	}
+	if time.Until(stored.Expiry) > tokenRefreshMargin {
+		return stored.AccessToken, nil
+	}
+
+	token, err := refreshOAuthToken(ctx, s.oauth, stored.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("アクセストークンの更新に失敗しました: %w", err)
+	}
+	if err := s.saveToken(ctx, token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// saveToken トークンを保存
+func (s *mstodoService) saveToken(ctx context.Context, token *oauth2.Token) error {
+	record := &model.OAuthToken{
+		Provider:     microsoftProvider,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}},
+		DoUpdates: clause.AssignmentColumns([]string{"access_token", "refresh_token", "expiry", "updated_at"}),
+	}).Create(record).Error
+	if err != nil {
+		return fmt.Errorf("トークンの保存に失敗しました: %w", err)
+	}
+	return nil
+}
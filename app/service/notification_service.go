@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/notify"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService 未完了Todoのダイジェストを通知するサービスのインターフェース
+// （期限切れ・期限間近のリマインダーは ReminderService が送信先ごとに配信する）
+type NotificationService interface {
+	SendDigest(ctx context.Context) error
+}
+
+// notificationService 通知サービスの実装
+type notificationService struct {
+	db *gorm.DB
+	// profiles 「今日」を数えるタイムゾーン（利用者のタイムゾーン）
+	profiles ProfileService
+}
+
+// NewNotificationService 新しい通知サービスインスタンスを作成
+func NewNotificationService(profiles ProfileService) NotificationService {
+	return &notificationService{
+		db:       db.GetDB(),
+		profiles: profiles,
+	}
+}
+
+// SendDigest 未完了Todoのダイジェスト（期限切れ・今日が期限・その他）を送信
+// 「今日」は利用者のタイムゾーンで数え、期限もそのタイムゾーンで表示する
+func (s *notificationService) SendDigest(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.SendDigest", tracing.SpanKindInternal)
+	defer span.End()
+
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("completed = ?", false).
+		Order("due_date IS NULL, due_date, created_at").
+		Find(&todos)
+	if result.Error != nil {
+		return fmt.Errorf("ダイジェスト対象のTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	loc := s.profiles.Location(ctx)
+	now := time.Now().In(loc)
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	digest := notify.Digest{GeneratedAt: now}
+	for _, todo := range todos {
+		overdue, dueToday := todo.DueBefore(now, loc), todo.DueBefore(endOfDay, loc)
+		todo.DueDate = todo.DueIn(loc)
+		switch {
+		case overdue:
+			digest.Overdue = append(digest.Overdue, *todo)
+		case dueToday:
+			digest.DueToday = append(digest.DueToday, *todo)
+		default:
+			digest.Pending = append(digest.Pending, *todo)
+		}
+	}
+
+	return notify.PublishDigest(ctx, digest)
+}
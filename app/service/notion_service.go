@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/jobs"
+	"myapp/notion"
+	"myapp/tracing"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notionエクスポートの定数
+const (
+	// notionBatchSize 1回のクエリで取得するTodoの件数
+	notionBatchSize = 100
+	// notionSyncLease 同期中のロックの期限（同期が異常終了した場合はこの時間で解放される）
+	notionSyncLease = 30 * time.Minute
+)
+
+// Notionエクスポートのエラー
+var (
+	ErrNotionSyncRunning   = errcode.New(errcode.SyncRunning, "別のインスタンスで同期中です")
+	ErrNotionInvalidSchema = errcode.New(errcode.InvalidSchema, "データベースのプロパティが設定と一致しません")
+)
+
+// NotionOptions エクスポート先のデータベースとプロパティの対応
+type NotionOptions struct {
+	DatabaseID string
+	// 各項目を書き込むプロパティ名（空の場合は書き込まない。Titleは必須）
+	TitleProperty       string
+	DescriptionProperty string
+	CompletedProperty   string
+	PriorityProperty    string
+	DueDateProperty     string
+	TagsProperty        string
+	IDProperty          string
+	// DoneStatus・TodoStatus 完了状態をステータス・セレクトのプロパティに書き込む場合の値
+	DoneStatus string
+	TodoStatus string
+}
+
+// NotionService TodoをNotionのデータベースへエクスポートするサービスのインターフェース
+type NotionService interface {
+	Sync(ctx context.Context, full bool) (*model.NotionSyncResult, error)
+	WatchSync(ctx context.Context, interval time.Duration)
+}
+
+// notionService Notionエクスポートサービスの実装
+type notionService struct {
+	db     *gorm.DB
+	client *notion.Client
+	opts   NotionOptions
+}
+
+// NewNotionService 新しいNotionエクスポートサービスインスタンスを作成
+func NewNotionService(client *notion.Client, opts NotionOptions) NotionService {
+	return &notionService{
+		db:     db.GetDB(),
+		client: client,
+		opts:   opts,
+	}
+}
+
+// Sync 前回以降に作成・更新されたTodoをページに反映し、削除されたTodoのページをアーカイブする（fullの場合は全てのTodoを反映し直す）
+func (s *notionService) Sync(ctx context.Context, full bool) (*model.NotionSyncResult, error) {
+	ctx, span := tracing.Start(ctx, "NotionService.Sync", tracing.SpanKindInternal)
+	defer span.End()
+
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.unlock(ctx)
+
+	// プロパティの種類に合わせて値を組み立てるため、毎回データベースの定義を取得する
+	database, err := s.client.GetDatabase(ctx, s.opts.DatabaseID)
+	if err != nil {
+		return nil, fmt.Errorf("データベースの取得に失敗しました: %w", err)
+	}
+	properties, err := s.resolveProperties(database)
+	if err != nil {
+		return nil, err
+	}
+
+	if full {
+		if err := s.db.WithContext(ctx).Model(&model.Todo{}).
+			Where("notion_synced_at IS NOT NULL").
+			UpdateColumn("notion_synced_at", nil).Error; err != nil {
+			return nil, fmt.Errorf("反映済みの更新日時のリセットに失敗しました: %w", err)
+		}
+	}
+
+	result := &model.NotionSyncResult{}
+	if err := s.export(ctx, properties, result); err != nil {
+		return result, err
+	}
+	if err := s.archive(ctx, result); err != nil {
+		return result, err
+	}
+
+	err = s.db.WithContext(ctx).Model(&model.NotionSyncState{}).
+		Where("database_id = ?", s.opts.DatabaseID).
+		UpdateColumn("last_synced_at", time.Now()).Error
+	if err != nil {
+		return result, fmt.Errorf("同期状態の保存に失敗しました: %w", err)
+	}
+	return result, nil
+}
+
+// WatchSync intervalごとに同期する（ctxがキャンセルされるまでブロック）
+func (s *notionService) WatchSync(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("notion-sync", "Notionのデータベースへの差分エクスポート", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, func(ctx context.Context) error {
+				_, err := s.Sync(ctx, false)
+				if errors.Is(err, ErrNotionSyncRunning) {
+					return nil
+				}
+				return err
+			})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lock 同期状態の行にロックの期限を設定（他のインスタンスが同期中の場合はErrNotionSyncRunning）
+func (s *notionService) lock(ctx context.Context) error {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.NotionSyncState{DatabaseID: s.opts.DatabaseID}).Error
+	if err != nil {
+		return fmt.Errorf("同期状態の作成に失敗しました: %w", err)
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.NotionSyncState{}).
+		Where("database_id = ? AND (locked_until IS NULL OR locked_until < ?)", s.opts.DatabaseID, now).
+		UpdateColumn("locked_until", now.Add(notionSyncLease))
+	if result.Error != nil {
+		return fmt.Errorf("同期のロックに失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotionSyncRunning
+	}
+	return nil
+}
+
+// unlock 同期のロックを解放
+func (s *notionService) unlock(ctx context.Context) {
+	s.db.WithContext(context.WithoutCancel(ctx)).Model(&model.NotionSyncState{}).
+		Where("database_id = ?", s.opts.DatabaseID).
+		UpdateColumn("locked_until", nil)
+}
+
+// notionProperty 書き込むプロパティとその種類
+type notionProperty struct {
+	name string
+	typ  string
+}
+
+// notionProperties Todoの各項目に対応するプロパティ（未設定の項目はnameが空）
+type notionProperties struct {
+	title, description, completed, priority, dueDate, tags, id notionProperty
+}
+
+// resolveProperties 設定したプロパティがデータベースに存在し、書き込める種類かを確認する
+func (s *notionService) resolveProperties(database *notion.Database) (*notionProperties, error) {
+	var errs []error
+	resolve := func(name string, allowed ...string) notionProperty {
+		if name == "" {
+			return notionProperty{}
+		}
+		prop, ok := database.Properties[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("プロパティ %q がありません", name))
+			return notionProperty{}
+		}
+		if !slices.Contains(allowed, prop.Type) {
+			errs = append(errs, fmt.Errorf("プロパティ %q の種類 %s には書き込めません（%s のいずれかにしてください）", name, prop.Type, strings.Join(allowed, " / ")))
+			return notionProperty{}
+		}
+		return notionProperty{name: name, typ: prop.Type}
+	}
+
+	props := &notionProperties{
+		title:       resolve(s.opts.TitleProperty, notion.TypeTitle),
+		description: resolve(s.opts.DescriptionProperty, notion.TypeRichText),
+		completed:   resolve(s.opts.CompletedProperty, notion.TypeCheckbox, notion.TypeStatus, notion.TypeSelect),
+		priority:    resolve(s.opts.PriorityProperty, notion.TypeSelect, notion.TypeStatus, notion.TypeRichText),
+		dueDate:     resolve(s.opts.DueDateProperty, notion.TypeDate),
+		tags:        resolve(s.opts.TagsProperty, notion.TypeMultiSelect, notion.TypeRichText),
+		id:          resolve(s.opts.IDProperty, notion.TypeNumber, notion.TypeRichText),
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrNotionInvalidSchema, errors.Join(errs...))
+	}
+	return props, nil
+}
+
+// pageProperties Todoをページのプロパティの値に変換
+func (s *notionService) pageProperties(props *notionProperties, todo *model.Todo) map[string]any {
+	values := map[string]any{
+		props.title.name: notion.Title(todo.Title),
+	}
+	if p := props.description; p.name != "" {
+		values[p.name] = notion.RichText(todo.Description)
+	}
+	if p := props.completed; p.name != "" {
+		status := s.opts.TodoStatus
+		if todo.Completed {
+			status = s.opts.DoneStatus
+		}
+		switch p.typ {
+		case notion.TypeCheckbox:
+			values[p.name] = notion.Checkbox(todo.Completed)
+		case notion.TypeStatus:
+			values[p.name] = notion.Status(status)
+		default:
+			values[p.name] = notion.Select(status)
+		}
+	}
+	if p := props.priority; p.name != "" {
+		switch p.typ {
+		case notion.TypeStatus:
+			values[p.name] = notion.Status(string(todo.Priority))
+		case notion.TypeRichText:
+			values[p.name] = notion.RichText(string(todo.Priority))
+		default:
+			values[p.name] = notion.Select(string(todo.Priority))
+		}
+	}
+	if p := props.dueDate; p.name != "" {
+		values[p.name] = notion.Date(todo.DueDate)
+	}
+	if p := props.tags; p.name != "" {
+		if p.typ == notion.TypeRichText {
+			values[p.name] = notion.RichText(strings.Join(todo.Tags, ", "))
+		} else {
+			values[p.name] = notion.MultiSelect(todo.Tags)
+		}
+	}
+	if p := props.id; p.name != "" {
+		if p.typ == notion.TypeRichText {
+			values[p.name] = notion.RichText(fmt.Sprint(todo.ID))
+		} else {
+			values[p.name] = notion.Number(float64(todo.ID))
+		}
+	}
+	return values
+}
+
+// export 前回の反映以降に作成・更新されたTodoをページに反映する
+func (s *notionService) export(ctx context.Context, props *notionProperties, result *model.NotionSyncResult) error {
+	for {
+		var todos []*model.Todo
+		err := s.db.WithContext(ctx).
+			Where("notion_synced_at IS NULL OR updated_at > notion_synced_at").
+			Order("updated_at").
+			Limit(notionBatchSize).
+			Find(&todos).Error
+		if err != nil {
+			return fmt.Errorf("反映するTodoの取得に失敗しました: %w", err)
+		}
+
+		for _, todo := range todos {
+			if err := s.exportTodo(ctx, props, todo, result); err != nil {
+				return err
+			}
+		}
+		if len(todos) < notionBatchSize {
+			return nil
+		}
+	}
+}
+
+// exportTodo 1件のTodoをページに反映（ページがNotion側で削除されていた場合は作成し直す）
+func (s *notionService) exportTodo(ctx context.Context, props *notionProperties, todo *model.Todo, result *model.NotionSyncResult) error {
+	values := s.pageProperties(props, todo)
+	updates := map[string]any{
+		// 反映中に更新された場合は次回改めて反映するよう、読み込んだ時点の更新日時を記録する
+		"notion_synced_at": todo.UpdatedAt,
+	}
+
+	created := false
+	if todo.NotionPageID != nil {
+		err := s.client.UpdatePage(ctx, *todo.NotionPageID, values)
+		if errors.Is(err, notion.ErrNotFound) {
+			slog.WarnContext(ctx, "ページが見つからないため作成し直します", "todo_id", todo.ID, "page_id", *todo.NotionPageID)
+			created = true
+		} else if err != nil {
+			return fmt.Errorf("Todo %d のページの更新に失敗しました: %w", todo.ID, err)
+		}
+	} else {
+		created = true
+	}
+
+	if created {
+		page, err := s.client.CreatePage(ctx, s.opts.DatabaseID, values)
+		if err != nil {
+			return fmt.Errorf("Todo %d のページの作成に失敗しました: %w", todo.ID, err)
+		}
+		updates["notion_page_id"] = page.ID
+		result.Created++
+	} else {
+		result.Updated++
+	}
+
+	if err := s.db.WithContext(ctx).Model(&model.Todo{}).Where("id = ?", todo.ID).
+		UpdateColumns(updates).Error; err != nil {
+		return fmt.Errorf("反映済みの更新日時の保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// archive 削除されたTodoのページをアーカイブし、ページとの紐付けを解除する
+func (s *notionService) archive(ctx context.Context, result *model.NotionSyncResult) error {
+	for {
+		var todos []*model.Todo
+		err := s.db.WithContext(ctx).Unscoped().
+			Select("id", "notion_page_id").
+			Where("deleted_at IS NOT NULL AND notion_page_id IS NOT NULL").
+			Limit(notionBatchSize).
+			Find(&todos).Error
+		if err != nil {
+			return fmt.Errorf("削除されたTodoの取得に失敗しました: %w", err)
+		}
+
+		for _, todo := range todos {
+			err := s.client.ArchivePage(ctx, *todo.NotionPageID)
+			switch {
+			case errors.Is(err, notion.ErrNotFound):
+				// Notion側で既に削除されている
+			case err != nil:
+				return fmt.Errorf("Todo %d のページのアーカイブに失敗しました: %w", todo.ID, err)
+			default:
+				result.Archived++
+			}
+			if err := s.db.WithContext(ctx).Unscoped().Model(&model.Todo{}).Where("id = ?", todo.ID).
+				UpdateColumns(map[string]any{"notion_page_id": nil, "notion_synced_at": nil}).Error; err != nil {
+				return fmt.Errorf("ページとの紐付けの解除に失敗しました: %w", err)
+			}
+		}
+		if len(todos) < notionBatchSize {
+			return nil
+		}
+	}
+}
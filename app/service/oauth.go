@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"myapp/db/model"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// oauthHTTPClient トークンエンドポイントの呼び出しに使うHTTPクライアント
+var oauthHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// oauthContext oauth2パッケージがトークンエンドポイントの呼び出しに oauthHTTPClient を使うコンテキスト
+func oauthContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, oauthHTTPClient)
+}
+
+// exchangeOAuthCode 認可コードをトークンに交換
+func exchangeOAuthCode(ctx context.Context, cfg *oauth2.Config, code string) (*oauth2.Token, error) {
+	return cfg.Exchange(oauthContext(ctx), code)
+}
+
+// refreshOAuthToken リフレッシュトークンでアクセストークンを更新
+// レスポンスにリフレッシュトークンが含まれない場合は元のリフレッシュトークンを引き継ぐ（oauth2パッケージが行う）
+func refreshOAuthToken(ctx context.Context, cfg *oauth2.Config, refreshToken string) (*oauth2.Token, error) {
+	return cfg.TokenSource(oauthContext(ctx), &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+// issueOAuthState stateを発行してDBに保存する（期限切れのstateはここで削除する）
+// インスタンスのメモリではなくDBに保存するため、同意画面からのコールバックを別のインスタンスが受けても検証できる
+func issueOAuthState(ctx context.Context, db *gorm.DB, provider string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+
+	now := time.Now()
+	if err := db.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.OAuthState{}).Error; err != nil {
+		return "", fmt.Errorf("期限切れのstateの削除に失敗しました: %w", err)
+	}
+	record := &model.OAuthState{State: state, Provider: provider, ExpiresAt: now.Add(oauthStateTTL)}
+	if err := db.WithContext(ctx).Create(record).Error; err != nil {
+		return "", fmt.Errorf("stateの保存に失敗しました: %w", err)
+	}
+	return state, nil
+}
+
+// consumeOAuthState 発行済みで有効期限内のstateか検証して削除する（同じstateは1回だけ使える）
+func consumeOAuthState(ctx context.Context, db *gorm.DB, provider, state string) error {
+	if state == "" {
+		return ErrInvalidOAuthState
+	}
+	result := db.WithContext(ctx).
+		Where("state = ? AND provider = ? AND expires_at >= ?", state, provider, time.Now()).
+		Delete(&model.OAuthState{})
+	if result.Error != nil {
+		return fmt.Errorf("stateの検証に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidOAuthState
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/tracing"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrProfileInvalidTimezone 利用者のタイムゾーンを読み込めない
+	ErrProfileInvalidTimezone = errcode.New(errcode.InvalidTimezone, "タイムゾーンを読み込めません")
+	// ErrProfileInvalidSort 一覧の並び順が正しくない
+	ErrProfileInvalidSort = errcode.New(errcode.InvalidSort, "一覧の並び順が正しくありません")
+)
+
+// ProfileService プロファイル（インスタンス全体のタイムゾーン・一覧の並び順）を管理するサービスのインターフェース
+type ProfileService interface {
+	GetProfile(ctx context.Context) (*model.UserProfile, error)
+	UpdateProfile(ctx context.Context, req *model.UserProfileRequest) (*model.UserProfile, error)
+	// Location 「今日」「期限切れ」の判定・リマインダー・ダイジェストに使うタイムゾーン
+	// 未設定・取得できない場合はスケジューラーのタイムゾーン
+	Location(ctx context.Context) *time.Location
+	// DefaultSort 並び順を指定しない一覧の並び順（取得できない場合は作成日時の新しい順）
+	DefaultSort(ctx context.Context) model.TodoSort
+}
+
+// profileService プロファイルサービスの実装
+type profileService struct {
+	db *gorm.DB
+	// location タイムゾーンを設定していない場合のタイムゾーン（スケジューラーのタイムゾーン）
+	location *time.Location
+}
+
+// NewProfileService 新しいプロファイルサービスインスタンスを作成
+func NewProfileService(location *time.Location) ProfileService {
+	return &profileService{
+		db:       db.GetDB(),
+		location: location,
+	}
+}
+
+// GetProfile プロファイルを取得（未登録の場合は既定値）
+func (s *profileService) GetProfile(ctx context.Context) (*model.UserProfile, error) {
+	ctx, span := tracing.Start(ctx, "ProfileService.GetProfile", tracing.SpanKindInternal)
+	defer span.End()
+
+	profile := &model.UserProfile{}
+	if err := s.db.WithContext(ctx).First(profile, model.UserProfileID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("プロファイルの取得に失敗しました: %w", err)
+		}
+		profile = &model.UserProfile{ID: model.UserProfileID, DefaultSort: model.TodoSortCreatedAt}
+	}
+	profile.EffectiveTimezone = s.locationOf(profile).String()
+	return profile, nil
+}
+
+// UpdateProfile プロファイルを更新（未登録の場合は登録。default_sort を省略した場合は変更しない）
+func (s *profileService) UpdateProfile(ctx context.Context, req *model.UserProfileRequest) (*model.UserProfile, error) {
+	ctx, span := tracing.Start(ctx, "ProfileService.UpdateProfile", tracing.SpanKindInternal)
+	defer span.End()
+
+	invalid := &errcode.ValidationError{}
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			invalid.Add("timezone", req.Timezone, ErrProfileInvalidTimezone)
+		}
+	}
+	if req.DefaultSort != "" && !req.DefaultSort.IsValid() {
+		invalid.Add("default_sort", req.DefaultSort, ErrProfileInvalidSort)
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	columns := []string{"timezone", "updated_at"}
+	if req.DefaultSort != "" {
+		columns = append(columns, "default_sort")
+	}
+	profile := &model.UserProfile{ID: model.UserProfileID, Timezone: timezone, DefaultSort: req.DefaultSort}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(profile)
+	if result.Error != nil {
+		return nil, fmt.Errorf("プロファイルの更新に失敗しました: %w", result.Error)
+	}
+	// 省略した default_sort は登録済みの値（未登録の場合は既定値）を返す
+	return s.GetProfile(ctx)
+}
+
+// Location 利用者のタイムゾーン（プロファイルを取得できない場合はスケジューラーのタイムゾーンで続行する）
+func (s *profileService) Location(ctx context.Context) *time.Location {
+	profile, err := s.GetProfile(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "プロファイルを取得できないため、スケジューラーのタイムゾーンを使います", "error", err)
+		return s.location
+	}
+	return s.locationOf(profile)
+}
+
+// DefaultSort 並び順を指定しない一覧の並び順（プロファイルを取得できない場合は作成日時の新しい順で続行する）
+func (s *profileService) DefaultSort(ctx context.Context) model.TodoSort {
+	profile, err := s.GetProfile(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "プロファイルを取得できないため、作成日時の新しい順で並べます", "error", err)
+		return model.TodoSortCreatedAt
+	}
+	if !profile.DefaultSort.IsValid() {
+		return model.TodoSortCreatedAt
+	}
+	return profile.DefaultSort
+}
+
+// locationOf プロファイルのタイムゾーン（未設定・読み込めない場合はスケジューラーのタイムゾーン）
+func (s *profileService) locationOf(profile *model.UserProfile) *time.Location {
+	if profile.Timezone != "" {
+		if loc, err := time.LoadLocation(profile.Timezone); err == nil {
+			return loc
+		}
+	}
+	return s.location
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeService 保持期間を過ぎたデータを物理削除するサービスのインターフェース
+type PurgeService interface {
+	Purge(ctx context.Context) (*model.PurgeResult, error)
+}
+
+// purgeService パージサービスの実装
+type purgeService struct {
+	db        *gorm.DB
+	retention time.Duration
+	// queueRetention 成功したジョブを保持する期間
+	queueRetention time.Duration
+}
+
+// NewPurgeService 新しいパージサービスインスタンスを作成（retentionは削除・終了してから保持する期間、queueRetentionは成功したジョブを保持する期間）
+func NewPurgeService(retention, queueRetention time.Duration) PurgeService {
+	return &purgeService{
+		db:             db.GetDB(),
+		retention:      retention,
+		queueRetention: queueRetention,
+	}
+}
+
+// Purge 論理削除から保持期間を過ぎたTodoと、終了から保持期間を過ぎた取り込み・一括操作の記録を物理削除する
+// 物理削除したTodo・完了したTodoのリマインダーの配信状態、成功したジョブ・Webhookの配信の履歴・スケジューラーの実行履歴も保持期間を過ぎたものを削除する
+func (s *purgeService) Purge(ctx context.Context) (*model.PurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "PurgeService.Purge", tracing.SpanKindInternal)
+	defer span.End()
+
+	cutoff := time.Now().Add(-s.retention)
+	result := &model.PurgeResult{}
+
+	todos := s.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", cutoff).Delete(&model.Todo{})
+	if todos.Error != nil {
+		return nil, fmt.Errorf("削除済みTodoのパージに失敗しました: %w", todos.Error)
+	}
+	result.Todos = todos.RowsAffected
+
+	imports := s.db.WithContext(ctx).Where("finished_at < ?", cutoff).Delete(&model.ImportJob{})
+	if imports.Error != nil {
+		return result, fmt.Errorf("取り込みの記録のパージに失敗しました: %w", imports.Error)
+	}
+	result.ImportJobs = imports.RowsAffected
+
+	bulks := s.db.WithContext(ctx).Where("finished_at < ?", cutoff).Delete(&model.BulkJob{})
+	if bulks.Error != nil {
+		return result, fmt.Errorf("一括操作の記録のパージに失敗しました: %w", bulks.Error)
+	}
+	result.BulkJobs = bulks.RowsAffected
+
+	active := s.db.Model(&model.Todo{}).Select("id").Where("completed = ?", false)
+	reminders := s.db.WithContext(ctx).Where("updated_at < ? AND todo_id NOT IN (?)", cutoff, active).Delete(&model.ReminderDelivery{})
+	if reminders.Error != nil {
+		return result, fmt.Errorf("リマインダーの配信状態のパージに失敗しました: %w", reminders.Error)
+	}
+	result.ReminderDeliveries = reminders.RowsAffected
+
+	// デッドレターは原因を調べて再実行できるよう残す
+	queueJobs := s.db.WithContext(ctx).
+		Where("status = ? AND finished_at < ?", model.QueueJobSucceeded, time.Now().Add(-s.queueRetention)).
+		Delete(&model.QueueJob{})
+	if queueJobs.Error != nil {
+		return result, fmt.Errorf("成功済みのジョブのパージに失敗しました: %w", queueJobs.Error)
+	}
+	result.QueueJobs = queueJobs.RowsAffected
+
+	// 失敗したWebhookの配信も再配信できるよう残す
+	deliveries := s.db.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?", []model.WebhookDeliveryStatus{model.WebhookDeliverySucceeded, model.WebhookDeliveryCanceled}, time.Now().Add(-s.queueRetention)).
+		Delete(&model.WebhookDelivery{})
+	if deliveries.Error != nil {
+		return result, fmt.Errorf("Webhookの配信の履歴のパージに失敗しました: %w", deliveries.Error)
+	}
+	result.WebhookDeliveries = deliveries.RowsAffected
+
+	jobRuns := s.db.WithContext(ctx).Where("started_at < ?", cutoff).Delete(&model.JobRun{})
+	if jobRuns.Error != nil {
+		return result, fmt.Errorf("ジョブの実行履歴のパージに失敗しました: %w", jobRuns.Error)
+	}
+	result.JobRuns = jobRuns.RowsAffected
+
+	slog.InfoContext(ctx, "保持期間を過ぎたデータをパージしました", "todos", result.Todos, "import_jobs", result.ImportJobs, "bulk_jobs", result.BulkJobs, "reminder_deliveries", result.ReminderDeliveries, "queue_jobs", result.QueueJobs, "webhook_deliveries", result.WebhookDeliveries, "job_runs", result.JobRuns, "cutoff", cutoff)
+	return result, nil
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/notify"
+	"myapp/tracing"
+	"myapp/webpush"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Web Push通知の定数
+const (
+	// pushMaxFailures 連続してこの回数送信に失敗した購読は削除する
+	pushMaxFailures = 5
+	// pushConcurrency 同時に送信する購読の数
+	pushConcurrency = 8
+	// pushSendTimeout 1回の通知で全ての購読へ送信し終えるまでのタイムアウト
+	pushSendTimeout = 2 * time.Minute
+	// pushMaxUserAgentLength 保存するUser-Agentの上限
+	pushMaxUserAgentLength = 512
+)
+
+// Web Push通知のエラー
+var (
+	ErrPushSubscriptionNotFound = errcode.New(errcode.PushSubscriptionNotFound, "購読が見つかりません")
+	ErrPushEndpointNotAllowed   = errcode.New(errcode.PushEndpointNotAllowed, "購読の送信先が許可されていません")
+	ErrPushInvalidSubscription  = errcode.New(errcode.PushInvalidSubscription, "購読の内容が不正です")
+)
+
+// PushOptions Web Push通知の設定
+type PushOptions struct {
+	// TTL プッシュサービスが配信を試みる期間（端末がオフラインの場合はこの間保持される）
+	TTL time.Duration
+	// AllowedHosts 購読を受け付けるプッシュサービスのホスト（*.example.com でサブドメインに一致。空の場合は全てのHTTPSのホスト）
+	AllowedHosts []string
+}
+
+// PushResult テスト送信の結果
+type PushResult struct {
+	Sent    int `json:"sent" doc:"送信に成功した購読の数"`
+	Failed  int `json:"failed" doc:"送信に失敗した購読の数"`
+	Removed int `json:"removed" doc:"失効・期限切れ・連続失敗により削除した購読の数"`
+}
+
+// PushService Web Pushの購読を管理し、通知を送信するサービスのインターフェース
+type PushService interface {
+	VAPIDPublicKey() string
+	Subscribe(ctx context.Context, req *model.PushSubscriptionRequest, userAgent string) (*model.PushSubscription, error)
+	Unsubscribe(ctx context.Context, endpoint string) error
+	ListSubscriptions(ctx context.Context) ([]*model.PushSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	SendTest(ctx context.Context) (*PushResult, error)
+	Notify(ctx context.Context, event notify.Event) (*PushResult, error)
+}
+
+// pushService Web Push通知サービスの実装
+type pushService struct {
+	db     *gorm.DB
+	vapid  *webpush.VAPID
+	client *webpush.Client
+	opts   PushOptions
+}
+
+// NewPushService 新しいWeb Push通知サービスインスタンスを作成
+func NewPushService(vapid *webpush.VAPID, opts PushOptions) PushService {
+	return &pushService{
+		db:     db.GetDB(),
+		vapid:  vapid,
+		client: webpush.NewClient(vapid),
+		opts:   opts,
+	}
+}
+
+// VAPIDPublicKey ブラウザの subscribe() に渡す公開鍵
+func (s *pushService) VAPIDPublicKey() string {
+	return s.vapid.PublicKey()
+}
+
+// Subscribe 購読を登録（同じ送信先の購読は鍵・有効期限を更新する）
+func (s *pushService) Subscribe(ctx context.Context, req *model.PushSubscriptionRequest, userAgent string) (*model.PushSubscription, error) {
+	ctx, span := tracing.Start(ctx, "PushService.Subscribe", tracing.SpanKindInternal)
+	defer span.End()
+
+	if err := s.validateEndpoint(req.Endpoint); err != nil {
+		return nil, err
+	}
+	if req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		return nil, fmt.Errorf("%w: keys.p256dh と keys.auth は必須です", ErrPushInvalidSubscription)
+	}
+
+	sub := &model.PushSubscription{
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: truncateRunes(userAgent, pushMaxUserAgentLength),
+	}
+	if req.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*req.ExpirationTime)
+		sub.ExpiresAt = &expiresAt
+		if sub.Expired(time.Now()) {
+			return nil, fmt.Errorf("%w: 有効期限を過ぎています", ErrPushInvalidSubscription)
+		}
+	}
+
+	// 鍵が壊れていないか、実際に暗号化できるかを送信前に確かめる
+	if err := webpush.Validate(webpush.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPushInvalidSubscription, err)
+	}
+
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"p256dh":        sub.P256dh,
+			"auth":          sub.Auth,
+			"user_agent":    sub.UserAgent,
+			"expires_at":    sub.ExpiresAt,
+			"failure_count": 0,
+			"updated_at":    time.Now(),
+		}),
+	}).Create(sub)
+	if result.Error != nil {
+		return nil, fmt.Errorf("購読の登録に失敗しました: %w", result.Error)
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe 送信先を指定して購読を解除
+func (s *pushService) Unsubscribe(ctx context.Context, endpoint string) error {
+	ctx, span := tracing.Start(ctx, "PushService.Unsubscribe", tracing.SpanKindInternal)
+	defer span.End()
+
+	result := s.db.WithContext(ctx).Where("endpoint = ?", endpoint).Delete(&model.PushSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("購読の解除に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPushSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListSubscriptions 登録済みの購読を新しい順に取得
+func (s *pushService) ListSubscriptions(ctx context.Context) ([]*model.PushSubscription, error) {
+	ctx, span := tracing.Start(ctx, "PushService.ListSubscriptions", tracing.SpanKindInternal)
+	defer span.End()
+
+	var subs []*model.PushSubscription
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("購読の取得に失敗しました: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription IDを指定して購読を削除
+func (s *pushService) DeleteSubscription(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "PushService.DeleteSubscription", tracing.SpanKindInternal)
+	defer span.End()
+
+	result := s.db.WithContext(ctx).Delete(&model.PushSubscription{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("購読の削除に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPushSubscriptionNotFound
+	}
+	return nil
+}
+
+// SendTest 全ての購読へテスト通知を送信
+func (s *pushService) SendTest(ctx context.Context) (*PushResult, error) {
+	ctx, span := tracing.Start(ctx, "PushService.SendTest", tracing.SpanKindInternal)
+	defer span.End()
+
+	return s.broadcast(ctx, pushMessage{
+		Title: "テスト通知",
+		Body:  "Web Push通知は正しく設定されています",
+		Tag:   "test",
+	}, webpush.UrgencyNormal)
+}
+
+// Notify Todoイベントを全ての購読へ送信
+func (s *pushService) Notify(ctx context.Context, event notify.Event) (*PushResult, error) {
+	ctx, span := tracing.Start(ctx, "PushService.Notify", tracing.SpanKindInternal)
+	defer span.End()
+
+	urgency := webpush.UrgencyNormal
+	if event.Type == notify.EventDueSoon || event.Type == notify.EventOverdue || event.Type == notify.EventEscalated {
+		urgency = webpush.UrgencyHigh
+	}
+	return s.broadcast(ctx, pushMessage{
+		Title: event.Todo.Title,
+		Body:  event.Summary(),
+		// 同じTodoの通知は端末上で置き換える
+		Tag: fmt.Sprintf("todo-%d", event.Todo.ID),
+		Data: map[string]any{
+			"todo_id": event.Todo.ID,
+			"type":    event.Type,
+		},
+	}, urgency)
+}
+
+// pushMessage Service Workerのpushイベントで受け取るペイロード
+type pushMessage struct {
+	Title string         `json:"title"`
+	Body  string         `json:"body"`
+	Tag   string         `json:"tag"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+// broadcast 全ての購読へ送信し、失効した購読を削除する
+func (s *pushService) broadcast(ctx context.Context, message pushMessage, urgency webpush.Urgency) (*PushResult, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > webpush.MaxPayloadSize {
+		// 本文が長すぎる場合は本文を削って送る
+		message.Body = truncateRunes(message.Body, 200)
+		if payload, err = json.Marshal(message); err != nil {
+			return nil, err
+		}
+	}
+
+	subs, err := s.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		result PushResult
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, pushConcurrency)
+	)
+	now := time.Now()
+	for _, sub := range subs {
+		if sub.Expired(now) {
+			s.remove(ctx, sub, "expired")
+			mu.Lock()
+			result.Removed++
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(sub *model.PushSubscription) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			sent, removed := s.send(ctx, sub, payload, urgency)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case sent:
+				result.Sent++
+			case removed:
+				result.Failed++
+				result.Removed++
+			default:
+				result.Failed++
+			}
+		}(sub)
+	}
+	wg.Wait()
+
+	return &result, nil
+}
+
+// send 1件の購読へ送信し、結果を購読に記録する（失効・連続失敗の場合は削除する）
+func (s *pushService) send(ctx context.Context, sub *model.PushSubscription, payload []byte, urgency webpush.Urgency) (sent, removed bool) {
+	err := s.client.Send(ctx, webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		P256dh:   sub.P256dh,
+		Auth:     sub.Auth,
+	}, payload, s.opts.TTL, urgency)
+
+	switch {
+	case err == nil:
+		now := time.Now()
+		if err := s.db.WithContext(ctx).Model(sub).UpdateColumns(map[string]any{
+			"failure_count":   0,
+			"last_success_at": now,
+		}).Error; err != nil {
+			slog.WarnContext(ctx, "購読の送信結果の記録に失敗しました", "subscription_id", sub.ID, "error", err)
+		}
+		return true, false
+	case errors.Is(err, webpush.ErrGone):
+		s.remove(ctx, sub, "gone")
+		return false, true
+	}
+
+	slog.WarnContext(ctx, "Web Push通知の送信に失敗しました", "subscription_id", sub.ID, "failure_count", sub.FailureCount+1, "error", err)
+	if sub.FailureCount+1 >= pushMaxFailures {
+		s.remove(ctx, sub, "failures")
+		return false, true
+	}
+	if err := s.db.WithContext(ctx).Model(sub).UpdateColumn("failure_count", gorm.Expr("failure_count + 1")).Error; err != nil {
+		slog.WarnContext(ctx, "購読の送信結果の記録に失敗しました", "subscription_id", sub.ID, "error", err)
+	}
+	return false, false
+}
+
+// remove 送信できなくなった購読を削除
+func (s *pushService) remove(ctx context.Context, sub *model.PushSubscription, reason string) {
+	if err := s.db.WithContext(ctx).Delete(&model.PushSubscription{}, sub.ID).Error; err != nil {
+		slog.WarnContext(ctx, "購読の削除に失敗しました", "subscription_id", sub.ID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Web Pushの購読を削除しました", "subscription_id", sub.ID, "reason", reason)
+}
+
+// validateEndpoint 送信先がHTTPSかつ許可されたプッシュサービスか（任意のURLへのリクエストを防ぐ）
+func (s *pushService) validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: endpointにはHTTPSのURLを指定してください", ErrPushInvalidSubscription)
+	}
+	if len(s.opts.AllowedHosts) == 0 {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.opts.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+		} else if host == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrPushEndpointNotAllowed, host)
+}
+
+// pushChannel Web Pushの購読へ送信する通知チャンネル
+type pushChannel struct {
+	service PushService
+}
+
+// NewPushChannel Web Push通知サービスを通知チャンネルとして使う
+func NewPushChannel(service PushService) notify.Channel {
+	return &pushChannel{service: service}
+}
+
+// Name チャンネル名
+func (c *pushChannel) Name() string {
+	return "webpush"
+}
+
+// Send イベントを全ての購読へ送信（個々の購読への送信失敗は購読に記録し、エラーにはしない）
+func (c *pushChannel) Send(ctx context.Context, event notify.Event) error {
+	_, err := c.service.Notify(ctx, event)
+	return err
+}
+
+// Timeout 購読の数だけ送信するため既定より長いタイムアウトを使う
+func (c *pushChannel) Timeout() time.Duration {
+	return pushSendTimeout
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ジョブキューの管理のエラー
+var (
+	ErrQueueJobNotFound = errcode.New(errcode.JobNotFound, "ジョブが見つかりません")
+	// ErrQueueJobNotRetryable 実行待ち・実行中のジョブは再実行できない
+	ErrQueueJobNotRetryable = errcode.New(errcode.JobNotRetryable, "デッドレター・成功済みのジョブのみ再実行できます")
+	// ErrQueueJobRunning 実行中のジョブは削除できない
+	ErrQueueJobRunning = errcode.New(errcode.JobRunning, "実行中のジョブは削除できません")
+)
+
+// QueueStat ジョブの種類・状態ごとの件数
+type QueueStat struct {
+	Kind   string               `json:"kind"`
+	Status model.QueueJobStatus `json:"status"`
+	Count  int64                `json:"count"`
+}
+
+// QueueService ジョブキューのジョブを確認・再実行するサービスのインターフェース
+type QueueService interface {
+	ListJobs(ctx context.Context, status model.QueueJobStatus, kind string, limit int) ([]*model.QueueJob, error)
+	GetJob(ctx context.Context, id uint) (*model.QueueJob, error)
+	Stats(ctx context.Context) ([]QueueStat, error)
+	RetryJob(ctx context.Context, id uint) (*model.QueueJob, error)
+	RetryDead(ctx context.Context, kind string) (int64, error)
+	DeleteJob(ctx context.Context, id uint) error
+}
+
+// queueService ジョブキューサービスの実装
+type queueService struct {
+	db *gorm.DB
+}
+
+// NewQueueService 新しいジョブキューサービスインスタンスを作成
+func NewQueueService() QueueService {
+	return &queueService{
+		db: db.GetDB(),
+	}
+}
+
+// ListJobs ジョブを新しい順に取得（status・kindが空の場合は絞り込まない）
+func (s *queueService) ListJobs(ctx context.Context, status model.QueueJobStatus, kind string, limit int) ([]*model.QueueJob, error) {
+	ctx, span := tracing.Start(ctx, "QueueService.ListJobs", tracing.SpanKindInternal)
+	defer span.End()
+
+	query := s.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var jobs []*model.QueueJob
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("ジョブの取得に失敗しました: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetJob IDでジョブを取得
+func (s *queueService) GetJob(ctx context.Context, id uint) (*model.QueueJob, error) {
+	ctx, span := tracing.Start(ctx, "QueueService.GetJob", tracing.SpanKindInternal)
+	defer span.End()
+
+	var job model.QueueJob
+	if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQueueJobNotFound
+		}
+		return nil, fmt.Errorf("ジョブの取得に失敗しました: %w", err)
+	}
+	return &job, nil
+}
+
+// Stats ジョブの種類・状態ごとの件数を取得
+func (s *queueService) Stats(ctx context.Context) ([]QueueStat, error) {
+	ctx, span := tracing.Start(ctx, "QueueService.Stats", tracing.SpanKindInternal)
+	defer span.End()
+
+	var stats []QueueStat
+	err := s.db.WithContext(ctx).Model(&model.QueueJob{}).
+		Select("kind, status, COUNT(*) AS count").
+		Group("kind, status").
+		Order("kind, status").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("ジョブの件数の取得に失敗しました: %w", err)
+	}
+	return stats, nil
+}
+
+// RetryJob デッドレター・成功済みのジョブを試行回数を戻して実行待ちに戻す
+func (s *queueService) RetryJob(ctx context.Context, id uint) (*model.QueueJob, error) {
+	ctx, span := tracing.Start(ctx, "QueueService.RetryJob", tracing.SpanKindInternal)
+	defer span.End()
+
+	result := s.db.WithContext(ctx).Model(&model.QueueJob{}).
+		Where("id = ? AND status IN ?", id, []model.QueueJobStatus{model.QueueJobDead, model.QueueJobSucceeded}).
+		UpdateColumns(retryColumns(time.Now()))
+	if result.Error != nil {
+		return nil, fmt.Errorf("ジョブの再実行に失敗しました: %w", result.Error)
+	}
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrQueueJobNotRetryable
+	}
+	return job, nil
+}
+
+// RetryDead デッドレターのジョブをまとめて実行待ちに戻し、件数を返す（kindが空の場合は全ての種類）
+func (s *queueService) RetryDead(ctx context.Context, kind string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "QueueService.RetryDead", tracing.SpanKindInternal)
+	defer span.End()
+
+	query := s.db.WithContext(ctx).Model(&model.QueueJob{}).Where("status = ?", model.QueueJobDead)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	result := query.UpdateColumns(retryColumns(time.Now()))
+	if result.Error != nil {
+		return 0, fmt.Errorf("ジョブの再実行に失敗しました: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteJob 実行中でないジョブを削除
+func (s *queueService) DeleteJob(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "QueueService.DeleteJob", tracing.SpanKindInternal)
+	defer span.End()
+
+	result := s.db.WithContext(ctx).Where("status <> ?", model.QueueJobRunning).Delete(&model.QueueJob{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("ジョブの削除に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := s.GetJob(ctx, id); err != nil {
+			return err
+		}
+		return ErrQueueJobRunning
+	}
+	return nil
+}
+
+// retryColumns ジョブを実行待ちに戻す更新内容（試行回数・失敗理由はリセットする）
+func retryColumns(now time.Time) map[string]any {
+	return map[string]any{
+		"status":      model.QueueJobPending,
+		"attempts":    0,
+		"run_at":      now,
+		"last_error":  "",
+		"finished_at": nil,
+		"updated_at":  now,
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"myapp/cache"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/events"
+	"myapp/notify"
+	"myapp/recurrence"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// recurrenceBatchSize 1回の生成で対象とする繰り返しTodoの上限（残りは次回の生成で処理する）
+	recurrenceBatchSize = 100
+	// recurrenceMaxSkips 過ぎた日付・祝日を飛ばして次回の日付を探す回数の上限
+	recurrenceMaxSkips = 1000
+)
+
+// RecurrenceService 繰り返しTodoの次回のTodoを生成するサービスのインターフェース
+type RecurrenceService interface {
+	Generate(ctx context.Context) (int, error)
+}
+
+// recurrenceService 繰り返しTodoサービスの実装
+type recurrenceService struct {
+	db *gorm.DB
+	// location タイムゾーンを指定していない繰り返しTodoの日付を数えるタイムゾーン
+	location *time.Location
+	holidays *recurrence.Holidays
+}
+
+// NewRecurrenceService 新しい繰り返しTodoサービスインスタンスを作成
+func NewRecurrenceService(location *time.Location, holidays *recurrence.Holidays) RecurrenceService {
+	return &recurrenceService{
+		db:       db.GetDB(),
+		location: location,
+		holidays: holidays,
+	}
+}
+
+// Generate 完了した、または期限を過ぎた繰り返しTodoについて次回のTodoを生成し、生成した件数を返す
+// 次回の期限は現在より後の最初の日付とし、期限を過ぎてから時間が経った場合も過去の回は生成しない
+func (s *recurrenceService) Generate(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "RecurrenceService.Generate", tracing.SpanKindInternal)
+	defer span.End()
+
+	now := time.Now()
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("recurrence_rule IS NOT NULL AND recurrence_generated_at IS NULL AND due_date IS NOT NULL").
+		Where("completed = ? OR ?", true, dueBefore(now, s.location)).
+		Order("due_date").
+		Limit(recurrenceBatchSize).
+		Find(&todos)
+	if result.Error != nil {
+		return 0, fmt.Errorf("繰り返しTodoの取得に失敗しました: %w", result.Error)
+	}
+
+	generated := 0
+	for _, todo := range todos {
+		if err := ctx.Err(); err != nil {
+			return generated, err
+		}
+		next, err := s.generate(ctx, todo, now)
+		if err != nil {
+			return generated, err
+		}
+		if next != nil {
+			generated++
+			notify.Publish(ctx, notify.Event{Type: notify.EventCreated, Todo: *next})
+			events.PublishTodo(ctx, events.TodoCreated, next)
+		}
+	}
+	return generated, nil
+}
+
+// generate todoの次回のTodoを作成し、todoを生成済みにする（繰り返しが終了した場合は生成済みにするのみでnilを返す）
+func (s *recurrenceService) generate(ctx context.Context, todo *model.Todo, now time.Time) (*model.Todo, error) {
+	due, err := s.nextDue(todo, now)
+	if err != nil {
+		// 保存後に解釈できなくなったルール（タイムゾーンの削除等）は生成を止める
+		slog.WarnContext(ctx, "繰り返しTodoの次回の期限を決められないため生成を停止します", "todo_id", todo.ID, "rule", *todo.RecurrenceRule, "error", err)
+	}
+
+	var next *model.Todo
+	err = cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		claim := tx.Model(&model.Todo{}).
+			Where("id = ? AND recurrence_generated_at IS NULL", todo.ID).
+			UpdateColumn("recurrence_generated_at", now)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			return claim.Error
+		}
+		if due.IsZero() {
+			return nil
+		}
+
+		next = &model.Todo{
+			Title:              todo.Title,
+			Description:        todo.Description,
+			Priority:           todo.Priority,
+			DueDate:            &due,
+			DueAllDay:          todo.DueAllDay,
+			Tags:               todo.Tags,
+			RecurrenceRule:     todo.RecurrenceRule,
+			RecurrenceTimezone: todo.RecurrenceTimezone,
+			SkipHolidays:       todo.SkipHolidays,
+			RecurrenceParentID: &todo.ID,
+		}
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Todo %d の次回のTodoの生成に失敗しました: %w", todo.ID, err)
+	}
+	return next, nil
+}
+
+// nextDue todoの次回の期限（繰り返しが終了した場合はゼロ値）
+func (s *recurrenceService) nextDue(todo *model.Todo, now time.Time) (time.Time, error) {
+	rule, err := recurrence.Parse(*todo.RecurrenceRule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := s.location
+	if todo.RecurrenceTimezone != "" {
+		if loc, err = time.LoadLocation(todo.RecurrenceTimezone); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	// 終日の期限は日付（UTCの0時）のまま数え、locでの今日以降の最初の日付にする
+	ruleLoc, after := loc, now
+	if todo.DueAllDay {
+		ruleLoc, after = time.UTC, model.AllDayCutoff(now, loc).Add(-time.Nanosecond)
+	}
+
+	due := *todo.DueDate
+	for i := 0; i < recurrenceMaxSkips; i++ {
+		due = rule.Next(due, ruleLoc)
+		if due.IsZero() {
+			return due, nil
+		}
+		if !due.After(after) || (todo.SkipHolidays && s.holidays.Contains(due)) {
+			continue
+		}
+		return due, nil
+	}
+	return time.Time{}, fmt.Errorf("%d回先までに該当する日付がありません", recurrenceMaxSkips)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/notify"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// reminderBatchSize 1回の配信で対象とする期限切れ・期限間近のTodoの上限（残りは次回の配信で処理する）
+	reminderBatchSize = 100
+	// reminderMaxFailures 配信に失敗した送信先へ再試行する回数の上限（超えた場合は期限が変わるまで配信しない）
+	reminderMaxFailures = 5
+	// reminderRetryBase・reminderRetryMax 配信に失敗した送信先へ再試行するまでの間隔（失敗のたびに倍増）
+	reminderRetryBase = time.Minute
+	reminderRetryMax  = time.Hour
+)
+
+// ReminderService 期限切れ・期限間近のTodoのリマインダーを送信先ごとに配信・記録するサービスのインターフェース
+type ReminderService interface {
+	Deliver(ctx context.Context) (int, error)
+	ListDeliveries(ctx context.Context, todoID uint, status model.ReminderStatus, limit int) ([]*model.ReminderDelivery, error)
+}
+
+// reminderService リマインダーサービスの実装
+type reminderService struct {
+	db *gorm.DB
+	// remindBefore 期限のどれだけ前に期限間近を配信するか（0の場合は配信しない）
+	remindBefore time.Duration
+	// renotifyInterval 配信済みの送信先へ再び配信する間隔（0の場合は期限が変わるまで再通知しない）
+	renotifyInterval time.Duration
+	// profiles リマインダーに表示する期限のタイムゾーン（利用者のタイムゾーン）
+	profiles ProfileService
+}
+
+// NewReminderService 新しいリマインダーサービスインスタンスを作成
+func NewReminderService(remindBefore, renotifyInterval time.Duration, profiles ProfileService) ReminderService {
+	return &reminderService{
+		db:               db.GetDB(),
+		remindBefore:     remindBefore,
+		renotifyInterval: renotifyInterval,
+		profiles:         profiles,
+	}
+}
+
+// Deliver 期限間近・期限切れの未完了Todoのうち、未配信・再通知の時刻を過ぎた送信先へ配信し、配信した件数を返す
+// 送信先ごとに配信状態を記録するため、一部の送信先への配信に失敗しても他の送信先へ重複して配信しない
+func (s *reminderService) Deliver(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "ReminderService.Deliver", tracing.SpanKindInternal)
+	defer span.End()
+
+	now := time.Now()
+	loc := s.profiles.Location(ctx)
+	var errs []error
+	total := 0
+	if s.remindBefore > 0 {
+		dueSoon := gorm.Expr("NOT ? AND ?", dueBefore(now, loc), dueBefore(now.Add(s.remindBefore), loc))
+		n, err := s.deliver(ctx, notify.EventDueSoon, now, loc, dueSoon)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("期限間近のリマインダーの配信に失敗しました: %w", err))
+		}
+		total += n
+	}
+	n, err := s.deliver(ctx, notify.EventOverdue, now, loc, dueBefore(now, loc))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("期限切れのリマインダーの配信に失敗しました: %w", err))
+	}
+	total += n
+	return total, errors.Join(errs...)
+}
+
+// deliver 条件に一致するTodoのリマインダーを、イベントを通知する全ての送信先へ配信する
+// 終日の期限は利用者のタイムゾーン（loc）でのその日の終わりを期限とし、本文の期限・日時もlocで表示する
+func (s *reminderService) deliver(ctx context.Context, eventType notify.EventType, now time.Time, loc *time.Location, cond clause.Expr) (int, error) {
+	subs := notify.Listening(eventType)
+	if len(subs) == 0 {
+		return 0, nil
+	}
+	channels := make([]string, len(subs))
+	for i, sub := range subs {
+		channels[i] = sub.Channel.Name()
+	}
+
+	// 現在の期限について全ての送信先が配信済み（または再試行待ち）のTodoは対象外
+	settled := s.db.Model(&model.ReminderDelivery{}).
+		Select("COUNT(*)").
+		Where("reminder_deliveries.todo_id = todos.id AND reminder_deliveries.due_date = todos.due_date").
+		Where("reminder_deliveries.event = ? AND reminder_deliveries.channel IN ?", eventType, channels).
+		Where("(reminder_deliveries.next_attempt_at IS NULL OR reminder_deliveries.next_attempt_at > ?)", now)
+
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Where("completed = ? AND due_date IS NOT NULL", false).
+		Where(cond).
+		Where("(?) < ?", settled, len(channels)).
+		Order("due_date").
+		Limit(reminderBatchSize).
+		Find(&todos)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if len(todos) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	var existing []*model.ReminderDelivery
+	if err := s.db.WithContext(ctx).Where("todo_id IN ? AND event = ?", ids, eventType).Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	deliveries := make(map[string]*model.ReminderDelivery, len(existing))
+	for _, d := range existing {
+		deliveries[fmt.Sprintf("%d/%s", d.TodoID, d.Channel)] = d
+	}
+
+	sent := 0
+	for _, todo := range todos {
+		event := notify.Event{Type: eventType, Todo: *todo, OccurredAt: now.In(loc)}
+		event.Todo.DueDate = todo.DueIn(loc)
+		for _, sub := range subs {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+
+			d, ok := deliveries[fmt.Sprintf("%d/%s", todo.ID, sub.Channel.Name())]
+			if !ok {
+				d = &model.ReminderDelivery{TodoID: todo.ID, Event: string(eventType), Channel: sub.Channel.Name()}
+			}
+			if !d.DueDate.Equal(*todo.DueDate) {
+				// 期限が変わった場合は新しい期限で改めて配信する
+				d.DueDate = *todo.DueDate
+				d.Failures = 0
+				d.NextAttemptAt = nil
+				d.Status = ""
+			} else if d.Status != "" && (d.NextAttemptAt == nil || d.NextAttemptAt.After(now)) {
+				continue
+			}
+
+			if s.send(ctx, sub, event, d, now) {
+				sent++
+			}
+			if err := s.db.WithContext(ctx).Save(d).Error; err != nil {
+				return sent, fmt.Errorf("Todo %d の配信状態の記録に失敗しました: %w", todo.ID, err)
+			}
+		}
+	}
+	return sent, nil
+}
+
+// send 送信先へ配信し、結果をdに反映する（配信した場合はtrue）
+func (s *reminderService) send(ctx context.Context, sub notify.Subscription, event notify.Event, d *model.ReminderDelivery, now time.Time) bool {
+	if !sub.Matches(event) {
+		d.Status = model.ReminderSkipped
+		d.NextAttemptAt = nil
+		return false
+	}
+
+	if err := notify.Send(ctx, sub.Channel, event); err != nil {
+		d.Status = model.ReminderFailed
+		d.Failures++
+		d.LastError = err.Error()
+		d.NextAttemptAt = nil
+		if d.Failures < reminderMaxFailures {
+			next := now.Add(min(reminderRetryBase<<(d.Failures-1), reminderRetryMax))
+			d.NextAttemptAt = &next
+		}
+		slog.WarnContext(ctx, "リマインダーの配信に失敗しました", "channel", d.Channel, "event", d.Event, "todo_id", d.TodoID, "failures", d.Failures, "error", err)
+		return false
+	}
+
+	d.Status = model.ReminderDelivered
+	d.Failures = 0
+	d.LastError = ""
+	d.DeliveredAt = &now
+	d.NextAttemptAt = nil
+	if s.renotifyInterval > 0 {
+		next := now.Add(s.renotifyInterval)
+		d.NextAttemptAt = &next
+	}
+	return true
+}
+
+// ListDeliveries 配信状態を新しい順に取得（todoIDが0・statusが空の場合は絞り込まない）
+func (s *reminderService) ListDeliveries(ctx context.Context, todoID uint, status model.ReminderStatus, limit int) ([]*model.ReminderDelivery, error) {
+	ctx, span := tracing.Start(ctx, "ReminderService.ListDeliveries", tracing.SpanKindInternal)
+	defer span.End()
+
+	query := s.db.WithContext(ctx).Order("updated_at DESC, id DESC").Limit(limit)
+	if todoID != 0 {
+		query = query.Where("todo_id = ?", todoID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []*model.ReminderDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("リマインダーの配信状態の取得に失敗しました: %w", err)
+	}
+	return deliveries, nil
+}
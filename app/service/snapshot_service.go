@@ -0,0 +1,389 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"myapp/cache"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/storage"
+	"myapp/tracing"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// snapshotPrefix スナップショットを保存するキーの接頭辞（snapshots/<ID>/todos.json 等）
+	snapshotPrefix = "snapshots/"
+	// snapshotIDLayout スナップショットのID（作成日時のUTC）の書式
+	snapshotIDLayout = "20060102-150405"
+	// snapshotBatchSize エクスポートで1回に読み込むTodoの件数
+	snapshotBatchSize = 500
+)
+
+// スナップショットのエラー
+var (
+	ErrSnapshotNotFound = errcode.New(errcode.SnapshotNotFound, "スナップショットが見つかりません")
+	ErrSnapshotExists   = errcode.New(errcode.SnapshotExists, "同じ時刻のスナップショットが作成済みです")
+	ErrSnapshotFormat   = errcode.New(errcode.SnapshotFormat, "スナップショットにこの形式のファイルはありません")
+)
+
+// snapshotContentTypes 形式ごとのContent-Type
+var snapshotContentTypes = map[string]string{
+	"json": "application/json",
+	"csv":  "text/csv; charset=utf-8",
+}
+
+// SnapshotService 全データのスナップショットをストレージへエクスポートし、管理するサービスのインターフェース
+type SnapshotService interface {
+	// Create スナップショットを作成し、保持する数を超えた古いスナップショットを削除する
+	Create(ctx context.Context) (*model.Snapshot, error)
+	List(ctx context.Context) ([]*model.Snapshot, error)
+	Get(ctx context.Context, id string) (*model.Snapshot, error)
+	// Open スナップショットのファイルを読み込む（呼び出し側でCloseする）
+	Open(ctx context.Context, id, format string) (io.ReadCloser, *storage.ObjectInfo, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// snapshotService スナップショットサービスの実装
+type snapshotService struct {
+	db      *gorm.DB
+	storage storage.Storage
+	// formats エクスポートする形式（json / csv）
+	formats []string
+	// keep 保持するスナップショットの数（0の場合は削除しない）
+	keep int
+}
+
+// NewSnapshotService 新しいスナップショットサービスインスタンスを作成
+func NewSnapshotService(store storage.Storage, formats []string, keep int) SnapshotService {
+	return &snapshotService{
+		db:      db.GetDB(),
+		storage: store,
+		formats: formats,
+		keep:    keep,
+	}
+}
+
+// Create 全てのTodo（完了済みを含み、削除済みを除く）を形式ごとのファイルに書き出し、最後にマニフェストを保存する
+// 形式ごとのファイルは1つの読み取りトランザクションで書き出すため、同じ時点の内容になる
+func (s *snapshotService) Create(ctx context.Context) (*model.Snapshot, error) {
+	ctx, span := tracing.Start(ctx, "SnapshotService.Create", tracing.SpanKindInternal)
+	defer span.End()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	snapshot := &model.Snapshot{ID: now.Format(snapshotIDLayout), CreatedAt: now}
+	if _, err := s.storage.Stat(ctx, manifestKey(snapshot.ID)); err == nil {
+		return nil, ErrSnapshotExists
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("スナップショットの確認に失敗しました: %w", err)
+	}
+
+	err := cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		for _, format := range s.formats {
+			key := fmt.Sprintf("%s%s/todos.%s", snapshotPrefix, snapshot.ID, format)
+			count, size, err := s.export(ctx, tx, key, format, now)
+			if err != nil {
+				return fmt.Errorf("%sのエクスポートに失敗しました: %w", format, err)
+			}
+			snapshot.Todos = count
+			snapshot.Files = append(snapshot.Files, model.SnapshotFile{Format: format, Key: key, Size: size})
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err == nil {
+		var manifest []byte
+		manifest, err = json.Marshal(snapshot)
+		if err == nil {
+			err = s.storage.Put(ctx, manifestKey(snapshot.ID), bytes.NewReader(manifest), int64(len(manifest)), "application/json")
+		}
+	}
+	if err != nil {
+		// マニフェストのないファイルは一覧に出ないが、容量を使わないよう削除しておく
+		s.remove(context.WithoutCancel(ctx), snapshot.ID)
+		return nil, fmt.Errorf("スナップショットの作成に失敗しました: %w", err)
+	}
+
+	slog.InfoContext(ctx, "スナップショットを作成しました", "id", snapshot.ID, "todos", snapshot.Todos, "formats", s.formats)
+	if err := s.prune(ctx); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+// export Todoを形式に応じて書き出しながらストレージに保存し、件数とサイズを返す
+func (s *snapshotService) export(ctx context.Context, tx *gorm.DB, key, format string, exportedAt time.Time) (int, int64, error) {
+	pr, pw := io.Pipe()
+	w := &countingWriter{w: pw}
+	done := make(chan struct{})
+	var count int
+	go func() {
+		defer close(done)
+		var err error
+		count, err = writeTodos(tx, w, format, exportedAt)
+		pw.CloseWithError(err)
+	}()
+
+	err := s.storage.Put(ctx, key, pr, -1, snapshotContentTypes[format])
+	// 保存に失敗した場合も書き出しを止めてから戻る
+	pr.Close()
+	<-done
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, w.n, nil
+}
+
+// writeTodos 全てのTodoをID順にJSON・CSVで書き出し、件数を返す
+func writeTodos(tx *gorm.DB, w io.Writer, format string, exportedAt time.Time) (int, error) {
+	var cw *csv.Writer
+	switch format {
+	case "json":
+		if _, err := fmt.Fprintf(w, `{"exported_at":%q,"todos":[`, exportedAt.Format(time.RFC3339)); err != nil {
+			return 0, err
+		}
+	case "csv":
+		cw = csv.NewWriter(w)
+		if err := cw.Write(todoCSVHeader); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, errcode.Errorf(errcode.UnsupportedFormat, "未対応の形式です: %s", format)
+	}
+
+	count := 0
+	var todos []*model.Todo
+	result := tx.Select(todoColumns).FindInBatches(&todos, snapshotBatchSize, func(_ *gorm.DB, _ int) error {
+		for _, todo := range todos {
+			if cw != nil {
+				if err := cw.Write(todoRecord(todo)); err != nil {
+					return err
+				}
+			} else {
+				if count > 0 {
+					if _, err := io.WriteString(w, ","); err != nil {
+						return err
+					}
+				}
+				b, err := json.Marshal(todo.ToResponse())
+				if err != nil {
+					return err
+				}
+				if _, err := w.Write(b); err != nil {
+					return err
+				}
+			}
+			count++
+		}
+		if cw != nil {
+			cw.Flush()
+			return cw.Error()
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return count, result.Error
+	}
+
+	if cw == nil {
+		if _, err := io.WriteString(w, "]}\n"); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// todoCSVHeader TodoのCSVのヘッダー（todoRecordの列の順）
+var todoCSVHeader = []string{
+	"id", "title", "description", "completed", "priority", "due_date", "tags", "created_at", "updated_at",
+	"recurrence_rule", "recurrence_timezone", "skip_holidays", "recurrence_parent_id",
+}
+
+// todoRecord TodoのCSVの1行（日時はRFC 3339、終日の期限は日付のみ、タグは "|" 区切り）
+func todoRecord(todo *model.Todo) []string {
+	record := []string{
+		strconv.FormatUint(uint64(todo.ID), 10),
+		todo.Title,
+		todo.Description,
+		strconv.FormatBool(todo.Completed),
+		string(todo.Priority),
+		"",
+		strings.Join(todo.Tags, "|"),
+		todo.CreatedAt.Format(time.RFC3339),
+		todo.UpdatedAt.Format(time.RFC3339),
+		"",
+		todo.RecurrenceTimezone,
+		strconv.FormatBool(todo.SkipHolidays),
+		"",
+	}
+	if todo.DueDate != nil && todo.DueAllDay {
+		record[5] = todo.DueDate.Format(time.DateOnly)
+	} else if todo.DueDate != nil {
+		record[5] = todo.DueDate.Format(time.RFC3339)
+	}
+	if todo.RecurrenceRule != nil {
+		record[9] = *todo.RecurrenceRule
+	}
+	if todo.RecurrenceParentID != nil {
+		record[12] = strconv.FormatUint(uint64(*todo.RecurrenceParentID), 10)
+	}
+	return record
+}
+
+// List スナップショットを新しい順に取得（マニフェストのない作成途中・失敗したスナップショットは含めない）
+func (s *snapshotService) List(ctx context.Context) ([]*model.Snapshot, error) {
+	ctx, span := tracing.Start(ctx, "SnapshotService.List", tracing.SpanKindInternal)
+	defer span.End()
+
+	ids, err := s.ids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*model.Snapshot, 0, len(ids))
+	for _, id := range ids {
+		snapshot, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// ids マニフェストのあるスナップショットのIDを新しい順に取得
+func (s *snapshotService) ids(ctx context.Context) ([]string, error) {
+	objects, err := s.storage.List(ctx, snapshotPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("スナップショットの一覧の取得に失敗しました: %w", err)
+	}
+	var ids []string
+	for _, object := range objects {
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(object.Key, snapshotPrefix), "/manifest.json"); ok && !strings.Contains(id, "/") {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	slices.Reverse(ids)
+	return ids, nil
+}
+
+// Get IDでスナップショットのマニフェストを取得
+func (s *snapshotService) Get(ctx context.Context, id string) (*model.Snapshot, error) {
+	ctx, span := tracing.Start(ctx, "SnapshotService.Get", tracing.SpanKindInternal)
+	defer span.End()
+
+	r, _, err := s.storage.Get(ctx, manifestKey(id))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+	}
+	defer r.Close()
+
+	var snapshot model.Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("スナップショット %s のマニフェストを読み込めません: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// Open スナップショットの形式ごとのファイルを読み込む
+func (s *snapshotService) Open(ctx context.Context, id, format string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	ctx, span := tracing.Start(ctx, "SnapshotService.Open", tracing.SpanKindInternal)
+	defer span.End()
+
+	snapshot, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, file := range snapshot.Files {
+		if file.Format != format {
+			continue
+		}
+		r, info, err := s.storage.Get(ctx, file.Key)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, nil, ErrSnapshotNotFound
+			}
+			return nil, nil, fmt.Errorf("スナップショットのファイルの読み込みに失敗しました: %w", err)
+		}
+		return r, info, nil
+	}
+	return nil, nil, ErrSnapshotFormat
+}
+
+// Delete スナップショットを削除（マニフェストを先に削除し、一覧から外してからファイルを削除する）
+func (s *snapshotService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "SnapshotService.Delete", tracing.SpanKindInternal)
+	defer span.End()
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.remove(ctx, id)
+}
+
+// remove スナップショットのマニフェストとファイルを削除
+func (s *snapshotService) remove(ctx context.Context, id string) error {
+	if err := s.storage.Delete(ctx, manifestKey(id)); err != nil {
+		return fmt.Errorf("スナップショット %s の削除に失敗しました: %w", id, err)
+	}
+	objects, err := s.storage.List(ctx, snapshotPrefix+id+"/")
+	if err != nil {
+		return fmt.Errorf("スナップショット %s の削除に失敗しました: %w", id, err)
+	}
+	for _, object := range objects {
+		if err := s.storage.Delete(ctx, object.Key); err != nil {
+			return fmt.Errorf("スナップショット %s の削除に失敗しました: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// prune 保持する数を超えた古いスナップショットを削除
+func (s *snapshotService) prune(ctx context.Context) error {
+	if s.keep <= 0 {
+		return nil
+	}
+	ids, err := s.ids(ctx)
+	if err != nil || len(ids) <= s.keep {
+		return err
+	}
+	for _, id := range ids[s.keep:] {
+		if err := s.remove(ctx, id); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "保持する数を超えた古いスナップショットを削除しました", "id", id)
+	}
+	return nil
+}
+
+// manifestKey スナップショットのマニフェストのキー
+func manifestKey(id string) string {
+	return snapshotPrefix + id + "/manifest.json"
+}
+
+// countingWriter 書き込んだバイト数を数える
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/notify"
+	"myapp/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// staleBatchSize 1回の実行で検出するTodoの上限（残りは次回の実行で処理する）
+const staleBatchSize = 500
+
+// 放置タスクを検出したときに行うこと
+const (
+	// StaleActionFlag 「レビューが必要」のフラグ（needs_review）を付ける
+	StaleActionFlag = "flag"
+	// StaleActionNotify 通知する（stale イベントを通知する設定の送信先へ送る）
+	StaleActionNotify = "notify"
+	// StaleActionBoth フラグを付けて通知する
+	StaleActionBoth = "both"
+)
+
+// StaleService 長期間更新されていない未完了のTodo（放置タスク）を検出するサービスのインターフェース
+type StaleService interface {
+	// List 未完了で、olderThanより長く更新されていないTodoを更新が古い順に取得し、判定の基準にした日時を返す（olderThanが0の場合は設定値）
+	List(ctx context.Context, olderThan time.Duration, limit int) ([]*model.Todo, time.Time, error)
+	// Detect 新たに放置タスクになったTodoにフラグを付ける・通知し、検出した件数を返す
+	Detect(ctx context.Context) (int, error)
+}
+
+// staleService 放置タスクのサービスの実装
+type staleService struct {
+	db *gorm.DB
+	// after 最後の更新からこの期間を過ぎた未完了のTodoを放置タスクとする
+	after time.Duration
+	// action 検出したTodoに行うこと（StaleActionFlag / StaleActionNotify / StaleActionBoth）
+	action string
+}
+
+// NewStaleService 新しい放置タスクのサービスインスタンスを作成
+func NewStaleService(after time.Duration, action string) StaleService {
+	return &staleService{
+		db:     db.GetDB(),
+		after:  after,
+		action: action,
+	}
+}
+
+// List 放置タスクを更新が古い順に取得し、判定の基準にした日時（これより前から更新されていない）とともに返す
+func (s *staleService) List(ctx context.Context, olderThan time.Duration, limit int) ([]*model.Todo, time.Time, error) {
+	ctx, span := tracing.Start(ctx, "StaleService.List", tracing.SpanKindInternal)
+	defer span.End()
+
+	if olderThan <= 0 {
+		olderThan = s.after
+	}
+	cutoff := time.Now().Add(-olderThan)
+
+	var todos []*model.Todo
+	err := s.db.WithContext(ctx).Select(todoColumns).
+		Where("completed = ? AND updated_at < ?", false, cutoff).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, cutoff, fmt.Errorf("放置タスクの取得に失敗しました: %w", err)
+	}
+	return todos, cutoff, nil
+}
+
+// Detect 放置タスクのうちまだ検出していないものを更新が古い順に検出する
+// 検出は更新されるまで1回だけで、フラグの付与は更新日時を変えずに行う（Todoを更新するとフラグと検出の記録が外れる）
+func (s *staleService) Detect(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "StaleService.Detect", tracing.SpanKindInternal)
+	defer span.End()
+
+	now := time.Now()
+	var todos []*model.Todo
+	err := s.db.WithContext(ctx).Select(todoColumns).
+		Where("completed = ? AND stale_detected_at IS NULL AND updated_at < ?", false, now.Add(-s.after)).
+		Order("updated_at, id").
+		Limit(staleBatchSize).
+		Find(&todos).Error
+	if err != nil {
+		return 0, fmt.Errorf("放置タスクの取得に失敗しました: %w", err)
+	}
+	if len(todos) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	flag := s.action == StaleActionFlag || s.action == StaleActionBoth
+	updates := map[string]any{"stale_detected_at": now}
+	if flag {
+		updates["needs_review"] = true
+	}
+	// 取得後に更新されたTodoは対象から外す
+	result := s.db.WithContext(ctx).Model(&model.Todo{}).
+		Where("id IN ? AND stale_detected_at IS NULL AND updated_at < ?", ids, now.Add(-s.after)).
+		UpdateColumns(updates)
+	if result.Error != nil {
+		return 0, fmt.Errorf("放置タスクの記録に失敗しました: %w", result.Error)
+	}
+
+	if s.action == StaleActionNotify || s.action == StaleActionBoth {
+		for _, todo := range todos {
+			todo.NeedsReview = flag
+			days := int(now.Sub(todo.UpdatedAt).Hours() / 24)
+			notify.Publish(ctx, notify.Event{Type: notify.EventStale, Todo: *todo, OccurredAt: now, Note: fmt.Sprintf("（%d日間更新なし）", days)})
+		}
+	}
+
+	slog.InfoContext(ctx, "放置タスクを検出しました", "count", result.RowsAffected, "action", s.action, "after", s.after)
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"myapp/db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dueBefore 期限がtより前のTodoの条件（model.Todo.DueBefore と同じ判定）
+// 終日の期限はlocでのその日の終わりを期限とするため、locでのtの日付より前の日付を対象とする
+func dueBefore(t time.Time, loc *time.Location) clause.Expr {
+	return gorm.Expr("((due_all_day = ? AND due_date < ?) OR (due_all_day = ? AND due_date < ?))",
+		false, t, true, model.AllDayCutoff(t, loc))
+}
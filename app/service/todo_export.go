@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/sanitize"
+	"myapp/tracing"
+
+	"gorm.io/gorm"
+)
+
+// ExportFormat ストリーミングで書き出す形式
+type ExportFormat string
+
+const (
+	// ExportJSONLines 1行に1件のJSON（JSON Lines / NDJSON）
+	ExportJSONLines ExportFormat = "jsonl"
+	// ExportCSV ヘッダー付きのCSV（列はスナップショットのCSVと同じ）
+	ExportCSV ExportFormat = "csv"
+)
+
+// exportBatchSize エクスポートで1回に読み込むTodoの件数（メモリに載せるのはこの件数まで）
+const exportBatchSize = 500
+
+// ExportTodos 絞り込みの条件に一致するTodoをID順に読みながらwへ1行ずつ書き出し、件数を返す（qのCursor・Limitは使わない）
+// 全件をメモリに載せず、exportBatchSize件ずつ id の範囲で読む。wへはバッチごと（またはバッファが一杯になるごと）にまとめて書き込む
+func (s *todoService) ExportTodos(ctx context.Context, q *TodoPageQuery, format ExportFormat, w io.Writer) (int, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.ExportTodos", tracing.SpanKindInternal)
+	defer span.End()
+
+	if q.Priority != "" && !q.Priority.IsValid() {
+		return 0, errcode.Errorf(errcode.InvalidPriority, "無効な優先度です: %s", q.Priority)
+	}
+
+	bw := bufio.NewWriterSize(w, 32<<10)
+	var cw *csv.Writer
+	switch format {
+	case ExportJSONLines:
+	case ExportCSV:
+		cw = csv.NewWriter(bw)
+		if err := cw.Write(todoCSVHeader); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, errcode.Errorf(errcode.UnsupportedFormat, "未対応の形式です: %s", format)
+	}
+
+	query := s.db.WithContext(ctx).Select(todoColumns)
+	if q.Priority != "" {
+		query = query.Where("priority = ?", q.Priority)
+	}
+	if q.Completed != nil {
+		query = query.Where("completed = ?", *q.Completed)
+	}
+
+	count := 0
+	var todos []*model.Todo
+	result := query.FindInBatches(&todos, exportBatchSize, func(_ *gorm.DB, _ int) error {
+		for _, todo := range todos {
+			// APIのレスポンスと同じく出力時のサニタイズを適用する
+			todo.Description = sanitize.OnOutput(todo.Description)
+			if cw != nil {
+				if err := cw.Write(todoRecord(todo)); err != nil {
+					return err
+				}
+			} else {
+				b, err := json.Marshal(todo.ToResponse())
+				if err != nil {
+					return err
+				}
+				if _, err := bw.Write(append(b, '\n')); err != nil {
+					return err
+				}
+			}
+			count++
+		}
+		if cw != nil {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	})
+	if result.Error != nil {
+		return count, fmt.Errorf("Todoのエクスポートに失敗しました: %w", result.Error)
+	}
+
+	// 0件の場合もCSVのヘッダーを書き出す
+	if cw != nil {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return count, err
+		}
+	}
+	return count, bw.Flush()
+}
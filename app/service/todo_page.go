@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/tracing"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor ページングのカーソルが読めない
+var ErrInvalidCursor = errcode.New(errcode.InvalidCursor, "カーソルが正しくありません")
+
+// CountMode 総件数の求め方
+type CountMode string
+
+const (
+	// CountExact COUNT(*)で数える（クエリキャッシュが有効な場合は書き込みまでキャッシュする）
+	CountExact CountMode = "exact"
+	// CountEstimated 統計情報（pg_class.reltuples・実行計画の推定行数）から推定する
+	CountEstimated CountMode = "estimated"
+	// CountNone 数えない
+	CountNone CountMode = "none"
+)
+
+// countExactBelow 推定値がこれより少ない場合は正確に数える（小さいテーブルは数えても速く、推定値の誤差が目立つため）
+const countExactBelow = 1000
+
+// TodoCount 総件数
+type TodoCount struct {
+	Total int64 `json:"total"`
+	// Estimated 推定値か（削除済み・統計情報の更新前の行を含むなど、実際の件数とずれることがある）
+	Estimated bool `json:"estimated"`
+}
+
+// TodoPageQuery Todoの一覧を1ページずつ取得する条件
+type TodoPageQuery struct {
+	// Priority 絞り込む優先度（空の場合は絞り込まない）
+	Priority model.Priority
+	// Completed 絞り込む完了状態（nilの場合は絞り込まない）
+	Completed *bool
+	// Cursor 前のページのNextCursor（空の場合は先頭のページ）
+	Cursor string
+	// Limit 1ページの件数
+	Limit int
+}
+
+// TodoPage Todoの一覧の1ページ
+type TodoPage struct {
+	Todos []*model.Todo
+	// NextCursor 次のページを取得するカーソル（最後のページの場合は空）
+	NextCursor string
+}
+
+// GetTodosPage Todoを一覧の並び順（プロファイルの default_sort）で1ページ取得する
+// 並び順のキー（created_at, id 等）で前のページの最後の行より後ろを読むキーセット方式のため、OFFSETと違い深いページでも読み飛ばす行が増えない
+func (s *todoService) GetTodosPage(ctx context.Context, q *TodoPageQuery) (*TodoPage, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetTodosPage", tracing.SpanKindInternal)
+	defer span.End()
+
+	if q.Priority != "" && !q.Priority.IsValid() {
+		return nil, errcode.Errorf(errcode.InvalidPriority, "無効な優先度です: %s", q.Priority)
+	}
+
+	query := s.db.WithContext(ctx).Select(todoColumns)
+	if q.Priority != "" {
+		query = query.Where("priority = ?", q.Priority)
+	}
+	if q.Completed != nil {
+		query = query.Where("completed = ?", *q.Completed)
+	}
+	sort := s.profiles.DefaultSort(ctx)
+	if q.Cursor != "" {
+		cursor, err := decodeTodoCursor(q.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		query = cursor.after(query)
+	}
+
+	// 1件多く読み、次のページがあるかを判定する
+	var todos []*model.Todo
+	result := query.Order(todoOrder(sort)).Limit(q.Limit + 1).Find(&todos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", result.Error)
+	}
+
+	page := &TodoPage{Todos: todos}
+	if len(todos) > q.Limit {
+		page.Todos = todos[:q.Limit]
+		last := page.Todos[q.Limit-1]
+		page.NextCursor = encodeTodoCursor(sort, last)
+	}
+	return page, nil
+}
+
+// CountTodos 絞り込みの条件に一致するTodoの総件数を返す（qのCursor・Limitは使わない）
+// CountEstimatedの場合、絞り込みがなければpg_class.reltuples、あれば実行計画の推定行数を返す
+func (s *todoService) CountTodos(ctx context.Context, q *TodoPageQuery, mode CountMode) (*TodoCount, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.CountTodos", tracing.SpanKindInternal)
+	defer span.End()
+
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+	if q.Priority != "" {
+		conditions = append(conditions, "priority = ?")
+		args = append(args, q.Priority)
+	}
+	if q.Completed != nil {
+		conditions = append(conditions, "completed = ?")
+		args = append(args, *q.Completed)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	if mode == CountEstimated {
+		estimate, err := s.estimateTodos(ctx, where, args, len(conditions) == 1)
+		if err != nil {
+			return nil, err
+		}
+		if estimate >= countExactBelow {
+			return &TodoCount{Total: estimate, Estimated: true}, nil
+		}
+	}
+
+	var total int64
+	if err := s.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM todos WHERE "+where, args...).Scan(&total).Error; err != nil {
+		return nil, fmt.Errorf("Todoの件数の取得に失敗しました: %w", err)
+	}
+	return &TodoCount{Total: total}, nil
+}
+
+// estimateTodos 統計情報からTodoの件数を推定する（統計情報がまだない場合は-1）
+func (s *todoService) estimateTodos(ctx context.Context, where string, args []any, unfiltered bool) (int64, error) {
+	if unfiltered {
+		var reltuples float64
+		err := s.db.WithContext(ctx).Raw("SELECT reltuples FROM pg_class WHERE oid = 'todos'::regclass").Scan(&reltuples).Error
+		if err != nil {
+			return 0, fmt.Errorf("Todoの推定件数の取得に失敗しました: %w", err)
+		}
+		return int64(reltuples), nil
+	}
+
+	var plan string
+	err := s.db.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) SELECT 1 FROM todos WHERE "+where, args...).Row().Scan(&plan)
+	if err != nil {
+		return 0, fmt.Errorf("Todoの推定件数の取得に失敗しました: %w", err)
+	}
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil || len(explained) == 0 {
+		return 0, fmt.Errorf("Todoの推定件数の取得に失敗しました: 実行計画を読めません")
+	}
+	return int64(explained[0].Plan.PlanRows), nil
+}
+
+// priorityRankSQL 優先度の大小比較用の値（model.Priority.Rank と同じ）
+const priorityRankSQL = "CASE priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END"
+
+// todoOrder 一覧の並び順のORDER BY（同じ値の間は作成日時の新しい順）
+func todoOrder(sort model.TodoSort) string {
+	switch sort {
+	case model.TodoSortDueDate:
+		return "due_date IS NULL, due_date, created_at DESC, id DESC"
+	case model.TodoSortPriority:
+		return priorityRankSQL + " DESC, created_at DESC, id DESC"
+	default:
+		return "created_at DESC, id DESC"
+	}
+}
+
+// todoCursor 前のページの最後の行の、並び順のキーの値
+type todoCursor struct {
+	sort      model.TodoSort
+	rank      int
+	dueDate   *time.Time
+	createdAt time.Time
+	id        uint
+}
+
+// after 並び順でカーソルの行より後ろの行に絞り込む
+func (c *todoCursor) after(query *gorm.DB) *gorm.DB {
+	switch c.sort {
+	case model.TodoSortDueDate:
+		// 期限は昇順・作成日時は降順のため、行値の比較ではなく条件を分けて書く
+		if c.dueDate == nil {
+			return query.Where("due_date IS NULL AND (created_at, id) < (?, ?)", c.createdAt, c.id)
+		}
+		return query.Where("(due_date > ? OR due_date IS NULL OR (due_date = ? AND (created_at, id) < (?, ?)))",
+			*c.dueDate, *c.dueDate, c.createdAt, c.id)
+	case model.TodoSortPriority:
+		return query.Where("("+priorityRankSQL+", created_at, id) < (?, ?, ?)", c.rank, c.createdAt, c.id)
+	default:
+		return query.Where("(created_at, id) < (?, ?)", c.createdAt, c.id)
+	}
+}
+
+// encodeTodoCursor 行の並び順のキーの値をカーソルにする
+// 作成日時の新しい順は「作成日時（マイクロ秒）.ID」、それ以外は先頭に並び順と、期限（マイクロ秒。期限なしは空）または優先度の値を付ける
+func encodeTodoCursor(sort model.TodoSort, todo *model.Todo) string {
+	raw := strconv.FormatInt(todo.CreatedAt.UnixMicro(), 10) + "." + strconv.FormatUint(uint64(todo.ID), 10)
+	switch sort {
+	case model.TodoSortDueDate:
+		due := ""
+		if todo.DueDate != nil {
+			due = strconv.FormatInt(todo.DueDate.UnixMicro(), 10)
+		}
+		raw = string(sort) + "." + due + "." + raw
+	case model.TodoSortPriority:
+		raw = string(sort) + "." + strconv.Itoa(todo.Priority.Rank()) + "." + raw
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTodoCursor カーソルから並び順のキーの値を取り出す（カーソルを作った後に並び順が変わった場合もエラー）
+func decodeTodoCursor(cursor string, sort model.TodoSort) (*todoCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ".")
+	c := &todoCursor{sort: model.TodoSortCreatedAt}
+	switch len(parts) {
+	case 2:
+	case 4:
+		c.sort = model.TodoSort(parts[0])
+		switch c.sort {
+		case model.TodoSortDueDate:
+			if parts[1] != "" {
+				unixMicro, err := strconv.ParseInt(parts[1], 10, 64)
+				if err != nil {
+					return nil, ErrInvalidCursor
+				}
+				due := time.UnixMicro(unixMicro)
+				c.dueDate = &due
+			}
+		case model.TodoSortPriority:
+			if c.rank, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, ErrInvalidCursor
+			}
+		default:
+			return nil, ErrInvalidCursor
+		}
+		parts = parts[2:]
+	default:
+		return nil, ErrInvalidCursor
+	}
+	if c.sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	unixMicro, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c.createdAt = time.UnixMicro(unixMicro)
+	c.id = uint(id)
+	return c, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"myapp/config"
+	"myapp/db/model"
+	"myapp/errcode"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 過去の期限の扱い（validation.past_due_date）
+const (
+	// PastDueAllow 過去の期限をそのまま受け付ける（既定）
+	PastDueAllow = "allow"
+	// PastDueWarn 作成・更新した上で、レスポンスに警告を含める
+	PastDueWarn = "warn"
+	// PastDueReject 422で拒否する
+	PastDueReject = "reject"
+)
+
+// ErrDueDateInPast 期限が過去の日時（validation.past_due_date が reject の場合はエラー、warn の場合は警告）
+var ErrDueDateInPast = errcode.New(errcode.DueDateInPast, "期限が過去の日時です")
+
+// Warning 作成・更新はできたが、利用者に知らせたい内容（フィールド単位）
+type Warning struct {
+	Field   string       `json:"field" doc:"警告の対象のフィールド（リクエストボディのJSONでの位置）"`
+	Code    errcode.Code `json:"code" doc:"警告の理由のコード"`
+	Message string       `json:"message" doc:"警告の理由"`
+}
+
+// Warnings リクエスト中にサービスが追加した警告（WithWarnings で作り、コンテキストで受け渡す）
+type Warnings struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+type warningsKey struct{}
+
+// WithWarnings 警告を集めるコンテキストを返す（ハンドラーがサービスを呼ぶ前に作り、呼んだ後に List で取り出す）
+func WithWarnings(ctx context.Context) (context.Context, *Warnings) {
+	w := &Warnings{}
+	return context.WithValue(ctx, warningsKey{}, w), w
+}
+
+// List 追加された警告（追加された順）
+func (w *Warnings) List() []Warning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.list)
+}
+
+// addWarning コンテキストに警告を追加する（WithWarnings で作ったコンテキストでない場合は何もしない）
+func addWarning(ctx context.Context, field string, err *errcode.Error) {
+	w, ok := ctx.Value(warningsKey{}).(*Warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, Warning{Field: field, Code: err.Code, Message: err.Error()})
+}
+
+// pastDuePolicy 過去の期限の検査に使う設定と利用者のタイムゾーン（一括作成ではTodoごとにプロファイルを読まないよう1度だけ求める）
+type pastDuePolicy struct {
+	policy string
+	loc    *time.Location
+	now    time.Time
+}
+
+// pastDuePolicy 現在の validation.past_due_date と利用者のタイムゾーンを求める（allowの場合はタイムゾーンを読まない）
+// 設定は config.Current() を参照するためホットリロードで変更できる
+func (s *todoService) pastDuePolicy(ctx context.Context) pastDuePolicy {
+	p := pastDuePolicy{policy: config.Current().Validation.PastDueDate, now: time.Now()}
+	if p.policy != PastDueAllow && p.policy != "" {
+		p.loc = s.profiles.Location(ctx)
+	}
+	return p
+}
+
+// checkPastDueDate 期限が過去の場合に validation.past_due_date に従ってエラー・警告を追加する
+// 終日の期限は利用者のタイムゾーンでの今日を過去としない
+func (s *todoService) checkPastDueDate(ctx context.Context, invalid *errcode.ValidationError, field string, due *time.Time, allDay bool) {
+	s.pastDuePolicy(ctx).check(ctx, invalid, field, due, allDay)
+}
+
+// check 期限が過去の場合にポリシーに従ってエラー・警告を追加する
+func (p pastDuePolicy) check(ctx context.Context, invalid *errcode.ValidationError, field string, due *time.Time, allDay bool) {
+	if due == nil || p.loc == nil {
+		return
+	}
+	todo := &model.Todo{DueDate: due, DueAllDay: allDay}
+	if !todo.DueBefore(p.now, p.loc) {
+		return
+	}
+	switch p.policy {
+	case PastDueReject:
+		invalid.Add(field, *due, ErrDueDateInPast)
+	case PastDueWarn:
+		addWarning(ctx, field, ErrDueDateInPast)
+	}
+}
@@ -1,42 +1,95 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"myapp/cache"
 	"myapp/db"
 	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/notify"
+	"myapp/recurrence"
+	"myapp/sanitize"
+	"myapp/tracing"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
 
+// Todoの作成・更新・取得のエラー（メッセージに値を含む場合も errors.Is で判定できる）
+// 作成・更新のリクエストの検証エラーは、フィールドごとに *errcode.ValidationError にまとめて返す
+var (
+	// ErrTodoNotFound Todoが存在しない（削除済みを含む）
+	ErrTodoNotFound = errcode.New(errcode.TodoNotFound, "Todoが見つかりません")
+	// ErrTitleRequired タイトルが空（空白のみを含む）
+	ErrTitleRequired = errcode.New(errcode.TitleRequired, "タイトルは必須です")
+	// ErrInvalidPriority 優先度が low / medium / high / urgent 以外
+	ErrInvalidPriority = errcode.New(errcode.InvalidPriority, "無効な優先度です")
+	// ErrInvalidTag タグが長すぎる
+	ErrInvalidTag = errcode.New(errcode.InvalidTag, "タグが正しくありません")
+	// ErrTooManyTags タグの数が上限を超えている
+	ErrTooManyTags = errcode.New(errcode.TooManyTags, "タグが多すぎます")
+	// ErrInvalidTimezone タイムゾーンを読み込めない
+	ErrInvalidTimezone = errcode.New(errcode.InvalidTimezone, "タイムゾーンを読み込めません")
+	// ErrInvalidRecurrenceRule 繰り返しルールを解釈できない
+	ErrInvalidRecurrenceRule = errcode.New(errcode.InvalidRecurrenceRule, "無効な繰り返しルールです")
+	// ErrDueDateRequired 繰り返しTodoに期限がない
+	ErrDueDateRequired = errcode.New(errcode.DueDateRequired, "繰り返しTodoには期限を指定してください")
+	// ErrDueDateForAllDay 期限を日付のみ（終日）にするかを切り替えたが、新しい期限がない
+	ErrDueDateForAllDay = errcode.New(errcode.DueDateRequired, "終日の期限にするかを切り替える場合は期限も指定してください")
+)
+
 // TodoService Todoサービスのインターフェース
 type TodoService interface {
-	GetAllTodos() ([]*model.Todo, error)
-	GetTodoByID(id uint) (*model.Todo, error)
-	CreateTodo(req *model.TodoCreateRequest) (*model.Todo, error)
-	UpdateTodo(id uint, req *model.TodoUpdateRequest) (*model.Todo, error)
-	DeleteTodo(id uint) error
-	GetTodosByPriority(priority model.Priority) ([]*model.Todo, error)
-	GetCompletedTodos() ([]*model.Todo, error)
-	GetPendingTodos() ([]*model.Todo, error)
+	GetAllTodos(ctx context.Context) ([]*model.Todo, error)
+	GetTodoByID(ctx context.Context, id uint) (*model.Todo, error)
+	CreateTodo(ctx context.Context, req *model.TodoCreateRequest) (*model.Todo, error)
+	// CreateTodos 複数のTodoを1つのトランザクションでbatchSize件ずつまとめて作成する（1件でも不正な場合は作成しない）
+	CreateTodos(ctx context.Context, reqs []*model.TodoCreateRequest, batchSize int) ([]*model.Todo, error)
+	UpdateTodo(ctx context.Context, id uint, req *model.TodoUpdateRequest) (*model.Todo, error)
+	DeleteTodo(ctx context.Context, id uint) error
+	GetTodosByPriority(ctx context.Context, priority model.Priority) ([]*model.Todo, error)
+	GetCompletedTodos(ctx context.Context) ([]*model.Todo, error)
+	GetPendingTodos(ctx context.Context) ([]*model.Todo, error)
+	// GetTodosPage 一覧の並び順（プロファイルの default_sort）で1ページ取得（キーセット方式）
+	GetTodosPage(ctx context.Context, q *TodoPageQuery) (*TodoPage, error)
+	// CountTodos 絞り込みの条件に一致する総件数（modeで正確な件数か推定値かを選ぶ）
+	CountTodos(ctx context.Context, q *TodoPageQuery, mode CountMode) (*TodoCount, error)
+	// ExportTodos 絞り込みの条件に一致するTodoを全件メモリに載せずに1行ずつ書き出す（JSON Lines / CSV）
+	ExportTodos(ctx context.Context, q *TodoPageQuery, format ExportFormat, w io.Writer) (int, error)
 }
 
+// todoColumns 一覧・取得時にSELECTするカラム（model.TodoColumns）
+var todoColumns = model.TodoColumns
+
 // todoService Todoサービスの実装
 type todoService struct {
 	db *gorm.DB
+	// profiles 過去の期限の判定に使う利用者のタイムゾーン
+	profiles ProfileService
 }
 
 // NewTodoService 新しいTodoサービスインスタンスを作成
-func NewTodoService() TodoService {
+func NewTodoService(profiles ProfileService) TodoService {
 	return &todoService{
-		db: db.GetDB(),
+		db:       db.GetDB(),
+		profiles: profiles,
 	}
 }
 
-// GetAllTodos 全てのTodoを取得
-func (s *todoService) GetAllTodos() ([]*model.Todo, error) {
+// GetAllTodos 全てのTodoを一覧の並び順（プロファイルの default_sort）で取得
+func (s *todoService) GetAllTodos(ctx context.Context) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetAllTodos", tracing.SpanKindInternal)
+	defer span.End()
+
 	var todos []*model.Todo
 
-	result := s.db.Order("created_at DESC").Find(&todos)
+	result := s.db.WithContext(ctx).Select(todoColumns).Order(todoOrder(s.profiles.DefaultSort(ctx))).Find(&todos)
 	if result.Error != nil {
 		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", result.Error)
 	}
@@ -45,13 +98,17 @@ func (s *todoService) GetAllTodos() ([]*model.Todo, error) {
 }
 
 // GetTodoByID IDで特定のTodoを取得
-func (s *todoService) GetTodoByID(id uint) (*model.Todo, error) {
+func (s *todoService) GetTodoByID(ctx context.Context, id uint) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetTodoByID", tracing.SpanKindInternal)
+	defer span.End()
+
 	var todo model.Todo
 
-	result := s.db.First(&todo, id)
+	// 主キー検索のためORDER BYを伴うFirstではなくTakeを使用
+	result := s.db.WithContext(ctx).Select(todoColumns).Take(&todo, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("ID %d のTodoが見つかりません", id)
+			return nil, errcode.Errorf(errcode.TodoNotFound, "ID %d のTodoが見つかりません", id)
 		}
 		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", result.Error)
 	}
@@ -60,10 +117,83 @@ func (s *todoService) GetTodoByID(id uint) (*model.Todo, error) {
 }
 
 // CreateTodo 新しいTodoを作成
-func (s *todoService) CreateTodo(req *model.TodoCreateRequest) (*model.Todo, error) {
+func (s *todoService) CreateTodo(ctx context.Context, req *model.TodoCreateRequest) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.CreateTodo", tracing.SpanKindInternal)
+	defer span.End()
+
+	todo, err := newTodo(req)
+	if err != nil {
+		return nil, err
+	}
+	invalid := &errcode.ValidationError{}
+	s.checkPastDueDate(ctx, invalid, "due_date", todo.DueDate, todo.DueAllDay)
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	result := s.db.WithContext(ctx).Create(todo)
+	if result.Error != nil {
+		return nil, fmt.Errorf("Todoの作成に失敗しました: %w", result.Error)
+	}
+
+	notify.Publish(ctx, notify.Event{Type: notify.EventCreated, Todo: *todo})
+	events.PublishTodo(ctx, events.TodoCreated, todo)
+
+	return todo, nil
+}
+
+// CreateTodos 全てのリクエストを検証してから、1つのトランザクションでbatchSize件ずつ複数行のINSERTで作成する
+// 1件ずつ作成するより往復が少なく、途中で失敗した場合は全て作成しない。作成の通知（Slack等）は取り込みと同じく送らず、ドメインイベントのみ発行する
+func (s *todoService) CreateTodos(ctx context.Context, reqs []*model.TodoCreateRequest, batchSize int) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.CreateTodos", tracing.SpanKindInternal)
+	defer span.End()
+
+	// 最初の不正なTodoで打ち切らず、全てのTodoの不正なフィールドを todos[i].priority 等の位置で返す
+	todos := make([]*model.Todo, len(reqs))
+	invalid := &errcode.ValidationError{}
+	pastDue := s.pastDuePolicy(ctx)
+	for i, req := range reqs {
+		prefix := fmt.Sprintf("todos[%d]", i)
+		todo, err := newTodo(req)
+		if v, ok := errcode.AsValidation(err); ok {
+			invalid.Merge(prefix, v)
+			continue
+		}
+		pastDue.check(ctx, invalid, prefix+".due_date", todo.DueDate, todo.DueAllDay)
+		todos[i] = todo
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, errcode.Errorf(errcode.BulkInvalidItem, "作成するTodoの内容が正しくありません: %w", err)
+	}
+
+	err := cache.Transaction(ctx, s.db, func(tx *gorm.DB) error {
+		return tx.CreateInBatches(todos, batchSize).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Todoの一括作成に失敗しました: %w", err)
+	}
+
+	for _, todo := range todos {
+		events.PublishTodo(ctx, events.TodoCreated, todo)
+	}
+	return todos, nil
+}
+
+// newTodo 作成リクエストを検証し、作成するTodoを組み立てる
+// 不正なフィールドがある場合は全てのフィールドを検証してから *errcode.ValidationError で返す
+func newTodo(req *model.TodoCreateRequest) (*model.Todo, error) {
+	invalid := &errcode.ValidationError{}
+	validateTitle(invalid, req.Title)
+
 	// 優先度の検証
 	if req.Priority != "" && !req.Priority.IsValid() {
-		return nil, fmt.Errorf("無効な優先度です: %s", req.Priority)
+		invalid.Add("priority", req.Priority, errcode.Errorf(errcode.InvalidPriority, "無効な優先度です: %s", req.Priority))
+	}
+
+	tags := normalizeTags(invalid, req.Tags)
+	validateRecurrence(invalid, req.RecurrenceRule, req.RecurrenceTimezone, req.DueDate)
+	if err := invalid.Err(); err != nil {
+		return nil, err
 	}
 
 	// デフォルト優先度の設定
@@ -72,86 +202,261 @@ func (s *todoService) CreateTodo(req *model.TodoCreateRequest) (*model.Todo, err
 	}
 
 	todo := &model.Todo{
-		Title:       req.Title,
-		Description: req.Description,
-		Priority:    req.Priority,
-		DueDate:     req.DueDate,
-		Completed:   false,
+		Title:              req.Title,
+		Description:        sanitize.OnSave(req.Description),
+		Priority:           req.Priority,
+		DueDate:            req.DueDate,
+		Tags:               tags,
+		Completed:          false,
+		RecurrenceTimezone: req.RecurrenceTimezone,
+		SkipHolidays:       req.SkipHolidays,
 	}
-
-	result := s.db.Create(todo)
-	if result.Error != nil {
-		return nil, fmt.Errorf("Todoの作成に失敗しました: %w", result.Error)
+	if req.DueAllDay && req.DueDate != nil {
+		due := model.AllDayDate(*req.DueDate)
+		todo.DueDate = &due
+		todo.DueAllDay = true
+	}
+	if req.RecurrenceRule != "" {
+		todo.RecurrenceRule = &req.RecurrenceRule
 	}
-
 	return todo, nil
 }
 
 // UpdateTodo 既存のTodoを更新
-func (s *todoService) UpdateTodo(id uint, req *model.TodoUpdateRequest) (*model.Todo, error) {
+func (s *todoService) UpdateTodo(ctx context.Context, id uint, req *model.TodoUpdateRequest) (*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.UpdateTodo", tracing.SpanKindInternal)
+	defer span.End()
+
 	// 既存のTodoを取得
-	todo, err := s.GetTodoByID(id)
+	todo, err := s.GetTodoByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// 更新フィールドの適用
+	// 変更のあったフィールドのみを差分として収集（不正なフィールドは全て検証してからまとめて返す）
+	invalid := &errcode.ValidationError{}
+	updates := make(map[string]interface{})
 	if req.Title != nil {
-		todo.Title = *req.Title
+		validateTitle(invalid, *req.Title)
+		if *req.Title != todo.Title {
+			updates["title"] = *req.Title
+		}
 	}
 	if req.Description != nil {
-		todo.Description = *req.Description
+		if description := sanitize.OnSave(*req.Description); description != todo.Description {
+			updates["description"] = description
+		}
 	}
-	if req.Completed != nil {
-		todo.Completed = *req.Completed
+	if req.Completed != nil && *req.Completed != todo.Completed {
+		updates["completed"] = *req.Completed
+		updates["completed_at"] = completedAt(*req.Completed)
 	}
 	if req.Priority != nil {
 		if !req.Priority.IsValid() {
-			return nil, fmt.Errorf("無効な優先度です: %s", *req.Priority)
+			invalid.Add("priority", *req.Priority, errcode.Errorf(errcode.InvalidPriority, "無効な優先度です: %s", *req.Priority))
+		} else if *req.Priority != todo.Priority {
+			updates["priority"] = *req.Priority
+		}
+	}
+	if req.DueAllDay != nil && *req.DueAllDay != todo.DueAllDay {
+		// 時刻のある期限と日付のみの期限は相互に変換できないため、切り替える場合は期限も指定する
+		if req.DueDate == nil && todo.DueDate != nil {
+			invalid.Add("due_date", nil, ErrDueDateForAllDay)
 		}
-		todo.Priority = *req.Priority
+		updates["due_all_day"] = *req.DueAllDay
 	}
 	if req.DueDate != nil {
-		todo.DueDate = req.DueDate
+		dueDate := *req.DueDate
+		if (req.DueAllDay == nil && todo.DueAllDay) || (req.DueAllDay != nil && *req.DueAllDay) {
+			dueDate = model.AllDayDate(dueDate)
+		}
+		if todo.DueDate == nil || !dueDate.Equal(*todo.DueDate) {
+			updates["due_date"] = dueDate
+			// 期限を変更した場合のみ判定する（過去の期限のまま他のフィールドを更新することはできる）
+			allDay := todo.DueAllDay
+			if req.DueAllDay != nil {
+				allDay = *req.DueAllDay
+			}
+			s.checkPastDueDate(ctx, invalid, "due_date", &dueDate, allDay)
+		}
+	}
+
+	if req.Tags != nil {
+		if tags := normalizeTags(invalid, *req.Tags); !slices.Equal(tags, todo.Tags) {
+			updates["tags"] = tags
+		}
 	}
 
-	result := s.db.Save(todo)
+	if req.RecurrenceRule != nil || req.RecurrenceTimezone != nil || req.SkipHolidays != nil {
+		rule, timezone := "", todo.RecurrenceTimezone
+		if todo.RecurrenceRule != nil {
+			rule = *todo.RecurrenceRule
+		}
+		if req.RecurrenceRule != nil {
+			rule = *req.RecurrenceRule
+			if rule == "" {
+				updates["recurrence_rule"] = nil
+			} else {
+				updates["recurrence_rule"] = rule
+			}
+		}
+		if req.RecurrenceTimezone != nil {
+			timezone = *req.RecurrenceTimezone
+			updates["recurrence_timezone"] = timezone
+		}
+		if req.SkipHolidays != nil {
+			updates["skip_holidays"] = *req.SkipHolidays
+		}
+		dueDate := todo.DueDate
+		if req.DueDate != nil {
+			dueDate = req.DueDate
+		}
+		validateRecurrence(invalid, rule, timezone, dueDate)
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	// 放置タスクとして検出されたTodoは、変更がなくても更新したことで見直し済みとする
+	if todo.StaleDetectedAt != nil {
+		updates["needs_review"] = false
+		updates["stale_detected_at"] = nil
+	}
+
+	// 変更がなければUPDATEを発行しない
+	if len(updates) == 0 {
+		return todo, nil
+	}
+
+	// 全カラムを書き戻すSave()ではなく差分のみUPDATE（updated_atはGORMが自動更新）
+	result := s.db.WithContext(ctx).Model(todo).Updates(updates)
 	if result.Error != nil {
 		return nil, fmt.Errorf("Todoの更新に失敗しました: %w", result.Error)
 	}
 
+	if completed, ok := updates["completed"].(bool); ok && completed {
+		notify.Publish(ctx, notify.Event{Type: notify.EventCompleted, Todo: *todo})
+	}
+	events.PublishTodo(ctx, events.TodoUpdated, todo)
+
 	return todo, nil
 }
 
-// DeleteTodo Todoを削除（ソフトデリート）
-func (s *todoService) DeleteTodo(id uint) error {
-	// 存在確認
-	_, err := s.GetTodoByID(id)
-	if err != nil {
-		return err
+// maxTitleLength タイトルの最大文字数（DBの列の長さ）
+const maxTitleLength = 255
+
+// validateTitle タイトルを検証（空白のみは不可）
+func validateTitle(invalid *errcode.ValidationError, title string) {
+	if strings.TrimSpace(title) == "" {
+		invalid.Add("title", title, ErrTitleRequired)
+	} else if utf8.RuneCountInString(title) > maxTitleLength {
+		invalid.Add("title", title, errcode.Errorf(errcode.TitleTooLong, "タイトルは%d文字以内で指定してください", maxTitleLength))
 	}
+}
 
-	result := s.db.Delete(&model.Todo{}, id)
+// validateRecurrence 繰り返しの設定を検証（繰り返しには基準となる期限が必要）
+func validateRecurrence(invalid *errcode.ValidationError, rule, timezone string, dueDate *time.Time) {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			invalid.Add("recurrence_timezone", timezone, errcode.Errorf(errcode.InvalidTimezone, "無効なタイムゾーンです: %s", timezone))
+		}
+	}
+	if rule == "" {
+		return
+	}
+	if _, err := recurrence.Parse(rule); err != nil {
+		invalid.Add("recurrence_rule", rule, errcode.Errorf(errcode.InvalidRecurrenceRule, "無効な繰り返しルールです: %w", err))
+	}
+	if dueDate == nil {
+		invalid.Add("due_date", nil, ErrDueDateRequired)
+	}
+}
+
+// completedAt 完了状態に応じた完了日時（未完了の場合はnil）
+func completedAt(completed bool) *time.Time {
+	if !completed {
+		return nil
+	}
+	now := time.Now()
+	return &now
+}
+
+// タグの上限
+const (
+	maxTagLength = 50
+	maxTags      = 20
+)
+
+// NormalizeTags タグの前後の空白を除き、空のタグと重複を取り除く（順序は維持）
+func NormalizeTags(tags []string) (model.Tags, error) {
+	invalid := &errcode.ValidationError{}
+	normalized := normalizeTags(invalid, tags)
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// normalizeTags タグを正規化し、長すぎるタグ（tags[i]）・多すぎるタグ（tags）をinvalidに追加する
+func normalizeTags(invalid *errcode.ValidationError, tags []string) model.Tags {
+	if len(tags) == 0 {
+		return nil
+	}
+	normalized := make(model.Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			invalid.Add(fmt.Sprintf("tags[%d]", i), tag, errcode.Errorf(errcode.InvalidTag, "タグは%d文字以内で指定してください: %s", maxTagLength, tag))
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTags {
+		invalid.Add("tags", len(normalized), errcode.Errorf(errcode.TooManyTags, "タグは%d個までです", maxTags))
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return normalized
+}
+
+// DeleteTodo Todoを削除（ソフトデリート）
+func (s *todoService) DeleteTodo(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TodoService.DeleteTodo", tracing.SpanKindInternal)
+	defer span.End()
+
+	// 事前のSELECTによる存在確認は行わず、影響行数で未存在（削除済みを含む）を判定
+	result := s.db.WithContext(ctx).Delete(&model.Todo{}, id)
 	if result.Error != nil {
 		return fmt.Errorf("Todoの削除に失敗しました: %w", result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("ID %d のTodoは既に削除されています", id)
+		return errcode.Errorf(errcode.TodoNotFound, "ID %d のTodoが見つかりません", id)
 	}
 
+	events.PublishTodoDeleted(ctx, id)
+
 	return nil
 }
 
 // GetTodosByPriority 優先度でTodoをフィルタリング
-func (s *todoService) GetTodosByPriority(priority model.Priority) ([]*model.Todo, error) {
+func (s *todoService) GetTodosByPriority(ctx context.Context, priority model.Priority) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetTodosByPriority", tracing.SpanKindInternal)
+	defer span.End()
+
 	if !priority.IsValid() {
-		return nil, fmt.Errorf("無効な優先度です: %s", priority)
+		return nil, errcode.Errorf(errcode.InvalidPriority, "無効な優先度です: %s", priority)
 	}
 
 	var todos []*model.Todo
 
-	result := s.db.Where("priority = ?", priority).Order("created_at DESC").Find(&todos)
+	result := s.db.WithContext(ctx).Select(todoColumns).Where("priority = ?", priority).Order(todoOrder(s.profiles.DefaultSort(ctx))).Find(&todos)
 	if result.Error != nil {
 		return nil, fmt.Errorf("優先度 %s のTodo取得に失敗しました: %w", priority, result.Error)
 	}
@@ -160,10 +465,13 @@ func (s *todoService) GetTodosByPriority(priority model.Priority) ([]*model.Todo
 }
 
 // GetCompletedTodos 完了済みTodoを取得
-func (s *todoService) GetCompletedTodos() ([]*model.Todo, error) {
+func (s *todoService) GetCompletedTodos(ctx context.Context) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetCompletedTodos", tracing.SpanKindInternal)
+	defer span.End()
+
 	var todos []*model.Todo
 
-	result := s.db.Where("completed = ?", true).Order("updated_at DESC").Find(&todos)
+	result := s.db.WithContext(ctx).Select(todoColumns).Where("completed = ?", true).Order(todoOrder(s.profiles.DefaultSort(ctx))).Find(&todos)
 	if result.Error != nil {
 		return nil, fmt.Errorf("完了済みTodoの取得に失敗しました: %w", result.Error)
 	}
@@ -172,10 +480,13 @@ func (s *todoService) GetCompletedTodos() ([]*model.Todo, error) {
 }
 
 // GetPendingTodos 未完了Todoを取得
-func (s *todoService) GetPendingTodos() ([]*model.Todo, error) {
+func (s *todoService) GetPendingTodos(ctx context.Context) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetPendingTodos", tracing.SpanKindInternal)
+	defer span.End()
+
 	var todos []*model.Todo
 
-	result := s.db.Where("completed = ?", false).Order("priority DESC, created_at DESC").Find(&todos)
+	result := s.db.WithContext(ctx).Select(todoColumns).Where("completed = ?", false).Order(todoOrder(s.profiles.DefaultSort(ctx))).Find(&todos)
 	if result.Error != nil {
 		return nil, fmt.Errorf("未完了Todoの取得に失敗しました: %w", result.Error)
 	}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/tracing"
+
+	"gorm.io/gorm"
+)
+
+// ポーリング型トリガーの取得件数
+const (
+	// DefaultTriggerLimit 件数の指定がない場合に返す件数
+	DefaultTriggerLimit = 50
+	// MaxTriggerLimit 1回のポーリングで返す件数の上限
+	MaxTriggerLimit = 100
+)
+
+// TriggerService Zapier・IFTTTのポーリング型トリガー向けに新しい順のTodoを返すサービスのインターフェース
+type TriggerService interface {
+	// NewTodos 作成日時の新しい順にTodoを取得
+	NewTodos(ctx context.Context, limit int) ([]*model.Todo, error)
+	// CompletedTodos 完了日時の新しい順に完了済みのTodoを取得
+	CompletedTodos(ctx context.Context, limit int) ([]*model.Todo, error)
+}
+
+// triggerService トリガーサービスの実装
+type triggerService struct {
+	db *gorm.DB
+}
+
+// NewTriggerService 新しいトリガーサービスインスタンスを作成
+func NewTriggerService() TriggerService {
+	return &triggerService{
+		db: db.GetDB(),
+	}
+}
+
+// NewTodos 作成日時の新しい順にTodoを取得
+func (s *triggerService) NewTodos(ctx context.Context, limit int) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TriggerService.NewTodos", tracing.SpanKindInternal)
+	defer span.End()
+
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(todoColumns).
+		Order("created_at DESC, id DESC").
+		Limit(clampTriggerLimit(limit)).
+		Find(&todos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("Todoの取得に失敗しました: %w", result.Error)
+	}
+	return todos, nil
+}
+
+// CompletedTodos 完了日時の新しい順に完了済みのTodoを取得（完了状態で取り込んだTodoは含まない）
+func (s *triggerService) CompletedTodos(ctx context.Context, limit int) ([]*model.Todo, error) {
+	ctx, span := tracing.Start(ctx, "TriggerService.CompletedTodos", tracing.SpanKindInternal)
+	defer span.End()
+
+	var todos []*model.Todo
+	result := s.db.WithContext(ctx).
+		Select(append(append([]string{}, todoColumns...), "completed_at")).
+		Where("completed = ? AND completed_at IS NOT NULL", true).
+		Order("completed_at DESC, id DESC").
+		Limit(clampTriggerLimit(limit)).
+		Find(&todos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("完了済みTodoの取得に失敗しました: %w", result.Error)
+	}
+	return todos, nil
+}
+
+// clampTriggerLimit 件数を既定値・上限に丸める
+func clampTriggerLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultTriggerLimit
+	case limit > MaxTriggerLimit:
+		return MaxTriggerLimit
+	}
+	return limit
+}
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/errcode"
+	"myapp/events"
+	"myapp/queue"
+	"myapp/tracing"
+	"myapp/webhook"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryJobKind 送信先へのイベントの配信ジョブの種類
+const WebhookDeliveryJobKind = "webhook.delivery"
+
+// Webhookの配信のヘッダー
+const (
+	// webhookEventHeader イベントの種類（CloudEventsのtype）
+	webhookEventHeader = "X-Webhook-Event"
+	// webhookDeliveryHeader 配信のID（再試行・再配信でも同じ値。受信側で重複の排除に使う）
+	webhookDeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Outgoing Webhookの送信先・配信のエラー
+var (
+	ErrWebhookEndpointNotFound = errcode.New(errcode.WebhookEndpointNotFound, "Webhookの送信先が見つかりません")
+	ErrWebhookInvalidURL       = errcode.New(errcode.WebhookInvalidURL, "送信先のURLはhttpまたはhttpsの絶対URLで指定してください")
+	// ErrWebhookForbiddenURL 送信先が内部ネットワークのアドレス（SSRF対策）
+	ErrWebhookForbiddenURL     = errcode.New(errcode.WebhookForbiddenURL, "送信先にプライベート・ループバック・リンクローカルのアドレスは指定できません")
+	ErrWebhookUnknownEvent     = errcode.New(errcode.WebhookUnknownEvent, "配信できないイベントの種類です（todo.created / todo.updated / todo.deleted）")
+	ErrWebhookDeliveryNotFound = errcode.New(errcode.WebhookDeliveryNotFound, "Webhookの配信が見つかりません")
+	// ErrWebhookDeliveryPending 配信待ちの配信は再配信できない
+	ErrWebhookDeliveryPending = errcode.New(errcode.WebhookDeliveryPending, "配信待ちの配信は再配信できません")
+	// ErrWebhookEndpointDisabled 停止中の送信先へは再配信できない
+	ErrWebhookEndpointDisabled = errcode.New(errcode.WebhookEndpointDisabled, "送信先が停止しています。再開してから再配信してください")
+)
+
+// webhookEventTypes 送信先で配信できるイベントの種類
+var webhookEventTypes = []string{string(events.TodoCreated), string(events.TodoUpdated), string(events.TodoDeleted)}
+
+// webhookDeliveryJob 配信ジョブの内容
+type webhookDeliveryJob struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// WebhookEndpointService Outgoing Webhookの送信先を管理し、Todoのイベントをジョブキュー経由で配信するサービスのインターフェース
+type WebhookEndpointService interface {
+	ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id uint) (*model.WebhookEndpoint, error)
+	CreateEndpoint(ctx context.Context, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, id uint, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, endpointID uint, status model.WebhookDeliveryStatus, limit int) ([]*model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id uint) (*model.WebhookDelivery, error)
+	// Redeliver 失敗・取り消した（または配信済みの）配信を、同じ内容でもう一度配信する
+	Redeliver(ctx context.Context, id uint) (*model.WebhookDelivery, error)
+	// Dispatch イベントを配信する送信先ごとに配信を記録してジョブキューに積む（events.Listener）
+	Dispatch(ctx context.Context, event *events.CloudEvent)
+	// HandleJob 配信ジョブを実行する（queue.Handler）
+	HandleJob(ctx context.Context, payload json.RawMessage) error
+}
+
+// webhookEndpointService Webhookの送信先サービスの実装
+type webhookEndpointService struct {
+	db     *gorm.DB
+	client *http.Client
+	jobs   queue.Enqueuer
+	// disableAfter 送信先を自動で停止する連続失敗回数（0の場合は410 Gone以外では停止しない）
+	disableAfter int
+	// allowPrivateNetworks 内部ネットワークのアドレスへの送信先の登録を許可するか
+	allowPrivateNetworks bool
+}
+
+// NewWebhookEndpointService 新しいWebhookの送信先サービスインスタンスを作成
+// jobsはWebhookDeliveryJobKindにHandleJobを登録したジョブキューを指定する
+// allowPrivateNetworksがfalseの場合、clientには内部ネットワークへ接続しないクライアント（webhook.NewPublicClient）を指定する
+func NewWebhookEndpointService(client *http.Client, jobs queue.Enqueuer, disableAfter int, allowPrivateNetworks bool) WebhookEndpointService {
+	return &webhookEndpointService{
+		db:                   db.GetDB(),
+		client:               client,
+		jobs:                 jobs,
+		disableAfter:         disableAfter,
+		allowPrivateNetworks: allowPrivateNetworks,
+	}
+}
+
+// ListEndpoints 送信先を登録順に取得
+func (s *webhookEndpointService) ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.ListEndpoints", tracing.SpanKindInternal)
+	defer span.End()
+
+	var endpoints []*model.WebhookEndpoint
+	if err := s.db.WithContext(ctx).Order("id").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("Webhookの送信先の取得に失敗しました: %w", err)
+	}
+	return endpoints, nil
+}
+
+// GetEndpoint IDで送信先を取得
+func (s *webhookEndpointService) GetEndpoint(ctx context.Context, id uint) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.GetEndpoint", tracing.SpanKindInternal)
+	defer span.End()
+
+	var endpoint model.WebhookEndpoint
+	if err := s.db.WithContext(ctx).First(&endpoint, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookEndpointNotFound
+		}
+		return nil, fmt.Errorf("Webhookの送信先の取得に失敗しました: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// CreateEndpoint 送信先を登録
+func (s *webhookEndpointService) CreateEndpoint(ctx context.Context, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.CreateEndpoint", tracing.SpanKindInternal)
+	defer span.End()
+
+	endpoint := &model.WebhookEndpoint{}
+	if err := applyWebhookEndpoint(endpoint, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, endpoint.URL); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("Webhookの送信先の登録に失敗しました: %w", err)
+	}
+	return endpoint, nil
+}
+
+// UpdateEndpoint 送信先を置き換える（停止中の送信先を有効にすると、連続失敗回数と停止の理由を戻して配信を再開する）
+func (s *webhookEndpointService) UpdateEndpoint(ctx context.Context, id uint, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.UpdateEndpoint", tracing.SpanKindInternal)
+	defer span.End()
+
+	endpoint, err := s.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWebhookEndpoint(endpoint, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, endpoint.URL); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("Webhookの送信先の更新に失敗しました: %w", err)
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint 送信先と配信の履歴を削除（配信待ちのジョブは実行時に何もせず終了する）
+func (s *webhookEndpointService) DeleteEndpoint(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.DeleteEndpoint", tracing.SpanKindInternal)
+	defer span.End()
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.WebhookEndpoint{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("Webhookの送信先の削除に失敗しました: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookEndpointNotFound
+		}
+		if err := tx.Where("endpoint_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("Webhookの配信の履歴の削除に失敗しました: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkURL 送信先のホストが内部ネットワークのアドレスでないか確認する（名前解決の結果が変わっても送信時に再度検査する）
+func (s *webhookEndpointService) checkURL(ctx context.Context, rawURL string) error {
+	if s.allowPrivateNetworks {
+		return nil
+	}
+	if err := webhook.CheckURL(ctx, rawURL); err != nil {
+		invalid := &errcode.ValidationError{}
+		invalid.Add("url", rawURL, ErrWebhookForbiddenURL)
+		return invalid.Err()
+	}
+	return nil
+}
+
+// applyWebhookEndpoint リクエストの内容を検証して送信先に反映する（無効なフィールドはまとめて返す）
+func applyWebhookEndpoint(endpoint *model.WebhookEndpoint, req *model.WebhookEndpointRequest) error {
+	invalid := &errcode.ValidationError{}
+	rawURL := strings.TrimSpace(req.URL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid.Add("url", req.URL, ErrWebhookInvalidURL)
+	}
+	eventTypes := make([]string, 0, len(req.Events))
+	for i, typ := range req.Events {
+		typ = strings.TrimSpace(typ)
+		if !slices.Contains(webhookEventTypes, typ) {
+			invalid.Add(fmt.Sprintf("events[%d]", i), typ, ErrWebhookUnknownEvent)
+			continue
+		}
+		if !slices.Contains(eventTypes, typ) {
+			eventTypes = append(eventTypes, typ)
+		}
+	}
+	if err := invalid.Err(); err != nil {
+		return err
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	if enabled && !endpoint.Enabled {
+		endpoint.ConsecutiveFailures = 0
+		endpoint.DisabledReason = ""
+		endpoint.DisabledAt = nil
+	}
+	endpoint.URL = rawURL
+	endpoint.Description = strings.TrimSpace(req.Description)
+	endpoint.Events = eventTypes
+	endpoint.Enabled = enabled
+	return nil
+}
+
+// ListDeliveries 配信の履歴を新しい順に取得（endpointIDが0・statusが空の場合は絞り込まない）
+func (s *webhookEndpointService) ListDeliveries(ctx context.Context, endpointID uint, status model.WebhookDeliveryStatus, limit int) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.ListDeliveries", tracing.SpanKindInternal)
+	defer span.End()
+
+	query := s.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if endpointID != 0 {
+		query = query.Where("endpoint_id = ?", endpointID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []*model.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("Webhookの配信の履歴の取得に失敗しました: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery IDで配信を取得
+func (s *webhookEndpointService) GetDelivery(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.GetDelivery", tracing.SpanKindInternal)
+	defer span.End()
+
+	var delivery model.WebhookDelivery
+	if err := s.db.WithContext(ctx).First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("Webhookの配信の取得に失敗しました: %w", err)
+	}
+	return &delivery, nil
+}
+
+// Redeliver 配信を配信待ちに戻し、新しいジョブとして積む（試行回数は通算で数える）
+func (s *webhookEndpointService) Redeliver(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.Redeliver", tracing.SpanKindInternal)
+	defer span.End()
+
+	delivery, err := s.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == model.WebhookDeliveryPending {
+		return nil, ErrWebhookDeliveryPending
+	}
+	endpoint, err := s.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Enabled {
+		return nil, ErrWebhookEndpointDisabled
+	}
+
+	result := s.db.WithContext(ctx).Model(delivery).
+		Where("status <> ?", model.WebhookDeliveryPending).
+		Updates(map[string]any{"status": model.WebhookDeliveryPending, "last_error": ""})
+	if result.Error != nil {
+		return nil, fmt.Errorf("Webhookの配信の更新に失敗しました: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWebhookDeliveryPending
+	}
+	if err := s.enqueue(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Dispatch イベントの種類を配信する有効な送信先ごとに配信を記録し、配信ジョブを積む
+// 発行元のリクエストの中で呼び出されるため送信はせず、失敗してもログに記録するだけでリクエストは失敗させない
+func (s *webhookEndpointService) Dispatch(ctx context.Context, event *events.CloudEvent) {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.Dispatch", tracing.SpanKindInternal)
+	defer span.End()
+
+	var endpoints []*model.WebhookEndpoint
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&endpoints).Error; err != nil {
+		slog.ErrorContext(ctx, "Webhookの送信先の取得に失敗しました", "type", event.Type, "subject", event.Subject, "error", err)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "イベントのエンコードに失敗しました", "type", event.Type, "subject", event.Subject, "error", err)
+		return
+	}
+	for _, endpoint := range endpoints {
+		if len(endpoint.Events) > 0 && !slices.Contains(endpoint.Events, string(event.Type)) {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			EndpointID: endpoint.ID,
+			EventID:    event.ID,
+			EventType:  string(event.Type),
+			Payload:    string(body),
+			Status:     model.WebhookDeliveryPending,
+		}
+		if err := s.db.WithContext(ctx).Create(delivery).Error; err != nil {
+			slog.ErrorContext(ctx, "Webhookの配信の記録に失敗しました", "endpoint_id", endpoint.ID, "event_id", event.ID, "error", err)
+			continue
+		}
+		if err := s.enqueue(ctx, delivery); err != nil {
+			slog.ErrorContext(ctx, "Webhookの配信ジョブの登録に失敗しました", "endpoint_id", endpoint.ID, "delivery_id", delivery.ID, "error", err)
+		}
+	}
+}
+
+// enqueue 配信ジョブを積み、ジョブのIDを配信に記録する
+func (s *webhookEndpointService) enqueue(ctx context.Context, delivery *model.WebhookDelivery) error {
+	job, err := s.jobs.Enqueue(ctx, WebhookDeliveryJobKind, webhookDeliveryJob{DeliveryID: delivery.ID})
+	if err != nil {
+		return fmt.Errorf("Webhookの配信ジョブの登録に失敗しました: %w", err)
+	}
+	delivery.JobID = job.ID
+	if err := s.db.WithContext(ctx).Model(delivery).UpdateColumn("job_id", job.ID).Error; err != nil {
+		return fmt.Errorf("Webhookの配信の更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// HandleJob 配信を送信先へ送り、試行ごとに結果を記録する
+// 最後の試行でも失敗した場合（4xxはタイムアウト・レート制限を除き最初の失敗で）は配信を失敗にし、送信先の連続失敗回数を数える
+func (s *webhookEndpointService) HandleJob(ctx context.Context, payload json.RawMessage) error {
+	ctx, span := tracing.Start(ctx, "WebhookEndpointService.HandleJob", tracing.SpanKindInternal)
+	defer span.End()
+
+	var job webhookDeliveryJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	delivery, err := s.GetDelivery(ctx, job.DeliveryID)
+	if errors.Is(err, ErrWebhookDeliveryNotFound) {
+		// 送信先ごと削除された配信
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	endpoint, err := s.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil && !errors.Is(err, ErrWebhookEndpointNotFound) {
+		return err
+	}
+	if endpoint == nil || !endpoint.Enabled {
+		return s.finish(ctx, delivery, map[string]any{
+			"status":     model.WebhookDeliveryCanceled,
+			"last_error": "送信先が停止・削除されたため配信しませんでした",
+		})
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", events.ContentType)
+	header.Set(webhookEventHeader, delivery.EventType)
+	header.Set(webhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	code, sendErr := webhook.Send(ctx, s.client, endpoint.URL, []byte(delivery.Payload), header)
+
+	updates := map[string]any{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": code,
+	}
+	if sendErr == nil {
+		now := time.Now()
+		updates["status"] = model.WebhookDeliverySucceeded
+		updates["last_error"] = ""
+		updates["delivered_at"] = &now
+		// 送信済みのため、記録に失敗しても再試行しない
+		if err := s.finish(ctx, delivery, updates); err != nil {
+			slog.WarnContext(ctx, "Webhookの配信の結果の記録に失敗しました", "delivery_id", delivery.ID, "error", err)
+		}
+		if endpoint.ConsecutiveFailures > 0 {
+			if err := s.db.WithContext(ctx).Model(endpoint).UpdateColumn("consecutive_failures", 0).Error; err != nil {
+				slog.WarnContext(ctx, "Webhookの送信先の連続失敗回数の更新に失敗しました", "endpoint_id", endpoint.ID, "error", err)
+			}
+		}
+		return nil
+	}
+
+	retryable := webhook.Retryable(sendErr)
+	attempt, maxAttempts, _ := queue.Attempt(ctx)
+	final := !retryable || attempt >= maxAttempts
+	updates["last_error"] = sendErr.Error()
+	updates["status"] = model.WebhookDeliveryPending
+	if final {
+		updates["status"] = model.WebhookDeliveryFailed
+	}
+	if err := s.finish(ctx, delivery, updates); err != nil {
+		slog.WarnContext(ctx, "Webhookの配信の結果の記録に失敗しました", "delivery_id", delivery.ID, "error", err)
+	}
+	if final {
+		s.recordFailure(ctx, endpoint, code)
+	}
+
+	if !retryable {
+		return queue.Permanent(sendErr)
+	}
+	return sendErr
+}
+
+// finish 配信の試行の結果を記録する
+func (s *webhookEndpointService) finish(ctx context.Context, delivery *model.WebhookDelivery, updates map[string]any) error {
+	if err := s.db.WithContext(ctx).Model(delivery).Updates(updates).Error; err != nil {
+		return fmt.Errorf("Webhookの配信の更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// recordFailure 送信先の連続失敗回数を数え、410 Goneが返された場合か連続失敗回数が上限に達した場合は送信先を停止する
+func (s *webhookEndpointService) recordFailure(ctx context.Context, endpoint *model.WebhookEndpoint, code int) {
+	var failures []int
+	err := s.db.WithContext(ctx).Raw(
+		"UPDATE webhook_endpoints SET consecutive_failures = consecutive_failures + 1, updated_at = ? WHERE id = ? RETURNING consecutive_failures",
+		time.Now(), endpoint.ID,
+	).Scan(&failures).Error
+	if err != nil || len(failures) == 0 {
+		slog.WarnContext(ctx, "Webhookの送信先の連続失敗回数の更新に失敗しました", "endpoint_id", endpoint.ID, "error", err)
+		return
+	}
+
+	var reason string
+	switch {
+	case code == http.StatusGone:
+		reason = "送信先が410 Goneを返しました"
+	case s.disableAfter > 0 && failures[0] >= s.disableAfter:
+		reason = fmt.Sprintf("配信に%d回連続で失敗しました", failures[0])
+	default:
+		return
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND enabled = ?", endpoint.ID, true).
+		Updates(map[string]any{"enabled": false, "disabled_reason": reason, "disabled_at": &now})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "Webhookの送信先の停止に失敗しました", "endpoint_id", endpoint.ID, "error", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		slog.WarnContext(ctx, "Webhookの送信先を停止しました", "endpoint_id", endpoint.ID, "url", endpoint.URL, "reason", reason)
+	}
+}
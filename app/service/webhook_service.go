@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"myapp/db"
+	"myapp/db/model"
+	"myapp/jobs"
+	"myapp/tracing"
+	"myapp/webhook"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookService Outgoing Webhookの署名シークレットを管理するサービスのインターフェース
+type WebhookService interface {
+	LoadSecrets(ctx context.Context) error
+	RefreshSecrets(ctx context.Context, interval time.Duration)
+	RotateSecret(ctx context.Context, gracePeriod time.Duration) (*model.WebhookSecretRotation, error)
+}
+
+// webhookService Webhookサービスの実装
+type webhookService struct {
+	db            *gorm.DB
+	initialSecret string
+}
+
+// NewWebhookService 新しいWebhookサービスインスタンスを作成
+// initialSecretはDBにシークレットが無い場合に使う設定ファイル・環境変数のシークレット
+func NewWebhookService(initialSecret string) WebhookService {
+	return &webhookService{
+		db:            db.GetDB(),
+		initialSecret: initialSecret,
+	}
+}
+
+// LoadSecrets DBに保存された有効なシークレットを署名に反映
+func (s *webhookService) LoadSecrets(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.LoadSecrets", tracing.SpanKindInternal)
+	defer span.End()
+
+	var records []*model.WebhookSecret
+
+	result := s.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("expires_at IS NOT NULL, created_at DESC").
+		Find(&records)
+	if result.Error != nil {
+		return fmt.Errorf("Webhookシークレットの読み込みに失敗しました: %w", result.Error)
+	}
+
+	secrets := make([]webhook.Secret, 0, len(records)+1)
+	if len(records) == 0 || records[0].ExpiresAt != nil {
+		// ローテーション前は設定のシークレットを現在のシークレットとして使う
+		if s.initialSecret != "" {
+			secrets = append(secrets, webhook.Secret{Value: s.initialSecret})
+		}
+	}
+	for _, record := range records {
+		secrets = append(secrets, webhook.Secret{Value: record.Secret, ExpiresAt: record.ExpiresAt})
+	}
+
+	webhook.SetSecrets(secrets)
+	return nil
+}
+
+// RefreshSecrets intervalごとにDBのシークレットを読み込み直す（ctxがキャンセルされるまでブロック）
+// ローテーションはAPIを受けたインスタンスでのみ即時に反映されるため、他のインスタンスは次の読み込みで新しいシークレットに切り替わる
+func (s *webhookService) RefreshSecrets(ctx context.Context, interval time.Duration) {
+	job := jobs.Register("webhook-secrets-refresh", "Webhookの署名シークレットの再読み込み（他のインスタンスでのローテーションへの追従）", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			job.Run(ctx, s.LoadSecrets)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RotateSecret 新しいシークレットを発行し、現在のシークレットは猶予期間の経過後に失効させる
+// 猶予期間中は新旧両方のシークレットで署名するため、受信側は順次切り替えられる
+func (s *webhookService) RotateSecret(ctx context.Context, gracePeriod time.Duration) (*model.WebhookSecretRotation, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.RotateSecret", tracing.SpanKindInternal)
+	defer span.End()
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("Webhookシークレットの生成に失敗しました: %w", err)
+	}
+
+	expiresAt := time.Now().Add(gracePeriod)
+	var previousExpiresAt *time.Time
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.WebhookSecret{}).
+			Where("expires_at IS NULL").
+			Update("expires_at", expiresAt)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		switch {
+		case result.RowsAffected > 0:
+			previousExpiresAt = &expiresAt
+		case s.initialSecret != "":
+			// 設定のシークレットからの初回ローテーションは、猶予期間中も署名できるようDBに残す
+			if err := tx.Create(&model.WebhookSecret{Secret: s.initialSecret, ExpiresAt: &expiresAt}).Error; err != nil {
+				return err
+			}
+			previousExpiresAt = &expiresAt
+		}
+
+		return tx.Create(&model.WebhookSecret{Secret: secret}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Webhookシークレットのローテーションに失敗しました: %w", err)
+	}
+
+	if err := s.LoadSecrets(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.WebhookSecretRotation{
+		Secret:             secret,
+		PreviousExpiresAt:  previousExpiresAt,
+		GracePeriodSeconds: int(gracePeriod.Seconds()),
+	}, nil
+}
+
+// generateSecret ランダムな署名シークレットを生成
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CookieStore セッションの内容をAES-256-GCMで暗号化・改ざん検知してCookie自体に保存するストア
+// サーバー側に状態を持たないため、破棄したセッションのCookieは有効期限まで再利用できる点に注意
+type CookieStore struct {
+	aead cipher.AEAD
+}
+
+// NewCookieStore 新しいCookieストアを作成（secretから暗号鍵を導出する）
+func NewCookieStore(secret string) (*CookieStore, error) {
+	if secret == "" {
+		return nil, errors.New("Cookieセッションには SESSION_SECRET が必要です")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieStore{aead: aead}, nil
+}
+
+// Load Cookieの値を復号してセッションを復元
+func (s *CookieStore) Load(ctx context.Context, cookieValue string) (*Session, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cookieValue)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, nil
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		// 改ざん・鍵の変更は無効なセッションとして扱う
+		return nil, nil
+	}
+
+	var session Session
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return nil, nil
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// Save セッションを暗号化してCookieの値を返す
+func (s *CookieStore) Save(ctx context.Context, session *Session) (string, error) {
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Delete Cookieストアではサーバー側に削除するものはない（Cookieの失効のみ）
+func (s *CookieStore) Delete(ctx context.Context, session *Session) error {
+	return nil
+}
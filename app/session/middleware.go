@@ -0,0 +1,133 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Middleware リクエストごとにセッションを読み込み、変更があればレスポンスヘッダーの書き込み前に保存するミドルウェア
+// ハンドラーは FromContext でセッションを取得する（無効な場合はnil）
+func Middleware(store Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := config.Current().Session
+			if !cfg.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var s *Session
+			if cookie, err := r.Cookie(cfg.CookieName); err == nil && cookie.Value != "" {
+				loaded, err := store.Load(r.Context(), cookie.Value)
+				if err != nil {
+					slog.ErrorContext(r.Context(), "セッションの読み込みに失敗しました", "error", err)
+					w.Header().Set("Content-Type", "application/problem+json")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					json.NewEncoder(w).Encode(huma.Error503ServiceUnavailable(i18n.T(r.Context(), "セッションを読み込めません")))
+					return
+				}
+				s = loaded
+			}
+			if s == nil {
+				created, err := newSession(cfg.MaxAge)
+				if err != nil {
+					w.Header().Set("Content-Type", "application/problem+json")
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(huma.Error500InternalServerError(i18n.T(r.Context(), "セッションを作成できません")))
+					return
+				}
+				s = created
+			}
+
+			sw := &sessionWriter{ResponseWriter: w, request: r, store: store, session: s, cfg: cfg}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+			sw.commit()
+		})
+	}
+}
+
+// sessionWriter ヘッダーの書き込み直前にセッションを保存してCookieを設定するResponseWriter
+type sessionWriter struct {
+	http.ResponseWriter
+	request   *http.Request
+	store     Store
+	session   *Session
+	cfg       config.SessionConfig
+	committed bool
+}
+
+func (sw *sessionWriter) WriteHeader(status int) {
+	sw.commit()
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *sessionWriter) Write(b []byte) (int, error) {
+	sw.commit()
+	return sw.ResponseWriter.Write(b)
+}
+
+// Unwrap http.ResponseController から元のResponseWriterを参照できるようにする
+func (sw *sessionWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// commit 破棄・変更されたセッションを反映する（1リクエストにつき1回）
+func (sw *sessionWriter) commit() {
+	if sw.committed {
+		return
+	}
+	sw.committed = true
+
+	ctx := sw.request.Context()
+	s := sw.session
+
+	switch {
+	case s.destroyed:
+		if !s.isNew {
+			if err := sw.store.Delete(ctx, s); err != nil {
+				slog.ErrorContext(ctx, "セッションの破棄に失敗しました", "error", err)
+			}
+		}
+		http.SetCookie(sw.ResponseWriter, sw.cookie("", -1))
+	case s.modified:
+		value, err := sw.store.Save(ctx, s)
+		if err != nil {
+			slog.ErrorContext(ctx, "セッションの保存に失敗しました", "error", err)
+			return
+		}
+		http.SetCookie(sw.ResponseWriter, sw.cookie(value, int(sw.cfg.MaxAge.Seconds())))
+	}
+}
+
+// cookie セッションCookieを作成（maxAgeが負の場合は削除用）
+func (sw *sessionWriter) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     sw.cfg.CookieName,
+		Value:    value,
+		Path:     "/",
+		Domain:   sw.cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   sw.cfg.CookieSecure,
+		HttpOnly: true,
+		SameSite: sameSite(sw.cfg.SameSite),
+	}
+}
+
+// sameSite 設定値をhttp.SameSiteに変換（不正な値の場合はLax）
+func sameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
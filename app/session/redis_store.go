@@ -0,0 +1,78 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore セッションの内容をRedisに保存し、CookieにはセッションIDのみを保存するストア
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 新しいRedisストアを作成
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+		}),
+		prefix: "session:",
+	}
+}
+
+// Load セッションIDに対応するセッションをRedisから取得
+func (s *RedisStore) Load(ctx context.Context, cookieValue string) (*Session, error) {
+	data, err := s.client.Get(ctx, s.prefix+cookieValue).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// Save セッションを有効期限付きで保存し、IDを再生成した場合は旧IDのセッションを削除する
+func (s *RedisStore) Save(ctx context.Context, session *Session) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.prefix+session.ID, data, time.Until(session.ExpiresAt))
+	if session.previousID != "" {
+		pipe.Del(ctx, s.prefix+session.previousID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return session.ID, nil
+}
+
+// Delete セッションをRedisから削除
+func (s *RedisStore) Delete(ctx context.Context, session *Session) error {
+	keys := []string{s.prefix + session.ID}
+	if session.previousID != "" {
+		keys = append(keys, s.prefix+session.previousID)
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// Close Redisとの接続を閉じる
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+)
+
+// Session Cookieで識別するサーバーセッション
+type Session struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"values"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+
+	previousID string
+	modified   bool
+	destroyed  bool
+	isNew      bool
+}
+
+// Store セッションの保存先
+type Store interface {
+	// Load Cookieの値からセッションを復元（無効・期限切れの場合は nil, nil）
+	Load(ctx context.Context, cookieValue string) (*Session, error)
+	// Save セッションを保存し、Cookieに設定する値を返す
+	Save(ctx context.Context, s *Session) (string, error)
+	// Delete セッションを破棄する
+	Delete(ctx context.Context, s *Session) error
+}
+
+type contextKey struct{}
+
+// FromContext リクエストに紐づくセッションを取得（セッションが無効な場合はnil）
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
+
+// newSession 新しい空のセッションを作成
+func newSession(maxAge time.Duration) (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Session{
+		ID:        id,
+		Values:    map[string]any{},
+		CreatedAt: now,
+		ExpiresAt: now.Add(maxAge),
+		isNew:     true,
+	}, nil
+}
+
+// Get 値を取得
+func (s *Session) Get(key string) (any, bool) {
+	value, ok := s.Values[key]
+	return value, ok
+}
+
+// Set 値を設定（レスポンス時に保存される）
+func (s *Session) Set(key string, value any) {
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete 値を削除
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// Regenerate セッションIDを再生成する（値は引き継ぐ）
+// セッション固定攻撃を防ぐため、ログイン・権限変更時に必ず呼び出すこと
+func (s *Session) Regenerate() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.ID
+	}
+	s.ID = id
+	s.modified = true
+	return nil
+}
+
+// Destroy セッションを破棄する（ログアウト時）
+func (s *Session) Destroy() {
+	s.Values = map[string]any{}
+	s.destroyed = true
+}
+
+// newID 推測困難なセッションIDを生成
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,164 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase 停止処理の段階（小さい順に実行）
+type Phase int
+
+const (
+	// PhaseServers 新しいリクエストの受付を止める（HTTPサーバー等）
+	PhaseServers Phase = iota
+	// PhaseWorkers バックグラウンドワーカーを停止し、実行中のジョブ完了を待つ
+	PhaseWorkers
+	// PhaseFlush 未送信のテレメトリ等を送信する
+	PhaseFlush
+	// PhaseResources DB接続等のリソースを解放する
+	PhaseResources
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseServers:
+		return "servers"
+	case PhaseWorkers:
+		return "workers"
+	case PhaseFlush:
+		return "flush"
+	case PhaseResources:
+		return "resources"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// hook 登録された停止処理
+type hook struct {
+	phase Phase
+	name  string
+	fn    func(ctx context.Context) error
+}
+
+// Manager HTTPサーバー・ワーカー・各種クライアントの停止を段階的に行うシャットダウンマネージャー
+type Manager struct {
+	mu    sync.Mutex
+	hooks []hook
+
+	// ワーカーに渡すコンテキスト（PhaseWorkersでキャンセルされる）
+	workerCtx    context.Context
+	cancelWorker context.CancelFunc
+	workers      sync.WaitGroup
+	running      map[string]int
+}
+
+// NewManager 新しいシャットダウンマネージャーを作成
+func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		workerCtx:    ctx,
+		cancelWorker: cancel,
+		running:      make(map[string]int),
+	}
+	m.Register(PhaseWorkers, "workers", m.stopWorkers)
+	return m
+}
+
+// Register 停止処理を登録（同じ段階の処理は登録順に実行）
+func (m *Manager) Register(phase Phase, name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{phase: phase, name: name, fn: fn})
+}
+
+// Go バックグラウンドワーカーを起動
+// fnに渡すコンテキストはシャットダウン時にキャンセルされる。fnは実行中のジョブを終えてから戻ること
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.mu.Lock()
+	m.running[name]++
+	m.mu.Unlock()
+
+	m.workers.Add(1)
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			if m.running[name]--; m.running[name] <= 0 {
+				delete(m.running, name)
+			}
+			m.mu.Unlock()
+			m.workers.Done()
+		}()
+		fn(m.workerCtx)
+	}()
+}
+
+// Shutdown 登録された停止処理を段階順に実行
+// ctxの期限を過ぎた場合も残りの処理は実行し、発生したエラーをまとめて返す
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].phase < hooks[j].phase
+	})
+
+	var errs []string
+	for _, h := range hooks {
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			slog.Error("停止処理に失敗しました", "phase", h.phase.String(), "name", h.name, "error", err)
+			errs = append(errs, fmt.Sprintf("%s: %v", h.name, err))
+			continue
+		}
+		slog.Debug("停止処理が完了しました", "phase", h.phase.String(), "name", h.name, "duration", time.Since(start))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("シャットダウン中に %d 件のエラーが発生しました: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// stopWorkers ワーカーのコンテキストをキャンセルし、全ワーカーの終了を待つ
+func (m *Manager) stopWorkers(ctx context.Context) error {
+	m.cancelWorker()
+
+	finished := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		names := make([]string, 0, len(m.running))
+		for name := range m.running {
+			names = append(names, name)
+		}
+		m.mu.Unlock()
+		sort.Strings(names)
+		return fmt.Errorf("ワーカーの停止がタイムアウトしました（実行中: %s）: %w", strings.Join(names, ", "), ctx.Err())
+	}
+}
+
+// Workers 実行中のワーカー名と起動数を取得
+func (m *Manager) Workers() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	workers := make(map[string]int, len(m.running))
+	for name, count := range m.running {
+		workers[name] = count
+	}
+	return workers
+}
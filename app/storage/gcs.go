@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// GCSの定数
+const (
+	// gcsScope オブジェクトの読み書きに必要なスコープ
+	gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+	// gcsEndpoint JSON APIのURL
+	gcsEndpoint = "https://storage.googleapis.com"
+)
+
+// GCSOptions GCSドライバーの設定
+type GCSOptions struct {
+	Bucket string
+	// CredentialsJSON サービスアカウントのキー（JSON）。空の場合はアプリケーションのデフォルト認証情報（GOOGLE_APPLICATION_CREDENTIALS・メタデータサーバー等）を使う
+	CredentialsJSON []byte
+	// Endpoint JSON APIのURL（空の場合は https://storage.googleapis.com。エミュレーター等に使う）
+	Endpoint string
+}
+
+// GCS Google Cloud Storage に保存するストレージ
+// アクセストークンの取得・更新は golang.org/x/oauth2/google に任せ、JSON APIを直接呼ぶ
+type GCS struct {
+	opts   GCSOptions
+	client *http.Client
+}
+
+// NewGCS 新しいGCSストレージを作成
+func NewGCS(opts GCSOptions) (*GCS, error) {
+	if opts.Endpoint == "" {
+		opts.Endpoint = gcsEndpoint
+	}
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+
+	ctx := context.Background()
+	var (
+		creds *google.Credentials
+		err   error
+	)
+	if len(opts.CredentialsJSON) > 0 {
+		creds, err = google.CredentialsFromJSON(ctx, opts.CredentialsJSON, gcsScope)
+		if err != nil {
+			return nil, fmt.Errorf("サービスアカウントのキーを読み込めません: %w", err)
+		}
+	} else {
+		creds, err = google.FindDefaultCredentials(ctx, gcsScope)
+		if err != nil {
+			return nil, fmt.Errorf("GCSの認証情報が見つかりません: %w", err)
+		}
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Minute,
+		Transport: &oauth2.Transport{Source: creds.TokenSource},
+	}
+	return &GCS{opts: opts, client: client}, nil
+}
+
+// Driver ドライバー名
+func (g *GCS) Driver() string {
+	return DriverGCS
+}
+
+// Put メディアアップロードで保存
+func (g *GCS) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	body, size, cleanup, err := sizedBody(r, size)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.bucketURL("/upload")+"/o?"+query.Encode(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get オブジェクトの内容を読み込む
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.objectURL(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, objectInfo(key, resp.Header), nil
+}
+
+// Stat オブジェクトのメタデータを取得
+func (g *GCS) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var object gcsObject
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, fmt.Errorf("GCSのメタデータを解析できません: %w", err)
+	}
+	info := object.info()
+	return &info, nil
+}
+
+// Delete オブジェクトを削除
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, g.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List ページを辿ってオブジェクトを取得
+func (g *GCS) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	pageToken := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "fields": {"items(name,size,contentType,updated),nextPageToken"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.bucketURL("")+"/o?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := g.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("GCSの一覧を解析できません: %w", err)
+		}
+		for _, item := range result.Items {
+			objects = append(objects, item.info())
+		}
+		if result.NextPageToken == "" {
+			return objects, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// gcsObject JSON APIのオブジェクトのメタデータ
+type gcsObject struct {
+	Name        string    `json:"name"`
+	Size        string    `json:"size"`
+	ContentType string    `json:"contentType"`
+	Updated     time.Time `json:"updated"`
+}
+
+// info ObjectInfoに変換（サイズは文字列で返される）
+func (o gcsObject) info() ObjectInfo {
+	size, _ := strconv.ParseInt(o.Size, 10, 64)
+	return ObjectInfo{Key: o.Name, Size: size, ContentType: o.ContentType, ModTime: o.Updated}
+}
+
+// bucketURL バケットのURL（prefixはアップロード用の "/upload" 等）
+func (g *GCS) bucketURL(prefix string) string {
+	return g.opts.Endpoint + prefix + "/storage/v1/b/" + url.PathEscape(g.opts.Bucket)
+}
+
+// objectURL オブジェクトのURL（オブジェクト名の "/" もエンコードする）
+func (g *GCS) objectURL(key string) string {
+	return g.bucketURL("") + "/o/" + url.PathEscape(key)
+}
+
+// do 送信し（アクセストークンはクライアントのTransportが付ける）、エラーのステータスをエラーに変換する
+func (g *GCS) do(req *http.Request) (*http.Response, error) {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("GCSがステータス %d を返しました: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("GCSがステータス %d を返しました", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// objectInfo レスポンスヘッダーからオブジェクトの情報を作成
+func objectInfo(key string, header http.Header) *ObjectInfo {
+	info := &ObjectInfo{Key: key, ContentType: header.Get("Content-Type")}
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if modTime, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info
+}
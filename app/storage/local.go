@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Local ローカルディスクのディレクトリに保存するストレージ
+type Local struct {
+	dir string
+}
+
+// NewLocal 新しいローカルストレージを作成（ディレクトリは最初の保存時に作成する）
+func NewLocal(dir string) (*Local, error) {
+	if dir == "" {
+		return nil, errors.New("ローカルストレージのディレクトリが指定されていません")
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Driver ドライバー名
+func (l *Local) Driver() string {
+	return DriverLocal
+}
+
+// Put 一時ファイルに書き込んでから置き換える（書き込み途中の内容を読まれないようにする）
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	name := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Get ファイルを開く
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		return nil, nil, l.wrap(err)
+	}
+	return f, info, nil
+}
+
+// Stat ファイルの情報を取得（Content-Typeは拡張子から推定する）
+func (l *Local) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(l.path(key))
+	if err != nil {
+		return nil, l.wrap(err)
+	}
+	if fi.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{
+		Key:         key,
+		Size:        fi.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     fi.ModTime(),
+	}, nil
+}
+
+// Delete ファイルを削除
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(l.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List ディレクトリを辿ってキーがprefixで始まるファイルを取得（prefixのディレクトリ部分より下のみ辿る）
+func (l *Local) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	root := l.dir
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		if err := ValidateKey(prefix[:i]); err != nil {
+			return nil, err
+		}
+		root = l.path(prefix[:i])
+	}
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{
+			Key:         key,
+			Size:        fi.Size(),
+			ContentType: mime.TypeByExtension(path.Ext(key)),
+			ModTime:     fi.ModTime(),
+		})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return objects, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// path キーに対応するファイルのパス
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(key))
+}
+
+// wrap ファイルが存在しない場合はErrNotFoundにする
+func (l *Local) wrap(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// contextReader ctxがキャンセルされたら読み込みを中断するReader
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read ctxがキャンセルされていなければ読み込む
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
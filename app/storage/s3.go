@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Options S3ドライバーの設定
+type S3Options struct {
+	Bucket string
+	Region string
+	// Endpoint S3互換サービス（MinIO・Cloudflare R2等）のURL（空の場合は https://s3.<region>.amazonaws.com）
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle バケット名をホスト名ではなくパスに含める（多くのS3互換サービスで必要）
+	PathStyle bool
+}
+
+// S3 Amazon S3（互換サービスを含む）に保存するストレージ
+// 署名（SigV4）・リトライ・エラーの解析は aws-sdk-go-v2 に任せる
+type S3 struct {
+	bucket string
+	client *s3.Client
+}
+
+// NewS3 新しいS3ストレージを作成
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Endpoint != "" {
+		endpoint, err := url.Parse(strings.TrimSuffix(opts.Endpoint, "/"))
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("S3のエンドポイントが不正です: %q", opts.Endpoint)
+		}
+	}
+
+	client := s3.NewFromConfig(aws.Config{
+		Region:      opts.Region,
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(opts.AccessKeyID, opts.SecretAccessKey, "")),
+		HTTPClient:  &http.Client{Timeout: 10 * time.Minute},
+	}, func(o *s3.Options) {
+		if opts.Endpoint != "" {
+			o.BaseEndpoint = aws.String(strings.TrimSuffix(opts.Endpoint, "/"))
+		}
+		o.UsePathStyle = opts.PathStyle
+	})
+	return &S3{bucket: opts.Bucket, client: client}, nil
+}
+
+// Driver ドライバー名
+func (s *S3) Driver() string {
+	return DriverS3
+}
+
+// Put PutObjectで保存（本文は署名せず、TLSで保護する。読み直せない本文でもハッシュの計算に巻き戻さずに送れる）
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	body, size, cleanup, err := sizedBody(r, size)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	_, err = s.client.PutObject(ctx, input, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+	return s3Error(err)
+}
+
+// Get GetObjectで読み込む
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, nil, s3Error(err)
+	}
+	return out.Body, s3ObjectInfo(key, out.ContentLength, out.ContentType, out.LastModified), nil
+}
+
+// Stat HeadObjectで情報を取得
+func (s *S3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return s3ObjectInfo(key, out.ContentLength, out.ContentType, out.LastModified), nil
+}
+
+// Delete DeleteObjectで削除（S3は存在しないキーの削除も成功を返す）
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err = s3Error(err); errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// List ListObjectsV2でページを辿って取得
+func (s *S3) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, s3Error(err)
+		}
+		for _, c := range page.Contents {
+			objects = append(objects, ObjectInfo{Key: aws.ToString(c.Key), Size: aws.ToInt64(c.Size), ModTime: aws.ToTime(c.LastModified)})
+		}
+	}
+	return objects, nil
+}
+
+// s3ObjectInfo レスポンスの項目からオブジェクトの情報を作成
+func s3ObjectInfo(key string, size *int64, contentType *string, modTime *time.Time) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        aws.ToInt64(size),
+		ContentType: aws.ToString(contentType),
+		ModTime:     aws.ToTime(modTime),
+	}
+}
+
+// s3Error 404をErrNotFoundに変換する（HeadObjectは本文がなくNoSuchKeyを返せないため、ステータスで判定する）
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("S3の操作に失敗しました: %w", err)
+}
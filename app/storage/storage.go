@@ -0,0 +1,121 @@
+// Package storage 添付ファイル・エクスポート成果物等を保存するストレージの抽象化
+// ローカルディスク・Amazon S3（互換サービスを含む）・Google Cloud Storage のドライバーを提供する
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ドライバー名
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverGCS   = "gcs"
+)
+
+// ErrNotFound オブジェクトが存在しない
+var ErrNotFound = errors.New("オブジェクトが見つかりません")
+
+// ErrInvalidKey キーが不正（空・絶対パス・".." を含む等）
+var ErrInvalidKey = errors.New("オブジェクトのキーが不正です")
+
+// ObjectInfo オブジェクトの情報
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage オブジェクトの保存先
+// キーは "/" 区切りの相対パス（例: exports/2025/09/todos.csv）で、ドライバーによらず同じキーで読み書きできる
+type Storage interface {
+	// Driver ドライバー名
+	Driver() string
+	// Put オブジェクトを保存（同じキーのオブジェクトは置き換える。sizeが不明な場合は-1）
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get オブジェクトを読み込む（呼び出し側でCloseする。存在しない場合はErrNotFound）
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat オブジェクトの情報を取得（存在しない場合はErrNotFound）
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete オブジェクトを削除（存在しない場合も成功とする）
+	Delete(ctx context.Context, key string) error
+	// List キーがprefixで始まるオブジェクトをキーの順に取得
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ValidateKey キーがドライバー間で共通に扱える相対パスか検証
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// spool サイズが不明な内容を一時ファイルに書き出す（S3・GCSはContent-Lengthが必要なため）
+// 返したファイルは読み込み位置を先頭に戻してあり、呼び出し側で閉じて削除する
+func spool(r io.Reader) (*os.File, int64, error) {
+	f, err := os.CreateTemp("", "storage-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+	return f, size, nil
+}
+
+// sizedBody サイズが不明な場合は一時ファイルに書き出して、送信できる本文とサイズを返す
+func sizedBody(r io.Reader, size int64) (io.Reader, int64, func(), error) {
+	if size >= 0 {
+		return r, size, func() {}, nil
+	}
+	f, size, err := spool(r)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("一時ファイルへの書き出しに失敗しました: %w", err)
+	}
+	return f, size, func() {
+		f.Close()
+		os.Remove(f.Name())
+	}, nil
+}
+
+// Options 使用するドライバーと各ドライバーの設定
+type Options struct {
+	Driver string
+	// LocalDir ローカルドライバーの保存先ディレクトリ
+	LocalDir string
+	S3       S3Options
+	GCS      GCSOptions
+}
+
+// New 設定したドライバーのストレージを作成
+func New(opts Options) (Storage, error) {
+	switch opts.Driver {
+	case DriverLocal:
+		return NewLocal(opts.LocalDir)
+	case DriverS3:
+		return NewS3(opts.S3)
+	case DriverGCS:
+		return NewGCS(opts.GCS)
+	default:
+		return nil, fmt.Errorf("対応していないストレージのドライバーです: %q", opts.Driver)
+	}
+}
@@ -0,0 +1,130 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"myapp/db/model"
+	"myapp/service"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// listLimit /list で表示する最大件数
+const listLimit = 20
+
+// titleMaxLength Todoのタイトルの最大文字数
+const titleMaxLength = 255
+
+// helpText /start・/help の返信
+const helpText = `Todoを操作できます。
+/add <タイトル> - Todoを追加（コマンドなしのメッセージも追加として扱います。2行目以降は説明）
+/list - 未完了のTodoを表示
+/done <ID> - Todoを完了にする`
+
+// TodoHandler Todoを操作するコマンドを処理するハンドラー
+type TodoHandler struct {
+	todoService service.TodoService
+}
+
+// NewTodoHandler 新しいハンドラーを作成
+func NewTodoHandler(todoService service.TodoService) *TodoHandler {
+	return &TodoHandler{todoService: todoService}
+}
+
+// HandleMessage コマンドを実行し、返信するテキストを返す
+func (h *TodoHandler) HandleMessage(ctx context.Context, msg *Message) string {
+	command, args := parseCommand(msg.Text)
+	switch command {
+	case "/start", "/help":
+		return helpText
+	case "/list":
+		return h.list(ctx)
+	case "/done":
+		return h.done(ctx, args)
+	case "/add":
+		return h.add(ctx, args)
+	case "":
+		return h.add(ctx, msg.Text)
+	default:
+		return "不明なコマンドです。\n\n" + helpText
+	}
+}
+
+// add メッセージの1行目をタイトル、2行目以降を説明としてTodoを作成
+func (h *TodoHandler) add(ctx context.Context, text string) string {
+	title, description, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "追加するTodoのタイトルを入力してください（例: /add 牛乳を買う）"
+	}
+	if utf8.RuneCountInString(title) > titleMaxLength {
+		title = string([]rune(title)[:titleMaxLength])
+	}
+
+	todo, err := h.todoService.CreateTodo(ctx, &model.TodoCreateRequest{
+		Title:       title,
+		Description: strings.TrimSpace(description),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "TelegramからのTodo作成に失敗しました", "error", err)
+		return "Todoを追加できませんでした。"
+	}
+	return fmt.Sprintf("追加しました: #%d %s", todo.ID, todo.Title)
+}
+
+// list 未完了のTodoを優先度順に表示
+func (h *TodoHandler) list(ctx context.Context) string {
+	todos, err := h.todoService.GetPendingTodos(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "TelegramへのTodo一覧の取得に失敗しました", "error", err)
+		return "Todoを取得できませんでした。"
+	}
+	if len(todos) == 0 {
+		return "未完了のTodoはありません。"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "未完了のTodo（%d件）", len(todos))
+	for i, todo := range todos {
+		if i == listLimit {
+			fmt.Fprintf(&b, "\n…ほか%d件", len(todos)-listLimit)
+			break
+		}
+		fmt.Fprintf(&b, "\n#%d [%s] %s", todo.ID, todo.Priority, todo.Title)
+		if todo.DueDate != nil {
+			b.WriteString("（期限: " + todo.DueDate.Format("01/02 15:04") + "）")
+		}
+	}
+	return b.String()
+}
+
+// done 指定したIDのTodoを完了にする
+func (h *TodoHandler) done(ctx context.Context, args string) string {
+	id, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(args), "#"), 10, 0)
+	if err != nil || id == 0 {
+		return "完了にするTodoのIDを指定してください（例: /done 12）"
+	}
+
+	completed := true
+	todo, err := h.todoService.UpdateTodo(ctx, uint(id), &model.TodoUpdateRequest{Completed: &completed})
+	if err != nil {
+		return fmt.Sprintf("ID %d のTodoを完了にできませんでした。", id)
+	}
+	return fmt.Sprintf("完了にしました: #%d %s", todo.ID, todo.Title)
+}
+
+// parseCommand "/cmd@botname 引数" 形式のテキストをコマンドと引数に分ける（コマンドでない場合は空文字）
+func parseCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", text
+	}
+	command, args := text, ""
+	if i := strings.IndexAny(text, " \n"); i >= 0 {
+		command, args = text[:i], text[i+1:]
+	}
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), args
+}
@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// apiBaseURL Telegram Bot APIのベースURL
+const apiBaseURL = "https://api.telegram.org"
+
+// pollTimeout getUpdates のロングポーリングの待ち時間
+const pollTimeout = 30 * time.Second
+
+// retryInterval getUpdates に失敗した場合の再試行間隔
+const retryInterval = 5 * time.Second
+
+// Update Telegramから受け取る更新（メッセージのみ扱う）
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// Message 受信したメッセージ
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat メッセージの送信元チャット
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// Client Telegram Bot APIのクライアント
+type Client struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient 新しいクライアントを作成
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: apiBaseURL,
+		client:  &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+// GetUpdates offset以降の更新をロングポーリングで取得
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(pollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage チャットにテキストを送信
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	}, nil)
+}
+
+// call Bot APIのメソッドを呼び出し、resultをoutにデコードする
+func (c *Client) call(ctx context.Context, method string, params any, out any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// URLにトークンが含まれるため、エラーメッセージにURLを出さない
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("Telegram API %s の呼び出しに失敗しました: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("Telegram API %s のレスポンスを解析できません: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("Telegram API %s がエラーを返しました: %s", method, result.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(result.Result, out)
+	}
+	return nil
+}
+
+// Handler メッセージへの返信を作成する
+type Handler interface {
+	HandleMessage(ctx context.Context, msg *Message) string
+}
+
+// Poll 更新をロングポーリングで受信し、許可されたチャットのメッセージをhandlerで処理する（ctxがキャンセルされるまでブロック）
+// allowedChatIDs 以外のチャットからのメッセージには応答しない
+func Poll(ctx context.Context, client *Client, handler Handler, allowedChatIDs []int64) {
+	allowed := make(map[int64]bool, len(allowedChatIDs))
+	for _, id := range allowedChatIDs {
+		allowed[id] = true
+	}
+
+	var offset int64
+	for {
+		updates, err := client.GetUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.ErrorContext(ctx, "Telegramの更新の取得に失敗しました", "error", err)
+			select {
+			case <-time.After(retryInterval):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			msg := update.Message
+			if msg == nil || msg.Text == "" {
+				continue
+			}
+			if !allowed[msg.Chat.ID] {
+				slog.WarnContext(ctx, "許可されていないTelegramチャットからのメッセージを無視しました", "chat_id", strconv.FormatInt(msg.Chat.ID, 10))
+				continue
+			}
+
+			reply := handler.HandleMessage(ctx, msg)
+			if reply == "" {
+				continue
+			}
+			if err := client.SendMessage(ctx, msg.Chat.ID, reply); err != nil {
+				slog.ErrorContext(ctx, "Telegramへの返信に失敗しました", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"myapp/config"
+	"myapp/i18n"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Middleware ルートごとのタイムアウトを適用し、時間内に応答できない場合は504を返すミドルウェア
+// ハンドラーはタイムアウト付きのコンテキストで別goroutineとして実行し、レスポンスはバッファしてから書き出す
+// 設定はリクエストごとに config.Current() を参照するため、ホットリロードで変更できる
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := timeoutFor(config.Current().Timeout, r.URL.Path)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			next.ServeHTTP(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			// 外側のリカバリーミドルウェアで処理させる
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			dst := w.Header()
+			for key, values := range tw.header {
+				dst[key] = values
+			}
+			if tw.status == 0 {
+				tw.status = http.StatusOK
+			}
+			w.WriteHeader(tw.status)
+			w.Write(tw.body.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if ctx.Err() == context.Canceled {
+				// クライアントが切断した場合は応答しない
+				return
+			}
+			slog.WarnContext(r.Context(), "リクエストがタイムアウトしました",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"timeout", timeout,
+			)
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusGatewayTimeout)
+			json.NewEncoder(w).Encode(huma.NewError(http.StatusGatewayTimeout, i18n.T(r.Context(), fmt.Sprintf("リクエストがタイムアウトしました（%s）", timeout))))
+		}
+	})
+}
+
+// timeoutFor パスに適用するタイムアウトを取得（最も長く先頭一致したパスごとの設定、なければ既定値）
+func timeoutFor(cfg config.TimeoutConfig, path string) time.Duration {
+	timeout := cfg.Default
+
+	matched := 0
+	for _, rule := range cfg.Paths {
+		if strings.HasPrefix(path, rule.Prefix) && len(rule.Prefix) > matched {
+			matched = len(rule.Prefix)
+			timeout = rule.Timeout
+		}
+	}
+	return timeout
+}
+
+// timeoutWriter ハンドラーのレスポンスをバッファするResponseWriter
+// タイムアウト後の書き込みは http.ErrHandlerTimeout を返して破棄する
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
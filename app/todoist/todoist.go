@@ -0,0 +1,217 @@
+// Package todoist TodoistのエクスポートをTodoに取り込むためのパーサー
+package todoist
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"myapp/db/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiTask REST API・同期APIのタスク（同期APIのitemsとREST APIのtasksの両方に対応）
+type apiTask struct {
+	ID          json.RawMessage `json:"id"`
+	Content     string          `json:"content"`
+	Description string          `json:"description"`
+	ProjectID   json.RawMessage `json:"project_id"`
+	Priority    int             `json:"priority"`
+	IsCompleted bool            `json:"is_completed"`
+	Checked     bool            `json:"checked"`
+	IsDeleted   bool            `json:"is_deleted"`
+	Due         *struct {
+		Date     string `json:"date"`
+		Datetime string `json:"datetime"`
+		Timezone string `json:"timezone"`
+		String   string `json:"string"`
+	} `json:"due"`
+}
+
+// apiProject REST API・同期APIのプロジェクト
+type apiProject struct {
+	ID   json.RawMessage `json:"id"`
+	Name string          `json:"name"`
+}
+
+// apiExport APIから取得したプロジェクトとタスク（tasksはREST API、itemsは同期APIの形式）
+type apiExport struct {
+	Projects []apiProject `json:"projects"`
+	Tasks    []apiTask    `json:"tasks"`
+	Items    []apiTask    `json:"items"`
+}
+
+// ParseJSON APIのエクスポート（タスクの配列、または projects と tasks / items を持つオブジェクト）を読み取る
+func ParseJSON(data []byte) ([]model.ImportTask, error) {
+	data = bytes.TrimSpace(data)
+	var export apiExport
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &export.Tasks); err != nil {
+			return nil, fmt.Errorf("JSONの形式が不正です: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("JSONの形式が不正です: %w", err)
+	}
+
+	projects := make(map[string]string, len(export.Projects))
+	for _, p := range export.Projects {
+		projects[rawID(p.ID)] = p.Name
+	}
+
+	var tasks []model.ImportTask
+	for _, t := range append(export.Tasks, export.Items...) {
+		if t.IsDeleted {
+			continue
+		}
+		task := model.ImportTask{
+			Ref:         rawID(t.ID),
+			Title:       t.Content,
+			Description: t.Description,
+			Project:     projects[rawID(t.ProjectID)],
+			// APIのpriorityは4が最も高い（画面上のp1）
+			Priority:  priorityFromLevel(5 - t.Priority),
+			Completed: t.IsCompleted || t.Checked,
+		}
+		if t.Due != nil {
+			value := t.Due.Datetime
+			if value == "" {
+				value = t.Due.Date
+			}
+			if due, ok := parseDue(value, t.Due.Timezone); ok {
+				task.Due = &due
+			} else if value != "" || t.Due.String != "" {
+				task.UnparsedDue = firstNonEmpty(t.Due.String, value)
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// ParseCSV プロジェクトのCSVエクスポートを読み取る（CSVにはプロジェクト名が含まれないため引数で指定）
+func ParseCSV(r io.Reader, project string) ([]model.ImportTask, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSVが空です")
+		}
+		return nil, fmt.Errorf("CSVの形式が不正です: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSVに %s 列がありません", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var tasks []model.ImportTask
+	line := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("CSVの%d行目が不正です: %w", line, err)
+		}
+		// セクション・コメント等の行は取り込まない
+		if !strings.EqualFold(field(record, "TYPE"), "task") {
+			continue
+		}
+
+		task := model.ImportTask{
+			Ref:         fmt.Sprintf("%d行目", line),
+			Title:       field(record, "CONTENT"),
+			Description: field(record, "DESCRIPTION"),
+			Project:     project,
+			Priority:    model.PriorityLow,
+		}
+		// CSVのPRIORITYは画面上の表記と同じく1が最も高い
+		if level, err := strconv.Atoi(field(record, "PRIORITY")); err == nil {
+			task.Priority = priorityFromLevel(level)
+		}
+		if value := field(record, "DATE"); value != "" {
+			if due, ok := parseDue(value, field(record, "TIMEZONE")); ok {
+				task.Due = &due
+			} else {
+				task.UnparsedDue = value
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// priorityFromLevel 画面上の優先度（p1〜p4、p1が最も高い）を変換
+func priorityFromLevel(level int) model.Priority {
+	switch level {
+	case 1:
+		return model.PriorityUrgent
+	case 2:
+		return model.PriorityHigh
+	case 3:
+		return model.PriorityMedium
+	default:
+		return model.PriorityLow
+	}
+}
+
+// parseDue 期限を解釈（RFC 3339・タイムゾーンなしの日時・日付のみ。タイムゾーンが指定されていなければサーバーのローカル時刻）
+func parseDue(value, timezone string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		if l, err := time.LoadLocation(timezone); err == nil {
+			loc = l
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// rawID 文字列・数値のどちらでも表されるIDを文字列にする
+func rawID(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// firstNonEmpty 最初の空でない文字列
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
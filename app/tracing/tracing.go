@@ -0,0 +1,91 @@
+// Package tracing OpenTelemetryによる分散トレーシング（HTTP・サービス・GORMのスパンの生成とOTLP/HTTPでの送信）
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName スパンを生成するトレーサーの名前
+const instrumentationName = "myapp"
+
+// SpanKind スパンの種別
+type SpanKind = trace.SpanKind
+
+const (
+	SpanKindInternal = trace.SpanKindInternal
+	SpanKindServer   = trace.SpanKindServer
+	SpanKindClient   = trace.SpanKindClient
+)
+
+// provider Initで登録したトレーサープロバイダー（Shutdownで残りのスパンを送信する）
+var provider *sdktrace.TracerProvider
+
+// Init トレーサープロバイダーとW3C Trace Contextの伝播を登録する
+// endpointが空の場合はスパンを送信しない（トレースIDの生成・伝播は行う）
+func Init(endpoint, serviceName string) error {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return fmt.Errorf("トレースのリソースの作成に失敗しました: %w", err)
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if endpoint == "" {
+		// 送信先がない場合も、ログ・レスポンスヘッダーに載せるトレースIDは生成する（スパンは記録しない）
+		options = append(options, sdktrace.WithSampler(sdktrace.NeverSample()))
+	} else {
+		exporter, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"),
+		)
+		if err != nil {
+			return fmt.Errorf("OTLPトレースエクスポーターの作成に失敗しました: %w", err)
+		}
+		// 受信したtraceparentのサンプリングの判断に従い、親がない場合は全てサンプリングする
+		options = append(options,
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+			sdktrace.WithBatcher(exporter),
+		)
+		slog.Info("OTLPトレースエクスポーターを開始しました", "endpoint", endpoint)
+	}
+
+	provider = sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown キューに残ったスパンを送信してトレーサープロバイダーを停止
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	if err := provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("トレーサープロバイダーの停止に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Start 新しいスパンを開始し、スパンを保持したコンテキストを返す
+// コンテキストに親スパン（またはリモートの親）があれば同じトレースに属する
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// TraceIDFromContext コンテキストからトレースIDを取得（存在しなければ空文字）
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+// Package trello TrelloのボードのJSONエクスポートをTodoに取り込むためのパーサー
+package trello
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"myapp/db/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// board ボードのJSONエクスポート（取り込みに使う項目のみ）
+type board struct {
+	Name       string      `json:"name"`
+	Lists      []list      `json:"lists"`
+	Cards      []card      `json:"cards"`
+	Checklists []checklist `json:"checklists"`
+}
+
+// list ボードのリスト
+type list struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Closed bool   `json:"closed"`
+}
+
+// card リストのカード
+type card struct {
+	ID           string   `json:"id"`
+	ShortLink    string   `json:"shortLink"`
+	Name         string   `json:"name"`
+	Desc         string   `json:"desc"`
+	IDList       string   `json:"idList"`
+	Closed       bool     `json:"closed"`
+	Due          *string  `json:"due"`
+	DueComplete  bool     `json:"dueComplete"`
+	IDChecklists []string `json:"idChecklists"`
+	Labels       []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// checklist カードのチェックリスト
+type checklist struct {
+	ID         string  `json:"id"`
+	IDCard     string  `json:"idCard"`
+	Name       string  `json:"name"`
+	Pos        float64 `json:"pos"`
+	CheckItems []struct {
+		Name  string  `json:"name"`
+		State string  `json:"state"`
+		Pos   float64 `json:"pos"`
+	} `json:"checkItems"`
+}
+
+// labelPriorities ラベル名と優先度の対応（大文字小文字を区別しない）
+var labelPriorities = map[string]model.Priority{
+	"urgent": model.PriorityUrgent,
+	"緊急":     model.PriorityUrgent,
+	"high":   model.PriorityHigh,
+	"高":      model.PriorityHigh,
+	"medium": model.PriorityMedium,
+	"中":      model.PriorityMedium,
+	"low":    model.PriorityLow,
+	"低":      model.PriorityLow,
+}
+
+// ParseBoard ボードのJSONエクスポートを読み取る
+// リスト→プロジェクト、カード→タスク、チェックリストの項目→サブタスクに変換し、アーカイブ済みのリスト・カードは取り込まない
+func ParseBoard(data []byte) ([]model.ImportTask, error) {
+	var b board
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("JSONの形式が不正です: %w", err)
+	}
+	if b.Lists == nil && b.Cards == nil {
+		return nil, errors.New("ボードのエクスポートではありません（lists・cardsがありません）")
+	}
+
+	lists := make(map[string]list, len(b.Lists))
+	for _, l := range b.Lists {
+		lists[l.ID] = l
+	}
+	checklists := make(map[string][]checklist)
+	for _, c := range b.Checklists {
+		checklists[c.IDCard] = append(checklists[c.IDCard], c)
+	}
+
+	var tasks []model.ImportTask
+	for _, c := range b.Cards {
+		l, ok := lists[c.IDList]
+		if c.Closed || (ok && l.Closed) {
+			continue
+		}
+
+		task := model.ImportTask{
+			Ref:         firstNonEmpty(c.ShortLink, c.ID),
+			Title:       c.Name,
+			Description: c.Desc,
+			Project:     l.Name,
+			Priority:    priorityFromLabels(c),
+			Completed:   c.DueComplete,
+		}
+		if c.Due != nil && *c.Due != "" {
+			if due, err := time.Parse(time.RFC3339Nano, *c.Due); err == nil {
+				task.Due = &due
+			} else {
+				task.UnparsedDue = *c.Due
+			}
+		}
+		task.Subtasks = subtasks(checklists[c.ID])
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// priorityFromLabels 優先度を表すラベルから優先度を決める（複数ある場合は最も高いもの。ない場合は中）
+func priorityFromLabels(c card) model.Priority {
+	priority := model.Priority("")
+	for _, label := range c.Labels {
+		if p, ok := labelPriorities[strings.ToLower(strings.TrimSpace(label.Name))]; ok && p.Rank() > priority.Rank() {
+			priority = p
+		}
+	}
+	if priority == "" {
+		return model.PriorityMedium
+	}
+	return priority
+}
+
+// subtasks チェックリストの項目を表示順にサブタスクへ変換（複数のチェックリストは項目名の前にチェックリスト名を付ける）
+func subtasks(lists []checklist) []model.ImportSubtask {
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+
+	var result []model.ImportSubtask
+	for _, l := range lists {
+		items := l.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		for _, item := range items {
+			title := item.Name
+			if len(lists) > 1 && l.Name != "" {
+				title = l.Name + ": " + title
+			}
+			result = append(result, model.ImportSubtask{
+				Title:     title,
+				Completed: item.State == "complete",
+			})
+		}
+	}
+	return result
+}
+
+// firstNonEmpty 最初の空でない文字列
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// ビルド時に -ldflags "-X" で埋め込まれる値
+//
+//	go build -ldflags "-X myapp/version.Version=1.2.0 -X myapp/version.Commit=$(git rev-parse HEAD) -X myapp/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "1.0.0"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info バージョン情報
+type Info struct {
+	Version   string `json:"version" doc:"アプリケーションのバージョン"`
+	Commit    string `json:"commit" doc:"ビルド元のコミットハッシュ"`
+	BuildDate string `json:"build_date" doc:"ビルド日時"`
+	GoVersion string `json:"go_version" doc:"ビルドに使用したGoのバージョン"`
+}
+
+// Get バージョン情報を取得
+// ldflagsで埋め込まれていない項目は、Goが埋め込むVCS情報から補完する
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+
+	return info
+}
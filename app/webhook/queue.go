@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"myapp/queue"
+	"net/http"
+	"sync/atomic"
+)
+
+// JobKind Webhookの送信ジョブの種類
+const JobKind = "webhook.post"
+
+// postJob Webhookの送信ジョブの内容
+type postJob struct {
+	URL     string          `json:"url"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// jobs Webhookの送信に使うジョブキュー（未設定の場合は呼び出し元で同期的に送信する）
+var jobs atomic.Pointer[queue.Enqueuer]
+
+// SetQueue Webhookの送信に使うジョブキューを設定する（JobKindにHandleJobを登録したキューを指定する）
+func SetQueue(q queue.Enqueuer) {
+	jobs.Store(&q)
+}
+
+// Deliver ペイロードを送信する。ジョブキューが設定されている場合はキューに積み、失敗時はキューで再試行する
+func Deliver(ctx context.Context, client *http.Client, url string, payload any) error {
+	q := jobs.Load()
+	if q == nil {
+		return Post(ctx, client, url, payload)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = (*q).Enqueue(ctx, JobKind, postJob{URL: url, Payload: body})
+	return err
+}
+
+// HandleJob clientで送信ジョブを実行する処理（送信先が4xxを返した場合は、タイムアウト・レート制限を除き再試行しない）
+func HandleJob(client *http.Client) queue.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job postJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return queue.Permanent(err)
+		}
+		err := Post(ctx, client, job.URL, job.Payload)
+		if err != nil && !Retryable(err) {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress 送信先が内部ネットワーク（プライベート・ループバック・リンクローカル等）のアドレス
+var ErrForbiddenAddress = errors.New("送信先にプライベート・ループバック・リンクローカルのアドレスは指定できません")
+
+// blockedPrefixes IsGlobalUnicast・IsPrivateで判定できない、外部へ送るべきでないアドレスの範囲
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // 「このネットワーク」
+	netip.MustParsePrefix("100.64.0.0/10"),  // キャリアグレードNAT（共有アドレス空間）
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETFプロトコル割り当て
+	netip.MustParsePrefix("198.18.0.0/15"),  // ベンチマーク用
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64（内部のIPv4アドレスを埋め込める）
+	netip.MustParsePrefix("64:ff9b:1::/48"), // ローカルNAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // ドキュメント用
+	netip.MustParsePrefix("fec0::/10"),      // サイトローカル（廃止済み）
+}
+
+// PublicAddr インターネット上の送信先として許可するアドレスか
+// ループバック・プライベート・リンクローカル・マルチキャスト・未指定アドレス等は許可しない（IPv4射影アドレスはIPv4として判定する）
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL 送信先のホストが内部ネットワークのアドレスでないか確認する（登録時の検証用）
+// ホスト名は名前解決し、いずれかのアドレスが内部ネットワークであればErrForbiddenAddress。名前解決できない場合は送信時の検査（NewPublicClient）に任せる
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !PublicAddr(addr) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !PublicAddr(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewPublicClient 内部ネットワークのアドレスへ接続しないHTTPクライアントを作成
+// 名前解決後の接続先アドレスを接続の直前に検査するため、登録後のDNSの変更（DNSリバインディング）やリダイレクトでも内部ネットワークへは送らない。
+// プロキシを経由すると接続先を検査できないため、環境変数のプロキシは使わない
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !PublicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
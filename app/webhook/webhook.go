@@ -0,0 +1,240 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 署名ヘッダー
+const (
+	// SignatureHeader 署名（v2=<hex>,v1=<hex>、ローテーション猶予中は新旧をカンマ区切りで並べる）
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader 署名時刻（UNIX秒）
+	TimestampHeader = "X-Webhook-Timestamp"
+	// NonceHeader リクエストごとに一意な値（受信側でリプレイ検出に使う）
+	NonceHeader = "X-Webhook-Nonce"
+)
+
+// 署名方式のバージョン
+const (
+	// signatureVersion HMAC-SHA256("<timestamp>.<nonce>.<body>")
+	signatureVersion = "v2"
+	// legacySignatureVersion nonceを含まない HMAC-SHA256("<timestamp>.<body>")（移行期間中のみ送る）
+	legacySignatureVersion = "v1"
+)
+
+// DefaultTolerance 受信側で許容する署名時刻のずれ
+const DefaultTolerance = 5 * time.Minute
+
+// 検証エラー
+var (
+	ErrMissingSignature = errors.New("署名ヘッダーがありません")
+	ErrTimestampExpired = errors.New("署名時刻が許容範囲外です")
+	ErrInvalidSignature = errors.New("署名が一致しません")
+)
+
+// Secret 署名に使うシークレット（ExpiresAtがnilのものが現在のシークレット）
+type Secret struct {
+	Value     string
+	ExpiresAt *time.Time
+}
+
+// secrets 署名に使うシークレット一覧（先頭が現在のシークレット）
+var secrets atomic.Pointer[[]Secret]
+
+// SetSecrets 署名に使うシークレットを差し替える（先頭が現在のシークレット、以降はローテーション猶予中の旧シークレット）
+func SetSecrets(list []Secret) {
+	secrets.Store(&list)
+}
+
+// legacySignature v2に加えてv1の署名も送るか（受信側がv2の検証に移行するまでの互換用）
+var legacySignature atomic.Bool
+
+func init() {
+	legacySignature.Store(true)
+}
+
+// SetLegacySignature v1の署名も送るかを設定
+func SetLegacySignature(enabled bool) {
+	legacySignature.Store(enabled)
+}
+
+// activeSecrets 失効していないシークレットを取得
+func activeSecrets(now time.Time) []string {
+	list := secrets.Load()
+	if list == nil {
+		return nil
+	}
+	values := make([]string, 0, len(*list))
+	for _, secret := range *list {
+		if secret.ExpiresAt == nil || now.Before(*secret.ExpiresAt) {
+			values = append(values, secret.Value)
+		}
+	}
+	return values
+}
+
+// Sign リクエストに署名・タイムスタンプ・nonceヘッダーを付与する（シークレット未設定の場合は何もしない）
+func Sign(req *http.Request, body []byte, now time.Time) {
+	SignWith(req, body, now, activeSecrets(now))
+}
+
+// SignWith 指定したシークレットで署名する（サーバー間APIの呼び出し等、Webhook以外の署名付きリクエスト用）
+func SignWith(req *http.Request, body []byte, now time.Time, secrets []string) {
+	if len(secrets) == 0 {
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	nonce := newNonce()
+	legacy := legacySignature.Load()
+	signatures := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, signatureVersion+"="+compute(secret, timestamp, nonce, body))
+		if legacy {
+			signatures = append(signatures, legacySignatureVersion+"="+compute(secret, timestamp, "", body))
+		}
+	}
+
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(NonceHeader, nonce)
+	req.Header.Set(SignatureHeader, strings.Join(signatures, ","))
+}
+
+// newNonce 128ビットのランダムなnonceを生成
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Verify 受信したWebhookの署名を検証する（受信側・テスト用）
+// 署名ヘッダーのいずれか（v2、またはnonceを含まないv1）がsecretで計算した値と一致し、署名時刻がtolerance以内であれば成功
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	return verify(secret, header, body, now, tolerance, true)
+}
+
+// VerifyNonce nonceを含むv2の署名のみを受け付けて検証する（nonceでリプレイを検出する場合、v1ではnonceを差し替えられるため）
+func VerifyNonce(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	return verify(secret, header, body, now, tolerance, false)
+}
+
+func verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration, allowLegacy bool) error {
+	timestamp := header.Get(TimestampHeader)
+	signature := header.Get(SignatureHeader)
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+	if diff := now.Sub(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+		return ErrTimestampExpired
+	}
+
+	nonce := header.Get(NonceHeader)
+	for _, part := range strings.Split(signature, ",") {
+		version, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		var expected string
+		switch {
+		case version == signatureVersion && nonce != "":
+			expected = compute(secret, timestamp, nonce, body)
+		case version == legacySignatureVersion && allowLegacy:
+			expected = compute(secret, timestamp, "", body)
+		default:
+			continue
+		}
+		if hmac.Equal([]byte(value), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// compute HMAC-SHA256("<timestamp>.<nonce>.<body>") を16進文字列で返す（nonceが空の場合は "<timestamp>.<body>"）
+func compute(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	if nonce != "" {
+		mac.Write([]byte(nonce))
+		mac.Write([]byte("."))
+	}
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post ペイロードをJSONで署名付きPOSTする（2xx以外はエラー）
+func Post(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = Send(ctx, client, url, body, nil)
+	return err
+}
+
+// Send エンコード済みのbodyをheaderを付けて署名付きPOSTし、送信先が返したステータスを返す（2xx以外はStatusError）
+// Content-Typeを指定しない場合は application/json で送る
+func Send(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	Sign(req, body, time.Now())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 接続を再利用できるよう、本文は上限付きで読み捨てる
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, &StatusError{Code: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
+}
+
+// Retryable 送信の失敗を再試行すべきか（4xxはタイムアウト・レート制限を除き、再試行しても成功しないとみなす。内部ネットワークへの送信も再試行しない）
+func Retryable(err error) bool {
+	if errors.Is(err, ErrForbiddenAddress) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code >= 400 && statusErr.Code < 500 {
+		return statusErr.Code == http.StatusRequestTimeout || statusErr.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// StatusError 送信先が2xx以外のステータスを返した
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("ステータス %d が返されました", e.Code)
+}
@@ -0,0 +1,310 @@
+// Package webpush Web Push（RFC 8030）のメッセージ送信（VAPID認証 RFC 8292・aes128gcmによる暗号化 RFC 8291）
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// 送信の定数
+const (
+	// recordSize 暗号化レコードのサイズ（ペイロードは1レコードに収める）
+	recordSize = 4096
+	// MaxPayloadSize 暗号化前のペイロードの上限（レコードサイズからヘッダー・認証タグ・区切りを除いたもの）
+	MaxPayloadSize = recordSize - 16 - 4 - 1 - 65 - 16 - 1
+	// jwtLifetime VAPIDのJWTの有効期限（仕様上の上限は24時間）
+	jwtLifetime = 12 * time.Hour
+)
+
+// エラー
+var (
+	// ErrGone 購読が失効している（404 / 410。購読を削除する）
+	ErrGone = errors.New("購読が失効しています")
+	// ErrPayloadTooLarge ペイロードが大きすぎる
+	ErrPayloadTooLarge = errors.New("ペイロードが大きすぎます")
+)
+
+// Urgency 配信の緊急度（端末の省電力状態での配信可否に影響する）
+type Urgency string
+
+const (
+	UrgencyNormal Urgency = "normal"
+	UrgencyHigh   Urgency = "high"
+)
+
+// Subscription ブラウザのPushSubscriptionの送信先と鍵
+type Subscription struct {
+	Endpoint string
+	// P256dh ブラウザの公開鍵（base64url）
+	P256dh string
+	// Auth 認証シークレット（base64url）
+	Auth string
+}
+
+// StatusError 送信先が2xx以外を返した
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("プッシュサービスがステータス %d を返しました: %s", e.StatusCode, e.Body)
+}
+
+// VAPID アプリケーションサーバーの鍵（VAPID）
+type VAPID struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  []byte
+	subject    string
+}
+
+// NewVAPID base64urlの秘密鍵（P-256の32バイト）と連絡先（mailto: または https:）からVAPIDを作成
+func NewVAPID(privateKey, subject string) (*VAPID, error) {
+	raw, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("VAPIDの秘密鍵が不正です: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("VAPIDの秘密鍵が不正です: %w", err)
+	}
+	// 署名に使うためPKCS#8を経由してECDSAの鍵に変換する
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("VAPIDの秘密鍵をECDSAの鍵に変換できません")
+	}
+	return &VAPID{
+		privateKey: ecdsaKey,
+		publicKey:  key.PublicKey().Bytes(),
+		subject:    subject,
+	}, nil
+}
+
+// GenerateKeys 新しいVAPIDの鍵ペアを生成（いずれもbase64url）
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encodeBase64(key.PublicKey().Bytes()), encodeBase64(key.Bytes()), nil
+}
+
+// PublicKey ブラウザの subscribe() に applicationServerKey として渡す公開鍵（base64url）
+func (v *VAPID) PublicKey() string {
+	return encodeBase64(v.publicKey)
+}
+
+// authorization 送信先のオリジンに対するVAPIDのAuthorizationヘッダー
+func (v *VAPID) authorization(endpoint *url.URL) (string, error) {
+	header := encodeBase64([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(jwtLifetime).Unix(),
+		"sub": v.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + encodeBase64(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, v.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// JWSのES256はr・sをそれぞれ32バイトの固定長で連結する
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return "vapid t=" + signingInput + "." + encodeBase64(signature) + ", k=" + v.PublicKey(), nil
+}
+
+// Client Web Pushの送信クライアント
+type Client struct {
+	vapid  *VAPID
+	client *http.Client
+}
+
+// NewClient 新しいクライアントを作成
+func NewClient(vapid *VAPID) *Client {
+	return &Client{
+		vapid:  vapid,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send ペイロードを暗号化して送信（ttlはプッシュサービスが配信を試みる期間。失効した購読はErrGone）
+func (c *Client) Send(ctx context.Context, sub Subscription, payload []byte, ttl time.Duration, urgency Urgency) error {
+	if len(payload) > MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return fmt.Errorf("購読の送信先が不正です: %w", err)
+	}
+
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := c.vapid.authorization(endpoint)
+	if err != nil {
+		return fmt.Errorf("VAPIDの署名に失敗しました: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	if urgency != "" {
+		req.Header.Set("Urgency", string(urgency))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// Validate 購読の鍵で暗号化できるか確認（登録時に壊れた購読を拒否する）
+func Validate(sub Subscription) error {
+	_, err := encrypt(sub, nil)
+	return err
+}
+
+// encrypt RFC 8291（aes128gcm）でペイロードを暗号化し、ヘッダーを付けたボディを返す
+// メッセージごとに使い捨ての鍵ペアとソルトを生成する
+func encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWithKey(sub, payload, asPrivate, salt)
+}
+
+// encryptWithKey 送信側の鍵ペアとソルトを指定して暗号化する
+func encryptWithKey(sub Subscription, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("購読の公開鍵が不正です: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("購読の公開鍵が不正です: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil || len(authSecret) == 0 {
+		return nil, fmt.Errorf("購読の認証シークレットが不正です")
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicRaw...), asPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, sharedSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 最後のレコードの区切り（0x02）を付けて暗号化する
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	// ヘッダー: salt(16) || rs(4) || idlen(1) || keyid(送信側の公開鍵)
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// expand HKDF-Expand
+func expand(prk, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// encodeBase64 パディングなしのbase64url
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeBase64 base64url（パディングの有無・標準のbase64も受け付ける）
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("base64として解釈できません")
+}
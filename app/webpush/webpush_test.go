@@ -0,0 +1,74 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"testing"
+)
+
+// TestEncryptRFC8291 RFC 8291 Appendix A のテストベクター
+func TestEncryptRFC8291(t *testing.T) {
+	sub := Subscription{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	asPrivateRaw := mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw")
+	asPrivate, err := ecdh.P256().NewPrivateKey(asPrivateRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw")
+	plaintext := []byte("When I grow up, I want to be a watermelon")
+	want := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+
+	got, err := encryptWithKey(sub, plaintext, asPrivate, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encryptWithKey() = %s, want %s", encodeBase64(got), encodeBase64(want))
+	}
+}
+
+func TestEncryptRandomKey(t *testing.T) {
+	sub := Subscription{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	a, err := encrypt(sub, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := encrypt(sub, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ヘッダー: salt(16) || rs(4) || idlen(1) || keyid(65)
+	if len(a) != 16+4+1+65+len("payload")+1+16 {
+		t.Errorf("len = %d", len(a))
+	}
+	if bytes.Equal(a[:16], b[:16]) || bytes.Equal(a[21:86], b[21:86]) {
+		t.Error("ソルトと送信側の鍵はメッセージごとに異なる必要があります")
+	}
+}
+
+func TestValidateRejectsBrokenSubscription(t *testing.T) {
+	tests := []Subscription{
+		{P256dh: "invalid", Auth: "BTBZMqHH6r4Tts7J_aSIgg"},
+		{P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4", Auth: ""},
+	}
+	for _, sub := range tests {
+		if err := Validate(sub); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", sub)
+		}
+	}
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,52 @@
+// Package workerpool 一括処理を設定した並列度のgoroutineで実行するワーカープール
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// Run itemsをconcurrency個のワーカーで並列に処理し、全て終わるまでブロックする
+// ctxがキャンセルされると未着手のitemは処理しない（処理中のitemの完了は待つ）
+// fnは複数のgoroutineから同時に呼ばれるため、共有する状態は呼び出し側で保護する
+func Run[T any](ctx context.Context, concurrency int, items []T, fn func(ctx context.Context, item T)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	concurrency = min(concurrency, len(items))
+
+	queue := make(chan T)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				fn(ctx, item)
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case queue <- item:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// Chunks itemsをsize件ずつに分割する（ワーカーに1件ずつではなくまとめて渡す場合に使う）
+func Chunks[T any](items []T, size int) [][]T {
+	if size < 1 {
+		size = 1
+	}
+	chunks := make([][]T, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		chunks = append(chunks, items[start:min(start+size, len(items))])
+	}
+	return chunks
+}